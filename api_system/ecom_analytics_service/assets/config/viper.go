@@ -15,6 +15,13 @@ type ReadENV struct {
 	JWTSecret           string   `mapstructure:"JWT_SECRET"`
 	ClientIP            []string `mapstructure:"CLIENT_IP"`
 	RedisAddress        string   `mapstructure:"REDIS_ADDRESS"`
+	// Khóa ký cursor phân trang, bỏ trống thì dùng JWT_SECRET
+	CursorSecret string `mapstructure:"CURSOR_SECRET"`
+	// Kafka configuration, bỏ trống KAFKA_BROKERS thì không nhận sự kiện cập nhật bảng tổng hợp
+	KafkaBrokers       string `mapstructure:"KAFKA_BROKERS"`
+	KafkaConsumerGroup string `mapstructure:"KAFKA_CONSUMER_GROUP"`
+	// Thư mục chứa file xuất báo cáo chạy nền, bỏ trống thì dùng ./exports
+	ExportDir string `mapstructure:"EXPORT_DIR"`
 	// // URL service`
 }
 
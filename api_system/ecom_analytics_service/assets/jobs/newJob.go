@@ -22,15 +22,17 @@ func NewJobScheduler() (*JobScheduler, error) {
 	}, nil
 }
 
-func (j *JobScheduler) start() {
+func (j *JobScheduler) Start() {
 	fmt.Println("Starting job scheduler...")
 	j.scheduler.Start()
 }
 
-func (j *JobScheduler) NewJob(hours int, job func()) error {
+func (j *JobScheduler) NewJob(minute, hours, date int, job func()) error {
+	interval := time.Duration(hours)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(date)*time.Hour*24
+
 	newJob, err := j.scheduler.NewJob(
 		gocron.DurationJob(
-			time.Duration(hours)*time.Hour,
+			interval,
 			// time.Duration(30)*time.Second,
 		),
 		gocron.NewTask(
@@ -48,7 +50,5 @@ func (j *JobScheduler) NewJob(hours int, job func()) error {
 	// each job has a unique id
 	fmt.Println(newJob.ID())
 
-	// start the scheduler
-	j.start()
 	return nil
 }
@@ -31,6 +31,7 @@ func (maker *JWTMaker) CreateToken(username string, duration time.Duration) (*Pa
 		"scope":  payload.Scope,
 		"iat":    payload.Iat,
 		"email":  payload.Email,
+		"shopId": payload.ShopID,
 	}
 	// Create a new token with claims
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -73,6 +74,10 @@ func (maker *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
 			UserId: claims["userId"].(string),
 			Email:  claims["email"].(string),
 		}
+		// claim shopId chỉ có trong token của người bán
+		if shopID, ok := claims["shopId"].(string); ok {
+			payload.ShopID = shopID
+		}
 
 		// Check expiration manually (optional, but recommended)
 		if !payload.Valid() {
@@ -19,6 +19,8 @@ type Payload struct {
 	Jti    string `json:"jti"`
 	UserId string `json:"userId"`
 	Email  string `json:"email"`
+	// ShopID shop của người bán (claim shopId), rỗng nếu token không có
+	ShopID string `json:"shopId"`
 }
 
 func CreateNewPayload(username string, duration time.Duration) *Payload {
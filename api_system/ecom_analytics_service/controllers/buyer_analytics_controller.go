@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	assets_api "github.com/TranVinhHien/ecom_analytics_service/assets/api"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// === Phân tích hành vi mua hàng (phễu đơn hàng, cohort, RFM) ===
+
+// getOrderFunnel: GET /api/v1/platform/funnel/orders
+// Query params: start_date, end_date (mặc định 30 ngày gần nhất, tối đa 93 ngày)
+func (api apiController) getOrderFunnel() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startDate := time.Now().AddDate(0, 0, -30)
+		endDate := time.Now()
+		var err error
+
+		if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+			startDate, err = time.Parse("2006-01-02", startDateStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid start_date format"))
+				return
+			}
+		}
+		if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+			endDate, err = time.Parse("2006-01-02", endDateStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid end_date format"))
+				return
+			}
+		}
+
+		params := entity.OrderFunnelParams{
+			StartDate: startDate,
+			EndDate:   endDate,
+		}
+
+		result, errors := api.service.GetOrderFunnel(ctx, params)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", result))
+	}
+}
+
+// getCohortRetention: GET /api/v1/platform/cohorts/retention
+// Query params: start_month, end_month (YYYY-MM, mặc định 12 tháng gần nhất), months (1-24, mặc định 12), repeat_days (1-365, mặc định 30)
+func (api apiController) getCohortRetention() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		now := time.Now()
+		endMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		var startMonth time.Time
+		var err error
+
+		if endMonthStr := ctx.Query("end_month"); endMonthStr != "" {
+			endMonth, err = time.Parse("2006-01", endMonthStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid end_month format"))
+				return
+			}
+		}
+		if startMonthStr := ctx.Query("start_month"); startMonthStr != "" {
+			startMonth, err = time.Parse("2006-01", startMonthStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid start_month format"))
+				return
+			}
+		} else {
+			startMonth = endMonth.AddDate(0, -11, 0)
+		}
+
+		months := 12
+		if monthsStr := ctx.Query("months"); monthsStr != "" {
+			months, err = strconv.Atoi(monthsStr)
+			if err != nil || months < 1 || months > 24 {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid months (1-24)"))
+				return
+			}
+		}
+
+		repeatDays := 30
+		if repeatDaysStr := ctx.Query("repeat_days"); repeatDaysStr != "" {
+			repeatDays, err = strconv.Atoi(repeatDaysStr)
+			if err != nil || repeatDays < 1 || repeatDays > 365 {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid repeat_days (1-365)"))
+				return
+			}
+		}
+
+		params := entity.CohortRetentionParams{
+			StartMonth: startMonth,
+			EndMonth:   endMonth,
+			Months:     months,
+			RepeatDays: repeatDays,
+		}
+
+		result, errors := api.service.GetCohortRetention(ctx, params)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", result))
+	}
+}
+
+// getBuyerRFM: GET /api/v1/platform/buyers/rfm
+// Query params: start_date, end_date (mặc định 365 ngày gần nhất, tối đa 731 ngày), segment, limit (1-100, mặc định 20), offset
+func (api apiController) getBuyerRFM() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		startDate := time.Now().AddDate(0, 0, -365)
+		endDate := time.Now()
+		var err error
+
+		if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+			startDate, err = time.Parse("2006-01-02", startDateStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid start_date format"))
+				return
+			}
+		}
+		if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+			endDate, err = time.Parse("2006-01-02", endDateStr)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid end_date format"))
+				return
+			}
+		}
+
+		limit := int32(20)
+		if limitStr := ctx.Query("limit"); limitStr != "" {
+			limitInt, err := strconv.ParseInt(limitStr, 10, 32)
+			if err != nil || limitInt < 1 || limitInt > 100 {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid limit (1-100)"))
+				return
+			}
+			limit = int32(limitInt)
+		}
+
+		offset := int32(0)
+		if offsetStr := ctx.Query("offset"); offsetStr != "" {
+			offsetInt, err := strconv.ParseInt(offsetStr, 10, 32)
+			if err != nil || offsetInt < 0 {
+				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
+				return
+			}
+			offset = int32(offsetInt)
+		}
+
+		params := entity.BuyerRFMParams{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Segment:   ctx.Query("segment"),
+			Limit:     limit,
+			Offset:    offset,
+		}
+
+		result, errors := api.service.GetBuyerRFM(ctx, params)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", result))
+	}
+}
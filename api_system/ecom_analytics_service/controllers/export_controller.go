@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	assets_api "github.com/TranVinhHien/ecom_analytics_service/assets/api"
+	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// === Xuất báo cáo CSV/XLSX ===
+
+var exportContentTypes = map[string]string{
+	entity.ExportFormatCSV:  "text/csv; charset=utf-8",
+	entity.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportRequester người yêu cầu xuất báo cáo, token người bán cũ có thể không có userId
+func exportRequester(ctx *gin.Context) string {
+	authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+	if authPayload.UserId != "" {
+		return authPayload.UserId
+	}
+	return authPayload.Sub
+}
+
+// exportList xử lý ?format=csv|xlsx (&async=true) của các API danh sách, params là bộ lọc đã parse của API đó.
+// Báo cáo nhỏ trả file ngay, báo cáo lớn (hoặc async=true) trả 202 kèm yêu cầu chạy nền để tải sau.
+func (api apiController) exportList(ctx *gin.Context, params interface{}) {
+	req := entity.ExportRequest{
+		Format:      ctx.Query("format"),
+		Async:       ctx.Query("async") == "true",
+		RequestedBy: exportRequester(ctx),
+		Params:      params,
+	}
+
+	started := false
+	open := func(fileName string) io.Writer {
+		started = true
+		ctx.Header("Content-Type", exportContentTypes[req.Format])
+		ctx.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+		ctx.Status(http.StatusOK)
+		return ctx.Writer
+	}
+
+	job, errors := api.service.ExportReport(ctx, req, open)
+	if errors != nil {
+		if started {
+			// đã gửi header và một phần file, chỉ còn cách ngắt response
+			ctx.Error(errors)
+			ctx.Abort()
+			return
+		}
+		ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+		return
+	}
+	if job != nil {
+		ctx.JSON(http.StatusAccepted, assets_api.SimpSuccessResponse("success", withDownloadURL(job)))
+	}
+}
+
+// withDownloadURL thêm link tải cho yêu cầu đã xuất xong
+func withDownloadURL(job *entity.ExportJobResponse) *entity.ExportJobResponse {
+	if job.Status == entity.ExportStatusDone {
+		job.DownloadURL = "/v1/exports/" + job.ID + "/download"
+	}
+	return job
+}
+
+// getExportJob: GET /api/v1/exports/:job_id
+func (api apiController) getExportJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		result, errors := api.service.GetExportJob(ctx, exportRequester(ctx), ctx.Param("job_id"))
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", withDownloadURL(result)))
+	}
+}
+
+// downloadExport: GET /api/v1/exports/:job_id/download
+func (api apiController) downloadExport() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		path, fileName, errors := api.service.GetExportFile(ctx, exportRequester(ctx), ctx.Param("job_id"))
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.FileAttachment(path, fileName)
+	}
+}
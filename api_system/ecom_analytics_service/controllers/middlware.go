@@ -6,6 +6,8 @@ import (
 
 	assets_api "github.com/TranVinhHien/ecom_analytics_service/assets/api"
 	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
+	"github.com/TranVinhHien/ecom_analytics_service/services"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +16,7 @@ const (
 	authorizationKey     = "authorization"
 	authorizationType    = "bearer"
 	authorizationPayload = "authorization_payload"
+	shopAccessKey        = "shop_access"
 )
 
 func authorization(jwt token.Maker) gin.HandlerFunc {
@@ -58,18 +61,35 @@ func checkRole(role string) gin.HandlerFunc {
 		}
 	}
 }
-func getShopID() gin.HandlerFunc {
+
+// getShopID xác định shop của người gọi từ token (claim shopId) hoặc bảng nhân sự shop,
+// query shop_id chỉ dùng để chọn shop khi người dùng thuộc nhiều shop, shop không thuộc quyền thì trả 403
+func getShopID(service services.ServiceUseCase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		_, exists := ctx.Get(authorizationPayload)
+		payload, exists := ctx.Get(authorizationPayload)
 		if !exists {
 			ctx.AbortWithStatusJSON(401, assets_api.ResponseError(401, "token không tồn tại"))
 			return
 		}
-		shop_id := ctx.Query("shop_id")
-		if shop_id == "" {
-			ctx.AbortWithStatusJSON(400, assets_api.ResponseError(400, "shop_id không được để trống"))
+		access, err := service.ResolveShopAccess(ctx, payload.(*token.Payload), ctx.Query("shop_id"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
 		}
-		ctx.Set("shop_id", shop_id)
+		ctx.Set("shop_id", access.ShopID)
+		ctx.Set(shopAccessKey, *access)
+	}
+}
+
+// checkShopRole chỉ cho phép nhân sự shop có một trong các vai trò (chạy sau getShopID)
+func checkShopRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		access := ctx.MustGet(shopAccessKey).(entity.ShopAccess)
+		for _, role := range roles {
+			if access.Role == role {
+				return
+			}
+		}
+		ctx.AbortWithStatusJSON(403, assets_api.ResponseError(403, fmt.Sprintf("không có quyền truy cập, chức năng này chỉ dành cho %s của shop", strings.Join(roles, ", "))))
 	}
 }
@@ -59,7 +59,7 @@ func (api apiController) getPlatformOverview() gin.HandlerFunc {
 // === Nhóm 2: Quản lý Đơn hàng ===
 
 // listPlatformOrders: GET /api/v1/platform/orders
-// Query params: shop_id, user_id, status, start_date, end_date, limit, offset
+// Query params: shop_id, user_id, status, start_date, end_date, limit, offset, pagination=cursor, cursor
 func (api apiController) listPlatformOrders() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		params := entity.ListPlatformOrdersParams{
@@ -115,6 +115,7 @@ func (api apiController) listPlatformOrders() gin.HandlerFunc {
 			}
 			params.Offset = int32(offset)
 		}
+		params.CursorParams = cursorParams(ctx)
 
 		result, errors := api.service.ListPlatformOrders(ctx, params)
 		if errors != nil {
@@ -148,36 +149,10 @@ func (api apiController) getEnrichedPlatformOrder() gin.HandlerFunc {
 // === Nhóm 3: Quản lý Tài chính ===
 
 // getPlatformRevenueTimeseries: GET /api/v1/platform/finance/revenue-timeseries
-// Query params: start_date, end_date
+// Query params: start_date, end_date, granularity, timezone, compare
 func (api apiController) getPlatformRevenueTimeseries() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		startDateStr := ctx.Query("start_date")
-		endDateStr := ctx.Query("end_date")
-
-		var startDate, endDate time.Time
-		var err error
-
-		if startDateStr != "" {
-			startDate, err = time.Parse("2006-01-02", startDateStr)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid start_date format"))
-				return
-			}
-		} else {
-			startDate = time.Now().AddDate(0, 0, -30)
-		}
-
-		if endDateStr != "" {
-			endDate, err = time.Parse("2006-01-02", endDateStr)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid end_date format"))
-				return
-			}
-		} else {
-			endDate = time.Now()
-		}
-
-		result, errors := api.service.GetPlatformRevenueTimeseries(ctx, startDate, endDate)
+		result, errors := api.service.GetPlatformRevenueTimeseries(ctx, timeseriesParams(ctx))
 		if errors != nil {
 			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
 			return
@@ -188,12 +163,11 @@ func (api apiController) getPlatformRevenueTimeseries() gin.HandlerFunc {
 }
 
 // listPlatformTransactions: GET /api/v1/platform/finance/transactions
-// Query params: type, status, start_date, end_date, limit, offset
+// Query params: type, status, start_date, end_date, limit, cursor, format=csv|xlsx, async
 func (api apiController) listPlatformTransactions() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		params := entity.ListPlatformTransactionsParams{
-			Limit:  20,
-			Offset: 0,
+			Limit: 20,
 		}
 
 		if typeStr := ctx.Query("type"); typeStr != "" {
@@ -231,13 +205,11 @@ func (api apiController) listPlatformTransactions() gin.HandlerFunc {
 			params.Limit = int32(limit)
 		}
 
-		if offsetStr := ctx.Query("offset"); offsetStr != "" {
-			offset, err := strconv.ParseInt(offsetStr, 10, 32)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
-				return
-			}
-			params.Offset = int32(offset)
+		params.Cursor = ctx.Query("cursor")
+
+		if ctx.Query("format") != "" {
+			api.exportList(ctx, params)
+			return
 		}
 
 		result, errors := api.service.ListPlatformTransactions(ctx, params)
@@ -251,12 +223,11 @@ func (api apiController) listPlatformTransactions() gin.HandlerFunc {
 }
 
 // listPlatformSettlements: GET /api/v1/platform/finance/settlements
-// Query params: status, start_date, end_date, limit, offset
+// Query params: status, start_date, end_date, limit, cursor, format=csv|xlsx, async
 func (api apiController) listPlatformSettlements() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		params := entity.ListPlatformSettlementsParams{
-			Limit:  20,
-			Offset: 0,
+			Limit: 20,
 		}
 
 		if status := ctx.Query("status"); status != "" {
@@ -290,13 +261,11 @@ func (api apiController) listPlatformSettlements() gin.HandlerFunc {
 			params.Limit = int32(limit)
 		}
 
-		if offsetStr := ctx.Query("offset"); offsetStr != "" {
-			offset, err := strconv.ParseInt(offsetStr, 10, 32)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
-				return
-			}
-			params.Offset = int32(offset)
+		params.Cursor = ctx.Query("cursor")
+
+		if ctx.Query("format") != "" {
+			api.exportList(ctx, params)
+			return
 		}
 
 		result, errors := api.service.ListPlatformSettlements(ctx, params)
@@ -310,12 +279,11 @@ func (api apiController) listPlatformSettlements() gin.HandlerFunc {
 }
 
 // listPlatformLedgers: GET /api/v1/platform/finance/ledgers
-// Query params: owner_type, limit, offset
+// Query params: owner_type, limit, cursor
 func (api apiController) listPlatformLedgers() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		params := entity.ListPlatformLedgersParams{
-			Limit:  20,
-			Offset: 0,
+			Limit: 20,
 		}
 
 		if ownerType := ctx.Query("owner_type"); ownerType != "" {
@@ -331,14 +299,7 @@ func (api apiController) listPlatformLedgers() gin.HandlerFunc {
 			params.Limit = int32(limit)
 		}
 
-		if offsetStr := ctx.Query("offset"); offsetStr != "" {
-			offset, err := strconv.ParseInt(offsetStr, 10, 32)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
-				return
-			}
-			params.Offset = int32(offset)
-		}
+		params.Cursor = ctx.Query("cursor")
 
 		result, errors := api.service.ListPlatformLedgers(ctx, params)
 		if errors != nil {
@@ -351,7 +312,7 @@ func (api apiController) listPlatformLedgers() gin.HandlerFunc {
 }
 
 // listLedgerEntries: GET /api/v1/platform/finance/ledgers/:ledger_id/entries
-// Query params: limit, offset
+// Query params: limit, cursor
 func (api apiController) listLedgerEntries() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ledgerID := ctx.Param("ledger_id")
@@ -361,7 +322,6 @@ func (api apiController) listLedgerEntries() gin.HandlerFunc {
 		}
 
 		limit := int32(20)
-		offset := int32(0)
 
 		if limitStr := ctx.Query("limit"); limitStr != "" {
 			limitInt, err := strconv.ParseInt(limitStr, 10, 32)
@@ -372,16 +332,7 @@ func (api apiController) listLedgerEntries() gin.HandlerFunc {
 			limit = int32(limitInt)
 		}
 
-		if offsetStr := ctx.Query("offset"); offsetStr != "" {
-			offsetInt, err := strconv.ParseInt(offsetStr, 10, 32)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
-				return
-			}
-			offset = int32(offsetInt)
-		}
-
-		result, errors := api.service.ListLedgerEntries(ctx, ledgerID, limit, offset)
+		result, errors := api.service.ListLedgerEntries(ctx, ledgerID, limit, ctx.Query("cursor"))
 		if errors != nil {
 			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
 			return
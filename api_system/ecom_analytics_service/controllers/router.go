@@ -27,11 +27,10 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 	})
 
 	// === Nhóm I: API cho Nhà bán hàng (SHOP) ===
-	// Tất cả API trong nhóm này yêu cầu đăng nhập và có vai trò "SHOP"
+	// Tất cả API trong nhóm này yêu cầu đăng nhập và là chủ shop (claim shopId) hoặc nhân sự của shop
 	shop := group.Group("/shop").
 		Use(authorization(api.jwt)).
-		Use(checkRole("ROLE_SELLER")).
-		Use(getShopID()) // Middleware để lấy shop_id và lưu vào context
+		Use(getShopID(api.service)) // Middleware xác định shop_id từ token/nhân sự shop và lưu vào context
 	{
 		// Nhóm 1: Tổng quan
 		shop.GET("/overview", api.getShopOverview())
@@ -54,6 +53,11 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 
 		// Nhóm 5: Xếp hạng
 		shop.GET("/ranking/products", api.getShopRankingProducts())
+
+		// Nhóm 6: Nhân sự shop (OWNER, MANAGER xem và quản lý, VIEWER chỉ xem thống kê)
+		shop.GET("/staff", checkShopRole("OWNER", "MANAGER"), api.listShopStaff())
+		shop.PUT("/staff", checkShopRole("OWNER", "MANAGER"), api.addShopStaff())
+		shop.DELETE("/staff/:user_id", checkShopRole("OWNER", "MANAGER"), api.removeShopStaff())
 	}
 
 	// === Nhóm II: API cho Nền tảng (PLATFORM / ADMIN) ===
@@ -83,6 +87,7 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 		// Nhóm 5: Phân tích Shop
 		platform.GET("/shops", api.listPlatformShops())
 		platform.GET("/shops/:shop_id/detail", api.getPlatformShopDetail()) // Xem chi tiết 1 shop
+		platform.PUT("/shops/:shop_id/owner", api.setShopOwner())           // Gán chủ shop (xem thống kê shop)
 
 		// Nhóm 6: Xếp hạng Toàn Sàn
 		platform.GET("/ranking/shops", api.getPlatformRankingShops())
@@ -107,9 +112,22 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 		platform.GET("/agent-analytics/purchase-intent", api.getPurchaseIntentStats())
 		platform.GET("/agent-analytics/top-categories", api.getTopMentionedCategories())
 
+		// Nhóm 10: Phân tích hành vi mua hàng (phễu đơn hàng, cohort, RFM)
+		platform.GET("/funnel/orders", api.getOrderFunnel())
+		platform.GET("/cohorts/retention", api.getCohortRetention())
+		platform.GET("/buyers/rfm", api.getBuyerRFM())
+
+	}
+
+	// === Nhóm III: Tải báo cáo xuất chạy nền (?format=csv|xlsx của các API danh sách) ===
+	// Chỉ người tạo yêu cầu mới xem và tải được
+	exports := group.Group("/exports").Use(authorization(api.jwt))
+	{
+		exports.GET("/:job_id", api.getExportJob())
+		exports.GET("/:job_id/download", api.downloadExport())
 	}
 
-	// === Nhóm III: API Công khai (Public) ===
+	// === Nhóm IV: API Công khai (Public) ===
 	public := group.Group("/public").Use(authorization(api.jwt))
 	{
 		// Nhóm 1 : Gửi đánh giá của chatbox
@@ -87,7 +87,7 @@ func (api apiController) getShopWalletSummary() gin.HandlerFunc {
 // === Nhóm 2: Phân tích Đơn hàng ===
 
 // listShopOrders: GET /api/v1/shop/orders
-// Query params: status, start_date, end_date, limit, offset
+// Query params: status, start_date, end_date, limit, offset, pagination=cursor, cursor, format=csv|xlsx, async
 func (api apiController) listShopOrders() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		shopID, exists := ctx.Get("shop_id")
@@ -142,6 +142,12 @@ func (api apiController) listShopOrders() gin.HandlerFunc {
 			}
 			params.Offset = int32(offset)
 		}
+		params.CursorParams = cursorParams(ctx)
+
+		if ctx.Query("format") != "" {
+			api.exportList(ctx, params)
+			return
+		}
 
 		result, errors := api.service.ListShopOrders(ctx, params)
 		if errors != nil {
@@ -248,7 +254,7 @@ func (api apiController) listShopOrderItems() gin.HandlerFunc {
 // === Nhóm 3: Phân tích Doanh thu & Dòng tiền ===
 
 // getShopRevenueTimeseries: GET /api/v1/shop/revenue/timeseries
-// Query params: start_date, end_date
+// Query params: start_date, end_date, granularity, timezone, compare
 func (api apiController) getShopRevenueTimeseries() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		shopID, exists := ctx.Get("shop_id")
@@ -257,35 +263,9 @@ func (api apiController) getShopRevenueTimeseries() gin.HandlerFunc {
 			return
 		}
 
-		startDateStr := ctx.Query("start_date")
-		endDateStr := ctx.Query("end_date")
-
-		var startDate, endDate time.Time
-		var err error
-
-		if startDateStr != "" {
-			startDate, err = time.Parse("2006-01-02", startDateStr)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid start_date format"))
-				return
-			}
-		} else {
-			startDate = time.Now().AddDate(0, 0, -30)
-		}
-
-		if endDateStr != "" {
-			endDate, err = time.Parse("2006-01-02", endDateStr)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid end_date format"))
-				return
-			}
-		} else {
-			endDate = time.Now()
-		}
-
-		result, errors := api.service.GetShopRevenueTimeseries(ctx, shopID.(string), startDate, endDate)
+		result, errors := api.service.GetShopRevenueTimeseries(ctx, shopID.(string), timeseriesParams(ctx))
 		if errors != nil {
-			ctx.JSON(http.StatusInternalServerError, assets_api.ResponseError(http.StatusInternalServerError, errors.Error()))
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
 			return
 		}
 
@@ -294,7 +274,7 @@ func (api apiController) getShopRevenueTimeseries() gin.HandlerFunc {
 }
 
 // listShopWalletLedgerEntries: GET /api/v1/shop/wallet/ledger-entries
-// Query params: limit, offset
+// Query params: limit, cursor, format=csv|xlsx, async
 func (api apiController) listShopWalletLedgerEntries() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		shopID, exists := ctx.Get("shop_id")
@@ -306,7 +286,6 @@ func (api apiController) listShopWalletLedgerEntries() gin.HandlerFunc {
 		params := entity.ListWalletLedgerEntriesParams{
 			ShopID: shopID.(string),
 			Limit:  20,
-			Offset: 0,
 		}
 
 		if limitStr := ctx.Query("limit"); limitStr != "" {
@@ -318,13 +297,11 @@ func (api apiController) listShopWalletLedgerEntries() gin.HandlerFunc {
 			params.Limit = int32(limit)
 		}
 
-		if offsetStr := ctx.Query("offset"); offsetStr != "" {
-			offset, err := strconv.ParseInt(offsetStr, 10, 32)
-			if err != nil {
-				ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "invalid offset"))
-				return
-			}
-			params.Offset = int32(offset)
+		params.Cursor = ctx.Query("cursor")
+
+		if ctx.Query("format") != "" {
+			api.exportList(ctx, params)
+			return
 		}
 
 		result, errors := api.service.ListShopWalletLedgerEntries(ctx, params)
@@ -338,7 +315,7 @@ func (api apiController) listShopWalletLedgerEntries() gin.HandlerFunc {
 }
 
 // listShopSettlements: GET /api/v1/shop/settlements
-// Query params: status, start_date, end_date, limit, offset
+// Query params: status, start_date, end_date, limit, offset, format=csv|xlsx, async
 func (api apiController) listShopSettlements() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		shopID, exists := ctx.Get("shop_id")
@@ -393,6 +370,11 @@ func (api apiController) listShopSettlements() gin.HandlerFunc {
 			params.Offset = int32(offset)
 		}
 
+		if ctx.Query("format") != "" {
+			api.exportList(ctx, params)
+			return
+		}
+
 		result, errors := api.service.ListShopSettlements(ctx, params)
 		if errors != nil {
 			ctx.JSON(http.StatusInternalServerError, assets_api.ResponseError(http.StatusInternalServerError, errors.Error()))
@@ -626,3 +608,24 @@ func (api apiController) getShopRankingProducts() gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", result))
 	}
 }
+
+// cursorParams đọc tham số phân trang cursor: bật khi có cursor hoặc pagination=cursor,
+// lúc đó offset bị bỏ qua và trang tiếp theo lấy bằng next_cursor/prev_cursor trong response
+func cursorParams(ctx *gin.Context) entity.CursorParams {
+	cursor := ctx.Query("cursor")
+	return entity.CursorParams{
+		CursorMode: cursor != "" || ctx.Query("pagination") == "cursor",
+		Cursor:     cursor,
+	}
+}
+
+// timeseriesParams đọc tham số chung của các API timeseries doanh thu, service kiểm tra giá trị
+func timeseriesParams(ctx *gin.Context) entity.TimeseriesParams {
+	return entity.TimeseriesParams{
+		StartDate:   ctx.Query("start_date"),
+		EndDate:     ctx.Query("end_date"),
+		Granularity: ctx.Query("granularity"),
+		Timezone:    ctx.Query("timezone"),
+		Compare:     ctx.Query("compare"),
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+
+	assets_api "github.com/TranVinhHien/ecom_analytics_service/assets/api"
+	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// === Nhóm 6: Nhân sự Shop ===
+
+// listShopStaff: GET /api/v1/shop/staff
+func (api apiController) listShopStaff() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		access := ctx.MustGet(shopAccessKey).(entity.ShopAccess)
+
+		result, errors := api.service.ListShopStaff(ctx, access.ShopID)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", result))
+	}
+}
+
+// addShopStaff: PUT /api/v1/shop/staff
+// Body: user_id, role (MANAGER | VIEWER). Người dùng đã là nhân sự thì đổi vai trò
+func (api apiController) addShopStaff() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		access := ctx.MustGet(shopAccessKey).(entity.ShopAccess)
+
+		var req entity.AddShopStaffRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "dữ liệu không hợp lệ: "+err.Error()))
+			return
+		}
+
+		errors := api.service.AddShopStaff(ctx, access, authPayload.UserId, req)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", nil))
+	}
+}
+
+// removeShopStaff: DELETE /api/v1/shop/staff/:user_id
+func (api apiController) removeShopStaff() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		access := ctx.MustGet(shopAccessKey).(entity.ShopAccess)
+
+		errors := api.service.RemoveShopStaff(ctx, access, authPayload.UserId, ctx.Param("user_id"))
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", nil))
+	}
+}
+
+// setShopOwner: PUT /api/v1/platform/shops/:shop_id/owner
+// Admin gán chủ shop cho người bán có token không kèm claim shopId
+func (api apiController) setShopOwner() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req entity.SetShopOwnerRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "dữ liệu không hợp lệ: "+err.Error()))
+			return
+		}
+
+		errors := api.service.SetShopOwner(ctx, authPayload.UserId, ctx.Param("shop_id"), req)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("success", nil))
+	}
+}
@@ -0,0 +1,18 @@
+-- =================================================================
+-- Nhân sự của shop được xem thống kê (API /shop/*)
+-- OWNER: chủ shop, quản lý toàn bộ nhân sự
+-- MANAGER: quản lý, thêm/xóa nhân viên VIEWER
+-- VIEWER: nhân viên, chỉ xem thống kê
+-- Người bán có claim shopId trong JWT được coi là OWNER của shop đó mà không cần dòng ở bảng này.
+-- =================================================================
+CREATE TABLE `shop_staff` (
+  `shop_id` CHAR(36) NOT NULL COMMENT 'ID của shop',
+  `user_id` CHAR(36) NOT NULL COMMENT 'ID người dùng (claim userId trong JWT)',
+  `role` ENUM('OWNER', 'MANAGER', 'VIEWER') NOT NULL COMMENT 'Vai trò trong shop',
+  `created_by` CHAR(36) NOT NULL COMMENT 'Người cấp quyền',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`shop_id`, `user_id`),
+  KEY `idx_user_id` (`user_id`)
+) ENGINE=InnoDB COMMENT='Nhân sự của shop và vai trò khi xem thống kê shop';
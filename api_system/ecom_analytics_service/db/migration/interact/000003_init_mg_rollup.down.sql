@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `rollup_dirty_days`;
+DROP TABLE IF EXISTS `rollup_voucher_daily`;
+DROP TABLE IF EXISTS `rollup_category_daily`;
+DROP TABLE IF EXISTS `rollup_product_daily`;
+DROP TABLE IF EXISTS `rollup_shop_daily`;
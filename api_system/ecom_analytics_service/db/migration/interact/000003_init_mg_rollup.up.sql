@@ -0,0 +1,69 @@
+-- =================================================================
+-- Bảng tổng hợp theo ngày (rollup) cho các API dashboard
+-- Ngày thống kê (stat_date) tính theo giờ Việt Nam (+07:00).
+-- Dữ liệu được tính lại từ order_db/transaction_db mỗi khi có sự kiện Kafka
+-- (order.status_changed, payment.*, settlement.updated) đánh dấu ngày cần tính lại,
+-- hoặc bằng lệnh backfill.
+-- =================================================================
+
+CREATE TABLE `rollup_shop_daily` (
+  `stat_date` DATE NOT NULL COMMENT 'Ngày thống kê',
+  `shop_id` CHAR(36) NOT NULL COMMENT 'ID của shop',
+  `placed_orders` INT NOT NULL DEFAULT 0 COMMENT 'Số đơn tạo trong ngày (trừ CANCELLED, AWAITING_PAYMENT)',
+  `placed_gmv` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'GMV của các đơn tạo trong ngày',
+  `processing_orders` INT NOT NULL DEFAULT 0 COMMENT 'Số đơn tạo trong ngày hiện đang PROCESSING',
+  `completed_orders` INT NOT NULL DEFAULT 0 COMMENT 'Số đơn hoàn thành trong ngày (theo completed_at)',
+  `completed_gmv` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'GMV của các đơn hoàn thành trong ngày',
+  `net_revenue` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Doanh thu thuần của shop đã đối soát trong ngày (theo settled_at)',
+  `commission_revenue` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Phí hoa hồng sàn thu từ shop trong ngày',
+  `shipping_revenue` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Phí vận chuyển sàn thu từ shop trong ngày',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`stat_date`, `shop_id`),
+  KEY `idx_shop_date` (`shop_id`, `stat_date`)
+) ENGINE=InnoDB COMMENT='Tổng hợp đơn hàng và doanh thu theo ngày của từng shop';
+
+CREATE TABLE `rollup_product_daily` (
+  `stat_date` DATE NOT NULL COMMENT 'Ngày thống kê (theo completed_at của đơn)',
+  `product_id` CHAR(36) NOT NULL COMMENT 'UUID của sản phẩm',
+  `sku_id` CHAR(36) NOT NULL COMMENT 'UUID của SKU',
+  `shop_id` CHAR(36) NOT NULL COMMENT 'ID của shop bán',
+  `category_id` VARCHAR(36) NOT NULL DEFAULT '' COMMENT 'Danh mục của sản phẩm, rỗng nếu đơn cũ chưa lưu danh mục',
+  `product_name` TEXT NOT NULL COMMENT 'Tên sản phẩm (snapshot)',
+  `sku_attributes` TEXT DEFAULT NULL COMMENT 'Thuộc tính SKU (snapshot)',
+  `quantity` INT NOT NULL DEFAULT 0 COMMENT 'Số lượng bán',
+  `revenue` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Doanh thu (tổng total_price)',
+  `order_count` INT NOT NULL DEFAULT 0 COMMENT 'Số đơn shop có sản phẩm này',
+
+  PRIMARY KEY (`stat_date`, `product_id`, `sku_id`, `shop_id`),
+  KEY `idx_shop_date` (`shop_id`, `stat_date`)
+) ENGINE=InnoDB COMMENT='Tổng hợp sản phẩm bán ra theo ngày';
+
+CREATE TABLE `rollup_category_daily` (
+  `stat_date` DATE NOT NULL COMMENT 'Ngày thống kê (theo completed_at của đơn)',
+  `category_id` VARCHAR(36) NOT NULL DEFAULT '' COMMENT 'ID danh mục, rỗng là chưa phân loại',
+  `quantity` INT NOT NULL DEFAULT 0 COMMENT 'Số lượng bán',
+  `revenue` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Doanh thu (tổng total_price)',
+  `order_count` INT NOT NULL DEFAULT 0 COMMENT 'Số đơn shop có sản phẩm thuộc danh mục',
+
+  PRIMARY KEY (`stat_date`, `category_id`)
+) ENGINE=InnoDB COMMENT='Tổng hợp doanh thu theo danh mục theo ngày';
+
+CREATE TABLE `rollup_voucher_daily` (
+  `stat_date` DATE NOT NULL COMMENT 'Ngày thống kê (theo used_at)',
+  `voucher_id` CHAR(36) NOT NULL COMMENT 'ID voucher',
+  `owner_type` VARCHAR(20) NOT NULL COMMENT 'SHOP hoặc PLATFORM',
+  `owner_id` CHAR(36) NOT NULL COMMENT 'ID shop sở hữu (hoặc ID sàn)',
+  `usage_count` INT NOT NULL DEFAULT 0 COMMENT 'Số lượt sử dụng',
+  `discount_amount` DECIMAL(18, 2) NOT NULL DEFAULT 0.00 COMMENT 'Tổng tiền đã giảm',
+
+  PRIMARY KEY (`stat_date`, `voucher_id`),
+  KEY `idx_owner_date` (`owner_type`, `owner_id`, `stat_date`)
+) ENGINE=InnoDB COMMENT='Tổng hợp lượt dùng voucher theo ngày';
+
+CREATE TABLE `rollup_dirty_days` (
+  `stat_date` DATE NOT NULL COMMENT 'Ngày cần tính lại',
+  `marked_at` TIMESTAMP(6) NOT NULL COMMENT 'Lần đánh dấu gần nhất',
+
+  PRIMARY KEY (`stat_date`)
+) ENGINE=InnoDB COMMENT='Hàng đợi các ngày cần tính lại bảng tổng hợp';
@@ -0,0 +1,24 @@
+-- =================================================================
+-- Yêu cầu xuất báo cáo CSV/XLSX chạy nền
+-- File kết quả nằm trong thư mục EXPORT_DIR, tên file là <id>.<format>
+-- PENDING: đang chờ, RUNNING: đang ghi file, DONE: tải được, FAILED: lỗi
+-- =================================================================
+CREATE TABLE `export_jobs` (
+  `id` CHAR(36) NOT NULL COMMENT 'UUID, Khóa chính',
+  `report` VARCHAR(50) NOT NULL COMMENT 'Loại báo cáo (shop_orders, shop_settlements, ...)',
+  `format` ENUM('csv', 'xlsx') NOT NULL COMMENT 'Định dạng file',
+  `status` ENUM('PENDING', 'RUNNING', 'DONE', 'FAILED') NOT NULL DEFAULT 'PENDING',
+  `requested_by` CHAR(36) NOT NULL COMMENT 'Người yêu cầu, chỉ người này được tải file',
+  `shop_id` CHAR(36) NULL COMMENT 'Shop của báo cáo, NULL với báo cáo toàn sàn',
+  `file_name` VARCHAR(255) NOT NULL COMMENT 'Tên file khi tải về',
+  `row_count` INT NOT NULL DEFAULT 0 COMMENT 'Số dòng dữ liệu đã ghi',
+  `error_message` TEXT NULL COMMENT 'Lý do lỗi khi FAILED',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `finished_at` TIMESTAMP NULL COMMENT 'Thời điểm xong (DONE hoặc FAILED)',
+  `expires_at` TIMESTAMP NULL COMMENT 'Sau thời điểm này file và yêu cầu bị xóa',
+
+  PRIMARY KEY (`id`),
+  KEY `idx_requested_by` (`requested_by`, `created_at`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB COMMENT='Yêu cầu xuất báo cáo CSV/XLSX chạy nền';
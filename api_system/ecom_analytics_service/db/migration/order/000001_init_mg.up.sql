@@ -89,6 +89,7 @@ CREATE TABLE `order_items` (
   `product_name_snapshot` TEXT NOT NULL COMMENT 'Tên sản phẩm tại thời điểm mua',
   `product_image_snapshot` TEXT DEFAULT NULL COMMENT 'URL hình ảnh sản phẩm tại thời điểm mua',
   `sku_attributes_snapshot` TEXT DEFAULT NULL COMMENT 'Các thuộc tính của SKU (Màu, Size...) tại thời điểm mua',
+  `category_id` VARCHAR(36) DEFAULT NULL COMMENT 'Danh mục của sản phẩm tại thời điểm mua',
   PRIMARY KEY (`id`),
   KEY `idx_shop_order_id` (`shop_order_id`),
   CONSTRAINT `fk_order_items_shop_order` FOREIGN KEY (`shop_order_id`) REFERENCES `shop_orders` (`id`) ON DELETE RESTRICT
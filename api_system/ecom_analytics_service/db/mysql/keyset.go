@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// KeysetParams vị trí trang khi phân trang keyset theo (cột thời gian, id) giảm dần.
+// AfterID rỗng là trang đầu; Backward lấy các bản ghi đứng trước bản ghi mốc (trang trước).
+// AfterTime không Valid khi cột thời gian của bản ghi mốc là NULL, MySQL xếp NULL cuối cùng khi giảm dần.
+type KeysetParams struct {
+	AfterTime sql.NullTime
+	AfterID   string
+	Backward  bool
+	Limit     int32
+}
+
+// condition điều kiện "sau bản ghi mốc" theo chiều đọc, rỗng ở trang đầu
+func (k KeysetParams) condition(timeCol, idCol string) (string, []interface{}) {
+	if k.AfterID == "" {
+		return "", nil
+	}
+	if !k.AfterTime.Valid {
+		// mốc là bản ghi NULL: đi tiếp chỉ còn các bản ghi NULL id nhỏ hơn,
+		// đi lùi là mọi bản ghi có thời gian và các bản ghi NULL id lớn hơn
+		if k.Backward {
+			return "(" + timeCol + " IS NOT NULL OR " + idCol + " > ?)", []interface{}{k.AfterID}
+		}
+		return "(" + timeCol + " IS NULL AND " + idCol + " < ?)", []interface{}{k.AfterID}
+	}
+	op := "<"
+	if k.Backward {
+		op = ">"
+	}
+	cond := timeCol + " " + op + " ? OR (" + timeCol + " = ? AND " + idCol + " " + op + " ?)"
+	if !k.Backward {
+		cond += " OR " + timeCol + " IS NULL"
+	}
+	return "(" + cond + ")", []interface{}{k.AfterTime.Time, k.AfterTime.Time, k.AfterID}
+}
+
+// query ghép điều kiện lọc, điều kiện keyset, ORDER BY và LIMIT vào câu SELECT.
+// Khi Backward kết quả được đọc theo chiều tăng dần, service sẽ đảo lại.
+func (k KeysetParams) query(base string, conditions []string, args []interface{}, timeCol, idCol string) (string, []interface{}) {
+	if cond, condArgs := k.condition(timeCol, idCol); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+	if len(conditions) > 0 {
+		base += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	dir := "DESC"
+	if k.Backward {
+		dir = "ASC"
+	}
+	base += " ORDER BY " + timeCol + " " + dir + ", " + idCol + " " + dir + " LIMIT ?"
+	return base, append(args, k.Limit)
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeysetQueryFirstPage(t *testing.T) {
+	k := KeysetParams{Limit: 21}
+	query, args := k.query("SELECT * FROM transactions", []string{"type = ?"}, []interface{}{"PAYMENT"}, "created_at", "id")
+	require.Equal(t, "SELECT * FROM transactions WHERE type = ? ORDER BY created_at DESC, id DESC LIMIT ?", query)
+	require.Equal(t, []interface{}{"PAYMENT", int32(21)}, args)
+}
+
+func TestKeysetQueryForward(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	k := KeysetParams{AfterTime: sql.NullTime{Time: at, Valid: true}, AfterID: "t-9", Limit: 11}
+	query, args := k.query("SELECT * FROM shop_order_settlements", nil, nil, "order_completed_at", "id")
+	// các bản ghi NULL xếp cuối khi giảm dần nên vẫn thuộc các trang sau
+	require.Equal(t, "SELECT * FROM shop_order_settlements WHERE (order_completed_at < ? OR (order_completed_at = ? AND id < ?) OR order_completed_at IS NULL) ORDER BY order_completed_at DESC, id DESC LIMIT ?", query)
+	require.Equal(t, []interface{}{at, at, "t-9", int32(11)}, args)
+}
+
+func TestKeysetQueryBackward(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	k := KeysetParams{AfterTime: sql.NullTime{Time: at, Valid: true}, AfterID: "t-9", Backward: true, Limit: 11}
+	query, _ := k.query("SELECT * FROM t", nil, nil, "created_at", "id")
+	require.Equal(t, "SELECT * FROM t WHERE (created_at > ? OR (created_at = ? AND id > ?)) ORDER BY created_at ASC, id ASC LIMIT ?", query)
+}
+
+func TestKeysetQueryNullAnchor(t *testing.T) {
+	forward := KeysetParams{AfterID: "s-5", Limit: 11}
+	query, args := forward.query("SELECT * FROM t", nil, nil, "order_completed_at", "id")
+	require.Equal(t, "SELECT * FROM t WHERE (order_completed_at IS NULL AND id < ?) ORDER BY order_completed_at DESC, id DESC LIMIT ?", query)
+	require.Equal(t, []interface{}{"s-5", int32(11)}, args)
+
+	backward := KeysetParams{AfterID: "s-5", Backward: true, Limit: 11}
+	query, _ = backward.query("SELECT * FROM t", nil, nil, "order_completed_at", "id")
+	require.Equal(t, "SELECT * FROM t WHERE (order_completed_at IS NOT NULL OR id > ?) ORDER BY order_completed_at ASC, id ASC LIMIT ?", query)
+}
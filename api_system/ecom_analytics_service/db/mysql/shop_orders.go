@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+)
+
+// ShopOrderKeysetParams tham số lấy shop_orders theo cursor, sắp xếp (created_at, id) giảm dần.
+// AfterID rỗng là trang đầu; Backward lấy các đơn mới hơn bản ghi mốc (trang trước).
+type ShopOrderKeysetParams struct {
+	ShopID         sql.NullString
+	Status         db_order.NullShopOrdersStatus
+	StartDate      sql.NullTime
+	EndDate        sql.NullTime
+	AfterCreatedAt time.Time
+	AfterID        string
+	Backward       bool
+	Limit          int32
+}
+
+// ListShopOrdersKeyset dùng chung cho danh sách đơn của shop và của sàn, thay cho LIMIT/OFFSET.
+// Khi Backward, kết quả được đọc theo chiều tăng dần, service sẽ đảo lại.
+func (q *SQLStoreOrder) ListShopOrdersKeyset(ctx context.Context, params ShopOrderKeysetParams) ([]db_order.ShopOrders, error) {
+	query := `
+		SELECT
+			id, shop_order_code, order_id, shop_id, status, subtotal, total_discount, total_amount,
+			shop_voucher_code, shop_voucher_discount, shipping_fee, shipping_method, tracking_code,
+			cancellation_reason, created_at, updated_at, paid_at, processing_at, shipped_at,
+			completed_at, cancelled_at
+		FROM shop_orders
+	`
+	var conditions []string
+	var args []interface{}
+	if params.ShopID.Valid {
+		conditions = append(conditions, "shop_id = ?")
+		args = append(args, params.ShopID.String)
+	}
+	if params.Status.Valid {
+		conditions = append(conditions, "status = ?")
+		args = append(args, params.Status.ShopOrdersStatus)
+	}
+	// giống các query sqlc: chỉ lọc theo ngày khi có đủ cả hai mốc
+	if params.StartDate.Valid && params.EndDate.Valid {
+		conditions = append(conditions, "created_at BETWEEN ? AND ?")
+		args = append(args, params.StartDate.Time, params.EndDate.Time)
+	}
+	op, dir := "<", "DESC"
+	if params.Backward {
+		op, dir = ">", "ASC"
+	}
+	if params.AfterID != "" {
+		conditions = append(conditions, "(created_at "+op+" ? OR (created_at = ? AND id "+op+" ?))")
+		args = append(args, params.AfterCreatedAt, params.AfterCreatedAt, params.AfterID)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at " + dir + ", id " + dir + " LIMIT ?"
+	args = append(args, params.Limit)
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db_order.ShopOrders
+	for rows.Next() {
+		var i db_order.ShopOrders
+		if err := rows.Scan(
+			&i.ID, &i.ShopOrderCode, &i.OrderID, &i.ShopID, &i.Status, &i.Subtotal, &i.TotalDiscount, &i.TotalAmount,
+			&i.ShopVoucherCode, &i.ShopVoucherDiscount, &i.ShippingFee, &i.ShippingMethod, &i.TrackingCode,
+			&i.CancellationReason, &i.CreatedAt, &i.UpdatedAt, &i.PaidAt, &i.ProcessingAt, &i.ShippedAt,
+			&i.CompletedAt, &i.CancelledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 
 	db_agent_ai_db "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/agent_ai_db"
 	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
@@ -16,6 +18,7 @@ type SQLStoreOrder struct {
 
 type StoreOrder interface {
 	db_order.Querier
+	ListShopOrdersKeyset(ctx context.Context, params ShopOrderKeysetParams) ([]db_order.ShopOrders, error)
 }
 
 // create new store
@@ -34,6 +37,10 @@ type SQLStoreTransaction struct {
 
 type StoreTransaction interface {
 	db_transaction.Querier
+	ListLedgerEntriesKeyset(ctx context.Context, params LedgerEntryKeysetParams) ([]db_transaction.LedgerEntries, error)
+	ListTransactionsKeyset(ctx context.Context, params TransactionKeysetParams) ([]db_transaction.Transactions, error)
+	ListSettlementsKeyset(ctx context.Context, params SettlementKeysetParams) ([]db_transaction.ShopOrderSettlements, error)
+	ListLedgersKeyset(ctx context.Context, params LedgerKeysetParams) ([]db_transaction.AccountLedgers, error)
 }
 
 // create new store
@@ -52,6 +59,7 @@ type SQLStoreInteract struct {
 
 type StoreInteract interface {
 	db_interact.Querier
+	ExecTS(ctx context.Context, fn func(tx db_interact.Querier) error) error
 }
 
 // create new store
@@ -63,6 +71,24 @@ func NewStoreInteract(connectPool *sql.DB) StoreInteract {
 	}
 }
 
+// ExecTS chạy fn trong 1 transaction của database interact
+func (s *SQLStoreInteract) ExecTS(ctx context.Context, fn func(tx db_interact.Querier) error) error {
+	tx, err := s.connPool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	q := db_interact.New(tx)
+	err = fn(q)
+	if err != nil {
+		if errTran := tx.Rollback(); errTran != nil {
+			return fmt.Errorf("transaction error %v ,rollback trancsaction error : %v", err, errTran)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
 type SQLStoreAgentAIDB struct {
 	*db_agent_ai_db.Queries
 	connPool *sql.DB
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+)
+
+// LedgerEntryKeysetParams bút toán của ví shop (ShopID) hoặc của một ví (LedgerID), mới nhất trước
+type LedgerEntryKeysetParams struct {
+	ShopID   string
+	LedgerID string
+	KeysetParams
+}
+
+// TransactionKeysetParams giao dịch toàn sàn, chỉ lọc theo ngày khi có đủ cả hai mốc
+type TransactionKeysetParams struct {
+	Type      db_transaction.NullTransactionsType
+	Status    db_transaction.NullTransactionsStatus
+	StartDate sql.NullTime
+	EndDate   sql.NullTime
+	KeysetParams
+}
+
+// SettlementKeysetParams bản ghi đối soát theo order_completed_at giảm dần.
+// ShopOrderIDs khác nil thì chỉ lấy đối soát của các đơn này (bảng không có shop_id), rỗng là không có bản ghi nào.
+type SettlementKeysetParams struct {
+	ShopOrderIDs []string
+	Status       db_transaction.NullShopOrderSettlementsStatus
+	StartDate    sql.NullTime
+	EndDate      sql.NullTime
+	KeysetParams
+}
+
+// LedgerKeysetParams ví trên sàn theo created_at giảm dần
+type LedgerKeysetParams struct {
+	OwnerType db_transaction.NullAccountLedgersOwnerType
+	KeysetParams
+}
+
+// ListLedgerEntriesKeyset thay cho ListLedgerEntriesByOwnerID/ListLedgerEntriesByLedgerID (LIMIT/OFFSET)
+func (q *SQLStoreTransaction) ListLedgerEntriesKeyset(ctx context.Context, params LedgerEntryKeysetParams) ([]db_transaction.LedgerEntries, error) {
+	base := `
+		SELECT le.id, le.ledger_id, le.transaction_id, le.amount, le.type, le.description, le.created_at
+		FROM ledger_entries le
+	`
+	var conditions []string
+	var args []interface{}
+	if params.ShopID != "" {
+		base += " JOIN account_ledgers al ON le.ledger_id = al.id"
+		conditions = append(conditions, "al.owner_id = ? AND al.owner_type = 'SHOP'")
+		args = append(args, params.ShopID)
+	}
+	if params.LedgerID != "" {
+		conditions = append(conditions, "le.ledger_id = ?")
+		args = append(args, params.LedgerID)
+	}
+	query, args := params.query(base, conditions, args, "le.created_at", "le.id")
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db_transaction.LedgerEntries
+	for rows.Next() {
+		var i db_transaction.LedgerEntries
+		if err := rows.Scan(&i.ID, &i.LedgerID, &i.TransactionID, &i.Amount, &i.Type, &i.Description, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListTransactionsKeyset thay cho ListPlatformTransactions (LIMIT/OFFSET)
+func (q *SQLStoreTransaction) ListTransactionsKeyset(ctx context.Context, params TransactionKeysetParams) ([]db_transaction.Transactions, error) {
+	base := `
+		SELECT id, transaction_code, order_id, payment_method_id, amount, currency, type, status,
+			gateway_transaction_id, notes, created_at, processed_at
+		FROM transactions
+	`
+	var conditions []string
+	var args []interface{}
+	if params.Type.Valid {
+		conditions = append(conditions, "type = ?")
+		args = append(args, params.Type.TransactionsType)
+	}
+	if params.Status.Valid {
+		conditions = append(conditions, "status = ?")
+		args = append(args, params.Status.TransactionsStatus)
+	}
+	if params.StartDate.Valid && params.EndDate.Valid {
+		conditions = append(conditions, "created_at BETWEEN ? AND ?")
+		args = append(args, params.StartDate.Time, params.EndDate.Time)
+	}
+	query, args := params.query(base, conditions, args, "created_at", "id")
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db_transaction.Transactions
+	for rows.Next() {
+		var i db_transaction.Transactions
+		if err := rows.Scan(
+			&i.ID, &i.TransactionCode, &i.OrderID, &i.PaymentMethodID, &i.Amount, &i.Currency, &i.Type, &i.Status,
+			&i.GatewayTransactionID, &i.Notes, &i.CreatedAt, &i.ProcessedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListSettlementsKeyset thay cho ListPlatformSettlements (LIMIT/OFFSET).
+// order_completed_at có thể NULL (đơn chưa hoàn thành), các bản ghi này đứng cuối danh sách.
+func (q *SQLStoreTransaction) ListSettlementsKeyset(ctx context.Context, params SettlementKeysetParams) ([]db_transaction.ShopOrderSettlements, error) {
+	base := `
+		SELECT id, shop_order_id, order_transaction_id, status, order_subtotal, shop_funded_product_discount,
+			site_funded_product_discount, shop_voucher_discount, shop_shipping_discount, shipping_fee,
+			commission_fee, net_settled_amount, order_completed_at, settled_at
+		FROM shop_order_settlements
+	`
+	var conditions []string
+	var args []interface{}
+	if params.ShopOrderIDs != nil {
+		if len(params.ShopOrderIDs) == 0 {
+			return nil, nil
+		}
+		conditions = append(conditions, "shop_order_id IN ("+strings.Repeat(",?", len(params.ShopOrderIDs))[1:]+")")
+		for _, id := range params.ShopOrderIDs {
+			args = append(args, id)
+		}
+	}
+	if params.Status.Valid {
+		conditions = append(conditions, "status = ?")
+		args = append(args, params.Status.ShopOrderSettlementsStatus)
+	}
+	if params.StartDate.Valid && params.EndDate.Valid {
+		conditions = append(conditions, "order_completed_at BETWEEN ? AND ?")
+		args = append(args, params.StartDate.Time, params.EndDate.Time)
+	}
+	query, args := params.query(base, conditions, args, "order_completed_at", "id")
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db_transaction.ShopOrderSettlements
+	for rows.Next() {
+		var i db_transaction.ShopOrderSettlements
+		if err := rows.Scan(
+			&i.ID, &i.ShopOrderID, &i.OrderTransactionID, &i.Status, &i.OrderSubtotal, &i.ShopFundedProductDiscount,
+			&i.SiteFundedProductDiscount, &i.ShopVoucherDiscount, &i.ShopShippingDiscount, &i.ShippingFee,
+			&i.CommissionFee, &i.NetSettledAmount, &i.OrderCompletedAt, &i.SettledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListLedgersKeyset thay cho ListPlatformLedgers (LIMIT/OFFSET, không có thứ tự cố định)
+func (q *SQLStoreTransaction) ListLedgersKeyset(ctx context.Context, params LedgerKeysetParams) ([]db_transaction.AccountLedgers, error) {
+	base := `
+		SELECT id, owner_id, owner_type, balance, pending_balance, created_at, updated_at
+		FROM account_ledgers
+	`
+	var conditions []string
+	var args []interface{}
+	if params.OwnerType.Valid {
+		conditions = append(conditions, "owner_type = ?")
+		args = append(args, params.OwnerType.AccountLedgersOwnerType)
+	}
+	query, args := params.query(base, conditions, args, "created_at", "id")
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db_transaction.AccountLedgers
+	for rows.Next() {
+		var i db_transaction.AccountLedgers
+		if err := rows.Scan(&i.ID, &i.OwnerID, &i.OwnerType, &i.Balance, &i.PendingBalance, &i.CreatedAt, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
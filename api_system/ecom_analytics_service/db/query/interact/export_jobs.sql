@@ -0,0 +1,43 @@
+-- =================================================================
+-- SQLC QUERIES FOR EXPORT_JOBS
+-- =================================================================
+
+-- name: CreateExportJob :exec
+INSERT INTO export_jobs (id, report, format, status, requested_by, shop_id, file_name)
+VALUES (?, ?, ?, 'PENDING', ?, ?, ?);
+
+-- name: GetExportJob :one
+-- Chỉ người yêu cầu mới xem được
+SELECT * FROM export_jobs
+WHERE id = ? AND requested_by = ?;
+
+-- name: MarkExportJobRunning :exec
+UPDATE export_jobs
+SET status = 'RUNNING'
+WHERE id = ?;
+
+-- name: FinishExportJob :exec
+UPDATE export_jobs
+SET status = 'DONE', row_count = ?, finished_at = NOW(), expires_at = ?
+WHERE id = ?;
+
+-- name: FailExportJob :exec
+UPDATE export_jobs
+SET status = 'FAILED', error_message = ?, finished_at = NOW(), expires_at = ?
+WHERE id = ?;
+
+-- name: FailStaleExportJobs :execrows
+-- Yêu cầu bị bỏ dở (dịch vụ khởi động lại khi đang chạy)
+UPDATE export_jobs
+SET status = 'FAILED', error_message = ?, finished_at = NOW(), expires_at = ?
+WHERE status IN ('PENDING', 'RUNNING') AND created_at < ?;
+
+-- name: ListExpiredExportJobs :many
+SELECT * FROM export_jobs
+WHERE expires_at IS NOT NULL AND expires_at < ?
+ORDER BY expires_at ASC
+LIMIT ?;
+
+-- name: DeleteExportJob :exec
+DELETE FROM export_jobs
+WHERE id = ?;
@@ -0,0 +1,161 @@
+-- =================================================================
+-- SQLC QUERIES FOR ROLLUP (bảng tổng hợp theo ngày)
+-- Ngày thống kê theo giờ Việt Nam, khoảng ngày luôn là [from_date, to_date)
+-- =================================================================
+
+-- name: MarkRollupDirtyDay :exec
+-- Đánh dấu 1 ngày cần tính lại (gọi khi nhận sự kiện Kafka)
+INSERT INTO rollup_dirty_days (stat_date, marked_at)
+VALUES (?, NOW(6))
+ON DUPLICATE KEY UPDATE marked_at = VALUES(marked_at);
+
+-- name: ListRollupDirtyDays :many
+SELECT * FROM rollup_dirty_days
+ORDER BY stat_date ASC
+LIMIT ?;
+
+-- name: DeleteRollupDirtyDay :exec
+-- Chỉ xóa khi ngày không bị đánh dấu lại trong lúc đang tính
+DELETE FROM rollup_dirty_days
+WHERE stat_date = ? AND marked_at <= ?;
+
+-- name: DeleteRollupShopDailyByDate :exec
+DELETE FROM rollup_shop_daily WHERE stat_date = ?;
+
+-- name: DeleteRollupProductDailyByDate :exec
+DELETE FROM rollup_product_daily WHERE stat_date = ?;
+
+-- name: DeleteRollupCategoryDailyByDate :exec
+DELETE FROM rollup_category_daily WHERE stat_date = ?;
+
+-- name: DeleteRollupVoucherDailyByDate :exec
+DELETE FROM rollup_voucher_daily WHERE stat_date = ?;
+
+-- name: CreateRollupShopDaily :exec
+INSERT INTO rollup_shop_daily (
+    stat_date, shop_id, placed_orders, placed_gmv, processing_orders,
+    completed_orders, completed_gmv, net_revenue, commission_revenue, shipping_revenue
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: CreateRollupProductDaily :exec
+INSERT INTO rollup_product_daily (
+    stat_date, product_id, sku_id, shop_id, category_id,
+    product_name, sku_attributes, quantity, revenue, order_count
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: CreateRollupCategoryDaily :exec
+INSERT INTO rollup_category_daily (stat_date, category_id, quantity, revenue, order_count)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: CreateRollupVoucherDaily :exec
+INSERT INTO rollup_voucher_daily (stat_date, voucher_id, owner_type, owner_id, usage_count, discount_amount)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetRollupShopSummary :one
+-- Tổng quan đơn hàng của shop theo ngày tạo đơn (API: GET /shop/overview)
+SELECT
+    COALESCE(SUM(placed_orders), 0) AS total_orders,
+    COALESCE(SUM(placed_gmv), 0.00) AS total_gmv,
+    COALESCE(SUM(processing_orders), 0) AS processing_orders
+FROM rollup_shop_daily
+WHERE shop_id = ? AND stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date);
+
+-- name: GetRollupShopNetRevenueTotal :one
+-- Tổng doanh thu thuần đã đối soát từ trước tới nay của shop
+SELECT COALESCE(SUM(net_revenue), 0.00) AS total_net_revenue
+FROM rollup_shop_daily
+WHERE shop_id = ?;
+
+-- name: ListRollupShopDaily :many
+-- Chuỗi theo ngày của shop (API: GET /shop/revenue/timeseries)
+SELECT * FROM rollup_shop_daily
+WHERE shop_id = ? AND stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date)
+ORDER BY stat_date ASC;
+
+-- name: ListRollupPlatformDaily :many
+-- Chuỗi theo ngày toàn sàn (API: GET /platform/finance/revenue-timeseries)
+SELECT
+    stat_date,
+    COALESCE(SUM(completed_orders), 0) AS completed_orders,
+    COALESCE(SUM(completed_gmv), 0.00) AS completed_gmv,
+    COALESCE(SUM(commission_revenue), 0.00) AS commission_revenue
+FROM rollup_shop_daily
+WHERE stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date)
+GROUP BY stat_date
+ORDER BY stat_date ASC;
+
+-- name: GetRollupPlatformSummary :one
+-- Tổng quan toàn sàn (API: GET /platform/overview)
+SELECT
+    COALESCE(SUM(completed_orders), 0) AS total_orders,
+    COALESCE(SUM(completed_gmv), 0.00) AS total_gmv,
+    COUNT(DISTINCT CASE WHEN completed_orders > 0 THEN shop_id END) AS total_shops,
+    COALESCE(SUM(commission_revenue), 0.00) AS total_commission,
+    COALESCE(SUM(shipping_revenue), 0.00) AS total_shipping_revenue
+FROM rollup_shop_daily
+WHERE stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date);
+
+-- name: ListRollupTopShops :many
+-- Xếp hạng shop theo GMV hoàn thành, bỏ trống khoảng ngày để lấy toàn thời gian (API: GET /platform/ranking/shops, /platform/shops)
+SELECT
+    shop_id,
+    COALESCE(SUM(completed_gmv), 0.00) AS total_gmv,
+    COALESCE(SUM(completed_orders), 0) AS total_orders
+FROM rollup_shop_daily
+WHERE
+    (sqlc.narg(from_date) IS NULL OR stat_date >= sqlc.narg(from_date))
+    AND (sqlc.narg(to_date) IS NULL OR stat_date < sqlc.narg(to_date))
+GROUP BY shop_id
+ORDER BY total_gmv DESC
+LIMIT ?;
+
+-- name: ListRollupShopTopProducts :many
+-- Xếp hạng sản phẩm của shop theo doanh thu hoặc số lượng (sort_by = revenue | quantity) (API: GET /shop/ranking/products)
+SELECT
+    product_id,
+    sku_id,
+    product_name,
+    sku_attributes,
+    COALESCE(SUM(quantity), 0) AS total_quantity,
+    COALESCE(SUM(revenue), 0.00) AS total_revenue
+FROM rollup_product_daily
+WHERE shop_id = ? AND stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date)
+GROUP BY product_id, sku_id, product_name, sku_attributes
+ORDER BY CASE WHEN sqlc.arg(sort_by) = 'quantity' THEN SUM(quantity) ELSE SUM(revenue) END DESC
+LIMIT ?;
+
+-- name: ListRollupPlatformTopProducts :many
+-- Xếp hạng sản phẩm toàn sàn theo số lượng bán (API: GET /platform/ranking/products)
+SELECT
+    product_id,
+    product_name,
+    COALESCE(SUM(quantity), 0) AS total_quantity
+FROM rollup_product_daily
+WHERE stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date)
+GROUP BY product_id, product_name
+ORDER BY total_quantity DESC
+LIMIT ?;
+
+-- name: ListRollupTopCategories :many
+-- Xếp hạng danh mục theo doanh thu (API: GET /platform/ranking/categories)
+SELECT
+    category_id,
+    COALESCE(SUM(quantity), 0) AS total_quantity,
+    COALESCE(SUM(revenue), 0.00) AS total_revenue,
+    COALESCE(SUM(order_count), 0) AS total_orders
+FROM rollup_category_daily
+WHERE stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date)
+GROUP BY category_id
+ORDER BY total_revenue DESC
+LIMIT ?;
+
+-- name: GetRollupVoucherUsage :one
+-- Hiệu suất voucher theo chủ sở hữu, owner_id bỏ trống để lấy mọi voucher của owner_type (API: GET /shop/vouchers/performance, /platform/vouchers/performance/platform)
+SELECT
+    COALESCE(SUM(usage_count), 0) AS total_usage_count,
+    COALESCE(SUM(discount_amount), 0.00) AS total_discount_value
+FROM rollup_voucher_daily
+WHERE
+    owner_type = ?
+    AND (sqlc.narg(owner_id) IS NULL OR owner_id = sqlc.narg(owner_id))
+    AND stat_date >= sqlc.arg(from_date) AND stat_date < sqlc.arg(to_date);
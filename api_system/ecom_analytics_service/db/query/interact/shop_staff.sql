@@ -0,0 +1,34 @@
+-- =================================================================
+-- SQLC QUERIES FOR SHOP_STAFF
+-- =================================================================
+
+-- name: GetShopStaff :one
+SELECT * FROM shop_staff
+WHERE shop_id = ? AND user_id = ?;
+
+-- name: ListShopsByStaffUser :many
+-- Các shop mà người dùng là nhân sự (dùng khi token không có claim shopId)
+SELECT * FROM shop_staff
+WHERE user_id = ?
+ORDER BY created_at ASC;
+
+-- name: ListShopStaff :many
+SELECT * FROM shop_staff
+WHERE shop_id = ?
+ORDER BY created_at ASC;
+
+-- name: UpsertShopStaff :exec
+-- Thêm nhân sự hoặc đổi vai trò nếu đã tồn tại
+INSERT INTO shop_staff (shop_id, user_id, role, created_by)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE role = VALUES(role), created_by = VALUES(created_by);
+
+-- name: DemoteShopOwners :execrows
+-- Hạ các chủ shop khác xuống MANAGER trước khi gán chủ mới, mỗi shop chỉ có một OWNER
+UPDATE shop_staff
+SET role = 'MANAGER', created_by = sqlc.arg('created_by')
+WHERE shop_id = sqlc.arg('shop_id') AND role = 'OWNER' AND user_id <> sqlc.arg('user_id');
+
+-- name: DeleteShopStaff :execrows
+DELETE FROM shop_staff
+WHERE shop_id = ? AND user_id = ?;
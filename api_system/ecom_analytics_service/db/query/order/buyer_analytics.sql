@@ -0,0 +1,88 @@
+-- =================================================================
+-- IV. PHÂN TÍCH HÀNH VI MUA HÀNG (phễu đơn hàng, cohort, RFM)
+-- Khoảng thời gian luôn là [from, to)
+-- Đơn hợp lệ: đơn shop đã được xác nhận (PROCESSING, SHIPPED, COMPLETED)
+-- Tháng tính theo múi giờ của phiên MySQL (+07:00)
+-- =================================================================
+
+-- name: ListShopOrderStageTimes :many
+-- Tác dụng: Mốc thời gian từng bước của các đơn shop tạo trong khoảng (API: GET /platform/funnel/orders)
+SELECT
+    status,
+    created_at,
+    paid_at,
+    processing_at,
+    shipped_at,
+    completed_at,
+    cancelled_at
+FROM shop_orders
+WHERE created_at >= sqlc.arg(from_created_at) AND created_at < sqlc.arg(to_created_at);
+
+-- name: ListCohortRetention :many
+-- Tác dụng: Số người mua có đơn hợp lệ ở tháng thứ month_offset kể từ tháng mua đầu tiên (API: GET /platform/cohorts/retention)
+-- cohort_month dạng YYYYMM, chỉ lấy các cohort có đơn đầu tiên trong khoảng
+WITH valid_orders AS (
+    SELECT o.user_id, o.created_at
+    FROM orders o
+    WHERE EXISTS (
+        SELECT 1 FROM shop_orders so
+        WHERE so.order_id = o.id AND so.status IN ('PROCESSING', 'SHIPPED', 'COMPLETED')
+    )
+),
+first_orders AS (
+    SELECT user_id, MIN(created_at) AS first_order_at
+    FROM valid_orders
+    GROUP BY user_id
+)
+SELECT
+    EXTRACT(YEAR_MONTH FROM f.first_order_at) AS cohort_month,
+    PERIOD_DIFF(EXTRACT(YEAR_MONTH FROM v.created_at), EXTRACT(YEAR_MONTH FROM f.first_order_at)) AS month_offset,
+    COUNT(DISTINCT v.user_id) AS buyers
+FROM valid_orders v
+JOIN first_orders f ON f.user_id = v.user_id
+WHERE f.first_order_at >= sqlc.arg(from_first_order_at) AND f.first_order_at < sqlc.arg(to_first_order_at)
+GROUP BY cohort_month, month_offset
+ORDER BY cohort_month, month_offset;
+
+-- name: ListCohortRepeatBuyers :many
+-- Tác dụng: Số người mua của từng cohort và số người có đơn hợp lệ tiếp theo trong vòng repeat_days ngày sau đơn đầu tiên
+WITH valid_orders AS (
+    SELECT o.user_id, o.created_at
+    FROM orders o
+    WHERE EXISTS (
+        SELECT 1 FROM shop_orders so
+        WHERE so.order_id = o.id AND so.status IN ('PROCESSING', 'SHIPPED', 'COMPLETED')
+    )
+),
+first_orders AS (
+    SELECT user_id, MIN(created_at) AS first_order_at
+    FROM valid_orders
+    GROUP BY user_id
+)
+SELECT
+    EXTRACT(YEAR_MONTH FROM f.first_order_at) AS cohort_month,
+    COUNT(*) AS buyers,
+    COUNT(CASE WHEN EXISTS (
+        SELECT 1 FROM valid_orders v
+        WHERE v.user_id = f.user_id
+          AND v.created_at > f.first_order_at
+          AND v.created_at <= f.first_order_at + INTERVAL sqlc.arg(repeat_days) DAY
+    ) THEN 1 END) AS repeat_buyers
+FROM first_orders f
+WHERE f.first_order_at >= sqlc.arg(from_first_order_at) AND f.first_order_at < sqlc.arg(to_first_order_at)
+GROUP BY cohort_month
+ORDER BY cohort_month;
+
+-- name: ListBuyerRFM :many
+-- Tác dụng: Lần mua gần nhất, số đơn và tổng chi tiêu của từng người mua trong khoảng (API: GET /platform/buyers/rfm)
+SELECT
+    o.user_id,
+    MAX(o.created_at) AS last_order_at,
+    COUNT(DISTINCT o.id) AS frequency,
+    COALESCE(SUM(so.total_amount), 0.00) AS monetary
+FROM orders o
+JOIN shop_orders so ON so.order_id = o.id
+WHERE
+    so.status IN ('PROCESSING', 'SHIPPED', 'COMPLETED')
+    AND o.created_at >= sqlc.arg(from_created_at) AND o.created_at < sqlc.arg(to_created_at)
+GROUP BY o.user_id;
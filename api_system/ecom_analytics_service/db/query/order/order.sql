@@ -48,9 +48,11 @@ SELECT id FROM shop_orders
 WHERE shop_id = ?;
 
 -- name: GetShopRevenueTimeSeries :many
--- Tác dụng: Lấy dữ liệu doanh thu GMV theo ngày để vẽ biểu đồ (API: GET /shop/revenue/timeseries)
+-- Tác dụng: Lấy GMV và số đơn theo từng khung 15 phút để gom lại theo giờ/ngày/tuần/tháng (API: GET /shop/revenue/timeseries)
+-- Khung 15 phút là đơn vị nhỏ nhất của mọi múi giờ nên service gom lại theo múi giờ bất kỳ mà không lệch
 SELECT
-    DATE(completed_at) AS report_date,
+    TIMESTAMP(DATE(completed_at), MAKETIME(HOUR(completed_at), FLOOR(MINUTE(completed_at) / 15) * 15, 0)) AS slot_start,
+    COUNT(*) AS order_count,
     COALESCE(SUM(subtotal), 0.00) AS gmv
 FROM shop_orders
 WHERE
@@ -58,10 +60,10 @@ WHERE
     AND (
         sqlc.narg(from_completed_at) IS NULL 
         OR sqlc.narg(to_completed_at) IS NULL 
-        OR completed_at BETWEEN sqlc.narg(from_completed_at) AND sqlc.narg(to_completed_at)
+        OR (completed_at >= sqlc.narg(from_completed_at) AND completed_at < sqlc.narg(to_completed_at))
     )
-GROUP BY report_date
-ORDER BY report_date ASC;
+GROUP BY slot_start
+ORDER BY slot_start ASC;
 
 -- name: GetShopTopProductsByRevenue :many
 -- Tác dụng: Xếp hạng sản phẩm theo Doanh thu (API: GET /shop/ranking/products/by-revenue)
@@ -203,14 +205,16 @@ ORDER BY total_spent DESC
 LIMIT ?;
 
 -- name: GetPlatformGMVTimeSeries :many
+-- Tác dụng: Lấy GMV và số đơn toàn sàn theo khung 15 phút (API: GET /platform/finance/revenue-timeseries)
 SELECT
-    DATE(completed_at) AS report_date,
+    TIMESTAMP(DATE(completed_at), MAKETIME(HOUR(completed_at), FLOOR(MINUTE(completed_at) / 15) * 15, 0)) AS slot_start,
+    COUNT(*) AS order_count,
     COALESCE(SUM(subtotal), 0.00) AS gmv
 FROM shop_orders
 WHERE
         sqlc.narg(start_date) IS NULL 
         OR sqlc.narg(end_date) IS NULL 
-        OR completed_at BETWEEN sqlc.narg(start_date) AND sqlc.narg(end_date)
+        OR (completed_at >= sqlc.narg(start_date) AND completed_at < sqlc.narg(end_date))
     
-GROUP BY report_date
-ORDER BY report_date ASC;
\ No newline at end of file
+GROUP BY slot_start
+ORDER BY slot_start ASC;
\ No newline at end of file
@@ -0,0 +1,79 @@
+-- =================================================================
+-- III. NGUỒN CHO BẢNG TỔNG HỢP (rollup) - chỉ dùng khi tính lại 1 ngày hoặc backfill
+-- Khoảng thời gian luôn là [from, to)
+-- =================================================================
+
+-- name: GetShopPlacedStatsInRange :many
+-- Tác dụng: Số đơn/GMV theo ngày tạo của từng shop (rollup_shop_daily.placed_*)
+SELECT
+    shop_id,
+    COUNT(*) AS placed_orders,
+    COALESCE(SUM(subtotal), 0.00) AS placed_gmv,
+    COUNT(CASE WHEN status = 'PROCESSING' THEN 1 END) AS processing_orders
+FROM shop_orders
+WHERE
+    status NOT IN ('CANCELLED', 'AWAITING_PAYMENT')
+    AND created_at >= sqlc.arg(from_created_at) AND created_at < sqlc.arg(to_created_at)
+GROUP BY shop_id;
+
+-- name: GetShopCompletedStatsInRange :many
+-- Tác dụng: Số đơn/GMV theo ngày hoàn thành của từng shop (rollup_shop_daily.completed_*)
+SELECT
+    shop_id,
+    COUNT(*) AS completed_orders,
+    COALESCE(SUM(subtotal), 0.00) AS completed_gmv
+FROM shop_orders
+WHERE completed_at >= sqlc.arg(from_completed_at) AND completed_at < sqlc.arg(to_completed_at)
+GROUP BY shop_id;
+
+-- name: GetProductSalesInRange :many
+-- Tác dụng: Sản phẩm bán ra theo ngày hoàn thành đơn (rollup_product_daily)
+-- Cùng 1 SKU có thể ra nhiều dòng nếu snapshot tên/thuộc tính khác nhau, service gộp lại
+SELECT
+    oi.product_id,
+    oi.sku_id,
+    so.shop_id,
+    oi.category_id,
+    oi.product_name_snapshot,
+    oi.sku_attributes_snapshot,
+    COALESCE(SUM(oi.quantity), 0) AS quantity,
+    COALESCE(SUM(oi.total_price), 0.00) AS revenue,
+    COUNT(DISTINCT so.id) AS order_count
+FROM order_items oi
+JOIN shop_orders so ON oi.shop_order_id = so.id
+WHERE so.completed_at >= sqlc.arg(from_completed_at) AND so.completed_at < sqlc.arg(to_completed_at)
+GROUP BY oi.product_id, oi.sku_id, so.shop_id, oi.category_id, oi.product_name_snapshot, oi.sku_attributes_snapshot;
+
+-- name: GetCategorySalesInRange :many
+-- Tác dụng: Doanh số theo danh mục theo ngày hoàn thành đơn (rollup_category_daily)
+SELECT
+    oi.category_id,
+    COALESCE(SUM(oi.quantity), 0) AS quantity,
+    COALESCE(SUM(oi.total_price), 0.00) AS revenue,
+    COUNT(DISTINCT so.id) AS order_count
+FROM order_items oi
+JOIN shop_orders so ON oi.shop_order_id = so.id
+WHERE so.completed_at >= sqlc.arg(from_completed_at) AND so.completed_at < sqlc.arg(to_completed_at)
+GROUP BY oi.category_id;
+
+-- name: GetVoucherUsageInRange :many
+-- Tác dụng: Lượt dùng voucher theo ngày sử dụng (rollup_voucher_daily)
+SELECT
+    vuh.voucher_id,
+    v.owner_type,
+    v.owner_id,
+    COUNT(vuh.id) AS usage_count,
+    COALESCE(SUM(vuh.discount_amount), 0.00) AS discount_amount
+FROM voucher_usage_history vuh
+JOIN vouchers v ON vuh.voucher_id = v.id
+WHERE vuh.used_at >= sqlc.arg(from_used_at) AND vuh.used_at < sqlc.arg(to_used_at)
+GROUP BY vuh.voucher_id, v.owner_type, v.owner_id;
+
+-- name: GetShopOrdersByIDs :many
+-- Tác dụng: Lấy các đơn hàng shop theo ID (xác định shop của settlement, ngày cần tính lại khi có sự kiện)
+SELECT * FROM shop_orders
+WHERE id IN (sqlc.slice(shop_order_ids));
+
+-- name: GetFirstShopOrderCreatedAt :one
+-- Tác dụng: Thời điểm đơn hàng đầu tiên, mốc bắt đầu mặc định của backfill
+SELECT MIN(created_at) AS first_created_at FROM shop_orders;
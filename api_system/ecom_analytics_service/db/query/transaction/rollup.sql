@@ -0,0 +1,10 @@
+-- =================================================================
+-- III. NGUỒN CHO BẢNG TỔNG HỢP (rollup)
+-- =================================================================
+
+-- name: ListSettledSettlementsInRange :many
+-- Tác dụng: Các settlement đã SETTLED trong khoảng [from, to) theo settled_at (rollup_shop_daily.*_revenue)
+SELECT * FROM shop_order_settlements
+WHERE
+    status = 'SETTLED'
+    AND settled_at >= sqlc.arg(from_settled_at) AND settled_at < sqlc.arg(to_settled_at);
@@ -6,9 +6,140 @@ package db
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"time"
 )
 
+type ExportJobsFormat string
+
+const (
+	ExportJobsFormatCsv  ExportJobsFormat = "csv"
+	ExportJobsFormatXlsx ExportJobsFormat = "xlsx"
+)
+
+func (e *ExportJobsFormat) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportJobsFormat(s)
+	case string:
+		*e = ExportJobsFormat(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportJobsFormat: %T", src)
+	}
+	return nil
+}
+
+type NullExportJobsFormat struct {
+	ExportJobsFormat ExportJobsFormat `json:"export_jobs_format"`
+	Valid            bool             `json:"valid"` // Valid is true if ExportJobsFormat is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportJobsFormat) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportJobsFormat, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportJobsFormat.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportJobsFormat) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportJobsFormat), nil
+}
+
+type ExportJobsStatus string
+
+const (
+	ExportJobsStatusPENDING ExportJobsStatus = "PENDING"
+	ExportJobsStatusRUNNING ExportJobsStatus = "RUNNING"
+	ExportJobsStatusDONE    ExportJobsStatus = "DONE"
+	ExportJobsStatusFAILED  ExportJobsStatus = "FAILED"
+)
+
+func (e *ExportJobsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportJobsStatus(s)
+	case string:
+		*e = ExportJobsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportJobsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullExportJobsStatus struct {
+	ExportJobsStatus ExportJobsStatus `json:"export_jobs_status"`
+	Valid            bool             `json:"valid"` // Valid is true if ExportJobsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportJobsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportJobsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportJobsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportJobsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportJobsStatus), nil
+}
+
+type ShopStaffRole string
+
+const (
+	ShopStaffRoleOWNER   ShopStaffRole = "OWNER"
+	ShopStaffRoleMANAGER ShopStaffRole = "MANAGER"
+	ShopStaffRoleVIEWER  ShopStaffRole = "VIEWER"
+)
+
+func (e *ShopStaffRole) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ShopStaffRole(s)
+	case string:
+		*e = ShopStaffRole(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ShopStaffRole: %T", src)
+	}
+	return nil
+}
+
+type NullShopStaffRole struct {
+	ShopStaffRole ShopStaffRole `json:"shop_staff_role"`
+	Valid         bool          `json:"valid"` // Valid is true if ShopStaffRole is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullShopStaffRole) Scan(value interface{}) error {
+	if value == nil {
+		ns.ShopStaffRole, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ShopStaffRole.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullShopStaffRole) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ShopStaffRole), nil
+}
+
 // Lưu trữ các phiếu phản hồi (tickets) từ khách hàng
 type CustomerFeedback struct {
 	// UUID, Khóa chính của phiếu phản hồi
@@ -27,6 +158,33 @@ type CustomerFeedback struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Yêu cầu xuất báo cáo CSV/XLSX chạy nền
+type ExportJobs struct {
+	// UUID, Khóa chính
+	ID string `json:"id"`
+	// Loại báo cáo (shop_orders, shop_settlements, ...)
+	Report string `json:"report"`
+	// Định dạng file
+	Format ExportJobsFormat `json:"format"`
+	Status ExportJobsStatus `json:"status"`
+	// Người yêu cầu, chỉ người này được tải file
+	RequestedBy string `json:"requested_by"`
+	// Shop của báo cáo, NULL với báo cáo toàn sàn
+	ShopID sql.NullString `json:"shop_id"`
+	// Tên file khi tải về
+	FileName string `json:"file_name"`
+	// Số dòng dữ liệu đã ghi
+	RowCount int32 `json:"row_count"`
+	// Lý do lỗi khi FAILED
+	ErrorMessage sql.NullString `json:"error_message"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	// Thời điểm xong (DONE hoặc FAILED)
+	FinishedAt sql.NullTime `json:"finished_at"`
+	// Sau thời điểm này file và yêu cầu bị xóa
+	ExpiresAt sql.NullTime `json:"expires_at"`
+}
+
 // Lưu trữ đánh giá Like/Dislike (và context) cho từng kết quả (event) của Agent
 type MessageRatings struct {
 	ID uint64 `json:"id"`
@@ -44,3 +202,104 @@ type MessageRatings struct {
 	AgentResponse sql.NullString `json:"agent_response"`
 	CreatedAt     time.Time      `json:"created_at"`
 }
+
+// Tổng hợp doanh thu theo danh mục theo ngày
+type RollupCategoryDaily struct {
+	// Ngày thống kê (theo completed_at của đơn)
+	StatDate time.Time `json:"stat_date"`
+	// ID danh mục, rỗng là chưa phân loại
+	CategoryID string `json:"category_id"`
+	// Số lượng bán
+	Quantity int32 `json:"quantity"`
+	// Doanh thu (tổng total_price)
+	Revenue string `json:"revenue"`
+	// Số đơn shop có sản phẩm thuộc danh mục
+	OrderCount int32 `json:"order_count"`
+}
+
+// Hàng đợi các ngày cần tính lại bảng tổng hợp
+type RollupDirtyDays struct {
+	// Ngày cần tính lại
+	StatDate time.Time `json:"stat_date"`
+	// Lần đánh dấu gần nhất
+	MarkedAt time.Time `json:"marked_at"`
+}
+
+// Tổng hợp sản phẩm bán ra theo ngày
+type RollupProductDaily struct {
+	// Ngày thống kê (theo completed_at của đơn)
+	StatDate time.Time `json:"stat_date"`
+	// UUID của sản phẩm
+	ProductID string `json:"product_id"`
+	// UUID của SKU
+	SkuID string `json:"sku_id"`
+	// ID của shop bán
+	ShopID string `json:"shop_id"`
+	// Danh mục của sản phẩm, rỗng nếu đơn cũ chưa lưu danh mục
+	CategoryID string `json:"category_id"`
+	// Tên sản phẩm (snapshot)
+	ProductName string `json:"product_name"`
+	// Thuộc tính SKU (snapshot)
+	SkuAttributes sql.NullString `json:"sku_attributes"`
+	// Số lượng bán
+	Quantity int32 `json:"quantity"`
+	// Doanh thu (tổng total_price)
+	Revenue string `json:"revenue"`
+	// Số đơn shop có sản phẩm này
+	OrderCount int32 `json:"order_count"`
+}
+
+// Tổng hợp đơn hàng và doanh thu theo ngày của từng shop
+type RollupShopDaily struct {
+	// Ngày thống kê
+	StatDate time.Time `json:"stat_date"`
+	// ID của shop
+	ShopID string `json:"shop_id"`
+	// Số đơn tạo trong ngày (trừ CANCELLED, AWAITING_PAYMENT)
+	PlacedOrders int32 `json:"placed_orders"`
+	// GMV của các đơn tạo trong ngày
+	PlacedGmv string `json:"placed_gmv"`
+	// Số đơn tạo trong ngày hiện đang PROCESSING
+	ProcessingOrders int32 `json:"processing_orders"`
+	// Số đơn hoàn thành trong ngày (theo completed_at)
+	CompletedOrders int32 `json:"completed_orders"`
+	// GMV của các đơn hoàn thành trong ngày
+	CompletedGmv string `json:"completed_gmv"`
+	// Doanh thu thuần của shop đã đối soát trong ngày (theo settled_at)
+	NetRevenue string `json:"net_revenue"`
+	// Phí hoa hồng sàn thu từ shop trong ngày
+	CommissionRevenue string `json:"commission_revenue"`
+	// Phí vận chuyển sàn thu từ shop trong ngày
+	ShippingRevenue string    `json:"shipping_revenue"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Tổng hợp lượt dùng voucher theo ngày
+type RollupVoucherDaily struct {
+	// Ngày thống kê (theo used_at)
+	StatDate time.Time `json:"stat_date"`
+	// ID voucher
+	VoucherID string `json:"voucher_id"`
+	// SHOP hoặc PLATFORM
+	OwnerType string `json:"owner_type"`
+	// ID shop sở hữu (hoặc ID sàn)
+	OwnerID string `json:"owner_id"`
+	// Số lượt sử dụng
+	UsageCount int32 `json:"usage_count"`
+	// Tổng tiền đã giảm
+	DiscountAmount string `json:"discount_amount"`
+}
+
+// Nhân sự của shop và vai trò khi xem thống kê shop
+type ShopStaff struct {
+	// ID của shop
+	ShopID string `json:"shop_id"`
+	// ID người dùng (claim userId trong JWT)
+	UserID string `json:"user_id"`
+	// Vai trò trong shop
+	Role ShopStaffRole `json:"role"`
+	// Người cấp quyền
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -6,6 +6,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
@@ -14,23 +15,85 @@ type Querier interface {
 	// =================================================================
 	CreateCustomerFeedback(ctx context.Context, arg CreateCustomerFeedbackParams) error
 	// =================================================================
+	// SQLC QUERIES FOR EXPORT_JOBS
+	// =================================================================
+	CreateExportJob(ctx context.Context, arg CreateExportJobParams) error
+	// =================================================================
 	// SQLC QUERIES FOR MESSAGE_RATINGS
 	// =================================================================
 	CreateMessageRating(ctx context.Context, arg CreateMessageRatingParams) error
+	CreateRollupCategoryDaily(ctx context.Context, arg CreateRollupCategoryDailyParams) error
+	CreateRollupProductDaily(ctx context.Context, arg CreateRollupProductDailyParams) error
+	CreateRollupShopDaily(ctx context.Context, arg CreateRollupShopDailyParams) error
+	CreateRollupVoucherDaily(ctx context.Context, arg CreateRollupVoucherDailyParams) error
+	DeleteExportJob(ctx context.Context, id string) error
+	DeleteRollupCategoryDailyByDate(ctx context.Context, statDate time.Time) error
+	// Chỉ xóa khi ngày không bị đánh dấu lại trong lúc đang tính
+	DeleteRollupDirtyDay(ctx context.Context, arg DeleteRollupDirtyDayParams) error
+	DeleteRollupProductDailyByDate(ctx context.Context, statDate time.Time) error
+	DeleteRollupShopDailyByDate(ctx context.Context, statDate time.Time) error
+	DeleteRollupVoucherDailyByDate(ctx context.Context, statDate time.Time) error
+	DeleteShopStaff(ctx context.Context, arg DeleteShopStaffParams) (int64, error)
+	// Hạ các chủ shop khác xuống MANAGER trước khi gán chủ mới, mỗi shop chỉ có một OWNER
+	DemoteShopOwners(ctx context.Context, arg DemoteShopOwnersParams) (int64, error)
+	FailExportJob(ctx context.Context, arg FailExportJobParams) error
+	// Yêu cầu bị bỏ dở (dịch vụ khởi động lại khi đang chạy)
+	FailStaleExportJobs(ctx context.Context, arg FailStaleExportJobsParams) (int64, error)
+	FinishExportJob(ctx context.Context, arg FinishExportJobParams) error
 	// Lấy chi tiết 1 feedback
 	GetCustomerFeedbackByID(ctx context.Context, id string) (CustomerFeedback, error)
 	// Thống kê tổng quan feedback
 	GetCustomerFeedbackStats(ctx context.Context, arg GetCustomerFeedbackStatsParams) (GetCustomerFeedbackStatsRow, error)
 	// Thống kê feedback theo category
 	GetCustomerFeedbacksByCategory(ctx context.Context, arg GetCustomerFeedbacksByCategoryParams) ([]GetCustomerFeedbacksByCategoryRow, error)
+	// Chỉ người yêu cầu mới xem được
+	GetExportJob(ctx context.Context, arg GetExportJobParams) (ExportJobs, error)
 	// Thống kê tổng quan đánh giá message ratings
 	GetMessageRatingStats(ctx context.Context, arg GetMessageRatingStatsParams) (GetMessageRatingStatsRow, error)
 	// Lấy danh sách ratings theo session để xem chi tiết
 	GetMessageRatingsBySession(ctx context.Context, arg GetMessageRatingsBySessionParams) ([]MessageRatings, error)
 	// Thống kê theo thời gian (theo ngày)
 	GetMessageRatingsTimeSeries(ctx context.Context, arg GetMessageRatingsTimeSeriesParams) ([]GetMessageRatingsTimeSeriesRow, error)
+	// Tổng quan toàn sàn (API: GET /platform/overview)
+	GetRollupPlatformSummary(ctx context.Context, arg GetRollupPlatformSummaryParams) (GetRollupPlatformSummaryRow, error)
+	// Tổng doanh thu thuần đã đối soát từ trước tới nay của shop
+	GetRollupShopNetRevenueTotal(ctx context.Context, shopID string) (interface{}, error)
+	// Tổng quan đơn hàng của shop theo ngày tạo đơn (API: GET /shop/overview)
+	GetRollupShopSummary(ctx context.Context, arg GetRollupShopSummaryParams) (GetRollupShopSummaryRow, error)
+	// Hiệu suất voucher theo chủ sở hữu, owner_id bỏ trống để lấy mọi voucher của owner_type (API: GET /shop/vouchers/performance, /platform/vouchers/performance/platform)
+	GetRollupVoucherUsage(ctx context.Context, arg GetRollupVoucherUsageParams) (GetRollupVoucherUsageRow, error)
+	// =================================================================
+	// SQLC QUERIES FOR SHOP_STAFF
+	// =================================================================
+	GetShopStaff(ctx context.Context, arg GetShopStaffParams) (ShopStaff, error)
 	// Lấy danh sách feedback cho Admin xem
 	ListCustomerFeedbacks(ctx context.Context, arg ListCustomerFeedbacksParams) ([]CustomerFeedback, error)
+	ListExpiredExportJobs(ctx context.Context, arg ListExpiredExportJobsParams) ([]ExportJobs, error)
+	ListRollupDirtyDays(ctx context.Context, limit int32) ([]RollupDirtyDays, error)
+	// Chuỗi theo ngày toàn sàn (API: GET /platform/finance/revenue-timeseries)
+	ListRollupPlatformDaily(ctx context.Context, arg ListRollupPlatformDailyParams) ([]ListRollupPlatformDailyRow, error)
+	// Xếp hạng sản phẩm toàn sàn theo số lượng bán (API: GET /platform/ranking/products)
+	ListRollupPlatformTopProducts(ctx context.Context, arg ListRollupPlatformTopProductsParams) ([]ListRollupPlatformTopProductsRow, error)
+	// Chuỗi theo ngày của shop (API: GET /shop/revenue/timeseries)
+	ListRollupShopDaily(ctx context.Context, arg ListRollupShopDailyParams) ([]RollupShopDaily, error)
+	// Xếp hạng sản phẩm của shop theo doanh thu hoặc số lượng (sort_by = revenue | quantity) (API: GET /shop/ranking/products)
+	ListRollupShopTopProducts(ctx context.Context, arg ListRollupShopTopProductsParams) ([]ListRollupShopTopProductsRow, error)
+	// Xếp hạng danh mục theo doanh thu (API: GET /platform/ranking/categories)
+	ListRollupTopCategories(ctx context.Context, arg ListRollupTopCategoriesParams) ([]ListRollupTopCategoriesRow, error)
+	// Xếp hạng shop theo GMV hoàn thành, bỏ trống khoảng ngày để lấy toàn thời gian (API: GET /platform/ranking/shops, /platform/shops)
+	ListRollupTopShops(ctx context.Context, arg ListRollupTopShopsParams) ([]ListRollupTopShopsRow, error)
+	ListShopStaff(ctx context.Context, shopID string) ([]ShopStaff, error)
+	// Các shop mà người dùng là nhân sự (dùng khi token không có claim shopId)
+	ListShopsByStaffUser(ctx context.Context, userID string) ([]ShopStaff, error)
+	MarkExportJobRunning(ctx context.Context, id string) error
+	// =================================================================
+	// SQLC QUERIES FOR ROLLUP (bảng tổng hợp theo ngày)
+	// Ngày thống kê theo giờ Việt Nam, khoảng ngày luôn là [from_date, to_date)
+	// =================================================================
+	// Đánh dấu 1 ngày cần tính lại (gọi khi nhận sự kiện Kafka)
+	MarkRollupDirtyDay(ctx context.Context, statDate time.Time) error
+	// Thêm nhân sự hoặc đổi vai trò nếu đã tồn tại
+	UpsertShopStaff(ctx context.Context, arg UpsertShopStaffParams) error
 }
 
 var _ Querier = (*Queries)(nil)
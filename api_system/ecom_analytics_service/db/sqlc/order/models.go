@@ -295,6 +295,8 @@ type OrderItems struct {
 	ProductImageSnapshot sql.NullString `json:"product_image_snapshot"`
 	// Các thuộc tính của SKU (Màu, Size...) tại thời điểm mua
 	SkuAttributesSnapshot sql.NullString `json:"sku_attributes_snapshot"`
+	// Danh mục của sản phẩm tại thời điểm mua
+	CategoryID sql.NullString `json:"category_id"`
 }
 
 // Bảng chứa các đơn hàng tổng của khách hàng (một lần checkout)
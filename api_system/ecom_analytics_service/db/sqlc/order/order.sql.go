@@ -83,7 +83,7 @@ func (q *Queries) GetOrderIDsFromShopOrders(ctx context.Context, shopOrderIds []
 }
 
 const getOrderItemsByShopOrderID = `-- name: GetOrderItemsByShopOrderID :many
-SELECT id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price, promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot FROM order_items
+SELECT id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price, promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot, category_id FROM order_items
 WHERE shop_order_id = ?
 `
 
@@ -110,6 +110,7 @@ func (q *Queries) GetOrderItemsByShopOrderID(ctx context.Context, shopOrderID st
 			&i.ProductNameSnapshot,
 			&i.ProductImageSnapshot,
 			&i.SkuAttributesSnapshot,
+			&i.CategoryID,
 		); err != nil {
 			return nil, err
 		}
@@ -126,16 +127,17 @@ func (q *Queries) GetOrderItemsByShopOrderID(ctx context.Context, shopOrderID st
 
 const getPlatformGMVTimeSeries = `-- name: GetPlatformGMVTimeSeries :many
 SELECT
-    DATE(completed_at) AS report_date,
+    TIMESTAMP(DATE(completed_at), MAKETIME(HOUR(completed_at), FLOOR(MINUTE(completed_at) / 15) * 15, 0)) AS slot_start,
+    COUNT(*) AS order_count,
     COALESCE(SUM(subtotal), 0.00) AS gmv
 FROM shop_orders
 WHERE
         ? IS NULL 
         OR ? IS NULL 
-        OR completed_at BETWEEN ? AND ?
+        OR (completed_at >= ? AND completed_at < ?)
     
-GROUP BY report_date
-ORDER BY report_date ASC
+GROUP BY slot_start
+ORDER BY slot_start ASC
 `
 
 type GetPlatformGMVTimeSeriesParams struct {
@@ -144,10 +146,12 @@ type GetPlatformGMVTimeSeriesParams struct {
 }
 
 type GetPlatformGMVTimeSeriesRow struct {
-	ReportDate time.Time   `json:"report_date"`
+	SlotStart  time.Time   `json:"slot_start"`
+	OrderCount int64       `json:"order_count"`
 	Gmv        interface{} `json:"gmv"`
 }
 
+// Tác dụng: Lấy GMV và số đơn toàn sàn theo khung 15 phút (API: GET /platform/finance/revenue-timeseries)
 func (q *Queries) GetPlatformGMVTimeSeries(ctx context.Context, arg GetPlatformGMVTimeSeriesParams) ([]GetPlatformGMVTimeSeriesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPlatformGMVTimeSeries,
 		arg.StartDate,
@@ -162,7 +166,7 @@ func (q *Queries) GetPlatformGMVTimeSeries(ctx context.Context, arg GetPlatformG
 	var items []GetPlatformGMVTimeSeriesRow
 	for rows.Next() {
 		var i GetPlatformGMVTimeSeriesRow
-		if err := rows.Scan(&i.ReportDate, &i.Gmv); err != nil {
+		if err := rows.Scan(&i.SlotStart, &i.OrderCount, &i.Gmv); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -561,7 +565,8 @@ func (q *Queries) GetShopOrdersByOrderID(ctx context.Context, orderID string) ([
 
 const getShopRevenueTimeSeries = `-- name: GetShopRevenueTimeSeries :many
 SELECT
-    DATE(completed_at) AS report_date,
+    TIMESTAMP(DATE(completed_at), MAKETIME(HOUR(completed_at), FLOOR(MINUTE(completed_at) / 15) * 15, 0)) AS slot_start,
+    COUNT(*) AS order_count,
     COALESCE(SUM(subtotal), 0.00) AS gmv
 FROM shop_orders
 WHERE
@@ -569,10 +574,10 @@ WHERE
     AND (
         ? IS NULL 
         OR ? IS NULL 
-        OR completed_at BETWEEN ? AND ?
+        OR (completed_at >= ? AND completed_at < ?)
     )
-GROUP BY report_date
-ORDER BY report_date ASC
+GROUP BY slot_start
+ORDER BY slot_start ASC
 `
 
 type GetShopRevenueTimeSeriesParams struct {
@@ -582,11 +587,13 @@ type GetShopRevenueTimeSeriesParams struct {
 }
 
 type GetShopRevenueTimeSeriesRow struct {
-	ReportDate time.Time   `json:"report_date"`
+	SlotStart  time.Time   `json:"slot_start"`
+	OrderCount int64       `json:"order_count"`
 	Gmv        interface{} `json:"gmv"`
 }
 
-// Tác dụng: Lấy dữ liệu doanh thu GMV theo ngày để vẽ biểu đồ (API: GET /shop/revenue/timeseries)
+// Tác dụng: Lấy GMV và số đơn theo từng khung 15 phút để gom lại theo giờ/ngày/tuần/tháng (API: GET /shop/revenue/timeseries)
+// Khung 15 phút là đơn vị nhỏ nhất của mọi múi giờ nên service gom lại theo múi giờ bất kỳ mà không lệch
 func (q *Queries) GetShopRevenueTimeSeries(ctx context.Context, arg GetShopRevenueTimeSeriesParams) ([]GetShopRevenueTimeSeriesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getShopRevenueTimeSeries,
 		arg.ShopID,
@@ -602,7 +609,7 @@ func (q *Queries) GetShopRevenueTimeSeries(ctx context.Context, arg GetShopReven
 	var items []GetShopRevenueTimeSeriesRow
 	for rows.Next() {
 		var i GetShopRevenueTimeSeriesRow
-		if err := rows.Scan(&i.ReportDate, &i.Gmv); err != nil {
+		if err := rows.Scan(&i.SlotStart, &i.OrderCount, &i.Gmv); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -9,6 +9,10 @@ import (
 )
 
 type Querier interface {
+	// Tác dụng: Doanh số theo danh mục theo ngày hoàn thành đơn (rollup_category_daily)
+	GetCategorySalesInRange(ctx context.Context, arg GetCategorySalesInRangeParams) ([]GetCategorySalesInRangeRow, error)
+	// Tác dụng: Thời điểm đơn hàng đầu tiên, mốc bắt đầu mặc định của backfill
+	GetFirstShopOrderCreatedAt(ctx context.Context) (interface{}, error)
 	// Tác dụng: Lấy thông tin đơn hàng TỔNG (API: GET /platform/orders/{id}/detail)
 	GetOrderByID(ctx context.Context, id string) (Orders, error)
 	// Tác dụng: Lấy danh sách ID đơn hàng TỔNG (Dùng nội bộ để truy vấn CSDL khác)
@@ -30,6 +34,11 @@ type Querier interface {
 	GetPlatformTopUsersBySpend(ctx context.Context, arg GetPlatformTopUsersBySpendParams) ([]GetPlatformTopUsersBySpendRow, error)
 	// TácG: Thống kê hiệu suất voucher Sàn (API: GET /platform/vouchers/performance/platform)
 	GetPlatformVoucherPerformance(ctx context.Context, arg GetPlatformVoucherPerformanceParams) (GetPlatformVoucherPerformanceRow, error)
+	// Tác dụng: Sản phẩm bán ra theo ngày hoàn thành đơn (rollup_product_daily)
+	// Cùng 1 SKU có thể ra nhiều dòng nếu snapshot tên/thuộc tính khác nhau, service gộp lại
+	GetProductSalesInRange(ctx context.Context, arg GetProductSalesInRangeParams) ([]GetProductSalesInRangeRow, error)
+	// Tác dụng: Số đơn/GMV theo ngày hoàn thành của từng shop (rollup_shop_daily.completed_*)
+	GetShopCompletedStatsInRange(ctx context.Context, arg GetShopCompletedStatsInRangeParams) ([]GetShopCompletedStatsInRangeRow, error)
 	// Tác dụng: Lấy chi tiết 1 đơn hàng của Shop (API: GET /shop/orders/{id}/enriched)
 	GetShopOrderByID(ctx context.Context, arg GetShopOrderByIDParams) (ShopOrders, error)
 	// Tác dụng: Lấy danh sách ID đơn hàng của Shop (Dùng nội bộ để truy vấn CSDL khác)
@@ -39,8 +48,16 @@ type Querier interface {
 	// =================================================================
 	// Tác dụng: Lấy chỉ số tổng quan cho Dashboard của Shop (API: GET /shop/overview)
 	GetShopOrderOverview(ctx context.Context, arg GetShopOrderOverviewParams) (GetShopOrderOverviewRow, error)
+	// Tác dụng: Lấy các đơn hàng shop theo ID (xác định shop của settlement, ngày cần tính lại khi có sự kiện)
+	GetShopOrdersByIDs(ctx context.Context, shopOrderIds []string) ([]ShopOrders, error)
 	// Tác dụng: Lấy các đơn hàng SHOP con của đơn hàng TỔNG (API: GET /platform/orders/{id}/detail)
 	GetShopOrdersByOrderID(ctx context.Context, orderID string) ([]ShopOrders, error)
+	// =================================================================
+	// III. NGUỒN CHO BẢNG TỔNG HỢP (rollup) - chỉ dùng khi tính lại 1 ngày hoặc backfill
+	// Khoảng thời gian luôn là [from, to)
+	// =================================================================
+	// Tác dụng: Số đơn/GMV theo ngày tạo của từng shop (rollup_shop_daily.placed_*)
+	GetShopPlacedStatsInRange(ctx context.Context, arg GetShopPlacedStatsInRangeParams) ([]GetShopPlacedStatsInRangeRow, error)
 	// Tác dụng: Lấy dữ liệu doanh thu GMV theo ngày để vẽ biểu đồ (API: GET /shop/revenue/timeseries)
 	GetShopRevenueTimeSeries(ctx context.Context, arg GetShopRevenueTimeSeriesParams) ([]GetShopRevenueTimeSeriesRow, error)
 	// Tác dụng: Xếp hạng sản phẩm theo Số lượng bán (API: GET /shop/ranking/products/by-quantity)
@@ -49,9 +66,18 @@ type Querier interface {
 	GetShopTopProductsByRevenue(ctx context.Context, arg GetShopTopProductsByRevenueParams) ([]GetShopTopProductsByRevenueRow, error)
 	// Tác dụng: Lấy lịch sử sử dụng của 1 voucher (API: GET /shop/vouchers/{id}/detail)
 	GetVoucherUsageHistory(ctx context.Context, arg GetVoucherUsageHistoryParams) ([]VoucherUsageHistory, error)
+	// Tác dụng: Lượt dùng voucher theo ngày sử dụng (rollup_voucher_daily)
+	GetVoucherUsageInRange(ctx context.Context, arg GetVoucherUsageInRangeParams) ([]GetVoucherUsageInRangeRow, error)
 	// Tác dụng: Thống kê hiệu suất voucher của Shop (API: GET /shop/vouchers/performance)
 	// Lưu ý: CSDL voucher_usage_history thiếu shop_order_id, nên chúng ta join bằng voucher_id
 	GetVoucherUsagePerformanceByOwner(ctx context.Context, arg GetVoucherUsagePerformanceByOwnerParams) (GetVoucherUsagePerformanceByOwnerRow, error)
+	// Tác dụng: Lần mua gần nhất, số đơn và tổng chi tiêu của từng người mua trong khoảng (API: GET /platform/buyers/rfm)
+	ListBuyerRFM(ctx context.Context, arg ListBuyerRFMParams) ([]ListBuyerRFMRow, error)
+	// Tác dụng: Số người mua của từng cohort và số người có đơn hợp lệ tiếp theo trong vòng repeat_days ngày sau đơn đầu tiên
+	ListCohortRepeatBuyers(ctx context.Context, arg ListCohortRepeatBuyersParams) ([]ListCohortRepeatBuyersRow, error)
+	// Tác dụng: Số người mua có đơn hợp lệ ở tháng thứ month_offset kể từ tháng mua đầu tiên (API: GET /platform/cohorts/retention)
+	// cohort_month dạng YYYYMM, chỉ lấy các cohort có đơn đầu tiên trong khoảng
+	ListCohortRetention(ctx context.Context, arg ListCohortRetentionParams) ([]ListCohortRetentionRow, error)
 	// Tác dụng: Lấy danh sách TẤT CẢ đơn hàng trên Sàn (API: GET /platform/orders)
 	ListPlatformOrders(ctx context.Context, arg ListPlatformOrdersParams) ([]ShopOrders, error)
 	// =================================================================
@@ -60,6 +86,14 @@ type Querier interface {
 	// Tác dụng: Lấy tất cả voucher trên Sàn (API: GET /platform/vouchers)
 	// ĐÃ SỬA: Bỏ các cast '::text'
 	ListPlatformVouchers(ctx context.Context, arg ListPlatformVouchersParams) ([]Vouchers, error)
+	// =================================================================
+	// IV. PHÂN TÍCH HÀNH VI MUA HÀNG (phễu đơn hàng, cohort, RFM)
+	// Khoảng thời gian luôn là [from, to)
+	// Đơn hợp lệ: đơn shop đã được xác nhận (PROCESSING, SHIPPED, COMPLETED)
+	// Tháng tính theo múi giờ của phiên MySQL (+07:00)
+	// =================================================================
+	// Tác dụng: Mốc thời gian từng bước của các đơn shop tạo trong khoảng (API: GET /platform/funnel/orders)
+	ListShopOrderStageTimes(ctx context.Context, arg ListShopOrderStageTimesParams) ([]ListShopOrderStageTimesRow, error)
 	// Tác dụng: Lấy danh sách đơn hàng (phân trang) cho Shop (API: GET /shop/orders)
 	ListShopOrders(ctx context.Context, arg ListShopOrdersParams) ([]ShopOrders, error)
 	// =================================================================
@@ -35,19 +35,11 @@ type Querier interface {
 	// Tác dụng: Lấy trạng thái thanh toán cho 1 loạt đơn hàng (Dùng nội bộ)
 	// ĐÃ SỬA: Sửa 'ANY(sqlc.slice())' thành 'IN (sqlc.slice())'
 	GetTransactionStatusesByOrderIDs(ctx context.Context, orderIds []sql.NullString) ([]GetTransactionStatusesByOrderIDsRow, error)
-	// Tác dụng: Lấy lịch sử giao dịch của 1 ví cụ thể (API: GET /platform/finance/ledgers/{id}/entries)
-	ListLedgerEntriesByLedgerID(ctx context.Context, arg ListLedgerEntriesByLedgerIDParams) ([]LedgerEntries, error)
-	// Tác dụng: Lấy lịch sử giao dịch ví của Shop (API: GET /shop/wallet/ledger-entries)
-	ListLedgerEntriesByOwnerID(ctx context.Context, arg ListLedgerEntriesByOwnerIDParams) ([]LedgerEntries, error)
-	// Tác dụng: Lấy tất cả ví trên Sàn (API: GET /platform/finance/ledgers)
-	// ĐÃ SỬA: Bỏ các cast '::text'
-	ListPlatformLedgers(ctx context.Context, arg ListPlatformLedgersParams) ([]AccountLedgers, error)
-	// Tác dụng: Lấy tất cả bản ghi đối soát (API: GET /platform/finance/settlements)
-	// ĐÃ SỬA: Bỏ các cast '::text'
-	ListPlatformSettlements(ctx context.Context, arg ListPlatformSettlementsParams) ([]ShopOrderSettlements, error)
-	// Tác dụng: Lấy tất cả giao dịch (API: GET /platform/finance/transactions)
-	// ĐÃ SỬA: Bỏ các cast '::text'
-	ListPlatformTransactions(ctx context.Context, arg ListPlatformTransactionsParams) ([]Transactions, error)
+	// =================================================================
+	// III. NGUỒN CHO BẢNG TỔNG HỢP (rollup)
+	// =================================================================
+	// Tác dụng: Các settlement đã SETTLED trong khoảng [from, to) theo settled_at (rollup_shop_daily.*_revenue)
+	ListSettledSettlementsInRange(ctx context.Context, arg ListSettledSettlementsInRangeParams) ([]ShopOrderSettlements, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -74,7 +74,7 @@ func (q *Queries) GetPlatformCostSummary(ctx context.Context, arg GetPlatformCos
 
 const getPlatformCostTimeSeries = `-- name: GetPlatformCostTimeSeries :many
 SELECT
-    DATE(created_at) AS report_date,
+    TIMESTAMP(DATE(created_at), MAKETIME(HOUR(created_at), FLOOR(MINUTE(created_at) / 15) * 15, 0)) AS slot_start,
     COALESCE(SUM(
         site_order_voucher_discount_amount +
         site_promotion_discount_amount +
@@ -82,9 +82,9 @@ SELECT
         total_site_funded_product_discount
     ), 0.00) AS total_cost
 FROM order_platform_costs
-WHERE created_at BETWEEN ? AND ?
-GROUP BY report_date
-ORDER BY report_date ASC
+WHERE created_at >= ? AND created_at < ?
+GROUP BY slot_start
+ORDER BY slot_start ASC
 `
 
 type GetPlatformCostTimeSeriesParams struct {
@@ -93,11 +93,11 @@ type GetPlatformCostTimeSeriesParams struct {
 }
 
 type GetPlatformCostTimeSeriesRow struct {
-	ReportDate time.Time   `json:"report_date"`
-	TotalCost  interface{} `json:"total_cost"`
+	SlotStart time.Time   `json:"slot_start"`
+	TotalCost interface{} `json:"total_cost"`
 }
 
-// Tác dụng: Vẽ biểu đồ Chi phí Sàn (API: GET /platform/finance/revenue-timeseries)
+// Tác dụng: Vẽ biểu đồ Chi phí Sàn theo khung 15 phút (API: GET /platform/finance/revenue-timeseries)
 func (q *Queries) GetPlatformCostTimeSeries(ctx context.Context, arg GetPlatformCostTimeSeriesParams) ([]GetPlatformCostTimeSeriesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPlatformCostTimeSeries, arg.FromCreatedAt, arg.ToCreatedAt)
 	if err != nil {
@@ -107,7 +107,7 @@ func (q *Queries) GetPlatformCostTimeSeries(ctx context.Context, arg GetPlatform
 	var items []GetPlatformCostTimeSeriesRow
 	for rows.Next() {
 		var i GetPlatformCostTimeSeriesRow
-		if err := rows.Scan(&i.ReportDate, &i.TotalCost); err != nil {
+		if err := rows.Scan(&i.SlotStart, &i.TotalCost); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -155,14 +155,15 @@ func (q *Queries) GetPlatformRevenueSummary(ctx context.Context, arg GetPlatform
 
 const getPlatformRevenueTimeSeries = `-- name: GetPlatformRevenueTimeSeries :many
 SELECT
-    DATE(settled_at) AS report_date,
+    TIMESTAMP(DATE(settled_at), MAKETIME(HOUR(settled_at), FLOOR(MINUTE(settled_at) / 15) * 15, 0)) AS slot_start,
     COALESCE(SUM(commission_fee), 0.00) AS platform_revenue
 FROM shop_order_settlements
 WHERE
     status = 'SETTLED'
-    AND settled_at BETWEEN ? AND ?
-GROUP BY report_date
-ORDER BY report_date ASC
+    AND settled_at >= ?
+    AND settled_at < ?
+GROUP BY slot_start
+ORDER BY slot_start ASC
 `
 
 type GetPlatformRevenueTimeSeriesParams struct {
@@ -171,11 +172,11 @@ type GetPlatformRevenueTimeSeriesParams struct {
 }
 
 type GetPlatformRevenueTimeSeriesRow struct {
-	ReportDate      time.Time   `json:"report_date"`
+	SlotStart       time.Time   `json:"slot_start"`
 	PlatformRevenue interface{} `json:"platform_revenue"`
 }
 
-// Tác dụng: Vẽ biểu đồ Doanh thu Sàn (API: GET /platform/finance/revenue-timeseries)
+// Tác dụng: Vẽ biểu đồ Doanh thu Sàn theo khung 15 phút (API: GET /platform/finance/revenue-timeseries)
 func (q *Queries) GetPlatformRevenueTimeSeries(ctx context.Context, arg GetPlatformRevenueTimeSeriesParams) ([]GetPlatformRevenueTimeSeriesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getPlatformRevenueTimeSeries, arg.FromSettledAt, arg.ToSettledAt)
 	if err != nil {
@@ -185,7 +186,7 @@ func (q *Queries) GetPlatformRevenueTimeSeries(ctx context.Context, arg GetPlatf
 	var items []GetPlatformRevenueTimeSeriesRow
 	for rows.Next() {
 		var i GetPlatformRevenueTimeSeriesRow
-		if err := rows.Scan(&i.ReportDate, &i.PlatformRevenue); err != nil {
+		if err := rows.Scan(&i.SlotStart, &i.PlatformRevenue); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -331,283 +332,3 @@ func (q *Queries) GetTransactionStatusesByOrderIDs(ctx context.Context, orderIds
 	}
 	return items, nil
 }
-
-const listLedgerEntriesByLedgerID = `-- name: ListLedgerEntriesByLedgerID :many
-SELECT id, ledger_id, transaction_id, amount, type, description, created_at FROM ledger_entries
-WHERE ledger_id = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
-`
-
-type ListLedgerEntriesByLedgerIDParams struct {
-	LedgerID string `json:"ledger_id"`
-	Limit    int32  `json:"limit"`
-	Offset   int32  `json:"offset"`
-}
-
-// Tác dụng: Lấy lịch sử giao dịch của 1 ví cụ thể (API: GET /platform/finance/ledgers/{id}/entries)
-func (q *Queries) ListLedgerEntriesByLedgerID(ctx context.Context, arg ListLedgerEntriesByLedgerIDParams) ([]LedgerEntries, error) {
-	rows, err := q.db.QueryContext(ctx, listLedgerEntriesByLedgerID, arg.LedgerID, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []LedgerEntries
-	for rows.Next() {
-		var i LedgerEntries
-		if err := rows.Scan(
-			&i.ID,
-			&i.LedgerID,
-			&i.TransactionID,
-			&i.Amount,
-			&i.Type,
-			&i.Description,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listLedgerEntriesByOwnerID = `-- name: ListLedgerEntriesByOwnerID :many
-SELECT le.id, le.ledger_id, le.transaction_id, le.amount, le.type, le.description, le.created_at
-FROM ledger_entries le
-JOIN account_ledgers al ON le.ledger_id = al.id
-WHERE
-    al.owner_id = ? AND al.owner_type = 'SHOP'
-ORDER BY le.created_at DESC
-LIMIT ? OFFSET ?
-`
-
-type ListLedgerEntriesByOwnerIDParams struct {
-	OwnerID string `json:"owner_id"`
-	Limit   int32  `json:"limit"`
-	Offset  int32  `json:"offset"`
-}
-
-// Tác dụng: Lấy lịch sử giao dịch ví của Shop (API: GET /shop/wallet/ledger-entries)
-func (q *Queries) ListLedgerEntriesByOwnerID(ctx context.Context, arg ListLedgerEntriesByOwnerIDParams) ([]LedgerEntries, error) {
-	rows, err := q.db.QueryContext(ctx, listLedgerEntriesByOwnerID, arg.OwnerID, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []LedgerEntries
-	for rows.Next() {
-		var i LedgerEntries
-		if err := rows.Scan(
-			&i.ID,
-			&i.LedgerID,
-			&i.TransactionID,
-			&i.Amount,
-			&i.Type,
-			&i.Description,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listPlatformLedgers = `-- name: ListPlatformLedgers :many
-SELECT id, owner_id, owner_type, balance, pending_balance, created_at, updated_at FROM account_ledgers
-WHERE
-    (? IS NULL OR owner_type = ?)
-LIMIT ? OFFSET ?
-`
-
-type ListPlatformLedgersParams struct {
-	OwnerTypeFilter NullAccountLedgersOwnerType `json:"owner_type_filter"`
-	Limit           int32                       `json:"limit"`
-	Offset          int32                       `json:"offset"`
-}
-
-// Tác dụng: Lấy tất cả ví trên Sàn (API: GET /platform/finance/ledgers)
-// ĐÃ SỬA: Bỏ các cast '::text'
-func (q *Queries) ListPlatformLedgers(ctx context.Context, arg ListPlatformLedgersParams) ([]AccountLedgers, error) {
-	rows, err := q.db.QueryContext(ctx, listPlatformLedgers,
-		arg.OwnerTypeFilter,
-		arg.OwnerTypeFilter,
-		arg.Limit,
-		arg.Offset,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []AccountLedgers
-	for rows.Next() {
-		var i AccountLedgers
-		if err := rows.Scan(
-			&i.ID,
-			&i.OwnerID,
-			&i.OwnerType,
-			&i.Balance,
-			&i.PendingBalance,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listPlatformSettlements = `-- name: ListPlatformSettlements :many
-SELECT id, shop_order_id, order_transaction_id, status, order_subtotal, shop_funded_product_discount, site_funded_product_discount, shop_voucher_discount, shop_shipping_discount, shipping_fee, commission_fee, net_settled_amount, order_completed_at, settled_at FROM shop_order_settlements
-WHERE
-    (? IS NULL OR status = ?)
-    AND (
-        ? IS NULL 
-        OR ? IS NULL 
-        OR order_completed_at BETWEEN ? AND ?
-    )
-ORDER BY order_completed_at DESC
-LIMIT ? OFFSET ?
-`
-
-type ListPlatformSettlementsParams struct {
-	StatusFilter NullShopOrderSettlementsStatus `json:"status_filter"`
-	StartDate    sql.NullTime                   `json:"start_date"`
-	EndDate      sql.NullTime                   `json:"end_date"`
-	Limit        int32                          `json:"limit"`
-	Offset       int32                          `json:"offset"`
-}
-
-// Tác dụng: Lấy tất cả bản ghi đối soát (API: GET /platform/finance/settlements)
-// ĐÃ SỬA: Bỏ các cast '::text'
-func (q *Queries) ListPlatformSettlements(ctx context.Context, arg ListPlatformSettlementsParams) ([]ShopOrderSettlements, error) {
-	rows, err := q.db.QueryContext(ctx, listPlatformSettlements,
-		arg.StatusFilter,
-		arg.StatusFilter,
-		arg.StartDate,
-		arg.EndDate,
-		arg.StartDate,
-		arg.EndDate,
-		arg.Limit,
-		arg.Offset,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ShopOrderSettlements
-	for rows.Next() {
-		var i ShopOrderSettlements
-		if err := rows.Scan(
-			&i.ID,
-			&i.ShopOrderID,
-			&i.OrderTransactionID,
-			&i.Status,
-			&i.OrderSubtotal,
-			&i.ShopFundedProductDiscount,
-			&i.SiteFundedProductDiscount,
-			&i.ShopVoucherDiscount,
-			&i.ShopShippingDiscount,
-			&i.ShippingFee,
-			&i.CommissionFee,
-			&i.NetSettledAmount,
-			&i.OrderCompletedAt,
-			&i.SettledAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const listPlatformTransactions = `-- name: ListPlatformTransactions :many
-SELECT id, transaction_code, order_id, payment_method_id, amount, currency, type, status, gateway_transaction_id, notes, created_at, processed_at FROM transactions
-WHERE
-    (? IS NULL OR type = ?)
-    AND (? IS NULL OR status = ?)
-    AND (created_at BETWEEN ? AND ?)
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
-`
-
-type ListPlatformTransactionsParams struct {
-	TypeFilter   NullTransactionsType   `json:"type_filter"`
-	StatusFilter NullTransactionsStatus `json:"status_filter"`
-	StartDate    sql.NullTime           `json:"start_date"`
-	EndDate      sql.NullTime           `json:"end_date"`
-	Limit        int32                  `json:"limit"`
-	Offset       int32                  `json:"offset"`
-}
-
-// Tác dụng: Lấy tất cả giao dịch (API: GET /platform/finance/transactions)
-// ĐÃ SỬA: Bỏ các cast '::text'
-func (q *Queries) ListPlatformTransactions(ctx context.Context, arg ListPlatformTransactionsParams) ([]Transactions, error) {
-	rows, err := q.db.QueryContext(ctx, listPlatformTransactions,
-		arg.TypeFilter,
-		arg.TypeFilter,
-		arg.StatusFilter,
-		arg.StatusFilter,
-		arg.StartDate,
-		arg.EndDate,
-		arg.Limit,
-		arg.Offset,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Transactions
-	for rows.Next() {
-		var i Transactions
-		if err := rows.Scan(
-			&i.ID,
-			&i.TransactionCode,
-			&i.OrderID,
-			&i.PaymentMethodID,
-			&i.Amount,
-			&i.Currency,
-			&i.Type,
-			&i.Status,
-			&i.GatewayTransactionID,
-			&i.Notes,
-			&i.CreatedAt,
-			&i.ProcessedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
@@ -0,0 +1,25 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// GetSaramaConfig trả về một cấu hình sarama chuẩn cho consumer
+func GetSaramaConfig() *sarama.Config {
+	config := sarama.NewConfig()
+
+	// Cấu hình chung
+	config.Version = sarama.V2_8_0_0 // Đặt phiên bản Kafka broker của bạn
+	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	config.Net.ReadTimeout = 10 * time.Second
+	config.Net.WriteTimeout = 10 * time.Second
+
+	// Cấu hình Consumer Group (quan trọng)
+	config.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRoundRobin()
+	config.Consumer.Offsets.AutoCommit.Enable = true // Tự động commit
+	config.Consumer.Offsets.AutoCommit.Interval = 1 * time.Second
+
+	return config
+}
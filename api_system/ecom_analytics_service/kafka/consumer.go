@@ -0,0 +1,117 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/TranVinhHien/ecom_analytics_service/services"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+)
+
+const (
+	TopicOrderStatusChanged = "order.status_changed"
+	TopicPaymentCompleted   = "payment.completed"
+	TopicPaymentFailed      = "payment.failed"
+	TopicSettlementUpdated  = "settlement.updated"
+)
+
+// RollupTopics các topic làm thay đổi số liệu của bảng tổng hợp
+var RollupTopics = []string{TopicOrderStatusChanged, TopicPaymentCompleted, TopicPaymentFailed, TopicSettlementUpdated}
+
+// RollupEventData phần chung của các sự kiện, chỉ cần biết đơn nào thay đổi và lúc nào
+type RollupEventData struct {
+	ShopOrderID string     `json:"shop_order_id"`
+	OrderID     string     `json:"order_id"` // payment.* chỉ có order_id (cha)
+	OccurredAt  *time.Time `json:"occurred_at"`
+}
+
+// KafkaConsumerHandler là adapter, nó implement interface của Sarama
+type KafkaConsumerHandler struct {
+	service services.ServiceUseCase // "Service" chứa logic nghiệp vụ
+	ready   chan bool
+}
+
+// NewKafkaConsumerHandler tạo một handler mới
+func NewKafkaConsumerHandler(service services.ServiceUseCase) *KafkaConsumerHandler {
+	return &KafkaConsumerHandler{
+		service: service,
+		ready:   make(chan bool),
+	}
+}
+
+// Ready trả về channel để báo hiệu consumer đã sẵn sàng
+func (h *KafkaConsumerHandler) Ready() <-chan bool {
+	return h.ready
+}
+func (h *KafkaConsumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	log.Println("Kafka consumer is setup and ready.")
+	// Đóng channel 'ready' để báo cho main.go biết là đã sẵn sàng
+	close(h.ready)
+	return nil
+}
+
+// Cleanup được gọi khi session kết thúc
+func (h *KafkaConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim là vòng lặp chính xử lý message
+func (h *KafkaConsumerHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case message, ok := <-claim.Messages():
+			if !ok {
+				log.Println("Message channel closed, exiting ConsumeClaim.")
+				return nil
+			}
+
+			ctx := session.Context()
+			// Kafka không gửi lại riêng message lỗi khi message sau đã được commit,
+			// nên thử lại ngay tại chỗ cho tới khi xử lý được hoặc session kết thúc.
+			for attempt := 1; ; attempt++ {
+				err := h.handleMessage(ctx, message)
+				if err == nil {
+					break
+				}
+				log.Printf("ERROR processing message (topic %s, offset %d, lần %d): %v. Will retry.", message.Topic, message.Offset, attempt, err)
+				select {
+				case <-time.After(min(time.Duration(attempt)*time.Second, 30*time.Second)):
+				case <-ctx.Done():
+					// không commit, consumer nhận partition sau sẽ đọc lại từ message này
+					return nil
+				}
+			}
+			session.MarkMessage(message, "")
+
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// handleMessage xử lý một message, lỗi trả về sẽ được thử lại
+func (h *KafkaConsumerHandler) handleMessage(ctx context.Context, message *sarama.ConsumerMessage) error {
+	switch message.Topic {
+	case TopicOrderStatusChanged, TopicPaymentCompleted, TopicPaymentFailed, TopicSettlementUpdated:
+		var data RollupEventData
+		if errJSON := json.Unmarshal(message.Value, &data); errJSON != nil {
+			// message hỏng thì gửi lại cũng không đọc được, bỏ qua để không chặn partition
+			log.Printf("ERROR unmarshaling message (topic %s, offset %d): %v. Skipped.", message.Topic, message.Offset, errJSON)
+			return nil
+		}
+		event := entity.RollupEvent{OrderID: data.OrderID, OccurredAt: message.Timestamp}
+		if data.ShopOrderID != "" {
+			event.ShopOrderIDs = []string{data.ShopOrderID}
+		}
+		if data.OccurredAt != nil {
+			event.OccurredAt = *data.OccurredAt
+		}
+		return h.service.MarkRollupDirty(ctx, event)
+	default:
+		log.Printf("WARN: Nhận được message từ topic lạ: %s", message.Topic)
+	}
+	return nil
+}
@@ -4,16 +4,22 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"os"
+	"sync"
 	"time"
 
 	config_assets "github.com/TranVinhHien/ecom_analytics_service/assets/config"
+	assets_jobs "github.com/TranVinhHien/ecom_analytics_service/assets/jobs"
 	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
 	"github.com/TranVinhHien/ecom_analytics_service/controllers"
 	db "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	"github.com/TranVinhHien/ecom_analytics_service/kafka"
 	"github.com/TranVinhHien/ecom_analytics_service/server"
 	"github.com/TranVinhHien/ecom_analytics_service/services"
 
+	"github.com/IBM/sarama"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	_ "github.com/go-sql-driver/mysql"
@@ -93,6 +99,13 @@ func main() {
 
 	// setup service
 	services := services.NewService(db_order, db_transaction, db_interact, db_agent_ai_db, jwtMaker, env, APIServer)
+
+	// go run . backfill -from 2024-01-01 -to 2024-12-31: tính lại bảng tổng hợp rồi thoát
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		runBackfill(services, os.Args[2:])
+		return
+	}
+
 	// setup controller
 	controller := controllers.NewAPIController(services, jwtMaker)
 
@@ -104,7 +117,7 @@ func main() {
 		AllowOrigins:     env.ClientIP,                                        // Chỉ cho phép localhost:3000
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Các method được phép
 		AllowHeaders:     []string{"Content-Type", "Origin", "Authorization"}, // Các headers được phép
-		ExposeHeaders:    []string{"Content-Length"},                          // Các headers trả về
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition"},   // Các headers trả về (Content-Disposition chứa tên file xuất)
 		AllowCredentials: true,                                                // Cho phép cookies
 	}
 
@@ -114,6 +127,26 @@ func main() {
 
 	log.Info().Msg("Starting server on port " + env.HTTPServerAddress)
 
+	// start jobs
+	job, err := assets_jobs.NewJobScheduler()
+	if err != nil {
+		log.Err(err).Msg("Error when created job scheduler")
+		return
+	}
+	runJobs(services, job)
+
+	// Start Kafka consumer (sự kiện đơn hàng/thanh toán để cập nhật bảng tổng hợp)
+	if env.KafkaBrokers != "" {
+		brokers := []string{env.KafkaBrokers}
+		consumerGroup, err := sarama.NewConsumerGroup(brokers, env.KafkaConsumerGroup, kafka.GetSaramaConfig())
+		if err != nil {
+			log.Err(err).Msg("Failed to create consumer group, rollups are refreshed only by backfill")
+		} else {
+			defer consumerGroup.Close()
+			go runConsumerGroup(consumerGroup, kafka.RollupTopics, services)
+		}
+	}
+
 	engine.Run(env.HTTPServerAddress)
 
 }
@@ -156,3 +189,85 @@ func connectDBWithRetry(times int, dbConfig string) (*sql.DB, error) {
 	}
 	return nil, e
 }
+
+func runJobs(s services.ServiceUseCase, jobScheduler *assets_jobs.JobScheduler) {
+	ctx := context.Background()
+	var refreshing sync.Mutex
+	// mốc quét đối soát vừa SETTLED, lần chạy đầu quét lại 1 ngày gần nhất để bù thời gian service dừng
+	settledFrom := time.Now().Add(-24 * time.Hour)
+
+	jobScheduler.NewJob(1, 0, 0, func() {
+		// bỏ qua lượt này nếu lượt trước chưa tính xong
+		if !refreshing.TryLock() {
+			return
+		}
+		defer refreshing.Unlock()
+		// chừa vài giây cuối cho các transaction chưa commit, lượt sau sẽ quét tiếp
+		settledTo := time.Now().Add(-10 * time.Second)
+		if err := s.MarkSettledRollupsDirty(ctx, settledFrom, settledTo); err != nil {
+			log.Err(err).Msg("Error mark settled rollups dirty")
+		} else {
+			settledFrom = settledTo
+		}
+		if err := s.RefreshDirtyRollups(ctx); err != nil {
+			log.Err(err).Msg("Error refresh dirty rollups")
+		}
+	})
+
+	jobScheduler.NewJob(0, 1, 0, func() {
+		if err := s.CleanupExportJobs(ctx); err != nil {
+			log.Err(err).Msg("Error cleanup export jobs")
+		}
+	})
+	jobScheduler.Start()
+}
+
+func runConsumerGroup(consumerGroup sarama.ConsumerGroup, topics []string, services services.ServiceUseCase) {
+	ctx := context.Background()
+	for {
+		// Consume sẽ block và chạy vòng lặp ConsumeClaim, trả về khi rebalance
+		err := consumerGroup.Consume(ctx, topics, kafka.NewKafkaConsumerHandler(services))
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return
+		}
+		if err != nil {
+			// lỗi kết nối broker/rebalance: chờ rồi tham gia lại group thay vì dừng hẳn consumer
+			log.Err(err).Msg("Error from consumer, rejoining")
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// runBackfill tính lại bảng tổng hợp cho khoảng ngày [-from, -to] (giờ Việt Nam).
+// Bỏ trống -from thì bắt đầu từ đơn hàng đầu tiên, bỏ trống -to thì tới hôm nay.
+func runBackfill(s services.ServiceUseCase, args []string) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromFlag := flags.String("from", "", "ngày bắt đầu (2006-01-02)")
+	toFlag := flags.String("to", "", "ngày kết thúc (2006-01-02)")
+	flags.Parse(args)
+
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		log.Err(err).Msg("Error load timezone")
+		return
+	}
+	var from, to time.Time
+	if *fromFlag != "" {
+		if from, err = time.ParseInLocation("2006-01-02", *fromFlag, loc); err != nil {
+			log.Err(err).Msg("Invalid -from")
+			return
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.ParseInLocation("2006-01-02", *toFlag, loc); err != nil {
+			log.Err(err).Msg("Invalid -to")
+			return
+		}
+	}
+
+	if err := s.RebuildRollups(context.Background(), from, to); err != nil {
+		log.Err(err).Msg("Backfill rollups failed")
+		return
+	}
+	log.Info().Msg("Backfill rollups done")
+}
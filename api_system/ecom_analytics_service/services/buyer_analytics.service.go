@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"golang.org/x/sync/errgroup"
+)
+
+// các bước của phễu đơn hàng theo thứ tự, ứng với created_at, paid_at, processing_at, shipped_at, completed_at
+var funnelStages = []string{"PLACED", "PAID", "PROCESSING", "SHIPPED", "COMPLETED"}
+
+const (
+	// số cohort (tháng) tối đa của 1 lần xem
+	maxCohortMonths = 36
+	// phễu quét từng đơn shop gốc nên giới hạn như API chuỗi thời gian không dùng bảng tổng hợp
+	maxFunnelDays = maxRawTimeseriesDays
+	// RFM gom mọi người mua trong khoảng vào bộ nhớ để chấm điểm, tối đa 2 năm
+	maxRFMDays = 731
+
+	rfmChampions          = "champions"
+	rfmLoyal              = "loyal"
+	rfmNewCustomers       = "new_customers"
+	rfmPotentialLoyalists = "potential_loyalists"
+	rfmCantLose           = "cant_lose"
+	rfmAtRisk             = "at_risk"
+	rfmHibernating        = "hibernating"
+)
+
+var rfmSegments = []string{rfmChampions, rfmLoyal, rfmNewCustomers, rfmPotentialLoyalists, rfmCantLose, rfmAtRisk, rfmHibernating}
+
+// GetOrderFunnel xử lý API: GET /api/v1/platform/funnel/orders
+func (s *service) GetOrderFunnel(ctx context.Context, params entity.OrderFunnelParams) (*entity.OrderFunnelResponse, *assets_services.ServiceError) {
+	from, to, serr := buyerAnalyticsRange(params.StartDate, params.EndDate, maxFunnelDays)
+	if serr != nil {
+		return nil, serr
+	}
+	rows, err := s.order.ListShopOrderStageTimes(ctx, db_order.ListShopOrderStageTimesParams{
+		FromCreatedAt: from,
+		ToCreatedAt:   to,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy mốc thời gian đơn hàng: %w", err)}
+	}
+
+	reached := make([]int64, len(funnelStages))
+	durations := make([][]float64, len(funnelStages))
+	cancelled := entity.OrderFunnelCancellation{ByStage: make(map[string]int64)}
+	var cancelDurations []float64
+	var refunded int64
+
+	for _, row := range rows {
+		times := []sql.NullTime{{Time: row.CreatedAt, Valid: true}, row.PaidAt, row.ProcessingAt, row.ShippedAt, row.CompletedAt}
+		// bước xa nhất có mốc thời gian, các bước trước đó coi như đã qua
+		last := 0
+		for i, t := range times {
+			if t.Valid {
+				last = i
+			}
+		}
+		prev := 0
+		for i := range times {
+			if i <= last {
+				reached[i]++
+			}
+			if i > 0 && times[i].Valid {
+				durations[i] = append(durations[i], times[i].Time.Sub(times[prev].Time).Seconds())
+				prev = i
+			}
+		}
+
+		if row.Status == db_order.ShopOrdersStatusCANCELLED || row.CancelledAt.Valid {
+			cancelled.Orders++
+			cancelled.ByStage[funnelStages[last]]++
+			if row.CancelledAt.Valid {
+				cancelDurations = append(cancelDurations, row.CancelledAt.Time.Sub(row.CreatedAt).Seconds())
+			}
+		}
+		if row.Status == db_order.ShopOrdersStatusREFUNDED {
+			refunded++
+		}
+	}
+
+	placed := reached[0]
+	resp := &entity.OrderFunnelResponse{Stages: make([]entity.OrderFunnelStage, len(funnelStages)), Refunded: refunded}
+	for i, stage := range funnelStages {
+		resp.Stages[i] = entity.OrderFunnelStage{
+			Stage:          stage,
+			Orders:         reached[i],
+			ConversionRate: ratePercent(reached[i], placed),
+			StepRate:       ratePercent(reached[i], placed),
+			MedianSeconds:  median(durations[i]),
+		}
+		if i > 0 {
+			resp.Stages[i].StepRate = ratePercent(reached[i], reached[i-1])
+			resp.Stages[i].DropOff = reached[i-1] - reached[i]
+		}
+	}
+	cancelled.Rate = ratePercent(cancelled.Orders, placed)
+	cancelled.MedianSeconds = median(cancelDurations)
+	resp.Cancelled = cancelled
+	return resp, nil
+}
+
+// GetCohortRetention xử lý API: GET /api/v1/platform/cohorts/retention
+func (s *service) GetCohortRetention(ctx context.Context, params entity.CohortRetentionParams) (*entity.CohortRetentionResponse, *assets_services.ServiceError) {
+	loc := rollupLocation()
+	now := time.Now().In(loc)
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	from := time.Date(params.StartMonth.Year(), params.StartMonth.Month(), 1, 0, 0, 0, 0, loc)
+	to := time.Date(params.EndMonth.Year(), params.EndMonth.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, 1, 0)
+	if to.After(currentMonth) {
+		to = currentMonth.AddDate(0, 1, 0)
+	}
+	if !from.Before(to) {
+		return nil, assets_services.NewError(http.StatusBadRequest, errors.New("start_month phải trước hoặc bằng end_month và không sau tháng hiện tại"))
+	}
+	if monthsBetween(from, to) > maxCohortMonths {
+		return nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("tối đa %d cohort (tháng) cho 1 lần xem", maxCohortMonths))
+	}
+
+	var retentionRows []db_order.ListCohortRetentionRow
+	var repeatRows []db_order.ListCohortRepeatBuyersRow
+	g, gCtx := errgroup.WithContext(ctx)
+
+	// Tác vụ 1: Ma trận giữ chân theo tháng
+	g.Go(func() error {
+		var err error
+		retentionRows, err = s.order.ListCohortRetention(gCtx, db_order.ListCohortRetentionParams{
+			FromFirstOrderAt: from,
+			ToFirstOrderAt:   to,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("lỗi khi lấy dữ liệu giữ chân: %w", err)
+		}
+		return nil
+	})
+
+	// Tác vụ 2: Số người mua lại trong RepeatDays ngày
+	g.Go(func() error {
+		var err error
+		repeatRows, err = s.order.ListCohortRepeatBuyers(gCtx, db_order.ListCohortRepeatBuyersParams{
+			RepeatDays:       params.RepeatDays,
+			FromFirstOrderAt: from,
+			ToFirstOrderAt:   to,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("lỗi khi lấy dữ liệu mua lại: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: err}
+	}
+
+	// map[YYYYMM][month_offset] -> số người mua
+	retention := make(map[int64]map[int64]int64)
+	for _, row := range retentionRows {
+		cohort, _ := entity.ParseInterfaceToInt(row.CohortMonth)
+		offset, _ := entity.ParseInterfaceToInt(row.MonthOffset)
+		if retention[cohort] == nil {
+			retention[cohort] = make(map[int64]int64)
+		}
+		retention[cohort][offset] = row.Buyers
+	}
+	repeat := make(map[int64]db_order.ListCohortRepeatBuyersRow, len(repeatRows))
+	for _, row := range repeatRows {
+		cohort, _ := entity.ParseInterfaceToInt(row.CohortMonth)
+		repeat[cohort] = row
+	}
+
+	resp := &entity.CohortRetentionResponse{RepeatDays: params.RepeatDays, Cohorts: []entity.CohortRow{}}
+	avgBuyers := make([]int64, params.Months+1)
+	avgBase := make([]int64, params.Months+1)
+	for m := from; m.Before(to); m = m.AddDate(0, 1, 0) {
+		key := int64(m.Year()*100 + int(m.Month()))
+		r := repeat[key]
+		row := entity.CohortRow{
+			Cohort:               m.Format("2006-01"),
+			Buyers:               r.Buyers,
+			RepeatBuyers:         r.RepeatBuyers,
+			RepeatRate:           ratePercent(r.RepeatBuyers, r.Buyers),
+			RepeatWindowComplete: !m.AddDate(0, 1, params.RepeatDays).After(now),
+			Retention:            []entity.CohortRetentionCell{},
+		}
+		// chỉ các tháng đã diễn ra (tới tháng hiện tại)
+		observable := min(params.Months, monthsBetween(m, currentMonth))
+		for k := 0; k <= observable; k++ {
+			buyers := retention[key][int64(k)]
+			row.Retention = append(row.Retention, entity.CohortRetentionCell{MonthOffset: k, Buyers: buyers, Rate: ratePercent(buyers, r.Buyers)})
+			avgBuyers[k] += buyers
+			avgBase[k] += r.Buyers
+		}
+		resp.Cohorts = append(resp.Cohorts, row)
+	}
+
+	resp.Average = []entity.CohortRetentionCell{}
+	for k := range avgBuyers {
+		if avgBase[k] == 0 {
+			continue
+		}
+		resp.Average = append(resp.Average, entity.CohortRetentionCell{MonthOffset: k, Buyers: avgBuyers[k], Rate: ratePercent(avgBuyers[k], avgBase[k])})
+	}
+	return resp, nil
+}
+
+// GetBuyerRFM xử lý API: GET /api/v1/platform/buyers/rfm
+func (s *service) GetBuyerRFM(ctx context.Context, params entity.BuyerRFMParams) (*entity.BuyerRFMResponse, *assets_services.ServiceError) {
+	if params.Segment != "" && !containsString(rfmSegments, params.Segment) {
+		return nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("segment không hợp lệ: %s", params.Segment))
+	}
+
+	from, to, serr := buyerAnalyticsRange(params.StartDate, params.EndDate, maxRFMDays)
+	if serr != nil {
+		return nil, serr
+	}
+	rows, err := s.order.ListBuyerRFM(ctx, db_order.ListBuyerRFMParams{
+		FromCreatedAt: from,
+		ToCreatedAt:   to,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy dữ liệu người mua: %w", err)}
+	}
+
+	buyers := make([]entity.BuyerRFMRow, len(rows))
+	recency := make([]float64, len(rows))
+	frequency := make([]float64, len(rows))
+	monetary := make([]float64, len(rows))
+	for i, row := range rows {
+		lastOrderAt, _ := row.LastOrderAt.(time.Time)
+		buyers[i] = entity.BuyerRFMRow{
+			UserID:      row.UserID,
+			LastOrderAt: lastOrderAt,
+			RecencyDays: int64(to.Sub(lastOrderAt).Hours() / 24),
+			Frequency:   row.Frequency,
+		}
+		buyers[i].Monetary, _ = entity.ParseAmountToFloat64(row.Monetary)
+		// mua càng gần đây càng tốt nên chấm điểm trên giá trị âm
+		recency[i] = -float64(buyers[i].RecencyDays)
+		frequency[i] = float64(row.Frequency)
+		monetary[i] = buyers[i].Monetary
+	}
+	rScores, fScores, mScores := rfmScores(recency), rfmScores(frequency), rfmScores(monetary)
+
+	summaries := make(map[string]*entity.RFMSegmentSummary, len(rfmSegments))
+	for _, segment := range rfmSegments {
+		summaries[segment] = &entity.RFMSegmentSummary{Segment: segment}
+	}
+	for i := range buyers {
+		buyers[i].RScore, buyers[i].FScore, buyers[i].MScore = rScores[i], fScores[i], mScores[i]
+		buyers[i].Segment = rfmSegment(rScores[i], fScores[i])
+
+		sum := summaries[buyers[i].Segment]
+		sum.Buyers++
+		sum.AvgRecencyDays += float64(buyers[i].RecencyDays)
+		sum.AvgFrequency += float64(buyers[i].Frequency)
+		sum.TotalMonetary += buyers[i].Monetary
+	}
+
+	resp := &entity.BuyerRFMResponse{TotalBuyers: int64(len(buyers)), Segments: make([]entity.RFMSegmentSummary, 0, len(rfmSegments))}
+	for _, segment := range rfmSegments {
+		sum := summaries[segment]
+		if sum.Buyers > 0 {
+			sum.AvgRecencyDays /= float64(sum.Buyers)
+			sum.AvgFrequency /= float64(sum.Buyers)
+			sum.AvgMonetary = sum.TotalMonetary / float64(sum.Buyers)
+		}
+		sum.Share = ratePercent(sum.Buyers, resp.TotalBuyers)
+		resp.Segments = append(resp.Segments, *sum)
+	}
+
+	sort.Slice(buyers, func(i, j int) bool {
+		if buyers[i].Monetary != buyers[j].Monetary {
+			return buyers[i].Monetary > buyers[j].Monetary
+		}
+		return buyers[i].UserID < buyers[j].UserID
+	})
+	filtered := buyers[:0]
+	for _, b := range buyers {
+		if params.Segment == "" || b.Segment == params.Segment {
+			filtered = append(filtered, b)
+		}
+	}
+	start := min(int(params.Offset), len(filtered))
+	end := min(start+int(params.Limit), len(filtered))
+	resp.Buyers = filtered[start:end]
+	return resp, nil
+}
+
+// buyerAnalyticsRange đổi [startDate, endDate] thành [from, to) và chặn khoảng quá maxDays ngày
+func buyerAnalyticsRange(startDate, endDate time.Time, maxDays int) (time.Time, time.Time, *assets_services.ServiceError) {
+	from, to := rollupDateRange(startDate, endDate)
+	if !from.Before(to) {
+		return from, to, assets_services.NewError(http.StatusBadRequest, errors.New("start_date phải trước hoặc bằng end_date"))
+	}
+	if to.After(from.AddDate(0, 0, maxDays)) {
+		return from, to, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("khoảng thời gian tối đa %d ngày", maxDays))
+	}
+	return from, to, nil
+}
+
+// rfmScores chấm điểm 1-5 theo thứ hạng, giá trị lớn hơn là tốt hơn.
+// Các giá trị bằng nhau cùng điểm (ví dụ mọi người mua 1 đơn đều có F = 1)
+func rfmScores(values []float64) []int {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	scores := make([]int, len(values))
+	for i, v := range values {
+		// số giá trị nhỏ hơn hẳn v
+		below := sort.SearchFloat64s(sorted, v)
+		scores[i] = 1 + below*5/len(values)
+	}
+	return scores
+}
+
+// rfmSegment phân khúc theo điểm R và F; M chỉ dùng để xếp hạng trong phân khúc
+func rfmSegment(r, f int) string {
+	switch {
+	case r >= 4 && f >= 4:
+		return rfmChampions
+	case r >= 3 && f >= 3:
+		return rfmLoyal
+	case r >= 4 && f == 1:
+		return rfmNewCustomers
+	case r >= 3:
+		return rfmPotentialLoyalists
+	case f >= 4:
+		return rfmCantLose
+	case f >= 2:
+		return rfmAtRisk
+	default:
+		return rfmHibernating
+	}
+}
+
+// ratePercent phần trăm part / total, bằng 0 khi total bằng 0
+func ratePercent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// median trung vị, nil khi không có giá trị
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	m := values[mid]
+	if len(values)%2 == 0 {
+		m = (values[mid-1] + values[mid]) / 2
+	}
+	return &m
+}
+
+// monthsBetween số tháng từ tháng của from tới tháng của to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
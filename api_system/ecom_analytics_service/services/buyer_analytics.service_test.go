@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBuyerOrderStore struct {
+	db_mysql.StoreOrder
+	stageTimes []db_order.ListShopOrderStageTimesRow
+	rfm        []db_order.ListBuyerRFMRow
+}
+
+func (f *fakeBuyerOrderStore) ListShopOrderStageTimes(ctx context.Context, arg db_order.ListShopOrderStageTimesParams) ([]db_order.ListShopOrderStageTimesRow, error) {
+	return f.stageTimes, nil
+}
+
+func (f *fakeBuyerOrderStore) ListBuyerRFM(ctx context.Context, arg db_order.ListBuyerRFMParams) ([]db_order.ListBuyerRFMRow, error) {
+	return f.rfm, nil
+}
+
+func TestRFMScores(t *testing.T) {
+	require.Equal(t, []int{1, 2, 3, 4, 5}, rfmScores([]float64{1, 2, 3, 4, 5}))
+	// điểm theo tỉ lệ số giá trị nhỏ hơn, thứ tự đầu vào giữ nguyên
+	require.Equal(t, []int{4, 1, 2}, rfmScores([]float64{30, 10, 20}))
+	// giá trị bằng nhau cùng điểm, lấy theo số giá trị nhỏ hơn hẳn
+	require.Equal(t, []int{1, 1, 1, 1}, rfmScores([]float64{1, 1, 1, 1}))
+	require.Equal(t, []int{1, 1, 4}, rfmScores([]float64{10, 10, 20}))
+	require.Equal(t, []int{1, 1, 1, 4, 4}, rfmScores([]float64{1, 1, 1, 2, 2}))
+	// recency chấm trên giá trị âm: mua gần đây điểm cao hơn
+	require.Equal(t, []int{3, 1}, rfmScores([]float64{-2, -40}))
+	require.Equal(t, []int{1}, rfmScores([]float64{7}))
+	require.Empty(t, rfmScores(nil))
+}
+
+func TestRFMSegment(t *testing.T) {
+	tests := []struct {
+		r, f int
+		want string
+	}{
+		{5, 5, rfmChampions},
+		{4, 4, rfmChampions},
+		{3, 5, rfmLoyal},
+		{5, 3, rfmLoyal},
+		{5, 1, rfmNewCustomers},
+		{4, 2, rfmPotentialLoyalists},
+		{3, 1, rfmPotentialLoyalists},
+		{2, 5, rfmCantLose},
+		{1, 4, rfmCantLose},
+		{2, 3, rfmAtRisk},
+		{1, 2, rfmAtRisk},
+		{2, 1, rfmHibernating},
+		{1, 1, rfmHibernating},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, rfmSegment(tt.r, tt.f), "R=%d F=%d", tt.r, tt.f)
+	}
+}
+
+func TestMedian(t *testing.T) {
+	require.Nil(t, median(nil))
+	require.InDelta(t, 7.0, *median([]float64{7}), 1e-9)
+	require.InDelta(t, 3.0, *median([]float64{5, 1, 3}), 1e-9)
+	require.InDelta(t, 2.5, *median([]float64{4, 1, 3, 2}), 1e-9)
+}
+
+func TestBuyerAnalyticsRange(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// lấy trọn ngày cuối: 1/1 tới hết 3/4 là 93 ngày
+	from, to, err := buyerAnalyticsRange(start, time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), maxFunnelDays)
+	require.Nil(t, err)
+	require.Equal(t, 93, int(to.Sub(from).Hours()/24))
+
+	_, _, err = buyerAnalyticsRange(start, time.Date(2026, 4, 4, 0, 0, 0, 0, time.UTC), maxFunnelDays)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Code)
+
+	_, _, err = buyerAnalyticsRange(start, start.AddDate(0, 0, -1), maxRFMDays)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Code)
+
+	// khoảng mặc định của controller luôn hợp lệ
+	now := time.Now()
+	_, _, err = buyerAnalyticsRange(now.AddDate(0, 0, -30), now, maxFunnelDays)
+	require.Nil(t, err)
+	_, _, err = buyerAnalyticsRange(now.AddDate(0, 0, -365), now, maxRFMDays)
+	require.Nil(t, err)
+}
+
+func TestGetOrderFunnel(t *testing.T) {
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	at := func(seconds int) sql.NullTime {
+		return sql.NullTime{Time: created.Add(time.Duration(seconds) * time.Second), Valid: true}
+	}
+	order := &fakeBuyerOrderStore{stageTimes: []db_order.ListShopOrderStageTimesRow{
+		// trả trước, đi hết phễu
+		{Status: db_order.ShopOrdersStatusCOMPLETED, CreatedAt: created, PaidAt: at(60), ProcessingAt: at(120), ShippedAt: at(180), CompletedAt: at(300)},
+		// COD không có paid_at: vẫn tính là đã qua bước PAID, thời gian xử lý đo từ lúc đặt
+		{Status: db_order.ShopOrdersStatusSHIPPED, CreatedAt: created, ProcessingAt: at(600), ShippedAt: at(700)},
+		// hủy sau khi thanh toán
+		{Status: db_order.ShopOrdersStatusCANCELLED, CreatedAt: created, PaidAt: at(30), CancelledAt: at(90)},
+		// hủy ngay khi vừa đặt
+		{Status: db_order.ShopOrdersStatusCANCELLED, CreatedAt: created, CancelledAt: at(10)},
+		{Status: db_order.ShopOrdersStatusREFUNDED, CreatedAt: created, PaidAt: at(60)},
+	}}
+	s := &service{order: order}
+
+	resp, err := s.GetOrderFunnel(context.Background(), entity.OrderFunnelParams{StartDate: created, EndDate: created})
+	require.Nil(t, err)
+	require.Len(t, resp.Stages, len(funnelStages))
+
+	reached := make([]int64, len(resp.Stages))
+	for i, stage := range resp.Stages {
+		require.Equal(t, funnelStages[i], stage.Stage)
+		reached[i] = stage.Orders
+	}
+	require.Equal(t, []int64{5, 4, 2, 2, 1}, reached)
+
+	require.InDelta(t, 100.0, resp.Stages[0].StepRate, 1e-9)
+	require.Nil(t, resp.Stages[0].MedianSeconds)
+	require.InDelta(t, 80.0, resp.Stages[1].ConversionRate, 1e-9)
+	require.InDelta(t, 50.0, resp.Stages[2].StepRate, 1e-9)
+	require.Equal(t, int64(2), resp.Stages[2].DropOff)
+	require.InDelta(t, 40.0, resp.Stages[2].ConversionRate, 1e-9)
+
+	// PAID: 60, 30, 60; PROCESSING: 60, 600; SHIPPED: 60, 100; COMPLETED: 120
+	require.InDelta(t, 60.0, *resp.Stages[1].MedianSeconds, 1e-9)
+	require.InDelta(t, 330.0, *resp.Stages[2].MedianSeconds, 1e-9)
+	require.InDelta(t, 80.0, *resp.Stages[3].MedianSeconds, 1e-9)
+	require.InDelta(t, 120.0, *resp.Stages[4].MedianSeconds, 1e-9)
+
+	require.Equal(t, int64(2), resp.Cancelled.Orders)
+	require.Equal(t, map[string]int64{"PLACED": 1, "PAID": 1}, resp.Cancelled.ByStage)
+	require.InDelta(t, 40.0, resp.Cancelled.Rate, 1e-9)
+	require.InDelta(t, 50.0, *resp.Cancelled.MedianSeconds, 1e-9)
+	require.Equal(t, int64(1), resp.Refunded)
+}
+
+func TestGetOrderFunnelEmpty(t *testing.T) {
+	s := &service{order: &fakeBuyerOrderStore{}}
+	resp, err := s.GetOrderFunnel(context.Background(), entity.OrderFunnelParams{StartDate: time.Now(), EndDate: time.Now()})
+	require.Nil(t, err)
+	for _, stage := range resp.Stages {
+		require.Zero(t, stage.Orders)
+		require.Zero(t, stage.ConversionRate)
+		require.Nil(t, stage.MedianSeconds)
+	}
+	require.Nil(t, resp.Cancelled.MedianSeconds)
+}
+
+func TestGetOrderFunnelRangeCap(t *testing.T) {
+	s := &service{order: &fakeBuyerOrderStore{}}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := s.GetOrderFunnel(context.Background(), entity.OrderFunnelParams{StartDate: start, EndDate: start.AddDate(1, 0, 0)})
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Code)
+}
+
+func TestGetBuyerRFM(t *testing.T) {
+	end := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	order := &fakeBuyerOrderStore{rfm: []db_order.ListBuyerRFMRow{
+		{UserID: "u-1", LastOrderAt: end.AddDate(0, 0, -1), Frequency: 5, Monetary: "500.00"},
+		{UserID: "u-2", LastOrderAt: end.AddDate(0, 0, -2), Frequency: 1, Monetary: "50.00"},
+		{UserID: "u-3", LastOrderAt: end.AddDate(0, 0, -200), Frequency: 1, Monetary: "50.00"},
+		{UserID: "u-4", LastOrderAt: end.AddDate(0, 0, -300), Frequency: 3, Monetary: "300.00"},
+	}}
+	s := &service{order: order}
+	params := entity.BuyerRFMParams{StartDate: end.AddDate(-1, 0, 0), EndDate: end, Limit: 2}
+
+	resp, err := s.GetBuyerRFM(context.Background(), params)
+	require.Nil(t, err)
+	require.Equal(t, int64(4), resp.TotalBuyers)
+	require.Len(t, resp.Segments, len(rfmSegments))
+	// sắp theo chi tiêu giảm dần, cùng chi tiêu thì theo user_id
+	require.Equal(t, []string{"u-1", "u-4"}, []string{resp.Buyers[0].UserID, resp.Buyers[1].UserID})
+	require.Equal(t, rfmChampions, resp.Buyers[0].Segment)
+
+	params.Offset = 2
+	resp, err = s.GetBuyerRFM(context.Background(), params)
+	require.Nil(t, err)
+	require.Equal(t, []string{"u-2", "u-3"}, []string{resp.Buyers[0].UserID, resp.Buyers[1].UserID})
+
+	params.Offset, params.Segment = 0, rfmChampions
+	resp, err = s.GetBuyerRFM(context.Background(), params)
+	require.Nil(t, err)
+	require.Len(t, resp.Buyers, 1)
+	require.Equal(t, "u-1", resp.Buyers[0].UserID)
+
+	params.Segment = "vip"
+	_, err = s.GetBuyerRFM(context.Background(), params)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Code)
+
+	params.Segment, params.StartDate = "", end.AddDate(-3, 0, 0)
+	_, err = s.GetBuyerRFM(context.Background(), params)
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.Code)
+}
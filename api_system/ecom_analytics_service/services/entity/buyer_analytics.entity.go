@@ -0,0 +1,117 @@
+package services
+
+import "time"
+
+// =================================================================
+// BUYER ANALYTICS ENTITIES (phễu đơn hàng, cohort, RFM)
+// =================================================================
+
+// === Phễu đơn hàng ===
+
+// OrderFunnelParams - Các đơn shop tạo trong [StartDate, EndDate] (lấy trọn ngày cuối)
+type OrderFunnelParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+}
+
+// OrderFunnelStage - 1 bước của phễu: PLACED, PAID, PROCESSING, SHIPPED, COMPLETED.
+// Đơn đã tới bước sau thì được tính là đã qua các bước trước (đơn COD không có paid_at vẫn tính PAID)
+type OrderFunnelStage struct {
+	Stage          string  `json:"stage"`
+	Orders         int64   `json:"orders"`          // số đơn đã tới bước này
+	ConversionRate float64 `json:"conversion_rate"` // % so với số đơn đặt
+	StepRate       float64 `json:"step_rate"`       // % so với bước trước
+	DropOff        int64   `json:"drop_off"`        // số đơn dừng ở bước trước
+	// Trung vị số giây từ mốc trước đó có ghi nhận tới bước này, nil khi không có đơn nào có mốc của bước
+	MedianSeconds *float64 `json:"median_seconds"`
+}
+
+// OrderFunnelCancellation - Đơn bị hủy và bước cuối cùng đơn đã tới trước khi hủy
+type OrderFunnelCancellation struct {
+	Orders  int64            `json:"orders"`
+	Rate    float64          `json:"rate"` // % so với số đơn đặt
+	ByStage map[string]int64 `json:"by_stage"`
+	// Trung vị số giây từ lúc đặt tới lúc hủy
+	MedianSeconds *float64 `json:"median_seconds"`
+}
+
+type OrderFunnelResponse struct {
+	Stages    []OrderFunnelStage      `json:"stages"`
+	Cancelled OrderFunnelCancellation `json:"cancelled"`
+	Refunded  int64                   `json:"refunded"`
+}
+
+// === Cohort ===
+
+// CohortRetentionParams - Cohort theo tháng có đơn hợp lệ đầu tiên trong [StartMonth, EndMonth]
+type CohortRetentionParams struct {
+	StartMonth time.Time
+	EndMonth   time.Time
+	Months     int // số tháng theo dõi sau tháng đầu tiên
+	RepeatDays int // cửa sổ tính mua lại (mặc định 30 ngày)
+}
+
+// CohortRetentionCell - Số người mua của cohort có đơn hợp lệ ở tháng thứ MonthOffset (0 là tháng đầu tiên)
+type CohortRetentionCell struct {
+	MonthOffset int     `json:"month_offset"`
+	Buyers      int64   `json:"buyers"`
+	Rate        float64 `json:"rate"` // % so với số người của cohort
+}
+
+type CohortRow struct {
+	Cohort       string  `json:"cohort"`        // YYYY-MM
+	Buyers       int64   `json:"buyers"`        // số người mua lần đầu trong tháng
+	RepeatBuyers int64   `json:"repeat_buyers"` // có đơn tiếp theo trong RepeatDays ngày sau đơn đầu tiên
+	RepeatRate   float64 `json:"repeat_rate"`
+	// false khi người mua cuối tháng chưa đủ RepeatDays ngày để quay lại
+	RepeatWindowComplete bool `json:"repeat_window_complete"`
+	// chỉ tới tháng hiện tại
+	Retention []CohortRetentionCell `json:"retention"`
+}
+
+type CohortRetentionResponse struct {
+	RepeatDays int         `json:"repeat_days"`
+	Cohorts    []CohortRow `json:"cohorts"`
+	// Tỉ lệ giữ chân chung của từng tháng, gộp các cohort đã tới tháng đó
+	Average []CohortRetentionCell `json:"average"`
+}
+
+// === RFM ===
+
+// BuyerRFMParams - Người mua có đơn hợp lệ trong [StartDate, EndDate], recency tính tới hết EndDate
+type BuyerRFMParams struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Segment   string // lọc danh sách người mua theo phân khúc
+	Limit     int32
+	Offset    int32
+}
+
+type BuyerRFMRow struct {
+	UserID      string    `json:"user_id"`
+	LastOrderAt time.Time `json:"last_order_at"`
+	RecencyDays int64     `json:"recency_days"`
+	Frequency   int64     `json:"frequency"`
+	Monetary    float64   `json:"monetary"`
+	RScore      int       `json:"r_score"` // 1-5, 5 là tốt nhất
+	FScore      int       `json:"f_score"`
+	MScore      int       `json:"m_score"`
+	Segment     string    `json:"segment"`
+}
+
+type RFMSegmentSummary struct {
+	Segment        string  `json:"segment"`
+	Buyers         int64   `json:"buyers"`
+	Share          float64 `json:"share"` // % so với tổng số người mua
+	AvgRecencyDays float64 `json:"avg_recency_days"`
+	AvgFrequency   float64 `json:"avg_frequency"`
+	AvgMonetary    float64 `json:"avg_monetary"`
+	TotalMonetary  float64 `json:"total_monetary"`
+}
+
+type BuyerRFMResponse struct {
+	TotalBuyers int64               `json:"total_buyers"`
+	Segments    []RFMSegmentSummary `json:"segments"`
+	// sắp theo tổng chi tiêu giảm dần, phân trang bằng limit/offset
+	Buyers []BuyerRFMRow `json:"buyers"`
+}
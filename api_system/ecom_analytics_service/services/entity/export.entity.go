@@ -0,0 +1,42 @@
+package services
+
+import "time"
+
+// =================================================================
+// EXPORT ENTITIES (xuất báo cáo CSV/XLSX)
+// =================================================================
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+
+	// yêu cầu chạy nền đã xuất xong, tải được file
+	ExportStatusDone = "DONE"
+)
+
+// ExportRequest - Yêu cầu xuất 1 danh sách ra file.
+// Params là bộ lọc của API danh sách tương ứng (Limit/Offset bị bỏ qua):
+// ListShopOrdersParams, ListShopSettlementsParams, ListWalletLedgerEntriesParams,
+// ListPlatformTransactionsParams hoặc ListPlatformSettlementsParams
+type ExportRequest struct {
+	Format      string // csv, xlsx
+	Async       bool   // true thì luôn chạy nền, không thì chỉ chạy nền khi vượt ngưỡng số dòng
+	RequestedBy string // người yêu cầu, chỉ người này được tải file chạy nền
+	Params      interface{}
+}
+
+// ExportJobResponse - Trạng thái 1 yêu cầu xuất báo cáo chạy nền
+type ExportJobResponse struct {
+	ID           string     `json:"id"`
+	Report       string     `json:"report"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"` // PENDING, RUNNING, DONE, FAILED
+	FileName     string     `json:"file_name"`
+	RowCount     int32      `json:"row_count"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	// chỉ có khi Status = DONE
+	DownloadURL string `json:"download_url,omitempty"`
+}
@@ -0,0 +1,11 @@
+package services
+
+import "time"
+
+// RollupEvent sự kiện từ Kafka làm thay đổi số liệu của bảng tổng hợp.
+// Có ShopOrderIDs thì dùng trực tiếp, không có thì lấy mọi shop order của OrderID.
+type RollupEvent struct {
+	ShopOrderIDs []string
+	OrderID      string
+	OccurredAt   time.Time
+}
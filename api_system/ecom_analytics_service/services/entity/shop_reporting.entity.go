@@ -41,6 +41,13 @@ type ListShopOrdersParams struct {
 	EndDate   sql.NullTime
 	Limit     int32
 	Offset    int32
+	CursorParams
+}
+
+// CursorParams phân trang theo cursor (keyset) thay cho Offset, Cursor rỗng là trang đầu
+type CursorParams struct {
+	CursorMode bool
+	Cursor     string
 }
 
 type ShopOrderWithPaymentStatus struct {
@@ -50,7 +57,9 @@ type ShopOrderWithPaymentStatus struct {
 
 type ListShopOrdersResponse struct {
 	Orders []ShopOrderWithPaymentStatus `json:"orders"`
-	// (Có thể thêm metadata phân trang tại đây)
+	// chỉ có ở chế độ cursor, nil khi không còn trang
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 type EnrichedShopOrderResponse struct {
@@ -72,20 +81,62 @@ type ListShopOrderItemsParams struct {
 
 // === Nhóm 3 ===
 
-type RevenueDatapoint struct {
-	Date       string  `json:"date"`
+// TimeseriesParams tham số của các API timeseries doanh thu, service tự kiểm tra và trả 400 khi sai.
+// Granularity: hour|day|week|month (mặc định day), Timezone: tên IANA (mặc định Asia/Ho_Chi_Minh),
+// Compare: previous_period|previous_year (bỏ trống để không so sánh)
+type TimeseriesParams struct {
+	StartDate   string
+	EndDate     string
+	Granularity string
+	Timezone    string
+	Compare     string
+}
+
+// TimeseriesDelta phần trăm thay đổi so với kỳ so sánh, nil khi kỳ so sánh bằng 0
+type TimeseriesDelta struct {
+	GMV        *float64 `json:"gmv"`
+	Orders     *float64 `json:"orders"`
+	AOV        *float64 `json:"aov"`
+	NetRevenue *float64 `json:"net_revenue"`
+}
+
+type RevenueMetrics struct {
 	GMV        float64 `json:"gmv"`         // Doanh thu gộp
+	Orders     int64   `json:"orders"`      // Số đơn hoàn thành
+	AOV        float64 `json:"aov"`         // Giá trị trung bình đơn = GMV / Orders
 	NetRevenue float64 `json:"net_revenue"` // Doanh thu thuần (đã đối soát)
 }
 
+type RevenueDatapoint struct {
+	Date        string    `json:"date"`         // Nhãn của khung theo múi giờ yêu cầu
+	BucketStart time.Time `json:"bucket_start"` // Thời điểm bắt đầu khung
+	RevenueMetrics
+	Delta *TimeseriesDelta `json:"delta,omitempty"`
+}
+
 type RevenueTimeseriesResponse struct {
-	Data []RevenueDatapoint `json:"data"`
+	Granularity string             `json:"granularity"`
+	Timezone    string             `json:"timezone"`
+	Compare     string             `json:"compare,omitempty"`
+	Data        []RevenueDatapoint `json:"data"`
+	Total       RevenueMetrics     `json:"total"`
+	// Chỉ có khi truyền compare: chuỗi kỳ so sánh khớp theo vị trí với Data
+	Comparison      []RevenueDatapoint `json:"comparison,omitempty"`
+	ComparisonTotal *RevenueMetrics    `json:"comparison_total,omitempty"`
+	TotalDelta      *TimeseriesDelta   `json:"total_delta,omitempty"`
 }
 
 type ListWalletLedgerEntriesParams struct {
 	ShopID string
 	Limit  int32
-	Offset int32
+	Cursor string // rỗng là trang đầu
+}
+
+type ListWalletLedgerEntriesResponse struct {
+	Entries []db_transaction.LedgerEntries `json:"entries"`
+	// nil khi không còn trang
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 type ListShopSettlementsParams struct {
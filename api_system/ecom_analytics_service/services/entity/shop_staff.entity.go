@@ -0,0 +1,33 @@
+package services
+
+import "time"
+
+// =================================================================
+// SHOP STAFF ENTITIES
+// =================================================================
+
+// ShopAccess - Shop mà người gọi API /shop/* được xem và vai trò của họ trong shop
+type ShopAccess struct {
+	ShopID string `json:"shop_id"`
+	Role   string `json:"role"` // OWNER, MANAGER, VIEWER
+}
+
+// AddShopStaffRequest - Chủ shop/quản lý thêm nhân sự hoặc đổi vai trò
+type AddShopStaffRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=MANAGER VIEWER"`
+}
+
+// SetShopOwnerRequest - Admin gán chủ shop (người bán có token không kèm claim shopId)
+type SetShopOwnerRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// ShopStaffItem - Một nhân sự của shop
+type ShopStaffItem struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -29,12 +29,15 @@ type ListPlatformOrdersParams struct {
 	EndDate   sql.NullTime
 	Limit     int32
 	Offset    int32
+	CursorParams
 }
 
 // (Sử dụng lại ShopOrderWithPaymentStatus từ dtos.go)
 type ListPlatformOrdersResponse struct {
 	Orders []ShopOrderWithPaymentStatus `json:"orders"`
-	// (Có thể thêm metadata phân trang)
+	// chỉ có ở chế độ cursor, nil khi không còn trang
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 type EnrichedPlatformOrderResponse struct {
@@ -44,16 +47,32 @@ type EnrichedPlatformOrderResponse struct {
 
 // === Nhóm 3: Quản lý Tài chính ===
 
-type PlatformRevenueDatapoint struct {
-	Date            string  `json:"date"`
+type PlatformRevenueMetrics struct {
 	TotalGMV        float64 `json:"total_gmv"`
-	PlatformRevenue float64 `json:"platform_revenue"`
+	Orders          int64   `json:"orders"`
+	AOV             float64 `json:"aov"`
+	PlatformRevenue float64 `json:"platform_revenue"` // Doanh thu thuần của sàn (phí hoa hồng)
 	PlatformCost    float64 `json:"platform_cost"`
 	PlatformProfit  float64 `json:"platform_profit"`
 }
 
+// PlatformRevenueDatapoint Delta.NetRevenue tính trên PlatformRevenue
+type PlatformRevenueDatapoint struct {
+	Date        string    `json:"date"`
+	BucketStart time.Time `json:"bucket_start"`
+	PlatformRevenueMetrics
+	Delta *TimeseriesDelta `json:"delta,omitempty"`
+}
+
 type PlatformRevenueTimeseriesResponse struct {
-	Data []PlatformRevenueDatapoint `json:"data"`
+	Granularity     string                     `json:"granularity"`
+	Timezone        string                     `json:"timezone"`
+	Compare         string                     `json:"compare,omitempty"`
+	Data            []PlatformRevenueDatapoint `json:"data"`
+	Total           PlatformRevenueMetrics     `json:"total"`
+	Comparison      []PlatformRevenueDatapoint `json:"comparison,omitempty"`
+	ComparisonTotal *PlatformRevenueMetrics    `json:"comparison_total,omitempty"`
+	TotalDelta      *TimeseriesDelta           `json:"total_delta,omitempty"`
 }
 
 type ListPlatformTransactionsParams struct {
@@ -62,7 +81,14 @@ type ListPlatformTransactionsParams struct {
 	StartDate sql.NullTime
 	EndDate   sql.NullTime
 	Limit     int32
-	Offset    int32
+	Cursor    string // rỗng là trang đầu
+}
+
+type ListPlatformTransactionsResponse struct {
+	Transactions []db_transaction.Transactions `json:"transactions"`
+	// nil khi không còn trang
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 type ListPlatformSettlementsParams struct {
@@ -70,13 +96,31 @@ type ListPlatformSettlementsParams struct {
 	StartDate sql.NullTime
 	EndDate   sql.NullTime
 	Limit     int32
-	Offset    int32
+	Cursor    string
+}
+
+type ListPlatformSettlementsResponse struct {
+	Settlements []db_transaction.ShopOrderSettlements `json:"settlements"`
+	NextCursor  *string                               `json:"next_cursor,omitempty"`
+	PrevCursor  *string                               `json:"prev_cursor,omitempty"`
 }
 
 type ListPlatformLedgersParams struct {
 	OwnerType sql.NullString // 'SHOP' hoặc 'PLATFORM'
 	Limit     int32
-	Offset    int32
+	Cursor    string
+}
+
+type ListPlatformLedgersResponse struct {
+	Ledgers    []db_transaction.AccountLedgers `json:"ledgers"`
+	NextCursor *string                         `json:"next_cursor,omitempty"`
+	PrevCursor *string                         `json:"prev_cursor,omitempty"`
+}
+
+type ListLedgerEntriesResponse struct {
+	Entries    []db_transaction.LedgerEntries `json:"entries"`
+	NextCursor *string                        `json:"next_cursor,omitempty"`
+	PrevCursor *string                        `json:"prev_cursor,omitempty"`
 }
 
 // === Nhóm 4: Phân tích Voucher ===
@@ -123,6 +167,13 @@ type PlatformRankingParams struct {
 }
 
 type PlatformRankingCategoriesResponse struct {
-	Message string `json:"message"`
-	// (Dữ liệu sẽ được thêm nếu có kết nối product_db)
+	Data []CategoryRankingRow `json:"data"`
+}
+
+// CategoryRankingRow danh mục lấy từ order_items.category_id, rỗng là đơn hàng cũ chưa lưu danh mục
+type CategoryRankingRow struct {
+	CategoryID    string  `json:"category_id"`
+	TotalQuantity int64   `json:"total_quantity"`
+	TotalRevenue  float64 `json:"total_revenue"`
+	TotalOrders   int64   `json:"total_orders"`
 }
@@ -0,0 +1,573 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	// số dòng lấy từ database mỗi lần, ghi xong lô này mới lấy lô tiếp
+	exportBatchSize = 1000
+	// báo cáo nhiều hơn ngưỡng này thì tự chuyển sang chạy nền
+	exportSyncMaxRows = 5000
+	// số yêu cầu chạy nền được ghi file cùng lúc, các yêu cầu khác chờ ở PENDING
+	exportMaxRunning = 2
+	// thời gian giữ file sau khi xuất xong
+	exportFileTTL = 24 * time.Hour
+	// yêu cầu chưa xong sau khoảng này coi như bị bỏ dở (dịch vụ khởi động lại giữa chừng)
+	exportStaleAfter = time.Hour
+	exportDefaultDir = "exports"
+)
+
+// exportSource 1 loại báo cáo: các cột và cách đọc dữ liệu theo lô
+type exportSource struct {
+	Report   string // mã báo cáo lưu trong export_jobs
+	FileName string // tiền tố tên file tải về
+	ShopID   string // rỗng với báo cáo toàn sàn
+	Columns  []exportColumn
+	// Open tạo bộ đọc mới từ đầu danh sách (phân trang keyset nên không bị trùng/sót dòng khi có dữ liệu mới)
+	Open func() exportFetch
+}
+
+// exportFetch mỗi lần gọi trả lô tiếp theo (tối đa limit dòng), lô rỗng là đã hết
+type exportFetch func(ctx context.Context, limit int32) ([][]interface{}, error)
+
+// ExportReport xuất danh sách ra CSV/XLSX: ghi thẳng vào response khi báo cáo nhỏ, tạo yêu cầu chạy nền khi báo cáo lớn
+func (s *service) ExportReport(ctx context.Context, req entity.ExportRequest, open func(fileName string) io.Writer) (*entity.ExportJobResponse, *assets_services.ServiceError) {
+	if req.Format != entity.ExportFormatCSV && req.Format != entity.ExportFormatXLSX {
+		return nil, assets_services.NewError(http.StatusBadRequest, errors.New("format chỉ nhận csv hoặc xlsx"))
+	}
+	src, serr := s.newExportSource(req.Params)
+	if serr != nil {
+		return nil, serr
+	}
+	fileName := fmt.Sprintf("%s_%s.%s", src.FileName, time.Now().In(rollupLocation()).Format("20060102_150405"), req.Format)
+
+	async := req.Async
+	fetch := src.Open()
+	var first [][]interface{}
+	if !async {
+		// đọc thử tối đa exportSyncMaxRows+1 dòng để biết báo cáo có lớn không, không cần đếm cả bảng.
+		// Báo cáo nhỏ thì ghi luôn các dòng đã đọc.
+		var err error
+		first, err = fetch(ctx, exportSyncMaxRows+1)
+		if err != nil {
+			return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy dữ liệu báo cáo: %w", err))
+		}
+		async = len(first) > exportSyncMaxRows
+	}
+	if async {
+		return s.startExportJob(ctx, req, src, fileName)
+	}
+
+	if _, err := writeExport(ctx, src.Columns, fetch, first, req.Format, open(fileName)); err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi xuất báo cáo: %w", err))
+	}
+	return nil, nil
+}
+
+// GetExportJob trạng thái yêu cầu chạy nền, chỉ người yêu cầu mới xem được
+func (s *service) GetExportJob(ctx context.Context, requestedBy, jobID string) (*entity.ExportJobResponse, *assets_services.ServiceError) {
+	job, err := s.interact.GetExportJob(ctx, db_interact.GetExportJobParams{ID: jobID, RequestedBy: requestedBy})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy yêu cầu xuất báo cáo"))
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy yêu cầu xuất báo cáo: %w", err))
+	}
+	return exportJobResponse(job), nil
+}
+
+// GetExportFile đường dẫn file trên đĩa và tên file tải về của yêu cầu đã xuất xong
+func (s *service) GetExportFile(ctx context.Context, requestedBy, jobID string) (string, string, *assets_services.ServiceError) {
+	job, serr := s.GetExportJob(ctx, requestedBy, jobID)
+	if serr != nil {
+		return "", "", serr
+	}
+	if job.Status != string(db_interact.ExportJobsStatusDONE) {
+		return "", "", assets_services.NewError(http.StatusConflict, fmt.Errorf("báo cáo chưa xuất xong (trạng thái %s)", job.Status))
+	}
+	path := s.exportFilePath(job.ID, job.Format)
+	if _, err := os.Stat(path); err != nil {
+		return "", "", assets_services.NewError(http.StatusGone, errors.New("file báo cáo đã hết hạn, vui lòng xuất lại"))
+	}
+	return path, job.FileName, nil
+}
+
+// CleanupExportJobs đánh lỗi các yêu cầu bị bỏ dở, xóa file và yêu cầu đã hết hạn
+func (s *service) CleanupExportJobs(ctx context.Context) error {
+	now := time.Now()
+	stale, err := s.interact.FailStaleExportJobs(ctx, db_interact.FailStaleExportJobsParams{
+		ErrorMessage: sql.NullString{String: "yêu cầu bị gián đoạn, vui lòng xuất lại", Valid: true},
+		ExpiresAt:    sql.NullTime{Time: now.Add(exportFileTTL), Valid: true},
+		CreatedAt:    now.Add(-exportStaleAfter),
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi đánh lỗi yêu cầu xuất bị bỏ dở: %w", err)
+	}
+	if stale > 0 {
+		log.Printf("Marked %d stale export jobs as failed", stale)
+	}
+
+	const batch = 100
+	for {
+		jobs, err := s.interact.ListExpiredExportJobs(ctx, db_interact.ListExpiredExportJobsParams{
+			ExpiresAt: sql.NullTime{Time: now, Valid: true},
+			Limit:     batch,
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy yêu cầu xuất hết hạn: %w", err)
+		}
+		for _, job := range jobs {
+			if err := os.Remove(s.exportFilePath(job.ID, string(job.Format))); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("lỗi khi xóa file báo cáo %s: %w", job.ID, err)
+			}
+			if err := s.interact.DeleteExportJob(ctx, job.ID); err != nil {
+				return fmt.Errorf("lỗi khi xóa yêu cầu xuất %s: %w", job.ID, err)
+			}
+		}
+		if len(jobs) < batch {
+			return nil
+		}
+	}
+}
+
+func (s *service) startExportJob(ctx context.Context, req entity.ExportRequest, src *exportSource, fileName string) (*entity.ExportJobResponse, *assets_services.ServiceError) {
+	if req.RequestedBy == "" {
+		return nil, assets_services.NewError(http.StatusForbidden, errors.New("không xác định được người yêu cầu xuất báo cáo"))
+	}
+	jobID := uuid.New().String()
+	err := s.interact.CreateExportJob(ctx, db_interact.CreateExportJobParams{
+		ID:          jobID,
+		Report:      src.Report,
+		Format:      db_interact.ExportJobsFormat(req.Format),
+		RequestedBy: req.RequestedBy,
+		ShopID:      sql.NullString{String: src.ShopID, Valid: src.ShopID != ""},
+		FileName:    fileName,
+	})
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi tạo yêu cầu xuất báo cáo: %w", err))
+	}
+
+	// chạy ngoài request nên không dùng ctx của request (bị hủy khi trả response)
+	go s.runExportJob(jobID, req.Format, src)
+
+	return s.GetExportJob(ctx, req.RequestedBy, jobID)
+}
+
+func (s *service) runExportJob(jobID, format string, src *exportSource) {
+	s.exportSlots <- struct{}{}
+	defer func() { <-s.exportSlots }()
+
+	ctx := context.Background()
+	if err := s.interact.MarkExportJobRunning(ctx, jobID); err != nil {
+		log.Printf("Error mark export job %s running: %v", jobID, err)
+	}
+
+	rowCount, err := s.writeExportFile(ctx, jobID, format, src)
+	expiresAt := sql.NullTime{Time: time.Now().Add(exportFileTTL), Valid: true}
+	if err != nil {
+		log.Printf("Error export job %s: %v", jobID, err)
+		err = s.interact.FailExportJob(ctx, db_interact.FailExportJobParams{
+			ErrorMessage: sql.NullString{String: err.Error(), Valid: true},
+			ExpiresAt:    expiresAt,
+			ID:           jobID,
+		})
+	} else {
+		err = s.interact.FinishExportJob(ctx, db_interact.FinishExportJobParams{
+			RowCount:  rowCount,
+			ExpiresAt: expiresAt,
+			ID:        jobID,
+		})
+	}
+	if err != nil {
+		log.Printf("Error update export job %s: %v", jobID, err)
+	}
+}
+
+func (s *service) writeExportFile(ctx context.Context, jobID, format string, src *exportSource) (int32, error) {
+	if err := os.MkdirAll(s.exportDir(), 0o755); err != nil {
+		return 0, fmt.Errorf("lỗi khi tạo thư mục xuất báo cáo: %w", err)
+	}
+	path := s.exportFilePath(jobID, format)
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("lỗi khi tạo file báo cáo: %w", err)
+	}
+	rowCount, err := writeExport(ctx, src.Columns, src.Open(), nil, format, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return rowCount, nil
+}
+
+func (s *service) exportDir() string {
+	if s.env.ExportDir == "" {
+		return exportDefaultDir
+	}
+	return s.env.ExportDir
+}
+
+func (s *service) exportFilePath(jobID, format string) string {
+	return filepath.Join(s.exportDir(), jobID+"."+format)
+}
+
+// writeExport ghi tiêu đề, các dòng đã đọc trước (first) rồi lần lượt từng lô dữ liệu, trả về số dòng đã ghi
+func writeExport(ctx context.Context, columns []exportColumn, fetch exportFetch, first [][]interface{}, format string, w io.Writer) (int32, error) {
+	ew, err := newExportWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+	if err := ew.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	var total int32
+	rows := first
+	for {
+		for _, row := range rows {
+			if err := ew.WriteRow(row); err != nil {
+				return total, err
+			}
+		}
+		total += int32(len(rows))
+		if err := ew.Flush(); err != nil {
+			return total, err
+		}
+		rows, err = fetch(ctx, exportBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+	}
+	return total, ew.Close()
+}
+
+// keysetExport bộ đọc lô cho các danh sách phân trang keyset, row chuyển 1 bản ghi thành 1 dòng báo cáo
+func keysetExport[T any](fetch func(context.Context, db_mysql.KeysetParams) ([]T, error), key func(T) (sql.NullTime, string), row func(T) []interface{}) exportFetch {
+	next := keysetAll(fetch, key)
+	return func(ctx context.Context, limit int32) ([][]interface{}, error) {
+		items, err := next(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([][]interface{}, 0, len(items))
+		for _, item := range items {
+			rows = append(rows, row(item))
+		}
+		return rows, nil
+	}
+}
+
+func exportJobResponse(job db_interact.ExportJobs) *entity.ExportJobResponse {
+	resp := &entity.ExportJobResponse{
+		ID:        job.ID,
+		Report:    job.Report,
+		Format:    string(job.Format),
+		Status:    string(job.Status),
+		FileName:  job.FileName,
+		RowCount:  job.RowCount,
+		CreatedAt: job.CreatedAt,
+	}
+	if job.ErrorMessage.Valid {
+		resp.ErrorMessage = &job.ErrorMessage.String
+	}
+	if job.FinishedAt.Valid {
+		resp.FinishedAt = &job.FinishedAt.Time
+	}
+	if job.ExpiresAt.Valid {
+		resp.ExpiresAt = &job.ExpiresAt.Time
+	}
+	return resp
+}
+
+// === Các loại báo cáo ===
+
+// newExportSource chọn loại báo cáo theo kiểu bộ lọc của API danh sách
+func (s *service) newExportSource(params interface{}) (*exportSource, *assets_services.ServiceError) {
+	switch p := params.(type) {
+	case entity.ListShopOrdersParams:
+		return s.shopOrdersExport(p), nil
+	case entity.ListShopSettlementsParams:
+		return s.shopSettlementsExport(p), nil
+	case entity.ListWalletLedgerEntriesParams:
+		return s.shopLedgerEntriesExport(p), nil
+	case entity.ListPlatformTransactionsParams:
+		return s.platformTransactionsExport(p), nil
+	case entity.ListPlatformSettlementsParams:
+		return s.platformSettlementsExport(p), nil
+	default:
+		return nil, assets_services.NewError(http.StatusBadRequest, errors.New("danh sách này không hỗ trợ xuất file"))
+	}
+}
+
+// GET /shop/orders
+func (s *service) shopOrdersExport(p entity.ListShopOrdersParams) *exportSource {
+	return &exportSource{
+		Report:   "shop_orders",
+		FileName: "don-hang",
+		ShopID:   p.ShopID,
+		Columns: []exportColumn{
+			{Title: "Mã đơn hàng", Width: 22},
+			{Title: "Mã đơn tổng", Width: 38},
+			{Title: "Trạng thái", Width: 16},
+			{Title: "Tiền hàng (VNĐ)", Kind: exportMoney, Width: 16},
+			{Title: "Giảm giá (VNĐ)", Kind: exportMoney, Width: 16},
+			{Title: "Phí vận chuyển (VNĐ)", Kind: exportMoney, Width: 20},
+			{Title: "Mã voucher shop", Width: 18},
+			{Title: "Giảm từ voucher shop (VNĐ)", Kind: exportMoney, Width: 26},
+			{Title: "Tổng tiền (VNĐ)", Kind: exportMoney, Width: 16},
+			{Title: "Đơn vị vận chuyển", Width: 20},
+			{Title: "Mã vận đơn", Width: 20},
+			{Title: "Ngày đặt", Kind: exportTime, Width: 20},
+			{Title: "Ngày thanh toán", Kind: exportTime, Width: 20},
+			{Title: "Ngày hoàn thành", Kind: exportTime, Width: 20},
+			{Title: "Ngày hủy", Kind: exportTime, Width: 20},
+			{Title: "Lý do hủy", Width: 30},
+		},
+		Open: func() exportFetch {
+			return keysetExport(func(ctx context.Context, kp db_mysql.KeysetParams) ([]db_order.ShopOrders, error) {
+				return s.order.ListShopOrdersKeyset(ctx, db_mysql.ShopOrderKeysetParams{
+					ShopID:         sql.NullString{String: p.ShopID, Valid: true},
+					Status:         shopOrderStatusFilter(p.Status),
+					StartDate:      p.StartDate,
+					EndDate:        p.EndDate,
+					AfterCreatedAt: kp.AfterTime.Time,
+					AfterID:        kp.AfterID,
+					Limit:          kp.Limit,
+				})
+			}, func(o db_order.ShopOrders) (sql.NullTime, string) {
+				return sql.NullTime{Time: o.CreatedAt, Valid: true}, o.ID
+			}, func(o db_order.ShopOrders) []interface{} {
+				return []interface{}{
+					o.ShopOrderCode,
+					o.OrderID,
+					string(o.Status),
+					exportAmount(o.Subtotal),
+					exportAmount(o.TotalDiscount),
+					exportAmount(o.ShippingFee),
+					exportNullString(o.ShopVoucherCode),
+					exportNullAmount(o.ShopVoucherDiscount),
+					exportAmount(o.TotalAmount),
+					exportNullString(o.ShippingMethod),
+					exportNullString(o.TrackingCode),
+					o.CreatedAt,
+					exportNullTime(o.PaidAt),
+					exportNullTime(o.CompletedAt),
+					exportNullTime(o.CancelledAt),
+					exportNullString(o.CancellationReason),
+				}
+			})
+		},
+	}
+}
+
+// GET /shop/settlements. Bảng đối soát không có shop_id nên lấy ID đơn của shop một lần rồi đọc theo keyset
+func (s *service) shopSettlementsExport(p entity.ListShopSettlementsParams) *exportSource {
+	return &exportSource{
+		Report:   "shop_settlements",
+		FileName: "doi-soat",
+		ShopID:   p.ShopID,
+		Columns:  settlementExportColumns,
+		Open: func() exportFetch {
+			var shopOrderIDs []string
+			return keysetExport(func(ctx context.Context, kp db_mysql.KeysetParams) ([]db_transaction.ShopOrderSettlements, error) {
+				if shopOrderIDs == nil {
+					ids, err := s.order.GetShopOrderIDs(ctx, p.ShopID)
+					if err != nil {
+						return nil, fmt.Errorf("lỗi khi lấy danh sách ID đơn hàng: %w", err)
+					}
+					shopOrderIDs = emptyIfNil(ids)
+				}
+				return s.transaction.ListSettlementsKeyset(ctx, shopSettlementsKeyset(p, shopOrderIDs, kp))
+			}, settlementKey, settlementExportRow)
+		},
+	}
+}
+
+// shopSettlementsKeyset lọc giống ListShopSettlements: mỗi mốc ngày có thể có hoặc không,
+// trong khi ListSettlementsKeyset chỉ lọc khi có đủ hai mốc nên mốc thiếu được mở rộng hết cỡ
+func shopSettlementsKeyset(p entity.ListShopSettlementsParams, shopOrderIDs []string, kp db_mysql.KeysetParams) db_mysql.SettlementKeysetParams {
+	start, end := p.StartDate, p.EndDate
+	if start.Valid && !end.Valid {
+		end = sql.NullTime{Time: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+	if end.Valid && !start.Valid {
+		start = sql.NullTime{Time: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	}
+	return db_mysql.SettlementKeysetParams{
+		ShopOrderIDs: shopOrderIDs,
+		Status:       db_transaction.NullShopOrderSettlementsStatus{Valid: p.Status.Valid, ShopOrderSettlementsStatus: db_transaction.ShopOrderSettlementsStatus(p.Status.String)},
+		StartDate:    start,
+		EndDate:      end,
+		KeysetParams: kp,
+	}
+}
+
+// GET /shop/wallet/ledger-entries
+func (s *service) shopLedgerEntriesExport(p entity.ListWalletLedgerEntriesParams) *exportSource {
+	return &exportSource{
+		Report:   "shop_ledger_entries",
+		FileName: "bien-dong-vi",
+		ShopID:   p.ShopID,
+		Columns: []exportColumn{
+			{Title: "Mã bút toán", Width: 14},
+			{Title: "Mã giao dịch", Width: 38},
+			{Title: "Loại", Width: 10},
+			{Title: "Số tiền (VNĐ)", Kind: exportMoney, Width: 16},
+			{Title: "Mô tả", Width: 40},
+			{Title: "Thời gian", Kind: exportTime, Width: 20},
+		},
+		Open: func() exportFetch {
+			return keysetExport(func(ctx context.Context, kp db_mysql.KeysetParams) ([]db_transaction.LedgerEntries, error) {
+				return s.transaction.ListLedgerEntriesKeyset(ctx, db_mysql.LedgerEntryKeysetParams{ShopID: p.ShopID, KeysetParams: kp})
+			}, ledgerEntryKey, func(e db_transaction.LedgerEntries) []interface{} {
+				return []interface{}{
+					strconv.FormatUint(e.ID, 10),
+					e.TransactionID,
+					string(e.Type),
+					exportAmount(e.Amount),
+					e.Description,
+					e.CreatedAt,
+				}
+			})
+		},
+	}
+}
+
+// GET /platform/finance/transactions
+func (s *service) platformTransactionsExport(p entity.ListPlatformTransactionsParams) *exportSource {
+	return &exportSource{
+		Report:   "platform_transactions",
+		FileName: "giao-dich",
+		Columns: []exportColumn{
+			{Title: "Mã giao dịch", Width: 22},
+			{Title: "Mã đơn tổng", Width: 38},
+			{Title: "Loại", Width: 12},
+			{Title: "Trạng thái", Width: 12},
+			{Title: "Số tiền", Kind: exportMoney, Width: 16},
+			{Title: "Tiền tệ", Width: 8},
+			{Title: "Mã giao dịch cổng thanh toán", Width: 28},
+			{Title: "Ghi chú", Width: 30},
+			{Title: "Thời gian tạo", Kind: exportTime, Width: 20},
+			{Title: "Thời gian xử lý", Kind: exportTime, Width: 20},
+		},
+		Open: func() exportFetch {
+			return keysetExport(func(ctx context.Context, kp db_mysql.KeysetParams) ([]db_transaction.Transactions, error) {
+				return s.transaction.ListTransactionsKeyset(ctx, platformTransactionsKeyset(p, kp))
+			}, transactionKey, func(t db_transaction.Transactions) []interface{} {
+				return []interface{}{
+					t.TransactionCode,
+					exportNullString(t.OrderID),
+					string(t.Type),
+					string(t.Status),
+					exportAmount(t.Amount),
+					t.Currency,
+					exportNullString(t.GatewayTransactionID),
+					exportNullString(t.Notes),
+					t.CreatedAt,
+					exportNullTime(t.ProcessedAt),
+				}
+			})
+		},
+	}
+}
+
+// GET /platform/finance/settlements
+func (s *service) platformSettlementsExport(p entity.ListPlatformSettlementsParams) *exportSource {
+	return &exportSource{
+		Report:   "platform_settlements",
+		FileName: "doi-soat-san",
+		Columns:  settlementExportColumns,
+		Open: func() exportFetch {
+			return keysetExport(func(ctx context.Context, kp db_mysql.KeysetParams) ([]db_transaction.ShopOrderSettlements, error) {
+				return s.transaction.ListSettlementsKeyset(ctx, platformSettlementsKeyset(p, kp))
+			}, settlementKey, settlementExportRow)
+		},
+	}
+}
+
+var settlementExportColumns = []exportColumn{
+	{Title: "Mã đối soát", Width: 38},
+	{Title: "Mã đơn hàng shop", Width: 38},
+	{Title: "Mã giao dịch thanh toán", Width: 38},
+	{Title: "Trạng thái", Width: 20},
+	{Title: "Giá trị hàng (VNĐ)", Kind: exportMoney, Width: 18},
+	{Title: "Giảm giá SP shop chịu (VNĐ)", Kind: exportMoney, Width: 26},
+	{Title: "Giảm giá SP sàn trợ giá (VNĐ)", Kind: exportMoney, Width: 28},
+	{Title: "Voucher shop (VNĐ)", Kind: exportMoney, Width: 18},
+	{Title: "Shop hỗ trợ phí ship (VNĐ)", Kind: exportMoney, Width: 26},
+	{Title: "Phí vận chuyển (VNĐ)", Kind: exportMoney, Width: 20},
+	{Title: "Phí hoa hồng (VNĐ)", Kind: exportMoney, Width: 18},
+	{Title: "Thực nhận (VNĐ)", Kind: exportMoney, Width: 16},
+	{Title: "Ngày hoàn thành đơn", Kind: exportTime, Width: 20},
+	{Title: "Ngày đối soát", Kind: exportTime, Width: 20},
+}
+
+func settlementExportRow(st db_transaction.ShopOrderSettlements) []interface{} {
+	return []interface{}{
+		st.ID,
+		st.ShopOrderID,
+		st.OrderTransactionID,
+		string(st.Status),
+		exportAmount(st.OrderSubtotal),
+		exportAmount(st.ShopFundedProductDiscount),
+		exportAmount(st.SiteFundedProductDiscount),
+		exportAmount(st.ShopVoucherDiscount),
+		exportAmount(st.ShopShippingDiscount),
+		exportAmount(st.ShippingFee),
+		exportAmount(st.CommissionFee),
+		exportAmount(st.NetSettledAmount),
+		exportNullTime(st.OrderCompletedAt),
+		exportNullTime(st.SettledAt),
+	}
+}
+
+func exportAmount(amount string) interface{} {
+	f, _ := entity.ParseAmountToFloat64(amount)
+	return f
+}
+
+func exportNullAmount(amount sql.NullString) interface{} {
+	if !amount.Valid {
+		return nil
+	}
+	return exportAmount(amount.String)
+}
+
+func exportNullString(v sql.NullString) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.String
+}
+
+func exportNullTime(v sql.NullTime) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time
+}
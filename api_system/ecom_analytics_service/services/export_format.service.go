@@ -0,0 +1,253 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportKind kiểu dữ liệu của cột, quyết định cách ghi ô trong CSV/XLSX
+type exportKind int
+
+const (
+	exportText   exportKind = iota
+	exportMoney             // số tiền VNĐ (float64)
+	exportNumber            // số nguyên (int64)
+	exportTime              // ngày giờ, ghi theo giờ Việt Nam (time.Time)
+)
+
+const (
+	exportSheetName  = "Báo cáo"
+	exportTimeLayout = "02/01/2006 15:04:05"
+)
+
+type exportColumn struct {
+	Title string
+	Kind  exportKind
+	Width float64 // độ rộng cột XLSX
+}
+
+// exportWriter ghi báo cáo theo từng dòng. Flush sau mỗi lô để dữ liệu không dồn trong bộ nhớ.
+// Giá trị ô nil là ô trống.
+type exportWriter interface {
+	WriteHeader(columns []exportColumn) error
+	WriteRow(row []interface{}) error
+	Flush() error
+	Close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case entity.ExportFormatCSV:
+		return newCSVExportWriter(w)
+	case entity.ExportFormatXLSX:
+		return newXLSXExportWriter(w)
+	default:
+		return nil, fmt.Errorf("định dạng không hỗ trợ: %s", format)
+	}
+}
+
+// === CSV ===
+
+// csvExportWriter ghi UTF-8 có BOM để Excel nhận đúng tiếng Việt, số viết kiểu Việt Nam (1.234.567,5)
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) (*csvExportWriter, error) {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return nil, err
+	}
+	return &csvExportWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvExportWriter) WriteHeader(columns []exportColumn) error {
+	titles := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.Title
+	}
+	return c.w.Write(titles)
+}
+
+func (c *csvExportWriter) WriteRow(row []interface{}) error {
+	record := make([]string, len(row))
+	for i, v := range row {
+		switch val := v.(type) {
+		case nil:
+		case string:
+			record[i] = csvSafeText(val)
+		case float64:
+			record[i] = formatVNNumber(val)
+		case int64:
+			record[i] = formatVNNumber(float64(val))
+		case time.Time:
+			record[i] = val.In(rollupLocation()).Format(exportTimeLayout)
+		default:
+			record[i] = fmt.Sprint(val)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) Close() error {
+	return c.Flush()
+}
+
+// csvSafeText thêm dấu ' trước ô văn bản bắt đầu bằng ký tự công thức để Excel/Sheets không chạy nội dung
+// do người dùng nhập (tên sản phẩm, lý do hủy...) như công thức. XLSX ghi ô kiểu chuỗi nên không cần.
+func csvSafeText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// formatVNNumber viết số theo kiểu Việt Nam: dấu chấm ngăn hàng nghìn, dấu phẩy thập phân, tối đa 2 chữ số lẻ
+func formatVNNumber(f float64) string {
+	// làm tròn trước để số âm rất nhỏ không thành "-0"
+	f = math.Round(f*100) / 100
+	sign := ""
+	if f < 0 {
+		sign = "-"
+	}
+	s := strconv.FormatFloat(math.Abs(f), 'f', 2, 64)
+	intPart, frac := s[:len(s)-3], strings.TrimRight(s[len(s)-2:], "0")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteByte(',')
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// === XLSX ===
+
+// xlsxExportWriter dùng StreamWriter của excelize: các dòng được ghi ra file tạm thay vì giữ trong bộ nhớ,
+// file hoàn chỉnh chỉ được ghi ra w khi Close. Số tiền/ngày giờ là ô số có định dạng nên lọc, cộng được trong Excel.
+type xlsxExportWriter struct {
+	out     io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []exportColumn
+	header  int
+	styles  map[exportKind]int
+	row     int
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", exportSheetName); err != nil {
+		f.Close()
+		return nil, err
+	}
+	stream, err := f.NewStreamWriter(exportSheetName)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	header, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	moneyFmt, timeFmt := `#,##0`, "dd/mm/yyyy hh:mm:ss"
+	styles := map[exportKind]*excelize.Style{
+		exportMoney:  {CustomNumFmt: &moneyFmt},
+		exportNumber: {CustomNumFmt: &moneyFmt},
+		exportTime:   {CustomNumFmt: &timeFmt},
+	}
+	x := &xlsxExportWriter{out: w, file: f, stream: stream, header: header, styles: make(map[exportKind]int), row: 1}
+	for kind, style := range styles {
+		id, err := f.NewStyle(style)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		x.styles[kind] = id
+	}
+	return x, nil
+}
+
+// WriteHeader dòng tiêu đề in đậm và được cố định khi cuộn
+func (x *xlsxExportWriter) WriteHeader(columns []exportColumn) error {
+	x.columns = columns
+	cells := make([]interface{}, len(columns))
+	for i, col := range columns {
+		if col.Width > 0 {
+			if err := x.stream.SetColWidth(i+1, i+1, col.Width); err != nil {
+				return err
+			}
+		}
+		cells[i] = excelize.Cell{StyleID: x.header, Value: col.Title}
+	}
+	if err := x.stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	return x.writeCells(cells)
+}
+
+func (x *xlsxExportWriter) WriteRow(row []interface{}) error {
+	cells := make([]interface{}, len(row))
+	for i, v := range row {
+		switch val := v.(type) {
+		case nil:
+		case time.Time:
+			// excelize tính ngày theo UTC, đổi sang giờ Việt Nam rồi giữ nguyên giờ hiển thị
+			local := val.In(rollupLocation())
+			cells[i] = excelize.Cell{StyleID: x.styles[exportTime], Value: time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)}
+		default:
+			if i < len(x.columns) && x.columns[i].Kind != exportText {
+				cells[i] = excelize.Cell{StyleID: x.styles[x.columns[i].Kind], Value: val}
+			} else {
+				cells[i] = val
+			}
+		}
+	}
+	return x.writeCells(cells)
+}
+
+func (x *xlsxExportWriter) writeCells(cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	x.row++
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxExportWriter) Flush() error {
+	return nil
+}
+
+func (x *xlsxExportWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var testExportColumns = []exportColumn{
+	{Title: "Mã", Width: 10},
+	{Title: "Số tiền (VNĐ)", Kind: exportMoney},
+	{Title: "Số lượng", Kind: exportNumber},
+	{Title: "Thời gian", Kind: exportTime},
+	{Title: "Ghi chú"},
+}
+
+func TestFormatVNNumber(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0, "0"},
+		{5, "5"},
+		{999, "999"},
+		{1000, "1.000"},
+		{1234567, "1.234.567"},
+		{1234567.5, "1.234.567,5"},
+		{1234.56, "1.234,56"},
+		// làm tròn 2 chữ số lẻ, có thể nhảy sang hàng nghìn
+		{999.999, "1.000"},
+		{0.005, "0,01"},
+		{-1234.5, "-1.234,5"},
+		{-0.001, "0"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, formatVNNumber(tt.in), "%v", tt.in)
+	}
+}
+
+func TestCSVSafeText(t *testing.T) {
+	require.Equal(t, "'=HYPERLINK(\"http://x\")", csvSafeText("=HYPERLINK(\"http://x\")"))
+	require.Equal(t, "'+1", csvSafeText("+1"))
+	require.Equal(t, "'-2+3", csvSafeText("-2+3"))
+	require.Equal(t, "'@SUM(A1)", csvSafeText("@SUM(A1)"))
+	require.Equal(t, "'\t=1", csvSafeText("\t=1"))
+	require.Equal(t, "", csvSafeText(""))
+	require.Equal(t, "Áo thun = đẹp", csvSafeText("Áo thun = đẹp"))
+}
+
+func TestCSVExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newExportWriter(entity.ExportFormatCSV, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader(testExportColumns))
+	// 17:30 UTC là 00:30 ngày hôm sau giờ Việt Nam
+	at := time.Date(2026, 1, 31, 17, 30, 5, 0, time.UTC)
+	require.NoError(t, w.WriteRow([]interface{}{"SO-1", 1234567.5, int64(12000), at, "=1+1"}))
+	require.NoError(t, w.WriteRow([]interface{}{"SO-2", nil, nil, nil, "có, dấu phẩy"}))
+	require.NoError(t, w.Close())
+
+	require.Equal(t, "\xEF\xBB\xBF"+
+		"Mã,Số tiền (VNĐ),Số lượng,Thời gian,Ghi chú\n"+
+		"SO-1,\"1.234.567,5\",12.000,01/02/2026 00:30:05,'=1+1\n"+
+		"SO-2,,,,\"có, dấu phẩy\"\n", buf.String())
+}
+
+func TestXLSXExportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newExportWriter(entity.ExportFormatXLSX, &buf)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader(testExportColumns))
+	at := time.Date(2026, 1, 31, 17, 30, 5, 0, time.UTC)
+	require.NoError(t, w.WriteRow([]interface{}{"SO-1", 1234567.5, int64(12000), at, "=1+1"}))
+	require.NoError(t, w.WriteRow([]interface{}{"SO-2", nil, nil, nil, "-"}))
+	require.NoError(t, w.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	require.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows(exportSheetName, excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, []string{"Mã", "Số tiền (VNĐ)", "Số lượng", "Thời gian", "Ghi chú"}, rows[0])
+	require.Equal(t, "SO-1", rows[1][0])
+	require.Equal(t, "1234567.5", rows[1][1])
+	require.Equal(t, "12000", rows[1][2])
+	require.Equal(t, []string{"SO-2", "", "", "", "-"}, rows[2])
+
+	// số tiền là ô số, văn bản bắt đầu bằng '=' vẫn là chuỗi chứ không phải công thức
+	typ, err := f.GetCellType(exportSheetName, "B2")
+	require.NoError(t, err)
+	require.NotEqual(t, excelize.CellTypeInlineString, typ)
+	require.NotEqual(t, excelize.CellTypeSharedString, typ)
+	formula, err := f.GetCellFormula(exportSheetName, "E2")
+	require.NoError(t, err)
+	require.Empty(t, formula)
+	value, err := f.GetCellValue(exportSheetName, "E2")
+	require.NoError(t, err)
+	require.Equal(t, "=1+1", value)
+
+	// ngày giờ hiển thị theo giờ Việt Nam
+	value, err = f.GetCellValue(exportSheetName, "D2")
+	require.NoError(t, err)
+	require.Equal(t, "01/02/2026 00:30:05", value)
+}
+
+func TestNewExportWriterUnknownFormat(t *testing.T) {
+	_, err := newExportWriter("pdf", &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestShopSettlementsKeysetDateBounds(t *testing.T) {
+	start := sql.NullTime{Time: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	kp := db_mysql.KeysetParams{Limit: 10}
+
+	params := shopSettlementsKeyset(entity.ListShopSettlementsParams{StartDate: start}, []string{"so-1"}, kp)
+	require.Equal(t, start, params.StartDate)
+	require.True(t, params.EndDate.Valid)
+	require.True(t, params.EndDate.Time.After(start.Time))
+	require.Equal(t, []string{"so-1"}, params.ShopOrderIDs)
+	require.Equal(t, kp, params.KeysetParams)
+
+	params = shopSettlementsKeyset(entity.ListShopSettlementsParams{EndDate: start, Status: sql.NullString{String: "SETTLED", Valid: true}}, nil, kp)
+	require.True(t, params.StartDate.Valid)
+	require.True(t, params.StartDate.Time.Before(start.Time))
+	require.True(t, params.Status.Valid)
+
+	// không có mốc nào thì không lọc theo ngày
+	params = shopSettlementsKeyset(entity.ListShopSettlementsParams{}, nil, kp)
+	require.False(t, params.StartDate.Valid)
+	require.False(t, params.EndDate.Valid)
+}
@@ -8,4 +8,8 @@ type ServiceUseCase interface {
 	iservices.ServiceSITEUseCase
 	iservices.FeedbackUseCase
 	iservices.AgentAnalyticsUseCase
+	iservices.ShopStaffUseCase
+	iservices.RollupUseCase
+	iservices.ExportUseCase
+	iservices.BuyerAnalyticsUseCase
 }
@@ -2,8 +2,10 @@ package iservices
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
 	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
 	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
 	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
@@ -23,8 +25,8 @@ type ServiceSITEUseCase interface {
 	ListShopOrderItems(ctx context.Context, params entity.ListShopOrderItemsParams) ([]db_order.OrderItems, *assets_services.ServiceError)
 
 	// === Nhóm 3: Phân tích Doanh thu & Dòng tiền ===
-	GetShopRevenueTimeseries(ctx context.Context, shopID string, startDate, endDate time.Time) (*entity.RevenueTimeseriesResponse, *assets_services.ServiceError)
-	ListShopWalletLedgerEntries(ctx context.Context, params entity.ListWalletLedgerEntriesParams) ([]db_transaction.LedgerEntries, *assets_services.ServiceError)
+	GetShopRevenueTimeseries(ctx context.Context, shopID string, params entity.TimeseriesParams) (*entity.RevenueTimeseriesResponse, *assets_services.ServiceError)
+	ListShopWalletLedgerEntries(ctx context.Context, params entity.ListWalletLedgerEntriesParams) (*entity.ListWalletLedgerEntriesResponse, *assets_services.ServiceError)
 	ListShopSettlements(ctx context.Context, params entity.ListShopSettlementsParams) ([]db_transaction.ShopOrderSettlements, *assets_services.ServiceError)
 
 	// === Nhóm 4: Phân tích Voucher ===
@@ -45,11 +47,11 @@ type ServiceSITEUseCase interface {
 	GetEnrichedPlatformOrder(ctx context.Context, orderID string) (*entity.EnrichedPlatformOrderResponse, *assets_services.ServiceError)
 
 	// Nhóm 3: Quản lý Tài chính
-	GetPlatformRevenueTimeseries(ctx context.Context, startDate, endDate time.Time) (*entity.PlatformRevenueTimeseriesResponse, *assets_services.ServiceError)
-	ListPlatformTransactions(ctx context.Context, params entity.ListPlatformTransactionsParams) ([]db_transaction.Transactions, *assets_services.ServiceError)
-	ListPlatformSettlements(ctx context.Context, params entity.ListPlatformSettlementsParams) ([]db_transaction.ShopOrderSettlements, *assets_services.ServiceError)
-	ListPlatformLedgers(ctx context.Context, params entity.ListPlatformLedgersParams) ([]db_transaction.AccountLedgers, *assets_services.ServiceError)
-	ListLedgerEntries(ctx context.Context, ledgerID string, limit, offset int32) ([]db_transaction.LedgerEntries, *assets_services.ServiceError)
+	GetPlatformRevenueTimeseries(ctx context.Context, params entity.TimeseriesParams) (*entity.PlatformRevenueTimeseriesResponse, *assets_services.ServiceError)
+	ListPlatformTransactions(ctx context.Context, params entity.ListPlatformTransactionsParams) (*entity.ListPlatformTransactionsResponse, *assets_services.ServiceError)
+	ListPlatformSettlements(ctx context.Context, params entity.ListPlatformSettlementsParams) (*entity.ListPlatformSettlementsResponse, *assets_services.ServiceError)
+	ListPlatformLedgers(ctx context.Context, params entity.ListPlatformLedgersParams) (*entity.ListPlatformLedgersResponse, *assets_services.ServiceError)
+	ListLedgerEntries(ctx context.Context, ledgerID string, limit int32, cursor string) (*entity.ListLedgerEntriesResponse, *assets_services.ServiceError)
 
 	// Nhóm 4: Phân tích Voucher
 	ListPlatformVouchers(ctx context.Context, params entity.ListPlatformVouchersParams) ([]db_order.Vouchers, *assets_services.ServiceError)
@@ -100,3 +102,48 @@ type AgentAnalyticsUseCase interface {
 	// Top Mentioned Categories
 	GetTopMentionedCategories(ctx context.Context, req *entity.GetTopMentionedCategoriesRequest) ([]entity.CategoryMentionItem, *assets_services.ServiceError)
 }
+
+type ShopStaffUseCase interface {
+	// Xác định shop người gọi API /shop/* được xem (từ token hoặc bảng shop_staff)
+	ResolveShopAccess(ctx context.Context, payload *token.Payload, requestedShopID string) (*entity.ShopAccess, *assets_services.ServiceError)
+
+	// Nhân sự shop (OWNER, MANAGER, VIEWER)
+	ListShopStaff(ctx context.Context, shopID string) ([]entity.ShopStaffItem, *assets_services.ServiceError)
+	AddShopStaff(ctx context.Context, access entity.ShopAccess, actorID string, req entity.AddShopStaffRequest) *assets_services.ServiceError
+	RemoveShopStaff(ctx context.Context, access entity.ShopAccess, actorID, userID string) *assets_services.ServiceError
+
+	// Admin gán chủ shop
+	SetShopOwner(ctx context.Context, adminID, shopID string, req entity.SetShopOwnerRequest) *assets_services.ServiceError
+}
+
+type RollupUseCase interface {
+	// Đánh dấu các ngày bị ảnh hưởng bởi 1 sự kiện đơn hàng/thanh toán (consumer Kafka gọi)
+	MarkRollupDirty(ctx context.Context, event entity.RollupEvent) error
+	// Đánh dấu các ngày có đối soát chuyển sang SETTLED trong [from, to) (job định kỳ gọi)
+	MarkSettledRollupsDirty(ctx context.Context, from, to time.Time) error
+	// Tính lại các ngày đã bị đánh dấu (job định kỳ gọi)
+	RefreshDirtyRollups(ctx context.Context) error
+	// Tính lại toàn bộ các ngày trong [from, to], dùng cho lệnh backfill
+	RebuildRollups(ctx context.Context, from, to time.Time) error
+}
+
+type ExportUseCase interface {
+	// Xuất danh sách ra CSV/XLSX. Báo cáo nhỏ được ghi thẳng vào writer do open trả về (open nhận tên file tải về),
+	// báo cáo lớn hoặc req.Async thì tạo yêu cầu chạy nền và trả về trạng thái của yêu cầu đó
+	ExportReport(ctx context.Context, req entity.ExportRequest, open func(fileName string) io.Writer) (*entity.ExportJobResponse, *assets_services.ServiceError)
+	// Trạng thái yêu cầu chạy nền của người dùng
+	GetExportJob(ctx context.Context, requestedBy, jobID string) (*entity.ExportJobResponse, *assets_services.ServiceError)
+	// Đường dẫn file và tên file tải về của yêu cầu đã xong
+	GetExportFile(ctx context.Context, requestedBy, jobID string) (string, string, *assets_services.ServiceError)
+	// Xóa file hết hạn và đánh lỗi các yêu cầu bị bỏ dở (job định kỳ gọi)
+	CleanupExportJobs(ctx context.Context) error
+}
+
+type BuyerAnalyticsUseCase interface {
+	// Phễu trạng thái đơn shop và trung vị thời gian từng bước
+	GetOrderFunnel(ctx context.Context, params entity.OrderFunnelParams) (*entity.OrderFunnelResponse, *assets_services.ServiceError)
+	// Cohort theo tháng mua đầu tiên và ma trận giữ chân
+	GetCohortRetention(ctx context.Context, params entity.CohortRetentionParams) (*entity.CohortRetentionResponse, *assets_services.ServiceError)
+	// Phân khúc người mua theo RFM
+	GetBuyerRFM(ctx context.Context, params entity.BuyerRFMParams) (*entity.BuyerRFMResponse, *assets_services.ServiceError)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+)
+
+// sort duy nhất của danh sách đơn, ghi vào cursor để không dùng lẫn cursor giữa các API khác
+const shopOrderCursorSort = "created_at"
+
+// cursorSecret khóa ký cursor phân trang, mặc định dùng chung JWT_SECRET
+func (s *service) cursorSecret() string {
+	if s.env.CursorSecret != "" {
+		return s.env.CursorSecret
+	}
+	return s.env.JWTSecret
+}
+
+// listShopOrdersCursor lấy một trang shop_orders theo cursor (created_at, id) giảm dần.
+// Lấy dư một bản ghi để biết còn trang sau hay không, trả về next/prev cursor (nil khi hết trang).
+func (s *service) listShopOrdersCursor(ctx context.Context, params db_mysql.ShopOrderKeysetParams, token string) ([]db_order.ShopOrders, *string, *string, *assets_services.ServiceError) {
+	var cursor *assets_services.Cursor
+	if token != "" {
+		c, err := assets_services.DecodeCursor(s.cursorSecret(), token)
+		if err != nil || c.Sort != shopOrderCursorSort {
+			return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, assets_services.ErrInvalidCursor)
+		}
+		after, err := time.Parse(time.RFC3339Nano, c.Key)
+		if err != nil {
+			return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, assets_services.ErrInvalidCursor)
+		}
+		params.AfterCreatedAt, params.AfterID, params.Backward = after, c.ID, c.Backward
+		cursor = &c
+	}
+	limit := int(params.Limit)
+	params.Limit++
+
+	items, err := s.order.ListShopOrdersKeyset(ctx, params)
+	if err != nil {
+		return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("lỗi khi lấy danh sách đơn hàng: %w", err))
+	}
+	page, hasNext, hasPrev := assets_services.KeysetPage(items, limit, cursor)
+	if len(page) == 0 {
+		return page, nil, nil, nil
+	}
+	var next, prev *string
+	if hasNext {
+		next = s.shopOrderCursor(page[len(page)-1], false)
+	}
+	if hasPrev {
+		prev = s.shopOrderCursor(page[0], true)
+	}
+	return page, next, prev, nil
+}
+
+func (s *service) shopOrderCursor(so db_order.ShopOrders, backward bool) *string {
+	token := assets_services.EncodeCursor(s.cursorSecret(), assets_services.Cursor{
+		Sort:     shopOrderCursorSort,
+		Key:      so.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:       so.ID,
+		Backward: backward,
+	})
+	return &token
+}
+
+// shopOrderStatusFilter chuyển bộ lọc trạng thái từ query string sang kiểu enum của order_db
+func shopOrderStatusFilter(status sql.NullString) db_order.NullShopOrdersStatus {
+	return db_order.NullShopOrdersStatus{ShopOrdersStatus: db_order.ShopOrdersStatus(status.String), Valid: status.Valid}
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
 	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
 	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
 	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
@@ -17,25 +18,26 @@ import (
 )
 
 // GetShopOverview xử lý API: GET /api/v1/shop/overview
+// Số đơn/GMV và doanh thu thuần đọc từ bảng tổng hợp theo ngày, số dư ví đọc trực tiếp
 func (s *service) GetShopOverview(ctx context.Context, shopID string, startDate, endDate time.Time) (*entity.ShopOverviewResponse, *assets_services.ServiceError) {
 
 	// Dùng errgroup để chạy 3 tác vụ đồng thời
 	g, gCtx := errgroup.WithContext(ctx)
 
-	var orderOverview db_order.GetShopOrderOverviewRow
+	var orderOverview db_interact.GetRollupShopSummaryRow
 	var shopLedger db_transaction.AccountLedgers
-	var shopOrderIDs []string
+	var totalNetRevenue interface{}
 
-	// Tác vụ 1: Lấy tổng quan đơn hàng (từ order_db)
+	// Tác vụ 1: Lấy tổng quan đơn hàng (từ rollup_shop_daily)
 	g.Go(func() error {
+		from, to := rollupDateRange(startDate, endDate)
 		var err error
-		orderOverview, err = s.order.GetShopOrderOverview(gCtx, db_order.GetShopOrderOverviewParams{
-			ShopID:    shopID,
-			StartDate: sql.NullTime{Time: startDate, Valid: startDate.IsZero() == false},
-			EndDate:   sql.NullTime{Time: endDate, Valid: endDate.IsZero() == false},
+		orderOverview, err = s.interact.GetRollupShopSummary(gCtx, db_interact.GetRollupShopSummaryParams{
+			ShopID:   shopID,
+			FromDate: from,
+			ToDate:   to,
 		})
 		if err != nil && err != sql.ErrNoRows {
-			// log.Printf("Error GetShopOrderOverview: %v", err)
 			return fmt.Errorf("lỗi khi lấy tổng quan đơn hàng: %w", err)
 		}
 		return nil
@@ -51,13 +53,12 @@ func (s *service) GetShopOverview(ctx context.Context, shopID string, startDate,
 		return nil
 	})
 
-	// Tác vụ 3: Lấy *tất cả* ID đơn hàng của shop (từ order_db)
-	// (Đây là tác vụ có thể nặng nếu Shop có hàng triệu đơn)
+	// Tác vụ 3: Tổng doanh thu thuần đã đối soát từ trước tới nay (từ rollup_shop_daily)
 	g.Go(func() error {
 		var err error
-		shopOrderIDs, err = s.order.GetShopOrderIDs(gCtx, shopID)
+		totalNetRevenue, err = s.interact.GetRollupShopNetRevenueTotal(gCtx, shopID)
 		if err != nil && err != sql.ErrNoRows {
-			return fmt.Errorf("lỗi khi lấy danh sách ID đơn hàng: %w", err)
+			return fmt.Errorf("lỗi khi lấy thông tin đối soát: %w", err)
 		}
 		return nil
 	})
@@ -67,29 +68,16 @@ func (s *service) GetShopOverview(ctx context.Context, shopID string, startDate,
 		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: err}
 	}
 
-	// Tác vụ 4: Lấy thống kê đối soát (từ transaction_db)
-	// Chạy SAU KHI có danh sách ID
-	var settlementStats db_transaction.GetShopSettlementStatsByOrderIDsRow
-	if len(shopOrderIDs) > 0 {
-		var err error
-		settlementStats, err = s.transaction.GetShopSettlementStatsByOrderIDs(ctx, shopOrderIDs)
-		if err != nil && err != sql.ErrNoRows {
-			log.Printf("Error GetShopSettlementStatsByOrderIDs: %v", err)
-			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin đối soát: %w", err)}
-		}
-	}
-
 	// Tổng hợp kết quả
-	resp := &entity.ShopOverviewResponse{
-		TotalOrders:      orderOverview.TotalOrders,
-		ProcessingOrders: orderOverview.ProcessingOrders,
-	}
+	resp := &entity.ShopOverviewResponse{}
 
 	// Xử lý parse (trong thực tế nên dùng thư viện decimal)
+	resp.TotalOrders, _ = entity.ParseInterfaceToInt(orderOverview.TotalOrders)
+	resp.ProcessingOrders, _ = entity.ParseInterfaceToInt(orderOverview.ProcessingOrders)
 	resp.TotalGMV, _ = entity.ParseAmountToFloat64(orderOverview.TotalGmv)
 	resp.WalletBalance, _ = entity.ParseAmountToFloat64(shopLedger.Balance)
 	resp.PendingBalance, _ = entity.ParseAmountToFloat64(shopLedger.PendingBalance)
-	resp.TotalNetRevenue, _ = entity.ParseAmountToFloat64(settlementStats.TotalSettled)
+	resp.TotalNetRevenue, _ = entity.ParseAmountToFloat64(totalNetRevenue)
 
 	return resp, nil
 }
@@ -130,10 +118,9 @@ func (s *service) GetShopWalletSummary(ctx context.Context, shopID string) (*ent
 	g.Go(func() error {
 		var err error
 		// Lấy 1000 giao dịch gần nhất để tính
-		ledgerEntries, err = s.transaction.ListLedgerEntriesByOwnerID(gCtx, db_transaction.ListLedgerEntriesByOwnerIDParams{
-			OwnerID: shopID,
-			Limit:   1000, // Giới hạn, nếu không sẽ sập CSDL
-			Offset:  0,
+		ledgerEntries, err = s.transaction.ListLedgerEntriesKeyset(gCtx, db_mysql.LedgerEntryKeysetParams{
+			ShopID:       shopID,
+			KeysetParams: db_mysql.KeysetParams{Limit: 1000}, // Giới hạn, nếu không sẽ sập CSDL
 		})
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("lỗi khi lấy lịch sử giao dịch: %w", err)
@@ -183,19 +170,36 @@ func (s *service) GetShopWalletSummary(ctx context.Context, shopID string) (*ent
 func (s *service) ListShopOrders(ctx context.Context, params entity.ListShopOrdersParams) (*entity.ListShopOrdersResponse, *assets_services.ServiceError) {
 
 	// Bước 1: Lấy danh sách đơn hàng của shop từ order_db
-	shopOrders, err := s.order.ListShopOrders(ctx, db_order.ListShopOrdersParams{
-		ShopID:       params.ShopID,
-		StatusFilter: db_order.NullShopOrdersStatus{ShopOrdersStatus: db_order.ShopOrdersStatus(params.Status.String), Valid: params.Status.Valid},
-		StartDate:    params.StartDate,
-		EndDate:      params.EndDate,
-		Limit:        params.Limit,
-		Offset:       params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return &entity.ListShopOrdersResponse{Orders: []entity.ShopOrderWithPaymentStatus{}}, nil
+	var shopOrders []db_order.ShopOrders
+	var nextCursor, prevCursor *string
+	if params.CursorMode {
+		var serr *assets_services.ServiceError
+		shopOrders, nextCursor, prevCursor, serr = s.listShopOrdersCursor(ctx, db_mysql.ShopOrderKeysetParams{
+			ShopID:    sql.NullString{String: params.ShopID, Valid: true},
+			Status:    shopOrderStatusFilter(params.Status),
+			StartDate: params.StartDate,
+			EndDate:   params.EndDate,
+			Limit:     params.Limit,
+		}, params.Cursor)
+		if serr != nil {
+			return nil, serr
+		}
+	} else {
+		var err error
+		shopOrders, err = s.order.ListShopOrders(ctx, db_order.ListShopOrdersParams{
+			ShopID:       params.ShopID,
+			StatusFilter: shopOrderStatusFilter(params.Status),
+			StartDate:    params.StartDate,
+			EndDate:      params.EndDate,
+			Limit:        params.Limit,
+			Offset:       params.Offset,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &entity.ListShopOrdersResponse{Orders: []entity.ShopOrderWithPaymentStatus{}}, nil
+			}
+			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy danh sách đơn hàng: %w", err)}
 		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy danh sách đơn hàng: %w", err)}
 	}
 
 	if len(shopOrders) == 0 {
@@ -224,7 +228,9 @@ func (s *service) ListShopOrders(ctx context.Context, params entity.ListShopOrde
 
 	// Bước 4: Tổng hợp dữ liệu
 	resp := &entity.ListShopOrdersResponse{
-		Orders: make([]entity.ShopOrderWithPaymentStatus, len(shopOrders)),
+		Orders:     make([]entity.ShopOrderWithPaymentStatus, len(shopOrders)),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}
 	for i, so := range shopOrders {
 		// status := "UNKNOWN" // Mặc định nếu không tìm thấy
@@ -348,27 +354,49 @@ func (s *service) ListShopOrderItems(ctx context.Context, params entity.ListShop
 // === NHÓM 3: Phân tích Doanh thu & Dòng tiền ===
 
 // GetShopRevenueTimeseries xử lý API: GET /api/v1/shop/revenue/timeseries
-func (s *service) GetShopRevenueTimeseries(ctx context.Context, shopID string, startDate, endDate time.Time) (*entity.RevenueTimeseriesResponse, *assets_services.ServiceError) {
+// Trả chuỗi đã lấp đủ các khung (khung không có đơn = 0), kèm chuỗi so sánh khi có compare.
+func (s *service) GetShopRevenueTimeseries(ctx context.Context, shopID string, params entity.TimeseriesParams) (*entity.RevenueTimeseriesResponse, *assets_services.ServiceError) {
+	current, compare, serr := newTimeseriesWindow(params)
+	if serr != nil {
+		return nil, serr
+	}
+	if useDailyRollup(current) {
+		return s.getShopRevenueTimeseriesFromRollup(ctx, shopID, params, current, compare)
+	}
 
 	g, gCtx := errgroup.WithContext(ctx)
 
-	var gmvData []db_order.GetShopRevenueTimeSeriesRow
+	var gmvData, compareGmvData []db_order.GetShopRevenueTimeSeriesRow
 	var shopOrderIDs []string
 	var settlements []db_transaction.ShopOrderSettlements
 
-	// Tác vụ 1: Lấy GMV timeseries
+	// Tác vụ 1: Lấy GMV timeseries (kỳ hiện tại và kỳ so sánh)
 	g.Go(func() error {
 		var err error
 		gmvData, err = s.order.GetShopRevenueTimeSeries(gCtx, db_order.GetShopRevenueTimeSeriesParams{
 			ShopID:          shopID,
-			FromCompletedAt: sql.NullTime{Time: startDate, Valid: true},
-			ToCompletedAt:   sql.NullTime{Time: endDate, Valid: true},
+			FromCompletedAt: sql.NullTime{Time: current.From, Valid: true},
+			ToCompletedAt:   sql.NullTime{Time: current.To, Valid: true},
 		})
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("lỗi khi lấy dữ liệu GMV: %w", err)
 		}
 		return nil
 	})
+	if compare != nil {
+		g.Go(func() error {
+			var err error
+			compareGmvData, err = s.order.GetShopRevenueTimeSeries(gCtx, db_order.GetShopRevenueTimeSeriesParams{
+				ShopID:          shopID,
+				FromCompletedAt: sql.NullTime{Time: compare.From, Valid: true},
+				ToCompletedAt:   sql.NullTime{Time: compare.To, Valid: true},
+			})
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("lỗi khi lấy dữ liệu GMV kỳ so sánh: %w", err)
+			}
+			return nil
+		})
+	}
 
 	// Tác vụ 2: Lấy ID đơn hàng
 	g.Go(func() error {
@@ -385,96 +413,179 @@ func (s *service) GetShopRevenueTimeseries(ctx context.Context, shopID string, s
 		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi chờ các tác vụ: %w", err)}
 	}
 
-	// ✅ Tạo errgroup mới cho tác vụ tiếp theo
-	g2, gCtx2 := errgroup.WithContext(ctx)
-
-	// Tác vụ 3: Lấy settlements (chạy sau khi có shopOrderIDs)
-	g2.Go(func() error {
-		if len(shopOrderIDs) == 0 {
-			return nil
-		}
+	// Tác vụ 3: Lấy settlements (chạy sau khi có shopOrderIDs), dùng chung cho cả hai kỳ
+	if len(shopOrderIDs) > 0 {
 		var err error
-		settlements, err = s.transaction.GetShopSettlementsByOrderIDs(gCtx2, shopOrderIDs)
+		settlements, err = s.transaction.GetShopSettlementsByOrderIDs(ctx, shopOrderIDs)
 		if err != nil && err != sql.ErrNoRows {
-			return fmt.Errorf("lỗi khi lấy thông tin đối soát: %w", err)
+			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin đối soát: %w", err)}
 		}
-		return nil
-	})
-
-	if err := g2.Wait(); err != nil {
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi chờ tác vụ đối soát: %w", err)}
 	}
 
 	// Bắt đầu xử lý logic trong Go (Kém hiệu quả, nhưng bắt buộc do kiến trúc 3-DB)
+	resp := &entity.RevenueTimeseriesResponse{
+		Granularity: current.Granularity,
+		Timezone:    current.Loc.String(),
+		Compare:     params.Compare,
+	}
+	resp.Data, resp.Total = shopRevenueSeries(current, gmvData, settlements)
+	if compare == nil {
+		return resp, nil
+	}
 
-	// map[date_string] -> net_revenue
-	netRevenueMap := make(map[string]float64)
-	for _, set := range settlements {
-		if set.Status == "SETTLED" && set.SettledAt.Valid {
-			dateStr := set.SettledAt.Time.Format("2006-01-02")
+	comparison, compareTotal := shopRevenueSeries(compare, compareGmvData, settlements)
+	attachRevenueComparison(resp, comparison, compareTotal)
+	return resp, nil
+}
 
-			// Lọc theo ngày
-			if set.SettledAt.Time.Before(startDate) || set.SettledAt.Time.After(endDate) {
-				continue
-			}
+// getShopRevenueTimeseriesFromRollup đọc chuỗi theo ngày/tuần/tháng từ rollup_shop_daily thay vì quét đơn hàng gốc
+func (s *service) getShopRevenueTimeseriesFromRollup(ctx context.Context, shopID string, params entity.TimeseriesParams, current, compare *timeseriesWindow) (*entity.RevenueTimeseriesResponse, *assets_services.ServiceError) {
+	g, gCtx := errgroup.WithContext(ctx)
 
-			amount, _ := entity.ParseAmountToFloat64(set.NetSettledAmount)
-			netRevenueMap[dateStr] += amount
+	var rows, compareRows []db_interact.RollupShopDaily
+	g.Go(func() error {
+		var err error
+		rows, err = s.interact.ListRollupShopDaily(gCtx, db_interact.ListRollupShopDailyParams{
+			ShopID:   shopID,
+			FromDate: current.From,
+			ToDate:   current.To,
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy dữ liệu tổng hợp: %w", err)
 		}
+		return nil
+	})
+	if compare != nil {
+		g.Go(func() error {
+			var err error
+			compareRows, err = s.interact.ListRollupShopDaily(gCtx, db_interact.ListRollupShopDailyParams{
+				ShopID:   shopID,
+				FromDate: compare.From,
+				ToDate:   compare.To,
+			})
+			if err != nil {
+				return fmt.Errorf("lỗi khi lấy dữ liệu tổng hợp kỳ so sánh: %w", err)
+			}
+			return nil
+		})
 	}
+	if err := g.Wait(); err != nil {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: err}
+	}
+
+	resp := &entity.RevenueTimeseriesResponse{
+		Granularity: current.Granularity,
+		Timezone:    current.Loc.String(),
+		Compare:     params.Compare,
+	}
+	resp.Data, resp.Total = shopRollupRevenueSeries(current, rows)
+	if compare == nil {
+		return resp, nil
+	}
+	comparison, compareTotal := shopRollupRevenueSeries(compare, compareRows)
+	attachRevenueComparison(resp, comparison, compareTotal)
+	return resp, nil
+}
+
+// attachRevenueComparison gắn chuỗi kỳ so sánh và phần trăm thay đổi vào resp
+func attachRevenueComparison(resp *entity.RevenueTimeseriesResponse, comparison []entity.RevenueDatapoint, compareTotal entity.RevenueMetrics) {
+	// Hai chuỗi khớp theo vị trí khung, kỳ so sánh có thể lệch một khung (vd: tuần của năm trước)
+	if len(comparison) > len(resp.Data) {
+		comparison = comparison[:len(resp.Data)]
+	}
+	for i := range comparison {
+		resp.Data[i].Delta = revenueDelta(resp.Data[i].RevenueMetrics, comparison[i].RevenueMetrics)
+	}
+	resp.Comparison = comparison
+	resp.ComparisonTotal = &compareTotal
+	resp.TotalDelta = revenueDelta(resp.Total, compareTotal)
+}
+
+// shopRevenueSeries gom GMV (theo completed_at) và doanh thu thuần (theo settled_at) vào các khung của w
+func shopRevenueSeries(w *timeseriesWindow, gmvData []db_order.GetShopRevenueTimeSeriesRow, settlements []db_transaction.ShopOrderSettlements) ([]entity.RevenueDatapoint, entity.RevenueMetrics) {
+	data := make([]entity.RevenueDatapoint, len(w.Buckets))
+	for i, b := range w.Buckets {
+		data[i] = entity.RevenueDatapoint{Date: w.label(i), BucketStart: b}
+	}
+	var total entity.RevenueMetrics
 
-	// map[date_string] -> Datapoint
-	// Dùng GMV làm "key" chính
-	finalMap := make(map[string]entity.RevenueDatapoint)
-	for _, gmv := range gmvData {
-		dateStr := gmv.ReportDate.Format("2006-01-02")
-		amount, _ := entity.ParseAmountToFloat64(gmv.Gmv)
-		finalMap[dateStr] = entity.RevenueDatapoint{
-			Date: dateStr,
-			GMV:  amount,
-			// NetRevenue sẽ được điền ở bước sau
+	for _, row := range gmvData {
+		i := w.index(row.SlotStart)
+		if i < 0 {
+			continue
 		}
+		gmv, _ := entity.ParseAmountToFloat64(row.Gmv)
+		data[i].GMV += gmv
+		data[i].Orders += row.OrderCount
+		total.GMV += gmv
+		total.Orders += row.OrderCount
 	}
 
-	// Ghép NetRevenue vào
-	for dateStr, netAmount := range netRevenueMap {
-		if dp, ok := finalMap[dateStr]; ok {
-			dp.NetRevenue = netAmount
-			finalMap[dateStr] = dp
-		} else {
-			// (Trường hợp có NetRevenue nhưng không có GMV)
-			finalMap[dateStr] = entity.RevenueDatapoint{
-				Date:       dateStr,
-				NetRevenue: netAmount,
-			}
+	for _, set := range settlements {
+		if set.Status != "SETTLED" || !set.SettledAt.Valid {
+			continue
 		}
+		i := w.index(set.SettledAt.Time)
+		if i < 0 {
+			continue
+		}
+		amount, _ := entity.ParseAmountToFloat64(set.NetSettledAmount)
+		data[i].NetRevenue += amount
+		total.NetRevenue += amount
 	}
 
-	resp := &entity.RevenueTimeseriesResponse{
-		Data: make([]entity.RevenueDatapoint, 0, len(finalMap)),
+	for i := range data {
+		data[i].AOV = averageOrderValue(data[i].GMV, data[i].Orders)
 	}
-	for _, dp := range finalMap {
-		resp.Data = append(resp.Data, dp)
+	total.AOV = averageOrderValue(total.GMV, total.Orders)
+	return data, total
+}
+
+// shopRollupRevenueSeries gom các dòng rollup theo ngày vào các khung của w
+func shopRollupRevenueSeries(w *timeseriesWindow, rows []db_interact.RollupShopDaily) ([]entity.RevenueDatapoint, entity.RevenueMetrics) {
+	data := make([]entity.RevenueDatapoint, len(w.Buckets))
+	for i, b := range w.Buckets {
+		data[i] = entity.RevenueDatapoint{Date: w.label(i), BucketStart: b}
 	}
-	// (Nên sắp xếp resp.Data theo ngày trước khi trả về)
+	var total entity.RevenueMetrics
 
-	return resp, nil
+	for _, row := range rows {
+		i := w.index(row.StatDate)
+		if i < 0 {
+			continue
+		}
+		gmv, _ := entity.ParseAmountToFloat64(row.CompletedGmv)
+		net, _ := entity.ParseAmountToFloat64(row.NetRevenue)
+		orders := int64(row.CompletedOrders)
+		data[i].GMV += gmv
+		data[i].Orders += orders
+		data[i].NetRevenue += net
+		total.GMV += gmv
+		total.Orders += orders
+		total.NetRevenue += net
+	}
+
+	for i := range data {
+		data[i].AOV = averageOrderValue(data[i].GMV, data[i].Orders)
+	}
+	total.AOV = averageOrderValue(total.GMV, total.Orders)
+	return data, total
 }
 
 // ListShopWalletLedgerEntries xử lý API: GET /api/v1/shop/wallet/ledger-entries
-func (s *service) ListShopWalletLedgerEntries(ctx context.Context, params entity.ListWalletLedgerEntriesParams) ([]db_transaction.LedgerEntries, *assets_services.ServiceError) {
-	entries, err := s.transaction.ListLedgerEntriesByOwnerID(ctx, db_transaction.ListLedgerEntriesByOwnerIDParams{
-		OwnerID: params.ShopID,
-		Limit:   params.Limit,
-		Offset:  params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []db_transaction.LedgerEntries{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin giao dịch: %w", err)}
+func (s *service) ListShopWalletLedgerEntries(ctx context.Context, params entity.ListWalletLedgerEntriesParams) (*entity.ListWalletLedgerEntriesResponse, *assets_services.ServiceError) {
+	entries, next, prev, serr := keysetList(s, ledgerEntryCursorSort, params.Cursor, params.Limit,
+		func(kp db_mysql.KeysetParams) ([]db_transaction.LedgerEntries, error) {
+			entries, err := s.transaction.ListLedgerEntriesKeyset(ctx, db_mysql.LedgerEntryKeysetParams{ShopID: params.ShopID, KeysetParams: kp})
+			if err != nil {
+				return nil, fmt.Errorf("lỗi khi lấy thông tin giao dịch: %w", err)
+			}
+			return entries, nil
+		}, ledgerEntryKey)
+	if serr != nil {
+		return nil, serr
 	}
-	return entries, nil
+	return &entity.ListWalletLedgerEntriesResponse{Entries: emptyIfNil(entries), NextCursor: next, PrevCursor: prev}, nil
 }
 
 // ListShopSettlements xử lý API: GET /api/v1/shop/settlements
@@ -552,10 +663,12 @@ func (s *service) ListShopVouchers(ctx context.Context, params entity.ListShopVo
 
 // GetShopVoucherPerformance xử lý API: GET /api/v1/shop/vouchers/performance
 func (s *service) GetShopVoucherPerformance(ctx context.Context, shopID string, startDate, endDate time.Time) (*entity.VoucherPerformanceResponse, *assets_services.ServiceError) {
-	stats, err := s.order.GetVoucherUsagePerformanceByOwner(ctx, db_order.GetVoucherUsagePerformanceByOwnerParams{
-		OwnerID:    shopID,
-		FromUsedAt: startDate,
-		ToUsedAt:   endDate,
+	from, to := rollupDateRange(startDate, endDate)
+	stats, err := s.interact.GetRollupVoucherUsage(ctx, db_interact.GetRollupVoucherUsageParams{
+		OwnerType: string(db_order.VouchersOwnerTypeSHOP),
+		OwnerID:   sql.NullString{String: shopID, Valid: true},
+		FromDate:  from,
+		ToDate:    to,
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -564,9 +677,8 @@ func (s *service) GetShopVoucherPerformance(ctx context.Context, shopID string,
 		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin hiệu suất voucher: %w", err)}
 	}
 
-	resp := &entity.VoucherPerformanceResponse{
-		TotalUsageCount: stats.TotalUsageCount,
-	}
+	resp := &entity.VoucherPerformanceResponse{}
+	resp.TotalUsageCount, _ = entity.ParseInterfaceToInt(stats.TotalUsageCount)
 	resp.TotalDiscountValue, _ = entity.ParseAmountToFloat64(stats.TotalDiscountValue)
 
 	return resp, nil
@@ -597,64 +709,39 @@ func (s *service) GetShopVoucherUsageDetails(ctx context.Context, params entity.
 // GetShopRankingProducts xử lý API: GET /api/v1/shop/ranking/products
 func (s *service) GetShopRankingProducts(ctx context.Context, params entity.ShopRankingProductsParams) ([]entity.ProductRankingRow, *assets_services.ServiceError) {
 
-	if params.SortBy == "revenue" {
-		rows, err := s.order.GetShopTopProductsByRevenue(ctx, db_order.GetShopTopProductsByRevenueParams{
-			ShopID:    params.ShopID,
-			StartDate: sql.NullTime{Time: params.StartDate, Valid: true},
-			EndDate:   sql.NullTime{Time: params.EndDate, Valid: true},
-			Limit:     params.Limit,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return []entity.ProductRankingRow{}, nil
-			}
-			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy sản phẩm xếp hạng theo doanh thu: %w", err)}
-		}
-
-		// Chuyển đổi sang DTO
-		resp := make([]entity.ProductRankingRow, len(rows))
-		for i, row := range rows {
-			revenue, _ := entity.ParseAmountToFloat64(row.TotalRevenue)
-			resp[i] = entity.ProductRankingRow{
-				ProductID:             row.ProductID,
-				SkuID:                 row.SkuID,
-				ProductNameSnapshot:   row.ProductNameSnapshot,
-				SkuAttributesSnapshot: row.SkuAttributesSnapshot.String,
-				TotalRevenue:          revenue,
-				// TotalQuantity không được trả về từ query này
-			}
-		}
-		return resp, nil
+	if params.SortBy != "revenue" && params.SortBy != "quantity" {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("tham số sort_by không hợp lệ: %s", params.SortBy)}
+	}
 
-	} else if params.SortBy == "quantity" {
-		rows, err := s.order.GetShopTopProductsByQuantity(ctx, db_order.GetShopTopProductsByQuantityParams{
-			ShopID:    params.ShopID,
-			StartDate: sql.NullTime{Time: params.StartDate, Valid: true},
-			EndDate:   sql.NullTime{Time: params.EndDate, Valid: true},
-			Limit:     params.Limit,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				return []entity.ProductRankingRow{}, nil
-			}
-			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy sản phẩm xếp hạng theo số lượng: %w", err)}
-		}
-
-		// Chuyển đổi sang DTO
-		resp := make([]entity.ProductRankingRow, len(rows))
-		for i, row := range rows {
-			quantity, _ := entity.ParseInterfaceToInt(row.TotalQuantity)
-			resp[i] = entity.ProductRankingRow{
-				ProductID:             row.ProductID,
-				SkuID:                 row.SkuID,
-				ProductNameSnapshot:   row.ProductNameSnapshot,
-				SkuAttributesSnapshot: row.SkuAttributesSnapshot.String,
-				TotalQuantity:         quantity,
-				// TotalRevenue không được trả về từ query này
-			}
+	// Đọc từ rollup_product_daily, trả về cả doanh thu và số lượng
+	from, to := rollupDateRange(params.StartDate, params.EndDate)
+	rows, err := s.interact.ListRollupShopTopProducts(ctx, db_interact.ListRollupShopTopProductsParams{
+		ShopID:   params.ShopID,
+		FromDate: from,
+		ToDate:   to,
+		SortBy:   params.SortBy,
+		Limit:    params.Limit,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []entity.ProductRankingRow{}, nil
 		}
-		return resp, nil
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy sản phẩm xếp hạng: %w", err)}
 	}
 
-	return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("tham số sort_by không hợp lệ: %s", params.SortBy)}
+	// Chuyển đổi sang DTO
+	resp := make([]entity.ProductRankingRow, len(rows))
+	for i, row := range rows {
+		revenue, _ := entity.ParseAmountToFloat64(row.TotalRevenue)
+		quantity, _ := entity.ParseInterfaceToInt(row.TotalQuantity)
+		resp[i] = entity.ProductRankingRow{
+			ProductID:             row.ProductID,
+			SkuID:                 row.SkuID,
+			ProductNameSnapshot:   row.ProductName,
+			SkuAttributesSnapshot: row.SkuAttributes.String,
+			TotalRevenue:          revenue,
+			TotalQuantity:         quantity,
+		}
+	}
+	return resp, nil
 }
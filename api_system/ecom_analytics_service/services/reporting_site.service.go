@@ -4,10 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
 	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
 	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
 	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
@@ -23,16 +24,16 @@ func (s *service) GetPlatformOverview(ctx context.Context, startDate, endDate ti
 
 	g, gCtx := errgroup.WithContext(ctx)
 
-	var orderOverview db_order.GetPlatformOrderOverviewRow
-	var revenueSummary db_transaction.GetPlatformRevenueSummaryRow
+	var summary db_interact.GetRollupPlatformSummaryRow
 	var costSummary db_transaction.GetPlatformCostSummaryRow
 
-	// Tác vụ 1: Lấy tổng quan GMV, Order, User, Shop (từ order_db)
+	// Tác vụ 1: Lấy GMV, Order, Shop và Doanh thu Sàn (từ rollup_shop_daily)
 	g.Go(func() error {
+		from, to := rollupDateRange(startDate, endDate)
 		var err error
-		orderOverview, err = s.order.GetPlatformOrderOverview(gCtx, db_order.GetPlatformOrderOverviewParams{
-			FromCompletedAt: sql.NullTime{Time: startDate, Valid: true},
-			ToCompletedAt:   sql.NullTime{Time: endDate, Valid: true},
+		summary, err = s.interact.GetRollupPlatformSummary(gCtx, db_interact.GetRollupPlatformSummaryParams{
+			FromDate: from,
+			ToDate:   to,
 		})
 		if err != nil && err != sql.ErrNoRows {
 			return fmt.Errorf("lỗi khi lấy tổng quan đơn hàng: %w", err)
@@ -40,20 +41,7 @@ func (s *service) GetPlatformOverview(ctx context.Context, startDate, endDate ti
 		return nil
 	})
 
-	// Tác vụ 2: Lấy Doanh thu Sàn (từ transaction_db)
-	g.Go(func() error {
-		var err error
-		revenueSummary, err = s.transaction.GetPlatformRevenueSummary(gCtx, db_transaction.GetPlatformRevenueSummaryParams{
-			FromSettledAt: sql.NullTime{Time: startDate, Valid: true},
-			ToSettledAt:   sql.NullTime{Time: endDate, Valid: true},
-		})
-		if err != nil && err != sql.ErrNoRows {
-			return fmt.Errorf("lỗi khi lấy doanh thu sàn: %w", err)
-		}
-		return nil
-	})
-
-	// Tác vụ 3: Lấy Chi phí Sàn (từ transaction_db)
+	// Tác vụ 2: Lấy Chi phí Sàn (từ transaction_db)
 	g.Go(func() error {
 		var err error
 		costSummary, err = s.transaction.GetPlatformCostSummary(gCtx, db_transaction.GetPlatformCostSummaryParams{
@@ -72,14 +60,14 @@ func (s *service) GetPlatformOverview(ctx context.Context, startDate, endDate ti
 
 	// Tổng hợp dữ liệu
 	resp := &entity.PlatformOverviewResponse{
-		TotalOrders: orderOverview.TotalOrders,
-		TotalShops:  orderOverview.TotalShops,
+		TotalShops: summary.TotalShops,
 	}
 
 	// (Parse từ sql.NullString sang float64. Cần hàm helper 'entity.ParseAmountToFloat64' đã viết)
-	totalGMV, _ := entity.ParseAmountToFloat64(orderOverview.TotalGmv)
-	totalCommission, _ := entity.ParseAmountToFloat64(revenueSummary.TotalCommission)
-	totalShippingRevenue, _ := entity.ParseAmountToFloat64(revenueSummary.TotalShippingRevenue)
+	resp.TotalOrders, _ = entity.ParseInterfaceToInt(summary.TotalOrders)
+	totalGMV, _ := entity.ParseAmountToFloat64(summary.TotalGmv)
+	totalCommission, _ := entity.ParseAmountToFloat64(summary.TotalCommission)
+	totalShippingRevenue, _ := entity.ParseAmountToFloat64(summary.TotalShippingRevenue)
 	totalOrderVoucherCost, _ := entity.ParseAmountToFloat64(costSummary.TotalOrderVoucherCost)
 	totalPromoCost, _ := entity.ParseAmountToFloat64(costSummary.TotalPromotionCost)
 	totalShippingDiscount, _ := entity.ParseAmountToFloat64(costSummary.TotalShippingDiscountCost)
@@ -99,19 +87,36 @@ func (s *service) GetPlatformOverview(ctx context.Context, startDate, endDate ti
 func (s *service) ListPlatformOrders(ctx context.Context, params entity.ListPlatformOrdersParams) (*entity.ListPlatformOrdersResponse, *assets_services.ServiceError) {
 
 	// Bước 1: Lấy danh sách đơn hàng từ order_db
-	shopOrders, err := s.order.ListPlatformOrders(ctx, db_order.ListPlatformOrdersParams{
-		ShopIDFilter: params.ShopID,
-		StatusFilter: db_order.NullShopOrdersStatus{ShopOrdersStatus: db_order.ShopOrdersStatus(params.Status.String), Valid: params.Status.Valid},
-		StartDate:    params.StartDate,
-		EndDate:      params.EndDate,
-		Limit:        params.Limit,
-		Offset:       params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return &entity.ListPlatformOrdersResponse{Orders: []entity.ShopOrderWithPaymentStatus{}}, nil
+	var shopOrders []db_order.ShopOrders
+	var nextCursor, prevCursor *string
+	if params.CursorMode {
+		var serr *assets_services.ServiceError
+		shopOrders, nextCursor, prevCursor, serr = s.listShopOrdersCursor(ctx, db_mysql.ShopOrderKeysetParams{
+			ShopID:    params.ShopID,
+			Status:    shopOrderStatusFilter(params.Status),
+			StartDate: params.StartDate,
+			EndDate:   params.EndDate,
+			Limit:     params.Limit,
+		}, params.Cursor)
+		if serr != nil {
+			return nil, serr
+		}
+	} else {
+		var err error
+		shopOrders, err = s.order.ListPlatformOrders(ctx, db_order.ListPlatformOrdersParams{
+			ShopIDFilter: params.ShopID,
+			StatusFilter: shopOrderStatusFilter(params.Status),
+			StartDate:    params.StartDate,
+			EndDate:      params.EndDate,
+			Limit:        params.Limit,
+			Offset:       params.Offset,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return &entity.ListPlatformOrdersResponse{Orders: []entity.ShopOrderWithPaymentStatus{}}, nil
+			}
+			return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy danh sách đơn hàng: %w", err)}
 		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy danh sách đơn hàng: %w", err)}
 	}
 
 	if len(shopOrders) == 0 {
@@ -138,7 +143,9 @@ func (s *service) ListPlatformOrders(ctx context.Context, params entity.ListPlat
 
 	// Bước 4: Tổng hợp dữ liệu
 	resp := &entity.ListPlatformOrdersResponse{
-		Orders: make([]entity.ShopOrderWithPaymentStatus, len(shopOrders)),
+		Orders:     make([]entity.ShopOrderWithPaymentStatus, len(shopOrders)),
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}
 	for i, so := range shopOrders {
 		// status := "UNKNOWN"
@@ -195,7 +202,58 @@ func (s *service) GetEnrichedPlatformOrder(ctx context.Context, orderID string)
 // === NHÓM 3: Quản lý Tài chính ===
 
 // GetPlatformRevenueTimeseries xử lý API: GET /api/v1/platform/finance/revenue-timeseries
-func (s *service) GetPlatformRevenueTimeseries(ctx context.Context, startDate, endDate time.Time) (*entity.PlatformRevenueTimeseriesResponse, *assets_services.ServiceError) {
+// Trả chuỗi đã lấp đủ các khung, kèm chuỗi so sánh khi có compare.
+func (s *service) GetPlatformRevenueTimeseries(ctx context.Context, params entity.TimeseriesParams) (*entity.PlatformRevenueTimeseriesResponse, *assets_services.ServiceError) {
+	current, compare, serr := newTimeseriesWindow(params)
+	if serr != nil {
+		return nil, serr
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	resp := &entity.PlatformRevenueTimeseriesResponse{
+		Granularity: current.Granularity,
+		Timezone:    current.Loc.String(),
+		Compare:     params.Compare,
+	}
+	var comparison []entity.PlatformRevenueDatapoint
+	var compareTotal entity.PlatformRevenueMetrics
+
+	g.Go(func() error {
+		var err error
+		resp.Data, resp.Total, err = s.platformRevenueSeries(gCtx, current)
+		return err
+	})
+	if compare != nil {
+		g.Go(func() error {
+			var err error
+			comparison, compareTotal, err = s.platformRevenueSeries(gCtx, compare)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy dữ liệu theo thời gian: %w", err)}
+	}
+	if compare == nil {
+		return resp, nil
+	}
+
+	// Hai chuỗi khớp theo vị trí khung, kỳ so sánh có thể lệch một khung (vd: tuần của năm trước)
+	if len(comparison) > len(resp.Data) {
+		comparison = comparison[:len(resp.Data)]
+	}
+	for i := range comparison {
+		resp.Data[i].Delta = platformRevenueDelta(resp.Data[i].PlatformRevenueMetrics, comparison[i].PlatformRevenueMetrics)
+	}
+	resp.Comparison = comparison
+	resp.ComparisonTotal = &compareTotal
+	resp.TotalDelta = platformRevenueDelta(resp.Total, compareTotal)
+	return resp, nil
+}
+
+// platformRevenueSeries lấy và gom GMV, doanh thu sàn, chi phí sàn vào các khung của w
+func (s *service) platformRevenueSeries(ctx context.Context, w *timeseriesWindow) ([]entity.PlatformRevenueDatapoint, entity.PlatformRevenueMetrics, error) {
 
 	// Phân tích: Chúng ta cần 3 dòng dữ liệu theo thời gian:
 	// 1. GMV (Từ order_db)
@@ -204,165 +262,206 @@ func (s *service) GetPlatformRevenueTimeseries(ctx context.Context, startDate, e
 
 	g, gCtx := errgroup.WithContext(ctx)
 
-	var gmvData []db_order.GetPlatformGMVTimeSeriesRow // (Sử dụng lại struct của Shop)
+	var gmvData []db_order.GetPlatformGMVTimeSeriesRow
 	var revenueData []db_transaction.GetPlatformRevenueTimeSeriesRow
+	var rollupData []db_interact.ListRollupPlatformDailyRow
 	var costData []db_transaction.GetPlatformCostTimeSeriesRow
 
-	// Tác vụ 1: Lấy GMV
-	g.Go(func() error {
-		var err error
-		gmvData, err = s.order.GetPlatformGMVTimeSeries(gCtx, db_order.GetPlatformGMVTimeSeriesParams{
-			StartDate: sql.NullTime{Time: startDate, Valid: true},
-			EndDate:   sql.NullTime{Time: endDate, Valid: true},
+	if useDailyRollup(w) {
+		// Tác vụ 1+2: GMV và Doanh thu Sàn lấy từ bảng tổng hợp theo ngày
+		g.Go(func() error {
+			var err error
+			rollupData, err = s.interact.ListRollupPlatformDaily(gCtx, db_interact.ListRollupPlatformDailyParams{
+				FromDate: w.From,
+				ToDate:   w.To,
+			})
+			return err
 		})
-		if err != nil {
+	} else {
+		// Tác vụ 1: Lấy GMV
+		g.Go(func() error {
+			var err error
+			gmvData, err = s.order.GetPlatformGMVTimeSeries(gCtx, db_order.GetPlatformGMVTimeSeriesParams{
+				StartDate: sql.NullTime{Time: w.From, Valid: true},
+				EndDate:   sql.NullTime{Time: w.To, Valid: true},
+			})
 			return err
-		}
-		// Tạm thời bỏ qua Tác vụ 1 vì sqlc query chưa tồn tại
-		return nil
-	})
-
-	// Tác vụ 2: Lấy Doanh thu Sàn
-	g.Go(func() error {
-		var err error
-		revenueData, err = s.transaction.GetPlatformRevenueTimeSeries(gCtx, db_transaction.GetPlatformRevenueTimeSeriesParams{
-			FromSettledAt: sql.NullTime{Time: startDate, Valid: true},
-			ToSettledAt:   sql.NullTime{Time: endDate, Valid: true},
 		})
-		if err != nil {
+
+		// Tác vụ 2: Lấy Doanh thu Sàn
+		g.Go(func() error {
+			var err error
+			revenueData, err = s.transaction.GetPlatformRevenueTimeSeries(gCtx, db_transaction.GetPlatformRevenueTimeSeriesParams{
+				FromSettledAt: sql.NullTime{Time: w.From, Valid: true},
+				ToSettledAt:   sql.NullTime{Time: w.To, Valid: true},
+			})
 			return err
-		}
-		return nil
-	})
+		})
+	}
 
 	// Tác vụ 3: Lấy Chi phí Sàn
 	g.Go(func() error {
 		var err error
 		costData, err = s.transaction.GetPlatformCostTimeSeries(gCtx, db_transaction.GetPlatformCostTimeSeriesParams{
-			FromCreatedAt: startDate,
-			ToCreatedAt:   endDate,
+			FromCreatedAt: w.From,
+			ToCreatedAt:   w.To,
 		})
-		if err != nil {
-			return err
-		}
-		return nil
+		return err
 	})
 
+	var total entity.PlatformRevenueMetrics
 	if err := g.Wait(); err != nil {
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy dữ liệu theo thời gian: %w", err)}
+		return nil, total, err
 	}
 
-	// Tổng hợp 3 luồng dữ liệu (Logic Join trong Go)
-	dataMap := make(map[string]*entity.PlatformRevenueDatapoint)
+	// Tổng hợp 3 luồng dữ liệu vào các khung đã lấp sẵn (Logic Join trong Go)
+	data := make([]entity.PlatformRevenueDatapoint, len(w.Buckets))
+	for i, b := range w.Buckets {
+		data[i] = entity.PlatformRevenueDatapoint{Date: w.label(i), BucketStart: b}
+	}
 
-	// (Nếu gmvData có dữ liệu)
 	for _, row := range gmvData {
-		dateStr := row.ReportDate.Format("2006-01-02")
-		gmv, _ := entity.ParseAmountToFloat64(row.Gmv)
-		dataMap[dateStr] = &entity.PlatformRevenueDatapoint{
-			Date:     dateStr,
-			TotalGMV: gmv,
+		if i := w.index(row.SlotStart); i >= 0 {
+			gmv, _ := entity.ParseAmountToFloat64(row.Gmv)
+			data[i].TotalGMV += gmv
+			data[i].Orders += row.OrderCount
 		}
 	}
 
 	for _, row := range revenueData {
-		dateStr := row.ReportDate.Format("2006-01-02")
-		revenue, _ := entity.ParseAmountToFloat64(row.PlatformRevenue)
-		if dp, ok := dataMap[dateStr]; ok {
-			dp.PlatformRevenue = revenue
-		} else {
-			dataMap[dateStr] = &entity.PlatformRevenueDatapoint{Date: dateStr, PlatformRevenue: revenue}
+		if i := w.index(row.SlotStart); i >= 0 {
+			revenue, _ := entity.ParseAmountToFloat64(row.PlatformRevenue)
+			data[i].PlatformRevenue += revenue
 		}
 	}
 
-	for _, row := range costData {
-		dateStr := row.ReportDate.Format("2006-01-02")
-		cost, _ := entity.ParseAmountToFloat64(row.TotalCost)
-		if dp, ok := dataMap[dateStr]; ok {
-			dp.PlatformCost = cost
-		} else {
-			dataMap[dateStr] = &entity.PlatformRevenueDatapoint{Date: dateStr, PlatformCost: cost}
+	for _, row := range rollupData {
+		if i := w.index(row.StatDate); i >= 0 {
+			gmv, _ := entity.ParseAmountToFloat64(row.CompletedGmv)
+			orders, _ := entity.ParseInterfaceToInt(row.CompletedOrders)
+			revenue, _ := entity.ParseAmountToFloat64(row.CommissionRevenue)
+			data[i].TotalGMV += gmv
+			data[i].Orders += orders
+			data[i].PlatformRevenue += revenue
 		}
 	}
 
-	// Tính lợi nhuận và chuyển sang slice
-	resp := &entity.PlatformRevenueTimeseriesResponse{
-		Data: make([]entity.PlatformRevenueDatapoint, 0, len(dataMap)),
+	for _, row := range costData {
+		if i := w.index(row.SlotStart); i >= 0 {
+			cost, _ := entity.ParseAmountToFloat64(row.TotalCost)
+			data[i].PlatformCost += cost
+		}
 	}
-	for _, dp := range dataMap {
+
+	// Tính AOV, lợi nhuận và tổng cả kỳ
+	for i := range data {
+		dp := &data[i]
+		dp.AOV = averageOrderValue(dp.TotalGMV, dp.Orders)
 		dp.PlatformProfit = dp.PlatformRevenue - dp.PlatformCost
-		resp.Data = append(resp.Data, *dp)
+		total.TotalGMV += dp.TotalGMV
+		total.Orders += dp.Orders
+		total.PlatformRevenue += dp.PlatformRevenue
+		total.PlatformCost += dp.PlatformCost
 	}
-	// (Nên sắp xếp resp.Data theo ngày)
-
-	return resp, nil
+	total.AOV = averageOrderValue(total.TotalGMV, total.Orders)
+	total.PlatformProfit = total.PlatformRevenue - total.PlatformCost
+	return data, total, nil
 }
 
 // ListPlatformTransactions (Wrapper)
-func (s *service) ListPlatformTransactions(ctx context.Context, params entity.ListPlatformTransactionsParams) ([]db_transaction.Transactions, *assets_services.ServiceError) {
-	txns, err := s.transaction.ListPlatformTransactions(ctx, db_transaction.ListPlatformTransactionsParams{
-		TypeFilter:   db_transaction.NullTransactionsType{Valid: params.Type.Valid, TransactionsType: db_transaction.TransactionsType(params.Type.String)},
-		StatusFilter: db_transaction.NullTransactionsStatus{Valid: params.Status.Valid, TransactionsStatus: db_transaction.TransactionsStatus(params.Status.String)},
+func (s *service) ListPlatformTransactions(ctx context.Context, params entity.ListPlatformTransactionsParams) (*entity.ListPlatformTransactionsResponse, *assets_services.ServiceError) {
+	txns, next, prev, serr := keysetList(s, transactionCursorSort, params.Cursor, params.Limit,
+		func(kp db_mysql.KeysetParams) ([]db_transaction.Transactions, error) {
+			txns, err := s.transaction.ListTransactionsKeyset(ctx, platformTransactionsKeyset(params, kp))
+			if err != nil {
+				return nil, fmt.Errorf("lỗi khi lấy thông tin giao dịch: %w", err)
+			}
+			return txns, nil
+		}, transactionKey)
+	if serr != nil {
+		return nil, serr
+	}
+	return &entity.ListPlatformTransactionsResponse{Transactions: emptyIfNil(txns), NextCursor: next, PrevCursor: prev}, nil
+}
+
+func platformTransactionsKeyset(params entity.ListPlatformTransactionsParams, kp db_mysql.KeysetParams) db_mysql.TransactionKeysetParams {
+	return db_mysql.TransactionKeysetParams{
+		Type:         db_transaction.NullTransactionsType{Valid: params.Type.Valid, TransactionsType: db_transaction.TransactionsType(params.Type.String)},
+		Status:       db_transaction.NullTransactionsStatus{Valid: params.Status.Valid, TransactionsStatus: db_transaction.TransactionsStatus(params.Status.String)},
 		StartDate:    params.StartDate,
 		EndDate:      params.EndDate,
-		Limit:        params.Limit,
-		Offset:       params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []db_transaction.Transactions{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin giao dịch: %w", err)}
+		KeysetParams: kp,
 	}
-	return txns, nil
+}
+
+func transactionKey(t db_transaction.Transactions) (sql.NullTime, string) {
+	return sql.NullTime{Time: t.CreatedAt, Valid: true}, t.ID
 }
 
 // ListPlatformSettlements (Wrapper)
-func (s *service) ListPlatformSettlements(ctx context.Context, params entity.ListPlatformSettlementsParams) ([]db_transaction.ShopOrderSettlements, *assets_services.ServiceError) {
-	settlements, err := s.transaction.ListPlatformSettlements(ctx, db_transaction.ListPlatformSettlementsParams{
-		StatusFilter: db_transaction.NullShopOrderSettlementsStatus{Valid: params.Status.Valid, ShopOrderSettlementsStatus: db_transaction.ShopOrderSettlementsStatus(params.Status.String)},
-		Limit:        params.Limit,
-		Offset:       params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []db_transaction.ShopOrderSettlements{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin thanh toán shop: %w", err)}
+func (s *service) ListPlatformSettlements(ctx context.Context, params entity.ListPlatformSettlementsParams) (*entity.ListPlatformSettlementsResponse, *assets_services.ServiceError) {
+	settlements, next, prev, serr := keysetList(s, settlementCursorSort, params.Cursor, params.Limit,
+		func(kp db_mysql.KeysetParams) ([]db_transaction.ShopOrderSettlements, error) {
+			settlements, err := s.transaction.ListSettlementsKeyset(ctx, platformSettlementsKeyset(params, kp))
+			if err != nil {
+				return nil, fmt.Errorf("lỗi khi lấy thông tin thanh toán shop: %w", err)
+			}
+			return settlements, nil
+		}, settlementKey)
+	if serr != nil {
+		return nil, serr
+	}
+	return &entity.ListPlatformSettlementsResponse{Settlements: emptyIfNil(settlements), NextCursor: next, PrevCursor: prev}, nil
+}
+
+func platformSettlementsKeyset(params entity.ListPlatformSettlementsParams, kp db_mysql.KeysetParams) db_mysql.SettlementKeysetParams {
+	return db_mysql.SettlementKeysetParams{
+		Status:       db_transaction.NullShopOrderSettlementsStatus{Valid: params.Status.Valid, ShopOrderSettlementsStatus: db_transaction.ShopOrderSettlementsStatus(params.Status.String)},
+		StartDate:    params.StartDate,
+		EndDate:      params.EndDate,
+		KeysetParams: kp,
 	}
-	return settlements, nil
+}
+
+func settlementKey(st db_transaction.ShopOrderSettlements) (sql.NullTime, string) {
+	return st.OrderCompletedAt, st.ID
 }
 
 // ListPlatformLedgers (Wrapper)
-func (s *service) ListPlatformLedgers(ctx context.Context, params entity.ListPlatformLedgersParams) ([]db_transaction.AccountLedgers, *assets_services.ServiceError) {
-	ledgers, err := s.transaction.ListPlatformLedgers(ctx, db_transaction.ListPlatformLedgersParams{
-		OwnerTypeFilter: db_transaction.NullAccountLedgersOwnerType{Valid: params.OwnerType.Valid, AccountLedgersOwnerType: db_transaction.AccountLedgersOwnerType(params.OwnerType.String)},
-		Limit:           params.Limit,
-		Offset:          params.Offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []db_transaction.AccountLedgers{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin sổ cái: %w", err)}
+func (s *service) ListPlatformLedgers(ctx context.Context, params entity.ListPlatformLedgersParams) (*entity.ListPlatformLedgersResponse, *assets_services.ServiceError) {
+	ledgers, next, prev, serr := keysetList(s, ledgerCursorSort, params.Cursor, params.Limit,
+		func(kp db_mysql.KeysetParams) ([]db_transaction.AccountLedgers, error) {
+			ledgers, err := s.transaction.ListLedgersKeyset(ctx, db_mysql.LedgerKeysetParams{
+				OwnerType:    db_transaction.NullAccountLedgersOwnerType{Valid: params.OwnerType.Valid, AccountLedgersOwnerType: db_transaction.AccountLedgersOwnerType(params.OwnerType.String)},
+				KeysetParams: kp,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("lỗi khi lấy thông tin sổ cái: %w", err)
+			}
+			return ledgers, nil
+		}, func(l db_transaction.AccountLedgers) (sql.NullTime, string) {
+			return sql.NullTime{Time: l.CreatedAt, Valid: true}, l.ID
+		})
+	if serr != nil {
+		return nil, serr
 	}
-	return ledgers, nil
+	return &entity.ListPlatformLedgersResponse{Ledgers: emptyIfNil(ledgers), NextCursor: next, PrevCursor: prev}, nil
 }
 
 // ListLedgerEntries (Wrapper)
-func (s *service) ListLedgerEntries(ctx context.Context, ledgerID string, limit, offset int32) ([]db_transaction.LedgerEntries, *assets_services.ServiceError) {
-	entries, err := s.transaction.ListLedgerEntriesByLedgerID(ctx, db_transaction.ListLedgerEntriesByLedgerIDParams{
-		LedgerID: ledgerID,
-		Limit:    limit,
-		Offset:   offset,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []db_transaction.LedgerEntries{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy các mục sổ cái: %w", err)}
+func (s *service) ListLedgerEntries(ctx context.Context, ledgerID string, limit int32, cursor string) (*entity.ListLedgerEntriesResponse, *assets_services.ServiceError) {
+	entries, next, prev, serr := keysetList(s, ledgerEntryCursorSort, cursor, limit,
+		func(kp db_mysql.KeysetParams) ([]db_transaction.LedgerEntries, error) {
+			entries, err := s.transaction.ListLedgerEntriesKeyset(ctx, db_mysql.LedgerEntryKeysetParams{LedgerID: ledgerID, KeysetParams: kp})
+			if err != nil {
+				return nil, fmt.Errorf("lỗi khi lấy các mục sổ cái: %w", err)
+			}
+			return entries, nil
+		}, ledgerEntryKey)
+	if serr != nil {
+		return nil, serr
 	}
-	return entries, nil
+	return &entity.ListLedgerEntriesResponse{Entries: emptyIfNil(entries), NextCursor: next, PrevCursor: prev}, nil
 }
 
 // === NHÓM 4: Phân tích Voucher ===
@@ -392,16 +491,19 @@ func (s *service) GetPlatformVoucherPerformance(ctx context.Context, startDate,
 	var usageStats db_order.GetPlatformVoucherPerformanceRow
 	var costStats db_transaction.GetPlatformCostSummaryRow
 
-	// Tác vụ 1: Lấy từ voucher_db (lịch sử sử dụng)
+	// Tác vụ 1: Lấy lượt dùng voucher Sàn (từ rollup_voucher_daily)
 	g.Go(func() error {
-		var err error
-		usageStats, err = s.order.GetPlatformVoucherPerformance(gCtx, db_order.GetPlatformVoucherPerformanceParams{
-			FromUsedAt: startDate,
-			ToUsedAt:   endDate,
+		from, to := rollupDateRange(startDate, endDate)
+		usage, err := s.interact.GetRollupVoucherUsage(gCtx, db_interact.GetRollupVoucherUsageParams{
+			OwnerType: string(db_order.VouchersOwnerTypePLATFORM),
+			FromDate:  from,
+			ToDate:    to,
 		})
 		if err != nil && err != sql.ErrNoRows {
 			return &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin hiệu suất voucher: %w", err)}
 		}
+		usageStats.TotalUsageCount, _ = entity.ParseInterfaceToInt(usage.TotalUsageCount)
+		usageStats.TotalDiscountValue = usage.TotalDiscountValue
 		return nil
 	})
 
@@ -451,16 +553,10 @@ func (s *service) ListPlatformShops(ctx context.Context, params entity.ListPlatf
 
 	// (Chúng ta cần một hàm sqlc mới `GetAllShopsStats` giống `GetPlatformTopShopsByGMV`
 	// nhưng có phân trang và không chỉ lấy TOP)
-	// Tạm thời dùng:
-	shopStats, err := s.order.GetPlatformTopShopsByGMV(ctx, db_order.GetPlatformTopShopsByGMVParams{
-		// (Bỏ qua date range để lấy TẤT CẢ)
-		Limit: params.Limit,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return []entity.PlatformShopRow{}, nil
-		}
-		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin shop: %w", err)}
+	// Tạm thời dùng bảng tổng hợp, bỏ qua date range để lấy TẤT CẢ:
+	shopStats, serr := s.rollupTopShops(ctx, sql.NullTime{}, sql.NullTime{}, params.Limit)
+	if serr != nil {
+		return nil, serr
 	}
 
 	resp := make([]entity.PlatformShopRow, len(shopStats))
@@ -514,10 +610,16 @@ func (s *service) GetPlatformShopDetail(ctx context.Context, shopID string, star
 
 // GetPlatformRankingShops (Wrapper)
 func (s *service) GetPlatformRankingShops(ctx context.Context, params entity.PlatformRankingParams) ([]db_order.GetPlatformTopShopsByGMVRow, *assets_services.ServiceError) {
-	shops, err := s.order.GetPlatformTopShopsByGMV(ctx, db_order.GetPlatformTopShopsByGMVParams{
-		StartDate: sql.NullTime{Time: params.StartDate, Valid: true},
-		EndDate:   sql.NullTime{Time: params.EndDate, Valid: true},
-		Limit:     params.Limit,
+	from, to := rollupDateRange(params.StartDate, params.EndDate)
+	return s.rollupTopShops(ctx, sql.NullTime{Time: from, Valid: true}, sql.NullTime{Time: to, Valid: true}, params.Limit)
+}
+
+// rollupTopShops xếp hạng shop theo GMV hoàn thành từ rollup_shop_daily, giữ nguyên DTO cũ của order_db
+func (s *service) rollupTopShops(ctx context.Context, from, to sql.NullTime, limit int32) ([]db_order.GetPlatformTopShopsByGMVRow, *assets_services.ServiceError) {
+	rows, err := s.interact.ListRollupTopShops(ctx, db_interact.ListRollupTopShopsParams{
+		FromDate: from,
+		ToDate:   to,
+		Limit:    limit,
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -525,15 +627,23 @@ func (s *service) GetPlatformRankingShops(ctx context.Context, params entity.Pla
 		}
 		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy thông tin shop: %w", err)}
 	}
+
+	shops := make([]db_order.GetPlatformTopShopsByGMVRow, len(rows))
+	for i, row := range rows {
+		shops[i] = db_order.GetPlatformTopShopsByGMVRow{ShopID: row.ShopID}
+		shops[i].TotalGmv, _ = entity.ParseAmountToFloat64(row.TotalGmv)
+		shops[i].TotalOrders, _ = entity.ParseInterfaceToInt(row.TotalOrders)
+	}
 	return shops, nil
 }
 
 // GetPlatformRankingProducts (Wrapper)
 func (s *service) GetPlatformRankingProducts(ctx context.Context, params entity.PlatformRankingParams) ([]db_order.GetPlatformTopProductsByQuantityRow, *assets_services.ServiceError) {
-	products, err := s.order.GetPlatformTopProductsByQuantity(ctx, db_order.GetPlatformTopProductsByQuantityParams{
-		StartDate: sql.NullTime{Time: params.StartDate, Valid: true},
-		EndDate:   sql.NullTime{Time: params.EndDate, Valid: true},
-		Limit:     params.Limit,
+	from, to := rollupDateRange(params.StartDate, params.EndDate)
+	rows, err := s.interact.ListRollupPlatformTopProducts(ctx, db_interact.ListRollupPlatformTopProductsParams{
+		FromDate: from,
+		ToDate:   to,
+		Limit:    params.Limit,
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -541,6 +651,15 @@ func (s *service) GetPlatformRankingProducts(ctx context.Context, params entity.
 		}
 		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy sản phẩm xếp hạng theo số lượng: %w", err)}
 	}
+
+	products := make([]db_order.GetPlatformTopProductsByQuantityRow, len(rows))
+	for i, row := range rows {
+		products[i] = db_order.GetPlatformTopProductsByQuantityRow{
+			ProductID:           row.ProductID,
+			ProductNameSnapshot: row.ProductName,
+		}
+		products[i].TotalQuantity, _ = entity.ParseInterfaceToInt(row.TotalQuantity)
+	}
 	return products, nil
 }
 
@@ -560,19 +679,24 @@ func (s *service) GetPlatformRankingUsers(ctx context.Context, params entity.Pla
 	return users, nil
 }
 
-// GetPlatformRankingCategories
+// GetPlatformRankingCategories xếp hạng danh mục theo doanh thu từ rollup_category_daily
 func (s *service) GetPlatformRankingCategories(ctx context.Context, params entity.PlatformRankingParams) (*entity.PlatformRankingCategoriesResponse, *assets_services.ServiceError) {
-	// Ghi chú Kiến trúc:
-	// Như đã phân tích, để thực hiện API này, chúng ta cần:
-	// 1. Lấy (order_items) từ `order_db`.
-	// 2. Lấy (product_id, category_id) từ `product_db`.
-	//
-	// Cấu trúc `service` của bạn KHÔNG có kết nối đến `product_db`.
-	// Vì vậy, API này KHÔNG THỂ thực hiện được với kiến trúc hiện tại.
-
-	log.Println("Architectural Warning: GetPlatformRankingCategories requires a database connection to 'product_service' which is not provided in the current service struct.")
-
-	return &entity.PlatformRankingCategoriesResponse{
-		Message: "API Not Implemented: This feature requires access to Product Database (product_db) to map products to categories. Please update service architecture.",
-	}, nil
+	from, to := rollupDateRange(params.StartDate, params.EndDate)
+	rows, err := s.interact.ListRollupTopCategories(ctx, db_interact.ListRollupTopCategoriesParams{
+		FromDate: from,
+		ToDate:   to,
+		Limit:    params.Limit,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, &assets_services.ServiceError{Code: http.StatusBadRequest, Err: fmt.Errorf("lỗi khi lấy danh mục xếp hạng: %w", err)}
+	}
+
+	resp := &entity.PlatformRankingCategoriesResponse{Data: make([]entity.CategoryRankingRow, len(rows))}
+	for i, row := range rows {
+		resp.Data[i] = entity.CategoryRankingRow{CategoryID: row.CategoryID}
+		resp.Data[i].TotalQuantity, _ = entity.ParseInterfaceToInt(row.TotalQuantity)
+		resp.Data[i].TotalRevenue, _ = entity.ParseAmountToFloat64(row.TotalRevenue)
+		resp.Data[i].TotalOrders, _ = entity.ParseInterfaceToInt(row.TotalOrders)
+	}
+	return resp, nil
 }
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"golang.org/x/sync/errgroup"
+)
+
+// số ngày bẩn tối đa được tính lại trong 1 lần chạy job
+const rollupRefreshBatch = 31
+
+// rollupLoc nạp 1 lần: LoadLocation mỗi lần gọi trả về con trỏ mới,
+// khi đó các mốc ngày dùng làm key của map sẽ không trùng nhau dù cùng thời điểm
+var rollupLoc = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation(defaultTimeseriesTimezone)
+	if err != nil {
+		return time.FixedZone("ICT", 7*60*60)
+	}
+	return loc
+})
+
+// rollupLocation múi giờ dùng để chia ngày thống kê của bảng tổng hợp
+func rollupLocation() *time.Location {
+	return rollupLoc()
+}
+
+// rollupDay trả về 0h của ngày (giờ Việt Nam) chứa t
+func rollupDay(t time.Time) time.Time {
+	loc := rollupLocation()
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// rollupDateRange đổi khoảng ngày [startDate, endDate] của API (lấy trọn ngày cuối) thành [from, to) của bảng tổng hợp
+func rollupDateRange(startDate, endDate time.Time) (time.Time, time.Time) {
+	loc := rollupLocation()
+	if startDate.IsZero() {
+		startDate = time.Date(2000, 1, 1, 0, 0, 0, 0, loc)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now().In(loc)
+	}
+	from := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	return from, to
+}
+
+// rollupAmount định dạng số tiền để ghi vào cột DECIMAL(18,2)
+func rollupAmount(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// MarkRollupDirty đánh dấu ngày tạo đơn, ngày hoàn thành và ngày xảy ra sự kiện để job tính lại
+func (s *service) MarkRollupDirty(ctx context.Context, event entity.RollupEvent) error {
+	var shopOrders []db_order.ShopOrders
+	var err error
+	if len(event.ShopOrderIDs) > 0 {
+		shopOrders, err = s.order.GetShopOrdersByIDs(ctx, event.ShopOrderIDs)
+	} else if event.OrderID != "" {
+		shopOrders, err = s.order.GetShopOrdersByOrderID(ctx, event.OrderID)
+	}
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy đơn hàng shop: %w", err)
+	}
+
+	days := map[time.Time]bool{}
+	if !event.OccurredAt.IsZero() {
+		days[rollupDay(event.OccurredAt)] = true
+	}
+	for _, shopOrder := range shopOrders {
+		days[rollupDay(shopOrder.CreatedAt)] = true
+		if shopOrder.CompletedAt.Valid {
+			days[rollupDay(shopOrder.CompletedAt.Time)] = true
+		}
+	}
+	for day := range days {
+		if err := s.interact.MarkRollupDirtyDay(ctx, day); err != nil {
+			return fmt.Errorf("lỗi khi đánh dấu ngày %s: %w", day.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
+
+// MarkSettledRollupsDirty đánh dấu các ngày có đối soát chuyển sang SETTLED trong [from, to) theo settled_at.
+// payment service chỉ gửi settlement.updated khi tạo đối soát nên job định kỳ quét settled_at để doanh thu thuần không bị sót.
+func (s *service) MarkSettledRollupsDirty(ctx context.Context, from, to time.Time) error {
+	settlements, err := s.transaction.ListSettledSettlementsInRange(ctx, db_transaction.ListSettledSettlementsInRangeParams{
+		FromSettledAt: sql.NullTime{Time: from, Valid: true},
+		ToSettledAt:   sql.NullTime{Time: to, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy đối soát đã chuyển tiền: %w", err)
+	}
+	days := map[time.Time]bool{}
+	for _, settlement := range settlements {
+		if settlement.SettledAt.Valid {
+			days[rollupDay(settlement.SettledAt.Time)] = true
+		}
+	}
+	for day := range days {
+		if err := s.interact.MarkRollupDirtyDay(ctx, day); err != nil {
+			return fmt.Errorf("lỗi khi đánh dấu ngày %s: %w", day.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
+
+// RefreshDirtyRollups tính lại các ngày đã bị đánh dấu, cũ nhất trước
+func (s *service) RefreshDirtyRollups(ctx context.Context) error {
+	dirtyDays, err := s.interact.ListRollupDirtyDays(ctx, rollupRefreshBatch)
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy danh sách ngày cần tính lại: %w", err)
+	}
+	for _, dirty := range dirtyDays {
+		if err := s.recomputeRollupDay(ctx, rollupDay(dirty.StatDate)); err != nil {
+			return fmt.Errorf("lỗi khi tính lại ngày %s: %w", dirty.StatDate.Format("2006-01-02"), err)
+		}
+		// ngày bị đánh dấu lại trong lúc tính (marked_at mới hơn) sẽ được giữ cho lần chạy sau
+		err := s.interact.DeleteRollupDirtyDay(ctx, db_interact.DeleteRollupDirtyDayParams{
+			StatDate: dirty.StatDate,
+			MarkedAt: dirty.MarkedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi xóa đánh dấu ngày %s: %w", dirty.StatDate.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
+
+// RebuildRollups tính lại từng ngày trong [from, to]. from bỏ trống thì bắt đầu từ đơn hàng đầu tiên, to bỏ trống thì tới hôm nay
+func (s *service) RebuildRollups(ctx context.Context, from, to time.Time) error {
+	if from.IsZero() {
+		first, err := s.order.GetFirstShopOrderCreatedAt(ctx)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy đơn hàng đầu tiên: %w", err)
+		}
+		firstCreatedAt, ok := first.(time.Time)
+		if !ok {
+			// chưa có đơn hàng nào
+			return nil
+		}
+		from = firstCreatedAt
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	for day, last := rollupDay(from), rollupDay(to); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if err := s.recomputeRollupDay(ctx, day); err != nil {
+			return fmt.Errorf("lỗi khi tính lại ngày %s: %w", day.Format("2006-01-02"), err)
+		}
+		log.Printf("Rollup %s rebuilt", day.Format("2006-01-02"))
+	}
+	return nil
+}
+
+type rollupShopAcc struct {
+	placedOrders      int64
+	placedGmv         float64
+	processingOrders  int64
+	completedOrders   int64
+	completedGmv      float64
+	netRevenue        float64
+	commissionRevenue float64
+	shippingRevenue   float64
+}
+
+type rollupProductKey struct {
+	productID string
+	skuID     string
+	shopID    string
+}
+
+type rollupSalesAcc struct {
+	params     db_interact.CreateRollupProductDailyParams
+	quantity   int64
+	revenue    float64
+	orderCount int64
+}
+
+// recomputeRollupDay đọc lại số liệu gốc của 1 ngày rồi ghi đè toàn bộ dòng tổng hợp của ngày đó
+func (s *service) recomputeRollupDay(ctx context.Context, day time.Time) error {
+	next := day.AddDate(0, 0, 1)
+	from := sql.NullTime{Time: day, Valid: true}
+	to := sql.NullTime{Time: next, Valid: true}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	var placed []db_order.GetShopPlacedStatsInRangeRow
+	var completed []db_order.GetShopCompletedStatsInRangeRow
+	var products []db_order.GetProductSalesInRangeRow
+	var categories []db_order.GetCategorySalesInRangeRow
+	var vouchers []db_order.GetVoucherUsageInRangeRow
+	var settlements []db_transaction.ShopOrderSettlements
+
+	// Tác vụ 1: Đơn đặt trong ngày theo shop
+	g.Go(func() error {
+		var err error
+		placed, err = s.order.GetShopPlacedStatsInRange(gCtx, db_order.GetShopPlacedStatsInRangeParams{FromCreatedAt: day, ToCreatedAt: next})
+		return err
+	})
+	// Tác vụ 2: Đơn hoàn thành trong ngày theo shop
+	g.Go(func() error {
+		var err error
+		completed, err = s.order.GetShopCompletedStatsInRange(gCtx, db_order.GetShopCompletedStatsInRangeParams{FromCompletedAt: from, ToCompletedAt: to})
+		return err
+	})
+	// Tác vụ 3: Sản phẩm bán được
+	g.Go(func() error {
+		var err error
+		products, err = s.order.GetProductSalesInRange(gCtx, db_order.GetProductSalesInRangeParams{FromCompletedAt: from, ToCompletedAt: to})
+		return err
+	})
+	// Tác vụ 4: Danh mục bán được
+	g.Go(func() error {
+		var err error
+		categories, err = s.order.GetCategorySalesInRange(gCtx, db_order.GetCategorySalesInRangeParams{FromCompletedAt: from, ToCompletedAt: to})
+		return err
+	})
+	// Tác vụ 5: Voucher được dùng
+	g.Go(func() error {
+		var err error
+		vouchers, err = s.order.GetVoucherUsageInRange(gCtx, db_order.GetVoucherUsageInRangeParams{FromUsedAt: day, ToUsedAt: next})
+		return err
+	})
+	// Tác vụ 6: Đối soát đã chuyển tiền cho shop
+	g.Go(func() error {
+		var err error
+		settlements, err = s.transaction.ListSettledSettlementsInRange(gCtx, db_transaction.ListSettledSettlementsInRangeParams{FromSettledAt: from, ToSettledAt: to})
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	shops := map[string]*rollupShopAcc{}
+	shopAcc := func(shopID string) *rollupShopAcc {
+		acc, ok := shops[shopID]
+		if !ok {
+			acc = &rollupShopAcc{}
+			shops[shopID] = acc
+		}
+		return acc
+	}
+	for _, row := range placed {
+		acc := shopAcc(row.ShopID)
+		acc.placedOrders = row.PlacedOrders
+		acc.placedGmv, _ = entity.ParseAmountToFloat64(row.PlacedGmv)
+		acc.processingOrders = row.ProcessingOrders
+	}
+	for _, row := range completed {
+		acc := shopAcc(row.ShopID)
+		acc.completedOrders = row.CompletedOrders
+		acc.completedGmv, _ = entity.ParseAmountToFloat64(row.CompletedGmv)
+	}
+
+	// bảng đối soát không lưu shop_id, lấy qua shop_orders
+	if len(settlements) > 0 {
+		shopOrderIDs := make([]string, 0, len(settlements))
+		for _, settlement := range settlements {
+			shopOrderIDs = append(shopOrderIDs, settlement.ShopOrderID)
+		}
+		shopOrders, err := s.order.GetShopOrdersByIDs(ctx, shopOrderIDs)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy shop của đối soát: %w", err)
+		}
+		shopByOrder := make(map[string]string, len(shopOrders))
+		for _, shopOrder := range shopOrders {
+			shopByOrder[shopOrder.ID] = shopOrder.ShopID
+		}
+		for _, settlement := range settlements {
+			shopID, ok := shopByOrder[settlement.ShopOrderID]
+			if !ok {
+				continue
+			}
+			acc := shopAcc(shopID)
+			net, _ := entity.ParseAmountToFloat64(settlement.NetSettledAmount)
+			commission, _ := entity.ParseAmountToFloat64(settlement.CommissionFee)
+			shipping, _ := entity.ParseAmountToFloat64(settlement.ShippingFee)
+			acc.netRevenue += net
+			acc.commissionRevenue += commission
+			acc.shippingRevenue += shipping
+		}
+	}
+
+	// 1 sản phẩm có thể ra nhiều dòng khi tên/thuộc tính snapshot khác nhau giữa các đơn
+	productSales := map[rollupProductKey]*rollupSalesAcc{}
+	for _, row := range products {
+		key := rollupProductKey{productID: row.ProductID, skuID: row.SkuID, shopID: row.ShopID}
+		acc, ok := productSales[key]
+		if !ok {
+			acc = &rollupSalesAcc{params: db_interact.CreateRollupProductDailyParams{
+				StatDate:      day,
+				ProductID:     row.ProductID,
+				SkuID:         row.SkuID,
+				ShopID:        row.ShopID,
+				ProductName:   row.ProductNameSnapshot,
+				SkuAttributes: row.SkuAttributesSnapshot,
+			}}
+			productSales[key] = acc
+		}
+		if acc.params.CategoryID == "" && row.CategoryID.Valid {
+			acc.params.CategoryID = row.CategoryID.String
+		}
+		quantity, _ := entity.ParseInterfaceToInt(row.Quantity)
+		revenue, _ := entity.ParseAmountToFloat64(row.Revenue)
+		acc.quantity += quantity
+		acc.revenue += revenue
+		acc.orderCount += row.OrderCount
+	}
+
+	// đơn hàng cũ chưa lưu danh mục được gom vào category_id rỗng
+	categorySales := map[string]*rollupSalesAcc{}
+	for _, row := range categories {
+		acc, ok := categorySales[row.CategoryID.String]
+		if !ok {
+			acc = &rollupSalesAcc{}
+			categorySales[row.CategoryID.String] = acc
+		}
+		quantity, _ := entity.ParseInterfaceToInt(row.Quantity)
+		revenue, _ := entity.ParseAmountToFloat64(row.Revenue)
+		acc.quantity += quantity
+		acc.revenue += revenue
+		acc.orderCount += row.OrderCount
+	}
+
+	return s.interact.ExecTS(ctx, func(tx db_interact.Querier) error {
+		if err := tx.DeleteRollupShopDailyByDate(ctx, day); err != nil {
+			return err
+		}
+		if err := tx.DeleteRollupProductDailyByDate(ctx, day); err != nil {
+			return err
+		}
+		if err := tx.DeleteRollupCategoryDailyByDate(ctx, day); err != nil {
+			return err
+		}
+		if err := tx.DeleteRollupVoucherDailyByDate(ctx, day); err != nil {
+			return err
+		}
+
+		for shopID, acc := range shops {
+			err := tx.CreateRollupShopDaily(ctx, db_interact.CreateRollupShopDailyParams{
+				StatDate:          day,
+				ShopID:            shopID,
+				PlacedOrders:      int32(acc.placedOrders),
+				PlacedGmv:         rollupAmount(acc.placedGmv),
+				ProcessingOrders:  int32(acc.processingOrders),
+				CompletedOrders:   int32(acc.completedOrders),
+				CompletedGmv:      rollupAmount(acc.completedGmv),
+				NetRevenue:        rollupAmount(acc.netRevenue),
+				CommissionRevenue: rollupAmount(acc.commissionRevenue),
+				ShippingRevenue:   rollupAmount(acc.shippingRevenue),
+			})
+			if err != nil {
+				return fmt.Errorf("lỗi khi ghi rollup shop %s: %w", shopID, err)
+			}
+		}
+		for _, acc := range productSales {
+			params := acc.params
+			params.Quantity = int32(acc.quantity)
+			params.Revenue = rollupAmount(acc.revenue)
+			params.OrderCount = int32(acc.orderCount)
+			if err := tx.CreateRollupProductDaily(ctx, params); err != nil {
+				return fmt.Errorf("lỗi khi ghi rollup sản phẩm %s: %w", params.SkuID, err)
+			}
+		}
+		for categoryID, acc := range categorySales {
+			err := tx.CreateRollupCategoryDaily(ctx, db_interact.CreateRollupCategoryDailyParams{
+				StatDate:   day,
+				CategoryID: categoryID,
+				Quantity:   int32(acc.quantity),
+				Revenue:    rollupAmount(acc.revenue),
+				OrderCount: int32(acc.orderCount),
+			})
+			if err != nil {
+				return fmt.Errorf("lỗi khi ghi rollup danh mục %s: %w", categoryID, err)
+			}
+		}
+		for _, row := range vouchers {
+			discount, _ := entity.ParseAmountToFloat64(row.DiscountAmount)
+			err := tx.CreateRollupVoucherDaily(ctx, db_interact.CreateRollupVoucherDailyParams{
+				StatDate:       day,
+				VoucherID:      row.VoucherID,
+				OwnerType:      string(row.OwnerType),
+				OwnerID:        row.OwnerID,
+				UsageCount:     int32(row.UsageCount),
+				DiscountAmount: rollupAmount(discount),
+			})
+			if err != nil {
+				return fmt.Errorf("lỗi khi ghi rollup voucher %s: %w", row.VoucherID, err)
+			}
+		}
+		return nil
+	})
+}
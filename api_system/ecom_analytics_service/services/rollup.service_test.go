@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"testing"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRollupOrderStore struct {
+	db_mysql.StoreOrder
+	placed     []db_order.GetShopPlacedStatsInRangeRow
+	completed  []db_order.GetShopCompletedStatsInRangeRow
+	products   []db_order.GetProductSalesInRangeRow
+	categories []db_order.GetCategorySalesInRangeRow
+	shopOrders []db_order.ShopOrders
+}
+
+func (f *fakeRollupOrderStore) GetShopPlacedStatsInRange(ctx context.Context, arg db_order.GetShopPlacedStatsInRangeParams) ([]db_order.GetShopPlacedStatsInRangeRow, error) {
+	return f.placed, nil
+}
+
+func (f *fakeRollupOrderStore) GetShopCompletedStatsInRange(ctx context.Context, arg db_order.GetShopCompletedStatsInRangeParams) ([]db_order.GetShopCompletedStatsInRangeRow, error) {
+	return f.completed, nil
+}
+
+func (f *fakeRollupOrderStore) GetProductSalesInRange(ctx context.Context, arg db_order.GetProductSalesInRangeParams) ([]db_order.GetProductSalesInRangeRow, error) {
+	return f.products, nil
+}
+
+func (f *fakeRollupOrderStore) GetCategorySalesInRange(ctx context.Context, arg db_order.GetCategorySalesInRangeParams) ([]db_order.GetCategorySalesInRangeRow, error) {
+	return f.categories, nil
+}
+
+func (f *fakeRollupOrderStore) GetVoucherUsageInRange(ctx context.Context, arg db_order.GetVoucherUsageInRangeParams) ([]db_order.GetVoucherUsageInRangeRow, error) {
+	return nil, nil
+}
+
+func (f *fakeRollupOrderStore) GetShopOrdersByIDs(ctx context.Context, shopOrderIds []string) ([]db_order.ShopOrders, error) {
+	return f.shopOrders, nil
+}
+
+type fakeRollupTransactionStore struct {
+	db_mysql.StoreTransaction
+	settlements []db_transaction.ShopOrderSettlements
+	lastParams  db_transaction.ListSettledSettlementsInRangeParams
+}
+
+func (f *fakeRollupTransactionStore) ListSettledSettlementsInRange(ctx context.Context, arg db_transaction.ListSettledSettlementsInRangeParams) ([]db_transaction.ShopOrderSettlements, error) {
+	f.lastParams = arg
+	return f.settlements, nil
+}
+
+type fakeRollupInteractStore struct {
+	db_mysql.StoreInteract
+	dirty      []time.Time
+	shops      []db_interact.CreateRollupShopDailyParams
+	products   []db_interact.CreateRollupProductDailyParams
+	categories []db_interact.CreateRollupCategoryDailyParams
+}
+
+func (f *fakeRollupInteractStore) ExecTS(ctx context.Context, fn func(tx db_interact.Querier) error) error {
+	return fn(f)
+}
+
+func (f *fakeRollupInteractStore) MarkRollupDirtyDay(ctx context.Context, statDate time.Time) error {
+	f.dirty = append(f.dirty, statDate)
+	return nil
+}
+
+func (f *fakeRollupInteractStore) DeleteRollupShopDailyByDate(ctx context.Context, statDate time.Time) error {
+	return nil
+}
+
+func (f *fakeRollupInteractStore) DeleteRollupProductDailyByDate(ctx context.Context, statDate time.Time) error {
+	return nil
+}
+
+func (f *fakeRollupInteractStore) DeleteRollupCategoryDailyByDate(ctx context.Context, statDate time.Time) error {
+	return nil
+}
+
+func (f *fakeRollupInteractStore) DeleteRollupVoucherDailyByDate(ctx context.Context, statDate time.Time) error {
+	return nil
+}
+
+func (f *fakeRollupInteractStore) CreateRollupShopDaily(ctx context.Context, arg db_interact.CreateRollupShopDailyParams) error {
+	f.shops = append(f.shops, arg)
+	return nil
+}
+
+func (f *fakeRollupInteractStore) CreateRollupProductDaily(ctx context.Context, arg db_interact.CreateRollupProductDailyParams) error {
+	f.products = append(f.products, arg)
+	return nil
+}
+
+func (f *fakeRollupInteractStore) CreateRollupCategoryDaily(ctx context.Context, arg db_interact.CreateRollupCategoryDailyParams) error {
+	f.categories = append(f.categories, arg)
+	return nil
+}
+
+func TestRollupDateRange(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+
+	from, to := rollupDateRange(time.Time{}, time.Date(2026, 3, 5, 15, 0, 0, 0, hcm))
+	require.Equal(t, time.Date(2000, 1, 1, 0, 0, 0, 0, hcm), from)
+	// lấy trọn ngày cuối
+	require.Equal(t, time.Date(2026, 3, 6, 0, 0, 0, 0, hcm), to)
+
+	from, to = rollupDateRange(time.Date(2026, 3, 1, 10, 0, 0, 0, hcm), time.Date(2026, 3, 1, 0, 0, 0, 0, hcm))
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, hcm), from)
+	require.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, hcm), to)
+
+	// end rỗng là hết hôm nay
+	_, to = rollupDateRange(time.Time{}, time.Time{})
+	require.True(t, to.After(time.Now()))
+	require.False(t, to.After(time.Now().Add(24*time.Hour)))
+}
+
+func TestRollupDay(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	// 17:30 UTC đã sang ngày hôm sau giờ Việt Nam
+	require.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, hcm), rollupDay(time.Date(2026, 3, 1, 17, 30, 0, 0, time.UTC)))
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, hcm), rollupDay(time.Date(2026, 3, 1, 16, 59, 0, 0, time.UTC)))
+}
+
+func TestShopRollupRevenueSeries(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, hcm)
+	w, err := buildTimeseriesWindow(hcm, granularityWeek, from, from.AddDate(0, 0, 14))
+	require.Nil(t, err)
+
+	rows := []db_interact.RollupShopDaily{
+		{StatDate: time.Date(2026, 3, 2, 0, 0, 0, 0, hcm), CompletedOrders: 2, CompletedGmv: "200.00", NetRevenue: "150.00"},
+		{StatDate: time.Date(2026, 3, 8, 0, 0, 0, 0, hcm), CompletedOrders: 1, CompletedGmv: "100.00", NetRevenue: "0.00"},
+		// ngoài khoảng
+		{StatDate: time.Date(2026, 3, 16, 0, 0, 0, 0, hcm), CompletedOrders: 5, CompletedGmv: "500.00"},
+	}
+
+	data, total := shopRollupRevenueSeries(w, rows)
+	require.Len(t, data, 2)
+	// các ngày trong cùng tuần cộng dồn, AOV tính lại theo tổng
+	require.Equal(t, entity.RevenueMetrics{GMV: 300, Orders: 3, AOV: 100, NetRevenue: 150}, data[0].RevenueMetrics)
+	// tuần không có đơn vẫn có mặt với giá trị 0
+	require.Equal(t, entity.RevenueMetrics{}, data[1].RevenueMetrics)
+	require.Equal(t, entity.RevenueMetrics{GMV: 300, Orders: 3, AOV: 100, NetRevenue: 150}, total)
+}
+
+func TestRecomputeRollupDay(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, hcm)
+
+	order := &fakeRollupOrderStore{
+		placed: []db_order.GetShopPlacedStatsInRangeRow{
+			{ShopID: "shop-1", PlacedOrders: 3, PlacedGmv: "300.00", ProcessingOrders: 1},
+		},
+		completed: []db_order.GetShopCompletedStatsInRangeRow{
+			{ShopID: "shop-1", CompletedOrders: 2, CompletedGmv: "250.50"},
+			{ShopID: "shop-2", CompletedOrders: 1, CompletedGmv: "80.00"},
+		},
+		products: []db_order.GetProductSalesInRangeRow{
+			// cùng SKU nhưng snapshot khác nhau giữa các đơn
+			{ProductID: "p-1", SkuID: "sku-1", ShopID: "shop-1", ProductNameSnapshot: "Áo", Quantity: "2", Revenue: "200.00", OrderCount: 1},
+			{ProductID: "p-1", SkuID: "sku-1", ShopID: "shop-1", CategoryID: sql.NullString{String: "cat-1", Valid: true}, ProductNameSnapshot: "Áo mới", Quantity: "1", Revenue: "50.50", OrderCount: 1},
+		},
+		categories: []db_order.GetCategorySalesInRangeRow{
+			{CategoryID: sql.NullString{String: "cat-1", Valid: true}, Quantity: "1", Revenue: "50.50", OrderCount: 1},
+			{Quantity: "2", Revenue: "200.00", OrderCount: 1},
+		},
+		shopOrders: []db_order.ShopOrders{{ID: "so-1", ShopID: "shop-1"}, {ID: "so-2", ShopID: "shop-1"}},
+	}
+	transaction := &fakeRollupTransactionStore{settlements: []db_transaction.ShopOrderSettlements{
+		{ShopOrderID: "so-1", NetSettledAmount: "90.00", CommissionFee: "10.00", ShippingFee: "5.00"},
+		{ShopOrderID: "so-2", NetSettledAmount: "60.00", CommissionFee: "6.00", ShippingFee: "0.00"},
+		// không tìm được shop thì bỏ qua
+		{ShopOrderID: "so-x", NetSettledAmount: "999.00"},
+	}}
+	interact := &fakeRollupInteractStore{}
+	s := &service{order: order, transaction: transaction, interact: interact}
+
+	require.NoError(t, s.recomputeRollupDay(context.Background(), day))
+
+	sort.Slice(interact.shops, func(i, j int) bool { return interact.shops[i].ShopID < interact.shops[j].ShopID })
+	require.Equal(t, []db_interact.CreateRollupShopDailyParams{
+		{
+			StatDate: day, ShopID: "shop-1",
+			PlacedOrders: 3, PlacedGmv: "300.00", ProcessingOrders: 1,
+			CompletedOrders: 2, CompletedGmv: "250.50",
+			NetRevenue: "150.00", CommissionRevenue: "16.00", ShippingRevenue: "5.00",
+		},
+		{
+			StatDate: day, ShopID: "shop-2",
+			PlacedGmv: "0.00", CompletedOrders: 1, CompletedGmv: "80.00",
+			NetRevenue: "0.00", CommissionRevenue: "0.00", ShippingRevenue: "0.00",
+		},
+	}, interact.shops)
+
+	// các dòng của cùng SKU gộp lại, giữ snapshot đầu tiên và lấy danh mục có giá trị
+	require.Len(t, interact.products, 1)
+	product := interact.products[0]
+	require.Equal(t, "Áo", product.ProductName)
+	require.Equal(t, "cat-1", product.CategoryID)
+	require.Equal(t, int32(3), product.Quantity)
+	require.Equal(t, "250.50", product.Revenue)
+	require.Equal(t, int32(2), product.OrderCount)
+
+	sort.Slice(interact.categories, func(i, j int) bool { return interact.categories[i].CategoryID < interact.categories[j].CategoryID })
+	require.Len(t, interact.categories, 2)
+	// đơn cũ chưa lưu danh mục gom vào category_id rỗng
+	require.Equal(t, "", interact.categories[0].CategoryID)
+	require.Equal(t, "200.00", interact.categories[0].Revenue)
+	require.Equal(t, "cat-1", interact.categories[1].CategoryID)
+}
+
+func TestMarkSettledRollupsDirty(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	transaction := &fakeRollupTransactionStore{settlements: []db_transaction.ShopOrderSettlements{
+		{SettledAt: sql.NullTime{Time: time.Date(2026, 3, 1, 16, 0, 0, 0, time.UTC), Valid: true}},
+		// cùng ngày 1/3 giờ Việt Nam
+		{SettledAt: sql.NullTime{Time: time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC), Valid: true}},
+		// 17:30 UTC đã là ngày 2/3
+		{SettledAt: sql.NullTime{Time: time.Date(2026, 3, 1, 17, 30, 0, 0, time.UTC), Valid: true}},
+	}}
+	interact := &fakeRollupInteractStore{}
+	s := &service{transaction: transaction, interact: interact}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	require.NoError(t, s.MarkSettledRollupsDirty(context.Background(), from, to))
+	require.Equal(t, from, transaction.lastParams.FromSettledAt.Time)
+	require.Equal(t, to, transaction.lastParams.ToSettledAt.Time)
+
+	sort.Slice(interact.dirty, func(i, j int) bool { return interact.dirty[i].Before(interact.dirty[j]) })
+	require.Len(t, interact.dirty, 2)
+	require.True(t, time.Date(2026, 3, 1, 0, 0, 0, 0, hcm).Equal(interact.dirty[0]))
+	require.True(t, time.Date(2026, 3, 2, 0, 0, 0, 0, hcm).Equal(interact.dirty[1]))
+}
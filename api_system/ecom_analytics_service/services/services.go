@@ -17,8 +17,10 @@ type service struct {
 	jwt            token.Maker
 	env            config_assets.ReadENV
 	apiServer      server.ApiServer
+	// giới hạn số yêu cầu xuất báo cáo chạy nền cùng lúc
+	exportSlots chan struct{}
 }
 
 func NewService(order db_order.StoreOrder, transaction db_transaction.StoreTransaction, interact db_interact.StoreInteract, db_agent_ai_db db_interact.StoreAgentAIDB, jwt token.Maker, env config_assets.ReadENV, apiServer server.ApiServer) ServiceUseCase {
-	return &service{order: order, transaction: transaction, interact: interact, db_agent_ai_db: db_agent_ai_db, jwt: jwt, env: env, apiServer: apiServer}
+	return &service{order: order, transaction: transaction, interact: interact, db_agent_ai_db: db_agent_ai_db, jwt: jwt, env: env, apiServer: apiServer, exportSlots: make(chan struct{}, exportMaxRunning)}
 }
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
+	db "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+)
+
+var errShopForbidden = errors.New("bạn không có quyền xem thống kê của shop này")
+
+// ResolveShopAccess xác định shop mà người gọi API /shop/* được xem, không tin shop_id client gửi lên:
+//   - người bán có claim shopId trong token là OWNER của shop đó
+//   - còn lại tra bảng shop_staff theo userId; requestedShopID (query shop_id) chỉ dùng để chọn shop
+//     khi người dùng là nhân sự của nhiều shop, shop không thuộc về người dùng bị từ chối (403)
+func (s *service) ResolveShopAccess(ctx context.Context, payload *token.Payload, requestedShopID string) (*entity.ShopAccess, *assets_services.ServiceError) {
+	if payload.ShopID != "" && payload.Scope == "ROLE_SELLER" && (requestedShopID == "" || requestedShopID == payload.ShopID) {
+		return &entity.ShopAccess{ShopID: payload.ShopID, Role: string(db.ShopStaffRoleOWNER)}, nil
+	}
+	if payload.UserId == "" {
+		return nil, assets_services.NewError(403, errShopForbidden)
+	}
+
+	if requestedShopID != "" {
+		staff, err := s.interact.GetShopStaff(ctx, db.GetShopStaffParams{ShopID: requestedShopID, UserID: payload.UserId})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, assets_services.NewError(403, errShopForbidden)
+			}
+			return nil, assets_services.NewError(500, fmt.Errorf("lỗi khi kiểm tra quyền xem shop: %w", err))
+		}
+		return &entity.ShopAccess{ShopID: staff.ShopID, Role: string(staff.Role)}, nil
+	}
+
+	shops, err := s.interact.ListShopsByStaffUser(ctx, payload.UserId)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("lỗi khi kiểm tra quyền xem shop: %w", err))
+	}
+	switch len(shops) {
+	case 0:
+		return nil, assets_services.NewError(403, errors.New("tài khoản không thuộc shop nào"))
+	case 1:
+		return &entity.ShopAccess{ShopID: shops[0].ShopID, Role: string(shops[0].Role)}, nil
+	default:
+		return nil, assets_services.NewError(400, errors.New("tài khoản thuộc nhiều shop, cần truyền shop_id"))
+	}
+}
+
+// ListShopStaff danh sách nhân sự của shop
+func (s *service) ListShopStaff(ctx context.Context, shopID string) ([]entity.ShopStaffItem, *assets_services.ServiceError) {
+	rows, err := s.interact.ListShopStaff(ctx, shopID)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("lỗi khi lấy danh sách nhân sự shop: %w", err))
+	}
+	items := make([]entity.ShopStaffItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, entity.ShopStaffItem{
+			UserID:    row.UserID,
+			Role:      string(row.Role),
+			CreatedBy: row.CreatedBy,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+	return items, nil
+}
+
+// AddShopStaff thêm nhân sự hoặc đổi vai trò: OWNER cấp được MANAGER/VIEWER, MANAGER chỉ cấp được VIEWER.
+// Không đổi được vai trò của chính mình và của chủ shop.
+func (s *service) AddShopStaff(ctx context.Context, access entity.ShopAccess, actorID string, req entity.AddShopStaffRequest) *assets_services.ServiceError {
+	if req.UserID == actorID {
+		return assets_services.NewError(400, errors.New("không thể tự đổi vai trò của chính mình"))
+	}
+	if serr := s.checkManageStaff(ctx, access, req.UserID); serr != nil {
+		return serr
+	}
+	if access.Role == string(db.ShopStaffRoleMANAGER) && req.Role != string(db.ShopStaffRoleVIEWER) {
+		return assets_services.NewError(403, errors.New("quản lý chỉ được thêm nhân viên với vai trò VIEWER"))
+	}
+
+	err := s.interact.UpsertShopStaff(ctx, db.UpsertShopStaffParams{
+		ShopID:    access.ShopID,
+		UserID:    req.UserID,
+		Role:      db.ShopStaffRole(req.Role),
+		CreatedBy: actorID,
+	})
+	if err != nil {
+		return assets_services.NewError(500, fmt.Errorf("lỗi khi lưu nhân sự shop: %w", err))
+	}
+	return nil
+}
+
+// RemoveShopStaff xóa nhân sự khỏi shop, cùng quy tắc phân quyền với AddShopStaff
+func (s *service) RemoveShopStaff(ctx context.Context, access entity.ShopAccess, actorID, userID string) *assets_services.ServiceError {
+	if userID == actorID {
+		return assets_services.NewError(400, errors.New("không thể tự xóa chính mình khỏi shop"))
+	}
+	if serr := s.checkManageStaff(ctx, access, userID); serr != nil {
+		return serr
+	}
+	rows, err := s.interact.DeleteShopStaff(ctx, db.DeleteShopStaffParams{ShopID: access.ShopID, UserID: userID})
+	if err != nil {
+		return assets_services.NewError(500, fmt.Errorf("lỗi khi xóa nhân sự shop: %w", err))
+	}
+	if rows == 0 {
+		return assets_services.NewError(404, errors.New("không tìm thấy nhân sự trong shop"))
+	}
+	return nil
+}
+
+// checkManageStaff kiểm tra người gọi được thay đổi nhân sự userID: chủ shop không bị thay đổi qua API này,
+// quản lý chỉ thay đổi được nhân viên VIEWER
+func (s *service) checkManageStaff(ctx context.Context, access entity.ShopAccess, userID string) *assets_services.ServiceError {
+	staff, err := s.interact.GetShopStaff(ctx, db.GetShopStaffParams{ShopID: access.ShopID, UserID: userID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return assets_services.NewError(500, fmt.Errorf("lỗi khi lấy nhân sự shop: %w", err))
+	}
+	if staff.Role == db.ShopStaffRoleOWNER {
+		return assets_services.NewError(403, errors.New("không thể thay đổi chủ shop"))
+	}
+	if access.Role == string(db.ShopStaffRoleMANAGER) && staff.Role != db.ShopStaffRoleVIEWER {
+		return assets_services.NewError(403, errors.New("quản lý chỉ được thay đổi nhân viên với vai trò VIEWER"))
+	}
+	return nil
+}
+
+// SetShopOwner admin gán chủ shop, dùng cho người bán có token không kèm claim shopId.
+// Chủ shop cũ bị hạ xuống MANAGER trong cùng transaction nên mỗi shop chỉ có một OWNER.
+func (s *service) SetShopOwner(ctx context.Context, adminID, shopID string, req entity.SetShopOwnerRequest) *assets_services.ServiceError {
+	err := s.interact.ExecTS(ctx, func(tx db.Querier) error {
+		_, err := tx.DemoteShopOwners(ctx, db.DemoteShopOwnersParams{
+			CreatedBy: adminID,
+			ShopID:    shopID,
+			UserID:    req.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.UpsertShopStaff(ctx, db.UpsertShopStaffParams{
+			ShopID:    shopID,
+			UserID:    req.UserID,
+			Role:      db.ShopStaffRoleOWNER,
+			CreatedBy: adminID,
+		})
+	})
+	if err != nil {
+		return assets_services.NewError(500, fmt.Errorf("lỗi khi gán chủ shop: %w", err))
+	}
+	return nil
+}
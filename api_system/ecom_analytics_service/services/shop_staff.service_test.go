@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/TranVinhHien/ecom_analytics_service/assets/token"
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_interact "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/interact"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeShopStaffStore giữ bảng shop_staff trong bộ nhớ theo khóa (shop_id, user_id)
+type fakeShopStaffStore struct {
+	db_mysql.StoreInteract
+	staff map[[2]string]db_interact.ShopStaffRole
+	txs   int
+}
+
+func newFakeShopStaffStore(rows ...db_interact.ShopStaff) *fakeShopStaffStore {
+	f := &fakeShopStaffStore{staff: map[[2]string]db_interact.ShopStaffRole{}}
+	for _, row := range rows {
+		f.staff[[2]string{row.ShopID, row.UserID}] = row.Role
+	}
+	return f
+}
+
+func (f *fakeShopStaffStore) ExecTS(ctx context.Context, fn func(tx db_interact.Querier) error) error {
+	f.txs++
+	return fn(f)
+}
+
+func (f *fakeShopStaffStore) GetShopStaff(ctx context.Context, arg db_interact.GetShopStaffParams) (db_interact.ShopStaff, error) {
+	role, ok := f.staff[[2]string{arg.ShopID, arg.UserID}]
+	if !ok {
+		return db_interact.ShopStaff{}, sql.ErrNoRows
+	}
+	return db_interact.ShopStaff{ShopID: arg.ShopID, UserID: arg.UserID, Role: role}, nil
+}
+
+func (f *fakeShopStaffStore) ListShopsByStaffUser(ctx context.Context, userID string) ([]db_interact.ShopStaff, error) {
+	var rows []db_interact.ShopStaff
+	for key, role := range f.staff {
+		if key[1] == userID {
+			rows = append(rows, db_interact.ShopStaff{ShopID: key[0], UserID: key[1], Role: role})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ShopID < rows[j].ShopID })
+	return rows, nil
+}
+
+func (f *fakeShopStaffStore) UpsertShopStaff(ctx context.Context, arg db_interact.UpsertShopStaffParams) error {
+	f.staff[[2]string{arg.ShopID, arg.UserID}] = arg.Role
+	return nil
+}
+
+func (f *fakeShopStaffStore) DeleteShopStaff(ctx context.Context, arg db_interact.DeleteShopStaffParams) (int64, error) {
+	key := [2]string{arg.ShopID, arg.UserID}
+	if _, ok := f.staff[key]; !ok {
+		return 0, nil
+	}
+	delete(f.staff, key)
+	return 1, nil
+}
+
+func (f *fakeShopStaffStore) DemoteShopOwners(ctx context.Context, arg db_interact.DemoteShopOwnersParams) (int64, error) {
+	var rows int64
+	for key, role := range f.staff {
+		if key[0] == arg.ShopID && key[1] != arg.UserID && role == db_interact.ShopStaffRoleOWNER {
+			f.staff[key] = db_interact.ShopStaffRoleMANAGER
+			rows++
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeShopStaffStore) owners(shopID string) []string {
+	var users []string
+	for key, role := range f.staff {
+		if key[0] == shopID && role == db_interact.ShopStaffRoleOWNER {
+			users = append(users, key[1])
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+func staffRow(shopID, userID string, role db_interact.ShopStaffRole) db_interact.ShopStaff {
+	return db_interact.ShopStaff{ShopID: shopID, UserID: userID, Role: role}
+}
+
+func TestResolveShopAccess(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeShopStaffStore(
+		staffRow("shop-a", "u-manager", db_interact.ShopStaffRoleMANAGER),
+		staffRow("shop-a", "u-multi", db_interact.ShopStaffRoleVIEWER),
+		staffRow("shop-b", "u-multi", db_interact.ShopStaffRoleMANAGER),
+	)
+	s := &service{interact: store}
+
+	// người bán có claim shopId là OWNER, không cần tra bảng
+	access, serr := s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-seller", Scope: "ROLE_SELLER", ShopID: "shop-s"}, "")
+	require.Nil(t, serr)
+	require.Equal(t, entity.ShopAccess{ShopID: "shop-s", Role: "OWNER"}, *access)
+
+	// người bán hỏi shop khác claim thì phải là nhân sự của shop đó
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-seller", Scope: "ROLE_SELLER", ShopID: "shop-s"}, "shop-a")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+
+	access, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-manager"}, "")
+	require.Nil(t, serr)
+	require.Equal(t, entity.ShopAccess{ShopID: "shop-a", Role: "MANAGER"}, *access)
+
+	// nhân sự hỏi shop không thuộc về mình
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-manager"}, "shop-b")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+
+	// thuộc nhiều shop thì phải chọn shop, chọn đúng shop thì lấy vai trò ở shop đó
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-multi"}, "")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusBadRequest, serr.Code)
+	access, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-multi"}, "shop-b")
+	require.Nil(t, serr)
+	require.Equal(t, entity.ShopAccess{ShopID: "shop-b", Role: "MANAGER"}, *access)
+
+	// không phải nhân sự của shop nào
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-stranger"}, "")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{UserId: "u-stranger"}, "shop-a")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+	_, serr = s.ResolveShopAccess(ctx, &token.Payload{}, "shop-a")
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+}
+
+func TestManageShopStaffPermissions(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeShopStaffStore(
+		staffRow("shop-a", "u-owner", db_interact.ShopStaffRoleOWNER),
+		staffRow("shop-a", "u-manager", db_interact.ShopStaffRoleMANAGER),
+		staffRow("shop-a", "u-manager-2", db_interact.ShopStaffRoleMANAGER),
+		staffRow("shop-a", "u-viewer", db_interact.ShopStaffRoleVIEWER),
+	)
+	s := &service{interact: store}
+	owner := entity.ShopAccess{ShopID: "shop-a", Role: "OWNER"}
+	manager := entity.ShopAccess{ShopID: "shop-a", Role: "MANAGER"}
+
+	tests := []struct {
+		access entity.ShopAccess
+		actor  string
+		target string
+		code   int
+	}{
+		// quản lý không xóa được chủ shop hay quản lý khác
+		{manager, "u-manager", "u-owner", http.StatusForbidden},
+		{manager, "u-manager", "u-manager-2", http.StatusForbidden},
+		// chủ shop cũng không bị xóa bởi chủ shop khác qua API này
+		{owner, "u-owner-claim", "u-owner", http.StatusForbidden},
+		{manager, "u-manager", "u-manager", http.StatusBadRequest},
+		{manager, "u-manager", "u-missing", http.StatusNotFound},
+		{manager, "u-manager", "u-viewer", 0},
+		{owner, "u-owner", "u-manager-2", 0},
+	}
+	for _, tt := range tests {
+		serr := s.RemoveShopStaff(ctx, tt.access, tt.actor, tt.target)
+		if tt.code == 0 {
+			require.Nil(t, serr, "%s xóa %s", tt.actor, tt.target)
+			continue
+		}
+		require.NotNil(t, serr, "%s xóa %s", tt.actor, tt.target)
+		require.Equal(t, tt.code, serr.Code, "%s xóa %s", tt.actor, tt.target)
+	}
+	require.Contains(t, store.staff, [2]string{"shop-a", "u-owner"})
+	require.Contains(t, store.staff, [2]string{"shop-a", "u-manager"})
+	require.NotContains(t, store.staff, [2]string{"shop-a", "u-viewer"})
+	require.NotContains(t, store.staff, [2]string{"shop-a", "u-manager-2"})
+
+	// quản lý chỉ cấp được VIEWER, không hạ được chủ shop
+	serr := s.AddShopStaff(ctx, manager, "u-manager", entity.AddShopStaffRequest{UserID: "u-new", Role: "MANAGER"})
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+	serr = s.AddShopStaff(ctx, manager, "u-manager", entity.AddShopStaffRequest{UserID: "u-owner", Role: "VIEWER"})
+	require.NotNil(t, serr)
+	require.Equal(t, http.StatusForbidden, serr.Code)
+	require.Nil(t, s.AddShopStaff(ctx, manager, "u-manager", entity.AddShopStaffRequest{UserID: "u-new", Role: "VIEWER"}))
+	require.Nil(t, s.AddShopStaff(ctx, owner, "u-owner", entity.AddShopStaffRequest{UserID: "u-new", Role: "MANAGER"}))
+	require.Equal(t, db_interact.ShopStaffRoleMANAGER, store.staff[[2]string{"shop-a", "u-new"}])
+}
+
+func TestSetShopOwnerKeepsSingleOwner(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeShopStaffStore(
+		staffRow("shop-a", "u-owner", db_interact.ShopStaffRoleOWNER),
+		staffRow("shop-a", "u-viewer", db_interact.ShopStaffRoleVIEWER),
+		staffRow("shop-b", "u-owner-b", db_interact.ShopStaffRoleOWNER),
+	)
+	s := &service{interact: store}
+
+	require.Nil(t, s.SetShopOwner(ctx, "admin", "shop-a", entity.SetShopOwnerRequest{UserID: "u-viewer"}))
+	require.Equal(t, []string{"u-viewer"}, store.owners("shop-a"))
+	// chủ cũ bị hạ xuống quản lý, shop khác không bị ảnh hưởng
+	require.Equal(t, db_interact.ShopStaffRoleMANAGER, store.staff[[2]string{"shop-a", "u-owner"}])
+	require.Equal(t, []string{"u-owner-b"}, store.owners("shop-b"))
+	require.Equal(t, 1, store.txs)
+
+	// gán lại đúng chủ hiện tại giữ nguyên
+	require.Nil(t, s.SetShopOwner(ctx, "admin", "shop-a", entity.SetShopOwnerRequest{UserID: "u-viewer"}))
+	require.Equal(t, []string{"u-viewer"}, store.owners("shop-a"))
+
+	// shop chưa có nhân sự
+	require.Nil(t, s.SetShopOwner(ctx, "admin", "shop-c", entity.SetShopOwnerRequest{UserID: "u-new"}))
+	require.Equal(t, []string{"u-new"}, store.owners("shop-c"))
+}
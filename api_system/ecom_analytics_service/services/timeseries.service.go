@@ -0,0 +1,204 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+)
+
+const (
+	granularityHour  = "hour"
+	granularityDay   = "day"
+	granularityWeek  = "week"
+	granularityMonth = "month"
+
+	comparePreviousPeriod = "previous_period"
+	comparePreviousYear   = "previous_year"
+
+	defaultTimeseriesTimezone = "Asia/Ho_Chi_Minh"
+	// giới hạn số khung của một chuỗi để tránh client xin theo giờ cho cả năm
+	maxTimeseriesBuckets = 1000
+	// chuỗi không đọc được từ bảng tổng hợp (theo giờ hoặc múi giờ khác) quét đơn hàng gốc theo khung 15 phút,
+	// mỗi ngày tối đa 96 dòng mỗi kỳ nên giới hạn độ dài khoảng thời gian
+	maxRawTimeseriesDays = 93
+)
+
+// timeseriesWindow khoảng thời gian [From, To) đã chia sẵn thành các khung theo Granularity trong múi giờ Loc
+type timeseriesWindow struct {
+	Loc         *time.Location
+	Granularity string
+	From        time.Time
+	To          time.Time
+	Buckets     []time.Time
+}
+
+// newTimeseriesWindow kiểm tra tham số và dựng khoảng thời gian chính, kèm khoảng so sánh nếu có compare.
+// start_date/end_date (2006-01-02) tính theo múi giờ yêu cầu, end_date được lấy trọn ngày.
+func newTimeseriesWindow(params entity.TimeseriesParams) (*timeseriesWindow, *timeseriesWindow, *assets_services.ServiceError) {
+	granularity := params.Granularity
+	if granularity == "" {
+		granularity = granularityDay
+	}
+	switch granularity {
+	case granularityHour, granularityDay, granularityWeek, granularityMonth:
+	default:
+		return nil, nil, assets_services.NewError(http.StatusBadRequest, errors.New("granularity chỉ nhận hour, day, week hoặc month"))
+	}
+
+	tz := params.Timezone
+	if tz == "" {
+		tz = defaultTimeseriesTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("timezone không hợp lệ: %s", tz))
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from, to := today.AddDate(0, 0, -30), today
+	if params.StartDate != "" {
+		if from, err = time.ParseInLocation("2006-01-02", params.StartDate, loc); err != nil {
+			return nil, nil, assets_services.NewError(http.StatusBadRequest, errors.New("invalid start_date format"))
+		}
+	}
+	if params.EndDate != "" {
+		if to, err = time.ParseInLocation("2006-01-02", params.EndDate, loc); err != nil {
+			return nil, nil, assets_services.NewError(http.StatusBadRequest, errors.New("invalid end_date format"))
+		}
+	}
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return nil, nil, assets_services.NewError(http.StatusBadRequest, errors.New("start_date phải trước hoặc bằng end_date"))
+	}
+
+	current, serr := buildTimeseriesWindow(loc, granularity, from, to)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	if !useDailyRollup(current) && to.After(from.AddDate(0, 0, maxRawTimeseriesDays)) {
+		return nil, nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("khoảng thời gian tối đa %d ngày với granularity hour hoặc timezone khác %s", maxRawTimeseriesDays, defaultTimeseriesTimezone))
+	}
+
+	var compare *timeseriesWindow
+	switch params.Compare {
+	case "":
+	case comparePreviousPeriod:
+		// lùi đúng bằng số khung của kỳ hiện tại để hai chuỗi có cùng số khung
+		n := -len(current.Buckets)
+		compare, serr = buildTimeseriesWindow(loc, granularity, stepBucket(from, granularity, n), stepBucket(to, granularity, n))
+	case comparePreviousYear:
+		compare, serr = buildTimeseriesWindow(loc, granularity, from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0))
+	default:
+		return nil, nil, assets_services.NewError(http.StatusBadRequest, errors.New("compare chỉ nhận previous_period hoặc previous_year"))
+	}
+	if serr != nil {
+		return nil, nil, serr
+	}
+	return current, compare, nil
+}
+
+func buildTimeseriesWindow(loc *time.Location, granularity string, from, to time.Time) (*timeseriesWindow, *assets_services.ServiceError) {
+	w := &timeseriesWindow{Loc: loc, Granularity: granularity, From: from, To: to}
+	for b := truncateBucket(from, granularity, loc); b.Before(to); b = stepBucket(b, granularity, 1) {
+		if len(w.Buckets) == maxTimeseriesBuckets {
+			return nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("khoảng thời gian quá dài cho granularity %s (tối đa %d điểm)", granularity, maxTimeseriesBuckets))
+		}
+		w.Buckets = append(w.Buckets, b)
+	}
+	return w, nil
+}
+
+// truncateBucket trả về đầu khung chứa t; tuần bắt đầu từ thứ Hai
+func truncateBucket(t time.Time, granularity string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch granularity {
+	case granularityHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case granularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case granularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// stepBucket dịch t đi n khung; ngày/tuần/tháng dịch theo lịch nên không lệch khi đổi giờ mùa hè
+func stepBucket(t time.Time, granularity string, n int) time.Time {
+	switch granularity {
+	case granularityHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case granularityWeek:
+		return t.AddDate(0, 0, 7*n)
+	case granularityMonth:
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// index trả về vị trí khung chứa t, -1 nếu t nằm ngoài [From, To)
+func (w *timeseriesWindow) index(t time.Time) int {
+	if t.Before(w.From) || !t.Before(w.To) {
+		return -1
+	}
+	return sort.Search(len(w.Buckets), func(i int) bool { return w.Buckets[i].After(t) }) - 1
+}
+
+func (w *timeseriesWindow) label(i int) string {
+	switch w.Granularity {
+	case granularityHour:
+		return w.Buckets[i].Format("2006-01-02 15:00")
+	case granularityMonth:
+		return w.Buckets[i].Format("2006-01")
+	default:
+		return w.Buckets[i].Format("2006-01-02")
+	}
+}
+
+// useDailyRollup bảng tổng hợp chia ngày theo giờ Việt Nam nên chỉ dùng được cho khung ngày/tuần/tháng ở múi giờ mặc định
+func useDailyRollup(w *timeseriesWindow) bool {
+	return w.Granularity != granularityHour && w.Loc.String() == defaultTimeseriesTimezone
+}
+
+// averageOrderValue GMV / số đơn, bằng 0 khi không có đơn
+func averageOrderValue(gmv float64, orders int64) float64 {
+	if orders == 0 {
+		return 0
+	}
+	return gmv / float64(orders)
+}
+
+// percentDelta phần trăm thay đổi của cur so với prev, nil khi prev bằng 0
+func percentDelta(cur, prev float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	d := (cur - prev) / prev * 100
+	return &d
+}
+
+func revenueDelta(cur, prev entity.RevenueMetrics) *entity.TimeseriesDelta {
+	return &entity.TimeseriesDelta{
+		GMV:        percentDelta(cur.GMV, prev.GMV),
+		Orders:     percentDelta(float64(cur.Orders), float64(prev.Orders)),
+		AOV:        percentDelta(cur.AOV, prev.AOV),
+		NetRevenue: percentDelta(cur.NetRevenue, prev.NetRevenue),
+	}
+}
+
+func platformRevenueDelta(cur, prev entity.PlatformRevenueMetrics) *entity.TimeseriesDelta {
+	return &entity.TimeseriesDelta{
+		GMV:        percentDelta(cur.TotalGMV, prev.TotalGMV),
+		Orders:     percentDelta(float64(cur.Orders), float64(prev.Orders)),
+		AOV:        percentDelta(cur.AOV, prev.AOV),
+		NetRevenue: percentDelta(cur.PlatformRevenue, prev.PlatformRevenue),
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	db_order "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/order"
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+	entity "github.com/TranVinhHien/ecom_analytics_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestNewTimeseriesWindowDefaults(t *testing.T) {
+	current, compare, err := newTimeseriesWindow(entity.TimeseriesParams{})
+	require.Nil(t, err)
+	require.Nil(t, compare)
+	require.Equal(t, granularityDay, current.Granularity)
+	require.Equal(t, defaultTimeseriesTimezone, current.Loc.String())
+	// 30 ngày trước tới hết hôm nay
+	require.Len(t, current.Buckets, 31)
+	require.Equal(t, current.From, current.Buckets[0])
+}
+
+func TestNewTimeseriesWindowEndDateInclusive(t *testing.T) {
+	loc := mustLoadLocation(t, defaultTimeseriesTimezone)
+	current, _, err := newTimeseriesWindow(entity.TimeseriesParams{StartDate: "2026-03-01", EndDate: "2026-03-01"})
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), current.From)
+	require.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), current.To)
+	require.Len(t, current.Buckets, 1)
+}
+
+func TestNewTimeseriesWindowInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		params entity.TimeseriesParams
+	}{
+		{"granularity sai", entity.TimeseriesParams{Granularity: "year"}},
+		{"timezone sai", entity.TimeseriesParams{Timezone: "Mars/Base"}},
+		{"start_date sai định dạng", entity.TimeseriesParams{StartDate: "01/03/2026"}},
+		{"start_date sau end_date", entity.TimeseriesParams{StartDate: "2026-03-02", EndDate: "2026-03-01"}},
+		{"compare sai", entity.TimeseriesParams{StartDate: "2026-03-01", EndDate: "2026-03-07", Compare: "last_week"}},
+		// 60 ngày theo giờ vượt 1000 khung
+		{"quá nhiều khung giờ", entity.TimeseriesParams{StartDate: "2026-01-01", EndDate: "2026-03-01", Granularity: granularityHour}},
+		// múi giờ khác không dùng được bảng tổng hợp, phải quét khung 15 phút
+		{"quét đơn gốc quá dài", entity.TimeseriesParams{StartDate: "2026-01-01", EndDate: "2026-06-30", Timezone: "UTC"}},
+	}
+	for _, tt := range tests {
+		_, _, err := newTimeseriesWindow(tt.params)
+		require.NotNil(t, err, tt.name)
+		require.Equal(t, http.StatusBadRequest, err.Code, tt.name)
+	}
+}
+
+func TestNewTimeseriesWindowRawRangeCap(t *testing.T) {
+	// múi giờ mặc định đọc bảng tổng hợp nên được xem cả năm theo ngày
+	current, _, err := newTimeseriesWindow(entity.TimeseriesParams{StartDate: "2026-01-01", EndDate: "2026-12-31"})
+	require.Nil(t, err)
+	require.Len(t, current.Buckets, 365)
+
+	// múi giờ khác trong giới hạn vẫn được
+	current, _, err = newTimeseriesWindow(entity.TimeseriesParams{StartDate: "2026-01-01", EndDate: "2026-03-31", Timezone: "UTC"})
+	require.Nil(t, err)
+	require.Len(t, current.Buckets, 90)
+}
+
+func TestNewTimeseriesWindowCompare(t *testing.T) {
+	loc := mustLoadLocation(t, defaultTimeseriesTimezone)
+
+	current, compare, err := newTimeseriesWindow(entity.TimeseriesParams{StartDate: "2026-03-01", EndDate: "2026-03-07", Compare: comparePreviousPeriod})
+	require.Nil(t, err)
+	require.Len(t, compare.Buckets, len(current.Buckets))
+	require.Equal(t, time.Date(2026, 2, 22, 0, 0, 0, 0, loc), compare.From)
+	require.Equal(t, current.From, compare.To)
+
+	current, compare, err = newTimeseriesWindow(entity.TimeseriesParams{StartDate: "2026-01-01", EndDate: "2026-03-31", Granularity: granularityMonth, Compare: comparePreviousYear})
+	require.Nil(t, err)
+	require.Len(t, current.Buckets, 3)
+	require.Len(t, compare.Buckets, 3)
+	require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, loc), compare.Buckets[0])
+}
+
+func TestTruncateBucket(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	// 17:30 UTC ngày 31/1 đã là 00:30 ngày 1/2 giờ Việt Nam
+	monthEdge := time.Date(2026, 1, 31, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		t           time.Time
+		granularity string
+		want        time.Time
+	}{
+		{"giờ", monthEdge, granularityHour, time.Date(2026, 2, 1, 0, 0, 0, 0, hcm)},
+		{"ngày qua mốc tháng", monthEdge, granularityDay, time.Date(2026, 2, 1, 0, 0, 0, 0, hcm)},
+		{"tháng qua mốc tháng", monthEdge, granularityMonth, time.Date(2026, 2, 1, 0, 0, 0, 0, hcm)},
+		// tuần bắt đầu từ thứ Hai: Chủ nhật 8/3 thuộc tuần từ 2/3
+		{"Chủ nhật", time.Date(2026, 3, 8, 23, 0, 0, 0, hcm), granularityWeek, time.Date(2026, 3, 2, 0, 0, 0, 0, hcm)},
+		{"thứ Hai", time.Date(2026, 3, 2, 0, 0, 0, 0, hcm), granularityWeek, time.Date(2026, 3, 2, 0, 0, 0, 0, hcm)},
+		// tuần cắt qua năm
+		{"tuần qua năm", time.Date(2027, 1, 1, 12, 0, 0, 0, hcm), granularityWeek, time.Date(2026, 12, 28, 0, 0, 0, 0, hcm)},
+	}
+	for _, tt := range tests {
+		got := truncateBucket(tt.t, tt.granularity, hcm)
+		require.True(t, tt.want.Equal(got), "%s: muốn %v, nhận %v", tt.name, tt.want, got)
+	}
+}
+
+func TestTimeseriesWindowDST(t *testing.T) {
+	// New York đổi sang giờ mùa hè lúc 2:00 ngày 8/3/2026, ngày này chỉ có 23 giờ
+	ny := mustLoadLocation(t, "America/New_York")
+	from := time.Date(2026, 3, 8, 0, 0, 0, 0, ny)
+
+	w, err := buildTimeseriesWindow(ny, granularityHour, from, from.AddDate(0, 0, 1))
+	require.Nil(t, err)
+	require.Len(t, w.Buckets, 23)
+	require.Equal(t, 3, w.Buckets[2].Hour())
+
+	// khung ngày đi theo lịch, luôn bắt đầu lúc 0:00 giờ địa phương
+	w, err = buildTimeseriesWindow(ny, granularityDay, from.AddDate(0, 0, -1), from.AddDate(0, 0, 2))
+	require.Nil(t, err)
+	require.Len(t, w.Buckets, 3)
+	for _, b := range w.Buckets {
+		require.Zero(t, b.Hour())
+	}
+	require.Equal(t, 2, w.index(time.Date(2026, 3, 9, 0, 30, 0, 0, ny)))
+	require.Equal(t, 1, w.index(time.Date(2026, 3, 8, 23, 59, 0, 0, ny)))
+}
+
+func TestTimeseriesWindowIndex(t *testing.T) {
+	hcm := mustLoadLocation(t, defaultTimeseriesTimezone)
+	// From giữa tuần: khung đầu bắt đầu từ thứ Hai trước From
+	from := time.Date(2026, 3, 4, 0, 0, 0, 0, hcm)
+	to := time.Date(2026, 3, 16, 0, 0, 0, 0, hcm)
+	w, err := buildTimeseriesWindow(hcm, granularityWeek, from, to)
+	require.Nil(t, err)
+	require.Len(t, w.Buckets, 2)
+
+	require.Equal(t, 0, w.index(from))
+	require.Equal(t, 1, w.index(time.Date(2026, 3, 9, 0, 0, 0, 0, hcm)))
+	require.Equal(t, 1, w.index(to.Add(-time.Nanosecond)))
+	// ngoài [From, To)
+	require.Equal(t, -1, w.index(from.Add(-time.Second)))
+	require.Equal(t, -1, w.index(to))
+	// thời điểm UTC được so theo mốc tuyệt đối
+	require.Equal(t, 0, w.index(time.Date(2026, 3, 8, 16, 59, 0, 0, time.UTC)))
+	require.Equal(t, 1, w.index(time.Date(2026, 3, 8, 17, 0, 0, 0, time.UTC)))
+}
+
+func TestPercentDelta(t *testing.T) {
+	require.Nil(t, percentDelta(100, 0))
+	require.InDelta(t, 50.0, *percentDelta(150, 100), 1e-9)
+	require.InDelta(t, -50.0, *percentDelta(100, 200), 1e-9)
+	require.InDelta(t, -100.0, *percentDelta(0, 80), 1e-9)
+}
+
+func TestShopRevenueSeriesFillsGaps(t *testing.T) {
+	bkk := mustLoadLocation(t, "Asia/Bangkok")
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, bkk)
+	w, err := buildTimeseriesWindow(bkk, granularityDay, from, from.AddDate(0, 0, 3))
+	require.Nil(t, err)
+
+	gmv := []db_order.GetShopRevenueTimeSeriesRow{
+		// 23:45 ngày 1/3 và 00:15 ngày 2/3 giờ Bangkok
+		{SlotStart: time.Date(2026, 3, 1, 16, 45, 0, 0, time.UTC), OrderCount: 1, Gmv: "50.00"},
+		{SlotStart: time.Date(2026, 3, 1, 17, 15, 0, 0, time.UTC), OrderCount: 2, Gmv: "100.00"},
+		// ngoài khoảng
+		{SlotStart: time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC), OrderCount: 9, Gmv: "900.00"},
+	}
+	settlements := []db_transaction.ShopOrderSettlements{
+		{Status: db_transaction.ShopOrderSettlementsStatusSETTLED, NetSettledAmount: "30.00", SettledAt: sql.NullTime{Time: time.Date(2026, 3, 3, 12, 0, 0, 0, bkk), Valid: true}},
+		{Status: db_transaction.ShopOrderSettlementsStatusFUNDSHELD, NetSettledAmount: "70.00", SettledAt: sql.NullTime{Time: time.Date(2026, 3, 3, 12, 0, 0, 0, bkk), Valid: true}},
+	}
+
+	data, total := shopRevenueSeries(w, gmv, settlements)
+	require.Len(t, data, 3)
+	require.Equal(t, []string{"2026-03-01", "2026-03-02", "2026-03-03"}, []string{data[0].Date, data[1].Date, data[2].Date})
+	require.Equal(t, entity.RevenueMetrics{GMV: 50, Orders: 1, AOV: 50}, data[0].RevenueMetrics)
+	require.Equal(t, entity.RevenueMetrics{GMV: 100, Orders: 2, AOV: 50}, data[1].RevenueMetrics)
+	// khung không có đơn vẫn có mặt với giá trị 0
+	require.Equal(t, entity.RevenueMetrics{NetRevenue: 30}, data[2].RevenueMetrics)
+	require.Equal(t, entity.RevenueMetrics{GMV: 150, Orders: 3, AOV: 50, NetRevenue: 30}, total)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_analytics_service/db/mysql"
+	db_transaction "github.com/TranVinhHien/ecom_analytics_service/db/sqlc/transaction"
+	assets_services "github.com/TranVinhHien/ecom_analytics_service/services/assets"
+)
+
+// sort của từng danh sách ghi vào cursor để không dùng lẫn cursor giữa các API khác
+const (
+	ledgerEntryCursorSort = "ledger_entries.created_at"
+	transactionCursorSort = "transactions.created_at"
+	settlementCursorSort  = "settlements.order_completed_at"
+	ledgerCursorSort      = "ledgers.created_at"
+)
+
+// keysetList lấy một trang theo cursor (thời gian, id) giảm dần: đọc cursor vào KeysetParams,
+// lấy dư một bản ghi để biết còn trang sau, trả về next/prev cursor (nil khi hết trang).
+// key trả về thời gian (có thể NULL) và id của bản ghi để sinh cursor.
+func keysetList[T any](s *service, sort, token string, limit int32, fetch func(db_mysql.KeysetParams) ([]T, error), key func(T) (sql.NullTime, string)) ([]T, *string, *string, *assets_services.ServiceError) {
+	if limit < 1 {
+		return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("limit phải lớn hơn 0"))
+	}
+	params := db_mysql.KeysetParams{Limit: limit + 1}
+	var cursor *assets_services.Cursor
+	if token != "" {
+		c, err := assets_services.DecodeCursor(s.cursorSecret(), token)
+		if err != nil || c.Sort != sort {
+			return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, assets_services.ErrInvalidCursor)
+		}
+		if c.Key != "" {
+			after, err := time.Parse(time.RFC3339Nano, c.Key)
+			if err != nil {
+				return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, assets_services.ErrInvalidCursor)
+			}
+			params.AfterTime = sql.NullTime{Time: after, Valid: true}
+		}
+		params.AfterID, params.Backward = c.ID, c.Backward
+		cursor = &c
+	}
+
+	items, err := fetch(params)
+	if err != nil {
+		return nil, nil, nil, assets_services.NewError(http.StatusBadRequest, err)
+	}
+	page, hasNext, hasPrev := assets_services.KeysetPage(items, int(limit), cursor)
+	if len(page) == 0 {
+		return page, nil, nil, nil
+	}
+	var next, prev *string
+	if hasNext {
+		at, id := key(page[len(page)-1])
+		next = s.keysetCursor(sort, at, id, false)
+	}
+	if hasPrev {
+		at, id := key(page[0])
+		prev = s.keysetCursor(sort, at, id, true)
+	}
+	return page, next, prev, nil
+}
+
+func (s *service) keysetCursor(sort string, at sql.NullTime, id string, backward bool) *string {
+	c := assets_services.Cursor{Sort: sort, ID: id, Backward: backward}
+	// Key rỗng nghĩa là thời gian của bản ghi mốc là NULL
+	if at.Valid {
+		c.Key = at.Time.UTC().Format(time.RFC3339Nano)
+	}
+	token := assets_services.EncodeCursor(s.cursorSecret(), c)
+	return &token
+}
+
+// keysetAll đọc lần lượt mọi trang của một danh sách keyset, dùng cho xuất báo cáo.
+// Mỗi lần gọi trả về lô tiếp theo, lô rỗng là đã hết.
+func keysetAll[T any](fetch func(context.Context, db_mysql.KeysetParams) ([]T, error), key func(T) (sql.NullTime, string)) func(ctx context.Context, limit int32) ([]T, error) {
+	var after db_mysql.KeysetParams
+	done := false
+	return func(ctx context.Context, limit int32) ([]T, error) {
+		if done {
+			return nil, nil
+		}
+		params := after
+		params.Limit = limit
+		items, err := fetch(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("lỗi khi lấy dữ liệu: %w", err)
+		}
+		if len(items) < int(limit) {
+			done = true
+		}
+		if len(items) > 0 {
+			after.AfterTime, after.AfterID = key(items[len(items)-1])
+		}
+		return items, nil
+	}
+}
+
+func ledgerEntryKey(e db_transaction.LedgerEntries) (sql.NullTime, string) {
+	return sql.NullTime{Time: e.CreatedAt, Valid: true}, strconv.FormatUint(e.ID, 10)
+}
+
+// emptyIfNil để danh sách rỗng trả về [] thay vì null
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
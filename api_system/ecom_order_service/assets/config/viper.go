@@ -3,6 +3,8 @@ package config_assets
 // write a struct and a function to read the .env using viper
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -16,6 +18,8 @@ type ReadENV struct {
 	// URL service
 	URLProductService     string `mapstructure:"URL_PRODUCT_SERVICE"`
 	URLTransactionService string `mapstructure:"URL_TRANSACTION_SERVICE"`
+	// Media service (ảnh/video đánh giá), hiện do product service đảm nhận
+	URLMediaService string `mapstructure:"URL_MEDIA_SERVICE"`
 
 	// Kafka configuration
 	KafkaBrokers       string `mapstructure:"KAFKA_BROKERS"`
@@ -24,6 +28,25 @@ type ReadENV struct {
 	TokenSystem string `mapstructure:"TOKEN_SYSTEM"`
 
 	PlatformOwnerID string `mapstructure:"PLATFORM_OWNER_ID"`
+
+	// Khóa ký cursor phân trang, bỏ trống thì dùng JWT_SECRET
+	CursorSecret string `mapstructure:"CURSOR_SECRET"`
+
+	// Firebase dùng để gửi thông báo cho người mua (bỏ trống nếu không dùng)
+	FirebaseCredentials string `mapstructure:"FIREBASE_CREDENTIALS"`
+
+	// Đánh giá sản phẩm: số ảnh tối đa mỗi đánh giá và thời gian người mua được sửa đánh giá (1 lần) kể từ lúc tạo,
+	// bỏ trống thì dùng mặc định
+	ReviewMaxImages  int           `mapstructure:"REVIEW_MAX_IMAGES"`
+	ReviewEditWindow time.Duration `mapstructure:"REVIEW_EDIT_WINDOW"`
+
+	// Kiểm duyệt đánh giá: danh sách từ ngữ cấm (phân tách bằng dấu phẩy, bỏ trống thì dùng danh sách mặc định)
+	// và cách xử lý khi đánh giá chứa từ cấm: mask (che bằng *, mặc định) hoặc hold (giữ nguyên, chờ admin duyệt)
+	ReviewBannedTerms  []string `mapstructure:"REVIEW_BANNED_TERMS"`
+	ReviewFilterAction string   `mapstructure:"REVIEW_FILTER_ACTION"`
+
+	// Số đánh giá "ảo" ở mức điểm trung bình toàn sàn dùng khi tính điểm xếp hạng (Bayesian) của sản phẩm, mặc định 10
+	ReviewBayesWeight float64 `mapstructure:"REVIEW_BAYES_WEIGHT"`
 }
 
 func LoadConfig(path string) (config ReadENV, err error) {
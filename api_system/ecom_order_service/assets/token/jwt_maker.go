@@ -73,6 +73,10 @@ func (maker *JWTMaker) VerifyToken(tokenString string) (*Payload, error) {
 			UserId: claims["userId"].(string),
 			Email:  claims["email"].(string),
 		}
+		// shopId là claim tùy chọn, token cũ hoặc token không phải seller sẽ không có
+		if shopId, ok := claims["shopId"].(string); ok {
+			payload.ShopId = shopId
+		}
 
 		// Check expiration manually (optional, but recommended)
 		if !payload.Valid() {
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"mime/multipart"
 	"net/http"
 
 	assets_api "github.com/TranVinhHien/ecom_order_service/assets/api"
@@ -8,28 +9,97 @@ import (
 	services "github.com/TranVinhHien/ecom_order_service/services/entity"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 // createComment handles POST /api/v1/comments
-// Tạo đánh giá/bình luận cho sản phẩm đã mua
+// Tạo đánh giá/bình luận cho sản phẩm đã mua, có thể kèm ảnh/video (multipart/form-data)
 func (api *apiController) createComment() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		// Require auth - lấy user_id từ token
 		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
 
 		var req services.CreateCommentRequest
-		if err := ctx.ShouldBindJSON(&req); err != nil {
+		if err := ctx.ShouldBind(&req); err != nil {
 			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
 			return
 		}
 
 		// Call service để tạo comment
-		if err := api.service.CreateComment(ctx, authPayload.Sub, req); err != nil {
+		result, err := api.service.CreateComment(ctx, authPayload.Sub, ctx.GetString("token"), req, reviewMediaFiles(ctx))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		message := "Đánh giá sản phẩm thành công"
+		if result.Status == "PENDING" {
+			message = "Đánh giá của bạn đang chờ kiểm duyệt"
+		}
+		ctx.JSON(http.StatusCreated, assets_api.SimpSuccessResponse(message, result))
+	}
+}
+
+// updateComment handles PUT /api/v1/comments/:commentID
+// Người mua sửa đánh giá của mình (1 lần, trong thời hạn cho phép)
+func (api *apiController) updateComment() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req services.UpdateCommentRequest
+		if err := ctx.ShouldBind(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
+			return
+		}
+
+		result, err := api.service.UpdateComment(ctx, authPayload.Sub, ctx.GetString("token"), ctx.Param("commentID"), req, reviewMediaFiles(ctx))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Sửa đánh giá thành công", result))
+	}
+}
+
+// reviewMediaFiles lấy file ảnh/video ở field "media", request JSON thì không có file
+func reviewMediaFiles(ctx *gin.Context) []*multipart.FileHeader {
+	if ctx.ContentType() != binding.MIMEMultipartPOSTForm {
+		return nil
+	}
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	return form.File["media"]
+}
+
+// replyComment handles PUT /api/v1/comments/:commentID/reply
+// Shop (shop lấy từ token của người bán) hoặc admin tạo/sửa phản hồi chính thức cho một đánh giá
+func (api *apiController) replyComment() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		// shop của người bán lấy từ token, không tin shop_id client gửi lên
+		shopID := authPayload.ShopId
+		if shopID == "" && authPayload.Scope == "ROLE_SELLER" {
+			ctx.JSON(http.StatusForbidden, assets_api.ResponseError(http.StatusForbidden, "tài khoản người bán chưa được gắn với shop nào"))
+			return
+		}
+
+		var req services.ReplyCommentRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
+			return
+		}
+
+		result, err := api.service.ReplyComment(ctx, authPayload.Sub, authPayload.Scope, shopID, ctx.Param("commentID"), req)
+		if err != nil {
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
 		}
 
-		ctx.JSON(http.StatusCreated, assets_api.SimpSuccessResponse("Đánh giá sản phẩm thành công", nil))
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Phản hồi đánh giá thành công", result))
 	}
 }
 
@@ -51,8 +121,16 @@ func (api *apiController) listComments() func(ctx *gin.Context) {
 		if req.Page <= 0 {
 			req.Page = 1
 		}
+		if req.Sort == "" {
+			req.Sort = "newest"
+		}
+		// Người xem đã đăng nhập thì trả thêm liked_by_me
+		userID := ""
+		if payload, ok := ctx.Get(authorizationPayload); ok {
+			userID = payload.(*token.Payload).Sub
+		}
 		// Call service để lấy comments
-		result, err := api.service.ListComments(ctx, req)
+		result, err := api.service.ListComments(ctx, userID, req)
 		if err != nil {
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
@@ -62,6 +140,105 @@ func (api *apiController) listComments() func(ctx *gin.Context) {
 	}
 }
 
+// toggleReviewLike handles POST /api/v1/comments/:commentID/helpful
+// Bấm hoặc bỏ bấm "Hữu ích" cho một đánh giá, trả về trạng thái và tổng lượt mới
+func (api *apiController) toggleReviewLike() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		result, err := api.service.ToggleReviewLike(ctx, authPayload.Sub, ctx.Param("commentID"))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Cập nhật lượt hữu ích thành công", result))
+	}
+}
+
+// reportComment handles POST /api/v1/comments/:commentID/report
+// Báo cáo đánh giá/phản hồi vi phạm kèm lý do
+func (api *apiController) reportComment() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req services.ReportCommentRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
+			return
+		}
+
+		if err := api.service.ReportComment(ctx, authPayload.Sub, ctx.Param("commentID"), req); err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, assets_api.SimpSuccessResponse("Đã gửi báo cáo đánh giá", nil))
+	}
+}
+
+// listModerationQueue handles GET /api/v1/comments/moderation
+// Admin xem hàng chờ kiểm duyệt: đánh giá chờ duyệt hoặc đang bị báo cáo
+func (api *apiController) listModerationQueue() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req services.ModerationQueueRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid query parameters: "+err.Error()))
+			return
+		}
+		if req.PageSize == 0 {
+			req.PageSize = 20
+		}
+		if req.Page <= 0 {
+			req.Page = 1
+		}
+
+		result, err := api.service.ListModerationQueue(ctx, req)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Lấy hàng chờ kiểm duyệt thành công", result))
+	}
+}
+
+// moderateComment handles PUT /api/v1/comments/:commentID/moderation
+// Admin ẩn hoặc hiện lại đánh giá, đóng các báo cáo đang mở
+func (api *apiController) moderateComment() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req services.ModerateCommentRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
+			return
+		}
+
+		result, err := api.service.ModerateComment(ctx, authPayload.Sub, ctx.Param("commentID"), req)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Cập nhật trạng thái đánh giá thành công", result))
+	}
+}
+
+// resyncProductRatings handles POST /api/v1/comments/rating-summary/resync
+// Admin đồng bộ lại điểm đánh giá của toàn bộ sản phẩm sang product service
+func (api *apiController) resyncProductRatings() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.ResyncProductRatings(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Đồng bộ điểm đánh giá sản phẩm thành công", result))
+	}
+}
+
 // checkReviewedItems handles POST /api/v1/comments/check-reviewed
 // Kiểm tra danh sách order_item_id nào đã được đánh giá
 // API này dành cho service khác (như order service) gọi để check trạng thái review
@@ -85,7 +262,7 @@ func (api *apiController) checkReviewedItems() func(ctx *gin.Context) {
 }
 
 // getBulkProductRatingStats handles POST /api/v1/comments/bulk-stats
-// Lấy thống kê đánh giá (điểm trung bình và tổng số lượt đánh giá) cho nhiều sản phẩm
+// Lấy thống kê đánh giá (điểm trung bình, tổng số lượt đánh giá và số đánh giá theo từng mức sao) cho nhiều sản phẩm
 // Trả về map với product_id là key
 func (api *apiController) getBulkProductRatingStats() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
@@ -105,3 +282,24 @@ func (api *apiController) getBulkProductRatingStats() func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Lấy thống kê đánh giá sản phẩm thành công", result))
 	}
 }
+
+// listCommentMediaReferences handles POST /api/v1/comments/media-references
+// Trả về các file media trong danh sách vẫn còn được bình luận sử dụng
+// API này dành cho product service gọi trước khi dọn file media mồ côi
+func (api *apiController) listCommentMediaReferences() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req services.CommentMediaReferencesRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "Invalid request body: "+err.Error()))
+			return
+		}
+
+		result, err := api.service.ListCommentMediaReferences(ctx, req)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("Kiểm tra media của bình luận thành công", result))
+	}
+}
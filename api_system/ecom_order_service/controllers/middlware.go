@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"strings"
 
@@ -43,6 +44,50 @@ func authorization(jwt token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// optionalAuthorization gắn payload vào context nếu request có token hợp lệ,
+// không có hoặc token lỗi thì vẫn cho đi tiếp như khách (dùng cho API public có dữ liệu riêng theo người xem)
+func optionalAuthorization(jwt token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		fields := strings.Fields(ctx.GetHeader(authorizationKey))
+		if len(fields) == 2 && strings.ToLower(fields[0]) == authorizationType {
+			if payload, err := jwt.VerifyToken(fields[1]); err == nil {
+				ctx.Set(authorizationPayload, payload)
+				ctx.Set("token", fields[1])
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// authorizationSystemOrAdmin cho phép service nội bộ gọi bằng TOKEN_SYSTEM, ngoài ra phải là admin.
+// tokenSystem rỗng (chưa cấu hình) thì chỉ admin được gọi.
+func authorizationSystemOrAdmin(jwt token.Maker, tokenSystem string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		fields := strings.Fields(ctx.GetHeader(authorizationKey))
+		if len(fields) < 2 || strings.ToLower(fields[0]) != authorizationType {
+			ctx.AbortWithStatusJSON(401, assets_api.ResponseError(401, "authorization header is not provided"))
+			return
+		}
+		if tokenSystem != "" && subtle.ConstantTimeCompare([]byte(fields[1]), []byte(tokenSystem)) == 1 {
+			ctx.Next()
+			return
+		}
+		payload, err := jwt.VerifyToken(fields[1])
+		if err != nil {
+			ctx.AbortWithStatusJSON(401, assets_api.ResponseError(401, "invalid access token: "+err.Error()))
+			return
+		}
+		if payload.Scope != "ROLE_ADMIN" {
+			ctx.AbortWithStatusJSON(403, assets_api.ResponseError(403, "không có quyền truy cập, chức năng này chỉ dành cho service nội bộ hoặc ROLE_ADMIN"))
+			return
+		}
+		ctx.Set(authorizationPayload, payload)
+		ctx.Set("token", fields[1])
+		ctx.Next()
+	}
+}
+
 func checkRole(roles []string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		payload, exists := ctx.Get(authorizationPayload)
@@ -40,6 +40,15 @@ func (api *apiController) createOrder() func(ctx *gin.Context) {
 	}
 }
 
+// withCursorQuery đọc tham số phân trang cursor: pagination=cursor cho trang đầu,
+// các trang sau gửi cursor nhận được; with_total=true để đếm tổng số đơn
+func withCursorQuery(ctx *gin.Context, query services.QueryFilter) services.QueryFilter {
+	query.Cursor = ctx.Query("cursor")
+	query.CursorMode = ctx.Query("pagination") == "cursor" || query.Cursor != ""
+	query.WithTotal = ctx.Query("with_total") == "true"
+	return query
+}
+
 // listUserOrders lấy danh sách đơn hàng của user hiện tại
 func (api *apiController) listUserOrders() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
@@ -59,7 +68,7 @@ func (api *apiController) listUserOrders() func(ctx *gin.Context) {
 			}
 		}
 
-		result, err := api.service.ListUserOrders(ctx, authPayload.Sub, services.NewQueryFilter(page, limit, nil, nil), status)
+		result, err := api.service.ListUserOrders(ctx, authPayload.Sub, withCursorQuery(ctx, services.NewQueryFilter(page, limit, nil, nil)), status)
 		if err != nil {
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
@@ -297,10 +306,10 @@ func (api *apiController) listShopOrders() func(ctx *gin.Context) {
 			status = statusParam
 		}
 
-		result, err := api.service.ListShopOrders(ctx, shopID, status, services.QueryFilter{
+		result, err := api.service.ListShopOrders(ctx, shopID, status, withCursorQuery(ctx, services.QueryFilter{
 			Page:     page,
 			PageSize: limit,
-		})
+		}))
 		if err != nil {
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
@@ -8,12 +8,13 @@ import (
 )
 
 type apiController struct {
-	service services.ServiceUseCase
-	jwt     token.Maker
+	service     services.ServiceUseCase
+	jwt         token.Maker
+	tokenSystem string
 }
 
-func NewAPIController(s services.ServiceUseCase, jwt token.Maker) apiController {
-	return apiController{service: s, jwt: jwt}
+func NewAPIController(s services.ServiceUseCase, jwt token.Maker, tokenSystem string) apiController {
+	return apiController{service: s, jwt: jwt, tokenSystem: tokenSystem}
 }
 
 func (api apiController) SetUpRoute(group *gin.RouterGroup) {
@@ -109,17 +110,41 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 	// =================================================================
 	comments := group.Group("/comments")
 	{
-		// GET /api/v1/comments - Lấy danh sách comment cho sản phẩm (public, không cần auth)
-		comments.GET("", api.listComments())
+		// GET /api/v1/comments - Lấy danh sách comment cho sản phẩm (public, có token thì trả thêm liked_by_me)
+		comments.GET("", optionalAuthorization(api.jwt), api.listComments())
 		// POST /api/v1/comments/check-reviewed - Check các order items đã review chưa
 		comments.POST("/check-reviewed", api.checkReviewedItems())
 		// POST /api/v1/comments/bulk-stats - Lấy thống kê đánh giá cho nhiều sản phẩm
 		comments.POST("/bulk-stats", api.getBulkProductRatingStats())
+		// POST /api/v1/comments/media-references - Các file media còn được bình luận sử dụng (chỉ service nội bộ hoặc admin)
+		comments.POST("/media-references", authorizationSystemOrAdmin(api.jwt, api.tokenSystem), api.listCommentMediaReferences())
 
 		comments_auth := comments.Use(authorization(api.jwt))
 		{
 			// POST /api/v1/comments - Tạo đánh giá sản phẩm (cần auth)
 			comments_auth.POST("", api.createComment())
+			// POST /api/v1/comments/:commentID/helpful - Bấm/bỏ bấm "Hữu ích" cho đánh giá
+			comments_auth.POST("/:commentID/helpful", api.toggleReviewLike())
+			// PUT /api/v1/comments/:commentID - Người mua sửa đánh giá của mình (1 lần, trong thời hạn cho phép)
+			comments_auth.PUT("/:commentID", api.updateComment())
+			// POST /api/v1/comments/:commentID/report - Báo cáo đánh giá/phản hồi vi phạm
+			comments_auth.POST("/:commentID/report", api.reportComment())
+
+			comment_reply := comments_auth.Use(checkRole([]string{"ROLE_ADMIN", "ROLE_SELLER"}))
+			{
+				// PUT /api/v1/comments/:commentID/reply - Shop/Admin tạo hoặc sửa phản hồi cho đánh giá
+				comment_reply.PUT("/:commentID/reply", api.replyComment())
+			}
+
+			comment_admin := comments_auth.Use(checkRole([]string{"ROLE_ADMIN"}))
+			{
+				// GET /api/v1/comments/moderation - Hàng chờ kiểm duyệt (đánh giá chờ duyệt hoặc bị báo cáo)
+				comment_admin.GET("/moderation", api.listModerationQueue())
+				// PUT /api/v1/comments/:commentID/moderation - Ẩn/hiện đánh giá, đóng các báo cáo đang mở
+				comment_admin.PUT("/:commentID/moderation", api.moderateComment())
+				// POST /api/v1/comments/rating-summary/resync - Đồng bộ lại toàn bộ điểm đánh giá sang product service
+				comment_admin.POST("/rating-summary/resync", api.resyncProductRatings())
+			}
 		}
 	}
 }
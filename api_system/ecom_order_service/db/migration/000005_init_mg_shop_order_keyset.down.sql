@@ -0,0 +1,2 @@
+DROP INDEX idx_shop_orders_created ON shop_orders;
+DROP INDEX idx_shop_orders_shop_created ON shop_orders;
@@ -0,0 +1,7 @@
+-- =================================================================
+-- Phân trang đơn hàng theo cursor (keyset)
+-- Danh sách đơn của shop sắp xếp theo (created_at, id) giảm dần; index phụ của
+-- InnoDB đã kèm khóa chính id nên đọc thẳng theo thứ tự index, không cần OFFSET.
+-- =================================================================
+CREATE INDEX idx_shop_orders_shop_created ON shop_orders(shop_id, created_at);
+CREATE INDEX idx_shop_orders_created ON shop_orders(created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE `product_comment` DROP INDEX `uq_parent_id`;
+DELETE FROM `product_comment` WHERE `order_item_id` IS NULL;
+ALTER TABLE `product_comment`
+  MODIFY COLUMN `order_item_id` CHAR(36) NOT NULL COMMENT 'UUID của order_items.id từ Order Service. Đây là "vé" để review.';
@@ -0,0 +1,9 @@
+-- =================================================================
+-- Phản hồi chính thức của Shop/Admin cho đánh giá
+-- Phản hồi lưu chung bảng product_comment với parent_id = đánh giá gốc, không gắn với
+-- order_item nào nên order_item_id được phép NULL (UNIQUE vẫn cho nhiều giá trị NULL).
+-- Mỗi đánh giá chỉ có đúng 1 phản hồi, sửa phản hồi thì cập nhật lại dòng này.
+-- =================================================================
+ALTER TABLE `product_comment`
+  MODIFY COLUMN `order_item_id` CHAR(36) DEFAULT NULL COMMENT 'UUID của order_items.id từ Order Service. Đây là "vé" để review. NULL với phản hồi của Shop/Admin',
+  ADD UNIQUE KEY `uq_parent_id` (`parent_id`);
@@ -0,0 +1,18 @@
+-- =================================================================
+-- Lịch sử sửa đánh giá
+-- Người mua được sửa đánh giá 1 lần trong thời hạn cho phép, nội dung trước khi sửa được lưu lại ở đây.
+-- =================================================================
+CREATE TABLE `product_comment_history` (
+  `id` CHAR(36) NOT NULL COMMENT 'UUID, Khóa chính',
+  `comment_id` CHAR(36) NOT NULL COMMENT 'FK tới product_comment.comment_id',
+  `rating` TINYINT NOT NULL COMMENT 'Điểm đánh giá trước khi sửa',
+  `title` NVARCHAR(255) DEFAULT NULL COMMENT 'Tiêu đề trước khi sửa',
+  `content` TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci COMMENT 'Nội dung trước khi sửa',
+  `media` TEXT COMMENT 'Mảng JSON media trước khi sửa',
+  `edited_by` CHAR(36) NOT NULL COMMENT 'Người sửa',
+  `edited_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`id`),
+  KEY `idx_comment_history_comment` (`comment_id`, `edited_at`),
+  CONSTRAINT `fk_comment_history_comment` FOREIGN KEY (`comment_id`) REFERENCES `product_comment` (`comment_id`) ON DELETE CASCADE
+) ENGINE=InnoDB COMMENT='Lịch sử sửa đánh giá sản phẩm';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `comment_reports`;
+ALTER TABLE `product_comment`
+  DROP INDEX `idx_product_status`,
+  DROP COLUMN `status`;
@@ -0,0 +1,28 @@
+-- =================================================================
+-- Kiểm duyệt đánh giá
+-- status: VISIBLE hiển thị bình thường, HIDDEN bị admin ẩn, PENDING chờ admin duyệt (dính từ ngữ cấm).
+-- Chỉ đánh giá VISIBLE được hiển thị và được tính vào điểm đánh giá của sản phẩm.
+-- =================================================================
+ALTER TABLE `product_comment`
+  ADD COLUMN `status` ENUM('VISIBLE', 'HIDDEN', 'PENDING') NOT NULL DEFAULT 'VISIBLE' COMMENT 'Trạng thái kiểm duyệt của đánh giá',
+  ADD KEY `idx_product_status` (`product_id`, `status`);
+
+-- =================================================================
+-- Báo cáo đánh giá vi phạm từ người dùng, mỗi người chỉ báo cáo 1 đánh giá 1 lần
+-- =================================================================
+CREATE TABLE `comment_reports` (
+  `id` CHAR(36) NOT NULL COMMENT 'UUID, Khóa chính',
+  `comment_id` CHAR(36) NOT NULL COMMENT 'FK tới product_comment.comment_id',
+  `reporter_id` CHAR(36) NOT NULL COMMENT 'UUID của người báo cáo',
+  `reason` ENUM('SPAM', 'OFFENSIVE', 'FALSE_INFO', 'UNRELATED', 'OTHER') NOT NULL COMMENT 'Lý do báo cáo',
+  `note` VARCHAR(500) DEFAULT NULL COMMENT 'Mô tả thêm của người báo cáo',
+  `status` ENUM('OPEN', 'RESOLVED', 'DISMISSED') NOT NULL DEFAULT 'OPEN' COMMENT 'OPEN chờ xử lý, RESOLVED đã ẩn đánh giá, DISMISSED admin giữ lại đánh giá',
+  `resolved_by` CHAR(36) DEFAULT NULL COMMENT 'Admin xử lý báo cáo',
+  `resolved_at` TIMESTAMP NULL DEFAULT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_comment_reporter` (`comment_id`, `reporter_id`),
+  KEY `idx_report_status` (`status`, `comment_id`),
+  CONSTRAINT `fk_reports_comment` FOREIGN KEY (`comment_id`) REFERENCES `product_comment` (`comment_id`) ON DELETE CASCADE
+) ENGINE=InnoDB COMMENT='Báo cáo đánh giá vi phạm';
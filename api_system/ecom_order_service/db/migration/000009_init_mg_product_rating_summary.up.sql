@@ -0,0 +1,39 @@
+-- =================================================================
+-- Tổng hợp điểm đánh giá theo sản phẩm
+-- Được tính lại trong cùng transaction mỗi khi đánh giá được tạo, sửa hoặc ẩn/hiện,
+-- để API bulk-stats (gọi ở mỗi lần product service lấy danh sách sản phẩm) chỉ cần đọc theo khóa chính.
+-- Chỉ tính đánh giá gốc đang hiển thị (parent_id IS NULL, status = 'VISIBLE').
+-- bayesian_score = (C * m + tổng số sao) / (C + số đánh giá), m là điểm trung bình toàn sàn,
+-- C là REVIEW_BAYES_WEIGHT (mặc định 10). Sản phẩm chưa có đánh giá có điểm 0.
+-- =================================================================
+CREATE TABLE `product_rating_summary` (
+  `product_id` VARCHAR(36) NOT NULL COMMENT 'FK (logic) tới product.id',
+  `star_1` INT NOT NULL DEFAULT 0 COMMENT 'Số đánh giá 1 sao',
+  `star_2` INT NOT NULL DEFAULT 0 COMMENT 'Số đánh giá 2 sao',
+  `star_3` INT NOT NULL DEFAULT 0 COMMENT 'Số đánh giá 3 sao',
+  `star_4` INT NOT NULL DEFAULT 0 COMMENT 'Số đánh giá 4 sao',
+  `star_5` INT NOT NULL DEFAULT 0 COMMENT 'Số đánh giá 5 sao',
+  `total_reviews` INT NOT NULL DEFAULT 0 COMMENT 'Tổng số đánh giá',
+  `average_rating` DOUBLE NOT NULL DEFAULT 0 COMMENT 'Điểm trung bình',
+  `bayesian_score` DOUBLE NOT NULL DEFAULT 0 COMMENT 'Điểm đã hiệu chỉnh theo số lượng đánh giá, dùng để xếp hạng',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (`product_id`)
+) ENGINE=InnoDB COMMENT='Tổng hợp điểm đánh giá theo sản phẩm';
+
+-- Dữ liệu cũ
+INSERT INTO `product_rating_summary` (
+  product_id, star_1, star_2, star_3, star_4, star_5, total_reviews, average_rating, bayesian_score
+)
+SELECT
+  pc.product_id,
+  SUM(pc.rating = 1), SUM(pc.rating = 2), SUM(pc.rating = 3), SUM(pc.rating = 4), SUM(pc.rating = 5),
+  COUNT(*),
+  AVG(pc.rating),
+  (10 * g.mean + SUM(pc.rating)) / (10 + COUNT(*))
+FROM product_comment pc
+CROSS JOIN (
+  SELECT AVG(rating) AS mean FROM product_comment WHERE parent_id IS NULL AND status = 'VISIBLE'
+) g
+WHERE pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+GROUP BY pc.product_id, g.mean;
@@ -0,0 +1,7 @@
+-- =================================================================
+-- Lưu danh mục sản phẩm tại thời điểm mua vào order_items
+-- để analytics service tổng hợp doanh số theo danh mục mà không cần đọc product_db.
+-- Các dòng cũ để NULL (không xác định danh mục).
+-- =================================================================
+ALTER TABLE `order_items`
+  ADD COLUMN `category_id` VARCHAR(36) DEFAULT NULL COMMENT 'Danh mục của sản phẩm tại thời điểm mua' AFTER `sku_attributes_snapshot`;
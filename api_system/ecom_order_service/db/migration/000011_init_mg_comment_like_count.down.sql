@@ -0,0 +1,3 @@
+ALTER TABLE `product_comment`
+  DROP INDEX `idx_product_status_likes`,
+  DROP COLUMN `like_count`;
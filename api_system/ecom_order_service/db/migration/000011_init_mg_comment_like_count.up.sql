@@ -0,0 +1,13 @@
+-- =================================================================
+-- Lưu sẵn số lượt "Hữu ích" trên product_comment để sắp xếp most_helpful
+-- không phải đếm review_likes cho từng dòng. Cập nhật cùng transaction với review_likes.
+-- =================================================================
+ALTER TABLE `product_comment`
+  ADD COLUMN `like_count` INT NOT NULL DEFAULT 0 COMMENT 'Số lượt "Hữu ích" của đánh giá' AFTER `status`,
+  ADD KEY `idx_product_status_likes` (`product_id`, `status`, `like_count`);
+
+UPDATE `product_comment` pc
+JOIN (
+  SELECT review_id, COUNT(*) AS total FROM review_likes GROUP BY review_id
+) rl ON rl.review_id = pc.comment_id
+SET pc.like_count = rl.total;
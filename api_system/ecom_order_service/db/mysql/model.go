@@ -2,6 +2,9 @@ package db
 
 import (
 	"database/sql"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
 )
 
 type ProductSkusDetailss struct {
@@ -18,3 +21,15 @@ type ProductSkusDetailss struct {
 	Image            string        `json:"image"`
 	InfoProduct      string        `json:"info_sku_attr"`
 }
+
+// ShopOrderKeysetParams tham số lấy danh sách shop_orders theo cursor, sắp xếp (created_at, id) giảm dần.
+// Lọc theo UserID (đơn của người mua) hoặc ShopID (đơn của shop). AfterID rỗng là trang đầu.
+type ShopOrderKeysetParams struct {
+	UserID         sql.NullString
+	ShopID         sql.NullString
+	Status         db.NullShopOrdersStatus
+	AfterCreatedAt time.Time
+	AfterID        string
+	Backward       bool // true: lấy các đơn mới hơn bản ghi mốc (trang trước)
+	Limit          int32
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"strings"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+)
+
+// ListShopOrdersKeyset lấy danh sách shop_orders theo cursor thay cho LIMIT/OFFSET.
+// Khi Backward, kết quả được đọc theo chiều tăng dần, service sẽ đảo lại.
+func (q *SQLStore) ListShopOrdersKeyset(ctx context.Context, params ShopOrderKeysetParams) ([]db.ShopOrders, error) {
+	query := `
+		SELECT
+			so.id, so.shop_order_code, so.order_id, so.shop_id, so.status,
+			so.subtotal, so.total_discount, so.total_amount,
+			so.shop_voucher_code, so.shop_voucher_discount, so.shipping_fee,
+			so.shipping_method, so.tracking_code, so.cancellation_reason,
+			so.created_at, so.updated_at, so.paid_at, so.processing_at,
+			so.shipped_at, so.completed_at, so.cancelled_at
+		FROM shop_orders so
+	`
+	var conditions []string
+	var args []interface{}
+	if params.UserID.Valid {
+		query += " JOIN orders o ON so.order_id = o.id"
+		conditions = append(conditions, "o.user_id = ?")
+		args = append(args, params.UserID.String)
+	}
+	if params.ShopID.Valid {
+		conditions = append(conditions, "so.shop_id = ?")
+		args = append(args, params.ShopID.String)
+	}
+	if params.Status.Valid {
+		conditions = append(conditions, "so.status = ?")
+		args = append(args, params.Status.ShopOrdersStatus)
+	}
+	op, dir := "<", "DESC"
+	if params.Backward {
+		op, dir = ">", "ASC"
+	}
+	if params.AfterID != "" {
+		conditions = append(conditions, "(so.created_at "+op+" ? OR (so.created_at = ? AND so.id "+op+" ?))")
+		args = append(args, params.AfterCreatedAt, params.AfterCreatedAt, params.AfterID)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY so.created_at " + dir + ", so.id " + dir + " LIMIT ?"
+	args = append(args, params.Limit)
+
+	rows, err := q.connPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []db.ShopOrders
+	for rows.Next() {
+		var i db.ShopOrders
+		if err := rows.Scan(
+			&i.ID, &i.ShopOrderCode, &i.OrderID, &i.ShopID, &i.Status,
+			&i.Subtotal, &i.TotalDiscount, &i.TotalAmount,
+			&i.ShopVoucherCode, &i.ShopVoucherDiscount, &i.ShippingFee,
+			&i.ShippingMethod, &i.TrackingCode, &i.CancellationReason,
+			&i.CreatedAt, &i.UpdatedAt, &i.PaidAt, &i.ProcessingAt,
+			&i.ShippedAt, &i.CompletedAt, &i.CancelledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -17,6 +17,7 @@ type SQLStore struct {
 type Store interface {
 	db.Querier
 	ExecTS(ctx context.Context, fn func(tx db.Querier) error) error
+	ListShopOrdersKeyset(ctx context.Context, params ShopOrderKeysetParams) ([]db.ShopOrders, error)
 }
 
 // create new store
@@ -7,9 +7,9 @@
 -- name: CreateOrderItem :exec
 INSERT INTO order_items (
   id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price,
-  promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot
+  promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot, category_id
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: ListOrderItemsByShopOrderID :many
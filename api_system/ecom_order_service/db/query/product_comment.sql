@@ -10,9 +10,10 @@ INSERT INTO product_comment (
   title,
   content,
   media,
-  parent_id
+  parent_id,
+  status
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: GetCommentByOrderItemID :one
@@ -21,40 +22,76 @@ SELECT * FROM product_comment
 WHERE order_item_id = ?;
 
 -- name: ListCommentsByProduct :many
--- Lấy danh sách bình luận (gốc, không phải trả lời) cho một sản phẩm, hỗ trợ phân trang.
-SELECT * FROM product_comment
-WHERE product_id = ? 
-ORDER BY created_at DESC
+-- Lấy danh sách bình luận (gốc, không phải trả lời, đang hiển thị) cho một sản phẩm, hỗ trợ lọc, sắp xếp và phân trang.
+-- sort_by: most_helpful | rating_high | rating_low, còn lại (newest) sắp xếp theo thời gian mới nhất.
+SELECT pc.*
+FROM product_comment pc
+WHERE pc.product_id = sqlc.arg('product_id') AND pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+  AND (sqlc.narg('rating') IS NULL OR pc.rating = sqlc.narg('rating'))
+  AND (sqlc.narg('sku_id') IS NULL OR pc.sku_id = sqlc.narg('sku_id'))
+  -- media lưu dạng mảng JSON, chuỗi rỗng hoặc [] coi như không có media
+  AND (sqlc.arg('has_media') = FALSE OR (pc.media IS NOT NULL AND pc.media NOT IN ('', '[]')))
+ORDER BY
+  CASE WHEN sqlc.arg('sort_by') = 'most_helpful' THEN pc.like_count END DESC,
+  CASE WHEN sqlc.arg('sort_by') = 'rating_high' THEN pc.rating END DESC,
+  CASE WHEN sqlc.arg('sort_by') = 'rating_low' THEN pc.rating END ASC,
+  pc.created_at DESC,
+  pc.comment_id DESC
 LIMIT ? OFFSET ?;
 
+-- name: CountCommentsByProduct :one
+-- Đếm số bình luận gốc của sản phẩm theo cùng bộ lọc với ListCommentsByProduct (dùng cho phân trang).
+SELECT COUNT(*) FROM product_comment pc
+WHERE pc.product_id = sqlc.arg('product_id') AND pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+  AND (sqlc.narg('rating') IS NULL OR pc.rating = sqlc.narg('rating'))
+  AND (sqlc.narg('sku_id') IS NULL OR pc.sku_id = sqlc.narg('sku_id'))
+  AND (sqlc.arg('has_media') = FALSE OR (pc.media IS NOT NULL AND pc.media NOT IN ('', '[]')));
+
+-- name: GetCommentByID :one
+SELECT * FROM product_comment
+WHERE comment_id = ?;
+
 
 -- name: GetProductRatingStats :one
 -- Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho một sản phẩm.
--- Chỉ tính các bình luận gốc (parent_id IS NULL).
+-- Chỉ tính các bình luận gốc (parent_id IS NULL) đang hiển thị, đánh giá bị ẩn/chờ duyệt không được tính.
 SELECT
   COUNT(*) AS total_reviews,
   AVG(rating) AS average_rating
 FROM product_comment
-WHERE product_id = ? AND parent_id IS NULL;
+WHERE product_id = ? AND parent_id IS NULL AND status = 'VISIBLE';
 
--- name: CreateReviewLike :exec
--- Thêm một lượt "Hữu ích" cho review
+-- name: CreateReviewLike :execrows
+-- Thêm một lượt "Hữu ích" cho review. Bấm trùng (hai request đồng thời) không lỗi,
+-- trả về 0 dòng để logic code không tăng like_count hai lần.
 INSERT INTO review_likes (
   review_id, user_id
 ) VALUES (
   ?, ?
-);
+)
+ON DUPLICATE KEY UPDATE review_id = review_id;
 
--- name: DeleteReviewLike :exec
--- Bỏ lượt "Hữu ích"
+-- name: DeleteReviewLike :execrows
+-- Bỏ lượt "Hữu ích", trả về 0 dòng nếu lượt bấm đã bị xóa trước đó
 DELETE FROM review_likes
 WHERE review_id = ? AND user_id = ?;
 
+-- name: AdjustCommentLikeCount :exec
+-- Cộng/trừ số lượt "Hữu ích" lưu trên đánh giá, không để xuống dưới 0
+UPDATE product_comment
+SET like_count = GREATEST(like_count + sqlc.arg('delta'), 0)
+WHERE comment_id = sqlc.arg('comment_id');
+
 -- name: CountReviewLikes :one
 -- Đếm số lượt "Hữu ích" của một review
 SELECT COUNT(*) FROM review_likes
 WHERE review_id = ?;
 
+-- name: ListReviewsLikedByUser :many
+-- Trong danh sách review, trả về những review mà user đã bấm "Hữu ích"
+SELECT review_id FROM review_likes
+WHERE user_id = sqlc.arg('user_id') AND review_id IN (sqlc.slice('review_ids'));
+
 -- name: CheckBulkOrderItemsReviewed :many
 -- Kiểm tra danh sách order_item_id đã được review chưa
 -- Chỉ trả về những order_item_id đã có bình luận
@@ -67,13 +104,100 @@ SELECT * FROM product_comment
 WHERE parent_id = ?
 ORDER BY created_at ASC;
 
--- name: GetBulkProductRatingStats :many
--- Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho nhiều sản phẩm
--- Chỉ tính các bình luận gốc (parent_id IS NULL).
+-- name: GetReviewForReply :one
+-- Lấy đánh giá gốc kèm shop đã bán sản phẩm (qua order_items -> shop_orders) để kiểm tra quyền phản hồi.
+-- Phản hồi không có order_item_id nên không bao giờ khớp, tức là không thể phản hồi một phản hồi.
 SELECT
-  product_id,
-  COUNT(*) AS total_reviews,
-  AVG(rating) AS average_rating
-FROM product_comment
-WHERE product_id IN (sqlc.slice('product_ids')) AND parent_id IS NULL
-GROUP BY product_id;
\ No newline at end of file
+  pc.comment_id,
+  pc.product_id,
+  pc.sku_id,
+  pc.user_id,
+  pc.status,
+  so.shop_id
+FROM product_comment pc
+JOIN order_items oi ON oi.id = pc.order_item_id
+JOIN shop_orders so ON so.id = oi.shop_order_id
+WHERE pc.comment_id = ? AND pc.parent_id IS NULL;
+
+-- name: UpdateCommentReply :exec
+-- Sửa nội dung phản hồi của Shop/Admin, ghi nhận người sửa cuối cùng.
+-- status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+UPDATE product_comment
+SET content = ?, user_id = ?, status = ?
+WHERE comment_id = ? AND parent_id IS NOT NULL;
+
+-- name: CountCommentsWithMedia :one
+-- Số bình luận (kể cả lịch sử sửa) có chứa file media (media lưu dạng mảng JSON tên file/URL)
+SELECT
+  (SELECT COUNT(*) FROM product_comment WHERE media LIKE CONCAT('%', sqlc.arg('file_name'), '%'))
+  + (SELECT COUNT(*) FROM product_comment_history WHERE media LIKE CONCAT('%', sqlc.arg('file_name'), '%')) AS total;
+
+-- name: UpdateCommentByBuyer :exec
+-- Người mua sửa đánh giá gốc của mình, status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+UPDATE product_comment
+SET rating = ?, title = ?, content = ?, media = ?, status = ?
+WHERE comment_id = ? AND parent_id IS NULL;
+
+-- name: CreateCommentHistory :execrows
+-- Lưu lại nội dung hiện tại của đánh giá trước khi sửa, chỉ khi đánh giá chưa từng được sửa.
+-- Mỗi đánh giá chỉ được sửa 1 lần: code phải kiểm tra RowsAffected() == 1 rồi mới cập nhật đánh giá.
+INSERT INTO product_comment_history (
+  id, comment_id, rating, title, content, media, edited_by
+)
+SELECT sqlc.arg('id'), pc.comment_id, pc.rating, pc.title, pc.content, pc.media, sqlc.arg('edited_by')
+FROM product_comment pc
+WHERE pc.comment_id = sqlc.arg('comment_id')
+  AND NOT EXISTS (SELECT 1 FROM product_comment_history h WHERE h.comment_id = pc.comment_id);
+
+-- name: CountCommentHistory :one
+-- Số lần đánh giá đã được sửa
+SELECT COUNT(*) FROM product_comment_history
+WHERE comment_id = ?;
+
+-- =================================================================
+-- Kiểm duyệt đánh giá
+-- =================================================================
+
+-- name: CreateCommentReport :exec
+-- Người dùng báo cáo đánh giá vi phạm, uq_comment_reporter chặn báo cáo trùng
+INSERT INTO comment_reports (
+  id, comment_id, reporter_id, reason, note
+) VALUES (
+  ?, ?, ?, ?, ?
+);
+
+-- name: ListModerationQueue :many
+-- Hàng chờ kiểm duyệt của admin: đánh giá chờ duyệt (PENDING) hoặc còn báo cáo chưa xử lý.
+-- Đánh giá bị báo cáo nhiều xếp trước, cùng số báo cáo thì cũ hơn xếp trước.
+SELECT
+  pc.*,
+  (SELECT COUNT(*) FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN') AS open_reports
+FROM product_comment pc
+WHERE pc.status = 'PENDING'
+  OR EXISTS (SELECT 1 FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN')
+ORDER BY open_reports DESC, pc.created_at ASC, pc.comment_id ASC
+LIMIT ? OFFSET ?;
+
+-- name: CountModerationQueue :one
+-- Đếm số đánh giá trong hàng chờ kiểm duyệt (cùng điều kiện với ListModerationQueue)
+SELECT COUNT(*) FROM product_comment pc
+WHERE pc.status = 'PENDING'
+  OR EXISTS (SELECT 1 FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN');
+
+-- name: ListOpenReportsByComments :many
+-- Các báo cáo chưa xử lý của những đánh giá trong trang hàng chờ
+SELECT * FROM comment_reports
+WHERE status = 'OPEN' AND comment_id IN (sqlc.slice('comment_ids'))
+ORDER BY created_at ASC;
+
+-- name: UpdateCommentStatus :exec
+-- Admin ẩn/hiện đánh giá
+UPDATE product_comment
+SET status = ?
+WHERE comment_id = ?;
+
+-- name: ResolveCommentReports :execrows
+-- Đóng các báo cáo đang mở của một đánh giá sau khi admin xử lý
+UPDATE comment_reports
+SET status = ?, resolved_by = ?, resolved_at = NOW()
+WHERE comment_id = ? AND status = 'OPEN';
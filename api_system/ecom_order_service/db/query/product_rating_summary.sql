@@ -0,0 +1,53 @@
+-- name: RefreshProductRatingSummary :exec
+-- Tính lại tổng hợp điểm đánh giá của một sản phẩm từ các đánh giá gốc đang hiển thị.
+-- Gọi trong cùng transaction với thao tác làm thay đổi đánh giá (tạo, sửa, ẩn/hiện).
+-- prior_mean là điểm trung bình toàn sàn, prior_weight là số đánh giá "ảo" kéo điểm về prior_mean.
+INSERT INTO product_rating_summary (
+  product_id, star_1, star_2, star_3, star_4, star_5, total_reviews, average_rating, bayesian_score
+)
+SELECT
+  sqlc.arg('product_id'),
+  COALESCE(SUM(pc.rating = 1), 0),
+  COALESCE(SUM(pc.rating = 2), 0),
+  COALESCE(SUM(pc.rating = 3), 0),
+  COALESCE(SUM(pc.rating = 4), 0),
+  COALESCE(SUM(pc.rating = 5), 0),
+  COUNT(*),
+  COALESCE(AVG(pc.rating), 0),
+  CASE WHEN COUNT(*) = 0 THEN 0
+    ELSE (sqlc.arg('prior_weight') * sqlc.arg('prior_mean') + SUM(pc.rating)) / (sqlc.arg('prior_weight') + COUNT(*))
+  END
+FROM product_comment pc
+WHERE pc.product_id = sqlc.arg('product_id') AND pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+ON DUPLICATE KEY UPDATE
+  star_1 = VALUES(star_1),
+  star_2 = VALUES(star_2),
+  star_3 = VALUES(star_3),
+  star_4 = VALUES(star_4),
+  star_5 = VALUES(star_5),
+  total_reviews = VALUES(total_reviews),
+  average_rating = VALUES(average_rating),
+  bayesian_score = VALUES(bayesian_score);
+
+-- name: GetRatingGlobalStats :one
+-- Tổng số sao và tổng số đánh giá toàn sàn, dùng tính điểm trung bình làm prior cho bayesian_score
+SELECT
+  CAST(COALESCE(SUM(star_1 + 2 * star_2 + 3 * star_3 + 4 * star_4 + 5 * star_5), 0) AS SIGNED) AS rating_sum,
+  CAST(COALESCE(SUM(total_reviews), 0) AS SIGNED) AS total_reviews
+FROM product_rating_summary;
+
+-- name: GetProductRatingSummary :one
+SELECT * FROM product_rating_summary
+WHERE product_id = ?;
+
+-- name: GetBulkProductRatingSummary :many
+-- Tổng hợp điểm đánh giá của nhiều sản phẩm (sản phẩm chưa từng có đánh giá không có dòng nào)
+SELECT * FROM product_rating_summary
+WHERE product_id IN (sqlc.slice('product_ids'));
+
+-- name: ListProductRatingSummaries :many
+-- Duyệt toàn bộ bảng tổng hợp theo product_id (đồng bộ lại sang product service)
+SELECT * FROM product_rating_summary
+WHERE product_id > ?
+ORDER BY product_id
+LIMIT ?;
@@ -12,6 +12,137 @@ import (
 	"time"
 )
 
+type CommentReportsReason string
+
+const (
+	CommentReportsReasonSPAM      CommentReportsReason = "SPAM"
+	CommentReportsReasonOFFENSIVE CommentReportsReason = "OFFENSIVE"
+	CommentReportsReasonFALSEINFO CommentReportsReason = "FALSE_INFO"
+	CommentReportsReasonUNRELATED CommentReportsReason = "UNRELATED"
+	CommentReportsReasonOTHER     CommentReportsReason = "OTHER"
+)
+
+func (e *CommentReportsReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CommentReportsReason(s)
+	case string:
+		*e = CommentReportsReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CommentReportsReason: %T", src)
+	}
+	return nil
+}
+
+type NullCommentReportsReason struct {
+	CommentReportsReason CommentReportsReason `json:"comment_reports_reason"`
+	Valid                bool                 `json:"valid"` // Valid is true if CommentReportsReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCommentReportsReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.CommentReportsReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CommentReportsReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCommentReportsReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CommentReportsReason), nil
+}
+
+type CommentReportsStatus string
+
+const (
+	CommentReportsStatusOPEN      CommentReportsStatus = "OPEN"
+	CommentReportsStatusRESOLVED  CommentReportsStatus = "RESOLVED"
+	CommentReportsStatusDISMISSED CommentReportsStatus = "DISMISSED"
+)
+
+func (e *CommentReportsStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CommentReportsStatus(s)
+	case string:
+		*e = CommentReportsStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CommentReportsStatus: %T", src)
+	}
+	return nil
+}
+
+type NullCommentReportsStatus struct {
+	CommentReportsStatus CommentReportsStatus `json:"comment_reports_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if CommentReportsStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCommentReportsStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.CommentReportsStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CommentReportsStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCommentReportsStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CommentReportsStatus), nil
+}
+
+type ProductCommentStatus string
+
+const (
+	ProductCommentStatusVISIBLE ProductCommentStatus = "VISIBLE"
+	ProductCommentStatusHIDDEN  ProductCommentStatus = "HIDDEN"
+	ProductCommentStatusPENDING ProductCommentStatus = "PENDING"
+)
+
+func (e *ProductCommentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProductCommentStatus(s)
+	case string:
+		*e = ProductCommentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProductCommentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullProductCommentStatus struct {
+	ProductCommentStatus ProductCommentStatus `json:"product_comment_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if ProductCommentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProductCommentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProductCommentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProductCommentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProductCommentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProductCommentStatus), nil
+}
+
 type ShopOrdersStatus string
 
 const (
@@ -269,6 +400,26 @@ func (ns NullVouchersOwnerType) Value() (driver.Value, error) {
 	return string(ns.VouchersOwnerType), nil
 }
 
+// Báo cáo đánh giá vi phạm
+type CommentReports struct {
+	// UUID, Khóa chính
+	ID string `json:"id"`
+	// FK tới product_comment.comment_id
+	CommentID string `json:"comment_id"`
+	// UUID của người báo cáo
+	ReporterID string `json:"reporter_id"`
+	// Lý do báo cáo
+	Reason CommentReportsReason `json:"reason"`
+	// Mô tả thêm của người báo cáo
+	Note sql.NullString `json:"note"`
+	// OPEN chờ xử lý, RESOLVED đã ẩn đánh giá, DISMISSED admin giữ lại đánh giá
+	Status CommentReportsStatus `json:"status"`
+	// Admin xử lý báo cáo
+	ResolvedBy sql.NullString `json:"resolved_by"`
+	ResolvedAt sql.NullTime   `json:"resolved_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
 // Bảng chứa các sản phẩm chi tiết trong một đơn hàng của shop
 type OrderItems struct {
 	// UUID, Khóa chính của item
@@ -295,6 +446,8 @@ type OrderItems struct {
 	ProductImageSnapshot sql.NullString `json:"product_image_snapshot"`
 	// Các thuộc tính của SKU (Màu, Size...) tại thời điểm mua
 	SkuAttributesSnapshot sql.NullString `json:"sku_attributes_snapshot"`
+	// Danh mục của sản phẩm tại thời điểm mua
+	CategoryID sql.NullString `json:"category_id"`
 }
 
 // Bảng chứa các đơn hàng tổng của khách hàng (một lần checkout)
@@ -337,8 +490,8 @@ type Orders struct {
 type ProductComment struct {
 	// UUID, Khóa chính của đánh giá
 	CommentID string `json:"comment_id"`
-	// UUID của order_items.id từ Order Service. Đây là "vé" để review.
-	OrderItemID string `json:"order_item_id"`
+	// UUID của order_items.id từ Order Service. Đây là "vé" để review. NULL với phản hồi của Shop/Admin
+	OrderItemID sql.NullString `json:"order_item_id"`
 	// FK (logic) tới product.id. Dùng để tra cứu nhanh.
 	ProductID string `json:"product_id"`
 	// FK (logic) tới product_sku.id. Dùng để tra cứu nhanh.
@@ -359,6 +512,52 @@ type ProductComment struct {
 	ParentID  sql.NullString `json:"parent_id"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+	// Trạng thái kiểm duyệt của đánh giá
+	Status ProductCommentStatus `json:"status"`
+	// Số lượt "Hữu ích" của đánh giá
+	LikeCount int32 `json:"like_count"`
+}
+
+// Lịch sử sửa đánh giá sản phẩm
+type ProductCommentHistory struct {
+	// UUID, Khóa chính
+	ID string `json:"id"`
+	// FK tới product_comment.comment_id
+	CommentID string `json:"comment_id"`
+	// Điểm đánh giá trước khi sửa
+	Rating int8 `json:"rating"`
+	// Tiêu đề trước khi sửa
+	Title sql.NullString `json:"title"`
+	// Nội dung trước khi sửa
+	Content sql.NullString `json:"content"`
+	// Mảng JSON media trước khi sửa
+	Media sql.NullString `json:"media"`
+	// Người sửa
+	EditedBy string    `json:"edited_by"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// Tổng hợp điểm đánh giá theo sản phẩm
+type ProductRatingSummary struct {
+	// FK (logic) tới product.id
+	ProductID string `json:"product_id"`
+	// Số đánh giá 1 sao
+	Star1 int32 `json:"star_1"`
+	// Số đánh giá 2 sao
+	Star2 int32 `json:"star_2"`
+	// Số đánh giá 3 sao
+	Star3 int32 `json:"star_3"`
+	// Số đánh giá 4 sao
+	Star4 int32 `json:"star_4"`
+	// Số đánh giá 5 sao
+	Star5 int32 `json:"star_5"`
+	// Tổng số đánh giá
+	TotalReviews int32 `json:"total_reviews"`
+	// Điểm trung bình
+	AverageRating float64 `json:"average_rating"`
+	// Điểm đã hiệu chỉnh theo số lượng đánh giá, dùng để xếp hạng
+	BayesianScore float64   `json:"bayesian_score"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // Theo dõi lượt "Hữu ích" (Helpful) cho mỗi đánh giá
@@ -15,9 +15,9 @@ const createOrderItem = `-- name: CreateOrderItem :exec
 
 INSERT INTO order_items (
   id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price,
-  promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot
+  promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot, category_id
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
@@ -34,6 +34,7 @@ type CreateOrderItemParams struct {
 	ProductNameSnapshot   string         `json:"product_name_snapshot"`
 	ProductImageSnapshot  sql.NullString `json:"product_image_snapshot"`
 	SkuAttributesSnapshot sql.NullString `json:"sku_attributes_snapshot"`
+	CategoryID            sql.NullString `json:"category_id"`
 }
 
 // =================================================================
@@ -53,6 +54,7 @@ func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams
 		arg.ProductNameSnapshot,
 		arg.ProductImageSnapshot,
 		arg.SkuAttributesSnapshot,
+		arg.CategoryID,
 	)
 	return err
 }
@@ -182,7 +184,7 @@ func (q *Queries) GetProductTotalSold(ctx context.Context, productIds []string)
 }
 
 const listOrderItemsByShopOrderID = `-- name: ListOrderItemsByShopOrderID :many
-SELECT id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price, promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot FROM order_items
+SELECT id, shop_order_id, product_id, sku_id, quantity, original_unit_price, final_unit_price, total_price, promotions_snapshot, product_name_snapshot, product_image_snapshot, sku_attributes_snapshot, category_id FROM order_items
 WHERE shop_order_id = ?
 `
 
@@ -208,6 +210,7 @@ func (q *Queries) ListOrderItemsByShopOrderID(ctx context.Context, shopOrderID s
 			&i.ProductNameSnapshot,
 			&i.ProductImageSnapshot,
 			&i.SkuAttributesSnapshot,
+			&i.CategoryID,
 		); err != nil {
 			return nil, err
 		}
@@ -9,8 +9,26 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"time"
 )
 
+const adjustCommentLikeCount = `-- name: AdjustCommentLikeCount :exec
+UPDATE product_comment
+SET like_count = GREATEST(like_count + ?, 0)
+WHERE comment_id = ?
+`
+
+type AdjustCommentLikeCountParams struct {
+	Delta     int32  `json:"delta"`
+	CommentID string `json:"comment_id"`
+}
+
+// Cộng/trừ số lượt "Hữu ích" lưu trên đánh giá, không để xuống dưới 0
+func (q *Queries) AdjustCommentLikeCount(ctx context.Context, arg AdjustCommentLikeCountParams) error {
+	_, err := q.db.ExecContext(ctx, adjustCommentLikeCount, arg.Delta, arg.CommentID)
+	return err
+}
+
 const checkBulkOrderItemsReviewed = `-- name: CheckBulkOrderItemsReviewed :many
 SELECT order_item_id FROM product_comment
 WHERE order_item_id IN (/*SLICE:order_item_ids*/?)
@@ -18,7 +36,7 @@ WHERE order_item_id IN (/*SLICE:order_item_ids*/?)
 
 // Kiểm tra danh sách order_item_id đã được review chưa
 // Chỉ trả về những order_item_id đã có bình luận
-func (q *Queries) CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds []string) ([]string, error) {
+func (q *Queries) CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds []sql.NullString) ([]sql.NullString, error) {
 	query := checkBulkOrderItemsReviewed
 	var queryParams []interface{}
 	if len(orderItemIds) > 0 {
@@ -34,9 +52,9 @@ func (q *Queries) CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []sql.NullString
 	for rows.Next() {
-		var order_item_id string
+		var order_item_id sql.NullString
 		if err := rows.Scan(&order_item_id); err != nil {
 			return nil, err
 		}
@@ -51,6 +69,77 @@ func (q *Queries) CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds
 	return items, nil
 }
 
+const countCommentHistory = `-- name: CountCommentHistory :one
+SELECT COUNT(*) FROM product_comment_history
+WHERE comment_id = ?
+`
+
+// Số lần đánh giá đã được sửa
+func (q *Queries) CountCommentHistory(ctx context.Context, commentID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCommentHistory, commentID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCommentsByProduct = `-- name: CountCommentsByProduct :one
+SELECT COUNT(*) FROM product_comment pc
+WHERE pc.product_id = ? AND pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+  AND (? IS NULL OR pc.rating = ?)
+  AND (? IS NULL OR pc.sku_id = ?)
+  AND (? = FALSE OR (pc.media IS NOT NULL AND pc.media NOT IN ('', '[]')))
+`
+
+type CountCommentsByProductParams struct {
+	ProductID string         `json:"product_id"`
+	Rating    sql.NullInt16  `json:"rating"`
+	SkuID     sql.NullString `json:"sku_id"`
+	HasMedia  interface{}    `json:"has_media"`
+}
+
+// Đếm số bình luận gốc của sản phẩm theo cùng bộ lọc với ListCommentsByProduct (dùng cho phân trang).
+func (q *Queries) CountCommentsByProduct(ctx context.Context, arg CountCommentsByProductParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCommentsByProduct,
+		arg.ProductID,
+		arg.Rating,
+		arg.Rating,
+		arg.SkuID,
+		arg.SkuID,
+		arg.HasMedia,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countCommentsWithMedia = `-- name: CountCommentsWithMedia :one
+SELECT
+  (SELECT COUNT(*) FROM product_comment WHERE media LIKE CONCAT('%', ?, '%'))
+  + (SELECT COUNT(*) FROM product_comment_history WHERE media LIKE CONCAT('%', ?, '%')) AS total
+`
+
+// Số bình luận (kể cả lịch sử sửa) có chứa file media (media lưu dạng mảng JSON tên file/URL)
+func (q *Queries) CountCommentsWithMedia(ctx context.Context, fileName interface{}) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCommentsWithMedia, fileName, fileName)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countModerationQueue = `-- name: CountModerationQueue :one
+SELECT COUNT(*) FROM product_comment pc
+WHERE pc.status = 'PENDING'
+  OR EXISTS (SELECT 1 FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN')
+`
+
+// Đếm số đánh giá trong hàng chờ kiểm duyệt (cùng điều kiện với ListModerationQueue)
+func (q *Queries) CountModerationQueue(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countModerationQueue)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countReviewLikes = `-- name: CountReviewLikes :one
 SELECT COUNT(*) FROM review_likes
 WHERE review_id = ?
@@ -76,24 +165,26 @@ INSERT INTO product_comment (
   title,
   content,
   media,
-  parent_id
+  parent_id,
+  status
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
 type CreateCommentParams struct {
-	CommentID       string         `json:"comment_id"`
-	OrderItemID     string         `json:"order_item_id"`
-	ProductID       string         `json:"product_id"`
-	SkuID           string         `json:"sku_id"`
-	UserID          string         `json:"user_id"`
-	SkuNameSnapshot sql.NullString `json:"sku_name_snapshot"`
-	Rating          int8           `json:"rating"`
-	Title           sql.NullString `json:"title"`
-	Content         sql.NullString `json:"content"`
-	Media           sql.NullString `json:"media"`
-	ParentID        sql.NullString `json:"parent_id"`
+	CommentID       string               `json:"comment_id"`
+	OrderItemID     sql.NullString       `json:"order_item_id"`
+	ProductID       string               `json:"product_id"`
+	SkuID           string               `json:"sku_id"`
+	UserID          string               `json:"user_id"`
+	SkuNameSnapshot sql.NullString       `json:"sku_name_snapshot"`
+	Rating          int8                 `json:"rating"`
+	Title           sql.NullString       `json:"title"`
+	Content         sql.NullString       `json:"content"`
+	Media           sql.NullString       `json:"media"`
+	ParentID        sql.NullString       `json:"parent_id"`
+	Status          ProductCommentStatus `json:"status"`
 }
 
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) error {
@@ -109,16 +200,72 @@ func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) er
 		arg.Content,
 		arg.Media,
 		arg.ParentID,
+		arg.Status,
+	)
+	return err
+}
+
+const createCommentHistory = `-- name: CreateCommentHistory :execrows
+INSERT INTO product_comment_history (
+  id, comment_id, rating, title, content, media, edited_by
+)
+SELECT ?, pc.comment_id, pc.rating, pc.title, pc.content, pc.media, ?
+FROM product_comment pc
+WHERE pc.comment_id = ?
+  AND NOT EXISTS (SELECT 1 FROM product_comment_history h WHERE h.comment_id = pc.comment_id)
+`
+
+type CreateCommentHistoryParams struct {
+	ID        string `json:"id"`
+	EditedBy  string `json:"edited_by"`
+	CommentID string `json:"comment_id"`
+}
+
+// Lưu lại nội dung hiện tại của đánh giá trước khi sửa, chỉ khi đánh giá chưa từng được sửa.
+// Mỗi đánh giá chỉ được sửa 1 lần: code phải kiểm tra RowsAffected() == 1 rồi mới cập nhật đánh giá.
+func (q *Queries) CreateCommentHistory(ctx context.Context, arg CreateCommentHistoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createCommentHistory, arg.ID, arg.EditedBy, arg.CommentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createCommentReport = `-- name: CreateCommentReport :exec
+INSERT INTO comment_reports (
+  id, comment_id, reporter_id, reason, note
+) VALUES (
+  ?, ?, ?, ?, ?
+)
+`
+
+type CreateCommentReportParams struct {
+	ID         string               `json:"id"`
+	CommentID  string               `json:"comment_id"`
+	ReporterID string               `json:"reporter_id"`
+	Reason     CommentReportsReason `json:"reason"`
+	Note       sql.NullString       `json:"note"`
+}
+
+// Người dùng báo cáo đánh giá vi phạm, uq_comment_reporter chặn báo cáo trùng
+func (q *Queries) CreateCommentReport(ctx context.Context, arg CreateCommentReportParams) error {
+	_, err := q.db.ExecContext(ctx, createCommentReport,
+		arg.ID,
+		arg.CommentID,
+		arg.ReporterID,
+		arg.Reason,
+		arg.Note,
 	)
 	return err
 }
 
-const createReviewLike = `-- name: CreateReviewLike :exec
+const createReviewLike = `-- name: CreateReviewLike :execrows
 INSERT INTO review_likes (
   review_id, user_id
 ) VALUES (
   ?, ?
 )
+ON DUPLICATE KEY UPDATE review_id = review_id
 `
 
 type CreateReviewLikeParams struct {
@@ -126,13 +273,17 @@ type CreateReviewLikeParams struct {
 	UserID   string `json:"user_id"`
 }
 
-// Thêm một lượt "Hữu ích" cho review
-func (q *Queries) CreateReviewLike(ctx context.Context, arg CreateReviewLikeParams) error {
-	_, err := q.db.ExecContext(ctx, createReviewLike, arg.ReviewID, arg.UserID)
-	return err
+// Thêm một lượt "Hữu ích" cho review. Bấm trùng (hai request đồng thời) không lỗi,
+// trả về 0 dòng để logic code không tăng like_count hai lần.
+func (q *Queries) CreateReviewLike(ctx context.Context, arg CreateReviewLikeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createReviewLike, arg.ReviewID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const deleteReviewLike = `-- name: DeleteReviewLike :exec
+const deleteReviewLike = `-- name: DeleteReviewLike :execrows
 DELETE FROM review_likes
 WHERE review_id = ? AND user_id = ?
 `
@@ -142,70 +293,50 @@ type DeleteReviewLikeParams struct {
 	UserID   string `json:"user_id"`
 }
 
-// Bỏ lượt "Hữu ích"
-func (q *Queries) DeleteReviewLike(ctx context.Context, arg DeleteReviewLikeParams) error {
-	_, err := q.db.ExecContext(ctx, deleteReviewLike, arg.ReviewID, arg.UserID)
-	return err
+// Bỏ lượt "Hữu ích", trả về 0 dòng nếu lượt bấm đã bị xóa trước đó
+func (q *Queries) DeleteReviewLike(ctx context.Context, arg DeleteReviewLikeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteReviewLike, arg.ReviewID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const getBulkProductRatingStats = `-- name: GetBulkProductRatingStats :many
-SELECT
-  product_id,
-  COUNT(*) AS total_reviews,
-  AVG(rating) AS average_rating
-FROM product_comment
-WHERE product_id IN (/*SLICE:product_ids*/?) AND parent_id IS NULL
-GROUP BY product_id
+const getCommentByID = `-- name: GetCommentByID :one
+SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at, status, like_count FROM product_comment
+WHERE comment_id = ?
 `
 
-type GetBulkProductRatingStatsRow struct {
-	ProductID     string      `json:"product_id"`
-	TotalReviews  int64       `json:"total_reviews"`
-	AverageRating interface{} `json:"average_rating"`
-}
-
-// Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho nhiều sản phẩm
-// Chỉ tính các bình luận gốc (parent_id IS NULL).
-func (q *Queries) GetBulkProductRatingStats(ctx context.Context, productIds []string) ([]GetBulkProductRatingStatsRow, error) {
-	query := getBulkProductRatingStats
-	var queryParams []interface{}
-	if len(productIds) > 0 {
-		for _, v := range productIds {
-			queryParams = append(queryParams, v)
-		}
-		query = strings.Replace(query, "/*SLICE:product_ids*/?", strings.Repeat(",?", len(productIds))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:product_ids*/?", "NULL", 1)
-	}
-	rows, err := q.db.QueryContext(ctx, query, queryParams...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetBulkProductRatingStatsRow
-	for rows.Next() {
-		var i GetBulkProductRatingStatsRow
-		if err := rows.Scan(&i.ProductID, &i.TotalReviews, &i.AverageRating); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) GetCommentByID(ctx context.Context, commentID string) (ProductComment, error) {
+	row := q.db.QueryRowContext(ctx, getCommentByID, commentID)
+	var i ProductComment
+	err := row.Scan(
+		&i.CommentID,
+		&i.OrderItemID,
+		&i.ProductID,
+		&i.SkuID,
+		&i.UserID,
+		&i.SkuNameSnapshot,
+		&i.Rating,
+		&i.Title,
+		&i.Content,
+		&i.Media,
+		&i.ParentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.LikeCount,
+	)
+	return i, err
 }
 
 const getCommentByOrderItemID = `-- name: GetCommentByOrderItemID :one
-SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at FROM product_comment
+SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at, status, like_count FROM product_comment
 WHERE order_item_id = ?
 `
 
 // Dùng để check xem order_item_id này đã được review hay chưa.
-func (q *Queries) GetCommentByOrderItemID(ctx context.Context, orderItemID string) (ProductComment, error) {
+func (q *Queries) GetCommentByOrderItemID(ctx context.Context, orderItemID sql.NullString) (ProductComment, error) {
 	row := q.db.QueryRowContext(ctx, getCommentByOrderItemID, orderItemID)
 	var i ProductComment
 	err := row.Scan(
@@ -222,6 +353,8 @@ func (q *Queries) GetCommentByOrderItemID(ctx context.Context, orderItemID strin
 		&i.ParentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Status,
+		&i.LikeCount,
 	)
 	return i, err
 }
@@ -231,7 +364,7 @@ SELECT
   COUNT(*) AS total_reviews,
   AVG(rating) AS average_rating
 FROM product_comment
-WHERE product_id = ? AND parent_id IS NULL
+WHERE product_id = ? AND parent_id IS NULL AND status = 'VISIBLE'
 `
 
 type GetProductRatingStatsRow struct {
@@ -240,7 +373,7 @@ type GetProductRatingStatsRow struct {
 }
 
 // Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho một sản phẩm.
-// Chỉ tính các bình luận gốc (parent_id IS NULL).
+// Chỉ tính các bình luận gốc (parent_id IS NULL) đang hiển thị, đánh giá bị ẩn/chờ duyệt không được tính.
 func (q *Queries) GetProductRatingStats(ctx context.Context, productID string) (GetProductRatingStatsRow, error) {
 	row := q.db.QueryRowContext(ctx, getProductRatingStats, productID)
 	var i GetProductRatingStatsRow
@@ -249,7 +382,7 @@ func (q *Queries) GetProductRatingStats(ctx context.Context, productID string) (
 }
 
 const getRepliesByCommentID = `-- name: GetRepliesByCommentID :many
-SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at FROM product_comment
+SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at, status, like_count FROM product_comment
 WHERE parent_id = ?
 ORDER BY created_at ASC
 `
@@ -278,6 +411,8 @@ func (q *Queries) GetRepliesByCommentID(ctx context.Context, parentID sql.NullSt
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.LikeCount,
 		); err != nil {
 			return nil, err
 		}
@@ -292,22 +427,88 @@ func (q *Queries) GetRepliesByCommentID(ctx context.Context, parentID sql.NullSt
 	return items, nil
 }
 
+const getReviewForReply = `-- name: GetReviewForReply :one
+SELECT
+  pc.comment_id,
+  pc.product_id,
+  pc.sku_id,
+  pc.user_id,
+  pc.status,
+  so.shop_id
+FROM product_comment pc
+JOIN order_items oi ON oi.id = pc.order_item_id
+JOIN shop_orders so ON so.id = oi.shop_order_id
+WHERE pc.comment_id = ? AND pc.parent_id IS NULL
+`
+
+type GetReviewForReplyRow struct {
+	CommentID string               `json:"comment_id"`
+	ProductID string               `json:"product_id"`
+	SkuID     string               `json:"sku_id"`
+	UserID    string               `json:"user_id"`
+	Status    ProductCommentStatus `json:"status"`
+	ShopID    string               `json:"shop_id"`
+}
+
+// Lấy đánh giá gốc kèm shop đã bán sản phẩm (qua order_items -> shop_orders) để kiểm tra quyền phản hồi.
+// Phản hồi không có order_item_id nên không bao giờ khớp, tức là không thể phản hồi một phản hồi.
+func (q *Queries) GetReviewForReply(ctx context.Context, commentID string) (GetReviewForReplyRow, error) {
+	row := q.db.QueryRowContext(ctx, getReviewForReply, commentID)
+	var i GetReviewForReplyRow
+	err := row.Scan(
+		&i.CommentID,
+		&i.ProductID,
+		&i.SkuID,
+		&i.UserID,
+		&i.Status,
+		&i.ShopID,
+	)
+	return i, err
+}
+
 const listCommentsByProduct = `-- name: ListCommentsByProduct :many
-SELECT comment_id, order_item_id, product_id, sku_id, user_id, sku_name_snapshot, rating, title, content, media, parent_id, created_at, updated_at FROM product_comment
-WHERE product_id = ? 
-ORDER BY created_at DESC
+SELECT pc.comment_id, pc.order_item_id, pc.product_id, pc.sku_id, pc.user_id, pc.sku_name_snapshot, pc.rating, pc.title, pc.content, pc.media, pc.parent_id, pc.created_at, pc.updated_at, pc.status, pc.like_count
+FROM product_comment pc
+WHERE pc.product_id = ? AND pc.parent_id IS NULL AND pc.status = 'VISIBLE'
+  AND (? IS NULL OR pc.rating = ?)
+  AND (? IS NULL OR pc.sku_id = ?)
+  -- media lưu dạng mảng JSON, chuỗi rỗng hoặc [] coi như không có media
+  AND (? = FALSE OR (pc.media IS NOT NULL AND pc.media NOT IN ('', '[]')))
+ORDER BY
+  CASE WHEN ? = 'most_helpful' THEN pc.like_count END DESC,
+  CASE WHEN ? = 'rating_high' THEN pc.rating END DESC,
+  CASE WHEN ? = 'rating_low' THEN pc.rating END ASC,
+  pc.created_at DESC,
+  pc.comment_id DESC
 LIMIT ? OFFSET ?
 `
 
 type ListCommentsByProductParams struct {
-	ProductID string `json:"product_id"`
-	Limit     int32  `json:"limit"`
-	Offset    int32  `json:"offset"`
+	ProductID string         `json:"product_id"`
+	Rating    sql.NullInt16  `json:"rating"`
+	SkuID     sql.NullString `json:"sku_id"`
+	HasMedia  interface{}    `json:"has_media"`
+	SortBy    interface{}    `json:"sort_by"`
+	Limit     int32          `json:"limit"`
+	Offset    int32          `json:"offset"`
 }
 
-// Lấy danh sách bình luận (gốc, không phải trả lời) cho một sản phẩm, hỗ trợ phân trang.
+// Lấy danh sách bình luận (gốc, không phải trả lời, đang hiển thị) cho một sản phẩm, hỗ trợ lọc, sắp xếp và phân trang.
+// sort_by: most_helpful | rating_high | rating_low, còn lại (newest) sắp xếp theo thời gian mới nhất.
 func (q *Queries) ListCommentsByProduct(ctx context.Context, arg ListCommentsByProductParams) ([]ProductComment, error) {
-	rows, err := q.db.QueryContext(ctx, listCommentsByProduct, arg.ProductID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listCommentsByProduct,
+		arg.ProductID,
+		arg.Rating,
+		arg.Rating,
+		arg.SkuID,
+		arg.SkuID,
+		arg.HasMedia,
+		arg.SortBy,
+		arg.SortBy,
+		arg.SortBy,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -329,6 +530,8 @@ func (q *Queries) ListCommentsByProduct(ctx context.Context, arg ListCommentsByP
 			&i.ParentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Status,
+			&i.LikeCount,
 		); err != nil {
 			return nil, err
 		}
@@ -342,3 +545,266 @@ func (q *Queries) ListCommentsByProduct(ctx context.Context, arg ListCommentsByP
 	}
 	return items, nil
 }
+
+const listModerationQueue = `-- name: ListModerationQueue :many
+SELECT
+  pc.comment_id, pc.order_item_id, pc.product_id, pc.sku_id, pc.user_id, pc.sku_name_snapshot, pc.rating, pc.title, pc.content, pc.media, pc.parent_id, pc.created_at, pc.updated_at, pc.status, pc.like_count,
+  (SELECT COUNT(*) FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN') AS open_reports
+FROM product_comment pc
+WHERE pc.status = 'PENDING'
+  OR EXISTS (SELECT 1 FROM comment_reports r WHERE r.comment_id = pc.comment_id AND r.status = 'OPEN')
+ORDER BY open_reports DESC, pc.created_at ASC, pc.comment_id ASC
+LIMIT ? OFFSET ?
+`
+
+type ListModerationQueueParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListModerationQueueRow struct {
+	CommentID       string               `json:"comment_id"`
+	OrderItemID     sql.NullString       `json:"order_item_id"`
+	ProductID       string               `json:"product_id"`
+	SkuID           string               `json:"sku_id"`
+	UserID          string               `json:"user_id"`
+	SkuNameSnapshot sql.NullString       `json:"sku_name_snapshot"`
+	Rating          int8                 `json:"rating"`
+	Title           sql.NullString       `json:"title"`
+	Content         sql.NullString       `json:"content"`
+	Media           sql.NullString       `json:"media"`
+	ParentID        sql.NullString       `json:"parent_id"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	Status          ProductCommentStatus `json:"status"`
+	LikeCount       int32                `json:"like_count"`
+	OpenReports     int64                `json:"open_reports"`
+}
+
+// Hàng chờ kiểm duyệt của admin: đánh giá chờ duyệt (PENDING) hoặc còn báo cáo chưa xử lý.
+// Đánh giá bị báo cáo nhiều xếp trước, cùng số báo cáo thì cũ hơn xếp trước.
+func (q *Queries) ListModerationQueue(ctx context.Context, arg ListModerationQueueParams) ([]ListModerationQueueRow, error) {
+	rows, err := q.db.QueryContext(ctx, listModerationQueue, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListModerationQueueRow
+	for rows.Next() {
+		var i ListModerationQueueRow
+		if err := rows.Scan(
+			&i.CommentID,
+			&i.OrderItemID,
+			&i.ProductID,
+			&i.SkuID,
+			&i.UserID,
+			&i.SkuNameSnapshot,
+			&i.Rating,
+			&i.Title,
+			&i.Content,
+			&i.Media,
+			&i.ParentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.LikeCount,
+			&i.OpenReports,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenReportsByComments = `-- name: ListOpenReportsByComments :many
+SELECT id, comment_id, reporter_id, reason, note, status, resolved_by, resolved_at, created_at FROM comment_reports
+WHERE status = 'OPEN' AND comment_id IN (/*SLICE:comment_ids*/?)
+ORDER BY created_at ASC
+`
+
+// Các báo cáo chưa xử lý của những đánh giá trong trang hàng chờ
+func (q *Queries) ListOpenReportsByComments(ctx context.Context, commentIds []string) ([]CommentReports, error) {
+	query := listOpenReportsByComments
+	var queryParams []interface{}
+	if len(commentIds) > 0 {
+		for _, v := range commentIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:comment_ids*/?", strings.Repeat(",?", len(commentIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:comment_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CommentReports
+	for rows.Next() {
+		var i CommentReports
+		if err := rows.Scan(
+			&i.ID,
+			&i.CommentID,
+			&i.ReporterID,
+			&i.Reason,
+			&i.Note,
+			&i.Status,
+			&i.ResolvedBy,
+			&i.ResolvedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReviewsLikedByUser = `-- name: ListReviewsLikedByUser :many
+SELECT review_id FROM review_likes
+WHERE user_id = ? AND review_id IN (/*SLICE:review_ids*/?)
+`
+
+type ListReviewsLikedByUserParams struct {
+	UserID    string   `json:"user_id"`
+	ReviewIds []string `json:"review_ids"`
+}
+
+// Trong danh sách review, trả về những review mà user đã bấm "Hữu ích"
+func (q *Queries) ListReviewsLikedByUser(ctx context.Context, arg ListReviewsLikedByUserParams) ([]string, error) {
+	query := listReviewsLikedByUser
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.ReviewIds) > 0 {
+		for _, v := range arg.ReviewIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:review_ids*/?", strings.Repeat(",?", len(arg.ReviewIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:review_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var review_id string
+		if err := rows.Scan(&review_id); err != nil {
+			return nil, err
+		}
+		items = append(items, review_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveCommentReports = `-- name: ResolveCommentReports :execrows
+UPDATE comment_reports
+SET status = ?, resolved_by = ?, resolved_at = NOW()
+WHERE comment_id = ? AND status = 'OPEN'
+`
+
+type ResolveCommentReportsParams struct {
+	Status     CommentReportsStatus `json:"status"`
+	ResolvedBy sql.NullString       `json:"resolved_by"`
+	CommentID  string               `json:"comment_id"`
+}
+
+// Đóng các báo cáo đang mở của một đánh giá sau khi admin xử lý
+func (q *Queries) ResolveCommentReports(ctx context.Context, arg ResolveCommentReportsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resolveCommentReports, arg.Status, arg.ResolvedBy, arg.CommentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateCommentByBuyer = `-- name: UpdateCommentByBuyer :exec
+UPDATE product_comment
+SET rating = ?, title = ?, content = ?, media = ?, status = ?
+WHERE comment_id = ? AND parent_id IS NULL
+`
+
+type UpdateCommentByBuyerParams struct {
+	Rating    int8                 `json:"rating"`
+	Title     sql.NullString       `json:"title"`
+	Content   sql.NullString       `json:"content"`
+	Media     sql.NullString       `json:"media"`
+	Status    ProductCommentStatus `json:"status"`
+	CommentID string               `json:"comment_id"`
+}
+
+// Người mua sửa đánh giá gốc của mình, status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+func (q *Queries) UpdateCommentByBuyer(ctx context.Context, arg UpdateCommentByBuyerParams) error {
+	_, err := q.db.ExecContext(ctx, updateCommentByBuyer,
+		arg.Rating,
+		arg.Title,
+		arg.Content,
+		arg.Media,
+		arg.Status,
+		arg.CommentID,
+	)
+	return err
+}
+
+const updateCommentReply = `-- name: UpdateCommentReply :exec
+UPDATE product_comment
+SET content = ?, user_id = ?, status = ?
+WHERE comment_id = ? AND parent_id IS NOT NULL
+`
+
+type UpdateCommentReplyParams struct {
+	Content   sql.NullString       `json:"content"`
+	UserID    string               `json:"user_id"`
+	Status    ProductCommentStatus `json:"status"`
+	CommentID string               `json:"comment_id"`
+}
+
+// Sửa nội dung phản hồi của Shop/Admin, ghi nhận người sửa cuối cùng.
+// status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+func (q *Queries) UpdateCommentReply(ctx context.Context, arg UpdateCommentReplyParams) error {
+	_, err := q.db.ExecContext(ctx, updateCommentReply,
+		arg.Content,
+		arg.UserID,
+		arg.Status,
+		arg.CommentID,
+	)
+	return err
+}
+
+const updateCommentStatus = `-- name: UpdateCommentStatus :exec
+UPDATE product_comment
+SET status = ?
+WHERE comment_id = ?
+`
+
+type UpdateCommentStatusParams struct {
+	Status    ProductCommentStatus `json:"status"`
+	CommentID string               `json:"comment_id"`
+}
+
+// Admin ẩn/hiện đánh giá
+func (q *Queries) UpdateCommentStatus(ctx context.Context, arg UpdateCommentStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateCommentStatus, arg.Status, arg.CommentID)
+	return err
+}
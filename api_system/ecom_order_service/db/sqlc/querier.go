@@ -10,16 +10,26 @@ import (
 )
 
 type Querier interface {
+	// Cộng/trừ số lượt "Hữu ích" lưu trên đánh giá, không để xuống dưới 0
+	AdjustCommentLikeCount(ctx context.Context, arg AdjustCommentLikeCountParams) error
 	// Cập nhật trạng thái một loạt shop_orders thành CANCELLED
 	CancelShopOrdersByIDs(ctx context.Context, arg CancelShopOrdersByIDsParams) error
 	// Kiểm tra danh sách order_item_id đã được review chưa
 	// Chỉ trả về những order_item_id đã có bình luận
-	CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds []string) ([]string, error)
+	CheckBulkOrderItemsReviewed(ctx context.Context, orderItemIds []sql.NullString) ([]sql.NullString, error)
 	// Xác thực quyền review:
 	// 1. order_item_id có tồn tại (JOIN order_items)
 	// 2. order_item_id đó có thuộc về user_id này (WHERE o.user_id = ?)
 	// 3. Đơn hàng shop (shop_order) chứa item đó PHẢI ở trạng thái 'COMPLETED' (WHERE so.status = 'COMPLETED')
 	CheckReviewPermission(ctx context.Context, arg CheckReviewPermissionParams) (CheckReviewPermissionRow, error)
+	// Số lần đánh giá đã được sửa
+	CountCommentHistory(ctx context.Context, commentID string) (int64, error)
+	// Đếm số bình luận gốc của sản phẩm theo cùng bộ lọc với ListCommentsByProduct (dùng cho phân trang).
+	CountCommentsByProduct(ctx context.Context, arg CountCommentsByProductParams) (int64, error)
+	// Số bình luận (kể cả lịch sử sửa) có chứa file media (media lưu dạng mảng JSON tên file/URL)
+	CountCommentsWithMedia(ctx context.Context, fileName interface{}) (int64, error)
+	// Đếm số đánh giá trong hàng chờ kiểm duyệt (cùng điều kiện với ListModerationQueue)
+	CountModerationQueue(ctx context.Context) (int64, error)
 	// Đếm số lượt "Hữu ích" của một review
 	CountReviewLikes(ctx context.Context, reviewID string) (int64, error)
 	// Đếm số lần user đã sử dụng 1 voucher (cho check max_usage_per_user)
@@ -30,6 +40,11 @@ type Querier interface {
 	// Đếm tổng số voucher theo owner với filters
 	CountVouchersForManagement(ctx context.Context, arg CountVouchersForManagementParams) (int64, error)
 	CreateComment(ctx context.Context, arg CreateCommentParams) error
+	// Lưu lại nội dung hiện tại của đánh giá trước khi sửa, chỉ khi đánh giá chưa từng được sửa.
+	// Mỗi đánh giá chỉ được sửa 1 lần: code phải kiểm tra RowsAffected() == 1 rồi mới cập nhật đánh giá.
+	CreateCommentHistory(ctx context.Context, arg CreateCommentHistoryParams) (int64, error)
+	// Người dùng báo cáo đánh giá vi phạm, uq_comment_reporter chặn báo cáo trùng
+	CreateCommentReport(ctx context.Context, arg CreateCommentReportParams) error
 	// =================================================================
 	// Queries for `orders` table
 	// =================================================================
@@ -38,8 +53,9 @@ type Querier interface {
 	// Queries for `order_items` table
 	// =================================================================
 	CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) error
-	// Thêm một lượt "Hữu ích" cho review
-	CreateReviewLike(ctx context.Context, arg CreateReviewLikeParams) error
+	// Thêm một lượt "Hữu ích" cho review. Bấm trùng (hai request đồng thời) không lỗi,
+	// trả về 0 dòng để logic code không tăng like_count hai lần.
+	CreateReviewLike(ctx context.Context, arg CreateReviewLikeParams) (int64, error)
 	// =================================================================
 	// Queries for `shop_orders` table
 	// =================================================================
@@ -51,8 +67,8 @@ type Querier interface {
 	CreateVoucherUser(ctx context.Context, arg CreateVoucherUserParams) error
 	// Giảm số lượng đã dùng (khi hủy đơn)
 	DecrementVoucherUsage(ctx context.Context, id string) (int64, error)
-	// Bỏ lượt "Hữu ích"
-	DeleteReviewLike(ctx context.Context, arg DeleteReviewLikeParams) error
+	// Bỏ lượt "Hữu ích", trả về 0 dòng nếu lượt bấm đã bị xóa trước đó
+	DeleteReviewLike(ctx context.Context, arg DeleteReviewLikeParams) (int64, error)
 	// Xóa 1 dòng lịch sử cụ thể (khi hủy đơn)
 	DeleteVoucherUsageHistory(ctx context.Context, id uint64) (int64, error)
 	// Lấy danh sách voucher ĐƯỢC GÁN RIÊNG (cho 1 user)
@@ -60,11 +76,11 @@ type Querier interface {
 	GetAssignedVouchersByUser(ctx context.Context, userID string) ([]Vouchers, error)
 	// Lấy danh sách voucher ĐƯỢC GÁN RIÊNG với bộ lọc
 	GetAssignedVouchersByUserWithFilter(ctx context.Context, arg GetAssignedVouchersByUserWithFilterParams) ([]Vouchers, error)
-	// Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho nhiều sản phẩm
-	// Chỉ tính các bình luận gốc (parent_id IS NULL).
-	GetBulkProductRatingStats(ctx context.Context, productIds []string) ([]GetBulkProductRatingStatsRow, error)
+	// Tổng hợp điểm đánh giá của nhiều sản phẩm (sản phẩm chưa từng có đánh giá không có dòng nào)
+	GetBulkProductRatingSummary(ctx context.Context, productIds []string) ([]ProductRatingSummary, error)
+	GetCommentByID(ctx context.Context, commentID string) (ProductComment, error)
 	// Dùng để check xem order_item_id này đã được review hay chưa.
-	GetCommentByOrderItemID(ctx context.Context, orderItemID string) (ProductComment, error)
+	GetCommentByOrderItemID(ctx context.Context, orderItemID sql.NullString) (ProductComment, error)
 	GetOrderByCode(ctx context.Context, orderCode string) (Orders, error)
 	GetOrderByID(ctx context.Context, id string) (Orders, error)
 	// -- =================================================================
@@ -83,8 +99,9 @@ type Querier interface {
 	// Lấy tất cả items thuộc các shop_orders (để gửi event 'order_cancelled' cho Product Service)
 	GetOrderItemsByShopOrderIDs(ctx context.Context, shopOrderIds []string) ([]GetOrderItemsByShopOrderIDsRow, error)
 	// Lấy điểm đánh giá trung bình và tổng số lượt đánh giá cho một sản phẩm.
-	// Chỉ tính các bình luận gốc (parent_id IS NULL).
+	// Chỉ tính các bình luận gốc (parent_id IS NULL) đang hiển thị, đánh giá bị ẩn/chờ duyệt không được tính.
 	GetProductRatingStats(ctx context.Context, productID string) (GetProductRatingStatsRow, error)
+	GetProductRatingSummary(ctx context.Context, productID string) (ProductRatingSummary, error)
 	//
 	// lấy tổng số lượng đã bán của các product_ids trong các đơn hàng có trạng thái 'PROCESSING', 'SHIPPED', 'COMPLETED'(đang dùng cho product_service)
 	GetProductTotalSold(ctx context.Context, productIds []string) ([]GetProductTotalSoldRow, error)
@@ -93,8 +110,13 @@ type Querier interface {
 	GetPublicVouchers(ctx context.Context) ([]Vouchers, error)
 	// Lấy danh sách voucher CÔNG KHAI với bộ lọc
 	GetPublicVouchersWithFilter(ctx context.Context, arg GetPublicVouchersWithFilterParams) ([]Vouchers, error)
+	// Tổng số sao và tổng số đánh giá toàn sàn, dùng tính điểm trung bình làm prior cho bayesian_score
+	GetRatingGlobalStats(ctx context.Context) (GetRatingGlobalStatsRow, error)
 	// Lấy danh sách các bình luận trả lời (replies) cho một comment gốc
 	GetRepliesByCommentID(ctx context.Context, parentID sql.NullString) ([]ProductComment, error)
+	// Lấy đánh giá gốc kèm shop đã bán sản phẩm (qua order_items -> shop_orders) để kiểm tra quyền phản hồi.
+	// Phản hồi không có order_item_id nên không bao giờ khớp, tức là không thể phản hồi một phản hồi.
+	GetReviewForReply(ctx context.Context, commentID string) (GetReviewForReplyRow, error)
 	GetShopOrderByID(ctx context.Context, id string) (ShopOrders, error)
 	// Lấy trạng thái voucher trong ví của user (cho check voucher ĐƯỢC GÁN)
 	GetUserVoucherStatus(ctx context.Context, arg GetUserVoucherStatusParams) (UserVouchers, error)
@@ -117,11 +139,21 @@ type Querier interface {
 	// Tăng số lượng đã dùng. Dùng :execrows để check race condition
 	// (Logic code phải kiểm tra RowsAffected() == 1)
 	IncrementVoucherUsage(ctx context.Context, id string) (int64, error)
-	// Lấy danh sách bình luận (gốc, không phải trả lời) cho một sản phẩm, hỗ trợ phân trang.
+	// Lấy danh sách bình luận (gốc, không phải trả lời, đang hiển thị) cho một sản phẩm, hỗ trợ lọc, sắp xếp và phân trang.
+	// sort_by: most_helpful | rating_high | rating_low, còn lại (newest) sắp xếp theo thời gian mới nhất.
 	ListCommentsByProduct(ctx context.Context, arg ListCommentsByProductParams) ([]ProductComment, error)
+	// Hàng chờ kiểm duyệt của admin: đánh giá chờ duyệt (PENDING) hoặc còn báo cáo chưa xử lý.
+	// Đánh giá bị báo cáo nhiều xếp trước, cùng số báo cáo thì cũ hơn xếp trước.
+	ListModerationQueue(ctx context.Context, arg ListModerationQueueParams) ([]ListModerationQueueRow, error)
+	// Các báo cáo chưa xử lý của những đánh giá trong trang hàng chờ
+	ListOpenReportsByComments(ctx context.Context, commentIds []string) ([]CommentReports, error)
 	ListOrderItemsByShopOrderID(ctx context.Context, shopOrderID string) ([]OrderItems, error)
 	ListOrdersByUserID(ctx context.Context, userID string) ([]Orders, error)
 	ListOrdersByUserIDPaged(ctx context.Context, arg ListOrdersByUserIDPagedParams) ([]Orders, error)
+	// Duyệt toàn bộ bảng tổng hợp theo product_id (đồng bộ lại sang product service)
+	ListProductRatingSummaries(ctx context.Context, arg ListProductRatingSummariesParams) ([]ProductRatingSummary, error)
+	// Trong danh sách review, trả về những review mà user đã bấm "Hữu ích"
+	ListReviewsLikedByUser(ctx context.Context, arg ListReviewsLikedByUserParams) ([]string, error)
 	ListShopOrdersByOrderID(ctx context.Context, arg ListShopOrdersByOrderIDParams) ([]ShopOrders, error)
 	ListShopOrdersByShopIDPaged(ctx context.Context, arg ListShopOrdersByShopIDPagedParams) ([]ShopOrders, error)
 	// -- name: ListShopOrdersByStatus :many
@@ -144,14 +176,27 @@ type Querier interface {
 	ListVouchersForManagementBySortEndDateDesc(ctx context.Context, arg ListVouchersForManagementBySortEndDateDescParams) ([]Vouchers, error)
 	ListVouchersForManagementBySortStartDateAsc(ctx context.Context, arg ListVouchersForManagementBySortStartDateAscParams) ([]Vouchers, error)
 	ListVouchersForManagementBySortStartDateDesc(ctx context.Context, arg ListVouchersForManagementBySortStartDateDescParams) ([]Vouchers, error)
+	// Tính lại tổng hợp điểm đánh giá của một sản phẩm từ các đánh giá gốc đang hiển thị.
+	// Gọi trong cùng transaction với thao tác làm thay đổi đánh giá (tạo, sửa, ẩn/hiện).
+	// prior_mean là điểm trung bình toàn sàn, prior_weight là số đánh giá "ảo" kéo điểm về prior_mean.
+	RefreshProductRatingSummary(ctx context.Context, arg RefreshProductRatingSummaryParams) error
 	// Xóa bằng ID của bảng history
 	// Reset trạng thái ví voucher (từ USED về AVAILABLE)
 	ResetUserVoucherStatus(ctx context.Context, arg ResetUserVoucherStatusParams) (int64, error)
+	// Đóng các báo cáo đang mở của một đánh giá sau khi admin xử lý
+	ResolveCommentReports(ctx context.Context, arg ResolveCommentReportsParams) (int64, error)
 	SearchShopOrders(ctx context.Context, arg SearchShopOrdersParams) ([]ShopOrders, error)
 	SearchShopOrdersCount(ctx context.Context, arg SearchShopOrdersCountParams) (int64, error)
 	// Cập nhật trạng thái voucher trong ví user (từ AVAILABLE -> USED)
 	// (Logic code nên kiểm tra RowsAffected() == 1)
 	SetUserVoucherStatus(ctx context.Context, arg SetUserVoucherStatusParams) (int64, error)
+	// Người mua sửa đánh giá gốc của mình, status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+	UpdateCommentByBuyer(ctx context.Context, arg UpdateCommentByBuyerParams) error
+	// Sửa nội dung phản hồi của Shop/Admin, ghi nhận người sửa cuối cùng.
+	// status đổi sang PENDING nếu nội dung mới phải chờ duyệt
+	UpdateCommentReply(ctx context.Context, arg UpdateCommentReplyParams) error
+	// Admin ẩn/hiện đánh giá
+	UpdateCommentStatus(ctx context.Context, arg UpdateCommentStatusParams) error
 	UpdateOrderShippingAddress(ctx context.Context, arg UpdateOrderShippingAddressParams) error
 	UpdateOrderTotals(ctx context.Context, arg UpdateOrderTotalsParams) error
 	UpdateShopOrderGeneralInfo(ctx context.Context, arg UpdateShopOrderGeneralInfoParams) error
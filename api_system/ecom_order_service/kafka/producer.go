@@ -10,6 +10,8 @@ import (
 const (
 	TopicPaymentCompleted = "payment.completed"
 	TopicPaymentFailed    = "payment.failed"
+	// trạng thái shop order thay đổi, analytics service dùng để cập nhật bảng tổng hợp
+	TopicOrderStatusChanged = "order.status_changed"
 )
 
 // EventProducer là interface để các service của bạn sử dụng
@@ -17,6 +19,7 @@ type EventProducer interface {
 	// Publish(ctx context.Context, topic string, key string, message []byte) error
 	PaymentCompleted(ctx context.Context, key string, message map[string]interface{}) error
 	PaymentFailed(ctx context.Context, key string, message map[string]interface{}) error
+	OrderStatusChanged(ctx context.Context, key string, message map[string]interface{}) error
 	Close() error
 }
 
@@ -50,6 +53,13 @@ func (p *kafkaProducer) PaymentFailed(ctx context.Context, key string, message m
 	}
 	return p.publish(ctx, TopicPaymentFailed, key, messageBytes)
 }
+func (p *kafkaProducer) OrderStatusChanged(ctx context.Context, key string, message map[string]interface{}) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, TopicOrderStatusChanged, key, messageBytes)
+}
 func (p *kafkaProducer) publish(ctx context.Context, topic string, key string, message []byte) error {
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
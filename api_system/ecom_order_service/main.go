@@ -12,6 +12,7 @@ import (
 
 	"github.com/IBM/sarama"
 	config_assets "github.com/TranVinhHien/ecom_order_service/assets/config"
+	assets_firebase "github.com/TranVinhHien/ecom_order_service/assets/fire-base"
 	"github.com/TranVinhHien/ecom_order_service/assets/token"
 	"github.com/TranVinhHien/ecom_order_service/controllers"
 	db "github.com/TranVinhHien/ecom_order_service/db/mysql"
@@ -68,10 +69,28 @@ func main() {
 
 	//setup redis Options
 	redisdb := redis_db.NewRedisDB(rdb)
+	// create firebase client (optional) để gửi thông báo cho người mua
+	var firebase *assets_firebase.FirebaseMessaging
+	if env.FirebaseCredentials != "" {
+		firebase, err = assets_firebase.NewFirebase(context.Background(), env.FirebaseCredentials)
+		if err != nil {
+			log.Err(err).Msg("Error create firebase, notifications are disabled")
+			firebase = nil
+		}
+	}
+	// create kafka producer để phát sự kiện trạng thái đơn hàng cho analytics
+	var events services.ServicesEvent
+	producer, err := kafka.NewProducer([]string{env.KafkaBrokers})
+	if err != nil {
+		log.Err(err).Msg("Error create kafka producer, order events are disabled")
+	} else {
+		defer producer.Close()
+		events = producer
+	}
 	// setup service
-	services := services.NewService(db, jwtMaker, env, redisdb, APIServer)
+	services := services.NewService(db, jwtMaker, env, redisdb, APIServer, firebase, events)
 	// setup controller
-	controller := controllers.NewAPIController(services, jwtMaker)
+	controller := controllers.NewAPIController(services, jwtMaker, env.TokenSystem)
 
 	engine := gin.Default()
 	engine.MaxMultipartMemory = 32 << 20 // 32 MB
@@ -16,13 +16,11 @@ type MediaServer struct {
 }
 
 type UploadResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Code    int    `json:"code"`
-	Error   string `json:"error"`
-	Result  struct {
-		Urls []string `json:"urls"`
-	} `json:"result"` // URLs của các ảnh đã upload
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Code    int      `json:"code"`
+	Error   string   `json:"error"`
+	Result  []string `json:"result"` // tên file của các ảnh/video đã upload, theo đúng thứ tự gửi lên
 }
 
 // NewMediaServer tạo mới media client với dependency injection
@@ -36,8 +34,8 @@ func NewMediaServer(baseURL string, timeout time.Duration) MediaServer {
 }
 
 // UploadImages upload nhiều ảnh lên media service
-// files: slice các multipart.FileHeader từ Gin context
-func (c MediaServer) uploadImages(token string, files []*multipart.FileHeader) (*UploadResponse, error) {
+// files: slice các multipart.FileHeader từ Gin context, path: /v1/media hoặc /v1/media/reviews
+func (c MediaServer) uploadImages(token, path string, files []*multipart.FileHeader) (*UploadResponse, error) {
 	// Tạo buffer để chứa multipart form data
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -72,7 +70,7 @@ func (c MediaServer) uploadImages(token string, files []*multipart.FileHeader) (
 	}
 
 	// Tạo request
-	url := fmt.Sprintf("%s/v1/media/uploads", c.baseURL)
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
 	req, err := http.NewRequest("POST", url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -110,7 +108,7 @@ func (c MediaServer) uploadImages(token string, files []*multipart.FileHeader) (
 	return &uploadResp, nil
 }
 func (c MediaServer) UploadMultipleImages(token string, files []*multipart.FileHeader) ([]string, error) {
-	resp, err := c.uploadImages(token, files)
+	resp, err := c.uploadImages(token, "/v1/media", files)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +116,20 @@ func (c MediaServer) UploadMultipleImages(token string, files []*multipart.FileH
 		return nil, fmt.Errorf("upload failed: %s", resp.Error)
 	}
 
-	return resp.Result.Urls, nil
+	return resp.Result, nil
+}
+
+// UploadReviewMedia upload ảnh hoặc video ngắn của đánh giá, chỉ API /v1/media/reviews nhận video
+func (c MediaServer) UploadReviewMedia(token string, files []*multipart.FileHeader) ([]string, error) {
+	resp, err := c.uploadImages(token, "/v1/media/reviews", files)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("upload failed: %s", resp.Error)
+	}
+
+	return resp.Result, nil
 }
 
 // UploadSingleImage upload 1 ảnh đơn lẻ
@@ -126,7 +137,7 @@ func (c MediaServer) UploadSingleImage(token string, file *multipart.FileHeader)
 	// Tạo slice chứa 1 file
 	files := []*multipart.FileHeader{file}
 
-	resp, err := c.uploadImages(token, files)
+	resp, err := c.uploadImages(token, "/v1/media", files)
 	if err != nil {
 		return "", err
 	}
@@ -134,5 +145,8 @@ func (c MediaServer) UploadSingleImage(token string, file *multipart.FileHeader)
 		return "", fmt.Errorf("upload failed: %s", resp.Error)
 	}
 
-	return resp.Result.Urls[0], nil
+	if len(resp.Result) == 0 {
+		return "", fmt.Errorf("upload failed: media service không trả về file")
+	}
+	return resp.Result[0], nil
 }
@@ -28,6 +28,17 @@ type GetSKUResponse struct {
 	} `json:"result"`
 }
 
+type GetSKUsBatchResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+	Error   string `json:"error"`
+	Result  struct {
+		Data    []SKUOrderInfo `json:"data"`
+		Missing []string       `json:"missing"`
+	} `json:"result"`
+}
+
 type GetProductListResponse struct {
 	Status  string `json:"status"`
 	Message string `json:"message"`
@@ -63,6 +74,23 @@ type SKUData struct {
 	SkuName          string    `json:"sku_name"`
 	UpdateDate       time.Time `json:"update_date"`
 	Weight           float64   `json:"weight"`
+	ProductStatus    string    `json:"product_status"`
+}
+
+// SKUOrderInfo thông tin SKU kèm sản phẩm trả về từ API tra cứu SKU hàng loạt
+type SKUOrderInfo struct {
+	SkuID          string  `json:"sku_id"`
+	SkuCode        string  `json:"sku_code"`
+	SkuName        string  `json:"sku_name"`
+	ProductID      string  `json:"product_id"`
+	ProductName    string  `json:"product_name"`
+	Image          string  `json:"image"`
+	ShopID         string  `json:"shop_id"`
+	CategoryID     string  `json:"category_id"`
+	Price          float64 `json:"price"`
+	AvailableStock int     `json:"available_stock"`
+	Weight         float64 `json:"weight"`
+	ProductStatus  string  `json:"product_status"` // Active, Unlisted, Deleted...
 }
 
 // =================================================================
@@ -224,6 +252,46 @@ func (c ProductServer) GetSKUs(sku_id string) (*GetSKUResponse, error) {
 	}
 	return &result, nil
 }
+
+// GetSKUsBatch lấy thông tin nhiều SKU trong một request, dùng khi tạo đơn hàng
+func (c ProductServer) GetSKUsBatch(skuIDs []string) (*GetSKUsBatchResponse, error) {
+	url := fmt.Sprintf("%s/v1/product/skus/batch", c.baseURL)
+	body, err := json.Marshal(map[string][]string{"sku_ids": skuIDs})
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi marshal dữ liệu: %w", err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Gửi request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Đọc response body
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Kiểm tra status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get skus failed with status %d: %s", resp.StatusCode, string(responseBody))
+	}
+
+	// Parse response
+	var result GetSKUsBatchResponse
+	err = json.Unmarshal(responseBody, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &result, nil
+}
 func (c ProductServer) GetProductDetail(product_id string) (*GetProductDetailResponse, error) {
 
 	// Tạo request
@@ -315,3 +383,67 @@ func (c ProductServer) UpdateProductSKU(token, status string, params []UpdatePro
 	}
 	return &result, nil
 }
+
+// InvalidateProductDetailCache báo product service làm mới cache chi tiết sản phẩm (vd khi có đánh giá mới),
+// token là TOKEN_SYSTEM vì API này chỉ cho service nội bộ hoặc admin gọi
+func (c ProductServer) InvalidateProductDetailCache(token string, productIDs []string) error {
+	url := fmt.Sprintf("%s/v1/product/invalidate_detail_cache", c.baseURL)
+	body, err := json.Marshal(map[string][]string{"product_ids": productIDs})
+	if err != nil {
+		return fmt.Errorf("lỗi khi marshal dữ liệu: %w", err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("lỗi khi tạo request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("lỗi khi gửi request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("làm mới cache sản phẩm thất bại với status %d: %s", resp.StatusCode, string(responseBody))
+	}
+	return nil
+}
+
+// ProductRatingParams điểm đánh giá của một sản phẩm gửi sang product service
+type ProductRatingParams struct {
+	ProductID     string  `json:"product_id"`
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int32   `json:"rating_count"`
+	RatingScore   float64 `json:"rating_score"`
+}
+
+// SyncProductRatings cập nhật điểm đánh giá của sản phẩm bên product service (dùng sắp xếp theo đánh giá),
+// product service làm mới cache chi tiết các sản phẩm này
+func (c ProductServer) SyncProductRatings(token string, ratings []ProductRatingParams) error {
+	url := fmt.Sprintf("%s/v1/product/sync_rating", c.baseURL)
+	body, err := json.Marshal(map[string][]ProductRatingParams{"ratings": ratings})
+	if err != nil {
+		return fmt.Errorf("lỗi khi marshal dữ liệu: %w", err)
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("lỗi khi tạo request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("lỗi khi gửi request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("đồng bộ điểm đánh giá thất bại với status %d: %s", resp.StatusCode, string(responseBody))
+	}
+	return nil
+}
@@ -22,16 +22,20 @@ type apiClient struct {
 type ApiServer interface {
 	UploadMultipleImages(token string, files []*multipart.FileHeader) ([]string, error)
 	UploadSingleImage(token string, file *multipart.FileHeader) (string, error)
+	UploadReviewMedia(token string, files []*multipart.FileHeader) ([]string, error)
 	GetSKUs(sku_id string) (*server_product.GetSKUResponse, error)
+	GetSKUsBatch(skuIDs []string) (*server_product.GetSKUsBatchResponse, error)
 	GetProductDetail(sku_id string) (*server_product.GetProductDetailResponse, error)
 	UpdateProductSKU(token, status string, params []server_product.UpdateProductSKUParams) (*server_product.GetProductDetailResponse, error)
+	InvalidateProductDetailCache(token string, productIDs []string) error
+	SyncProductRatings(token string, ratings []server_product.ProductRatingParams) error
 	GetTransaction(payment_method_id string) (*server_transaction.GetTransactionsResponse, error)
 	CreateTransaction(token string, params server_transaction.InitPaymentParams) (*server_transaction.InitTransactionResponse, error)
 }
 
 func NewAPIServices(jwt config_assets.ReadENV, timeout time.Duration) ApiServer {
 	return &apiClient{
-		// media:       server_media.NewMediaServer(jwt.URLMediaService, timeout),
+		media:       server_media.NewMediaServer(jwt.URLMediaService, timeout),
 		product:     server_product.NewProductServer(jwt.URLProductService, timeout),
 		transaction: server_transaction.NewTransactionServer(jwt.URLTransactionService, timeout),
 	}
@@ -44,15 +48,27 @@ func (c apiClient) UploadMultipleImages(token string, files []*multipart.FileHea
 func (c apiClient) UploadSingleImage(token string, file *multipart.FileHeader) (string, error) {
 	return c.media.UploadSingleImage(token, file)
 }
+func (c apiClient) UploadReviewMedia(token string, files []*multipart.FileHeader) ([]string, error) {
+	return c.media.UploadReviewMedia(token, files)
+}
 func (c apiClient) GetSKUs(sku_id string) (*server_product.GetSKUResponse, error) {
 	return c.product.GetSKUs(sku_id)
 }
+func (c apiClient) GetSKUsBatch(skuIDs []string) (*server_product.GetSKUsBatchResponse, error) {
+	return c.product.GetSKUsBatch(skuIDs)
+}
 func (c apiClient) GetProductDetail(product_id string) (*server_product.GetProductDetailResponse, error) {
 	return c.product.GetProductDetail(product_id)
 }
 func (c apiClient) UpdateProductSKU(token, status string, params []server_product.UpdateProductSKUParams) (*server_product.GetProductDetailResponse, error) {
 	return c.product.UpdateProductSKU(token, status, params)
 }
+func (c apiClient) InvalidateProductDetailCache(token string, productIDs []string) error {
+	return c.product.InvalidateProductDetailCache(token, productIDs)
+}
+func (c apiClient) SyncProductRatings(token string, ratings []server_product.ProductRatingParams) error {
+	return c.product.SyncProductRatings(token, ratings)
+}
 func (c apiClient) GetTransaction(payment_method_id string) (*server_transaction.GetTransactionsResponse, error) {
 	return c.transaction.GetTransaction(payment_method_id)
 }
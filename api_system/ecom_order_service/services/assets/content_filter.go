@@ -0,0 +1,89 @@
+package assets_services
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ContentFilter lọc từ ngữ cấm trong nội dung người dùng nhập (đánh giá sản phẩm).
+// So khớp theo từng từ, không phân biệt hoa thường, cụm nhiều từ phải khớp các từ liền nhau.
+// Giữ nguyên dấu tiếng Việt khi so khớp để tránh che nhầm từ bình thường (ví dụ "lớn" và "lồn").
+// Nội dung và từ cấm được chuẩn hóa NFC trước khi so khớp, vì bàn phím/IME có thể gửi dấu dạng tổ hợp (NFD).
+type ContentFilter struct {
+	terms [][]string
+}
+
+type wordSpan struct {
+	start, end int // vị trí rune trong nội dung
+	word       string
+}
+
+func NewContentFilter(terms []string) *ContentFilter {
+	f := &ContentFilter{}
+	for _, term := range terms {
+		var words []string
+		for _, span := range splitWords([]rune(norm.NFC.String(term))) {
+			words = append(words, span.word)
+		}
+		if len(words) > 0 {
+			f.terms = append(f.terms, words)
+		}
+	}
+	return f
+}
+
+// Mask che các từ cấm bằng '*' (giữ nguyên độ dài), found cho biết nội dung có từ cấm hay không.
+// masked luôn ở dạng NFC.
+func (f *ContentFilter) Mask(text string) (masked string, found bool) {
+	if f == nil || len(f.terms) == 0 || text == "" {
+		return text, false
+	}
+	text = norm.NFC.String(text)
+	runes := []rune(text)
+	words := splitWords(runes)
+	for i := range words {
+		for _, term := range f.terms {
+			if i+len(term) > len(words) || !matchWords(words[i:i+len(term)], term) {
+				continue
+			}
+			found = true
+			for _, w := range words[i : i+len(term)] {
+				for j := w.start; j < w.end; j++ {
+					runes[j] = '*'
+				}
+			}
+		}
+	}
+	if !found {
+		return text, false
+	}
+	return string(runes), true
+}
+
+func matchWords(words []wordSpan, term []string) bool {
+	for i, w := range term {
+		if words[i].word != w {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords tách nội dung thành các từ (chuỗi chữ/số liên tiếp), từ được chuyển về chữ thường
+func splitWords(runes []rune) []wordSpan {
+	var spans []wordSpan
+	start := -1
+	for i := 0; i <= len(runes); i++ {
+		isWord := i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsNumber(runes[i]) || unicode.Is(unicode.Mn, runes[i]))
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			spans = append(spans, wordSpan{start: start, end: i, word: strings.ToLower(string(runes[start:i]))})
+			start = -1
+		}
+	}
+	return spans
+}
@@ -0,0 +1,28 @@
+package assets_services
+
+import "testing"
+
+func TestContentFilterMask(t *testing.T) {
+	f := NewContentFilter([]string{"vcl", "Óc chó", "fuck"})
+
+	tests := []struct {
+		in     string
+		want   string
+		banned bool
+	}{
+		{"Hàng đẹp, giao nhanh", "Hàng đẹp, giao nhanh", false},
+		{"Shop lừa đảo VCL", "Shop lừa đảo ***", true},
+		{"đồ óc  chó!", "đồ **  ***!", true},
+		{"fucking great", "fucking great", false},
+		{"Fuck, fuck.", "****, ****.", true},
+		{"Ốc chó ngon", "Ốc chó ngon", false},
+		// dấu gửi dạng tổ hợp (NFD): "o" + dấu sắc
+		{"đồ o\u0301c cho\u0301", "đồ ** ***", true},
+	}
+	for _, tt := range tests {
+		got, banned := f.Mask(tt.in)
+		if got != tt.want || banned != tt.banned {
+			t.Errorf("Mask(%q) = %q, %v; want %q, %v", tt.in, got, banned, tt.want, tt.banned)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package services_assets_sendMessage
+
+import (
+	"firebase.google.com/go/messaging"
+)
+
+// độ dài tối đa của nội dung phản hồi hiển thị trong thông báo
+const maxReplyPreview = 100
+
+// UserTopic trả về topic firebase mà app của người mua đăng ký để nhận thông báo
+func UserTopic(userID string) string {
+	return "user_" + userID
+}
+
+// PhanHoiDanhGia thông báo cho người mua khi đánh giá có phản hồi, replier là "Người bán" hoặc "Sàn"
+func PhanHoiDanhGia(replier, reply string) *messaging.Notification {
+	if r := []rune(reply); len(r) > maxReplyPreview {
+		reply = string(r[:maxReplyPreview]) + "..."
+	}
+	return &messaging.Notification{
+		Title: "Đánh giá của bạn đã có phản hồi",
+		Body:  replier + " đã phản hồi đánh giá của bạn: \"" + reply + "\"",
+	}
+}
+
+// DanhGiaBiAn thông báo cho người viết khi đánh giá/phản hồi bị admin ẩn
+func DanhGiaBiAn() *messaging.Notification {
+	return &messaging.Notification{
+		Title: "Đánh giá của bạn đã bị ẩn",
+		Body:  "Đánh giá của bạn vi phạm tiêu chuẩn cộng đồng và đã bị ẩn khỏi trang sản phẩm",
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 
 	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
@@ -26,15 +27,42 @@ func getMediaOrEmpty(media sql.NullString) interface{} {
 	return result
 }
 
+// toCommentResponse chuyển bản ghi product_comment sang response, các cột NULL trả về nil
+func toCommentResponse(comment db.ProductComment) services.CommentResponse {
+	resp := services.CommentResponse{
+		CommentID:   comment.CommentID,
+		OrderItemID: comment.OrderItemID.String,
+		ProductID:   comment.ProductID,
+		SkuID:       comment.SkuID,
+		UserID:      comment.UserID,
+		Rating:      int32(comment.Rating),
+		Content:     comment.Content.String,
+		Media:       getMediaOrEmpty(comment.Media),
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+		Status:      string(comment.Status),
+		LikeCount:   int64(comment.LikeCount),
+	}
+	if comment.SkuNameSnapshot.Valid {
+		resp.SkuNameSnapshot = &comment.SkuNameSnapshot.String
+	}
+	if comment.Title.Valid {
+		resp.Title = &comment.Title.String
+	}
+	if comment.ParentID.Valid {
+		resp.ParentID = &comment.ParentID.String
+	}
+	return resp
+}
+
 // CreateComment xử lý việc tạo bình luận/đánh giá sản phẩm
-func (s *service) CreateComment(ctx context.Context, userID string, req services.CreateCommentRequest) *assets_services.ServiceError {
-	// Kiểm tra nếu có parent_id (reply comment)
+// files là ảnh/video đính kèm, được upload lên media service bằng token của người mua
+func (s *service) CreateComment(ctx context.Context, userID, token string, req services.CreateCommentRequest, files []*multipart.FileHeader) (*services.CommentResponse, *assets_services.ServiceError) {
+	// Phản hồi của Shop/Admin đi qua PUT /comments/:commentID/reply, người mua chỉ tạo đánh giá gốc
 	if req.ParentID != nil && *req.ParentID != "" {
-		// TODO: Xử lý logic reply comment ở đây (tạm thời để None)
-		// Có thể cần kiểm tra parent comment có tồn tại không, v.v.
-		return assets_services.NewError(
-			http.StatusNotImplemented,
-			errors.New("reply comment feature is not implemented yet"),
+		return nil, assets_services.NewError(
+			http.StatusBadRequest,
+			errors.New("không thể trả lời đánh giá qua API này, shop/admin dùng PUT /comments/{comment_id}/reply"),
 		)
 	}
 
@@ -45,35 +73,46 @@ func (s *service) CreateComment(ctx context.Context, userID string, req services
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return assets_services.NewError(
+			return nil, assets_services.NewError(
 				http.StatusForbidden,
 				errors.New("bạn không có quyền đánh giá sản phẩm này. Chỉ có thể đánh giá sau khi đơn hàng hoàn thành"),
 			)
 		}
-		return assets_services.NewError(
+		return nil, assets_services.NewError(
 			http.StatusInternalServerError,
 			fmt.Errorf("lỗi khi kiểm tra quyền đánh giá: %w", err),
 		)
 	}
 
 	// Bước 2: Kiểm tra xem order_item này đã được review chưa
-	_, err = s.repository.GetCommentByOrderItemID(ctx, req.OrderItemID)
+	orderItemID := sql.NullString{String: req.OrderItemID, Valid: true}
+	_, err = s.repository.GetCommentByOrderItemID(ctx, orderItemID)
 	if err == nil {
 		// Nếu không có lỗi, nghĩa là đã tồn tại review
-		return assets_services.NewError(
+		return nil, assets_services.NewError(
 			http.StatusConflict,
 			errors.New("bạn đã đánh giá sản phẩm này rồi. Mỗi sản phẩm chỉ được đánh giá 1 lần"),
 		)
 	}
 	if err != sql.ErrNoRows {
 		// Nếu lỗi khác ErrNoRows, có nghĩa là lỗi DB
-		return assets_services.NewError(
+		return nil, assets_services.NewError(
 			http.StatusInternalServerError,
 			fmt.Errorf("lỗi khi kiểm tra đánh giá hiện có: %w", err),
 		)
 	}
 
-	// Bước 3: Tạo comment mới
+	// Bước 3: Upload ảnh/video đính kèm (tối đa N ảnh hoặc 1 video)
+	uploaded, serr := s.uploadReviewMedia(token, files)
+	if serr != nil {
+		return nil, serr
+	}
+	media, serr := s.buildReviewMedia(uploaded)
+	if serr != nil {
+		return nil, serr
+	}
+
+	// Bước 4: Tạo comment mới
 	commentID := uuid.New().String()
 
 	// Lấy sku_name_snapshot (có thể cần query thêm nếu cần, hiện tại set NULL)
@@ -81,7 +120,7 @@ func (s *service) CreateComment(ctx context.Context, userID string, req services
 
 	params := db.CreateCommentParams{
 		CommentID:       commentID,
-		OrderItemID:     req.OrderItemID,
+		OrderItemID:     orderItemID,
 		ProductID:       permission.ProductID,
 		SkuID:           permission.SkuID,
 		UserID:          userID,
@@ -95,7 +134,7 @@ func (s *service) CreateComment(ctx context.Context, userID string, req services
 			String: req.Comment,
 			Valid:  true,
 		},
-		Media: sql.NullString{},
+		Media: media,
 		ParentID: sql.NullString{
 			String: "",
 			Valid:  false,
@@ -110,24 +149,47 @@ func (s *service) CreateComment(ctx context.Context, userID string, req services
 		}
 	}
 
-	// Thực hiện insert vào DB
-	err = s.repository.CreateComment(ctx, params)
+	// Lọc từ ngữ cấm: che bằng '*' hoặc giữ lại chờ admin duyệt tùy REVIEW_FILTER_ACTION
+	params.Title, params.Content.String, params.Status = s.moderateReview(params.Title, params.Content.String, db.ProductCommentStatusVISIBLE)
+
+	// Thực hiện insert vào DB, tổng hợp điểm đánh giá của sản phẩm được tính lại trong cùng transaction
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		if err := tx.CreateComment(ctx, params); err != nil {
+			return fmt.Errorf("lỗi khi tạo đánh giá: %w", err)
+		}
+		return s.refreshRatingSummary(ctx, tx, permission.ProductID)
+	})
+	if txErr != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, txErr)
+	}
+
+	// Bước 5: Đồng bộ điểm đánh giá sang product service (sắp xếp theo đánh giá, làm mới cache chi tiết sản phẩm)
+	s.syncProductRating(ctx, permission.ProductID)
+
+	comment, err := s.repository.GetCommentByID(ctx, commentID)
 	if err != nil {
-		return assets_services.NewError(
+		return nil, assets_services.NewError(
 			http.StatusInternalServerError,
-			fmt.Errorf("lỗi khi tạo đánh giá: %w", err),
+			fmt.Errorf("lỗi khi lấy đánh giá vừa tạo: %w", err),
 		)
 	}
-
-	return nil
+	resp := toCommentResponse(comment)
+	return &resp, nil
 }
 
 // ListComments lấy danh sách bình luận cho một sản phẩm (có phân trang)
 // Các comment con (replies) sẽ được nest vào comment cha
-func (s *service) ListComments(ctx context.Context, req services.ListCommentsRequest) (map[string]interface{}, *assets_services.ServiceError) {
+// userID rỗng khi người xem chưa đăng nhập, khi đó liked_by_me luôn là false
+func (s *service) ListComments(ctx context.Context, userID string, req services.ListCommentsRequest) (map[string]interface{}, *assets_services.ServiceError) {
 	// Bước 1: Lấy danh sách comment gốc (parent_id IS NULL)
+	rating := sql.NullInt16{Int16: int16(req.Rating), Valid: req.Rating > 0}
+	skuID := sql.NullString{String: req.SkuID, Valid: req.SkuID != ""}
 	comments, err := s.repository.ListCommentsByProduct(ctx, db.ListCommentsByProductParams{
 		ProductID: req.ProductID,
+		Rating:    rating,
+		SkuID:     skuID,
+		HasMedia:  req.HasMedia,
+		SortBy:    req.Sort,
 		Limit:     req.PageSize,
 		Offset:    int32(req.PageSize * (req.Page - 1)),
 	})
@@ -137,48 +199,49 @@ func (s *service) ListComments(ctx context.Context, req services.ListCommentsReq
 			fmt.Errorf("lỗi khi lấy danh sách bình luận: %w", err),
 		)
 	}
-
-	// Bước 2: Lấy thống kê rating
-	stats, err := s.repository.GetProductRatingStats(ctx, req.ProductID)
-	if err != nil && err != sql.ErrNoRows {
+	total, err := s.repository.CountCommentsByProduct(ctx, db.CountCommentsByProductParams{
+		ProductID: req.ProductID,
+		Rating:    rating,
+		SkuID:     skuID,
+		HasMedia:  req.HasMedia,
+	})
+	if err != nil {
 		return nil, assets_services.NewError(
 			http.StatusInternalServerError,
-			fmt.Errorf("lỗi khi lấy thống kê đánh giá: %w", err),
+			fmt.Errorf("lỗi khi đếm số bình luận: %w", err),
 		)
 	}
 
-	// Bước 3: Build response với nested children
-	var commentResponses []services.CommentResponse
-	for _, comment := range comments {
-		commentResp := services.CommentResponse{
-			CommentID:       comment.CommentID,
-			OrderItemID:     comment.OrderItemID,
-			ProductID:       comment.ProductID,
-			SkuID:           comment.SkuID,
-			UserID:          comment.UserID,
-			SkuNameSnapshot: nil,
-			Rating:          int32(comment.Rating),
-			Title:           nil,
-			Content:         comment.Content.String,
-			Media:           getMediaOrEmpty(comment.Media),
-			ParentID:        nil,
-			CreatedAt:       comment.CreatedAt,
-			UpdatedAt:       comment.UpdatedAt,
-			Children:        []services.CommentResponse{},
+	// Các đánh giá trong trang mà người xem đã bấm "Hữu ích"
+	likedByMe := map[string]bool{}
+	if userID != "" && len(comments) > 0 {
+		reviewIDs := make([]string, 0, len(comments))
+		for _, comment := range comments {
+			reviewIDs = append(reviewIDs, comment.CommentID)
 		}
-
-		// Set optional fields
-		if comment.SkuNameSnapshot.Valid {
-			commentResp.SkuNameSnapshot = &comment.SkuNameSnapshot.String
-		}
-		if comment.Title.Valid {
-			commentResp.Title = &comment.Title.String
+		liked, err := s.repository.ListReviewsLikedByUser(ctx, db.ListReviewsLikedByUserParams{
+			UserID:    userID,
+			ReviewIds: reviewIDs,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(
+				http.StatusInternalServerError,
+				fmt.Errorf("lỗi khi lấy lượt hữu ích của người dùng: %w", err),
+			)
 		}
-		if comment.ParentID.Valid {
-			commentResp.ParentID = &comment.ParentID.String
+		for _, id := range liked {
+			likedByMe[id] = true
 		}
+	}
+
+	// Bước 2: Build response với nested children
+	var commentResponses []services.CommentResponse
+	for _, comment := range comments {
+		commentResp := toCommentResponse(comment)
+		commentResp.LikedByMe = likedByMe[comment.CommentID]
+		commentResp.Children = []services.CommentResponse{}
 
-		// Lấy các replies (children) cho comment này
+		// Lấy phản hồi của Shop/Admin (children) cho comment này
 		replies, err := s.repository.GetRepliesByCommentID(ctx, sql.NullString{
 			String: comment.CommentID,
 			Valid:  true,
@@ -187,60 +250,17 @@ func (s *service) ListComments(ctx context.Context, req services.ListCommentsReq
 			// Log error nhưng không fail toàn bộ request
 			fmt.Printf("Warning: Lỗi khi lấy replies cho comment %s: %v\n", comment.CommentID, err)
 		}
-
-		// Map replies vào children
-		if len(replies) > 0 {
-			for _, reply := range replies {
-				replyResp := services.CommentResponse{
-					CommentID:       reply.CommentID,
-					OrderItemID:     reply.OrderItemID,
-					ProductID:       reply.ProductID,
-					SkuID:           reply.SkuID,
-					UserID:          reply.UserID,
-					SkuNameSnapshot: nil,
-					Rating:          int32(reply.Rating),
-					Title:           nil,
-					Content:         reply.Content.String,
-					Media:           getMediaOrEmpty(reply.Media),
-					ParentID:        nil,
-					CreatedAt:       reply.CreatedAt,
-					UpdatedAt:       reply.UpdatedAt,
-				}
-
-				// Set optional fields cho reply
-				if reply.SkuNameSnapshot.Valid {
-					replyResp.SkuNameSnapshot = &reply.SkuNameSnapshot.String
-				}
-				if reply.Title.Valid {
-					replyResp.Title = &reply.Title.String
-				}
-				if reply.ParentID.Valid {
-					replyResp.ParentID = &reply.ParentID.String
-				}
-
-				commentResp.Children = append(commentResp.Children, replyResp)
+		for _, reply := range replies {
+			if reply.Status != db.ProductCommentStatusVISIBLE {
+				continue
 			}
+			commentResp.Children = append(commentResp.Children, toCommentResponse(reply))
 		}
 
 		commentResponses = append(commentResponses, commentResp)
 	}
 
-	// Bước 4: Parse average rating từ interface{}
-	averageRating := 0.0
-	if stats.AverageRating != nil {
-		// MySQL AVG() trả về []uint8 hoặc nil
-		switch v := stats.AverageRating.(type) {
-		case []uint8:
-			// Convert []uint8 to string then to float64
-			fmt.Sscanf(string(v), "%f", &averageRating)
-		case float64:
-			averageRating = v
-		case float32:
-			averageRating = float64(v)
-		}
-	}
-
-	// Bước 5: Build final response
+	// Bước 3: Build final response
 	// result := map[string]interface{}{
 	// 	"data": commentResponses,
 	// 	"stats": map[string]interface{}{
@@ -256,8 +276,8 @@ func (s *service) ListComments(ctx context.Context, req services.ListCommentsReq
 	result := map[string]interface{}{}
 	result["data"] = commentResponses
 	result["currentPage"] = req.Page
-	result["totalPages"] = (stats.TotalReviews + int64(req.PageSize) - 1) / int64(req.PageSize)
-	result["totalElements"] = stats.TotalReviews
+	result["totalPages"] = (total + int64(req.PageSize) - 1) / int64(req.PageSize)
+	result["totalElements"] = total
 	result["limit"] = req.PageSize
 
 	return result, nil
@@ -273,60 +293,45 @@ func (s *service) CheckReviewedItems(ctx context.Context, req services.CheckRevi
 	}
 
 	// Gọi query để check bulk
-	reviewedItems, err := s.repository.CheckBulkOrderItemsReviewed(ctx, req.OrderItemIDs)
+	orderItemIDs := make([]sql.NullString, 0, len(req.OrderItemIDs))
+	for _, id := range req.OrderItemIDs {
+		orderItemIDs = append(orderItemIDs, sql.NullString{String: id, Valid: true})
+	}
+	rows, err := s.repository.CheckBulkOrderItemsReviewed(ctx, orderItemIDs)
 	if err != nil {
 		return nil, assets_services.NewError(
 			http.StatusInternalServerError,
 			fmt.Errorf("lỗi khi kiểm tra các đánh giá: %w", err),
 		)
 	}
+	reviewedItems := make([]string, 0, len(rows))
+	for _, id := range rows {
+		reviewedItems = append(reviewedItems, id.String)
+	}
 
 	return &services.CheckReviewedItemsResponse{
 		ReviewedOrderItemIDs: reviewedItems,
 	}, nil
 }
 
-// GetBulkProductRatingStats lấy thống kê đánh giá cho nhiều sản phẩm cùng lúc
-// Trả về map với product_id là key và thông tin rating là value
-func (s *service) GetBulkProductRatingStats(ctx context.Context, req services.GetBulkProductRatingStatsRequest) (map[string]interface{}, *assets_services.ServiceError) {
-	if len(req.ProductIDs) == 0 {
-		return map[string]interface{}{}, nil
-
-	}
-
-	// Gọi query để lấy stats cho nhiều products
-	stats, err := s.repository.GetBulkProductRatingStats(ctx, req.ProductIDs)
-	if err != nil {
-		return nil, assets_services.NewError(
-			http.StatusInternalServerError,
-			fmt.Errorf("lỗi khi lấy thống kê đánh giá sản phẩm: %w", err),
-		)
-	}
-
-	// Build map response
-	statsMap := []services.ProductRatingStatsItem{}
-	for _, stat := range stats {
-		// Parse average rating từ interface{}
-		averageRating := 0.0
-		if stat.AverageRating != nil {
-			switch v := stat.AverageRating.(type) {
-			case []uint8:
-				// MySQL AVG() trả về []uint8
-				fmt.Sscanf(string(v), "%f", &averageRating)
-			case float64:
-				averageRating = v
-			case float32:
-				averageRating = float64(v)
-			}
+// ListCommentMediaReferences trả về các file trong danh sách vẫn còn được bình luận sử dụng.
+// Product service gọi trước khi dọn file media không còn được tham chiếu.
+func (s *service) ListCommentMediaReferences(ctx context.Context, req services.CommentMediaReferencesRequest) (map[string]interface{}, *assets_services.ServiceError) {
+	referenced := make([]string, 0)
+	for _, file := range req.Files {
+		if file == "" {
+			continue
+		}
+		count, err := s.repository.CountCommentsWithMedia(ctx, file)
+		if err != nil {
+			return nil, assets_services.NewError(
+				http.StatusInternalServerError,
+				fmt.Errorf("lỗi khi kiểm tra media của bình luận: %w", err),
+			)
+		}
+		if count > 0 {
+			referenced = append(referenced, file)
 		}
-
-		statsMap = append(statsMap, services.ProductRatingStatsItem{
-			ProductID:     stat.ProductID,
-			TotalReviews:  stat.TotalReviews,
-			AverageRating: averageRating,
-		})
 	}
-	result := map[string]interface{}{}
-	result["data"] = statsMap
-	return result, nil
+	return map[string]interface{}{"data": referenced}, nil
 }
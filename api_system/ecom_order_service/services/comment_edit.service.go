@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultReviewMaxImages  = 5
+	defaultReviewEditWindow = 7 * 24 * time.Hour
+)
+
+var errReviewAlreadyEdited = errors.New("mỗi đánh giá chỉ được sửa 1 lần")
+
+func (s *service) reviewMaxImages() int {
+	if s.env.ReviewMaxImages > 0 {
+		return s.env.ReviewMaxImages
+	}
+	return defaultReviewMaxImages
+}
+
+func (s *service) reviewEditWindow() time.Duration {
+	if s.env.ReviewEditWindow > 0 {
+		return s.env.ReviewEditWindow
+	}
+	return defaultReviewEditWindow
+}
+
+// isReviewVideo media service lưu video dạng .mp4, còn lại là ảnh
+func isReviewVideo(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), ".mp4")
+}
+
+// uploadReviewMedia upload file đính kèm đánh giá lên media service bằng token của người mua.
+// File thừa khi đánh giá không được lưu sẽ được job dọn media của product service xóa sau.
+func (s *service) uploadReviewMedia(token string, files []*multipart.FileHeader) ([]string, *assets_services.ServiceError) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if len(files) > s.reviewMaxImages() {
+		return nil, s.reviewMediaLimitError()
+	}
+	names, err := s.apiServer.UploadReviewMedia(token, files)
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("không thể upload ảnh/video đánh giá: %w", err))
+	}
+	return names, nil
+}
+
+// buildReviewMedia kiểm tra số lượng (tối đa N ảnh hoặc 1 video, không trộn lẫn) và trả về giá trị cột media
+func (s *service) buildReviewMedia(names []string) (sql.NullString, *assets_services.ServiceError) {
+	if len(names) == 0 {
+		return sql.NullString{}, nil
+	}
+	videos := 0
+	for _, name := range names {
+		if isReviewVideo(name) {
+			videos++
+		}
+	}
+	if videos > 1 || (videos == 1 && len(names) > 1) || len(names) > s.reviewMaxImages() {
+		return sql.NullString{}, s.reviewMediaLimitError()
+	}
+	data, err := json.Marshal(names)
+	if err != nil {
+		return sql.NullString{}, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lưu media đánh giá: %w", err))
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func (s *service) reviewMediaLimitError() *assets_services.ServiceError {
+	return assets_services.NewError(http.StatusBadRequest, fmt.Errorf("mỗi đánh giá chỉ được kèm tối đa %d ảnh hoặc 1 video", s.reviewMaxImages()))
+}
+
+// reviewMediaNames đọc danh sách file từ cột media (mảng JSON)
+func reviewMediaNames(media sql.NullString) []string {
+	var names []string
+	if media.Valid && media.String != "" {
+		if err := json.Unmarshal([]byte(media.String), &names); err != nil {
+			return nil
+		}
+	}
+	return names
+}
+
+// UpdateComment người mua sửa đánh giá của mình 1 lần trong thời hạn cho phép.
+// Nội dung cũ được lưu vào lịch sử, tổng hợp điểm đánh giá của sản phẩm được tính lại theo số sao mới.
+func (s *service) UpdateComment(ctx context.Context, userID, token, commentID string, req services.UpdateCommentRequest, files []*multipart.FileHeader) (*services.CommentResponse, *assets_services.ServiceError) {
+	comment, err := s.repository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá: %w", err))
+	}
+	if comment.ParentID.Valid {
+		return nil, assets_services.NewError(http.StatusBadRequest, errors.New("không thể sửa phản hồi qua API này"))
+	}
+	if comment.UserID != userID {
+		return nil, assets_services.NewError(http.StatusForbidden, errors.New("bạn không có quyền sửa đánh giá này"))
+	}
+	if window := s.reviewEditWindow(); time.Since(comment.CreatedAt) > window {
+		return nil, assets_services.NewError(http.StatusForbidden, fmt.Errorf("đã quá thời hạn sửa đánh giá (%s kể từ khi đánh giá)", window))
+	}
+	edits, err := s.repository.CountCommentHistory(ctx, commentID)
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi kiểm tra lịch sử sửa đánh giá: %w", err))
+	}
+	if edits > 0 {
+		return nil, assets_services.NewError(http.StatusConflict, errReviewAlreadyEdited)
+	}
+
+	// chỉ giữ lại được file đang thuộc đánh giá này
+	current := map[string]bool{}
+	for _, name := range reviewMediaNames(comment.Media) {
+		current[name] = true
+	}
+	names := make([]string, 0, len(req.KeepMedia)+len(files))
+	for _, name := range req.KeepMedia {
+		if !current[name] {
+			return nil, assets_services.NewError(http.StatusBadRequest, fmt.Errorf("file %s không thuộc đánh giá này", name))
+		}
+		names = append(names, name)
+	}
+	if len(names)+len(files) > s.reviewMaxImages() {
+		return nil, s.reviewMediaLimitError()
+	}
+	uploaded, serr := s.uploadReviewMedia(token, files)
+	if serr != nil {
+		return nil, serr
+	}
+	media, serr := s.buildReviewMedia(append(names, uploaded...))
+	if serr != nil {
+		return nil, serr
+	}
+
+	params := db.UpdateCommentByBuyerParams{
+		Rating:    int8(req.Star),
+		Content:   sql.NullString{String: req.Comment, Valid: true},
+		Media:     media,
+		CommentID: commentID,
+	}
+	if req.Title != nil && *req.Title != "" {
+		params.Title = sql.NullString{String: *req.Title, Valid: true}
+	}
+	// nội dung mới dính từ cấm thì bị che hoặc chuyển về chờ duyệt, đánh giá đang bị ẩn vẫn giữ trạng thái ẩn
+	params.Title, params.Content.String, params.Status = s.moderateReview(params.Title, params.Content.String, comment.Status)
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		rows, err := tx.CreateCommentHistory(ctx, db.CreateCommentHistoryParams{
+			ID:        uuid.New().String(),
+			EditedBy:  userID,
+			CommentID: commentID,
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi lưu lịch sử đánh giá: %w", err)
+		}
+		if rows != 1 {
+			return errReviewAlreadyEdited
+		}
+		if err := tx.UpdateCommentByBuyer(ctx, params); err != nil {
+			return fmt.Errorf("lỗi khi sửa đánh giá: %w", err)
+		}
+		return s.refreshRatingSummary(ctx, tx, comment.ProductID)
+	})
+	if txErr != nil {
+		if errors.Is(txErr, errReviewAlreadyEdited) {
+			return nil, assets_services.NewError(http.StatusConflict, txErr)
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, txErr)
+	}
+
+	// số sao thay đổi thì điểm đánh giá của sản phẩm thay đổi
+	s.syncProductRating(ctx, comment.ProductID)
+
+	updated, err := s.repository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá vừa sửa: %w", err))
+	}
+	resp := toCommentResponse(updated)
+	return &resp, nil
+}
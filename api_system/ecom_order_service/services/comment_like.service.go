@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+)
+
+// ToggleReviewLike bấm hoặc bỏ bấm "Hữu ích" cho một đánh giá.
+// Chỉ đánh giá gốc mới được bấm, người viết đánh giá không tự bấm cho đánh giá của mình.
+func (s *service) ToggleReviewLike(ctx context.Context, userID, commentID string) (*services.ReviewLikeResponse, *assets_services.ServiceError) {
+	review, err := s.repository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá: %w", err))
+	}
+	if review.Status != db.ProductCommentStatusVISIBLE {
+		return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+	}
+	if review.ParentID.Valid {
+		return nil, assets_services.NewError(http.StatusBadRequest, errors.New("chỉ có thể bấm hữu ích cho đánh giá, không áp dụng cho phản hồi"))
+	}
+	if review.UserID == userID {
+		return nil, assets_services.NewError(http.StatusForbidden, errors.New("bạn không thể bấm hữu ích cho đánh giá của chính mình"))
+	}
+
+	result := &services.ReviewLikeResponse{CommentID: commentID}
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		liked, err := tx.ListReviewsLikedByUser(ctx, db.ListReviewsLikedByUserParams{
+			UserID:    userID,
+			ReviewIds: []string{commentID},
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi kiểm tra lượt hữu ích: %w", err)
+		}
+		// bấm trùng do hai request đồng thời không tạo thêm dòng, lượt bấm vẫn coi là thành công
+		// và like_count chỉ đổi khi review_likes thực sự thay đổi
+		var changed int64
+		var delta int32
+		if len(liked) > 0 {
+			changed, err = tx.DeleteReviewLike(ctx, db.DeleteReviewLikeParams{ReviewID: commentID, UserID: userID})
+			delta = -1
+		} else {
+			changed, err = tx.CreateReviewLike(ctx, db.CreateReviewLikeParams{ReviewID: commentID, UserID: userID})
+			delta = 1
+		}
+		if err != nil {
+			return fmt.Errorf("lỗi khi cập nhật lượt hữu ích: %w", err)
+		}
+		result.Liked = len(liked) == 0
+
+		if changed > 0 {
+			err = tx.AdjustCommentLikeCount(ctx, db.AdjustCommentLikeCountParams{Delta: delta, CommentID: commentID})
+			if err != nil {
+				return fmt.Errorf("lỗi khi cập nhật số lượt hữu ích: %w", err)
+			}
+		}
+		current, err := tx.GetCommentByID(ctx, commentID)
+		if err != nil {
+			return fmt.Errorf("lỗi khi đếm lượt hữu ích: %w", err)
+		}
+		result.LikeCount = int64(current.LikeCount)
+		return nil
+	})
+	if txErr != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, txErr)
+	}
+	return result, nil
+}
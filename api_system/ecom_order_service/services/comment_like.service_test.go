@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+)
+
+// likeStore giữ lượt bấm của một đánh giá, inserted giả lập số dòng INSERT ... ON DUPLICATE KEY trả về
+type likeStore struct {
+	db_mysql.Store
+	likedBefore bool
+	inserted    int64
+	likeCount   int32
+	adjusts     int
+}
+
+func (f *likeStore) ExecTS(ctx context.Context, fn func(tx db.Querier) error) error {
+	return fn(f)
+}
+
+func (f *likeStore) GetCommentByID(ctx context.Context, commentID string) (db.ProductComment, error) {
+	return db.ProductComment{CommentID: commentID, UserID: "author", Status: db.ProductCommentStatusVISIBLE, LikeCount: f.likeCount}, nil
+}
+
+func (f *likeStore) ListReviewsLikedByUser(ctx context.Context, arg db.ListReviewsLikedByUserParams) ([]string, error) {
+	if f.likedBefore {
+		return arg.ReviewIds, nil
+	}
+	return nil, nil
+}
+
+func (f *likeStore) CreateReviewLike(ctx context.Context, arg db.CreateReviewLikeParams) (int64, error) {
+	return f.inserted, nil
+}
+
+func (f *likeStore) DeleteReviewLike(ctx context.Context, arg db.DeleteReviewLikeParams) (int64, error) {
+	return 1, nil
+}
+
+func (f *likeStore) AdjustCommentLikeCount(ctx context.Context, arg db.AdjustCommentLikeCountParams) error {
+	f.adjusts++
+	f.likeCount += arg.Delta
+	return nil
+}
+
+func TestToggleReviewLikeDuplicateInsert(t *testing.T) {
+	// request đồng thời đã ghi lượt bấm giữa lúc kiểm tra và lúc INSERT
+	store := &likeStore{inserted: 0, likeCount: 3}
+	s := &service{repository: store}
+
+	result, err := s.ToggleReviewLike(context.Background(), "buyer", "c-1")
+	if err != nil {
+		t.Fatalf("bấm trùng phải thành công, nhận lỗi %v", err)
+	}
+	if !result.Liked || result.LikeCount != 3 {
+		t.Fatalf("muốn liked=true, like_count=3, nhận %+v", result)
+	}
+	if store.adjusts != 0 {
+		t.Fatalf("không được tăng like_count khi không thêm dòng nào, đã cập nhật %d lần", store.adjusts)
+	}
+}
+
+func TestToggleReviewLikeCounter(t *testing.T) {
+	store := &likeStore{inserted: 1, likeCount: 3}
+	s := &service{repository: store}
+
+	result, err := s.ToggleReviewLike(context.Background(), "buyer", "c-1")
+	if err != nil {
+		t.Fatalf("lỗi khi bấm hữu ích: %v", err)
+	}
+	if !result.Liked || result.LikeCount != 4 {
+		t.Fatalf("muốn liked=true, like_count=4, nhận %+v", result)
+	}
+
+	store.likedBefore = true
+	result, err = s.ToggleReviewLike(context.Background(), "buyer", "c-1")
+	if err != nil {
+		t.Fatalf("lỗi khi bỏ bấm hữu ích: %v", err)
+	}
+	if result.Liked || result.LikeCount != 3 {
+		t.Fatalf("muốn liked=false, like_count=3, nhận %+v", result)
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	sendMessage "github.com/TranVinhHien/ecom_order_service/services/assets/sendMessage"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+	"github.com/google/uuid"
+)
+
+// REVIEW_FILTER_ACTION=hold: đánh giá chứa từ cấm được giữ nguyên nội dung và chờ admin duyệt,
+// mặc định (mask) thì che từ cấm bằng '*' và hiển thị luôn
+const reviewFilterHold = "hold"
+
+// defaultReviewBannedTerms danh sách từ cấm mặc định khi không cấu hình REVIEW_BANNED_TERMS
+var defaultReviewBannedTerms = []string{
+	// tiếng Việt (cả dạng viết tắt, không dấu phổ biến)
+	"đm", "đmm", "đcm", "dcm", "vcl", "vkl", "clgt",
+	"địt", "đụ", "lồn", "cặc", "buồi", "đĩ", "óc chó", "mẹ mày",
+	// tiếng Anh
+	"fuck", "fucking", "shit", "bitch", "asshole", "bastard", "cunt", "motherfucker",
+}
+
+// moderateReview lọc từ cấm trong tiêu đề và nội dung đánh giá của người mua hoặc phản hồi của Shop/Admin.
+// status là trạng thái hiện tại của đánh giá, được giữ nguyên khi nội dung sạch.
+func (s *service) moderateReview(title sql.NullString, content string, status db.ProductCommentStatus) (sql.NullString, string, db.ProductCommentStatus) {
+	maskedTitle, titleBanned := s.reviewFilter.Mask(title.String)
+	maskedContent, contentBanned := s.reviewFilter.Mask(content)
+	if !titleBanned && !contentBanned {
+		return title, content, status
+	}
+	if s.env.ReviewFilterAction == reviewFilterHold {
+		return title, content, db.ProductCommentStatusPENDING
+	}
+	if title.Valid {
+		title.String = maskedTitle
+	}
+	return title, maskedContent, status
+}
+
+// ReportComment người dùng báo cáo một đánh giá/phản hồi vi phạm, mỗi người chỉ báo cáo 1 lần
+func (s *service) ReportComment(ctx context.Context, userID, commentID string, req services.ReportCommentRequest) *assets_services.ServiceError {
+	comment, err := s.repository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+		}
+		return assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá: %w", err))
+	}
+	// đánh giá đang ẩn/chờ duyệt không hiển thị cho người dùng nên không thể bị báo cáo
+	if comment.Status != db.ProductCommentStatusVISIBLE {
+		return assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+	}
+	if comment.UserID == userID {
+		return assets_services.NewError(http.StatusBadRequest, errors.New("bạn không thể báo cáo đánh giá của chính mình"))
+	}
+
+	err = s.repository.CreateCommentReport(ctx, db.CreateCommentReportParams{
+		ID:         uuid.New().String(),
+		CommentID:  commentID,
+		ReporterID: userID,
+		Reason:     db.CommentReportsReason(req.Reason),
+		Note:       sql.NullString{String: req.Note, Valid: req.Note != ""},
+	})
+	if err != nil {
+		// uq_comment_reporter chặn báo cáo trùng
+		return assets_services.NewError(http.StatusConflict, fmt.Errorf("không thể gửi báo cáo, bạn có thể đã báo cáo đánh giá này: %w", err))
+	}
+	return nil
+}
+
+// ListModerationQueue hàng chờ kiểm duyệt cho admin: đánh giá chờ duyệt hoặc đang bị báo cáo, kèm các báo cáo chưa xử lý
+func (s *service) ListModerationQueue(ctx context.Context, req services.ModerationQueueRequest) (map[string]interface{}, *assets_services.ServiceError) {
+	rows, err := s.repository.ListModerationQueue(ctx, db.ListModerationQueueParams{
+		Limit:  req.PageSize,
+		Offset: req.PageSize * (req.Page - 1),
+	})
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy hàng chờ kiểm duyệt: %w", err))
+	}
+	total, err := s.repository.CountModerationQueue(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi đếm hàng chờ kiểm duyệt: %w", err))
+	}
+
+	reports := map[string][]services.CommentReportResponse{}
+	if len(rows) > 0 {
+		commentIDs := make([]string, 0, len(rows))
+		for _, row := range rows {
+			commentIDs = append(commentIDs, row.CommentID)
+		}
+		openReports, err := s.repository.ListOpenReportsByComments(ctx, commentIDs)
+		if err != nil {
+			return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy báo cáo đánh giá: %w", err))
+		}
+		for _, report := range openReports {
+			item := services.CommentReportResponse{
+				ID:         report.ID,
+				ReporterID: report.ReporterID,
+				Reason:     string(report.Reason),
+				CreatedAt:  report.CreatedAt,
+			}
+			if report.Note.Valid {
+				item.Note = &report.Note.String
+			}
+			reports[report.CommentID] = append(reports[report.CommentID], item)
+		}
+	}
+
+	items := make([]services.ModerationQueueItem, 0, len(rows))
+	for _, row := range rows {
+		item := services.ModerationQueueItem{
+			CommentResponse: toCommentResponse(db.ProductComment{
+				CommentID:       row.CommentID,
+				OrderItemID:     row.OrderItemID,
+				ProductID:       row.ProductID,
+				SkuID:           row.SkuID,
+				UserID:          row.UserID,
+				SkuNameSnapshot: row.SkuNameSnapshot,
+				Rating:          row.Rating,
+				Title:           row.Title,
+				Content:         row.Content,
+				Media:           row.Media,
+				ParentID:        row.ParentID,
+				CreatedAt:       row.CreatedAt,
+				UpdatedAt:       row.UpdatedAt,
+				Status:          row.Status,
+			}),
+			OpenReports: row.OpenReports,
+			Reports:     reports[row.CommentID],
+		}
+		if item.Reports == nil {
+			item.Reports = []services.CommentReportResponse{}
+		}
+		items = append(items, item)
+	}
+
+	result := map[string]interface{}{}
+	result["data"] = items
+	result["currentPage"] = req.Page
+	result["totalPages"] = (total + int64(req.PageSize) - 1) / int64(req.PageSize)
+	result["totalElements"] = total
+	result["limit"] = req.PageSize
+	return result, nil
+}
+
+// ModerateComment admin ẩn hoặc hiện một đánh giá/phản hồi và đóng các báo cáo đang mở của nó:
+// ẩn thì báo cáo được ghi nhận (RESOLVED), hiện thì báo cáo bị bỏ qua (DISMISSED).
+func (s *service) ModerateComment(ctx context.Context, adminID, commentID string, req services.ModerateCommentRequest) (*services.CommentResponse, *assets_services.ServiceError) {
+	comment, err := s.repository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá"))
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá: %w", err))
+	}
+
+	status := db.ProductCommentStatus(req.Status)
+	reportStatus := db.CommentReportsStatusDISMISSED
+	if status == db.ProductCommentStatusHIDDEN {
+		reportStatus = db.CommentReportsStatusRESOLVED
+	}
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		if err := tx.UpdateCommentStatus(ctx, db.UpdateCommentStatusParams{Status: status, CommentID: commentID}); err != nil {
+			return fmt.Errorf("lỗi khi cập nhật trạng thái đánh giá: %w", err)
+		}
+		if _, err := tx.ResolveCommentReports(ctx, db.ResolveCommentReportsParams{
+			Status:     reportStatus,
+			ResolvedBy: sql.NullString{String: adminID, Valid: true},
+			CommentID:  commentID,
+		}); err != nil {
+			return fmt.Errorf("lỗi khi đóng báo cáo đánh giá: %w", err)
+		}
+		// đánh giá gốc ẩn/hiện thì điểm đánh giá của sản phẩm thay đổi
+		if !comment.ParentID.Valid && status != comment.Status {
+			return s.refreshRatingSummary(ctx, tx, comment.ProductID)
+		}
+		return nil
+	})
+	if txErr != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, txErr)
+	}
+
+	if status != comment.Status {
+		if !comment.ParentID.Valid {
+			s.syncProductRating(ctx, comment.ProductID)
+		}
+		if status == db.ProductCommentStatusHIDDEN {
+			s.notifyUser(ctx, comment.UserID, sendMessage.DanhGiaBiAn())
+		}
+	}
+
+	comment.Status = status
+	resp := toCommentResponse(comment)
+	return &resp, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"firebase.google.com/go/messaging"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	sendMessage "github.com/TranVinhHien/ecom_order_service/services/assets/sendMessage"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+	"github.com/google/uuid"
+)
+
+// ReplyComment tạo hoặc sửa phản hồi chính thức cho một đánh giá.
+// Chỉ shop đã bán sản phẩm trong đánh giá (shopID lấy từ token của seller) hoặc admin được phản hồi,
+// mỗi đánh giá có tối đa 1 phản hồi. Người mua được thông báo khi đánh giá có phản hồi lần đầu.
+func (s *service) ReplyComment(ctx context.Context, userID, role, shopID, commentID string, req services.ReplyCommentRequest) (*services.CommentResponse, *assets_services.ServiceError) {
+	review, err := s.repository.GetReviewForReply(ctx, commentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá cần phản hồi"))
+		}
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy đánh giá: %w", err))
+	}
+	if role != "ROLE_ADMIN" && (shopID == "" || review.ShopID != shopID) {
+		return nil, assets_services.NewError(http.StatusForbidden, errors.New("bạn không có quyền phản hồi đánh giá của shop khác"))
+	}
+	// đánh giá đang ẩn/chờ duyệt không hiển thị nên không nhận phản hồi
+	if review.Status != db.ProductCommentStatusVISIBLE {
+		return nil, assets_services.NewError(http.StatusNotFound, errors.New("không tìm thấy đánh giá cần phản hồi"))
+	}
+
+	parentID := sql.NullString{String: review.CommentID, Valid: true}
+	replies, err := s.repository.GetRepliesByCommentID(ctx, parentID)
+	if err != nil {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy phản hồi hiện có: %w", err))
+	}
+
+	// phản hồi cũng qua bộ lọc từ cấm như đánh giá, sửa phản hồi sạch thì giữ trạng thái hiện tại
+	created := len(replies) == 0
+	status := db.ProductCommentStatusVISIBLE
+	if !created {
+		status = replies[0].Status
+	}
+	_, masked, status := s.moderateReview(sql.NullString{}, req.Content, status)
+	content := sql.NullString{String: masked, Valid: true}
+	if created {
+		err = s.repository.CreateComment(ctx, db.CreateCommentParams{
+			CommentID: uuid.New().String(),
+			ProductID: review.ProductID,
+			SkuID:     review.SkuID,
+			UserID:    userID,
+			Content:   content,
+			ParentID:  parentID,
+			Status:    status,
+		})
+		if err != nil {
+			// uq_parent_id chặn trường hợp hai người cùng phản hồi một lúc
+			return nil, assets_services.NewError(http.StatusConflict, fmt.Errorf("không thể tạo phản hồi, đánh giá có thể vừa được phản hồi: %w", err))
+		}
+	} else {
+		err = s.repository.UpdateCommentReply(ctx, db.UpdateCommentReplyParams{
+			Content:   content,
+			UserID:    userID,
+			Status:    status,
+			CommentID: replies[0].CommentID,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi sửa phản hồi: %w", err))
+		}
+	}
+
+	replies, err = s.repository.GetRepliesByCommentID(ctx, parentID)
+	if err != nil || len(replies) == 0 {
+		return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy phản hồi vừa lưu: %v", err))
+	}
+
+	// phản hồi chờ duyệt chưa hiển thị nên chưa báo cho người mua
+	if created && status == db.ProductCommentStatusVISIBLE {
+		replier := "Người bán"
+		if role == "ROLE_ADMIN" {
+			replier = "Sàn"
+		}
+		s.notifyUser(ctx, review.UserID, sendMessage.PhanHoiDanhGia(replier, masked))
+	}
+
+	resp := toCommentResponse(replies[0])
+	return &resp, nil
+}
+
+// notifyUser gửi thông báo tới topic của người dùng, bỏ qua khi chưa cấu hình firebase
+func (s *service) notifyUser(ctx context.Context, userID string, notification *messaging.Notification) {
+	if s.firebase == nil || userID == "" {
+		return
+	}
+	if err := s.firebase.SendToTopic(ctx, sendMessage.UserTopic(userID), notification); err != nil {
+		fmt.Println("Error SendToTopic:", err)
+	}
+}
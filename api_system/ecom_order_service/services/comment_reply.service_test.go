@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	config_assets "github.com/TranVinhHien/ecom_order_service/assets/config"
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+)
+
+// replyStore đánh giá thuộc shop-a, đếm số lần ghi phản hồi và giữ phản hồi vừa tạo
+type replyStore struct {
+	db_mysql.Store
+	reviewStatus db.ProductCommentStatus
+	writes       int
+	reply        *db.ProductComment
+}
+
+func (f *replyStore) GetReviewForReply(ctx context.Context, commentID string) (db.GetReviewForReplyRow, error) {
+	status := f.reviewStatus
+	if status == "" {
+		status = db.ProductCommentStatusVISIBLE
+	}
+	return db.GetReviewForReplyRow{CommentID: commentID, ProductID: "p-1", SkuID: "sku-1", UserID: "buyer", Status: status, ShopID: "shop-a"}, nil
+}
+
+func (f *replyStore) GetRepliesByCommentID(ctx context.Context, parentID sql.NullString) ([]db.ProductComment, error) {
+	if f.reply == nil {
+		return nil, nil
+	}
+	return []db.ProductComment{*f.reply}, nil
+}
+
+func (f *replyStore) CreateComment(ctx context.Context, arg db.CreateCommentParams) error {
+	f.writes++
+	f.reply = &db.ProductComment{CommentID: arg.CommentID, ParentID: arg.ParentID, Content: arg.Content, Status: arg.Status}
+	return nil
+}
+
+func (f *replyStore) UpdateCommentReply(ctx context.Context, arg db.UpdateCommentReplyParams) error {
+	f.writes++
+	return nil
+}
+
+func TestReplyCommentOtherShopForbidden(t *testing.T) {
+	store := &replyStore{}
+	s := &service{repository: store}
+	req := services.ReplyCommentRequest{Content: "Cảm ơn bạn"}
+
+	tests := []struct {
+		name   string
+		shopID string
+	}{
+		{"shop khác", "shop-b"},
+		// seller chưa gắn shop không được coi là khớp với mọi đánh giá
+		{"không có shop", ""},
+	}
+	for _, tt := range tests {
+		_, err := s.ReplyComment(context.Background(), "seller", "ROLE_SELLER", tt.shopID, "c-1", req)
+		if err == nil || err.Code != http.StatusForbidden {
+			t.Fatalf("%s: muốn lỗi 403, nhận %v", tt.name, err)
+		}
+	}
+	if store.writes != 0 {
+		t.Fatalf("không được ghi phản hồi khi bị từ chối, đã ghi %d lần", store.writes)
+	}
+}
+
+func TestReplyCommentHiddenReview(t *testing.T) {
+	store := &replyStore{reviewStatus: db.ProductCommentStatusHIDDEN}
+	s := &service{repository: store}
+
+	_, err := s.ReplyComment(context.Background(), "seller", "ROLE_SELLER", "shop-a", "c-1", services.ReplyCommentRequest{Content: "Cảm ơn bạn"})
+	if err == nil || err.Code != http.StatusNotFound {
+		t.Fatalf("muốn lỗi 404 khi phản hồi đánh giá bị ẩn, nhận %v", err)
+	}
+	if store.writes != 0 {
+		t.Fatalf("không được ghi phản hồi cho đánh giá bị ẩn, đã ghi %d lần", store.writes)
+	}
+}
+
+func TestReplyCommentBannedTerms(t *testing.T) {
+	filter := assets_services.NewContentFilter([]string{"vcl"})
+
+	store := &replyStore{}
+	s := &service{repository: store, reviewFilter: filter}
+	resp, err := s.ReplyComment(context.Background(), "seller", "ROLE_SELLER", "shop-a", "c-1", services.ReplyCommentRequest{Content: "Khách VCL"})
+	if err != nil {
+		t.Fatalf("lỗi khi phản hồi: %v", err)
+	}
+	if resp.Content != "Khách ***" || resp.Status != string(db.ProductCommentStatusVISIBLE) {
+		t.Fatalf("phản hồi phải được che từ cấm, nhận %q (%s)", resp.Content, resp.Status)
+	}
+
+	// REVIEW_FILTER_ACTION=hold: giữ nguyên nội dung, chờ admin duyệt
+	store = &replyStore{}
+	s = &service{repository: store, reviewFilter: filter, env: config_assets.ReadENV{ReviewFilterAction: reviewFilterHold}}
+	resp, err = s.ReplyComment(context.Background(), "seller", "ROLE_SELLER", "shop-a", "c-1", services.ReplyCommentRequest{Content: "Khách VCL"})
+	if err != nil {
+		t.Fatalf("lỗi khi phản hồi: %v", err)
+	}
+	if resp.Content != "Khách VCL" || resp.Status != string(db.ProductCommentStatusPENDING) {
+		t.Fatalf("phản hồi phải chờ duyệt, nhận %q (%s)", resp.Content, resp.Status)
+	}
+}
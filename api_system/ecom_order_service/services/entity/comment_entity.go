@@ -3,12 +3,51 @@ package services
 import "time"
 
 // CreateCommentRequest represents the request to create a new comment/review
+// Gửi dạng JSON hoặc multipart/form-data (kèm file ảnh/video ở field "media")
 type CreateCommentRequest struct {
-	OrderItemID string  `json:"order_item_id" binding:"required"`
-	Comment     string  `json:"comment" binding:"required"`
-	Star        int     `json:"star" binding:"required,min=1,max=5"`
-	Title       *string `json:"title"`
-	ParentID    *string `json:"parent_id"` // Nếu có parent_id thì đây là reply
+	OrderItemID string  `json:"order_item_id" form:"order_item_id" binding:"required"`
+	Comment     string  `json:"comment" form:"comment" binding:"required"`
+	Star        int     `json:"star" form:"star" binding:"required,min=1,max=5"`
+	Title       *string `json:"title" form:"title"`
+	ParentID    *string `json:"parent_id" form:"parent_id"` // Nếu có parent_id thì đây là reply
+}
+
+// UpdateCommentRequest người mua sửa đánh giá (1 lần, trong thời hạn cho phép).
+// keep_media là các file media hiện có muốn giữ lại, file mới gửi kèm ở field "media" của multipart/form-data
+type UpdateCommentRequest struct {
+	Comment   string   `json:"comment" form:"comment" binding:"required"`
+	Star      int      `json:"star" form:"star" binding:"required,min=1,max=5"`
+	Title     *string  `json:"title" form:"title"`
+	KeepMedia []string `json:"keep_media" form:"keep_media"`
+}
+
+// ReplyCommentRequest nội dung phản hồi chính thức của Shop/Admin cho một đánh giá
+type ReplyCommentRequest struct {
+	Content string `json:"content" binding:"required,max=2000"`
+}
+
+// ReportCommentRequest báo cáo đánh giá vi phạm, note là mô tả thêm (không bắt buộc)
+type ReportCommentRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=SPAM OFFENSIVE FALSE_INFO UNRELATED OTHER"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// ModerateCommentRequest admin ẩn (HIDDEN) hoặc hiện lại (VISIBLE) đánh giá
+type ModerateCommentRequest struct {
+	Status string `json:"status" binding:"required,oneof=VISIBLE HIDDEN"`
+}
+
+// ModerationQueueRequest phân trang hàng chờ kiểm duyệt của admin
+type ModerationQueueRequest struct {
+	PageSize int32 `form:"page_size" binding:"max=100"`
+	Page     int32 `form:"page" binding:"min=0"`
+}
+
+// ReviewLikeResponse trạng thái "Hữu ích" của một đánh giá sau khi bấm/bỏ bấm
+type ReviewLikeResponse struct {
+	CommentID string `json:"comment_id"`
+	Liked     bool   `json:"liked"`
+	LikeCount int64  `json:"like_count"`
 }
 
 // ListCommentsRequest represents the request to list comments for a product
@@ -16,6 +55,12 @@ type ListCommentsRequest struct {
 	ProductID string `form:"product_id" binding:"required"`
 	PageSize  int32  `form:"page_size" binding:"max=100"`
 	Page      int32  `form:"page" binding:"min=0"`
+	// Sắp xếp: most_helpful, newest (mặc định), rating_high, rating_low
+	Sort string `form:"sort" binding:"omitempty,oneof=most_helpful newest rating_high rating_low"`
+	// Bộ lọc: số sao, chỉ đánh giá có ảnh/video, biến thể SKU
+	Rating   int    `form:"rating" binding:"omitempty,min=1,max=5"`
+	HasMedia bool   `form:"has_media"`
+	SkuID    string `form:"sku_id"`
 }
 
 // CheckReviewedItemsRequest represents the request to check reviewed order items
@@ -43,9 +88,28 @@ type CommentResponse struct {
 	ParentID        *string           `json:"parent_id"`
 	CreatedAt       time.Time         `json:"created_at"`
 	UpdatedAt       time.Time         `json:"updated_at"`
+	LikeCount       int64             `json:"like_count"`
+	LikedByMe       bool              `json:"liked_by_me"`
+	Status          string            `json:"status"`
 	Children        []CommentResponse `json:"children,omitempty"` // Nested replies
 }
 
+// CommentReportResponse một báo cáo chưa xử lý của đánh giá
+type CommentReportResponse struct {
+	ID         string    `json:"id"`
+	ReporterID string    `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Note       *string   `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ModerationQueueItem đánh giá trong hàng chờ kiểm duyệt kèm các báo cáo chưa xử lý
+type ModerationQueueItem struct {
+	CommentResponse
+	OpenReports int64                   `json:"open_reports"`
+	Reports     []CommentReportResponse `json:"reports"`
+}
+
 // ProductRatingStats represents rating statistics for a product
 type ProductRatingStats struct {
 	TotalReviews  int64   `json:"total_reviews"`
@@ -57,11 +121,20 @@ type GetBulkProductRatingStatsRequest struct {
 	ProductIDs []string `json:"product_ids" binding:"required,min=1"`
 }
 
+// CommentMediaReferencesRequest danh sách file media cần kiểm tra còn được bình luận sử dụng hay không
+type CommentMediaReferencesRequest struct {
+	Files []string `json:"files" binding:"required,min=1,max=200"`
+}
+
 // ProductRatingStatsItem represents rating statistics for a single product
 type ProductRatingStatsItem struct {
 	ProductID     string  `json:"product_id"`
 	TotalReviews  int64   `json:"total_reviews"`
 	AverageRating float64 `json:"average_rating"`
+	// Điểm đã hiệu chỉnh theo số lượng đánh giá (Bayesian), dùng để xếp hạng
+	BayesianScore float64 `json:"bayesian_score"`
+	// Số đánh giá theo từng mức sao, key "1".."5"
+	Distribution map[string]int64 `json:"distribution"`
 }
 
 // GetBulkProductRatingStatsResponse represents the response with rating stats keyed by product_id
@@ -30,6 +30,10 @@ type QueryFilter struct {
 	OrderBy    *OrderBy // Trường để sắp xếp
 	Page       int      // Trang hiện tại
 	PageSize   int      // Số lượng kết quả mỗi trang
+	// Phân trang theo cursor (keyset) thay cho Page, Cursor rỗng là trang đầu
+	CursorMode bool
+	Cursor     string
+	WithTotal  bool // chế độ cursor mặc định không đếm tổng số bản ghi
 }
 
 func NewQueryFilter(page int, pageSize int, conditions []Condition, orderBy *OrderBy) QueryFilter {
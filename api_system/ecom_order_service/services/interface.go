@@ -31,3 +31,8 @@ type ServicesRedis interface {
 
 	DeleteOrderOnline(ctx context.Context, orderID string) error
 }
+
+// ServicesEvent phát sự kiện ra Kafka cho các service khác (analytics)
+type ServicesEvent interface {
+	OrderStatusChanged(ctx context.Context, key string, message map[string]interface{}) error
+}
@@ -2,6 +2,7 @@ package iservices
 
 import (
 	"context"
+	"mime/multipart"
 
 	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
 	services "github.com/TranVinhHien/ecom_order_service/services/entity"
@@ -42,14 +43,38 @@ type Vouchers interface {
 // Comments defines comment-related use cases
 type Comments interface {
 	// Create a new comment/review
-	CreateComment(ctx context.Context, userID string, req services.CreateCommentRequest) *assets_services.ServiceError
+	CreateComment(ctx context.Context, userID, token string, req services.CreateCommentRequest, files []*multipart.FileHeader) (*services.CommentResponse, *assets_services.ServiceError)
 
-	// List comments for a product with pagination
-	ListComments(ctx context.Context, req services.ListCommentsRequest) (map[string]interface{}, *assets_services.ServiceError)
+	// Buyer edits their own review once within the edit window
+	UpdateComment(ctx context.Context, userID, token, commentID string, req services.UpdateCommentRequest, files []*multipart.FileHeader) (*services.CommentResponse, *assets_services.ServiceError)
+
+	// Create or edit the official shop/admin reply of a review
+	ReplyComment(ctx context.Context, userID, role, shopID, commentID string, req services.ReplyCommentRequest) (*services.CommentResponse, *assets_services.ServiceError)
+
+	// List comments for a product with pagination, filters and sorting (userID may be empty for guests)
+	ListComments(ctx context.Context, userID string, req services.ListCommentsRequest) (map[string]interface{}, *assets_services.ServiceError)
+
+	// Toggle the "helpful" vote of the current user on a review
+	ToggleReviewLike(ctx context.Context, userID, commentID string) (*services.ReviewLikeResponse, *assets_services.ServiceError)
+
+	// Report an abusive review/reply
+	ReportComment(ctx context.Context, userID, commentID string, req services.ReportCommentRequest) *assets_services.ServiceError
+
+	// Admin moderation queue (pending reviews and reviews with open reports)
+	ListModerationQueue(ctx context.Context, req services.ModerationQueueRequest) (map[string]interface{}, *assets_services.ServiceError)
+
+	// Admin hides or shows a review and closes its open reports
+	ModerateComment(ctx context.Context, adminID, commentID string, req services.ModerateCommentRequest) (*services.CommentResponse, *assets_services.ServiceError)
 
 	// Check which order items have been reviewed
 	CheckReviewedItems(ctx context.Context, req services.CheckReviewedItemsRequest) (*services.CheckReviewedItemsResponse, *assets_services.ServiceError)
 
 	// Get bulk product rating stats for multiple products
 	GetBulkProductRatingStats(ctx context.Context, req services.GetBulkProductRatingStatsRequest) (map[string]interface{}, *assets_services.ServiceError)
+
+	// Admin pushes every product rating summary to the product service
+	ResyncProductRatings(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+
+	// Media files still referenced by comments (used by product service media cleanup)
+	ListCommentMediaReferences(ctx context.Context, req services.CommentMediaReferencesRequest) (map[string]interface{}, *assets_services.ServiceError)
 }
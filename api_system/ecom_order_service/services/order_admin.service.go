@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
 	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
 	server_product "github.com/TranVinhHien/ecom_order_service/server/product"
 	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
@@ -13,30 +14,44 @@ import (
 
 // ListShopOrders lấy danh sách đơn hàng của shop với filter và phân trang
 func (s *service) ListShopOrders(ctx context.Context, shopID string, status string, query services.QueryFilter) (map[string]interface{}, *assets_services.ServiceError) {
-
-	// Lấy orders từ DB
-	orders, err := s.repository.ListShopOrdersSHOP(ctx, db.ListShopOrdersSHOPParams{
-		ShopID: shopID,
-		Status: db.NullShopOrdersStatus{
-			ShopOrdersStatus: db.ShopOrdersStatus(status),
-			Valid:            status != "",
-		},
-		Limit:  int32(query.PageSize),
-		Offset: int32(query.PageSize * (query.Page - 1)),
-	})
-	if err != nil {
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy đơn hàng: %w", err))
+	statusFilter := db.NullShopOrdersStatus{
+		ShopOrdersStatus: db.ShopOrdersStatus(status),
+		Valid:            status != "",
 	}
-	//
-	totalElements, err := s.repository.ListShopOrdersSHOPCount(ctx, db.ListShopOrdersSHOPCountParams{
-		ShopID: shopID,
-		Status: db.NullShopOrdersStatus{
-			ShopOrdersStatus: db.ShopOrdersStatus(status),
-			Valid:            status != "",
-		},
-	})
-	if err != nil {
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi đếm đơn hàng: %w", err))
+	// Lấy orders từ DB, chế độ cursor dùng keyset thay cho OFFSET
+	var orders []db.ShopOrders
+	var err error
+	var nextCursor, prevCursor interface{}
+	if query.CursorMode {
+		var errCursor *assets_services.ServiceError
+		orders, nextCursor, prevCursor, errCursor = s.listShopOrdersCursor(ctx, db_mysql.ShopOrderKeysetParams{
+			ShopID: sql.NullString{String: shopID, Valid: true},
+			Status: statusFilter,
+		}, query)
+		if errCursor != nil {
+			return nil, errCursor
+		}
+	} else {
+		orders, err = s.repository.ListShopOrdersSHOP(ctx, db.ListShopOrdersSHOPParams{
+			ShopID: shopID,
+			Status: statusFilter,
+			Limit:  int32(query.PageSize),
+			Offset: int32(query.PageSize * (query.Page - 1)),
+		})
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy đơn hàng: %w", err))
+		}
+	}
+	// chế độ cursor chỉ đếm tổng khi client yêu cầu
+	var totalElements int64
+	if !query.CursorMode || query.WithTotal {
+		totalElements, err = s.repository.ListShopOrdersSHOPCount(ctx, db.ListShopOrdersSHOPCountParams{
+			ShopID: shopID,
+			Status: statusFilter,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi đếm đơn hàng: %w", err))
+		}
 	}
 	// Build order summaries
 	orderSummaries := make([]services.ShopOrderDetail, len(orders))
@@ -48,13 +63,19 @@ func (s *service) ListShopOrders(ctx context.Context, shopID string, status stri
 		}
 		orderSummaries[i] = s.convertDBShopOrderToService(order, OrderItems)
 	}
-	totalPage := totalElements / int64(query.PageSize)
-
 	result := map[string]interface{}{}
 	result["data"] = orderSummaries
-	result["currentPage"] = query.Page
-	result["totalPages"] = totalPage
-	result["totalElements"] = totalElements
+	if query.CursorMode {
+		result["next_cursor"] = nextCursor
+		result["prev_cursor"] = prevCursor
+		if query.WithTotal {
+			result["totalElements"] = totalElements
+		}
+	} else {
+		result["currentPage"] = query.Page
+		result["totalPages"] = totalElements / int64(query.PageSize)
+		result["totalElements"] = totalElements
+	}
 	result["limit"] = query.PageSize
 	return result, nil
 }
@@ -187,6 +208,7 @@ func (s *service) UpdateShopOrderStatus(ctx context.Context, shopID, user_role,
 	default:
 		return assets_services.NewError(400, fmt.Errorf("trạng thái không hợp lệ: %s", status))
 	}
+	s.publishShopOrderStatus(ctx, shopOrder, db.ShopOrdersStatus(status))
 	return nil
 }
 
@@ -207,6 +229,7 @@ func (s *service) CallbackPaymentOnline(ctx context.Context, OrderID string) *as
 			if err := s.repository.UpdateShopOrderStatusToProcessing(ctx, shopOrder.ID); err != nil {
 				return assets_services.NewError(400, fmt.Errorf("lỗi khi cập nhật trạng thái: %w", err))
 			}
+			s.publishShopOrderStatus(ctx, shopOrder, db.ShopOrdersStatusPROCESSING)
 		}
 	}
 	return nil
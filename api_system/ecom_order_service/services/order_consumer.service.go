@@ -76,6 +76,9 @@ func (s *service) HandlePaymentFailedEvent(ctx context.Context, body services.Pa
 		return err // Thử lại (NACK)
 	}
 
+	for _, so := range shopOrderIDs {
+		s.publishShopOrderStatus(ctx, so, db.ShopOrdersStatusCANCELLED)
+	}
 	log.Printf("Successfully cancelled %d shop orders for main order %s", len(shopOrderIDs), body.OrderID)
 	return nil // Hoàn tất (ACK)
 }
@@ -114,6 +117,9 @@ func (s *service) HandlePaymentSucceededEvent(ctx context.Context, body services
 		return nil
 	})
 
+	for _, so := range shopOrderItems {
+		s.publishShopOrderStatus(ctx, so, db.ShopOrdersStatusPROCESSING)
+	}
 	log.Printf("Successfully update %d shop orders for main order %s", len(shopOrderID), body.OrderID)
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strings"
 	"time"
 
 	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
@@ -66,6 +67,8 @@ func (s *service) CreateOrder(ctx context.Context, userID string, token string,
 
 	// Bước 8: Lưu order vào database trong transaction
 	var paymentURL *string
+	// các shop order đã lưu, dùng để phát sự kiện sau khi transaction commit
+	var createdShopOrders []db.ShopOrders
 	saveErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
 		// Lưu main order
 		shippingJSON, _ := json.Marshal(req.ShippingAddress)
@@ -160,6 +163,10 @@ func (s *service) CreateOrder(ctx context.Context, userID string, token string,
 			}); err != nil {
 				return fmt.Errorf("lỗi khi tạo shop order: %w", err)
 			}
+			createdShopOrders = append(createdShopOrders, db.ShopOrders{
+				ID: shopOrder.ShopOrderID, OrderID: orderID, ShopID: shopOrder.ShopID,
+				Status: db.ShopOrdersStatus(status), CreatedAt: time.Now(),
+			})
 
 			// Lưu order items
 			for _, item := range shopOrder.Items {
@@ -183,6 +190,7 @@ func (s *service) CreateOrder(ctx context.Context, userID string, token string,
 					ProductNameSnapshot:   item.ProductName,
 					ProductImageSnapshot:  productImage,
 					SkuAttributesSnapshot: sql.NullString{String: string(item.SkuAttributes), Valid: true},
+					CategoryID:            sql.NullString{String: item.CategoryID, Valid: item.CategoryID != ""},
 				}); err != nil {
 					return fmt.Errorf("lỗi khi tạo order item: %w", err)
 				}
@@ -241,6 +249,10 @@ func (s *service) CreateOrder(ctx context.Context, userID string, token string,
 		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lưu đơn hàng: %w", saveErr))
 	}
 
+	for _, so := range createdShopOrders {
+		s.publishShopOrderStatus(ctx, so, so.Status)
+	}
+
 	// Bước 10: Build response
 	shopOrderCodes := make([]string, len(shopOrders))
 	for i, so := range shopOrders {
@@ -382,34 +394,41 @@ func (s *service) validateCreateOrderRequest(req services.CreateOrderRequest) *a
 	return nil
 }
 
-// Helper: lấy thông tin sản phẩm (giả định gọi qua repository hoặc service khác)
+// Helper: lấy thông tin sản phẩm của toàn bộ giỏ hàng bằng một lần gọi Product Service
 func (s *service) fetchProductInfoForOrder(ctx context.Context, items []services.OrderItemRequest) (map[string]*ProductInfo, *assets_services.ServiceError) {
-	productMap := make(map[string]*ProductInfo)
-	// s.apiServer
+	skuIDs := make([]string, 0, len(items))
 	for _, item := range items {
-		// Trong production, bạn sẽ gọi Product Service hoặc repository
-		// Ở đây tôi giả định lấy từ local DB
-		sku, err := s.apiServer.GetSKUs(item.SkuID)
-		if err != nil {
-			return nil, assets_services.NewError(404, fmt.Errorf("lỗi khi lấy product SKU %s: %w", item.SkuID, err))
-		}
+		skuIDs = append(skuIDs, item.SkuID)
+	}
+	skus, err := s.apiServer.GetSKUsBatch(skuIDs)
+	if err != nil {
+		return nil, assets_services.NewError(404, fmt.Errorf("lỗi khi lấy product SKU: %w", err))
+	}
+	if len(skus.Result.Missing) > 0 {
+		return nil, assets_services.NewError(404, fmt.Errorf("không tìm thấy product SKU %s", strings.Join(skus.Result.Missing, ", ")))
+	}
 
-		product, err := s.apiServer.GetProductDetail(sku.Result.Data.ProductID)
-		if err != nil {
-			return nil, assets_services.NewError(404, fmt.Errorf("lỗi khi lấy product %s: %w", sku.Result.Data.ProductID, err))
+	productMap := make(map[string]*ProductInfo, len(skus.Result.Data))
+	for _, sku := range skus.Result.Data {
+		// sản phẩm đã ẩn, đã xóa hoặc chưa được duyệt thì không cho đặt hàng
+		if sku.ProductStatus != "Active" {
+			return nil, assets_services.NewError(409, fmt.Errorf("sản phẩm %s hiện không còn được bán", sku.ProductName))
 		}
-
-		price := sku.Result.Data.Price
-		quantity := sku.Result.Data.Quantity
-
-		productMap[item.SkuID] = &ProductInfo{
-			ProductID:   sku.Result.Data.ProductID,
-			SkuID:       sku.Result.Data.ID,
-			ProductName: product.Result.Data.Product.Name,
-			Image:       &product.Result.Data.Product.Image,
-			Price:       price,
-			Stock:       quantity,
-			Attributes:  sku.Result.Data.SkuName,
+		image := sku.Image
+		productMap[sku.SkuID] = &ProductInfo{
+			ProductID:   sku.ProductID,
+			SkuID:       sku.SkuID,
+			ProductName: sku.ProductName,
+			Image:       &image,
+			Price:       sku.Price,
+			Stock:       sku.AvailableStock,
+			Attributes:  sku.SkuName,
+			CategoryID:  sku.CategoryID,
+		}
+	}
+	for _, item := range items {
+		if productMap[item.SkuID] == nil {
+			return nil, assets_services.NewError(404, fmt.Errorf("không tìm thấy product SKU %s", item.SkuID))
 		}
 	}
 
@@ -505,6 +524,7 @@ func (s *service) createShopOrdersWithItems(
 				ProductName:       product.ProductName,
 				ProductImage:      product.Image,
 				SkuAttributes:     product.Attributes,
+				CategoryID:        product.CategoryID,
 			}
 
 			shopOrder.Items = append(shopOrder.Items, item)
@@ -763,6 +783,7 @@ type ProductInfo struct {
 	Price       float64
 	Stock       int
 	Attributes  string
+	CategoryID  string
 }
 
 type ShopOrderWithItems struct {
@@ -789,6 +810,7 @@ type OrderItemData struct {
 	ProductName       string
 	ProductImage      *string
 	SkuAttributes     string
+	CategoryID        string
 }
 
 // Helper functions
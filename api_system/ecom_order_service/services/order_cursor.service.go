@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+)
+
+// danh sách đơn hàng chỉ sắp xếp theo thời gian tạo mới nhất trước
+const shopOrderCursorSort = "created_at"
+
+// cursorSecret khóa ký cursor phân trang, mặc định dùng chung JWT_SECRET
+func (s *service) cursorSecret() string {
+	if s.env.CursorSecret != "" {
+		return s.env.CursorSecret
+	}
+	return s.env.JWTSecret
+}
+
+// listShopOrdersCursor lấy một trang shop_orders theo cursor, trả về trang hiện tại cùng next/prev cursor
+func (s *service) listShopOrdersCursor(ctx context.Context, params db_mysql.ShopOrderKeysetParams, query services.QueryFilter) ([]db.ShopOrders, interface{}, interface{}, *assets_services.ServiceError) {
+	var cursor *assets_services.Cursor
+	if query.Cursor != "" {
+		c, err := assets_services.DecodeCursor(s.cursorSecret(), query.Cursor)
+		if err != nil || c.Sort != shopOrderCursorSort {
+			return nil, nil, nil, assets_services.NewError(400, assets_services.ErrInvalidCursor)
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, c.Key)
+		if err != nil {
+			return nil, nil, nil, assets_services.NewError(400, assets_services.ErrInvalidCursor)
+		}
+		params.AfterCreatedAt, params.AfterID, params.Backward = createdAt, c.ID, c.Backward
+		cursor = &c
+	}
+	// lấy dư 1 bản ghi để biết còn trang hay không
+	params.Limit = int32(query.PageSize + 1)
+	orders, err := s.repository.ListShopOrdersKeyset(ctx, params)
+	if err != nil {
+		return nil, nil, nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy đơn hàng: %w", err))
+	}
+	page, hasNext, hasPrev := assets_services.KeysetPage(orders, query.PageSize, cursor)
+	var next, prev interface{}
+	if len(page) > 0 && hasNext {
+		last := page[len(page)-1]
+		next = assets_services.EncodeCursor(s.cursorSecret(), assets_services.Cursor{Sort: shopOrderCursorSort, Key: last.CreatedAt.UTC().Format(time.RFC3339Nano), ID: last.ID})
+	}
+	if len(page) > 0 && hasPrev {
+		first := page[0]
+		prev = assets_services.EncodeCursor(s.cursorSecret(), assets_services.Cursor{Sort: shopOrderCursorSort, Key: first.CreatedAt.UTC().Format(time.RFC3339Nano), ID: first.ID, Backward: true})
+	}
+	return page, next, prev, nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+)
+
+// publishShopOrderStatus phát sự kiện order.status_changed cho analytics service.
+// Lỗi chỉ ghi log: đơn hàng đã được cập nhật, analytics có thể chạy backfill để bù lại.
+func (s *service) publishShopOrderStatus(ctx context.Context, shopOrder db.ShopOrders, status db.ShopOrdersStatus) {
+	if s.events == nil {
+		return
+	}
+	message := map[string]interface{}{
+		"shop_order_id": shopOrder.ID,
+		"order_id":      shopOrder.OrderID,
+		"shop_id":       shopOrder.ShopID,
+		"status":        string(status),
+		"created_at":    shopOrder.CreatedAt,
+		"occurred_at":   time.Now(),
+	}
+	if err := s.events.OrderStatusChanged(ctx, shopOrder.ID, message); err != nil {
+		log.Printf("Error publishing order.status_changed for shop order %s: %v", shopOrder.ID, err)
+	}
+}
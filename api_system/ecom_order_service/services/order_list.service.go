@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
 	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
 	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
 	services "github.com/TranVinhHien/ecom_order_service/services/entity"
@@ -13,30 +14,44 @@ import (
 
 // ListUserOrders lấy danh sách đơn hàng của user với phân trang
 func (s *service) ListUserOrders(ctx context.Context, userID string, query services.QueryFilter, status string) (map[string]interface{}, *assets_services.ServiceError) {
-
-	// Lấy orders từ DB
-	orders, err := s.repository.ListShopOrdersByStatus(ctx, db.ListShopOrdersByStatusParams{
-		UserID: userID,
-		Status: db.NullShopOrdersStatus{
-			ShopOrdersStatus: db.ShopOrdersStatus(status),
-			Valid:            status != "",
-		},
-		Limit:  int32(query.PageSize),
-		Offset: int32(query.PageSize * (query.Page - 1)),
-	})
-	if err != nil {
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy đơn hàng: %w", err))
-	}
-	//
-	totalElements, err := s.repository.ListShopOrdersByStatusCount(ctx, db.ListShopOrdersByStatusCountParams{
-		UserID: userID,
-		Status: db.NullShopOrdersStatus{
-			ShopOrdersStatus: db.ShopOrdersStatus(status),
-			Valid:            status != "",
-		},
-	})
-	if err != nil {
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi đếm đơn hàng: %w", err))
+	statusFilter := db.NullShopOrdersStatus{
+		ShopOrdersStatus: db.ShopOrdersStatus(status),
+		Valid:            status != "",
+	}
+	// Lấy orders từ DB, chế độ cursor dùng keyset thay cho OFFSET
+	var orders []db.ShopOrders
+	var err error
+	var nextCursor, prevCursor interface{}
+	if query.CursorMode {
+		var errCursor *assets_services.ServiceError
+		orders, nextCursor, prevCursor, errCursor = s.listShopOrdersCursor(ctx, db_mysql.ShopOrderKeysetParams{
+			UserID: sql.NullString{String: userID, Valid: true},
+			Status: statusFilter,
+		}, query)
+		if errCursor != nil {
+			return nil, errCursor
+		}
+	} else {
+		orders, err = s.repository.ListShopOrdersByStatus(ctx, db.ListShopOrdersByStatusParams{
+			UserID: userID,
+			Status: statusFilter,
+			Limit:  int32(query.PageSize),
+			Offset: int32(query.PageSize * (query.Page - 1)),
+		})
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy đơn hàng: %w", err))
+		}
+	}
+	// chế độ cursor chỉ đếm tổng khi client yêu cầu
+	var totalElements int64
+	if !query.CursorMode || query.WithTotal {
+		totalElements, err = s.repository.ListShopOrdersByStatusCount(ctx, db.ListShopOrdersByStatusCountParams{
+			UserID: userID,
+			Status: statusFilter,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi đếm đơn hàng: %w", err))
+		}
 	}
 	// Build order summaries
 	orderSummaries := make([]services.ShopOrderDetail, len(orders))
@@ -48,13 +63,19 @@ func (s *service) ListUserOrders(ctx context.Context, userID string, query servi
 		}
 		orderSummaries[i] = s.convertDBShopOrderToService(order, OrderItems)
 	}
-	totalPage := totalElements / int64(query.PageSize)
-
 	result := map[string]interface{}{}
 	result["data"] = orderSummaries
-	result["currentPage"] = query.Page
-	result["totalPages"] = totalPage
-	result["totalElements"] = totalElements
+	if query.CursorMode {
+		result["next_cursor"] = nextCursor
+		result["prev_cursor"] = prevCursor
+		if query.WithTotal {
+			result["totalElements"] = totalElements
+		}
+	} else {
+		result["currentPage"] = query.Page
+		result["totalPages"] = totalElements / int64(query.PageSize)
+		result["totalElements"] = totalElements
+	}
 	result["limit"] = query.PageSize
 	return result, nil
 }
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	server_product "github.com/TranVinhHien/ecom_order_service/server/product"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
+	services "github.com/TranVinhHien/ecom_order_service/services/entity"
+)
+
+const (
+	// số đánh giá "ảo" ở mức điểm trung bình toàn sàn cộng vào mỗi sản phẩm khi tính bayesian_score
+	defaultReviewBayesWeight = 10
+	// số sản phẩm mỗi lần gửi khi đồng bộ lại toàn bộ điểm đánh giá sang product service
+	ratingSyncBatchSize = 200
+)
+
+func (s *service) reviewBayesWeight() float64 {
+	if s.env.ReviewBayesWeight > 0 {
+		return s.env.ReviewBayesWeight
+	}
+	return defaultReviewBayesWeight
+}
+
+// refreshRatingSummary tính lại bảng tổng hợp điểm đánh giá của sản phẩm, gọi trong transaction vừa thay đổi đánh giá
+func (s *service) refreshRatingSummary(ctx context.Context, tx db.Querier, productID string) error {
+	global, err := tx.GetRatingGlobalStats(ctx)
+	if err != nil {
+		return fmt.Errorf("lỗi khi lấy điểm đánh giá toàn sàn: %w", err)
+	}
+	// sàn chưa có đánh giá nào thì lấy mức giữa thang điểm làm prior
+	mean := 3.0
+	if global.TotalReviews > 0 {
+		mean = float64(global.RatingSum) / float64(global.TotalReviews)
+	}
+	if err := tx.RefreshProductRatingSummary(ctx, db.RefreshProductRatingSummaryParams{
+		ProductID:   productID,
+		PriorWeight: s.reviewBayesWeight(),
+		PriorMean:   mean,
+	}); err != nil {
+		return fmt.Errorf("lỗi khi cập nhật tổng hợp điểm đánh giá: %w", err)
+	}
+	return nil
+}
+
+// syncProductRating gửi điểm đánh giá mới của sản phẩm sang product service (dùng để sắp xếp danh sách và làm mới cache).
+// Lỗi chỉ ghi log, admin có thể đồng bộ lại toàn bộ qua ResyncProductRatings.
+func (s *service) syncProductRating(ctx context.Context, productID string) {
+	summary, err := s.repository.GetProductRatingSummary(ctx, productID)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("không thể lấy tổng hợp điểm đánh giá sản phẩm %s: %v", productID, err)
+		return
+	}
+	summary.ProductID = productID
+	if err := s.apiServer.SyncProductRatings(s.env.TokenSystem, []server_product.ProductRatingParams{toProductRatingParams(summary)}); err != nil {
+		log.Printf("không thể đồng bộ điểm đánh giá sản phẩm %s: %v", productID, err)
+	}
+}
+
+func toProductRatingParams(summary db.ProductRatingSummary) server_product.ProductRatingParams {
+	return server_product.ProductRatingParams{
+		ProductID:     summary.ProductID,
+		RatingAverage: summary.AverageRating,
+		RatingCount:   summary.TotalReviews,
+		RatingScore:   summary.BayesianScore,
+	}
+}
+
+// ResyncProductRatings admin đồng bộ lại toàn bộ điểm đánh giá sang product service (sau khi product service mất dữ liệu hoặc lần đầu triển khai)
+func (s *service) ResyncProductRatings(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	lastID := ""
+	synced := 0
+	for {
+		summaries, err := s.repository.ListProductRatingSummaries(ctx, db.ListProductRatingSummariesParams{
+			ProductID: lastID,
+			Limit:     ratingSyncBatchSize,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(http.StatusInternalServerError, fmt.Errorf("lỗi khi lấy tổng hợp điểm đánh giá: %w", err))
+		}
+		if len(summaries) == 0 {
+			break
+		}
+		params := make([]server_product.ProductRatingParams, 0, len(summaries))
+		for _, summary := range summaries {
+			params = append(params, toProductRatingParams(summary))
+		}
+		if err := s.apiServer.SyncProductRatings(s.env.TokenSystem, params); err != nil {
+			return nil, assets_services.NewError(http.StatusBadGateway, fmt.Errorf("lỗi khi đồng bộ điểm đánh giá sang product service (đã đồng bộ %d sản phẩm): %w", synced, err))
+		}
+		synced += len(summaries)
+		lastID = summaries[len(summaries)-1].ProductID
+		if len(summaries) < ratingSyncBatchSize {
+			break
+		}
+	}
+	return map[string]interface{}{"synced": synced}, nil
+}
+
+// GetBulkProductRatingStats lấy thống kê đánh giá cho nhiều sản phẩm cùng lúc từ bảng tổng hợp
+// Sản phẩm chưa có đánh giá không có trong kết quả
+func (s *service) GetBulkProductRatingStats(ctx context.Context, req services.GetBulkProductRatingStatsRequest) (map[string]interface{}, *assets_services.ServiceError) {
+	if len(req.ProductIDs) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	summaries, err := s.repository.GetBulkProductRatingSummary(ctx, req.ProductIDs)
+	if err != nil {
+		return nil, assets_services.NewError(
+			http.StatusInternalServerError,
+			fmt.Errorf("lỗi khi lấy thống kê đánh giá sản phẩm: %w", err),
+		)
+	}
+
+	statsMap := []services.ProductRatingStatsItem{}
+	for _, summary := range summaries {
+		if summary.TotalReviews == 0 {
+			continue
+		}
+		distribution := map[string]int64{}
+		for star, count := range []int32{summary.Star1, summary.Star2, summary.Star3, summary.Star4, summary.Star5} {
+			distribution[strconv.Itoa(star+1)] = int64(count)
+		}
+		statsMap = append(statsMap, services.ProductRatingStatsItem{
+			ProductID:     summary.ProductID,
+			TotalReviews:  int64(summary.TotalReviews),
+			AverageRating: summary.AverageRating,
+			BayesianScore: summary.BayesianScore,
+			Distribution:  distribution,
+		})
+	}
+	result := map[string]interface{}{}
+	result["data"] = statsMap
+	return result, nil
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	config_assets "github.com/TranVinhHien/ecom_order_service/assets/config"
+	db_mysql "github.com/TranVinhHien/ecom_order_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_order_service/db/sqlc"
+	"github.com/TranVinhHien/ecom_order_service/server"
+	server_product "github.com/TranVinhHien/ecom_order_service/server/product"
+)
+
+// ratingStore giữ thống kê toàn sàn và bảng tổng hợp (sắp theo product_id), ghi lại tham số prior nhận được
+type ratingStore struct {
+	db_mysql.Store
+	global    db.GetRatingGlobalStatsRow
+	summaries []db.ProductRatingSummary
+	refreshed db.RefreshProductRatingSummaryParams
+	listCalls []db.ListProductRatingSummariesParams
+}
+
+func (f *ratingStore) GetRatingGlobalStats(ctx context.Context) (db.GetRatingGlobalStatsRow, error) {
+	return f.global, nil
+}
+
+func (f *ratingStore) RefreshProductRatingSummary(ctx context.Context, arg db.RefreshProductRatingSummaryParams) error {
+	f.refreshed = arg
+	return nil
+}
+
+func (f *ratingStore) GetProductRatingSummary(ctx context.Context, productID string) (db.ProductRatingSummary, error) {
+	for _, summary := range f.summaries {
+		if summary.ProductID == productID {
+			return summary, nil
+		}
+	}
+	return db.ProductRatingSummary{}, sql.ErrNoRows
+}
+
+func (f *ratingStore) ListProductRatingSummaries(ctx context.Context, arg db.ListProductRatingSummariesParams) ([]db.ProductRatingSummary, error) {
+	f.listCalls = append(f.listCalls, arg)
+	var page []db.ProductRatingSummary
+	for _, summary := range f.summaries {
+		if summary.ProductID > arg.ProductID && len(page) < int(arg.Limit) {
+			page = append(page, summary)
+		}
+	}
+	return page, nil
+}
+
+// ratingAPI ghi lại các lần gửi điểm đánh giá sang product service
+type ratingAPI struct {
+	server.ApiServer
+	tokens []string
+	sent   [][]server_product.ProductRatingParams
+	err    error
+}
+
+func (f *ratingAPI) SyncProductRatings(token string, ratings []server_product.ProductRatingParams) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.tokens = append(f.tokens, token)
+	f.sent = append(f.sent, ratings)
+	return nil
+}
+
+func TestRefreshRatingSummaryPrior(t *testing.T) {
+	tests := []struct {
+		name       string
+		global     db.GetRatingGlobalStatsRow
+		weight     float64
+		wantMean   float64
+		wantWeight float64
+	}{
+		{"trung bình toàn sàn", db.GetRatingGlobalStatsRow{RatingSum: 42, TotalReviews: 10}, 0, 4.2, defaultReviewBayesWeight},
+		// sàn chưa có đánh giá: lấy mức giữa thang điểm
+		{"sàn chưa có đánh giá", db.GetRatingGlobalStatsRow{}, 0, 3, defaultReviewBayesWeight},
+		{"cấu hình REVIEW_BAYES_WEIGHT", db.GetRatingGlobalStatsRow{RatingSum: 9, TotalReviews: 2}, 25, 4.5, 25},
+	}
+	for _, tt := range tests {
+		store := &ratingStore{global: tt.global}
+		s := &service{repository: store, env: config_assets.ReadENV{ReviewBayesWeight: tt.weight}}
+		if err := s.refreshRatingSummary(context.Background(), store, "p-1"); err != nil {
+			t.Fatalf("%s: lỗi %v", tt.name, err)
+		}
+		if store.refreshed.ProductID != "p-1" || store.refreshed.PriorMean != tt.wantMean || store.refreshed.PriorWeight != tt.wantWeight {
+			t.Fatalf("%s: muốn prior_mean=%v prior_weight=%v, nhận %+v", tt.name, tt.wantMean, tt.wantWeight, store.refreshed)
+		}
+	}
+}
+
+func TestSyncProductRatingSendsSystemToken(t *testing.T) {
+	store := &ratingStore{summaries: []db.ProductRatingSummary{
+		{ProductID: "p-1", TotalReviews: 4, AverageRating: 4.5, BayesianScore: 3.9},
+	}}
+	api := &ratingAPI{}
+	s := &service{repository: store, apiServer: api, env: config_assets.ReadENV{TokenSystem: "system-token"}}
+
+	s.syncProductRating(context.Background(), "p-1")
+	// sản phẩm không còn đánh giá hiển thị vẫn gửi điểm 0 để product service xóa điểm cũ
+	s.syncProductRating(context.Background(), "p-2")
+
+	if len(api.sent) != 2 {
+		t.Fatalf("muốn gửi 2 lần, nhận %d", len(api.sent))
+	}
+	for _, token := range api.tokens {
+		if token != "system-token" {
+			t.Fatalf("phải gửi TOKEN_SYSTEM, nhận %q", token)
+		}
+	}
+	want := server_product.ProductRatingParams{ProductID: "p-1", RatingAverage: 4.5, RatingCount: 4, RatingScore: 3.9}
+	if api.sent[0][0] != want {
+		t.Fatalf("muốn %+v, nhận %+v", want, api.sent[0][0])
+	}
+	if got := api.sent[1][0]; got.ProductID != "p-2" || got.RatingCount != 0 || got.RatingScore != 0 {
+		t.Fatalf("sản phẩm không có đánh giá phải gửi điểm 0, nhận %+v", got)
+	}
+}
+
+func TestResyncProductRatingsBatches(t *testing.T) {
+	store := &ratingStore{}
+	for i := 0; i < ratingSyncBatchSize+5; i++ {
+		store.summaries = append(store.summaries, db.ProductRatingSummary{ProductID: fmt.Sprintf("p-%03d", i)})
+	}
+	api := &ratingAPI{}
+	s := &service{repository: store, apiServer: api, env: config_assets.ReadENV{TokenSystem: "system-token"}}
+
+	result, err := s.ResyncProductRatings(context.Background())
+	if err != nil {
+		t.Fatalf("lỗi %v", err)
+	}
+	if result["synced"] != ratingSyncBatchSize+5 {
+		t.Fatalf("muốn đồng bộ %d sản phẩm, nhận %v", ratingSyncBatchSize+5, result["synced"])
+	}
+	if len(api.sent) != 2 || len(api.sent[0]) != ratingSyncBatchSize || len(api.sent[1]) != 5 {
+		t.Fatalf("muốn 2 lô %d và 5 sản phẩm, nhận %d lô", ratingSyncBatchSize, len(api.sent))
+	}
+	// lô sau đọc tiếp từ product_id cuối của lô trước
+	if store.listCalls[1].ProductID != store.summaries[ratingSyncBatchSize-1].ProductID {
+		t.Fatalf("lô thứ 2 phải bắt đầu sau %s, nhận %s", store.summaries[ratingSyncBatchSize-1].ProductID, store.listCalls[1].ProductID)
+	}
+
+	api.err = errors.New("product service không phản hồi")
+	_, err = s.ResyncProductRatings(context.Background())
+	if err == nil || err.Code != http.StatusBadGateway {
+		t.Fatalf("muốn lỗi 502 khi product service lỗi, nhận %v", err)
+	}
+}
@@ -2,21 +2,31 @@ package services
 
 import (
 	config_assets "github.com/TranVinhHien/ecom_order_service/assets/config"
+	assets_firebase "github.com/TranVinhHien/ecom_order_service/assets/fire-base"
 	"github.com/TranVinhHien/ecom_order_service/assets/token"
 	db "github.com/TranVinhHien/ecom_order_service/db/mysql"
 	"github.com/TranVinhHien/ecom_order_service/server"
+	assets_services "github.com/TranVinhHien/ecom_order_service/services/assets"
 )
 
 type service struct {
 	repository db.Store
 	redis      ServicesRedis
+	events     ServicesEvent // nil nếu không kết nối được Kafka
 	jwt        token.Maker
 	env        config_assets.ReadENV
 	apiServer  server.ApiServer
-	// firebase   *assets_firebase.FirebaseMessaging
+	firebase   *assets_firebase.FirebaseMessaging // nil nếu không cấu hình FIREBASE_CREDENTIALS
+	// bộ lọc từ ngữ cấm cho đánh giá, dựng 1 lần từ REVIEW_BANNED_TERMS
+	reviewFilter *assets_services.ContentFilter
 	// jobs       *assets_jobs.JobScheduler
 }
 
-func NewService(repo db.Store, jwt token.Maker, env config_assets.ReadENV, redis ServicesRedis, apiServer server.ApiServer) ServiceUseCase {
-	return &service{repository: repo, jwt: jwt, env: env, redis: redis, apiServer: apiServer}
+func NewService(repo db.Store, jwt token.Maker, env config_assets.ReadENV, redis ServicesRedis, apiServer server.ApiServer, firebase *assets_firebase.FirebaseMessaging, events ServicesEvent) ServiceUseCase {
+	terms := env.ReviewBannedTerms
+	if len(terms) == 0 {
+		terms = defaultReviewBannedTerms
+	}
+	return &service{repository: repo, jwt: jwt, env: env, redis: redis, apiServer: apiServer, firebase: firebase, events: events,
+		reviewFilter: assets_services.NewContentFilter(terms)}
 }
@@ -10,6 +10,8 @@ import (
 const (
 	TopicPaymentCompleted = "payment.completed"
 	TopicPaymentFailed    = "payment.failed"
+	// settlement của shop order được tạo/đổi trạng thái, analytics service dùng để cập nhật bảng tổng hợp
+	TopicSettlementUpdated = "settlement.updated"
 )
 
 // EventProducer là interface để các service của bạn sử dụng
@@ -17,6 +19,7 @@ type EventProducer interface {
 	// Publish(ctx context.Context, topic string, key string, message []byte) error
 	PaymentCompleted(ctx context.Context, key string, message map[string]interface{}) error
 	PaymentFailed(ctx context.Context, key string, message map[string]interface{}) error
+	SettlementUpdated(ctx context.Context, key string, message map[string]interface{}) error
 	Close() error
 }
 
@@ -50,6 +53,13 @@ func (p *kafkaProducer) PaymentFailed(ctx context.Context, key string, message m
 	}
 	return p.publish(ctx, TopicPaymentFailed, key, messageBytes)
 }
+func (p *kafkaProducer) SettlementUpdated(ctx context.Context, key string, message map[string]interface{}) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return p.publish(ctx, TopicSettlementUpdated, key, messageBytes)
+}
 func (p *kafkaProducer) publish(ctx context.Context, topic string, key string, message []byte) error {
 	msg := &sarama.ProducerMessage{
 		Topic: topic,
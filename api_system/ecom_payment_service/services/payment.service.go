@@ -203,6 +203,19 @@ func (s *service) InitPayment(ctx context.Context, userId string, email string,
 		// Lỗi xảy ra trong transaction (DB hoặc MoMo API...)
 		return nil, assets_services.NewError(400, err)
 	}
+	// báo cho analytics service các settlement vừa tạo, lỗi chỉ ghi log
+	for _, item := range order.SettlementDetails {
+		eventData := map[string]interface{}{
+			"shop_order_id":  item.ShopOrderID,
+			"order_id":       order.OrderID,
+			"transaction_id": transactionID,
+			"status":         string(db.ShopOrderSettlementsStatusPENDINGSETTLEMENT),
+			"occurred_at":    time.Now(),
+		}
+		if err := s.producer.SettlementUpdated(ctx, item.ShopOrderID, eventData); err != nil {
+			fmt.Println("❌ Lỗi gửi Kafka event SettlementUpdated:", item.ShopOrderID, err)
+		}
+	}
 	result, _ := assets_services.HideFields(paymentResult, "data")
 
 	return result, nil
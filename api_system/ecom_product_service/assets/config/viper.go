@@ -3,6 +3,8 @@ package config_assets
 // write a struct and a function to read the .env using viper
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -13,8 +15,29 @@ type ReadENV struct {
 	ClientIP          []string `mapstructure:"CLIENT_IP"`
 	RedisAddress      string   `mapstructure:"REDIS_ADDRESS"`
 	// // URL service
-	ImagePath         string `mapstructure:"IMAGE_PATH"`
-	OrderServiceURL   string `mapstructure:"ORDER_SERVICE_URL"`
+	ImagePath       string `mapstructure:"IMAGE_PATH"`
+	OrderServiceURL string `mapstructure:"ORDER_SERVICE_URL"`
+	// Firebase dùng để gửi thông báo cho shop (bỏ trống nếu không dùng)
+	FirebaseCredentials string `mapstructure:"FIREBASE_CREDENTIALS"`
+	// Ngưỡng thay đổi giá (tỉ lệ, vd 0.3 = 30%) khiến sản phẩm phải duyệt lại
+	ModerationPriceThreshold float64 `mapstructure:"MODERATION_PRICE_THRESHOLD"`
+	// Khóa ký cursor phân trang, bỏ trống thì dùng JWT_SECRET
+	CursorSecret string `mapstructure:"CURSOR_SECRET"`
+	// Thời gian giữ cache chi tiết sản phẩm trong Redis (vd 10m), bỏ trống thì dùng mặc định
+	ProductDetailCacheTTL time.Duration `mapstructure:"PRODUCT_DETAIL_CACHE_TTL"`
+	// Giới hạn ảnh upload: dung lượng tối đa (bytes) và cạnh dài nhất (pixel), bỏ trống thì dùng mặc định
+	MediaMaxUploadSize int64 `mapstructure:"MEDIA_MAX_UPLOAD_SIZE"`
+	MediaMaxDimension  int   `mapstructure:"MEDIA_MAX_DIMENSION"`
+	// Tổng số pixel tối đa (rộng x cao) của ảnh, chặn ảnh nhỏ về dung lượng nhưng giải mã ra rất nhiều bộ nhớ
+	MediaMaxPixels int64 `mapstructure:"MEDIA_MAX_PIXELS"`
+	// Giới hạn video upload (chỉ MP4, dùng cho đánh giá sản phẩm): dung lượng tối đa (bytes) và thời lượng tối đa
+	MediaMaxVideoSize     int64         `mapstructure:"MEDIA_MAX_VIDEO_SIZE"`
+	MediaMaxVideoDuration time.Duration `mapstructure:"MEDIA_MAX_VIDEO_DURATION"`
+	// Job dọn file media không còn được sử dụng: chu kỳ chạy và thời gian chờ trước khi file mới upload bị coi là mồ côi
+	MediaReaperInterval time.Duration `mapstructure:"MEDIA_REAPER_INTERVAL"`
+	MediaOrphanGrace    time.Duration `mapstructure:"MEDIA_ORPHAN_GRACE"`
+	// Token các service nội bộ dùng khi gọi nhau (giống TOKEN_SYSTEM của order service)
+	TokenSystem string `mapstructure:"TOKEN_SYSTEM"`
 }
 
 func LoadConfig(path string) (config ReadENV, err error) {
@@ -19,6 +19,7 @@ type Payload struct {
 	Jti    string `json:"jti"`
 	UserId string `json:"userId"`
 	Email  string `json:"email"`
+	ShopId string `json:"shopId"` // chỉ có với tài khoản người bán, có thể rỗng
 }
 
 func CreateNewPayload(username string, duration time.Duration) *Payload {
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
+	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (api *apiController) listBrands() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		pageInt, errors := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+		pageSizeInt, errors := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+
+		brands, err := api.service.ListBrands(ctx, services.NewQueryFilter(pageInt, pageSizeInt, nil, nil))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get brands successfully", brands))
+	}
+}
+
+func (api *apiController) searchBrands() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		keyword := ctx.Query("keywords")
+
+		brands, err := api.service.SearchBrands(ctx, keyword)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("search brands successfully", brands))
+	}
+}
+
+func (api *apiController) getBrandDetail() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		key := ctx.Param("id")
+		if key == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide brand_id or code"))
+			return
+		}
+
+		brand, err := api.service.GetBrandDetail(ctx, key)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get brand successfully", brand))
+	}
+}
+
+func (api *apiController) createBrand() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var image *multipart.FileHeader
+		if file, err := ctx.FormFile("image"); err == nil {
+			image = file
+		}
+		brand := services.Brand{
+			Name: ctx.PostForm("name"),
+			Code: ctx.PostForm("code"),
+		}
+
+		errors := api.service.CreateBrand(ctx, authPayload.Sub, brand, image)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("create brand successfully", nil))
+	}
+}
+
+func (api *apiController) updateBrand() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		brandID := ctx.Param("id")
+		if brandID == "" {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "brand_id is required"))
+			return
+		}
+
+		var image *multipart.FileHeader
+		if file, err := ctx.FormFile("image"); err == nil {
+			image = file
+		}
+		brand := services.Brand{
+			BrandID: brandID,
+			Name:    ctx.PostForm("name"),
+			Code:    ctx.PostForm("code"),
+		}
+
+		errors := api.service.UpdateBrand(ctx, authPayload.Sub, brand, image)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("update brand successfully", nil))
+	}
+}
+
+func (api *apiController) deleteBrand() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		brandID := ctx.Param("id")
+		if brandID == "" {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "brand_id is required"))
+			return
+		}
+		// reassign_to: thương hiệu nhận lại các sản phẩm đang dùng thương hiệu bị xóa
+		reassignTo := ctx.Query("reassign_to")
+
+		err := api.service.DeleteBrand(ctx, authPayload.Sub, brandID, reassignTo)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("delete brand successfully", nil))
+	}
+}
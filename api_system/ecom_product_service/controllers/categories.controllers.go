@@ -7,6 +7,7 @@ import (
 
 	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	controllers_model "github.com/TranVinhHien/ecom_product_service/controllers/models"
 	services "github.com/TranVinhHien/ecom_product_service/services/entity"
 
 	"github.com/gin-gonic/gin"
@@ -94,12 +95,14 @@ func (api *apiController) updateCategories() func(ctx *gin.Context) {
 func (api *apiController) deleteCategories() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
-		categoryID := ctx.Param("cate_id") // or ctx.Query("cate_id")
+		categoryID := ctx.Param("id")
 		if categoryID == "" {
 			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, "cate_id is required"))
 			return
 		}
-		err := api.service.DeleteCategory(ctx, authPayload.Sub, categoryID)
+		// reassign_to: danh mục nhận lại các sản phẩm của danh mục bị xóa
+		reassignTo := ctx.Query("reassign_to")
+		err := api.service.DeleteCategory(ctx, authPayload.Sub, categoryID, reassignTo)
 		if err != nil {
 			fmt.Print(err)
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
@@ -109,3 +112,41 @@ func (api *apiController) deleteCategories() func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("delete category successful", nil))
 	}
 }
+func (api *apiController) moveCategory() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.MoveCategoryRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		err := api.service.MoveCategory(ctx, authPayload.Sub, req.CategoryID, req.Parent)
+		if err != nil {
+			fmt.Print(err)
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("move category successful", nil))
+	}
+}
+func (api *apiController) reorderCategories() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.ReorderCategoriesRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		err := api.service.ReorderCategories(ctx, authPayload.Sub, req.Parent, req.CategoryIDs)
+		if err != nil {
+			fmt.Print(err)
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("reorder categories successful", nil))
+	}
+}
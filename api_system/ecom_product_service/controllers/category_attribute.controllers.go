@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
+	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	controllers_model "github.com/TranVinhHien/ecom_product_service/controllers/models"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (api *apiController) listCategoryAttributes() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		attrs, err := api.service.ListCategoryAttributes(ctx, ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get category attributes successful", attrs))
+	}
+}
+func (api *apiController) createCategoryAttribute() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.CategoryAttributeRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		err := api.service.CreateCategoryAttribute(ctx, authPayload.Sub, services.CategoryAttribute{
+			CategoryID:   req.CategoryID,
+			Code:         req.Code,
+			Name:         req.Name,
+			DataType:     req.DataType,
+			Unit:         req.Unit,
+			EnumValues:   req.EnumValues,
+			IsRequired:   req.IsRequired,
+			IsFilterable: req.IsFilterable,
+			SortOrder:    req.SortOrder,
+		})
+		if err != nil {
+			fmt.Print(err)
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("create category attribute successful", nil))
+	}
+}
+func (api *apiController) updateCategoryAttribute() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.CategoryAttributeUpdateRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		err := api.service.UpdateCategoryAttribute(ctx, authPayload.Sub, services.CategoryAttributeUpdate{
+			ID:           ctx.Param("id"),
+			Name:         req.Name,
+			Unit:         req.Unit,
+			EnumValues:   req.EnumValues,
+			IsRequired:   req.IsRequired,
+			IsFilterable: req.IsFilterable,
+			SortOrder:    req.SortOrder,
+		})
+		if err != nil {
+			fmt.Print(err)
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("update category attribute successful", nil))
+	}
+}
+func (api *apiController) deleteCategoryAttribute() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		err := api.service.DeleteCategoryAttribute(ctx, authPayload.Sub, ctx.Param("id"))
+		if err != nil {
+			fmt.Print(err)
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("delete category attribute successful", nil))
+	}
+}
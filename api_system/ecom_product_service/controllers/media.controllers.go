@@ -7,14 +7,25 @@ import (
 	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
 	controllers_model "github.com/TranVinhHien/ecom_product_service/controllers/models"
+	services "github.com/TranVinhHien/ecom_product_service/services"
 	"github.com/gin-gonic/gin"
 )
 
 func (api *apiController) renderURLLocal() func(c *gin.Context) {
 	return func(ctx *gin.Context) {
 		filename := ctx.Param("id")
+		// size: thumb, medium, large; bỏ trống để lấy ảnh gốc
+		size := ctx.Query("size")
+		if size != "" && !services.IsMediaVariant(size) {
+			ctx.JSON(400, assets_api.ResponseError(400, "size must be one of thumb, medium, large"))
+			return
+		}
 
-		filePath := api.service.RenderImage(ctx, filename)
+		filePath := api.service.RenderImage(ctx, filename, size)
+		if filePath == "" {
+			ctx.JSON(404, gin.H{"error": "File not found"})
+			return
+		}
 		if _, err := os.Stat(filePath); os.IsNotExist(err) {
 			// Nếu file không tồn tại, trả về lỗi 404
 			ctx.JSON(404, gin.H{"error": "File not found", "filePath": filePath})
@@ -44,17 +55,35 @@ func (api *apiController) uploadMultiMedia() func(c *gin.Context) {
 	}
 }
 
+// uploadReviewMedia ảnh/video đính kèm đánh giá sản phẩm, chỉ API này nhận video
+func (api *apiController) uploadReviewMedia() func(c *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			ctx.JSON(400, gin.H{"error": "Invalid form data file"})
+			return
+		}
+		result, errorr := api.service.UploadReviewMedia(ctx, authPayload.Sub, form.File["media"])
+		if errorr != nil {
+			ctx.JSON(errorr.Code, assets_api.ResponseError(errorr.Code, errorr.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("upload media success", result))
+	}
+}
+
 func (api *apiController) deleteMultiImage() func(c *gin.Context) {
 	return func(ctx *gin.Context) {
-		// authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
 
 		var req controllers_model.DeleteMediaParams
 		if err := ctx.ShouldBindJSON(&req); err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
 			return
 		}
-		// errorr := api.service.DeleteMultiImage(ctx, authPayload.Sub, req.ListID)
-		errorr := api.service.DeleteMultiImage(ctx, "123", req.ListID)
+		errorr := api.service.DeleteOwnedMedia(ctx, principalFromPayload(authPayload), req.ListID)
 		if errorr != nil {
 			ctx.JSON(errorr.Code, assets_api.ResponseError(errorr.Code, errorr.Error()))
 			return
@@ -63,3 +92,15 @@ func (api *apiController) deleteMultiImage() func(c *gin.Context) {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("delete image success", nil))
 	}
 }
+
+func (api *apiController) reapOrphanMedia() func(c *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.ReapOrphanMedia(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("reap orphan media success", result))
+	}
+}
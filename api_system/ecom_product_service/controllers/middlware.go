@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"strings"
 
 	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
 
 	"github.com/gin-gonic/gin"
 )
@@ -44,6 +46,19 @@ func authorization(jwt token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// optionalAuthorization dùng cho API công khai: không có header thì đi tiếp như khách,
+// có header thì token phải hợp lệ
+func optionalAuthorization(jwt token.Maker) gin.HandlerFunc {
+	required := authorization(jwt)
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(authorizationKey) == "" {
+			ctx.Next()
+			return
+		}
+		required(ctx)
+	}
+}
 func checkRole(roles []string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		payload, exists := ctx.Get(authorizationPayload)
@@ -67,3 +82,41 @@ func checkRole(roles []string) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// authorizationSystemOrAdmin cho phép service nội bộ gọi bằng TOKEN_SYSTEM, ngoài ra phải là admin.
+// tokenSystem rỗng (chưa cấu hình) thì chỉ admin được gọi.
+func authorizationSystemOrAdmin(jwt token.Maker, tokenSystem string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		fields := strings.Fields(ctx.GetHeader(authorizationKey))
+		if len(fields) < 2 || strings.ToLower(fields[0]) != authorizationType {
+			ctx.AbortWithStatusJSON(401, assets_api.ResponseError(401, "authorization header is not provided"))
+			return
+		}
+		if tokenSystem != "" && subtle.ConstantTimeCompare([]byte(fields[1]), []byte(tokenSystem)) == 1 {
+			ctx.Next()
+			return
+		}
+		payload, err := jwt.VerifyToken(fields[1])
+		if err != nil {
+			ctx.AbortWithStatusJSON(401, assets_api.ResponseError(401, "invalid access token: "+err.Error()))
+			return
+		}
+		if payload.Scope != "ROLE_ADMIN" {
+			ctx.AbortWithStatusJSON(403, assets_api.ResponseError(403, "không có quyền truy cập, chức năng này chỉ dành cho service nội bộ hoặc ROLE_ADMIN"))
+			return
+		}
+		ctx.Set(authorizationPayload, payload)
+		ctx.Set("token", fields[1])
+		ctx.Next()
+	}
+}
+
+// principalFromPayload chuyển payload của token sang thông tin người thao tác cho tầng service
+func principalFromPayload(payload *token.Payload) services.Principal {
+	return services.Principal{
+		UserID:   payload.UserId,
+		UserName: payload.Sub,
+		Role:     payload.Scope,
+		ShopID:   payload.ShopId,
+	}
+}
@@ -8,3 +8,35 @@ package controllers_model
 // 	Parent string                  `json:"parent"`
 // 	Image  []*multipart.FileHeader `json:"image"`
 // }
+
+type MoveCategoryRequest struct {
+	CategoryID string `json:"category_id" binding:"required"`
+	Parent     string `json:"parent"` // rỗng: chuyển lên làm danh mục gốc
+}
+
+type ReorderCategoriesRequest struct {
+	Parent      string   `json:"parent"`                                // rỗng: sắp xếp các danh mục gốc
+	CategoryIDs []string `json:"category_ids" binding:"required,min=1"` // đủ toàn bộ danh mục cùng cấp theo thứ tự mới
+}
+
+type CategoryAttributeRequest struct {
+	CategoryID   string   `json:"category_id" binding:"required"`
+	Code         string   `json:"code" binding:"required"`
+	Name         string   `json:"name" binding:"required"`
+	DataType     string   `json:"data_type" binding:"required,oneof=ENUM NUMBER BOOLEAN"`
+	Unit         string   `json:"unit"`
+	EnumValues   []string `json:"enum_values"`
+	IsRequired   bool     `json:"is_required"`
+	IsFilterable bool     `json:"is_filterable"`
+	SortOrder    int32    `json:"sort_order"`
+}
+
+// CategoryAttributeUpdateRequest không cho đổi code và data_type, trường nil là giữ nguyên
+type CategoryAttributeUpdateRequest struct {
+	Name         *string  `json:"name"`
+	Unit         *string  `json:"unit"`
+	EnumValues   []string `json:"enum_values"`
+	IsRequired   *bool    `json:"is_required"`
+	IsFilterable *bool    `json:"is_filterable"`
+	SortOrder    *int32   `json:"sort_order"`
+}
@@ -12,6 +12,8 @@ type Product struct {
 	ProductIsPermissionCheck  bool          `form:"product_is_permission_check" json:"product_is_permission_check" binding:"omitempty"`
 	ProductSKU                []ProductSku  `form:"product_sku" json:"product_sku" binding:"dive,required"`
 	OptionValue               []OptionValue `form:"option_value" json:"option_value" binding:"dive,required"`
+	// giá trị thuộc tính danh mục theo code, vd: {"screen_size": "6.1", "material": "Cotton"}
+	Attributes map[string]string `form:"attributes" json:"attributes"`
 }
 
 type ProductUpdate struct {
@@ -23,6 +25,10 @@ type ProductUpdate struct {
 	ProductIsPermissionCheck  *bool   `form:"product_is_permission_check" json:"product_is_permission_check"`
 	DeleteStatus              *bool   `form:"delete_status" json:"delete_status" `
 	ApprovalProduct           *bool   `form:"approval_product" json:"approval_product"`
+	RejectReason              string  `form:"reject_reason" json:"reject_reason"`
+	CategoryID                *string `form:"category_id" json:"category_id" binding:"omitempty,uuid"`
+	// nil: giữ nguyên; giá trị rỗng sẽ xóa thuộc tính tương ứng
+	Attributes map[string]string `form:"attributes" json:"attributes"`
 
 	ProductSKU  []ProductSku  `form:"product_sku" json:"product_sku" binding:"omitempty,dive"`
 	OptionValue []OptionValue `form:"option_value" json:"option_value" binding:"omitempty,dive"`
@@ -56,3 +62,33 @@ type UpdateSKUReserverRequest struct {
 	Data   []ProductUpdateSKUReserver `json:"data" binding:"required,dive"`
 	Status string                     `json:"status" binding:"required,oneof=commit hold rollback"`
 }
+
+type SKUBatchRequest struct {
+	SkuIDs []string `json:"sku_ids" binding:"required,min=1"`
+}
+
+// InvalidateProductCacheRequest service khác (vd order service khi có đánh giá mới) báo sản phẩm cần làm mới cache
+type InvalidateProductCacheRequest struct {
+	ProductIDs []string `json:"product_ids" binding:"required,min=1"`
+}
+
+// SyncProductRatingRequest order service gửi điểm đánh giá mới của các sản phẩm
+type SyncProductRatingRequest struct {
+	Ratings []ProductRating `json:"ratings" binding:"required,min=1,max=500,dive"`
+}
+
+type ProductRating struct {
+	ProductID     string  `json:"product_id" binding:"required"`
+	RatingAverage float64 `json:"rating_average" binding:"min=0,max=5"`
+	RatingCount   int32   `json:"rating_count" binding:"min=0"`
+	RatingScore   float64 `json:"rating_score" binding:"min=0,max=5"`
+}
+
+type RejectProductRequest struct {
+	Reason string `json:"reason" binding:"required,min=1"`
+}
+
+type SellerShopRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	ShopID string `json:"shop_id" binding:"required,uuid"`
+}
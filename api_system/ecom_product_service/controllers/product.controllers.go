@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
@@ -29,7 +30,7 @@ func (api *apiController) getAllProductSimple() func(ctx *gin.Context) {
 		keywords := ctx.DefaultQuery("keywords", "")
 		sort := ctx.DefaultQuery("sort", "")
 		status := ctx.DefaultQuery("status", "")
-		sort_order := []string{"price_asc", "price_desc", "name_asc", "name_desc", "best_sell"}
+		sort_order := []string{"price_asc", "price_desc", "name_asc", "name_desc", "best_sell", "rating", "relevance"}
 		// check if sort not in sort_order
 		if sort != "" {
 			check := false
@@ -47,7 +48,7 @@ func (api *apiController) getAllProductSimple() func(ctx *gin.Context) {
 			}
 		}
 
-		deleteStatus := []string{"Pending", "Deleted", "Active"}
+		deleteStatus := []string{"Pending", "Deleted", "Active", "Rejected", "Unlisted"}
 		// check if sort not in DeleteStatus
 		if status != "" {
 			check := false
@@ -87,7 +88,26 @@ func (api *apiController) getAllProductSimple() func(ctx *gin.Context) {
 			return
 		}
 
-		orders, err := api.service.GetAllProductSimple(ctx, services.NewQueryFilter(pageInt, pageSizeInt, nil, nil), cate_path, brand, shop_id, keywords, sort, float64(price_min), float64(price_max), status)
+		// lọc theo thuộc tính danh mục: attr.<code>=<giá trị>
+		attributes := make(map[string]string)
+		for key, values := range ctx.Request.URL.Query() {
+			if code, ok := strings.CutPrefix(key, "attr."); ok && code != "" && len(values) > 0 {
+				attributes[code] = strings.Join(values, ",")
+			}
+		}
+
+		// phân trang theo cursor: pagination=cursor cho trang đầu, các trang sau gửi cursor nhận được
+		query := services.NewQueryFilter(pageInt, pageSizeInt, nil, nil)
+		query.Cursor = ctx.DefaultQuery("cursor", "")
+		query.CursorMode = ctx.DefaultQuery("pagination", "") == "cursor" || query.Cursor != ""
+		query.WithTotal = ctx.DefaultQuery("with_total", "") == "true"
+
+		// khách không đăng nhập chỉ xem được sản phẩm đang bán
+		var principal services.Principal
+		if payload, ok := ctx.Get(authorizationPayload); ok {
+			principal = principalFromPayload(payload.(*token.Payload))
+		}
+		orders, err := api.service.GetAllProductSimple(ctx, principal, query, cate_path, brand, shop_id, keywords, sort, float64(price_min), float64(price_max), status, attributes)
 		if err != nil {
 			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
 			return
@@ -173,7 +193,7 @@ func (api *apiController) createProduct() func(ctx *gin.Context) {
 			}
 		}
 		// 5. Gọi hàm xử lý logic
-		errors := api.service.CreateProduct(ctx, token, authPayload.Sub,
+		errors := api.service.CreateProduct(ctx, token, principalFromPayload(authPayload),
 			productParams, image, mediaFiles, option_images)
 
 		if errors != nil {
@@ -255,7 +275,7 @@ func (api *apiController) updateProduct() func(ctx *gin.Context) {
 		}
 
 		// 9. Gọi service để update product
-		errors := api.service.UpdateProduct(ctx, authPayload.Scope, authPayload.Sub, productID,
+		errors := api.service.UpdateProduct(ctx, principalFromPayload(authPayload), productID,
 			productParams, image, mediaFiles, nil)
 
 		if errors != nil {
@@ -292,6 +312,112 @@ func (api *apiController) updateSKUReserverProduct() func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("update sku reserver product successfully", nil))
 	}
 }
+func (api *apiController) getSKUsBatch() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req controllers_model.SKUBatchRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		result, err := api.service.GetSKUsBatch(ctx, req.SkuIDs)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get skus successful", result))
+	}
+}
+func (api *apiController) getSKUPriceHistory() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		limit, errors := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "limit must be a number"))
+			return
+		}
+		result, err := api.service.GetSKUPriceHistory(ctx, ctx.Param("id"), limit)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get sku price history successful", result))
+	}
+}
+
+// priceRaiseReport: promotion_start (RFC3339 hoặc 2006-01-02), window_hours (mặc định 168),
+// shop_id, min_increase_percent, limit
+func (api *apiController) priceRaiseReport() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		params := services.PriceRaiseReportParams{ShopID: ctx.Query("shop_id")}
+		start := ctx.Query("promotion_start")
+		promotionStart, errors := time.Parse(time.RFC3339, start)
+		if errors != nil {
+			promotionStart, errors = time.ParseInLocation("2006-01-02", start, time.Local)
+			if errors != nil {
+				ctx.JSON(402, assets_api.ResponseError(402, "promotion_start must be RFC3339 or YYYY-MM-DD"))
+				return
+			}
+		}
+		params.PromotionStart = promotionStart
+		windowHours, errors := strconv.Atoi(ctx.DefaultQuery("window_hours", "0"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "window_hours must be a number"))
+			return
+		}
+		params.Window = time.Duration(windowHours) * time.Hour
+		params.MinIncreasePercent, errors = strconv.ParseFloat(ctx.DefaultQuery("min_increase_percent", "0"), 64)
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "min_increase_percent must be a number"))
+			return
+		}
+		params.Limit, errors = strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "limit must be a number"))
+			return
+		}
+		result, err := api.service.PriceRaiseReport(ctx, params)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get price raise report successful", result))
+	}
+}
+
+func (api *apiController) invalidateProductDetailCache() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req controllers_model.InvalidateProductCacheRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		errors := api.service.InvalidateProductDetailCache(ctx, req.ProductIDs)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("invalidate product cache successfully", nil))
+	}
+}
+func (api *apiController) syncProductRatings() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req controllers_model.SyncProductRatingRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		var ratings []services.ProductRatingSync
+		if err := copier.Copy(&ratings, &req.Ratings); err != nil {
+			ctx.JSON(http.StatusInternalServerError, assets_api.ResponseError(http.StatusInternalServerError, err.Error()))
+			return
+		}
+		errors := api.service.SyncProductRatings(ctx, ratings)
+		if errors != nil {
+			ctx.JSON(errors.Code, assets_api.ResponseError(errors.Code, errors.Error()))
+			return
+		}
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("sync product rating successfully", nil))
+	}
+}
 func (api *apiController) getSKUProduct() func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		sku_id := ctx.Param("id")
@@ -369,3 +495,84 @@ func (api *apiController) getListProductWithIDs() func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get list product with IDs successfully", products))
 	}
 }
+func (api *apiController) reindexProductAggregates() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.ReindexProductAggregates(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("reindex product aggregates successful", result))
+	}
+}
+func (api *apiController) reindexProductSearch() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.ReindexProductSearch(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("reindex product search successful", result))
+	}
+}
+
+func (api *apiController) suggestProducts() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		q := ctx.DefaultQuery("q", "")
+		limit, errors := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "limit must be a number"))
+			return
+		}
+		result, err := api.service.Suggest(ctx, q, limit)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get suggestions successful", result))
+	}
+}
+
+func (api *apiController) rebuildSuggestIndex() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.RebuildSuggestIndex(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("rebuild suggest index successful", result))
+	}
+}
+
+func (api *apiController) topSearchQueries() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		limit, errors := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, "limit must be a number"))
+			return
+		}
+		result, err := api.service.TopSearchQueries(ctx, limit)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get popular search queries successful", result))
+	}
+}
+
+func (api *apiController) productDetailCacheStats() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		result, err := api.service.ProductDetailCacheStats(ctx)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get product cache stats successful", result))
+	}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
+	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	controllers_model "github.com/TranVinhHien/ecom_product_service/controllers/models"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (api *apiController) listPendingProducts() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		pageInt, errors := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+		pageSizeInt, errors := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+		shop_id := ctx.DefaultQuery("shop_id", "")
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		products, err := api.service.GetAllProductSimple(ctx, principalFromPayload(authPayload), services.NewQueryFilter(pageInt, pageSizeInt, nil, nil), "", "", shop_id, "", "", -1, -1, "Pending", nil)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get pending products successfully", products))
+	}
+}
+
+func (api *apiController) approveProduct() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		productID := ctx.Param("id")
+		if productID == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide product_id"))
+			return
+		}
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		err := api.service.ApproveProduct(ctx, authPayload.Sub, productID)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("approve product successfully", nil))
+	}
+}
+
+func (api *apiController) rejectProduct() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		productID := ctx.Param("id")
+		if productID == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide product_id"))
+			return
+		}
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.RejectProductRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		err := api.service.RejectProduct(ctx, authPayload.Sub, productID, req.Reason)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("reject product successfully", nil))
+	}
+}
+
+func (api *apiController) listProductModeration() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		productID := ctx.Param("id")
+		if productID == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide product_id"))
+			return
+		}
+
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		history, err := api.service.ListProductModeration(ctx, principalFromPayload(authPayload), productID)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get product moderation history successfully", history))
+	}
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
+	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type productStatusAction func(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError
+
+// changeProductStatus dùng chung cho các thao tác ẩn/hiện/xóa/khôi phục sản phẩm của người bán
+func (api *apiController) changeProductStatus(action productStatusAction, message string) func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		productID := ctx.Param("id")
+		if productID == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide product_id"))
+			return
+		}
+
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		if err := action(ctx, principalFromPayload(authPayload), productID); err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse(message, nil))
+	}
+}
+
+func (api *apiController) unlistProduct() func(ctx *gin.Context) {
+	return api.changeProductStatus(api.service.UnlistProduct, "unlist product successfully")
+}
+
+func (api *apiController) relistProduct() func(ctx *gin.Context) {
+	return api.changeProductStatus(api.service.RelistProduct, "relist product successfully")
+}
+
+func (api *apiController) deleteProduct() func(ctx *gin.Context) {
+	return api.changeProductStatus(api.service.SoftDeleteProduct, "delete product successfully")
+}
+
+func (api *apiController) restoreProduct() func(ctx *gin.Context) {
+	return api.changeProductStatus(api.service.RestoreProduct, "restore product successfully")
+}
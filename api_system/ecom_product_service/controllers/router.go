@@ -8,12 +8,13 @@ import (
 )
 
 type apiController struct {
-	service services.ServiceUseCase
-	jwt     token.Maker
+	service     services.ServiceUseCase
+	jwt         token.Maker
+	tokenSystem string
 }
 
-func NewAPIController(s services.ServiceUseCase, jwt token.Maker) apiController {
-	return apiController{service: s, jwt: jwt}
+func NewAPIController(s services.ServiceUseCase, jwt token.Maker, tokenSystem string) apiController {
+	return apiController{service: s, jwt: jwt, tokenSystem: tokenSystem}
 }
 
 func (api apiController) SetUpRoute(group *gin.RouterGroup) {
@@ -25,16 +26,35 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 	categories := group.Group("/categories")
 	{
 		categories.GET("/get", api.listCategories())
+		categories.GET("/attributes/:id", api.listCategoryAttributes())
 		categories_auth := categories.Group("").Use(authorization(api.jwt)).Use(checkRole([]string{"ROLE_ADMIN"}))
 		{
 			categories_auth.POST("/create", api.createCategories())
 			categories_auth.PUT("/update", api.updateCategories())
 			categories_auth.DELETE("/delete/:id", api.deleteCategories())
+			categories_auth.PUT("/move", api.moveCategory())
+			categories_auth.PUT("/reorder", api.reorderCategories())
+			categories_auth.POST("/attributes/create", api.createCategoryAttribute())
+			categories_auth.PUT("/attributes/update/:id", api.updateCategoryAttribute())
+			categories_auth.DELETE("/attributes/delete/:id", api.deleteCategoryAttribute())
+		}
+	}
+	brands := group.Group("/brands")
+	{
+		brands.GET("/get", api.listBrands())
+		brands.GET("/search", api.searchBrands())
+		brands.GET("/detail/:id", api.getBrandDetail())
+		brands_auth := brands.Group("").Use(authorization(api.jwt)).Use(checkRole([]string{"ROLE_ADMIN"}))
+		{
+			brands_auth.POST("/create", api.createBrand())
+			brands_auth.PUT("/update/:id", api.updateBrand())
+			brands_auth.DELETE("/delete/:id", api.deleteBrand())
 		}
 	}
 	product := group.Group("/product")
 	{
-		product.GET("/getall", api.getAllProductSimple())
+		product.GET("/getall", optionalAuthorization(api.jwt), api.getAllProductSimple())
+		product.GET("/suggest", api.suggestProducts())
 		product.GET("/getdetail/:id", api.getDetailProduct())
 		product.GET("/build_search_string/:id", api.buildProductSearchString())
 		product.GET("/getallproductid", api.getAllProductID())
@@ -44,11 +64,46 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 		{
 			product_auth.POST("/create", api.createProduct())
 			product_auth.PUT("/update/:id", api.updateProduct())
+			// ẩn/hiện lại, xóa mềm và khôi phục sản phẩm
+			product_auth.POST("/unlist/:id", api.unlistProduct())
+			product_auth.POST("/relist/:id", api.relistProduct())
+			product_auth.DELETE("/delete/:id", api.deleteProduct())
+			product_auth.POST("/restore/:id", api.restoreProduct())
+		}
+		// kiểm duyệt sản phẩm
+		moderation := product.Group("/moderation").Use(authorization(api.jwt))
+		{
+			moderation.GET("/queue", checkRole([]string{"ROLE_ADMIN"}), api.listPendingProducts())
+			moderation.POST("/:id/approve", checkRole([]string{"ROLE_ADMIN"}), api.approveProduct())
+			moderation.POST("/:id/reject", checkRole([]string{"ROLE_ADMIN"}), api.rejectProduct())
+			moderation.GET("/:id/history", checkRole([]string{"ROLE_SELLER", "ROLE_ADMIN"}), api.listProductModeration())
+		}
+		// tác vụ quản trị dữ liệu sản phẩm
+		product_admin := product.Group("/admin").Use(authorization(api.jwt)).Use(checkRole([]string{"ROLE_ADMIN"}))
+		{
+			product_admin.POST("/reindex_aggregates", api.reindexProductAggregates())
+			product_admin.POST("/reindex_search", api.reindexProductSearch())
+			product_admin.POST("/rebuild_suggest", api.rebuildSuggestIndex())
+			product_admin.GET("/popular_queries", api.topSearchQueries())
+			product_admin.GET("/cache_stats", api.productDetailCacheStats())
+			product_admin.GET("/price_raise_report", api.priceRaiseReport())
+		}
+		// quản lý shop của người bán (bảng seller_shop) và nhật ký vi phạm quyền sở hữu
+		ownership := product.Group("/ownership").Use(authorization(api.jwt)).Use(checkRole([]string{"ROLE_ADMIN"}))
+		{
+			ownership.GET("/seller/:user_id", api.listSellerShops())
+			ownership.POST("/seller", api.assignSellerShop())
+			ownership.DELETE("/seller", api.removeSellerShop())
+			ownership.GET("/audit", api.listOwnershipAudit())
 		}
 		// sau này tạo thêm check endpoint chỉ cho phép admin mới được xóa sản phẩm
 		product.POST("/update_sku_reserver", api.updateSKUReserverProduct())
+		product.POST("/invalidate_detail_cache", authorizationSystemOrAdmin(api.jwt, api.tokenSystem), api.invalidateProductDetailCache())
+		product.POST("/sync_rating", authorizationSystemOrAdmin(api.jwt, api.tokenSystem), api.syncProductRatings())
 
 		product.GET("/getsku/:id", api.getSKUProduct())
+		product.GET("/getsku/:id/price_history", api.getSKUPriceHistory())
+		product.POST("/skus/batch", api.getSKUsBatch())
 		product.GET("/getdetail_with_id/:id", api.getProductWithID())
 	}
 	media := group.Group("/media")
@@ -57,8 +112,12 @@ func (api apiController) SetUpRoute(group *gin.RouterGroup) {
 		media_auth := media.Group("").Use(authorization(api.jwt))
 		{
 			media_auth.POST("", api.uploadMultiMedia())
+			// ảnh hoặc video ngắn của đánh giá sản phẩm (order service gọi bằng token người mua)
+			media_auth.POST("/reviews", api.uploadReviewMedia())
 			media_auth.DELETE("", api.deleteMultiImage())
 		}
+		// dọn file media không còn được sử dụng (job cũng tự chạy định kỳ)
+		media.POST("/reap", authorization(api.jwt), checkRole([]string{"ROLE_ADMIN"}), api.reapOrphanMedia())
 	}
 
 }
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	assets_api "github.com/TranVinhHien/ecom_product_service/assets/api"
+	"github.com/TranVinhHien/ecom_product_service/assets/token"
+	controllers_model "github.com/TranVinhHien/ecom_product_service/controllers/models"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (api *apiController) listSellerShops() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		userID := ctx.Param("user_id")
+		if userID == "" {
+			ctx.JSON(402, assets_api.ResponseError(402, "must provide user_id"))
+			return
+		}
+
+		shops, err := api.service.ListSellerShops(ctx, userID)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get seller shops successfully", shops))
+	}
+}
+
+func (api *apiController) assignSellerShop() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayload).(*token.Payload)
+
+		var req controllers_model.SellerShopRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		err := api.service.AssignSellerShop(ctx, authPayload.Sub, req.UserID, req.ShopID)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("assign seller shop successfully", nil))
+	}
+}
+
+func (api *apiController) removeSellerShop() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		var req controllers_model.SellerShopRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, assets_api.ResponseError(http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		err := api.service.RemoveSellerShop(ctx, req.UserID, req.ShopID)
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("remove seller shop successfully", nil))
+	}
+}
+
+func (api *apiController) listOwnershipAudit() func(ctx *gin.Context) {
+	return func(ctx *gin.Context) {
+		pageInt, errors := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+		pageSizeInt, errors := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+		if errors != nil {
+			ctx.JSON(402, assets_api.ResponseError(402, errors.Error()))
+			return
+		}
+
+		audits, err := api.service.ListOwnershipAudit(ctx, services.NewQueryFilter(pageInt, pageSizeInt, nil, nil))
+		if err != nil {
+			ctx.JSON(err.Code, assets_api.ResponseError(err.Code, err.Error()))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, assets_api.SimpSuccessResponse("get ownership audit successfully", audits))
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_moderation;
+
+UPDATE product SET delete_status = 'Pending' WHERE delete_status = 'Rejected';
+ALTER TABLE product
+MODIFY COLUMN delete_status ENUM('Pending','Active', 'Deleted') DEFAULT 'Active';
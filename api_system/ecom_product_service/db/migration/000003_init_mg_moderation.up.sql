@@ -0,0 +1,21 @@
+-- =================================================================
+-- Kiểm duyệt sản phẩm
+-- Sản phẩm mới tạo hoặc bị sửa các thông tin quan trọng (tên, ảnh, danh mục,
+-- giá thay đổi vượt ngưỡng) sẽ chuyển về trạng thái Pending để admin duyệt.
+-- =================================================================
+ALTER TABLE product
+MODIFY COLUMN delete_status ENUM('Pending','Active', 'Deleted', 'Rejected') DEFAULT 'Active';
+
+-- Lịch sử kiểm duyệt của từng sản phẩm
+CREATE TABLE product_moderation (
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL,
+    action ENUM('SUBMITTED', 'APPROVED', 'REJECTED') NOT NULL,
+    reason TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci, -- Lý do từ chối (nếu có)
+    changed_fields VARCHAR(255), -- Các trường thay đổi khiến sản phẩm phải duyệt lại (JSON array)
+    actor VARCHAR(128) NOT NULL, -- Người thực hiện (seller hoặc admin)
+    create_date DATETIME DEFAULT NOW(),
+    FOREIGN KEY (product_id) REFERENCES product(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_moderation_product ON product_moderation(product_id, create_date);
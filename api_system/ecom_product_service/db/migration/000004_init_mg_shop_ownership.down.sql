@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_ownership_audit;
+DROP TABLE IF EXISTS seller_shop;
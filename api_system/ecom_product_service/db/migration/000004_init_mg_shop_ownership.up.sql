@@ -0,0 +1,27 @@
+-- =================================================================
+-- Quyền sở hữu shop
+-- Token không phải lúc nào cũng chứa shop của người bán, nên product service
+-- lưu thêm bảng ánh xạ seller -> shop để kiểm tra quyền khi thêm/sửa sản phẩm.
+-- =================================================================
+CREATE TABLE seller_shop (
+    user_id VARCHAR(36) NOT NULL, -- userId của người bán trong token
+    shop_id VARCHAR(36) NOT NULL,
+    create_by VARCHAR(128),
+    create_date DATETIME DEFAULT NOW(),
+    PRIMARY KEY (user_id, shop_id)
+);
+
+CREATE INDEX idx_seller_shop_shop ON seller_shop(shop_id);
+
+-- Nhật ký các lần thao tác sản phẩm của shop khác (bị từ chối)
+CREATE TABLE product_ownership_audit (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    user_name VARCHAR(128) NOT NULL,
+    shop_id VARCHAR(36) NOT NULL, -- shop mà người dùng cố thao tác
+    product_id VARCHAR(36), -- NULL khi tạo sản phẩm mới
+    action VARCHAR(64) NOT NULL, -- CREATE_PRODUCT, UPDATE_PRODUCT, VIEW_MODERATION_HISTORY...
+    create_date DATETIME DEFAULT NOW()
+);
+
+CREATE INDEX idx_product_ownership_audit_user ON product_ownership_audit(user_id, create_date);
@@ -0,0 +1,2 @@
+DROP INDEX idx_category_parent_sort ON category;
+ALTER TABLE category DROP COLUMN sort_order;
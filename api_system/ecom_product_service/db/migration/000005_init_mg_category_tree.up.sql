@@ -0,0 +1,25 @@
+-- =================================================================
+-- Cây danh mục
+-- Trigger tính path ở 000002 đã bị comment nên path được tính trong service.
+-- Thêm sort_order để sắp xếp các danh mục cùng cấp.
+-- =================================================================
+ALTER TABLE category
+ADD COLUMN sort_order INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_category_parent_sort ON category(parent, sort_order);
+
+-- Tính lại path cho toàn bộ danh mục hiện có theo dạng /cha/con
+UPDATE category c
+JOIN (
+    WITH RECURSIVE tree AS (
+        SELECT category_id, CAST(CONCAT('/', `key`) AS CHAR(500)) AS new_path
+        FROM category
+        WHERE parent IS NULL
+        UNION ALL
+        SELECT child.category_id, CONCAT(tree.new_path, '/', child.`key`)
+        FROM category child
+        JOIN tree ON child.parent = tree.category_id
+    )
+    SELECT category_id, new_path FROM tree
+) t ON t.category_id = c.category_id
+SET c.`path` = t.new_path;
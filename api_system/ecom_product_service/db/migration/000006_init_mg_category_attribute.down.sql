@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_attribute_value;
+DROP TABLE IF EXISTS category_attribute;
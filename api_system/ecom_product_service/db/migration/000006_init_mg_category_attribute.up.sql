@@ -0,0 +1,34 @@
+-- =================================================================
+-- Thuộc tính theo danh mục
+-- Mỗi danh mục định nghĩa các thuộc tính có kiểu (ENUM, NUMBER, BOOLEAN),
+-- danh mục con kế thừa thuộc tính của các danh mục cha theo `path`.
+-- =================================================================
+CREATE TABLE category_attribute (
+    id VARCHAR(36) PRIMARY KEY,
+    category_id VARCHAR(36) NOT NULL,
+    code VARCHAR(64) NOT NULL, -- dùng trong bộ lọc: attr.<code>=...
+    name NVARCHAR(128) NOT NULL,
+    data_type ENUM('ENUM', 'NUMBER', 'BOOLEAN') NOT NULL,
+    unit VARCHAR(32), -- đơn vị cho kiểu NUMBER (inch, GB, kg...)
+    enum_values TEXT CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci, -- JSON array các giá trị cho kiểu ENUM
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    is_filterable BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    create_date DATETIME DEFAULT NOW(),
+    UNIQUE KEY uq_category_attribute_code (category_id, code),
+    FOREIGN KEY (category_id) REFERENCES category(category_id) ON DELETE CASCADE
+);
+
+-- Giá trị thuộc tính của sản phẩm
+CREATE TABLE product_attribute_value (
+    product_id VARCHAR(36) NOT NULL,
+    attribute_id VARCHAR(36) NOT NULL,
+    value VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL, -- giá trị đã chuẩn hóa
+    value_number DOUBLE, -- chỉ có với kiểu NUMBER, dùng cho lọc theo khoảng
+    PRIMARY KEY (product_id, attribute_id),
+    FOREIGN KEY (product_id) REFERENCES product(id) ON DELETE CASCADE,
+    FOREIGN KEY (attribute_id) REFERENCES category_attribute(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_attribute_value ON product_attribute_value(attribute_id, value);
+CREATE INDEX idx_product_attribute_number ON product_attribute_value(attribute_id, value_number);
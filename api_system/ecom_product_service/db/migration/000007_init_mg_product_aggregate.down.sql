@@ -0,0 +1,6 @@
+DROP INDEX idx_product_status_price ON product;
+ALTER TABLE product
+DROP COLUMN in_stock,
+DROP COLUMN total_stock,
+DROP COLUMN max_price_sku_id,
+DROP COLUMN min_price_sku_id;
@@ -0,0 +1,13 @@
+-- =================================================================
+-- Tổng hợp giá / tồn kho của sản phẩm
+-- Trigger cập nhật min_price/max_price ở 000002 đã bị comment nên các giá trị
+-- này được tính lại trong service, cùng transaction với mọi thay đổi SKU.
+-- Dữ liệu cũ được sửa bằng API admin POST /product/admin/reindex_aggregates.
+-- =================================================================
+ALTER TABLE product
+ADD COLUMN min_price_sku_id VARCHAR(36) NULL,
+ADD COLUMN max_price_sku_id VARCHAR(36) NULL,
+ADD COLUMN total_stock INT NOT NULL DEFAULT 0,
+ADD COLUMN in_stock BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_product_status_price ON product(delete_status, min_price);
@@ -0,0 +1,19 @@
+-- =================================================================
+-- Tài liệu tìm kiếm sản phẩm
+-- Mỗi sản phẩm có một tài liệu đã bỏ dấu, tách theo trường để đánh trọng số
+-- (tên > thương hiệu > danh mục > mô tả). Service nạp bảng này vào chỉ mục
+-- ngược trong bộ nhớ khi khởi động và đồng bộ định kỳ theo update_date.
+-- =================================================================
+CREATE TABLE product_search_document (
+    product_id VARCHAR(36) PRIMARY KEY,
+    name_folded VARCHAR(500) NOT NULL,
+    brand_folded VARCHAR(255) NOT NULL DEFAULT '',
+    category_folded VARCHAR(500) NOT NULL DEFAULT '',
+    description_folded TEXT NOT NULL,
+    -- chuỗi đầy đủ từ BuildProductSearchString (đã bỏ dấu)
+    document TEXT NOT NULL,
+    update_date DATETIME DEFAULT NOW() ON UPDATE NOW(),
+    FOREIGN KEY (product_id) REFERENCES product(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_search_document_update ON product_search_document(update_date);
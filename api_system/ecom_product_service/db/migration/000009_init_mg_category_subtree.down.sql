@@ -0,0 +1 @@
+DROP INDEX idx_product_status_category ON product;
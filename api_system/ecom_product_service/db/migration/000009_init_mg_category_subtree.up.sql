@@ -0,0 +1,8 @@
+-- =================================================================
+-- Lọc sản phẩm theo cả cây danh mục
+-- Danh mục con được lấy theo tiền tố path (dùng idx_category_path),
+-- sản phẩm lọc bằng category_id IN (...) qua idx_product_category_optimized.
+-- Index dưới đây phục vụ đếm số sản phẩm đang bán theo từng danh mục
+-- (GROUP BY category_id) chỉ bằng cách quét index.
+-- =================================================================
+CREATE INDEX idx_product_status_category ON product(delete_status, category_id);
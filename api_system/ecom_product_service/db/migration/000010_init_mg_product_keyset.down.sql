@@ -0,0 +1,2 @@
+DROP INDEX idx_product_status_create ON product;
+DROP INDEX idx_product_status_sold ON product;
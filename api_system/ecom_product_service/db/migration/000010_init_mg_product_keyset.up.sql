@@ -0,0 +1,9 @@
+-- =================================================================
+-- Phân trang sản phẩm theo cursor (keyset)
+-- Truy vấn sắp xếp theo (cột sort, id); index phụ của InnoDB đã kèm khóa chính
+-- nên các index dưới đây phục vụ được cả điều kiện "sau bản ghi mốc".
+-- price_asc/price_desc dùng idx_product_status_price (000007).
+-- name là TEXT nên không đánh index, sort theo tên vẫn phải sắp xếp trên tập đã lọc.
+-- =================================================================
+CREATE INDEX idx_product_status_sold ON product(delete_status, total_sold);
+CREATE INDEX idx_product_status_create ON product(delete_status, create_date);
@@ -0,0 +1,25 @@
+-- =================================================================
+-- Lịch sử giá SKU
+-- Mỗi lần giá của SKU thay đổi (kể cả giá ban đầu khi tạo SKU) ghi một dòng,
+-- dùng để hiển thị giá thấp nhất 30 ngày và phát hiện tăng giá trước khuyến mãi.
+-- =================================================================
+CREATE TABLE sku_price_history (
+    id VARCHAR(36) PRIMARY KEY,
+    sku_id VARCHAR(36) NOT NULL,
+    product_id VARCHAR(36) NOT NULL,
+    old_price DOUBLE, -- NULL: giá ban đầu khi tạo SKU
+    new_price DOUBLE NOT NULL,
+    actor VARCHAR(128) NOT NULL, -- Người đổi giá (seller hoặc admin)
+    create_date DATETIME NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (sku_id) REFERENCES product_sku(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sku_price_history_sku ON sku_price_history(sku_id, create_date);
+CREATE INDEX idx_sku_price_history_product ON sku_price_history(product_id, create_date);
+-- báo cáo tăng giá quét theo khoảng thời gian
+CREATE INDEX idx_sku_price_history_date ON sku_price_history(create_date);
+
+-- giá hiện tại của các SKU đã có được ghi làm mốc ban đầu
+INSERT INTO sku_price_history (id, sku_id, product_id, old_price, new_price, actor, create_date)
+SELECT UUID(), id, product_id, NULL, price, 'system', COALESCE(update_date, create_date, NOW())
+FROM product_sku;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_status_history;
+
+UPDATE product SET delete_status = 'Pending' WHERE delete_status = 'Unlisted';
+ALTER TABLE product
+MODIFY COLUMN delete_status ENUM('Pending','Active', 'Deleted', 'Rejected') DEFAULT 'Active';
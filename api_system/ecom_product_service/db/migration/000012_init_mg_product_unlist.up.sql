@@ -0,0 +1,19 @@
+-- =================================================================
+-- Người bán tự ẩn / hiện lại / xóa mềm / khôi phục sản phẩm
+-- Unlisted: sản phẩm bị ẩn khỏi trang bán nhưng người bán vẫn chỉnh sửa được.
+-- Lịch sử đổi trạng thái dùng để khôi phục sản phẩm về trạng thái trước khi xóa.
+-- =================================================================
+ALTER TABLE product
+MODIFY COLUMN delete_status ENUM('Pending','Active', 'Deleted', 'Rejected', 'Unlisted') DEFAULT 'Active';
+
+CREATE TABLE product_status_history (
+    id VARCHAR(36) PRIMARY KEY,
+    product_id VARCHAR(36) NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    actor VARCHAR(128) NOT NULL, -- Người thực hiện (seller hoặc admin)
+    create_date DATETIME NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (product_id) REFERENCES product(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_product_status_history_product ON product_status_history(product_id, to_status, create_date);
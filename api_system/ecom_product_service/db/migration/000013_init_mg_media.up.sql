@@ -0,0 +1,18 @@
+-- =================================================================
+-- Quản lý file media đã upload
+-- id là tên file lưu trên đĩa (giá trị được lưu vào product.image, product.media, ...),
+-- các bản thu nhỏ (thumb/medium/large) được sinh cùng thư mục theo quy ước tên.
+-- File cũ upload trước khi có bảng này không được ghi nhận nên job dọn file sẽ không đụng tới.
+-- =================================================================
+CREATE TABLE media (
+    id VARCHAR(255) PRIMARY KEY,
+    owner VARCHAR(128) NOT NULL, -- Người upload
+    mime_type VARCHAR(32) NOT NULL,
+    size BIGINT NOT NULL, -- Dung lượng file gốc (bytes)
+    width INT NOT NULL,
+    height INT NOT NULL,
+    create_date DATETIME NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_owner ON media(owner);
+CREATE INDEX idx_media_create_date ON media(create_date);
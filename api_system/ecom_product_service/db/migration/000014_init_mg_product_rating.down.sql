@@ -0,0 +1,6 @@
+DROP INDEX idx_product_rating_score ON product;
+
+ALTER TABLE product
+DROP COLUMN rating_average,
+DROP COLUMN rating_count,
+DROP COLUMN rating_score;
@@ -0,0 +1,11 @@
+-- =================================================================
+-- Điểm đánh giá của sản phẩm, đồng bộ từ bảng product_rating_summary của order service
+-- mỗi khi đánh giá được tạo, sửa hoặc ẩn/hiện (POST /v1/product/sync_rating).
+-- rating_score là điểm Bayesian (đã hiệu chỉnh theo số lượng đánh giá), dùng cho sort=rating.
+-- =================================================================
+ALTER TABLE product
+ADD COLUMN rating_average DOUBLE NOT NULL DEFAULT 0,
+ADD COLUMN rating_count INT NOT NULL DEFAULT 0,
+ADD COLUMN rating_score DOUBLE NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_product_rating_score ON product(delete_status, rating_score);
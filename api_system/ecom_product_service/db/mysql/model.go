@@ -2,6 +2,8 @@ package db
 
 import (
 	"database/sql"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
 )
 
 type ProductSkusDetailss struct {
@@ -18,3 +20,46 @@ type ProductSkusDetailss struct {
 	Image            string        `json:"image"`
 	InfoProduct      string        `json:"info_sku_attr"`
 }
+
+// ProductAttributeFilter điều kiện lọc sản phẩm theo thuộc tính danh mục
+type ProductAttributeFilter struct {
+	AttributeID string
+	Values      []string        // so khớp chính xác (ENUM, BOOLEAN, NUMBER), nhiều giá trị là OR
+	Min         sql.NullFloat64 // lọc theo khoảng cho NUMBER
+	Max         sql.NullFloat64
+}
+
+// ProductDynamicParams tham số cho truy vấn sản phẩm động, mở rộng từ ListProductsAdvancedParams của sqlc
+type ProductDynamicParams struct {
+	db.ListProductsAdvancedParams
+	Attributes []ProductAttributeFilter
+	// ProductIDs giới hạn kết quả trong các sản phẩm khớp từ khóa (thứ tự theo độ liên quan giảm dần).
+	// nil: không giới hạn
+	ProductIDs []string
+	// CategoryIDs lọc theo cả cây danh mục (danh mục được chọn và các danh mục con), ưu tiên hơn CategoryID
+	CategoryIDs []string
+	// Keyset khác nil: phân trang theo cursor thay cho OFFSET (chỉ áp dụng cho ListProductsDynamic)
+	Keyset *ProductKeyset
+}
+
+// ProductKeyset bản ghi mốc khi phân trang theo cursor, ID rỗng là trang đầu.
+// Value là giá trị cột sắp xếp của bản ghi mốc (time.Time, int64, float64 hoặc string tùy sort),
+// nil khi cột đó của bản ghi mốc là NULL (create_date, min_price).
+type ProductKeyset struct {
+	Value    interface{}
+	ID       string
+	Backward bool
+}
+
+type ProductAttributeFacetRow struct {
+	AttributeID string `json:"attribute_id"`
+	Value       string `json:"value"`
+	Total       int64  `json:"total"`
+}
+
+type ProductBrandFacetRow struct {
+	BrandID string `json:"brand_id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Total   int64  `json:"total"`
+}
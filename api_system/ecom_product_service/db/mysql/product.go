@@ -3,13 +3,32 @@ package db
 import (
 	"context"
 	"database/sql"
+	"sort"
 	"strings"
 
 	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
 )
 
+// số id tối đa trong một mệnh đề IN, tập ứng viên lớn từ chỉ mục tìm kiếm được chia thành nhiều lô
+const productIDChunkSize = 1000
+
+// chunkProductIDs tách params thành từng lô ProductIDs để đếm/lọc rồi cộng kết quả,
+// các lô không giao nhau nên số đếm cộng dồn được
+func chunkProductIDs(params ProductDynamicParams) []ProductDynamicParams {
+	if len(params.ProductIDs) <= productIDChunkSize {
+		return []ProductDynamicParams{params}
+	}
+	var chunks []ProductDynamicParams
+	for start := 0; start < len(params.ProductIDs); start += productIDChunkSize {
+		chunk := params
+		chunk.ProductIDs = params.ProductIDs[start:min(start+productIDChunkSize, len(params.ProductIDs))]
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
 // Hàm hỗ trợ build WHERE clause chung cho cả List và Count
-func buildWhereClause(params db.ListProductsAdvancedParams) (string, []interface{}) {
+func buildWhereClause(params ProductDynamicParams) (string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 
@@ -24,7 +43,12 @@ func buildWhereClause(params db.ListProductsAdvancedParams) (string, []interface
 		conditions = append(conditions, "p.shop_id = ?")
 		args = append(args, params.ShopID.String)
 	}
-	if params.CategoryID.Valid {
+	if len(params.CategoryIDs) > 0 {
+		conditions = append(conditions, "p.category_id IN ("+strings.TrimSuffix(strings.Repeat("?,", len(params.CategoryIDs)), ",")+")")
+		for _, id := range params.CategoryIDs {
+			args = append(args, id)
+		}
+	} else if params.CategoryID.Valid {
 		conditions = append(conditions, "p.category_id = ?")
 		args = append(args, params.CategoryID.String)
 	}
@@ -48,6 +72,39 @@ func buildWhereClause(params db.ListProductsAdvancedParams) (string, []interface
 		args = append(args, "%"+kw.String+"%")
 	}
 
+	// Lọc theo danh sách sản phẩm từ chỉ mục tìm kiếm
+	if params.ProductIDs != nil {
+		if len(params.ProductIDs) == 0 {
+			conditions = append(conditions, "1 = 0")
+		} else {
+			conditions = append(conditions, "p.id IN ("+strings.TrimSuffix(strings.Repeat("?,", len(params.ProductIDs)), ",")+")")
+			for _, id := range params.ProductIDs {
+				args = append(args, id)
+			}
+		}
+	}
+
+	// Lọc theo thuộc tính danh mục, mỗi thuộc tính là một điều kiện EXISTS
+	for _, attr := range params.Attributes {
+		cond := "EXISTS (SELECT 1 FROM product_attribute_value pav WHERE pav.product_id = p.id AND pav.attribute_id = ?"
+		args = append(args, attr.AttributeID)
+		if len(attr.Values) > 0 {
+			cond += " AND pav.value IN (" + strings.TrimSuffix(strings.Repeat("?,", len(attr.Values)), ",") + ")"
+			for _, v := range attr.Values {
+				args = append(args, v)
+			}
+		}
+		if attr.Min.Valid {
+			cond += " AND pav.value_number >= ?"
+			args = append(args, attr.Min.Float64)
+		}
+		if attr.Max.Valid {
+			cond += " AND pav.value_number <= ?"
+			args = append(args, attr.Max.Float64)
+		}
+		conditions = append(conditions, cond+")")
+	}
+
 	if len(conditions) == 0 {
 		return "", args
 	}
@@ -57,7 +114,7 @@ func buildWhereClause(params db.ListProductsAdvancedParams) (string, []interface
 // ============================================================
 // 1. HÀM LIST PRODUCTS (Dynamic)
 // ============================================================
-func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProductsAdvancedParams) ([]db.ListProductsAdvancedRow, error) {
+func (q *SQLStore) ListProductsDynamic(ctx context.Context, params ProductDynamicParams) ([]db.ListProductsAdvancedRow, error) {
 	// A. Build SELECT
 	baseQuery := `
 		SELECT 
@@ -66,8 +123,10 @@ func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProduc
 			p.delete_status, p.product_is_permission_return, p.product_is_permission_check,
 			p.create_date, p.update_date, p.create_by, p.update_by,
 			p.total_sold, p.min_price, p.max_price,
-			(SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price ASC LIMIT 1) AS min_price_sku_id,
-			(SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price DESC LIMIT 1) AS max_price_sku_id
+			COALESCE(p.min_price_sku_id, '') AS min_price_sku_id,
+			COALESCE(p.max_price_sku_id, '') AS max_price_sku_id,
+			p.total_stock, p.in_stock,
+			p.rating_average, p.rating_count, p.rating_score
 		FROM product p
 	`
 
@@ -76,6 +135,7 @@ func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProduc
 
 	// C. Build ORDER BY (Whitelist để tránh SQL Injection)
 	orderBy := "ORDER BY p.create_date DESC" // Mặc định
+	var orderArgs []interface{}
 	if sortStr, ok := params.Sort.(sql.NullString); ok && sortStr.Valid {
 		switch sortStr.String {
 		case "best_sell":
@@ -88,8 +148,40 @@ func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProduc
 			orderBy = "ORDER BY p.name ASC"
 		case "name_desc":
 			orderBy = "ORDER BY p.name DESC"
+		case "rating":
+			// điểm Bayesian đồng bộ từ order service, sản phẩm ít đánh giá không bị đẩy lên đầu
+			orderBy = "ORDER BY p.rating_score DESC"
+		case "relevance":
+			// giữ thứ tự điểm liên quan của chỉ mục tìm kiếm
+			if len(params.ProductIDs) > 0 {
+				orderBy = "ORDER BY FIELD(p.id, " + strings.TrimSuffix(strings.Repeat("?,", len(params.ProductIDs)), ",") + ")"
+				for _, id := range params.ProductIDs {
+					orderArgs = append(orderArgs, id)
+				}
+			}
+		}
+	}
+
+	// C2. Phân trang theo cursor: thay ORDER BY bằng (cột sort, p.id) và lọc các bản ghi sau bản ghi mốc
+	if params.Keyset != nil {
+		sortName := ""
+		if sortStr, ok := params.Sort.(sql.NullString); ok && sortStr.Valid {
+			sortName = sortStr.String
+		}
+		cond, condArgs, keysetOrder := productKeysetClause(sortName, params.Keyset)
+		if cond != "" {
+			if whereClause == "" {
+				whereClause = "WHERE " + cond
+			} else {
+				whereClause += " AND " + cond
+			}
+			args = append(args, condArgs...)
 		}
+		orderBy = keysetOrder
+		orderArgs = nil
+		params.Offset = 0
 	}
+	args = append(args, orderArgs...)
 
 	// D. Build LIMIT/OFFSET
 	limitOffset := " LIMIT ? OFFSET ?"
@@ -116,6 +208,8 @@ func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProduc
 			&i.CreateDate, &i.UpdateDate, &i.CreateBy, &i.UpdateBy,
 			&i.TotalSold, &i.MinPrice, &i.MaxPrice,
 			&i.MinPriceSkuID, &i.MaxPriceSkuID,
+			&i.TotalStock, &i.InStock,
+			&i.RatingAverage, &i.RatingCount, &i.RatingScore,
 		); err != nil {
 			return nil, err
 		}
@@ -127,10 +221,77 @@ func (q *SQLStore) ListProductsDynamic(ctx context.Context, params db.ListProduc
 	return items, nil
 }
 
+// productKeysetColumns cột sắp xếp khi phân trang cursor theo từng kiểu sort, desc là giảm dần,
+// nullable là cột có thể NULL. Sort rỗng là mặc định (create_date mới nhất trước).
+var productKeysetColumns = map[string]struct {
+	column   string
+	desc     bool
+	nullable bool
+}{
+	"":            {"p.create_date", true, true},
+	"create_date": {"p.create_date", true, true},
+	"best_sell":   {"p.total_sold", true, false},
+	"price_asc":   {"p.min_price", false, true},
+	"price_desc":  {"p.min_price", true, true},
+	"name_asc":    {"p.name", false, false},
+	"name_desc":   {"p.name", true, false},
+	"rating":      {"p.rating_score", true, false},
+}
+
+// ProductKeysetSortable kiểm tra sort có hỗ trợ phân trang cursor (relevance thì không)
+func ProductKeysetSortable(sort string) bool {
+	_, ok := productKeysetColumns[sort]
+	return ok
+}
+
+// productKeysetClause trả về điều kiện "sau bản ghi mốc" và ORDER BY tương ứng.
+// Đi lùi thì đảo chiều so sánh lẫn sắp xếp, service sẽ đảo lại kết quả.
+// MySQL xếp NULL nhỏ nhất (đầu khi tăng dần, cuối khi giảm dần) nên điều kiện phải tính riêng các bản ghi NULL,
+// nếu không "col < ?" sẽ bỏ mất chúng.
+func productKeysetClause(sort string, keyset *ProductKeyset) (string, []interface{}, string) {
+	col, ok := productKeysetColumns[sort]
+	if !ok {
+		col = productKeysetColumns[""]
+	}
+	desc := col.desc != keyset.Backward
+	op, dir := ">", "ASC"
+	if desc {
+		op, dir = "<", "DESC"
+	}
+	orderBy := "ORDER BY " + col.column + " " + dir + ", p.id " + dir
+	if keyset.ID == "" {
+		return "", nil, orderBy
+	}
+	if keyset.Value == nil {
+		// mốc NULL: giảm dần thì phía sau chỉ còn các NULL, tăng dần thì mọi bản ghi có giá trị đều ở phía sau
+		if desc {
+			return "(" + col.column + " IS NULL AND p.id < ?)", []interface{}{keyset.ID}, orderBy
+		}
+		return "(" + col.column + " IS NOT NULL OR p.id > ?)", []interface{}{keyset.ID}, orderBy
+	}
+	cond := col.column + " " + op + " ? OR (" + col.column + " = ? AND p.id " + op + " ?)"
+	if desc && col.nullable {
+		cond += " OR " + col.column + " IS NULL"
+	}
+	return "(" + cond + ")", []interface{}{keyset.Value, keyset.Value, keyset.ID}, orderBy
+}
+
 // ============================================================
 // 2. HÀM COUNT PRODUCTS (Dynamic)
 // ============================================================
-func (q *SQLStore) CountProductsDynamic(ctx context.Context, params db.ListProductsAdvancedParams) (int64, error) {
+func (q *SQLStore) CountProductsDynamic(ctx context.Context, params ProductDynamicParams) (int64, error) {
+	var total int64
+	for _, chunk := range chunkProductIDs(params) {
+		count, err := q.countProductsDynamic(ctx, chunk)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+func (q *SQLStore) countProductsDynamic(ctx context.Context, params ProductDynamicParams) (int64, error) {
 	// A. Build SELECT
 	baseQuery := "SELECT COUNT(*) FROM product p"
 
@@ -148,3 +309,194 @@ func (q *SQLStore) CountProductsDynamic(ctx context.Context, params db.ListProdu
 	}
 	return count, nil
 }
+
+// FilterProductIDsDynamic trả về các id trong params.ProductIDs thỏa các điều kiện lọc còn lại,
+// giữ nguyên thứ tự của ProductIDs (thứ tự liên quan của chỉ mục tìm kiếm). ProductIDs nil thì trả về nil
+func (q *SQLStore) FilterProductIDsDynamic(ctx context.Context, params ProductDynamicParams) ([]string, error) {
+	if params.ProductIDs == nil {
+		return nil, nil
+	}
+	matched := make(map[string]bool)
+	for _, chunk := range chunkProductIDs(params) {
+		whereClause, args := buildWhereClause(chunk)
+		rows, err := q.connPool.QueryContext(ctx, "SELECT p.id FROM product p "+whereClause, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			matched[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	ids := make([]string, 0, len(matched))
+	for _, id := range params.ProductIDs {
+		if matched[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// ============================================================
+// 3. HÀM ĐẾM FACET THEO THUỘC TÍNH (Dynamic)
+// ============================================================
+// Đếm số sản phẩm theo từng giá trị thuộc tính trên cùng tập kết quả với ListProductsDynamic
+func (q *SQLStore) CountProductAttributeFacets(ctx context.Context, params ProductDynamicParams, attributeIDs []string) ([]ProductAttributeFacetRow, error) {
+	if len(attributeIDs) == 0 {
+		return nil, nil
+	}
+	chunks := chunkProductIDs(params)
+	if len(chunks) == 1 {
+		return q.countProductAttributeFacets(ctx, params, attributeIDs)
+	}
+	type facetKey struct{ attributeID, value string }
+	totals := make(map[facetKey]int64)
+	for _, chunk := range chunks {
+		rows, err := q.countProductAttributeFacets(ctx, chunk, attributeIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			totals[facetKey{row.AttributeID, row.Value}] += row.Total
+		}
+	}
+	items := make([]ProductAttributeFacetRow, 0, len(totals))
+	for key, total := range totals {
+		items = append(items, ProductAttributeFacetRow{AttributeID: key.attributeID, Value: key.value, Total: total})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].AttributeID != items[j].AttributeID {
+			return items[i].AttributeID < items[j].AttributeID
+		}
+		if items[i].Total != items[j].Total {
+			return items[i].Total > items[j].Total
+		}
+		return items[i].Value < items[j].Value
+	})
+	return items, nil
+}
+
+func (q *SQLStore) countProductAttributeFacets(ctx context.Context, params ProductDynamicParams, attributeIDs []string) ([]ProductAttributeFacetRow, error) {
+	// A. Build SELECT
+	baseQuery := `
+		SELECT f.attribute_id, f.value, COUNT(*) AS total
+		FROM product_attribute_value f
+		JOIN product p ON p.id = f.product_id
+	`
+
+	// B. Build WHERE (dùng chung điều kiện lọc sản phẩm)
+	whereClause, args := buildWhereClause(params)
+	inClause := "f.attribute_id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(attributeIDs)), ",") + ")"
+	if whereClause == "" {
+		whereClause = "WHERE " + inClause
+	} else {
+		whereClause += " AND " + inClause
+	}
+	for _, id := range attributeIDs {
+		args = append(args, id)
+	}
+
+	// C. Final Query
+	finalQuery := baseQuery + " " + whereClause + " GROUP BY f.attribute_id, f.value ORDER BY f.attribute_id, total DESC"
+
+	// D. Execute
+	rows, err := q.connPool.QueryContext(ctx, finalQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ProductAttributeFacetRow
+	for rows.Next() {
+		var i ProductAttributeFacetRow
+		if err := rows.Scan(&i.AttributeID, &i.Value, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ============================================================
+// 4. HÀM ĐẾM FACET THEO THƯƠNG HIỆU (Dynamic)
+// ============================================================
+// Đếm số sản phẩm theo thương hiệu, service bỏ điều kiện BrandID trước khi gọi để vẫn chọn được thương hiệu khác
+func (q *SQLStore) CountProductBrandFacets(ctx context.Context, params ProductDynamicParams) ([]ProductBrandFacetRow, error) {
+	chunks := chunkProductIDs(params)
+	if len(chunks) == 1 {
+		return q.countProductBrandFacets(ctx, params)
+	}
+	totals := make(map[string]*ProductBrandFacetRow)
+	for _, chunk := range chunks {
+		rows, err := q.countProductBrandFacets(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if total, ok := totals[row.BrandID]; ok {
+				total.Total += row.Total
+				continue
+			}
+			row := row
+			totals[row.BrandID] = &row
+		}
+	}
+	items := make([]ProductBrandFacetRow, 0, len(totals))
+	for _, total := range totals {
+		items = append(items, *total)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Total != items[j].Total {
+			return items[i].Total > items[j].Total
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+func (q *SQLStore) countProductBrandFacets(ctx context.Context, params ProductDynamicParams) ([]ProductBrandFacetRow, error) {
+	// A. Build SELECT
+	baseQuery := `
+		SELECT b.brand_id, b.code, b.name, COUNT(*) AS total
+		FROM product p
+		JOIN brand b ON b.brand_id = p.brand_id
+	`
+
+	// B. Build WHERE (dùng chung điều kiện lọc sản phẩm)
+	whereClause, args := buildWhereClause(params)
+
+	// C. Final Query
+	finalQuery := baseQuery + " " + whereClause + " GROUP BY b.brand_id, b.code, b.name ORDER BY total DESC, b.name"
+
+	// D. Execute
+	rows, err := q.connPool.QueryContext(ctx, finalQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ProductBrandFacetRow
+	for rows.Next() {
+		var i ProductBrandFacetRow
+		if err := rows.Scan(&i.BrandID, &i.Code, &i.Name, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductKeysetClauseFirstPage(t *testing.T) {
+	cond, args, orderBy := productKeysetClause("price_asc", &ProductKeyset{})
+	require.Empty(t, cond)
+	require.Nil(t, args)
+	require.Equal(t, "ORDER BY p.min_price ASC, p.id ASC", orderBy)
+}
+
+func TestProductKeysetClauseForward(t *testing.T) {
+	cond, args, orderBy := productKeysetClause("best_sell", &ProductKeyset{Value: int64(10), ID: "p-5"})
+	require.Equal(t, "(p.total_sold < ? OR (p.total_sold = ? AND p.id < ?))", cond)
+	require.Equal(t, []interface{}{int64(10), int64(10), "p-5"}, args)
+	require.Equal(t, "ORDER BY p.total_sold DESC, p.id DESC", orderBy)
+
+	// giảm dần thì sản phẩm chưa có giá (NULL) đứng cuối nên vẫn thuộc các trang sau
+	cond, _, _ = productKeysetClause("price_desc", &ProductKeyset{Value: 99000.0, ID: "p-5"})
+	require.Equal(t, "(p.min_price < ? OR (p.min_price = ? AND p.id < ?) OR p.min_price IS NULL)", cond)
+
+	// tăng dần thì NULL đứng đầu, đã đi qua hết trước bản ghi mốc có giá
+	cond, _, _ = productKeysetClause("price_asc", &ProductKeyset{Value: 99000.0, ID: "p-5"})
+	require.Equal(t, "(p.min_price > ? OR (p.min_price = ? AND p.id > ?))", cond)
+}
+
+func TestProductKeysetClauseBackward(t *testing.T) {
+	cond, _, orderBy := productKeysetClause("price_desc", &ProductKeyset{Value: 99000.0, ID: "p-5", Backward: true})
+	require.Equal(t, "(p.min_price > ? OR (p.min_price = ? AND p.id > ?))", cond)
+	require.Equal(t, "ORDER BY p.min_price ASC, p.id ASC", orderBy)
+
+	cond, _, orderBy = productKeysetClause("price_asc", &ProductKeyset{Value: 99000.0, ID: "p-5", Backward: true})
+	require.Equal(t, "(p.min_price < ? OR (p.min_price = ? AND p.id < ?) OR p.min_price IS NULL)", cond)
+	require.Equal(t, "ORDER BY p.min_price DESC, p.id DESC", orderBy)
+}
+
+func TestProductKeysetClauseNullAnchor(t *testing.T) {
+	// mốc chưa có giá: giảm dần chỉ còn các NULL id nhỏ hơn phía sau
+	cond, args, _ := productKeysetClause("price_desc", &ProductKeyset{ID: "p-5"})
+	require.Equal(t, "(p.min_price IS NULL AND p.id < ?)", cond)
+	require.Equal(t, []interface{}{"p-5"}, args)
+
+	// tăng dần thì mọi sản phẩm có giá và các NULL id lớn hơn đều ở phía sau
+	cond, _, _ = productKeysetClause("price_asc", &ProductKeyset{ID: "p-5"})
+	require.Equal(t, "(p.min_price IS NOT NULL OR p.id > ?)", cond)
+
+	cond, _, orderBy := productKeysetClause("", &ProductKeyset{ID: "p-5", Backward: true})
+	require.Equal(t, "(p.create_date IS NOT NULL OR p.id > ?)", cond)
+	require.Equal(t, "ORDER BY p.create_date ASC, p.id ASC", orderBy)
+}
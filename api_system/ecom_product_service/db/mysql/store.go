@@ -17,8 +17,11 @@ type SQLStore struct {
 type Store interface {
 	db.Querier
 	ExecTS(ctx context.Context, fn func(tx db.Querier) error) error
-	ListProductsDynamic(ctx context.Context, params db.ListProductsAdvancedParams) ([]db.ListProductsAdvancedRow, error)
-	CountProductsDynamic(ctx context.Context, params db.ListProductsAdvancedParams) (int64, error)
+	ListProductsDynamic(ctx context.Context, params ProductDynamicParams) ([]db.ListProductsAdvancedRow, error)
+	CountProductsDynamic(ctx context.Context, params ProductDynamicParams) (int64, error)
+	FilterProductIDsDynamic(ctx context.Context, params ProductDynamicParams) ([]string, error)
+	CountProductAttributeFacets(ctx context.Context, params ProductDynamicParams, attributeIDs []string) ([]ProductAttributeFacetRow, error)
+	CountProductBrandFacets(ctx context.Context, params ProductDynamicParams) ([]ProductBrandFacetRow, error)
 }
 
 // create new store
@@ -22,6 +22,11 @@ WHERE brand_id = ?;
 SELECT * FROM brand
 WHERE brand_id = ? LIMIT 1;
 
+-- name: GetBrandForUpdate :one
+SELECT * FROM brand
+WHERE brand_id = ? LIMIT 1
+FOR UPDATE;
+
 -- name: GetBrandByCode :one
 SELECT * FROM brand
 WHERE code = ? LIMIT 1;
@@ -37,7 +42,7 @@ LIMIT ? OFFSET ?;
 
 -- name: SearchBrandsByName :many
 SELECT * FROM brand
-WHERE name LIKE '%' || ? || '%'
+WHERE name LIKE CONCAT('%', sqlc.arg('keyword'), '%')
 ORDER BY name;
 
 -- name: CountBrands :one
@@ -47,3 +52,12 @@ SELECT COUNT(*) AS total FROM brand;
 UPDATE brand
 SET image = ?, update_date = NOW()
 WHERE brand_id = ?;
+
+-- name: CountProductsByBrand :one
+SELECT COUNT(*) AS total FROM product
+WHERE brand_id = sqlc.arg('brand_id');
+
+-- name: ReassignProductsBrand :exec
+UPDATE product
+SET brand_id = sqlc.narg('new_brand_id')
+WHERE brand_id = sqlc.arg('old_brand_id');
@@ -23,9 +23,14 @@ WHERE category_id = ?;
 SELECT * FROM category
 WHERE category_id = ? LIMIT 1;
 
+-- name: GetCategoryForUpdate :one
+SELECT * FROM category
+WHERE category_id = ? LIMIT 1
+FOR UPDATE;
+
 -- name: ListCategories :many
 SELECT * FROM category
-ORDER BY name;
+ORDER BY sort_order, name;
 
 -- name: ListCategoriesPaged :many
 SELECT * FROM category
@@ -60,3 +65,33 @@ WHERE category_id = ?;
 
 -- name: CountCategories :one
 SELECT COUNT(*) AS total FROM category;
+
+-- name: ListCategoryDescendants :many
+-- Danh mục con theo tiền tố path, thoát ký tự đại diện của LIKE (key danh mục có thể chứa '_')
+SELECT * FROM category
+WHERE `path` LIKE CONCAT(REPLACE(REPLACE(REPLACE(sqlc.arg('path'), '\\', '\\\\'), '%', '\\%'), '_', '\\_'), '/%');
+
+-- name: UpdateCategoryPath :exec
+UPDATE category
+SET `path` = sqlc.arg('path')
+WHERE category_id = sqlc.arg('category_id');
+
+-- name: UpdateCategorySortOrder :exec
+UPDATE category
+SET sort_order = sqlc.arg('sort_order')
+WHERE category_id = sqlc.arg('category_id');
+
+-- name: CountProductsByCategory :one
+SELECT COUNT(*) AS total FROM product
+WHERE category_id = sqlc.arg('category_id');
+
+-- name: CountActiveProductsPerCategory :many
+-- Số sản phẩm đang bán trực tiếp trong từng danh mục (chưa cộng danh mục con)
+SELECT category_id, COUNT(*) AS total FROM product
+WHERE delete_status = 'Active'
+GROUP BY category_id;
+
+-- name: ReassignProductsCategory :exec
+UPDATE product
+SET category_id = sqlc.arg('new_category_id')
+WHERE category_id = sqlc.arg('old_category_id');
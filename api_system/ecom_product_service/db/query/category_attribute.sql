@@ -0,0 +1,58 @@
+-- name: CreateCategoryAttribute :exec
+INSERT INTO category_attribute (
+  id, category_id, code, name, data_type, unit, enum_values, is_required, is_filterable, sort_order
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateCategoryAttribute :exec
+UPDATE category_attribute
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  unit = COALESCE(sqlc.narg('unit'), unit),
+  enum_values = COALESCE(sqlc.narg('enum_values'), enum_values),
+  is_required = COALESCE(sqlc.narg('is_required'), is_required),
+  is_filterable = COALESCE(sqlc.narg('is_filterable'), is_filterable),
+  sort_order = COALESCE(sqlc.narg('sort_order'), sort_order)
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteCategoryAttribute :exec
+DELETE FROM category_attribute
+WHERE id = ?;
+
+-- name: GetCategoryAttribute :one
+SELECT * FROM category_attribute
+WHERE id = ? LIMIT 1;
+
+-- name: ListCategoryAttributesByPath :many
+-- Thuộc tính của danh mục và toàn bộ danh mục cha (kế thừa theo path), danh mục cha đứng trước
+SELECT ca.* FROM category_attribute ca
+JOIN category c ON c.category_id = ca.category_id
+WHERE CONCAT(sqlc.arg('path'), '/') LIKE CONCAT(c.`path`, '/%')
+ORDER BY LENGTH(c.`path`), ca.sort_order;
+
+-- name: CountDescendantAttributesByCode :one
+-- Số thuộc tính cùng code ở các danh mục con (theo tiền tố path, thoát ký tự đại diện của LIKE)
+SELECT COUNT(*) AS total FROM category_attribute ca
+JOIN category c ON c.category_id = ca.category_id
+JOIN category p ON p.category_id = sqlc.arg('category_id')
+WHERE ca.code = sqlc.arg('code')
+  AND c.`path` LIKE CONCAT(REPLACE(REPLACE(REPLACE(p.`path`, '\\', '\\\\'), '%', '\\%'), '_', '\\_'), '/%');
+
+-- name: CreateProductAttributeValue :exec
+INSERT INTO product_attribute_value (
+  product_id, attribute_id, value, value_number
+) VALUES (
+  ?, ?, ?, ?
+);
+
+-- name: DeleteProductAttributeValues :exec
+DELETE FROM product_attribute_value
+WHERE product_id = ?;
+
+-- name: ListProductAttributeValues :many
+SELECT pav.attribute_id, ca.code, ca.name, ca.data_type, ca.unit, pav.value, pav.value_number
+FROM product_attribute_value pav
+JOIN category_attribute ca ON ca.id = pav.attribute_id
+WHERE pav.product_id = ?
+ORDER BY ca.sort_order;
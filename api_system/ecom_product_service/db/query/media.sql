@@ -0,0 +1,32 @@
+-- name: CreateMedia :exec
+INSERT INTO media (
+  id, owner, mime_type, size, width, height
+) VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('owner'),
+  sqlc.arg('mime_type'),
+  sqlc.arg('size'),
+  sqlc.arg('width'),
+  sqlc.arg('height')
+);
+
+-- name: GetMedia :one
+SELECT * FROM media WHERE id = sqlc.arg('id') LIMIT 1;
+
+-- name: DeleteMedia :exec
+DELETE FROM media WHERE id = sqlc.arg('id');
+
+-- name: ListMediaCreatedBefore :many
+-- Media tạo trước mốc thời gian, đọc theo từng lô (keyset theo id) cho job dọn file
+SELECT * FROM media
+WHERE create_date < sqlc.arg('created_before') AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT ?;
+
+-- name: CountMediaReferences :one
+-- Số nơi còn dùng file media: ảnh chính/ảnh phụ sản phẩm, ảnh option, ảnh danh mục và thương hiệu
+SELECT
+  (SELECT COUNT(*) FROM product p WHERE p.image = sqlc.arg('file_name') OR p.media LIKE CONCAT('%"', sqlc.arg('file_name'), '"%'))
+  + (SELECT COUNT(*) FROM option_value o WHERE o.image = sqlc.arg('file_name'))
+  + (SELECT COUNT(*) FROM category c WHERE c.image = sqlc.arg('file_name'))
+  + (SELECT COUNT(*) FROM brand b WHERE b.image = sqlc.arg('file_name')) AS total;
@@ -110,9 +110,14 @@ SELECT
     p.total_sold,
     p.min_price,
     p.max_price,
-    -- Subquery lấy SKU ID đại diện (giữ nguyên logic tối ưu)
-    (SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price ASC LIMIT 1) AS min_price_sku_id,
-    (SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price DESC LIMIT 1) AS max_price_sku_id
+    -- SKU đại diện và tồn kho được tính sẵn khi thay đổi SKU
+    COALESCE(p.min_price_sku_id, '') AS min_price_sku_id,
+    COALESCE(p.max_price_sku_id, '') AS max_price_sku_id,
+    p.total_stock,
+    p.in_stock,
+    p.rating_average,
+    p.rating_count,
+    p.rating_score
 FROM product p
 WHERE 
     (sqlc.narg('delete_status') IS NULL OR p.delete_status = sqlc.narg('delete_status'))
@@ -151,6 +156,40 @@ WHERE
 -- name: GetAllProductID :many
 SELECT id FROM product;
 
+-- name: ListProductIDsAfter :many
+-- Duyệt toàn bộ sản phẩm theo từng lô (keyset theo id), dùng cho job reindex
+SELECT id FROM product
+WHERE id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT ?;
+
+-- name: ListActiveProductsForSuggest :many
+-- Sản phẩm đang bán để dựng chỉ mục gợi ý, đọc theo từng lô (keyset theo id)
+SELECT id, name, `key`, brand_id, category_id, total_sold FROM product
+WHERE delete_status = 'Active' AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT ?;
+
+-- name: UpdateProductAggregates :exec
+UPDATE product
+SET
+  min_price = sqlc.arg('min_price'),
+  max_price = sqlc.arg('max_price'),
+  min_price_sku_id = sqlc.narg('min_price_sku_id'),
+  max_price_sku_id = sqlc.narg('max_price_sku_id'),
+  total_stock = sqlc.arg('total_stock'),
+  in_stock = sqlc.arg('in_stock')
+WHERE id = sqlc.arg('id');
+
+-- name: UpdateProductRating :exec
+-- Điểm đánh giá đồng bộ từ order service
+UPDATE product
+SET
+  rating_average = sqlc.arg('rating_average'),
+  rating_count = sqlc.arg('rating_count'),
+  rating_score = sqlc.arg('rating_score')
+WHERE id = sqlc.arg('id');
+
 -- name: GetProductIDs :many
 SELECT * FROM product 
 WHERE id IN (sqlc.slice(product_ids));
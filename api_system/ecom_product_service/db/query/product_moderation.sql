@@ -0,0 +1,16 @@
+-- name: CreateProductModeration :exec
+INSERT INTO product_moderation (
+  id, product_id, action, reason, changed_fields, actor
+) VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('product_id'),
+  sqlc.arg('action'),
+  sqlc.narg('reason'),
+  sqlc.narg('changed_fields'),
+  sqlc.arg('actor')
+);
+
+-- name: ListProductModerationByProduct :many
+SELECT * FROM product_moderation
+WHERE product_id = sqlc.arg('product_id')
+ORDER BY create_date DESC;
@@ -0,0 +1,45 @@
+-- PRODUCT SEARCH DOCUMENT (product_search_document)
+
+-- name: UpsertProductSearchDocument :exec
+INSERT INTO product_search_document (
+  product_id, name_folded, brand_folded, category_folded, description_folded, document
+) VALUES (
+  sqlc.arg('product_id'),
+  sqlc.arg('name_folded'),
+  sqlc.arg('brand_folded'),
+  sqlc.arg('category_folded'),
+  sqlc.arg('description_folded'),
+  sqlc.arg('document')
+)
+ON DUPLICATE KEY UPDATE
+  name_folded = VALUES(name_folded),
+  brand_folded = VALUES(brand_folded),
+  category_folded = VALUES(category_folded),
+  description_folded = VALUES(description_folded),
+  document = VALUES(document),
+  update_date = NOW();
+
+-- name: DeleteProductSearchDocument :exec
+DELETE FROM product_search_document WHERE product_id = sqlc.arg('product_id');
+
+-- name: ListProductIDsByBrand :many
+-- Sản phẩm cần dựng lại tài liệu tìm kiếm khi thương hiệu đổi tên/mã
+SELECT id FROM product
+WHERE brand_id = sqlc.arg('brand_id');
+
+-- name: ListProductIDsByCategories :many
+-- Sản phẩm cần dựng lại tài liệu tìm kiếm khi danh mục đổi tên hoặc đổi path
+SELECT id FROM product
+WHERE category_id IN (sqlc.slice(category_ids));
+
+-- name: ListProductSearchDocuments :many
+-- Đọc theo từng lô (keyset theo product_id) để nạp chỉ mục
+SELECT * FROM product_search_document
+WHERE product_id > sqlc.arg('after_id')
+ORDER BY product_id
+LIMIT ?;
+
+-- name: ListProductSearchDocumentsUpdatedSince :many
+SELECT * FROM product_search_document
+WHERE update_date >= sqlc.arg('update_date')
+ORDER BY update_date;
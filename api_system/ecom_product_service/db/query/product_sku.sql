@@ -30,6 +30,15 @@ DELETE FROM product_sku WHERE id = sqlc.arg('id');
 -- name: GetProductSKU :one
 SELECT * FROM product_sku WHERE id = sqlc.arg('id') LIMIT 1;
 
+-- name: ListSKUsForOrder :many
+-- SKU kèm thông tin sản phẩm cho tạo đơn hàng, lấy nhiều SKU trong một truy vấn
+SELECT
+  ps.id, ps.product_id, ps.sku_code, ps.sku_name, ps.price, ps.quantity, ps.quantity_reserver, ps.weight,
+  p.name AS product_name, p.image AS product_image, p.shop_id, p.category_id, p.delete_status AS product_status
+FROM product_sku ps
+JOIN product p ON p.id = ps.product_id
+WHERE ps.id IN (sqlc.slice(sku_ids));
+
 -- name: ListSKUsByProduct :many
 SELECT * FROM product_sku
 WHERE product_id = sqlc.arg('product_id')
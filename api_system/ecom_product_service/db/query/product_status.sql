@@ -0,0 +1,32 @@
+-- name: CreateProductStatusHistory :exec
+INSERT INTO product_status_history (
+  id, product_id, from_status, to_status, actor
+) VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('product_id'),
+  sqlc.arg('from_status'),
+  sqlc.arg('to_status'),
+  sqlc.arg('actor')
+);
+
+-- name: GetStatusBeforeDelete :one
+-- Trạng thái của sản phẩm ngay trước lần xóa mềm gần nhất
+SELECT from_status FROM product_status_history
+WHERE product_id = sqlc.arg('product_id') AND to_status = 'Deleted'
+ORDER BY create_date DESC
+LIMIT 1;
+
+-- name: GetProductStatusForUpdate :one
+-- Khóa dòng sản phẩm trong transaction để đổi trạng thái dựa trên trạng thái mới nhất
+SELECT delete_status FROM product
+WHERE id = sqlc.arg('id')
+FOR UPDATE;
+
+-- name: GetStatusBeforeModeration :one
+-- Trạng thái của sản phẩm ngay trước khi vào vòng kiểm duyệt gần nhất (chờ duyệt hoặc bị từ chối)
+SELECT from_status FROM product_status_history
+WHERE product_id = sqlc.arg('product_id')
+  AND to_status IN ('Pending', 'Rejected')
+  AND from_status NOT IN ('Pending', 'Rejected')
+ORDER BY create_date DESC
+LIMIT 1;
@@ -0,0 +1,36 @@
+-- name: CreateSellerShop :exec
+INSERT INTO seller_shop (
+  user_id, shop_id, create_by
+) VALUES (
+  sqlc.arg('user_id'),
+  sqlc.arg('shop_id'),
+  sqlc.narg('create_by')
+);
+
+-- name: DeleteSellerShop :exec
+DELETE FROM seller_shop
+WHERE user_id = sqlc.arg('user_id') AND shop_id = sqlc.arg('shop_id');
+
+-- name: ListShopIDsBySeller :many
+SELECT shop_id FROM seller_shop
+WHERE user_id = sqlc.arg('user_id');
+
+-- name: CreateProductOwnershipAudit :exec
+INSERT INTO product_ownership_audit (
+  id, user_id, user_name, shop_id, product_id, action
+) VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('user_id'),
+  sqlc.arg('user_name'),
+  sqlc.arg('shop_id'),
+  sqlc.narg('product_id'),
+  sqlc.arg('action')
+);
+
+-- name: ListProductOwnershipAudit :many
+SELECT * FROM product_ownership_audit
+ORDER BY create_date DESC
+LIMIT ? OFFSET ?;
+
+-- name: CountProductOwnershipAudit :one
+SELECT COUNT(*) FROM product_ownership_audit;
@@ -0,0 +1,40 @@
+-- name: CreateSKUPriceHistory :exec
+INSERT INTO sku_price_history (
+  id, sku_id, product_id, old_price, new_price, actor
+) VALUES (
+  sqlc.arg('id'),
+  sqlc.arg('sku_id'),
+  sqlc.arg('product_id'),
+  sqlc.narg('old_price'),
+  sqlc.arg('new_price'),
+  sqlc.arg('actor')
+);
+
+-- name: ListSKUPriceHistory :many
+SELECT * FROM sku_price_history
+WHERE sku_id = sqlc.arg('sku_id')
+ORDER BY create_date DESC
+LIMIT ?;
+
+-- name: ListSKULowestPricesSince :many
+-- Giá thấp nhất của từng SKU trong sản phẩm kể từ mốc thời gian (chưa tính giá hiện tại).
+-- old_price của lần đổi đầu tiên trong khoảng chính là giá đang áp dụng ở đầu khoảng.
+SELECT sku_id, CAST(MIN(LEAST(new_price, COALESCE(old_price, new_price))) AS DOUBLE) AS lowest_price
+FROM sku_price_history
+WHERE product_id = sqlc.arg('product_id') AND create_date >= sqlc.arg('since')
+GROUP BY sku_id;
+
+-- name: ListSKUPriceRaises :many
+-- Các lần tăng giá ít nhất min_increase_percent (%) trong khoảng [from_date, to_date), lọc theo shop nếu có
+SELECT
+  h.id, h.sku_id, h.product_id, p.name AS product_name, p.shop_id, ps.sku_code,
+  h.old_price, h.new_price, ps.price AS current_price, h.actor, h.create_date
+FROM sku_price_history h
+JOIN product p ON p.id = h.product_id
+JOIN product_sku ps ON ps.id = h.sku_id
+WHERE h.old_price IS NOT NULL AND h.new_price > h.old_price
+  AND h.create_date >= sqlc.arg('from_date') AND h.create_date < sqlc.arg('to_date')
+  AND (h.new_price - h.old_price) * 100 >= sqlc.arg('min_increase_percent') * h.old_price
+  AND (sqlc.narg('shop_id') IS NULL OR p.shop_id = sqlc.narg('shop_id'))
+ORDER BY h.create_date DESC
+LIMIT ?;
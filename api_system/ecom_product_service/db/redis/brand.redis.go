@@ -0,0 +1,69 @@
+package redis_db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	modelServices "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// AddBrands thay toàn bộ cache thương hiệu trong một transaction để không còn sót thương hiệu đã xóa
+func (s *RedisDB) AddBrands(ctx context.Context, brands []modelServices.Brand) error {
+	dataMap := make(map[string]string, len(brands))
+	for _, brand := range brands {
+		brandJson, err := json.Marshal(brand)
+		if err != nil {
+			return err
+		}
+		dataMap[brand.BrandID] = string(brandJson)
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, BrandDataKey)
+		if len(dataMap) > 0 {
+			pipe.HSet(ctx, BrandDataKey, dataMap)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save brand data: %w", err)
+	}
+	return nil
+}
+
+func (s *RedisDB) RemoveBrands(ctx context.Context) error {
+	return s.client.Del(ctx, BrandDataKey).Err()
+}
+
+func (s *RedisDB) GetBrands(ctx context.Context) ([]modelServices.Brand, error) {
+	dataMap, err := s.client.HGetAll(ctx, BrandDataKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand data: %w", err)
+	}
+	brands := make([]modelServices.Brand, 0, len(dataMap))
+	for _, jsonStr := range dataMap {
+		var brand modelServices.Brand
+		if err := json.Unmarshal([]byte(jsonStr), &brand); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal brand: %w", err)
+		}
+		brands = append(brands, brand)
+	}
+	return brands, nil
+}
+
+// GetBrand trả về nil nếu thương hiệu chưa có trong cache
+func (s *RedisDB) GetBrand(ctx context.Context, brandID string) (*modelServices.Brand, error) {
+	jsonStr, err := s.client.HGet(ctx, BrandDataKey, brandID).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get brand %s: %w", brandID, err)
+	}
+	var brand modelServices.Brand
+	if err := json.Unmarshal([]byte(jsonStr), &brand); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal brand: %w", err)
+	}
+	return &brand, nil
+}
@@ -4,7 +4,30 @@ const BLACK_LIST = "black_list"
 const (
 	CategoryDataKey     = "category:tree:data_map"
 	CategoryChildrenKey = "category:tree:children_map"
+	// số sản phẩm đang bán của mỗi danh mục (đã cộng dồn cây con), bị xóa cùng cây danh mục
+	CategoryProductCountKey = "category:tree:product_count"
+)
+const (
+	BrandDataKey = "brand:data_map"
 )
 const (
 	OrderOnline = "orderOnline:"
 )
+const (
+	// chỉ mục gợi ý được dựng theo phiên bản: suggest:<version>:p:<tiền tố> và suggest:<version>:data
+	SuggestVersionKey = "suggest:version"
+	SuggestKeyPrefix  = "suggest:"
+	// log từ khóa tìm kiếm không phụ thuộc phiên bản để giữ lại khi dựng lại chỉ mục
+	SuggestQueryAllKey    = "suggest:query:all"
+	SuggestQueryPrefixKey = "suggest:query:p:"
+	// khóa chặn 2 lần dựng lại chỉ mục chạy cùng lúc (ticker và admin)
+	SuggestLockKey = "suggest:lock"
+)
+const (
+	// cache chi tiết sản phẩm theo phiên bản: product:detail:<loại>:<product_id>:<version>,
+	// mỗi lần ghi sản phẩm chỉ cần tăng product:detail:ver:<product_id> để bỏ toàn bộ bản cũ
+	ProductDetailKeyPrefix     = "product:detail:"
+	ProductDetailVersionPrefix = "product:detail:ver:"
+	// ánh xạ key (slug) của sản phẩm -> product_id cho API getdetail
+	ProductDetailSlugPrefix = "product:detail:slug:"
+)
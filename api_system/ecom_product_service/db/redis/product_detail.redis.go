@@ -0,0 +1,97 @@
+package redis_db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// bộ đếm phiên bản phải sống lâu hơn mọi bản cache, nếu không khi nó hết hạn
+// (quay về 0) có thể đọc lại bản cache cũ cùng số phiên bản
+const productDetailVersionTTL = 24 * time.Hour
+
+func productDetailKey(kind, productID string, version int64) string {
+	return ProductDetailKeyPrefix + kind + ":" + productID + ":" + strconv.FormatInt(version, 10)
+}
+
+// ProductDetailVersion trả về 0 nếu sản phẩm chưa từng bị invalidate
+func (s *RedisDB) ProductDetailVersion(ctx context.Context, productID string) (int64, error) {
+	version, err := s.client.Get(ctx, ProductDetailVersionPrefix+productID).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("lỗi khi đọc phiên bản cache sản phẩm %s: %w", productID, err)
+	}
+	return version, nil
+}
+
+// GetProductDetail trả về (nil, nil) khi không có cache
+func (s *RedisDB) GetProductDetail(ctx context.Context, kind, productID string, version int64) (map[string]interface{}, error) {
+	value, err := s.client.Get(ctx, productDetailKey(kind, productID, version)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lỗi khi đọc cache sản phẩm %s: %w", productID, err)
+	}
+	var detail map[string]interface{}
+	if err := json.Unmarshal(value, &detail); err != nil {
+		return nil, fmt.Errorf("lỗi chuyển đổi JSON cache sản phẩm %s: %w", productID, err)
+	}
+	return detail, nil
+}
+
+func (s *RedisDB) SetProductDetail(ctx context.Context, kind, productID string, version int64, detail map[string]interface{}, ttl time.Duration) error {
+	value, err := json.Marshal(detail)
+	if err != nil {
+		return fmt.Errorf("lỗi khi chuyển chi tiết sản phẩm %s sang JSON: %w", productID, err)
+	}
+	if err := s.client.Set(ctx, productDetailKey(kind, productID, version), value, ttl).Err(); err != nil {
+		return fmt.Errorf("lỗi khi ghi cache sản phẩm %s: %w", productID, err)
+	}
+	return nil
+}
+
+// ProductIDBySlug trả về "" khi chưa có ánh xạ
+func (s *RedisDB) ProductIDBySlug(ctx context.Context, key string) (string, error) {
+	productID, err := s.client.Get(ctx, ProductDetailSlugPrefix+key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("lỗi khi đọc ánh xạ key sản phẩm %s: %w", key, err)
+	}
+	return productID, nil
+}
+
+func (s *RedisDB) SetProductIDBySlug(ctx context.Context, key, productID string, ttl time.Duration) error {
+	if err := s.client.Set(ctx, ProductDetailSlugPrefix+key, productID, ttl).Err(); err != nil {
+		return fmt.Errorf("lỗi khi ghi ánh xạ key sản phẩm %s: %w", key, err)
+	}
+	return nil
+}
+
+// InvalidateProductDetail tăng phiên bản cache của các sản phẩm, các bản cũ tự hết hạn theo TTL.
+// slugs là các key (slug) cũ cần bỏ ánh xạ, vd khi sản phẩm đổi key.
+func (s *RedisDB) InvalidateProductDetail(ctx context.Context, productIDs []string, slugs ...string) error {
+	if len(productIDs) == 0 && len(slugs) == 0 {
+		return nil
+	}
+	pipe := s.client.TxPipeline()
+	for _, productID := range productIDs {
+		pipe.Incr(ctx, ProductDetailVersionPrefix+productID)
+		pipe.Expire(ctx, ProductDetailVersionPrefix+productID, productDetailVersionTTL)
+	}
+	for _, key := range slugs {
+		pipe.Del(ctx, ProductDetailSlugPrefix+key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("lỗi khi làm mới cache sản phẩm: %w", err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -192,19 +193,28 @@ func (s *RedisDB) AddCategories(ctx context.Context, cates []modelServices.Categ
 			childMap[parentID] = append(childMap[parentID], cat.CategoryID)
 		}
 	}
-	// Lưu dataMap lên Redis (HMSET)
-	if err := s.client.HSet(ctx, CategoryDataKey, dataMap).Err(); err != nil {
-		return fmt.Errorf("failed to save category data: %w", err)
-	}
-	// Lưu childrenMap lên Redis
+	childrenData := make(map[string]string, len(childMap))
 	for parentID, children := range childMap {
 		childrenJson, err := json.Marshal(children)
 		if err != nil {
 			return fmt.Errorf("failed to marshal children for parent %s: %w", parentID, err)
 		}
-		if err := s.client.HSet(ctx, CategoryChildrenKey, parentID, childrenJson).Err(); err != nil {
-			return fmt.Errorf("failed to save children: %w", err)
+		childrenData[parentID] = string(childrenJson)
+	}
+	// Ghi đè cả 2 hash trong MULTI/EXEC để người đọc không thấy cây đang dựng dở
+	// và không còn sót danh mục đã xóa/di chuyển
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, CategoryDataKey, CategoryChildrenKey, CategoryProductCountKey)
+		if len(dataMap) > 0 {
+			pipe.HSet(ctx, CategoryDataKey, dataMap)
+		}
+		if len(childrenData) > 0 {
+			pipe.HSet(ctx, CategoryChildrenKey, childrenData)
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save category tree: %w", err)
 	}
 	return nil
 }
@@ -213,9 +223,39 @@ func (s *RedisDB) RemoveCategories(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if err := s.client.Del(ctx, CategoryProductCountKey).Err(); err != nil {
+		return err
+	}
 	return s.client.Del(ctx, CategoryDataKey).Err()
 
 }
+
+// GetCategoryProductCounts trả về (nil, nil) khi chưa có cache
+func (s *RedisDB) GetCategoryProductCounts(ctx context.Context) (map[string]int64, error) {
+	value, err := s.client.Get(ctx, CategoryProductCountKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category product counts: %w", err)
+	}
+	counts := make(map[string]int64)
+	if err := json.Unmarshal(value, &counts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal category product counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (s *RedisDB) SetCategoryProductCounts(ctx context.Context, counts map[string]int64, ttl time.Duration) error {
+	value, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal category product counts: %w", err)
+	}
+	if err := s.client.Set(ctx, CategoryProductCountKey, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save category product counts: %w", err)
+	}
+	return nil
+}
 func (s *RedisDB) GetCategoryTree(ctx context.Context, rootID string) ([]modelServices.Categorys, error) {
 	// Lấy toàn bộ dữ liệu danh mục từ Redis
 	dataMap, err := s.client.HGetAll(ctx, CategoryDataKey).Result()
@@ -305,27 +345,38 @@ func (s *RedisDB) GetCategoryTree(ctx context.Context, rootID string) ([]modelSe
 	}
 
 	// Nếu không có rootID, lấy toàn bộ danh mục gốc và xây dựng cây con
-	var result []modelServices.Categorys
+	roots := make([]*modelServices.Categorys, 0)
 	for _, cat := range categories {
 		if !cat.Parent.Valid || categories[cat.Parent.Data] == nil {
-			// Tạo bản sao của category gốc
-			rootCopy := *cat
+			roots = append(roots, cat)
+		}
+	}
+	// hash của redis không giữ thứ tự nên sắp lại danh mục gốc theo sort_order
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].SortOrder != roots[j].SortOrder {
+			return roots[i].SortOrder < roots[j].SortOrder
+		}
+		return roots[i].Name < roots[j].Name
+	})
+	var result []modelServices.Categorys
+	for _, cat := range roots {
+		// Tạo bản sao của category gốc
+		rootCopy := *cat
 
-			// Xây dựng cây con với độ sâu bắt đầu từ 1
-			children, err := buildTree(cat.CategoryID, 1)
-			if err != nil {
-				return nil, err
-			}
+		// Xây dựng cây con với độ sâu bắt đầu từ 1
+		children, err := buildTree(cat.CategoryID, 1)
+		if err != nil {
+			return nil, err
+		}
 
-			if len(children) > 0 {
-				rootCopy.Childs = modelServices.Narg[[]modelServices.Categorys]{
-					Data:  children,
-					Valid: true,
-				}
+		if len(children) > 0 {
+			rootCopy.Childs = modelServices.Narg[[]modelServices.Categorys]{
+				Data:  children,
+				Valid: true,
 			}
-
-			result = append(result, rootCopy)
 		}
+
+		result = append(result, rootCopy)
 	}
 
 	return result, nil
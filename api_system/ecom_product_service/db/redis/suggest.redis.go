@@ -0,0 +1,363 @@
+package redis_db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	modelServices "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// độ dài tối đa của tiền tố được đánh chỉ mục
+	suggestMaxPrefixLen = 20
+	// chỉ sinh tiền tố bắt đầu từ tối đa 5 từ đầu tiên để "thun" vẫn khớp "ao thun"
+	suggestMaxWordStarts = 5
+	// số phần tử tối đa giữ lại trong mỗi tiền tố (theo điểm cao nhất)
+	suggestMaxPerPrefix = 300
+	// số mục ghi mỗi lần pipeline khi dựng lại chỉ mục
+	suggestWriteBatch = 500
+	// thời gian giữ khóa dựng lại, hết hạn để không kẹt nếu tiến trình chết giữa chừng
+	suggestLockTTL = 10 * time.Minute
+)
+
+const (
+	// điểm từ khóa giảm một nửa sau mỗi 14 ngày. Thay vì giảm điểm cũ, mỗi lượt tìm mới
+	// được cộng trọng số 2^((t-epoch)/halfLife) nên lượt tìm gần đây luôn nặng hơn lượt cũ.
+	// Với chu kỳ 14 ngày điểm chỉ vượt giới hạn float64 sau khoảng 39 năm kể từ epoch.
+	suggestQueryHalfLife = 14 * 24 * time.Hour
+	suggestQueryEpoch    = 1704067200 // 2024-01-01 UTC
+	// tiền tố không còn ai tìm sẽ tự hết hạn
+	suggestQueryTTL = 60 * 24 * time.Hour
+	// số từ khóa tối đa giữ trong bảng xếp hạng chung
+	suggestQueryMaxAll = 10000
+)
+
+// releaseSuggestLock chỉ xóa khóa nếu vẫn là của lần dựng này
+var releaseSuggestLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func suggestMember(entryType, id string) string {
+	return entryType + ":" + id
+}
+
+// mỗi loại (sản phẩm, thương hiệu, danh mục) có tập tiền tố riêng để loại này không lấn át loại khác
+func suggestPrefixKey(version, entryType, prefix string) string {
+	return SuggestKeyPrefix + version + ":p:" + entryType + ":" + prefix
+}
+
+func suggestDataKey(version string) string {
+	return SuggestKeyPrefix + version + ":data"
+}
+
+// suggestPrefixes sinh các tiền tố từ chuỗi đã bỏ dấu, bắt đầu ở đầu mỗi từ
+func suggestPrefixes(folded string) []string {
+	words := strings.Fields(folded)
+	seen := make(map[string]bool)
+	prefixes := []string{}
+	for i := 0; i < len(words) && i < suggestMaxWordStarts; i++ {
+		suffix := strings.Join(words[i:], " ")
+		for n := 1; n <= len(suffix) && n <= suggestMaxPrefixLen; n++ {
+			p := strings.TrimSpace(suffix[:n])
+			if p == "" || seen[p] {
+				continue
+			}
+			seen[p] = true
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
+
+// suggestVersion trả về "" nếu chỉ mục chưa được dựng
+func (s *RedisDB) suggestVersion(ctx context.Context) (string, error) {
+	version, err := s.client.Get(ctx, SuggestVersionKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return version, err
+}
+
+func (s *RedisDB) HasSuggestions(ctx context.Context) (bool, error) {
+	version, err := s.suggestVersion(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get suggest version: %w", err)
+	}
+	return version != "", nil
+}
+
+func addSuggestEntry(ctx context.Context, pipe redis.Pipeliner, version string, entry modelServices.SuggestEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	member := suggestMember(entry.Type, entry.ID)
+	for _, prefix := range suggestPrefixes(entry.Folded) {
+		pipe.ZAdd(ctx, suggestPrefixKey(version, entry.Type, prefix), redis.Z{Score: entry.Score, Member: member})
+	}
+	pipe.HSet(ctx, suggestDataKey(version), member, string(data))
+	return nil
+}
+
+// ReplaceSuggestions dựng chỉ mục gợi ý ở phiên bản mới rồi chuyển con trỏ phiên bản,
+// trong lúc dựng người dùng vẫn đọc phiên bản cũ. Phiên bản cũ bị xóa sau khi chuyển.
+// Mỗi lần chỉ một tiến trình được dựng, nếu không 2 lần dựng cùng đọc một phiên bản cũ
+// và phiên bản chuyển trước sẽ không bao giờ bị xóa.
+func (s *RedisDB) ReplaceSuggestions(ctx context.Context, entries []modelServices.SuggestEntry) error {
+	lockToken := strconv.FormatInt(time.Now().UnixNano(), 36)
+	locked, err := s.client.SetNX(ctx, SuggestLockKey, lockToken, suggestLockTTL).Result()
+	if err != nil {
+		return fmt.Errorf("failed to lock suggest index: %w", err)
+	}
+	if !locked {
+		return modelServices.ErrSuggestRebuildRunning
+	}
+	defer releaseSuggestLock.Run(context.Background(), s.client, []string{SuggestLockKey}, lockToken)
+
+	oldVersion, err := s.suggestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get suggest version: %w", err)
+	}
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	prefixKeys := make(map[string]bool)
+	for _, entry := range entries {
+		for _, p := range suggestPrefixes(entry.Folded) {
+			prefixKeys[suggestPrefixKey(version, entry.Type, p)] = true
+		}
+	}
+	for start := 0; start < len(entries); start += suggestWriteBatch {
+		end := min(start+suggestWriteBatch, len(entries))
+		_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, entry := range entries[start:end] {
+				if err := addSuggestEntry(ctx, pipe, version, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			s.deleteSuggestVersion(ctx, version)
+			return fmt.Errorf("failed to save suggest entries: %w", err)
+		}
+	}
+	// giữ kích thước mỗi tiền tố, bỏ các mục điểm thấp nhất
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key := range prefixKeys {
+			pipe.ZRemRangeByRank(ctx, key, 0, -suggestMaxPerPrefix-1)
+		}
+		return nil
+	})
+	if err != nil {
+		s.deleteSuggestVersion(ctx, version)
+		return fmt.Errorf("failed to trim suggest entries: %w", err)
+	}
+	if err := s.client.Set(ctx, SuggestVersionKey, version, 0).Err(); err != nil {
+		s.deleteSuggestVersion(ctx, version)
+		return fmt.Errorf("failed to switch suggest version: %w", err)
+	}
+	if oldVersion != "" {
+		s.deleteSuggestVersion(ctx, oldVersion)
+	}
+	return nil
+}
+
+func (s *RedisDB) deleteSuggestVersion(ctx context.Context, version string) {
+	iter := s.client.Scan(ctx, 0, SuggestKeyPrefix+version+":*", 1000).Iterator()
+	keys := make([]string, 0, 1000)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == cap(keys) {
+			s.client.Unlink(ctx, keys...)
+			keys = keys[:0]
+		}
+	}
+	if len(keys) > 0 {
+		s.client.Unlink(ctx, keys...)
+	}
+}
+
+// UpsertSuggestion cập nhật một mục vào phiên bản hiện tại, bỏ các tiền tố cũ nếu tên đã đổi
+func (s *RedisDB) UpsertSuggestion(ctx context.Context, entry modelServices.SuggestEntry) error {
+	version, err := s.suggestVersion(ctx)
+	if err != nil || version == "" {
+		// chưa dựng chỉ mục thì lần dựng đầy đủ sẽ lấy dữ liệu mới nhất
+		return err
+	}
+	member := suggestMember(entry.Type, entry.ID)
+	old, err := s.getSuggestEntry(ctx, version, member)
+	if err != nil {
+		return err
+	}
+	newPrefixes := suggestPrefixes(entry.Folded)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if old != nil {
+			keep := make(map[string]bool, len(newPrefixes))
+			for _, p := range newPrefixes {
+				keep[p] = true
+			}
+			for _, p := range suggestPrefixes(old.Folded) {
+				if !keep[p] {
+					pipe.ZRem(ctx, suggestPrefixKey(version, entry.Type, p), member)
+				}
+			}
+		}
+		if err := addSuggestEntry(ctx, pipe, version, entry); err != nil {
+			return err
+		}
+		for _, p := range newPrefixes {
+			// giữ kích thước mỗi tiền tố, bỏ các mục điểm thấp nhất
+			pipe.ZRemRangeByRank(ctx, suggestPrefixKey(version, entry.Type, p), 0, -suggestMaxPerPrefix-1)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert suggestion %s: %w", member, err)
+	}
+	return nil
+}
+
+func (s *RedisDB) RemoveSuggestion(ctx context.Context, entryType, id string) error {
+	version, err := s.suggestVersion(ctx)
+	if err != nil || version == "" {
+		return err
+	}
+	member := suggestMember(entryType, id)
+	old, err := s.getSuggestEntry(ctx, version, member)
+	if err != nil || old == nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, p := range suggestPrefixes(old.Folded) {
+			pipe.ZRem(ctx, suggestPrefixKey(version, entryType, p), member)
+		}
+		pipe.HDel(ctx, suggestDataKey(version), member)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove suggestion %s: %w", member, err)
+	}
+	return nil
+}
+
+func (s *RedisDB) getSuggestEntry(ctx context.Context, version, member string) (*modelServices.SuggestEntry, error) {
+	jsonStr, err := s.client.HGet(ctx, suggestDataKey(version), member).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggestion %s: %w", member, err)
+	}
+	var entry modelServices.SuggestEntry
+	if err := json.Unmarshal([]byte(jsonStr), &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal suggestion: %w", err)
+	}
+	return &entry, nil
+}
+
+// SearchSuggestions trả về tối đa limit mục của một loại khớp tiền tố (đã bỏ dấu), điểm cao nhất trước
+func (s *RedisDB) SearchSuggestions(ctx context.Context, entryType, prefix string, limit int) ([]modelServices.SuggestEntry, error) {
+	version, err := s.suggestVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggest version: %w", err)
+	}
+	if version == "" {
+		return []modelServices.SuggestEntry{}, nil
+	}
+	members, err := s.client.ZRevRange(ctx, suggestPrefixKey(version, entryType, prefix), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search suggestions: %w", err)
+	}
+	entries := make([]modelServices.SuggestEntry, 0, len(members))
+	if len(members) == 0 {
+		return entries, nil
+	}
+	values, err := s.client.HMGet(ctx, suggestDataKey(version), members...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get suggestion data: %w", err)
+	}
+	for _, v := range values {
+		jsonStr, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var entry modelServices.SuggestEntry
+		if err := json.Unmarshal([]byte(jsonStr), &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal suggestion: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// suggestQueryWeight trọng số của một lượt tìm tại thời điểm t, gấp đôi sau mỗi suggestQueryHalfLife
+func suggestQueryWeight(t time.Time) float64 {
+	return math.Exp2(float64(t.Unix()-suggestQueryEpoch) / suggestQueryHalfLife.Seconds())
+}
+
+// suggestQueryPrefixes các tiền tố của từ khóa (đã bỏ dấu) được ghi log, bỏ tiền tố kết thúc bằng khoảng trắng
+func suggestQueryPrefixes(query string) []string {
+	prefixes := []string{}
+	for n := 1; n <= len(query) && n <= suggestMaxPrefixLen; n++ {
+		if query[n-1] == ' ' {
+			continue
+		}
+		prefixes = append(prefixes, query[:n])
+	}
+	return prefixes
+}
+
+// LogSearchQuery cộng một lượt tìm (có trọng số theo thời gian) cho từ khóa ở mọi tiền tố của nó.
+// Mỗi tập chỉ bỏ các từ khóa điểm thấp nhất vượt giới hạn, lượt tìm mới có trọng số lớn nhất
+// nên chỉ bị loại khi tiền tố đã đủ từ khóa được tìm gần đây hơn.
+func (s *RedisDB) LogSearchQuery(ctx context.Context, query string) error {
+	weight := suggestQueryWeight(time.Now())
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZIncrBy(ctx, SuggestQueryAllKey, weight, query)
+		pipe.ZRemRangeByRank(ctx, SuggestQueryAllKey, 0, -suggestQueryMaxAll-1)
+		pipe.Expire(ctx, SuggestQueryAllKey, suggestQueryTTL)
+		for _, prefix := range suggestQueryPrefixes(query) {
+			key := SuggestQueryPrefixKey + prefix
+			pipe.ZIncrBy(ctx, key, weight, query)
+			pipe.ZRemRangeByRank(ctx, key, 0, -suggestMaxPerPrefix-1)
+			pipe.Expire(ctx, key, suggestQueryTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to log search query: %w", err)
+	}
+	return nil
+}
+
+// SuggestQueries trả về các từ khóa phổ biến bắt đầu bằng prefix
+func (s *RedisDB) SuggestQueries(ctx context.Context, prefix string, limit int) ([]modelServices.SuggestQuery, error) {
+	return s.topQueries(ctx, SuggestQueryPrefixKey+prefix, limit)
+}
+
+// TopSearchQueries trả về các từ khóa được tìm nhiều nhất
+func (s *RedisDB) TopSearchQueries(ctx context.Context, limit int) ([]modelServices.SuggestQuery, error) {
+	return s.topQueries(ctx, SuggestQueryAllKey, limit)
+}
+
+func (s *RedisDB) topQueries(ctx context.Context, key string, limit int) ([]modelServices.SuggestQuery, error) {
+	items, err := s.client.ZRevRangeWithScores(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search queries: %w", err)
+	}
+	// quy điểm về số lượt tìm tương đương tại thời điểm hiện tại
+	weight := suggestQueryWeight(time.Now())
+	queries := make([]modelServices.SuggestQuery, 0, len(items))
+	for _, item := range items {
+		query, _ := item.Member.(string)
+		queries = append(queries, modelServices.SuggestQuery{Query: query, Count: item.Score / weight})
+	}
+	return queries, nil
+}
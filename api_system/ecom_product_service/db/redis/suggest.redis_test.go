@@ -0,0 +1,48 @@
+package redis_db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestPrefixes(t *testing.T) {
+	prefixes := suggestPrefixes("ao thun nam")
+	require.Contains(t, prefixes, "a")
+	require.Contains(t, prefixes, "ao thun")
+	require.Contains(t, prefixes, "thun")
+	require.Contains(t, prefixes, "nam")
+	// không có tiền tố trùng hoặc kết thúc bằng khoảng trắng
+	seen := map[string]bool{}
+	for _, p := range prefixes {
+		require.False(t, seen[p], p)
+		seen[p] = true
+		require.NotEqual(t, ' ', p[len(p)-1])
+	}
+
+	long := suggestPrefixes("abcdefghijklmnopqrstuvwxyz")
+	require.Len(t, long, suggestMaxPrefixLen)
+}
+
+func TestSuggestQueryPrefixes(t *testing.T) {
+	require.Equal(t, []string{"a", "ao", "ao t", "ao th"}, suggestQueryPrefixes("ao th"))
+	require.Empty(t, suggestQueryPrefixes(""))
+	require.Len(t, suggestQueryPrefixes("abcdefghijklmnopqrstuvwxyz"), suggestMaxPrefixLen)
+}
+
+func TestSuggestQueryWeightDecay(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	weight := suggestQueryWeight(now)
+	require.InDelta(t, 2*suggestQueryWeight(now.Add(-suggestQueryHalfLife)), weight, weight*1e-9)
+
+	// 1 lượt tìm hôm nay hơn 1 lượt cách đây 1 ngày, 2 lượt cách 1 chu kỳ bằng 1 lượt hôm nay
+	older := suggestQueryWeight(now.Add(-24 * time.Hour))
+	require.Greater(t, weight, older)
+	score := 2 * suggestQueryWeight(now.Add(-suggestQueryHalfLife))
+	require.InDelta(t, 1.0, score/weight, 1e-9)
+
+	// từ khóa mới (1 lượt) vượt từ khóa cũ có 100 lượt cách đây 7 chu kỳ
+	stale := 100 * suggestQueryWeight(now.Add(-7*suggestQueryHalfLife))
+	require.Greater(t, weight, stale)
+}
@@ -21,6 +21,18 @@ func (q *Queries) CountBrands(ctx context.Context) (int64, error) {
 	return total, err
 }
 
+const countProductsByBrand = `-- name: CountProductsByBrand :one
+SELECT COUNT(*) AS total FROM product
+WHERE brand_id = ?
+`
+
+func (q *Queries) CountProductsByBrand(ctx context.Context, brandID sql.NullString) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProductsByBrand, brandID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const createBrand = `-- name: CreateBrand :exec
 INSERT INTO brand (
   brand_id, name, code, image, create_date, update_date
@@ -94,6 +106,26 @@ func (q *Queries) GetBrandByCode(ctx context.Context, code string) (Brand, error
 	return i, err
 }
 
+const getBrandForUpdate = `-- name: GetBrandForUpdate :one
+SELECT brand_id, name, code, image, create_date, update_date FROM brand
+WHERE brand_id = ? LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetBrandForUpdate(ctx context.Context, brandID string) (Brand, error) {
+	row := q.db.QueryRowContext(ctx, getBrandForUpdate, brandID)
+	var i Brand
+	err := row.Scan(
+		&i.BrandID,
+		&i.Name,
+		&i.Code,
+		&i.Image,
+		&i.CreateDate,
+		&i.UpdateDate,
+	)
+	return i, err
+}
+
 const listBrands = `-- name: ListBrands :many
 SELECT brand_id, name, code, image, create_date, update_date FROM brand
 ORDER BY create_date DESC
@@ -170,14 +202,30 @@ func (q *Queries) ListBrandsPaged(ctx context.Context, arg ListBrandsPagedParams
 	return items, nil
 }
 
+const reassignProductsBrand = `-- name: ReassignProductsBrand :exec
+UPDATE product
+SET brand_id = ?
+WHERE brand_id = ?
+`
+
+type ReassignProductsBrandParams struct {
+	NewBrandID sql.NullString `json:"new_brand_id"`
+	OldBrandID sql.NullString `json:"old_brand_id"`
+}
+
+func (q *Queries) ReassignProductsBrand(ctx context.Context, arg ReassignProductsBrandParams) error {
+	_, err := q.db.ExecContext(ctx, reassignProductsBrand, arg.NewBrandID, arg.OldBrandID)
+	return err
+}
+
 const searchBrandsByName = `-- name: SearchBrandsByName :many
 SELECT brand_id, name, code, image, create_date, update_date FROM brand
-WHERE name LIKE '%' || ? || '%'
+WHERE name LIKE CONCAT('%', ?, '%')
 ORDER BY name
 `
 
-func (q *Queries) SearchBrandsByName(ctx context.Context, dollar_1 interface{}) ([]Brand, error) {
-	rows, err := q.db.QueryContext(ctx, searchBrandsByName, dollar_1)
+func (q *Queries) SearchBrandsByName(ctx context.Context, keyword interface{}) ([]Brand, error) {
+	rows, err := q.db.QueryContext(ctx, searchBrandsByName, keyword)
 	if err != nil {
 		return nil, err
 	}
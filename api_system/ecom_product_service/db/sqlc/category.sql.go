@@ -10,6 +10,41 @@ import (
 	"database/sql"
 )
 
+const countActiveProductsPerCategory = `-- name: CountActiveProductsPerCategory :many
+SELECT category_id, COUNT(*) AS total FROM product
+WHERE delete_status = 'Active'
+GROUP BY category_id
+`
+
+type CountActiveProductsPerCategoryRow struct {
+	CategoryID string `json:"category_id"`
+	Total      int64  `json:"total"`
+}
+
+// Số sản phẩm đang bán trực tiếp trong từng danh mục (chưa cộng danh mục con)
+func (q *Queries) CountActiveProductsPerCategory(ctx context.Context) ([]CountActiveProductsPerCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, countActiveProductsPerCategory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountActiveProductsPerCategoryRow
+	for rows.Next() {
+		var i CountActiveProductsPerCategoryRow
+		if err := rows.Scan(&i.CategoryID, &i.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const countCategories = `-- name: CountCategories :one
 SELECT COUNT(*) AS total FROM category
 `
@@ -21,6 +56,18 @@ func (q *Queries) CountCategories(ctx context.Context) (int64, error) {
 	return total, err
 }
 
+const countProductsByCategory = `-- name: CountProductsByCategory :one
+SELECT COUNT(*) AS total FROM product
+WHERE category_id = ?
+`
+
+func (q *Queries) CountProductsByCategory(ctx context.Context, categoryID string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProductsByCategory, categoryID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const createCategory = `-- name: CreateCategory :exec
 INSERT INTO category (
   category_id, name, ` + "`" + `key` + "`" + `, ` + "`" + `path` + "`" + `, parent, image
@@ -61,7 +108,7 @@ func (q *Queries) DeleteCategory(ctx context.Context, categoryID string) error {
 }
 
 const getCategory = `-- name: GetCategory :one
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 WHERE category_id = ? LIMIT 1
 `
 
@@ -75,12 +122,13 @@ func (q *Queries) GetCategory(ctx context.Context, categoryID string) (Category,
 		&i.Path,
 		&i.Parent,
 		&i.Image,
+		&i.SortOrder,
 	)
 	return i, err
 }
 
 const getCategoryByPath = `-- name: GetCategoryByPath :one
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 WHERE path = ? LIMIT 1
 `
 
@@ -94,12 +142,34 @@ func (q *Queries) GetCategoryByPath(ctx context.Context, path sql.NullString) (C
 		&i.Path,
 		&i.Parent,
 		&i.Image,
+		&i.SortOrder,
+	)
+	return i, err
+}
+
+const getCategoryForUpdate = `-- name: GetCategoryForUpdate :one
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
+WHERE category_id = ? LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetCategoryForUpdate(ctx context.Context, categoryID string) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategoryForUpdate, categoryID)
+	var i Category
+	err := row.Scan(
+		&i.CategoryID,
+		&i.Name,
+		&i.Key,
+		&i.Path,
+		&i.Parent,
+		&i.Image,
+		&i.SortOrder,
 	)
 	return i, err
 }
 
 const getRootCategories = `-- name: GetRootCategories :many
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 WHERE parent IS NULL
 `
 
@@ -119,6 +189,7 @@ func (q *Queries) GetRootCategories(ctx context.Context) ([]Category, error) {
 			&i.Path,
 			&i.Parent,
 			&i.Image,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -134,7 +205,7 @@ func (q *Queries) GetRootCategories(ctx context.Context) ([]Category, error) {
 }
 
 const getSubCategories = `-- name: GetSubCategories :many
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 WHERE parent = ?
 `
 
@@ -154,6 +225,7 @@ func (q *Queries) GetSubCategories(ctx context.Context, parent sql.NullString) (
 			&i.Path,
 			&i.Parent,
 			&i.Image,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -169,8 +241,8 @@ func (q *Queries) GetSubCategories(ctx context.Context, parent sql.NullString) (
 }
 
 const listCategories = `-- name: ListCategories :many
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
-ORDER BY name
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
+ORDER BY sort_order, name
 `
 
 func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
@@ -189,6 +261,45 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 			&i.Path,
 			&i.Parent,
 			&i.Image,
+			&i.SortOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategoryDescendants = `-- name: ListCategoryDescendants :many
+
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
+WHERE ` + "`" + `path` + "`" + ` LIKE CONCAT(REPLACE(REPLACE(REPLACE(?, '\\', '\\\\'), '%', '\\%'), '_', '\\_'), '/%')
+`
+
+// Danh mục con theo tiền tố path, thoát ký tự đại diện của LIKE (key danh mục có thể chứa '_')
+func (q *Queries) ListCategoryDescendants(ctx context.Context, path interface{}) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategoryDescendants, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Name,
+			&i.Key,
+			&i.Path,
+			&i.Parent,
+			&i.Image,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -204,7 +315,7 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 }
 
 const listCategoriesPaged = `-- name: ListCategoriesPaged :many
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 ORDER BY name
 LIMIT ? OFFSET ?
 `
@@ -230,6 +341,7 @@ func (q *Queries) ListCategoriesPaged(ctx context.Context, arg ListCategoriesPag
 			&i.Path,
 			&i.Parent,
 			&i.Image,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -244,8 +356,24 @@ func (q *Queries) ListCategoriesPaged(ctx context.Context, arg ListCategoriesPag
 	return items, nil
 }
 
+const reassignProductsCategory = `-- name: ReassignProductsCategory :exec
+UPDATE product
+SET category_id = ?
+WHERE category_id = ?
+`
+
+type ReassignProductsCategoryParams struct {
+	NewCategoryID string `json:"new_category_id"`
+	OldCategoryID string `json:"old_category_id"`
+}
+
+func (q *Queries) ReassignProductsCategory(ctx context.Context, arg ReassignProductsCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, reassignProductsCategory, arg.NewCategoryID, arg.OldCategoryID)
+	return err
+}
+
 const searchCategoriesByName = `-- name: SearchCategoriesByName :many
-SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image FROM category
+SELECT category_id, name, ` + "`" + `key` + "`" + `, path, parent, image, sort_order FROM category
 WHERE name LIKE '%' || ? || '%'
 ORDER BY name
 `
@@ -266,6 +394,7 @@ func (q *Queries) SearchCategoriesByName(ctx context.Context, dollar_1 interface
 			&i.Path,
 			&i.Parent,
 			&i.Image,
+			&i.SortOrder,
 		); err != nil {
 			return nil, err
 		}
@@ -343,3 +472,35 @@ func (q *Queries) UpdateCategoryParent(ctx context.Context, arg UpdateCategoryPa
 	_, err := q.db.ExecContext(ctx, updateCategoryParent, arg.Parent, arg.CategoryID)
 	return err
 }
+
+const updateCategoryPath = `-- name: UpdateCategoryPath :exec
+UPDATE category
+SET ` + "`" + `path` + "`" + ` = ?
+WHERE category_id = ?
+`
+
+type UpdateCategoryPathParams struct {
+	Path       sql.NullString `json:"path"`
+	CategoryID string         `json:"category_id"`
+}
+
+func (q *Queries) UpdateCategoryPath(ctx context.Context, arg UpdateCategoryPathParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategoryPath, arg.Path, arg.CategoryID)
+	return err
+}
+
+const updateCategorySortOrder = `-- name: UpdateCategorySortOrder :exec
+UPDATE category
+SET sort_order = ?
+WHERE category_id = ?
+`
+
+type UpdateCategorySortOrderParams struct {
+	SortOrder  int32  `json:"sort_order"`
+	CategoryID string `json:"category_id"`
+}
+
+func (q *Queries) UpdateCategorySortOrder(ctx context.Context, arg UpdateCategorySortOrderParams) error {
+	_, err := q.db.ExecContext(ctx, updateCategorySortOrder, arg.SortOrder, arg.CategoryID)
+	return err
+}
@@ -8,14 +8,60 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"time"
 )
 
+type CategoryAttributeDataType string
+
+const (
+	CategoryAttributeDataTypeENUM    CategoryAttributeDataType = "ENUM"
+	CategoryAttributeDataTypeNUMBER  CategoryAttributeDataType = "NUMBER"
+	CategoryAttributeDataTypeBOOLEAN CategoryAttributeDataType = "BOOLEAN"
+)
+
+func (e *CategoryAttributeDataType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CategoryAttributeDataType(s)
+	case string:
+		*e = CategoryAttributeDataType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CategoryAttributeDataType: %T", src)
+	}
+	return nil
+}
+
+type NullCategoryAttributeDataType struct {
+	CategoryAttributeDataType CategoryAttributeDataType `json:"category_attribute_data_type"`
+	Valid                     bool                      `json:"valid"` // Valid is true if CategoryAttributeDataType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCategoryAttributeDataType) Scan(value interface{}) error {
+	if value == nil {
+		ns.CategoryAttributeDataType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CategoryAttributeDataType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCategoryAttributeDataType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CategoryAttributeDataType), nil
+}
+
 type ProductDeleteStatus string
 
 const (
-	ProductDeleteStatusPending ProductDeleteStatus = "Pending"
-	ProductDeleteStatusActive  ProductDeleteStatus = "Active"
-	ProductDeleteStatusDeleted ProductDeleteStatus = "Deleted"
+	ProductDeleteStatusPending  ProductDeleteStatus = "Pending"
+	ProductDeleteStatusActive   ProductDeleteStatus = "Active"
+	ProductDeleteStatusDeleted  ProductDeleteStatus = "Deleted"
+	ProductDeleteStatusRejected ProductDeleteStatus = "Rejected"
+	ProductDeleteStatusUnlisted ProductDeleteStatus = "Unlisted"
 )
 
 func (e *ProductDeleteStatus) Scan(src interface{}) error {
@@ -53,6 +99,49 @@ func (ns NullProductDeleteStatus) Value() (driver.Value, error) {
 	return string(ns.ProductDeleteStatus), nil
 }
 
+type ProductModerationAction string
+
+const (
+	ProductModerationActionSUBMITTED ProductModerationAction = "SUBMITTED"
+	ProductModerationActionAPPROVED  ProductModerationAction = "APPROVED"
+	ProductModerationActionREJECTED  ProductModerationAction = "REJECTED"
+)
+
+func (e *ProductModerationAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProductModerationAction(s)
+	case string:
+		*e = ProductModerationAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProductModerationAction: %T", src)
+	}
+	return nil
+}
+
+type NullProductModerationAction struct {
+	ProductModerationAction ProductModerationAction `json:"product_moderation_action"`
+	Valid                   bool                    `json:"valid"` // Valid is true if ProductModerationAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProductModerationAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProductModerationAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProductModerationAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProductModerationAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProductModerationAction), nil
+}
+
 type Brand struct {
 	BrandID    string         `json:"brand_id"`
 	Name       string         `json:"name"`
@@ -69,6 +158,31 @@ type Category struct {
 	Path       sql.NullString `json:"path"`
 	Parent     sql.NullString `json:"parent"`
 	Image      sql.NullString `json:"image"`
+	SortOrder  int32          `json:"sort_order"`
+}
+
+type CategoryAttribute struct {
+	ID           string                    `json:"id"`
+	CategoryID   string                    `json:"category_id"`
+	Code         string                    `json:"code"`
+	Name         string                    `json:"name"`
+	DataType     CategoryAttributeDataType `json:"data_type"`
+	Unit         sql.NullString            `json:"unit"`
+	EnumValues   sql.NullString            `json:"enum_values"`
+	IsRequired   bool                      `json:"is_required"`
+	IsFilterable bool                      `json:"is_filterable"`
+	SortOrder    int32                     `json:"sort_order"`
+	CreateDate   sql.NullTime              `json:"create_date"`
+}
+
+type Medium struct {
+	ID         string    `json:"id"`
+	Owner      string    `json:"owner"`
+	MimeType   string    `json:"mime_type"`
+	Size       int64     `json:"size"`
+	Width      int32     `json:"width"`
+	Height     int32     `json:"height"`
+	CreateDate time.Time `json:"create_date"`
 }
 
 type OptionValue struct {
@@ -100,6 +214,50 @@ type Product struct {
 	TotalSold                 int64                   `json:"total_sold"`
 	MinPrice                  sql.NullFloat64         `json:"min_price"`
 	MaxPrice                  sql.NullFloat64         `json:"max_price"`
+	MinPriceSkuID             sql.NullString          `json:"min_price_sku_id"`
+	MaxPriceSkuID             sql.NullString          `json:"max_price_sku_id"`
+	TotalStock                int32                   `json:"total_stock"`
+	InStock                   bool                    `json:"in_stock"`
+	RatingAverage             float64                 `json:"rating_average"`
+	RatingCount               int32                   `json:"rating_count"`
+	RatingScore               float64                 `json:"rating_score"`
+}
+
+type ProductAttributeValue struct {
+	ProductID   string          `json:"product_id"`
+	AttributeID string          `json:"attribute_id"`
+	Value       string          `json:"value"`
+	ValueNumber sql.NullFloat64 `json:"value_number"`
+}
+
+type ProductModeration struct {
+	ID            string                  `json:"id"`
+	ProductID     string                  `json:"product_id"`
+	Action        ProductModerationAction `json:"action"`
+	Reason        sql.NullString          `json:"reason"`
+	ChangedFields sql.NullString          `json:"changed_fields"`
+	Actor         string                  `json:"actor"`
+	CreateDate    sql.NullTime            `json:"create_date"`
+}
+
+type ProductOwnershipAudit struct {
+	ID         string         `json:"id"`
+	UserID     string         `json:"user_id"`
+	UserName   string         `json:"user_name"`
+	ShopID     string         `json:"shop_id"`
+	ProductID  sql.NullString `json:"product_id"`
+	Action     string         `json:"action"`
+	CreateDate sql.NullTime   `json:"create_date"`
+}
+
+type ProductSearchDocument struct {
+	ProductID         string       `json:"product_id"`
+	NameFolded        string       `json:"name_folded"`
+	BrandFolded       string       `json:"brand_folded"`
+	CategoryFolded    string       `json:"category_folded"`
+	DescriptionFolded string       `json:"description_folded"`
+	Document          string       `json:"document"`
+	UpdateDate        sql.NullTime `json:"update_date"`
 }
 
 type ProductSku struct {
@@ -115,8 +273,34 @@ type ProductSku struct {
 	UpdateDate       sql.NullTime   `json:"update_date"`
 }
 
+type ProductStatusHistory struct {
+	ID         string    `json:"id"`
+	ProductID  string    `json:"product_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor"`
+	CreateDate time.Time `json:"create_date"`
+}
+
+type SellerShop struct {
+	UserID     string         `json:"user_id"`
+	ShopID     string         `json:"shop_id"`
+	CreateBy   sql.NullString `json:"create_by"`
+	CreateDate sql.NullTime   `json:"create_date"`
+}
+
 type SkuAttr struct {
 	SkuID         string `json:"sku_id"`
 	OptionValueID string `json:"option_value_id"`
 	ProductID     string `json:"product_id"`
 }
+
+type SkuPriceHistory struct {
+	ID         string          `json:"id"`
+	SkuID      string          `json:"sku_id"`
+	ProductID  string          `json:"product_id"`
+	OldPrice   sql.NullFloat64 `json:"old_price"`
+	NewPrice   float64         `json:"new_price"`
+	Actor      string          `json:"actor"`
+	CreateDate time.Time       `json:"create_date"`
+}
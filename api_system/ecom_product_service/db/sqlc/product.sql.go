@@ -289,7 +289,7 @@ func (q *Queries) GetProductByKey(ctx context.Context, key string) (GetProductBy
 }
 
 const getProductIDs = `-- name: GetProductIDs :many
-SELECT id, name, ` + "`" + `key` + "`" + `, description, short_description, brand_id, category_id, shop_id, image, media, delete_status, product_is_permission_return, product_is_permission_check, create_date, update_date, create_by, update_by, total_sold, min_price, max_price FROM product 
+SELECT id, name, ` + "`" + `key` + "`" + `, description, short_description, brand_id, category_id, shop_id, image, media, delete_status, product_is_permission_return, product_is_permission_check, create_date, update_date, create_by, update_by, total_sold, min_price, max_price, min_price_sku_id, max_price_sku_id, total_stock, in_stock, rating_average, rating_count, rating_score FROM product 
 WHERE id IN (/*SLICE:product_ids*/?)
 `
 
@@ -333,6 +333,13 @@ func (q *Queries) GetProductIDs(ctx context.Context, productIds []string) ([]Pro
 			&i.TotalSold,
 			&i.MinPrice,
 			&i.MaxPrice,
+			&i.MinPriceSkuID,
+			&i.MaxPriceSkuID,
+			&i.TotalStock,
+			&i.InStock,
+			&i.RatingAverage,
+			&i.RatingCount,
+			&i.RatingScore,
 		); err != nil {
 			return nil, err
 		}
@@ -378,6 +385,94 @@ func (q *Queries) IncrementProductTotalSold(ctx context.Context, arg IncrementPr
 	return err
 }
 
+const listActiveProductsForSuggest = `-- name: ListActiveProductsForSuggest :many
+SELECT id, name, ` + "`" + `key` + "`" + `, brand_id, category_id, total_sold FROM product
+WHERE delete_status = 'Active' AND id > ?
+ORDER BY id
+LIMIT ?
+`
+
+type ListActiveProductsForSuggestParams struct {
+	AfterID string `json:"after_id"`
+	Limit   int32  `json:"limit"`
+}
+
+type ListActiveProductsForSuggestRow struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Key        string         `json:"key"`
+	BrandID    sql.NullString `json:"brand_id"`
+	CategoryID string         `json:"category_id"`
+	TotalSold  int64          `json:"total_sold"`
+}
+
+// Sản phẩm đang bán để dựng chỉ mục gợi ý, đọc theo từng lô (keyset theo id)
+func (q *Queries) ListActiveProductsForSuggest(ctx context.Context, arg ListActiveProductsForSuggestParams) ([]ListActiveProductsForSuggestRow, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveProductsForSuggest, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveProductsForSuggestRow
+	for rows.Next() {
+		var i ListActiveProductsForSuggestRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Key,
+			&i.BrandID,
+			&i.CategoryID,
+			&i.TotalSold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductIDsAfter = `-- name: ListProductIDsAfter :many
+SELECT id FROM product
+WHERE id > ?
+ORDER BY id
+LIMIT ?
+`
+
+type ListProductIDsAfterParams struct {
+	AfterID string `json:"after_id"`
+	Limit   int32  `json:"limit"`
+}
+
+// Duyệt toàn bộ sản phẩm theo từng lô (keyset theo id), dùng cho job reindex
+func (q *Queries) ListProductIDsAfter(ctx context.Context, arg ListProductIDsAfterParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listProductIDsAfter, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProductsAdvanced = `-- name: ListProductsAdvanced :many
 SELECT 
     p.id,
@@ -400,9 +495,14 @@ SELECT
     p.total_sold,
     p.min_price,
     p.max_price,
-    -- Subquery lấy SKU ID đại diện (giữ nguyên logic tối ưu)
-    (SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price ASC LIMIT 1) AS min_price_sku_id,
-    (SELECT ps.id FROM product_sku ps WHERE ps.product_id = p.id ORDER BY ps.price DESC LIMIT 1) AS max_price_sku_id
+    -- SKU đại diện và tồn kho được tính sẵn khi thay đổi SKU
+    COALESCE(p.min_price_sku_id, '') AS min_price_sku_id,
+    COALESCE(p.max_price_sku_id, '') AS max_price_sku_id,
+    p.total_stock,
+    p.in_stock,
+    p.rating_average,
+    p.rating_count,
+    p.rating_score
 FROM product p
 WHERE 
     (? IS NULL OR p.delete_status = ?)
@@ -461,6 +561,11 @@ type ListProductsAdvancedRow struct {
 	MaxPrice                  sql.NullFloat64         `json:"max_price"`
 	MinPriceSkuID             string                  `json:"min_price_sku_id"`
 	MaxPriceSkuID             string                  `json:"max_price_sku_id"`
+	TotalStock                int32                   `json:"total_stock"`
+	InStock                   bool                    `json:"in_stock"`
+	RatingAverage             float64                 `json:"rating_average"`
+	RatingCount               int32                   `json:"rating_count"`
+	RatingScore               float64                 `json:"rating_score"`
 }
 
 func (q *Queries) ListProductsAdvanced(ctx context.Context, arg ListProductsAdvancedParams) ([]ListProductsAdvancedRow, error) {
@@ -517,6 +622,11 @@ func (q *Queries) ListProductsAdvanced(ctx context.Context, arg ListProductsAdva
 			&i.MaxPrice,
 			&i.MinPriceSkuID,
 			&i.MaxPriceSkuID,
+			&i.TotalStock,
+			&i.InStock,
+			&i.RatingAverage,
+			&i.RatingCount,
+			&i.RatingScore,
 		); err != nil {
 			return nil, err
 		}
@@ -587,3 +697,65 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) er
 	)
 	return err
 }
+
+const updateProductAggregates = `-- name: UpdateProductAggregates :exec
+UPDATE product
+SET
+  min_price = ?,
+  max_price = ?,
+  min_price_sku_id = ?,
+  max_price_sku_id = ?,
+  total_stock = ?,
+  in_stock = ?
+WHERE id = ?
+`
+
+type UpdateProductAggregatesParams struct {
+	MinPrice      float64        `json:"min_price"`
+	MaxPrice      float64        `json:"max_price"`
+	MinPriceSkuID sql.NullString `json:"min_price_sku_id"`
+	MaxPriceSkuID sql.NullString `json:"max_price_sku_id"`
+	TotalStock    int32          `json:"total_stock"`
+	InStock       bool           `json:"in_stock"`
+	ID            string         `json:"id"`
+}
+
+func (q *Queries) UpdateProductAggregates(ctx context.Context, arg UpdateProductAggregatesParams) error {
+	_, err := q.db.ExecContext(ctx, updateProductAggregates,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.MinPriceSkuID,
+		arg.MaxPriceSkuID,
+		arg.TotalStock,
+		arg.InStock,
+		arg.ID,
+	)
+	return err
+}
+
+const updateProductRating = `-- name: UpdateProductRating :exec
+UPDATE product
+SET
+  rating_average = ?,
+  rating_count = ?,
+  rating_score = ?
+WHERE id = ?
+`
+
+type UpdateProductRatingParams struct {
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int32   `json:"rating_count"`
+	RatingScore   float64 `json:"rating_score"`
+	ID            string  `json:"id"`
+}
+
+// Điểm đánh giá đồng bộ từ order service
+func (q *Queries) UpdateProductRating(ctx context.Context, arg UpdateProductRatingParams) error {
+	_, err := q.db.ExecContext(ctx, updateProductRating,
+		arg.RatingAverage,
+		arg.RatingCount,
+		arg.RatingScore,
+		arg.ID,
+	)
+	return err
+}
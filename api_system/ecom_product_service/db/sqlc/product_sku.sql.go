@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 )
 
 const createProductSKU = `-- name: CreateProductSKU :exec
@@ -117,6 +118,79 @@ func (q *Queries) ListSKUsByProduct(ctx context.Context, productID string) ([]Pr
 	return items, nil
 }
 
+const listSKUsForOrder = `-- name: ListSKUsForOrder :many
+SELECT
+  ps.id, ps.product_id, ps.sku_code, ps.sku_name, ps.price, ps.quantity, ps.quantity_reserver, ps.weight,
+  p.name AS product_name, p.image AS product_image, p.shop_id, p.category_id, p.delete_status AS product_status
+FROM product_sku ps
+JOIN product p ON p.id = ps.product_id
+WHERE ps.id IN (/*SLICE:sku_ids*/?)
+`
+
+type ListSKUsForOrderRow struct {
+	ID               string                  `json:"id"`
+	ProductID        string                  `json:"product_id"`
+	SkuCode          string                  `json:"sku_code"`
+	SkuName          sql.NullString          `json:"sku_name"`
+	Price            float64                 `json:"price"`
+	Quantity         int32                   `json:"quantity"`
+	QuantityReserver int32                   `json:"quantity_reserver"`
+	Weight           float64                 `json:"weight"`
+	ProductName      string                  `json:"product_name"`
+	ProductImage     string                  `json:"product_image"`
+	ShopID           string                  `json:"shop_id"`
+	CategoryID       string                  `json:"category_id"`
+	ProductStatus    NullProductDeleteStatus `json:"product_status"`
+}
+
+// SKU kèm thông tin sản phẩm cho tạo đơn hàng, lấy nhiều SKU trong một truy vấn
+func (q *Queries) ListSKUsForOrder(ctx context.Context, skuIds []string) ([]ListSKUsForOrderRow, error) {
+	query := listSKUsForOrder
+	var queryParams []interface{}
+	if len(skuIds) > 0 {
+		for _, v := range skuIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:sku_ids*/?", strings.Repeat(",?", len(skuIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:sku_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSKUsForOrderRow
+	for rows.Next() {
+		var i ListSKUsForOrderRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.SkuCode,
+			&i.SkuName,
+			&i.Price,
+			&i.Quantity,
+			&i.QuantityReserver,
+			&i.Weight,
+			&i.ProductName,
+			&i.ProductImage,
+			&i.ShopID,
+			&i.CategoryID,
+			&i.ProductStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateProductSKU = `-- name: UpdateProductSKU :exec
 UPDATE product_sku
 SET
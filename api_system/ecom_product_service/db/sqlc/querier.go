@@ -10,56 +10,126 @@ import (
 )
 
 type Querier interface {
+	// Số sản phẩm đang bán trực tiếp trong từng danh mục (chưa cộng danh mục con)
+	CountActiveProductsPerCategory(ctx context.Context) ([]CountActiveProductsPerCategoryRow, error)
 	CountBrands(ctx context.Context) (int64, error)
 	CountCategories(ctx context.Context) (int64, error)
+	// Số thuộc tính cùng code ở các danh mục con (theo tiền tố path, thoát ký tự đại diện của LIKE)
+	CountDescendantAttributesByCode(ctx context.Context, arg CountDescendantAttributesByCodeParams) (int64, error)
+	// Số nơi còn dùng file media: ảnh chính/ảnh phụ sản phẩm, ảnh option, ảnh danh mục và thương hiệu
+	CountMediaReferences(ctx context.Context, fileName string) (int64, error)
+	CountProductOwnershipAudit(ctx context.Context) (int64, error)
 	CountProductsAdvanced(ctx context.Context, arg CountProductsAdvancedParams) (int64, error)
+	CountProductsByBrand(ctx context.Context, brandID sql.NullString) (int64, error)
+	CountProductsByCategory(ctx context.Context, categoryID string) (int64, error)
 	CreateBrand(ctx context.Context, arg CreateBrandParams) error
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) error
+	CreateCategoryAttribute(ctx context.Context, arg CreateCategoryAttributeParams) error
+	CreateMedia(ctx context.Context, arg CreateMediaParams) error
 	// OPTION VALUE (option_value) CRUD
 	CreateOptionValue(ctx context.Context, arg CreateOptionValueParams) error
 	// PRODUCT CRUD & UTILS
 	CreateProduct(ctx context.Context, arg CreateProductParams) error
+	CreateProductAttributeValue(ctx context.Context, arg CreateProductAttributeValueParams) error
+	CreateProductModeration(ctx context.Context, arg CreateProductModerationParams) error
+	CreateProductOwnershipAudit(ctx context.Context, arg CreateProductOwnershipAuditParams) error
 	// PRODUCT SKU (product_sku) CRUD
 	CreateProductSKU(ctx context.Context, arg CreateProductSKUParams) error
+	CreateProductStatusHistory(ctx context.Context, arg CreateProductStatusHistoryParams) error
 	// SKU_ATTR (sku_attr) CRUD
 	CreateSKUAttr(ctx context.Context, arg CreateSKUAttrParams) error
+	CreateSKUPriceHistory(ctx context.Context, arg CreateSKUPriceHistoryParams) error
+	CreateSellerShop(ctx context.Context, arg CreateSellerShopParams) error
 	DeleteBrand(ctx context.Context, brandID string) error
 	DeleteCategory(ctx context.Context, categoryID string) error
+	DeleteCategoryAttribute(ctx context.Context, id string) error
+	DeleteMedia(ctx context.Context, id string) error
 	DeleteOptionValue(ctx context.Context, id string) error
 	DeleteProduct(ctx context.Context, id string) error
+	DeleteProductAttributeValues(ctx context.Context, productID string) error
 	DeleteProductSKU(ctx context.Context, id string) error
+	DeleteProductSearchDocument(ctx context.Context, productID string) error
 	DeleteSKUAttr(ctx context.Context, arg DeleteSKUAttrParams) error
+	DeleteSellerShop(ctx context.Context, arg DeleteSellerShopParams) error
 	GetAllProductID(ctx context.Context) ([]string, error)
 	GetBrand(ctx context.Context, brandID string) (Brand, error)
 	GetBrandByCode(ctx context.Context, code string) (Brand, error)
+	GetBrandForUpdate(ctx context.Context, brandID string) (Brand, error)
 	GetCategory(ctx context.Context, categoryID string) (Category, error)
+	GetCategoryAttribute(ctx context.Context, id string) (CategoryAttribute, error)
 	GetCategoryByPath(ctx context.Context, path sql.NullString) (Category, error)
+	GetCategoryForUpdate(ctx context.Context, categoryID string) (Category, error)
+	GetMedia(ctx context.Context, id string) (Medium, error)
 	GetProduct(ctx context.Context, id string) (GetProductRow, error)
 	GetProductByKey(ctx context.Context, key string) (GetProductByKeyRow, error)
 	GetProductIDs(ctx context.Context, productIds []string) ([]Product, error)
 	GetProductSKU(ctx context.Context, id string) (ProductSku, error)
+	// Khóa dòng sản phẩm trong transaction để đổi trạng thái dựa trên trạng thái mới nhất
+	GetProductStatusForUpdate(ctx context.Context, id string) (NullProductDeleteStatus, error)
 	GetProductStockTotal(ctx context.Context, productID string) (interface{}, error)
 	GetRootCategories(ctx context.Context) ([]Category, error)
+	// Trạng thái của sản phẩm ngay trước lần xóa mềm gần nhất
+	GetStatusBeforeDelete(ctx context.Context, productID string) (string, error)
+	// Trạng thái của sản phẩm ngay trước khi vào vòng kiểm duyệt gần nhất (chờ duyệt hoặc bị từ chối)
+	GetStatusBeforeModeration(ctx context.Context, productID string) (string, error)
 	GetSubCategories(ctx context.Context, parent sql.NullString) ([]Category, error)
 	IncrementProductTotalSold(ctx context.Context, arg IncrementProductTotalSoldParams) error
+	// Sản phẩm đang bán để dựng chỉ mục gợi ý, đọc theo từng lô (keyset theo id)
+	ListActiveProductsForSuggest(ctx context.Context, arg ListActiveProductsForSuggestParams) ([]ListActiveProductsForSuggestRow, error)
 	ListBrands(ctx context.Context) ([]Brand, error)
 	ListBrandsPaged(ctx context.Context, arg ListBrandsPagedParams) ([]Brand, error)
 	ListCategories(ctx context.Context) ([]Category, error)
+	// Thuộc tính của danh mục và toàn bộ danh mục cha (kế thừa theo path), danh mục cha đứng trước
+	ListCategoryAttributesByPath(ctx context.Context, path interface{}) ([]CategoryAttribute, error)
 	ListCategoriesPaged(ctx context.Context, arg ListCategoriesPagedParams) ([]Category, error)
+	// Danh mục con theo tiền tố path, thoát ký tự đại diện của LIKE (key danh mục có thể chứa '_')
+	ListCategoryDescendants(ctx context.Context, path interface{}) ([]Category, error)
+	// Media tạo trước mốc thời gian, đọc theo từng lô (keyset theo id) cho job dọn file
+	ListMediaCreatedBefore(ctx context.Context, arg ListMediaCreatedBeforeParams) ([]Medium, error)
 	ListOptionValuesByProductID(ctx context.Context, productID string) ([]OptionValue, error)
+	ListProductAttributeValues(ctx context.Context, productID string) ([]ListProductAttributeValuesRow, error)
+	// Duyệt toàn bộ sản phẩm theo từng lô (keyset theo id), dùng cho job reindex
+	ListProductIDsAfter(ctx context.Context, arg ListProductIDsAfterParams) ([]string, error)
+	// Sản phẩm cần dựng lại tài liệu tìm kiếm khi thương hiệu đổi tên/mã
+	ListProductIDsByBrand(ctx context.Context, brandID sql.NullString) ([]string, error)
+	// Sản phẩm cần dựng lại tài liệu tìm kiếm khi danh mục đổi tên hoặc đổi path
+	ListProductIDsByCategories(ctx context.Context, categoryIds []string) ([]string, error)
+	ListProductModerationByProduct(ctx context.Context, productID string) ([]ProductModeration, error)
+	ListProductOwnershipAudit(ctx context.Context, arg ListProductOwnershipAuditParams) ([]ProductOwnershipAudit, error)
+	// Đọc theo từng lô (keyset theo product_id) để nạp chỉ mục
+	ListProductSearchDocuments(ctx context.Context, arg ListProductSearchDocumentsParams) ([]ProductSearchDocument, error)
+	ListProductSearchDocumentsUpdatedSince(ctx context.Context, updateDate sql.NullTime) ([]ProductSearchDocument, error)
 	ListProductsAdvanced(ctx context.Context, arg ListProductsAdvancedParams) ([]ListProductsAdvancedRow, error)
+	// Giá thấp nhất của từng SKU trong sản phẩm kể từ mốc thời gian (chưa tính giá hiện tại).
+	// old_price của lần đổi đầu tiên trong khoảng chính là giá đang áp dụng ở đầu khoảng.
+	ListSKULowestPricesSince(ctx context.Context, arg ListSKULowestPricesSinceParams) ([]ListSKULowestPricesSinceRow, error)
 	ListSKUOptionValuesByProductID(ctx context.Context, productID string) ([]SkuAttr, error)
+	ListSKUPriceHistory(ctx context.Context, arg ListSKUPriceHistoryParams) ([]SkuPriceHistory, error)
+	// Các lần tăng giá ít nhất min_increase_percent (%) trong khoảng [from_date, to_date), lọc theo shop nếu có
+	ListSKUPriceRaises(ctx context.Context, arg ListSKUPriceRaisesParams) ([]ListSKUPriceRaisesRow, error)
 	ListSKUsByProduct(ctx context.Context, productID string) ([]ProductSku, error)
-	SearchBrandsByName(ctx context.Context, dollar_1 interface{}) ([]Brand, error)
+	// SKU kèm thông tin sản phẩm cho tạo đơn hàng, lấy nhiều SKU trong một truy vấn
+	ListSKUsForOrder(ctx context.Context, skuIds []string) ([]ListSKUsForOrderRow, error)
+	ListShopIDsBySeller(ctx context.Context, userID string) ([]string, error)
+	ReassignProductsBrand(ctx context.Context, arg ReassignProductsBrandParams) error
+	ReassignProductsCategory(ctx context.Context, arg ReassignProductsCategoryParams) error
+	SearchBrandsByName(ctx context.Context, keyword interface{}) ([]Brand, error)
 	SearchCategoriesByName(ctx context.Context, dollar_1 interface{}) ([]Category, error)
 	UpdateBrand(ctx context.Context, arg UpdateBrandParams) error
 	UpdateBrandImage(ctx context.Context, arg UpdateBrandImageParams) error
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) error
+	UpdateCategoryAttribute(ctx context.Context, arg UpdateCategoryAttributeParams) error
 	UpdateCategoryImage(ctx context.Context, arg UpdateCategoryImageParams) error
 	UpdateCategoryParent(ctx context.Context, arg UpdateCategoryParentParams) error
+	UpdateCategoryPath(ctx context.Context, arg UpdateCategoryPathParams) error
+	UpdateCategorySortOrder(ctx context.Context, arg UpdateCategorySortOrderParams) error
 	UpdateOptionValue(ctx context.Context, arg UpdateOptionValueParams) error
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) error
+	UpdateProductAggregates(ctx context.Context, arg UpdateProductAggregatesParams) error
+	// Điểm đánh giá đồng bộ từ order service
+	UpdateProductRating(ctx context.Context, arg UpdateProductRatingParams) error
 	UpdateProductSKU(ctx context.Context, arg UpdateProductSKUParams) error
+	UpsertProductSearchDocument(ctx context.Context, arg UpsertProductSearchDocumentParams) error
 }
 
 var _ Querier = (*Queries)(nil)
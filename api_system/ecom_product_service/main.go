@@ -8,6 +8,7 @@ import (
 	"time"
 
 	config_assets "github.com/TranVinhHien/ecom_product_service/assets/config"
+	assets_firebase "github.com/TranVinhHien/ecom_product_service/assets/fire-base"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
 	"github.com/TranVinhHien/ecom_product_service/controllers"
 	db "github.com/TranVinhHien/ecom_product_service/db/mysql"
@@ -63,10 +64,19 @@ func main() {
 
 	//setup redis Options
 	redisdb := redis_db.NewRedisDB(rdb)
+	// create firebase client (optional) để gửi thông báo cho shop
+	var firebase *assets_firebase.FirebaseMessaging
+	if env.FirebaseCredentials != "" {
+		firebase, err = assets_firebase.NewFirebase(context.Background(), env.FirebaseCredentials)
+		if err != nil {
+			log.Err(err).Msg("Error create firebase, notifications are disabled")
+			firebase = nil
+		}
+	}
 	// setup service
-	services := services.NewService(db, jwtMaker, env, redisdb, APIServer)
+	services := services.NewService(db, jwtMaker, env, redisdb, APIServer, firebase)
 	// setup controller
-	controller := controllers.NewAPIController(services, jwtMaker)
+	controller := controllers.NewAPIController(services, jwtMaker, env.TokenSystem)
 
 	engine := gin.Default()
 	engine.MaxMultipartMemory = 32 << 20 // 32 MB
@@ -96,6 +106,9 @@ func main() {
 
 	// start jobs
 	go redisdb.RemoveTokenExp(redis_db.BLACK_LIST)
+	go services.StartSearchIndexSync(context.Background())
+	go services.StartSuggestIndexSync(context.Background())
+	go services.StartMediaReaper(context.Background())
 	// go job.NewJob(1, func() {
 	// 	services.NotiNewDiscount(context.Background())
 	// })
@@ -17,9 +17,11 @@ type OrderServer struct {
 
 // ProductRatingStatsItem represents rating statistics for a single product
 type ProductRatingStatsItem struct {
-	ProductID     string  `json:"product_id"`
-	TotalReviews  int64   `json:"total_reviews"`
-	AverageRating float64 `json:"average_rating"`
+	ProductID     string           `json:"product_id"`
+	TotalReviews  int64            `json:"total_reviews"`
+	AverageRating float64          `json:"average_rating"`
+	BayesianScore float64          `json:"bayesian_score"`
+	Distribution  map[string]int64 `json:"distribution"`
 }
 
 // GetProductTotalSold
@@ -187,3 +189,57 @@ func (c OrderServer) GetProductTotalSold(ctx context.Context, productIDs []strin
 
 	return result, nil
 }
+
+// CommentMediaReferencesResponse danh sách file media còn được bình luận sử dụng
+type CommentMediaReferencesResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+	Result  struct {
+		Data []string `json:"data"`
+	} `json:"result"`
+}
+
+// ListCommentMediaReferences hỏi order service những file nào trong danh sách vẫn còn được bình luận sử dụng,
+// token là TOKEN_SYSTEM vì API này chỉ cho service nội bộ hoặc admin gọi
+func (c OrderServer) ListCommentMediaReferences(ctx context.Context, token string, files []string) ([]string, error) {
+	if len(files) == 0 {
+		return []string{}, nil
+	}
+
+	jsonData, err := json.Marshal(map[string][]string{"files": files})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/comments/media-references", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(responseBody))
+	}
+
+	var apiResp CommentMediaReferencesResponse
+	if err := json.Unmarshal(responseBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if apiResp.Status != "success" {
+		return nil, fmt.Errorf("API returned error: %s", apiResp.Message)
+	}
+	return apiResp.Result.Data, nil
+}
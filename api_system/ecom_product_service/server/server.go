@@ -23,6 +23,7 @@ type ApiServer interface {
 	RemoveImage(token string, imageURLs []string) error
 	GetProductTotalSold(ctx context.Context, productIDs []string) (map[string]server_order.GetProductTotalSold, error)
 	GetBulkProductRatingStats(productIDs []string) (map[string]server_order.ProductRatingStatsItem, error)
+	ListCommentMediaReferences(ctx context.Context, token string, files []string) ([]string, error)
 }
 
 func NewAPIServices(config config_assets.ReadENV, timeout time.Duration) ApiServer {
@@ -53,3 +54,8 @@ func (c apiClient) GetBulkProductRatingStats(productIDs []string) (map[string]se
 func (c apiClient) GetProductTotalSold(ctx context.Context, productIDs []string) (map[string]server_order.GetProductTotalSold, error) {
 	return c.order.GetProductTotalSold(ctx, productIDs)
 }
+
+// ListCommentMediaReferences lấy các file media còn được bình luận bên order service sử dụng
+func (c apiClient) ListCommentMediaReferences(ctx context.Context, token string, files []string) ([]string, error) {
+	return c.order.ListCommentMediaReferences(ctx, token, files)
+}
@@ -0,0 +1,70 @@
+package assets_services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Cursor vị trí của bản ghi mốc khi phân trang keyset.
+// Key là giá trị cột sắp xếp của bản ghi (dạng chuỗi), ID dùng để phân định các bản ghi trùng Key.
+type Cursor struct {
+	Sort     string `json:"s"`
+	Key      string `json:"k"`
+	ID       string `json:"i"`
+	Backward bool   `json:"b,omitempty"` // true: lấy trang phía trước bản ghi mốc
+	Null     bool   `json:"n,omitempty"` // true: cột sắp xếp của bản ghi mốc là NULL, Key bị bỏ qua
+}
+
+var ErrInvalidCursor = errors.New("cursor không hợp lệ")
+
+// EncodeCursor mã hóa cursor thành chuỗi base64url kèm chữ ký HMAC để client không sửa được
+func EncodeCursor(secret string, c Cursor) string {
+	payload, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signCursor(secret, payload))
+}
+
+func DecodeCursor(secret, token string) (Cursor, error) {
+	var c Cursor
+	payloadPart, sigPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil || !hmac.Equal(sig, signCursor(secret, payload)) {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, &c); err != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func signCursor(secret string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)[:16]
+}
+
+// KeysetPage cắt danh sách đã lấy dư một bản ghi (LIMIT limit+1) thành một trang theo thứ tự hiển thị.
+// cursor nil là trang đầu. Khi đi lùi, truy vấn đọc ngược chiều sắp xếp nên kết quả được đảo lại.
+func KeysetPage[T any](items []T, limit int, cursor *Cursor) (page []T, hasNext, hasPrev bool) {
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if cursor == nil || !cursor.Backward {
+		return items, more, cursor != nil
+	}
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items, true, more
+}
@@ -0,0 +1,46 @@
+package assets_services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{Sort: "price_asc", Key: "150000", ID: "p-1", Backward: true}
+	token := EncodeCursor("secret", c)
+
+	decoded, err := DecodeCursor("secret", token)
+	require.NoError(t, err)
+	require.Equal(t, c, decoded)
+
+	// sai khóa hoặc bị sửa nội dung thì không chấp nhận
+	_, err = DecodeCursor("other", token)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+	payload, sig, _ := strings.Cut(token, ".")
+	_, err = DecodeCursor("secret", payload[:len(payload)-2]+"xx."+sig)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = DecodeCursor("secret", "abc")
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestKeysetPage(t *testing.T) {
+	// trang đầu, còn trang sau
+	page, hasNext, hasPrev := KeysetPage([]int{1, 2, 3}, 2, nil)
+	require.Equal(t, []int{1, 2}, page)
+	require.True(t, hasNext)
+	require.False(t, hasPrev)
+
+	// đi tiếp tới trang cuối
+	page, hasNext, hasPrev = KeysetPage([]int{5}, 2, &Cursor{ID: "4"})
+	require.Equal(t, []int{5}, page)
+	require.False(t, hasNext)
+	require.True(t, hasPrev)
+
+	// đi lùi: truy vấn đọc ngược chiều nên kết quả được đảo lại
+	page, hasNext, hasPrev = KeysetPage([]int{4, 3, 2}, 2, &Cursor{ID: "5", Backward: true})
+	require.Equal(t, []int{3, 4}, page)
+	require.True(t, hasNext)
+	require.True(t, hasPrev)
+}
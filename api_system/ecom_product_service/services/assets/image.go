@@ -0,0 +1,154 @@
+package assets_services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	_ "golang.org/x/image/webp"
+)
+
+// các định dạng ảnh được phép upload, nhận diện theo magic bytes chứ không theo Content-Type/đuôi file của client
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+var ErrUnsupportedImage = errors.New("định dạng ảnh không được hỗ trợ")
+
+// DetectImageType nhận diện loại ảnh từ nội dung file, trả về MIME type và đuôi file dùng khi lưu
+func DetectImageType(data []byte) (mimeType, ext string, err error) {
+	mimeType = http.DetectContentType(data)
+	ext, ok := allowedImageTypes[mimeType]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnsupportedImage, mimeType)
+	}
+	return mimeType, ext, nil
+}
+
+// ImageSize đọc kích thước ảnh từ header, không giải mã toàn bộ ảnh
+func ImageSize(mimeType string, data []byte) (width, height int, err error) {
+	if mimeType == "image/webp" {
+		return webpSize(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("không đọc được kích thước ảnh: %w", err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpSize đọc kích thước từ header RIFF của ảnh WebP (VP8, VP8L hoặc VP8X)
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, fmt.Errorf("header WebP không hợp lệ")
+	}
+	switch string(data[12:16]) {
+	case "VP8X":
+		w := int(data[24]) | int(data[25])<<8 | int(data[26])<<16
+		h := int(data[27]) | int(data[28])<<8 | int(data[29])<<16
+		return w + 1, h + 1, nil
+	case "VP8 ":
+		if data[23] != 0x9d || data[24] != 0x01 || data[25] != 0x2a {
+			return 0, 0, fmt.Errorf("header WebP VP8 không hợp lệ")
+		}
+		w := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+		return w, h, nil
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0, fmt.Errorf("header WebP VP8L không hợp lệ")
+		}
+		b := binary.LittleEndian.Uint32(data[21:25])
+		return int(b&0x3fff) + 1, int((b>>14)&0x3fff) + 1, nil
+	}
+	return 0, 0, fmt.Errorf("header WebP không hợp lệ")
+}
+
+// ResizeImage thu nhỏ ảnh để cạnh dài nhất không vượt quá maxSide (lọc trung bình vùng),
+// phần trong suốt được phủ nền trắng để lưu được dạng JPEG. Ảnh nhỏ hơn maxSide giữ nguyên kích thước.
+func ResizeImage(src image.Image, maxSide int) *image.RGBA {
+	rgba := toRGBA(src)
+	sw, sh := rgba.Rect.Dx(), rgba.Rect.Dy()
+	dw, dh := sw, sh
+	if sw > maxSide || sh > maxSide {
+		if sw >= sh {
+			dw, dh = maxSide, max(1, sh*maxSide/sw)
+		} else {
+			dw, dh = max(1, sw*maxSide/sh), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, b, a, n uint64
+			for yy := y0; yy < y1; yy++ {
+				off := rgba.PixOffset(rgba.Rect.Min.X+x0, rgba.Rect.Min.Y+yy)
+				for xx := x0; xx < x1; xx++ {
+					r += uint64(rgba.Pix[off])
+					g += uint64(rgba.Pix[off+1])
+					b += uint64(rgba.Pix[off+2])
+					a += uint64(rgba.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			// màu đã nhân alpha (premultiplied) nên chỉ cần cộng thêm phần nền trắng còn thiếu
+			white := 255*n - a
+			d := dst.PixOffset(x, y)
+			dst.Pix[d] = uint8((r + white) / n)
+			dst.Pix[d+1] = uint8((g + white) / n)
+			dst.Pix[d+2] = uint8((b + white) / n)
+			dst.Pix[d+3] = 255
+		}
+	}
+	return dst
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok {
+		return rgba
+	}
+	b := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, src, b.Min, draw.Src)
+	return rgba
+}
+
+// EncodeJPEG ghi ảnh dạng JPEG
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
+
+// WriteFile ghi dữ liệu ra file trong thư mục, tạo thư mục nếu chưa có
+func WriteFile(dir, fileName string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("không thể tạo thư mục %s: %w", dir, err)
+	}
+	dstPath := filepath.Join(dir, fileName)
+	if err := os.WriteFile(dstPath, data, 0644); err != nil {
+		return fmt.Errorf("không thể tạo file %s: %w", dstPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package assets_services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectImageType(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	mimeType, ext, err := DetectImageType(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "image/png", mimeType)
+	require.Equal(t, ".png", ext)
+
+	w, h, err := ImageSize(mimeType, buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 3, w)
+	require.Equal(t, 2, h)
+
+	// file đổi đuôi thành .jpg nhưng nội dung là HTML vẫn bị từ chối
+	_, _, err = DetectImageType([]byte("<html><script>alert(1)</script></html>"))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+func TestWebPSize(t *testing.T) {
+	data := make([]byte, 30)
+	copy(data[0:4], "RIFF")
+	copy(data[8:12], "WEBP")
+	copy(data[12:16], "VP8L")
+	data[20] = 0x2f
+	binary.LittleEndian.PutUint32(data[21:25], uint32(640-1)|uint32(480-1)<<14)
+
+	mimeType, _, err := DetectImageType(data)
+	require.NoError(t, err)
+	require.Equal(t, "image/webp", mimeType)
+
+	w, h, err := ImageSize(mimeType, data)
+	require.NoError(t, err)
+	require.Equal(t, 640, w)
+	require.Equal(t, 480, h)
+}
+
+func TestResizeImage(t *testing.T) {
+	// nửa bên trái màu đỏ, nửa bên phải trong suốt
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 200; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	dst := ResizeImage(src, 100)
+	require.Equal(t, 100, dst.Rect.Dx())
+	require.Equal(t, 50, dst.Rect.Dy())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(10, 10))
+	// phần trong suốt được phủ nền trắng
+	require.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, dst.RGBAAt(90, 10))
+
+	// ảnh nhỏ hơn kích thước tối đa giữ nguyên
+	small := ResizeImage(image.NewNRGBA(image.Rect(0, 0, 40, 30)), 100)
+	require.Equal(t, image.Rect(0, 0, 40, 30), small.Rect)
+}
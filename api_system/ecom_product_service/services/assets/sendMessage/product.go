@@ -0,0 +1,24 @@
+package services_assets_sendMessage
+
+import (
+	"firebase.google.com/go/messaging"
+)
+
+// ShopTopic trả về topic firebase mà app của shop đăng ký để nhận thông báo
+func ShopTopic(shopID string) string {
+	return "shop_" + shopID
+}
+
+func SanPhamDaDuyet(productName string) *messaging.Notification {
+	return &messaging.Notification{
+		Title: "Sản phẩm đã được duyệt",
+		Body:  "Sản phẩm \"" + productName + "\" đã được duyệt và đang hiển thị trên sàn.",
+	}
+}
+
+func SanPhamBiTuChoi(productName, reason string) *messaging.Notification {
+	return &messaging.Notification{
+		Title: "Sản phẩm bị từ chối",
+		Body:  "Sản phẩm \"" + productName + "\" không được duyệt. Lý do: " + reason,
+	}
+}
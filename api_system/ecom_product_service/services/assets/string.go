@@ -68,3 +68,30 @@ func ConvertToSlug(input string) (string, error) {
 	slug := strings.ReplaceAll(lower, " ", "_")
 	return slug, nil
 }
+
+// FoldVietnamese bỏ dấu tiếng Việt, chuyển về chữ thường và thay các ký tự không phải chữ/số bằng khoảng trắng.
+// Dùng cho tìm kiếm để "ao thun" khớp với "Áo thun".
+func FoldVietnamese(input string) string {
+	var builder strings.Builder
+	builder.Grow(len(input))
+	space := true
+	for _, r := range input {
+		// dấu dạng tổ hợp (NFD) thì bỏ luôn
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if replacement, exists := vietnameseToASCII[r]; exists {
+			r = replacement
+		}
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			builder.WriteRune(unicode.ToLower(r))
+			space = false
+			continue
+		}
+		if !space {
+			builder.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,22 @@
+package assets_services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoldVietnamese(t *testing.T) {
+	cases := map[string]string{
+		"Áo thun Nữ":                   "ao thun nu",
+		"ĐIỆN THOẠI Samsung":           "dien thoai samsung",
+		"  Giày---thể thao, size 42! ": "giay the thao size 42",
+		// dạng tổ hợp (NFD): dấu là ký tự riêng phải bị bỏ
+		"Áo thường": "ao thuong",
+		"":              "",
+		"!!!":           "",
+	}
+	for input, want := range cases {
+		require.Equal(t, want, FoldVietnamese(input), input)
+	}
+}
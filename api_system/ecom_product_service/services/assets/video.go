@@ -0,0 +1,112 @@
+package assets_services
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// brand của ảnh HEIF/AVIF cũng dùng box ftyp nhưng không phải video
+var imageFtypBrands = map[string]bool{
+	"heic": true, "heix": true, "mif1": true, "msf1": true, "avif": true, "avis": true,
+}
+
+// brand của video MP4, các định dạng ISO BMFF khác (MOV "qt  ", 3GP...) không nhận
+var mp4FtypBrands = map[string]bool{
+	"isom": true, "iso2": true, "iso4": true, "iso5": true, "iso6": true,
+	"mp41": true, "mp42": true, "avc1": true, "M4V ": true,
+}
+
+// DetectVideoType nhận diện video MP4 (định dạng ISO BMFF, có box ftyp) từ nội dung file.
+// http.DetectContentType chỉ nhận major brand "mp4*" nên tự kiểm tra ftyp để nhận cả isom từ điện thoại:
+// major brand hoặc một compatible brand phải là brand MP4.
+func DetectVideoType(data []byte) (mimeType, ext string, err error) {
+	if isMP4Ftyp(data) {
+		return "video/mp4", ".mp4", nil
+	}
+	return "", "", fmt.Errorf("%w: %s", ErrUnsupportedImage, http.DetectContentType(data))
+}
+
+func isMP4Ftyp(data []byte) bool {
+	ftyp, ok := findMP4Box(data, "ftyp")
+	// ftyp phải là box đầu tiên: major brand(4) minor version(4) rồi tới các compatible brand
+	if !ok || len(data) < 8 || string(data[4:8]) != "ftyp" || len(ftyp) < 8 {
+		return false
+	}
+	major := string(ftyp[0:4])
+	if imageFtypBrands[major] {
+		return false
+	}
+	if mp4FtypBrands[major] {
+		return true
+	}
+	for i := 8; i+4 <= len(ftyp); i += 4 {
+		if mp4FtypBrands[string(ftyp[i:i+4])] {
+			return true
+		}
+	}
+	return false
+}
+
+// MP4Duration đọc thời lượng video từ box moov/mvhd, không giải mã video
+func MP4Duration(data []byte) (time.Duration, error) {
+	moov, ok := findMP4Box(data, "moov")
+	if !ok {
+		return 0, fmt.Errorf("không tìm thấy box moov trong file MP4")
+	}
+	mvhd, ok := findMP4Box(moov, "mvhd")
+	if !ok || len(mvhd) < 4 {
+		return 0, fmt.Errorf("không tìm thấy box mvhd trong file MP4")
+	}
+	var timescale uint32
+	var duration uint64
+	// mvhd: version(1) flags(3), sau đó thời gian tạo/sửa 4 hoặc 8 byte tùy version
+	switch mvhd[0] {
+	case 0:
+		if len(mvhd) < 20 {
+			return 0, fmt.Errorf("box mvhd không hợp lệ")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[12:16])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	case 1:
+		if len(mvhd) < 32 {
+			return 0, fmt.Errorf("box mvhd không hợp lệ")
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:24])
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	default:
+		return 0, fmt.Errorf("phiên bản mvhd không hỗ trợ: %d", mvhd[0])
+	}
+	if timescale == 0 {
+		return 0, fmt.Errorf("box mvhd không hợp lệ")
+	}
+	return time.Duration(duration) * time.Second / time.Duration(timescale), nil
+}
+
+// findMP4Box tìm box con cùng cấp theo tên, trả về phần nội dung (bỏ header)
+func findMP4Box(data []byte, name string) ([]byte, bool) {
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[0:4]))
+		boxType := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0: // box kéo dài tới hết file
+			size = uint64(len(data))
+		case 1: // kích thước 64 bit nằm sau tên box
+			if len(data) < 16 {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return nil, false
+		}
+		if boxType == name {
+			return data[header:size], true
+		}
+		data = data[size:]
+	}
+	return nil, false
+}
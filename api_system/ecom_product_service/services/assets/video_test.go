@@ -0,0 +1,59 @@
+package assets_services
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mp4Box(name string, payload []byte) []byte {
+	box := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(box[0:4], uint32(8+len(payload)))
+	copy(box[4:8], name)
+	return append(box, payload...)
+}
+
+func TestMP4Duration(t *testing.T) {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)  // timescale
+	binary.BigEndian.PutUint32(mvhd[16:20], 15500) // 15.5 giây
+	data := mp4Box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	data = append(data, mp4Box("free", nil)...)
+	data = append(data, mp4Box("moov", mp4Box("mvhd", mvhd))...)
+
+	mimeType, ext, err := DetectVideoType(data)
+	require.NoError(t, err)
+	require.Equal(t, "video/mp4", mimeType)
+	require.Equal(t, ".mp4", ext)
+
+	d, err := MP4Duration(data)
+	require.NoError(t, err)
+	require.Equal(t, 15500*time.Millisecond, d)
+
+	// thiếu moov (file bị cắt) thì báo lỗi
+	_, err = MP4Duration(data[:len(data)-20])
+	require.Error(t, err)
+
+	// ảnh HEIC không được nhận là video
+	_, _, err = DetectVideoType(mp4Box("ftyp", []byte("heic\x00\x00\x00\x00mif1heic")))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+
+	// video MOV từ iPhone và 3GP không phải MP4
+	_, _, err = DetectVideoType(mp4Box("ftyp", []byte("qt  \x00\x00\x02\x00qt  ")))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+	_, _, err = DetectVideoType(mp4Box("ftyp", []byte("3gp5\x00\x00\x00\x003gp5")))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+}
+
+func TestDetectVideoTypeCompatibleBrand(t *testing.T) {
+	// major brand của hãng máy, nhưng khai báo tương thích mp42
+	_, ext, err := DetectVideoType(mp4Box("ftyp", []byte("MSNV\x01\x29\x00\x46MSNVmp42isom")))
+	require.NoError(t, err)
+	require.Equal(t, ".mp4", ext)
+
+	// box ftyp bị cắt
+	_, _, err = DetectVideoType([]byte("\x00\x00\x00\x18ftypmp42"))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+}
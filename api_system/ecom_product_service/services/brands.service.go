@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"sort"
+	"strings"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+func toBrandEntity(brand db.Brand) services.Brand {
+	return services.Brand{
+		BrandID: brand.BrandID,
+		Name:    brand.Name,
+		Code:    brand.Code,
+		Image:   services.Narg[string]{Valid: brand.Image.Valid, Data: brand.Image.String},
+	}
+}
+
+// loadBrands lấy danh sách thương hiệu từ redis, nếu cache trống thì đọc DB và nạp lại cache
+func (s *service) loadBrands(ctx context.Context) ([]services.Brand, error) {
+	brands, err := s.redis.GetBrands(ctx)
+	if err != nil {
+		fmt.Println("Error GetBrands:", err)
+	}
+	if len(brands) > 0 {
+		return brands, nil
+	}
+	return s.refreshBrandCache(ctx)
+}
+
+// refreshBrandCache đọc lại toàn bộ thương hiệu từ DB và ghi đè cache
+func (s *service) refreshBrandCache(ctx context.Context) ([]services.Brand, error) {
+	rows, err := s.repository.ListBrands(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("không thể lấy danh sách thương hiệu: %w", err)
+	}
+	brands := make([]services.Brand, 0, len(rows))
+	for _, b := range rows {
+		brands = append(brands, toBrandEntity(b))
+	}
+	if err := s.redis.AddBrands(ctx, brands); err != nil {
+		// cache lỗi không làm hỏng request, lần đọc sau sẽ nạp lại
+		fmt.Println("Error AddBrands:", err)
+	}
+	return brands, nil
+}
+
+func (s *service) ListBrands(ctx context.Context, query services.QueryFilter) (map[string]interface{}, *assets_services.ServiceError) {
+	if query.Page < 1 || query.PageSize < 1 {
+		return nil, assets_services.NewError(400, fmt.Errorf("page và limit phải lớn hơn 0"))
+	}
+	brands, err := s.loadBrands(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(400, err)
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		return strings.ToLower(brands[i].Name) < strings.ToLower(brands[j].Name)
+	})
+
+	totalElements := len(brands)
+	start := (query.Page - 1) * query.PageSize
+	if start > totalElements {
+		start = totalElements
+	}
+	end := start + query.PageSize
+	if end > totalElements {
+		end = totalElements
+	}
+
+	result := make(map[string]interface{})
+	result["data"] = brands[start:end]
+	result["currentPage"] = query.Page
+	result["totalPages"] = int64(math.Ceil(float64(totalElements) / float64(query.PageSize)))
+	result["totalElements"] = totalElements
+	result["limit"] = query.PageSize
+	return result, nil
+}
+
+func (s *service) SearchBrands(ctx context.Context, keyword string) (map[string]interface{}, *assets_services.ServiceError) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, assets_services.NewError(400, fmt.Errorf("phải nhập từ khóa tìm kiếm thương hiệu"))
+	}
+	rows, err := s.repository.SearchBrandsByName(ctx, keyword)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể tìm kiếm thương hiệu. Lỗi: %v", err))
+	}
+	brands := make([]services.Brand, 0, len(rows))
+	for _, b := range rows {
+		brands = append(brands, toBrandEntity(b))
+	}
+	return map[string]interface{}{"data": brands}, nil
+}
+
+// GetBrandDetail nhận brand_id hoặc code của thương hiệu
+func (s *service) GetBrandDetail(ctx context.Context, key string) (map[string]interface{}, *assets_services.ServiceError) {
+	brand, err := s.redis.GetBrand(ctx, key)
+	if err != nil {
+		fmt.Println("Error GetBrand:", err)
+	}
+	if brand == nil {
+		row, err := s.repository.GetBrand(ctx, key)
+		if err == sql.ErrNoRows {
+			row, err = s.repository.GetBrandByCode(ctx, key)
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, assets_services.NewError(404, fmt.Errorf("thương hiệu không tồn tại"))
+			}
+			return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin thương hiệu: %w", err))
+		}
+		b := toBrandEntity(row)
+		brand = &b
+	}
+
+	totalProducts, err := s.repository.CountProductsByBrand(ctx, sql.NullString{String: brand.BrandID, Valid: true})
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể đếm sản phẩm của thương hiệu. Lỗi: %v", err))
+	}
+	result := struct {
+		services.Brand
+		TotalProducts int64 `json:"total_products"`
+	}{
+		Brand:         *brand,
+		TotalProducts: totalProducts,
+	}
+	return map[string]interface{}{"data": result}, nil
+}
+
+func (s *service) CreateBrand(ctx context.Context, userName string, brand services.Brand, image *multipart.FileHeader) *assets_services.ServiceError {
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Name == "" {
+		return assets_services.NewError(400, fmt.Errorf("tên thương hiệu không được để trống"))
+	}
+	if brand.Code == "" {
+		code, err := assets_services.ConvertToSlug(brand.Name)
+		if err != nil {
+			return assets_services.NewError(400, fmt.Errorf("tên thương hiệu không hợp lệ: %v", err))
+		}
+		brand.Code = code
+	}
+	if _, err := s.repository.GetBrandByCode(ctx, brand.Code); err == nil {
+		return assets_services.NewError(409, fmt.Errorf("mã thương hiệu '%s' đã tồn tại", brand.Code))
+	} else if err != sql.ErrNoRows {
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi kiểm tra mã thương hiệu: %w", err))
+	}
+
+	var imageUrl string
+	if image != nil {
+		uploaded, errUpload := s.UploadMultiMedia(ctx, userName, []*multipart.FileHeader{image})
+		if errUpload != nil {
+			return assets_services.NewError(500, fmt.Errorf("không thể upload ảnh thương hiệu. Lỗi: %v", errUpload))
+		}
+		imageUrl = uploaded[0]
+	}
+
+	err := s.repository.CreateBrand(ctx, db.CreateBrandParams{
+		BrandID: uuid.New().String(),
+		Name:    brand.Name,
+		Code:    brand.Code,
+		Image:   sql.NullString{String: imageUrl, Valid: imageUrl != ""},
+	})
+	if err != nil {
+		if imageUrl != "" {
+			s.DeleteMultiImage(ctx, userName, []string{imageUrl})
+		}
+		return assets_services.NewError(400, fmt.Errorf("không thể tạo thương hiệu. Lỗi: %v", err))
+	}
+
+	if _, err := s.refreshBrandCache(ctx); err != nil {
+		fmt.Println("Error refreshBrandCache after create:", err)
+	}
+	return nil
+}
+
+func (s *service) UpdateBrand(ctx context.Context, userName string, brand services.Brand, image *multipart.FileHeader) *assets_services.ServiceError {
+	if brand.BrandID == "" {
+		return assets_services.NewError(400, fmt.Errorf("brand_id is required"))
+	}
+	current, err := s.repository.GetBrand(ctx, brand.BrandID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(404, fmt.Errorf("thương hiệu không tồn tại"))
+		}
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin thương hiệu: %w", err))
+	}
+	brand.Name = strings.TrimSpace(brand.Name)
+	if brand.Code != "" && brand.Code != current.Code {
+		if _, err := s.repository.GetBrandByCode(ctx, brand.Code); err == nil {
+			return assets_services.NewError(409, fmt.Errorf("mã thương hiệu '%s' đã tồn tại", brand.Code))
+		} else if err != sql.ErrNoRows {
+			return assets_services.NewError(400, fmt.Errorf("lỗi khi kiểm tra mã thương hiệu: %w", err))
+		}
+	}
+
+	var imageUrl string
+	if image != nil {
+		uploaded, errUpload := s.UploadMultiMedia(ctx, userName, []*multipart.FileHeader{image})
+		if errUpload != nil {
+			return assets_services.NewError(500, fmt.Errorf("không thể upload ảnh thương hiệu. Lỗi: %v", errUpload))
+		}
+		imageUrl = uploaded[0]
+	}
+
+	err = s.repository.UpdateBrand(ctx, db.UpdateBrandParams{
+		BrandID: brand.BrandID,
+		Name:    sql.NullString{String: brand.Name, Valid: brand.Name != ""},
+		Code:    sql.NullString{String: brand.Code, Valid: brand.Code != ""},
+		Image:   sql.NullString{String: imageUrl, Valid: imageUrl != ""},
+	})
+	if err != nil {
+		if imageUrl != "" {
+			s.DeleteMultiImage(ctx, userName, []string{imageUrl})
+		}
+		return assets_services.NewError(400, fmt.Errorf("không thể cập nhật thương hiệu. Lỗi: %v", err))
+	}
+	// chỉ xóa ảnh cũ sau khi cập nhật DB thành công
+	if imageUrl != "" && current.Image.Valid && current.Image.String != "" {
+		s.DeleteMultiImage(ctx, userName, []string{current.Image.String})
+	}
+
+	if _, err := s.refreshBrandCache(ctx); err != nil {
+		fmt.Println("Error refreshBrandCache after update:", err)
+	}
+	// tên và mã thương hiệu nằm trong tài liệu tìm kiếm và chi tiết sản phẩm
+	if (brand.Name != "" && brand.Name != current.Name) || (brand.Code != "" && brand.Code != current.Code) {
+		s.refreshBrandProducts(ctx, brand.BrandID)
+	}
+	return nil
+}
+
+// DeleteBrand xóa thương hiệu. Nếu còn sản phẩm tham chiếu thì phải truyền reassignTo
+// để chuyển các sản phẩm đó sang thương hiệu khác trong cùng transaction.
+// Dòng thương hiệu bị khóa trước khi đếm sản phẩm nên sản phẩm gắn vào thương hiệu trong lúc xóa
+// (khóa ngoại product.brand_id) phải chờ transaction kết thúc.
+func (s *service) DeleteBrand(ctx context.Context, userName, brandID, reassignTo string) *assets_services.ServiceError {
+	if reassignTo == brandID {
+		return assets_services.NewError(400, fmt.Errorf("không thể chuyển sản phẩm sang chính thương hiệu đang xóa"))
+	}
+
+	var current db.Brand
+	var reassigned []string
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		var err error
+		current, err = tx.GetBrandForUpdate(ctx, brandID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return assets_services.NewError(404, fmt.Errorf("thương hiệu không tồn tại"))
+			}
+			return fmt.Errorf("lỗi khi lấy thông tin thương hiệu: %w", err)
+		}
+		totalProducts, err := tx.CountProductsByBrand(ctx, sql.NullString{String: brandID, Valid: true})
+		if err != nil {
+			return fmt.Errorf("không thể đếm sản phẩm của thương hiệu: %w", err)
+		}
+		if totalProducts > 0 {
+			if reassignTo == "" {
+				return assets_services.NewError(409, fmt.Errorf("thương hiệu đang được %d sản phẩm sử dụng, hãy chọn thương hiệu thay thế", totalProducts))
+			}
+			if _, err := tx.GetBrand(ctx, reassignTo); err != nil {
+				if err == sql.ErrNoRows {
+					return assets_services.NewError(404, fmt.Errorf("thương hiệu thay thế không tồn tại"))
+				}
+				return fmt.Errorf("lỗi khi lấy thông tin thương hiệu thay thế: %w", err)
+			}
+			// lấy danh sách sản phẩm bị chuyển thương hiệu để dựng lại tài liệu tìm kiếm và bỏ cache sau khi commit
+			reassigned, err = tx.ListProductIDsByBrand(ctx, sql.NullString{String: brandID, Valid: true})
+			if err != nil {
+				return fmt.Errorf("không thể lấy sản phẩm của thương hiệu: %w", err)
+			}
+			err = tx.ReassignProductsBrand(ctx, db.ReassignProductsBrandParams{
+				NewBrandID: sql.NullString{String: reassignTo, Valid: true},
+				OldBrandID: sql.NullString{String: brandID, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("không thể chuyển sản phẩm sang thương hiệu mới: %w", err)
+			}
+		}
+		return tx.DeleteBrand(ctx, brandID)
+	})
+	if txErr != nil {
+		var serr *assets_services.ServiceError
+		if errors.As(txErr, &serr) {
+			return serr
+		}
+		return assets_services.NewError(400, fmt.Errorf("không thể xóa thương hiệu. Lỗi: %v", txErr))
+	}
+	if current.Image.Valid && current.Image.String != "" {
+		s.DeleteMultiImage(ctx, userName, []string{current.Image.String})
+	}
+
+	if _, err := s.refreshBrandCache(ctx); err != nil {
+		fmt.Println("Error refreshBrandCache after delete:", err)
+	}
+	s.invalidateProductDetail(ctx, reassigned)
+	s.refreshProductSearchAsync(reassigned)
+	return nil
+}
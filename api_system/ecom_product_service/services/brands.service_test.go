@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// brandRedis trả danh sách thương hiệu có sẵn và ghi lại các sản phẩm bị bỏ cache chi tiết
+type brandRedis struct {
+	ServicesRedis
+	mu          sync.Mutex
+	brands      []services.Brand
+	invalidated []string
+}
+
+func (f *brandRedis) GetBrands(ctx context.Context) ([]services.Brand, error) {
+	return append([]services.Brand(nil), f.brands...), nil
+}
+
+func (f *brandRedis) AddBrands(ctx context.Context, brands []services.Brand) error {
+	return nil
+}
+
+func (f *brandRedis) InvalidateProductDetail(ctx context.Context, productIDs []string, slugs ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invalidated = append(f.invalidated, productIDs...)
+	return nil
+}
+
+// brandTx giữ thương hiệu và brand_id của sản phẩm trong bộ nhớ, ghi lại thứ tự câu lệnh
+type brandTx struct {
+	db.Querier
+	brands   map[string]db.Brand
+	products map[string]string // product_id -> brand_id
+	calls    []string
+}
+
+func (f *brandTx) GetBrandForUpdate(ctx context.Context, brandID string) (db.Brand, error) {
+	f.calls = append(f.calls, "lock")
+	return f.GetBrand(ctx, brandID)
+}
+
+func (f *brandTx) GetBrand(ctx context.Context, brandID string) (db.Brand, error) {
+	b, ok := f.brands[brandID]
+	if !ok {
+		return db.Brand{}, sql.ErrNoRows
+	}
+	return b, nil
+}
+
+func (f *brandTx) CountProductsByBrand(ctx context.Context, brandID sql.NullString) (int64, error) {
+	f.calls = append(f.calls, "count")
+	ids, _ := f.ListProductIDsByBrand(ctx, brandID)
+	return int64(len(ids)), nil
+}
+
+func (f *brandTx) ListProductIDsByBrand(ctx context.Context, brandID sql.NullString) ([]string, error) {
+	var ids []string
+	for id, b := range f.products {
+		if b == brandID.String {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *brandTx) ReassignProductsBrand(ctx context.Context, arg db.ReassignProductsBrandParams) error {
+	f.calls = append(f.calls, "reassign")
+	for id, b := range f.products {
+		if b == arg.OldBrandID.String {
+			f.products[id] = arg.NewBrandID.String
+		}
+	}
+	return nil
+}
+
+func (f *brandTx) DeleteBrand(ctx context.Context, brandID string) error {
+	f.calls = append(f.calls, "delete")
+	delete(f.brands, brandID)
+	return nil
+}
+
+type brandStore struct {
+	db_mysql.Store
+	tx *brandTx
+}
+
+func (f *brandStore) ExecTS(ctx context.Context, fn func(tx db.Querier) error) error {
+	return fn(f.tx)
+}
+
+func (f *brandStore) ListBrands(ctx context.Context) ([]db.Brand, error) {
+	return nil, nil
+}
+
+// GetProduct dùng cho việc dựng lại tài liệu tìm kiếm chạy nền, không cần dữ liệu thật
+func (f *brandStore) GetProduct(ctx context.Context, id string) (db.GetProductRow, error) {
+	return db.GetProductRow{}, sql.ErrNoRows
+}
+
+func TestListBrandsPaging(t *testing.T) {
+	brands := make([]services.Brand, 5)
+	for i := range brands {
+		brands[i] = services.Brand{BrandID: fmt.Sprintf("b-%d", i), Name: fmt.Sprintf("Brand %d", i)}
+	}
+	s := &service{redis: &brandRedis{brands: brands}}
+
+	tests := []struct {
+		page, limit int
+		code        int
+		wantLen     int
+		totalPages  int64
+	}{
+		{1, 2, 0, 2, 3},
+		{3, 2, 0, 1, 3},
+		// trang vượt quá số thương hiệu trả về rỗng
+		{4, 2, 0, 0, 3},
+		{1, 100, 0, 5, 1},
+		{0, 2, 400, 0, 0},
+		{-1, 2, 400, 0, 0},
+		{1, 0, 400, 0, 0},
+		{1, -5, 400, 0, 0},
+	}
+	for _, tt := range tests {
+		result, serr := s.ListBrands(context.Background(), services.NewQueryFilter(tt.page, tt.limit, nil, nil))
+		if tt.code != 0 {
+			require.NotNil(t, serr, "page=%d limit=%d", tt.page, tt.limit)
+			require.Equal(t, tt.code, serr.Code)
+			continue
+		}
+		require.Nil(t, serr, "page=%d limit=%d", tt.page, tt.limit)
+		require.Len(t, result["data"], tt.wantLen, "page=%d limit=%d", tt.page, tt.limit)
+		require.Equal(t, tt.totalPages, result["totalPages"], "page=%d limit=%d", tt.page, tt.limit)
+		require.Equal(t, 5, result["totalElements"])
+	}
+}
+
+func newBrandTx() *brandTx {
+	return &brandTx{
+		brands: map[string]db.Brand{
+			"b-old": {BrandID: "b-old", Name: "Old"},
+			"b-new": {BrandID: "b-new", Name: "New"},
+		},
+		products: map[string]string{"p-1": "b-old", "p-2": "b-old", "p-3": "b-new"},
+	}
+}
+
+func TestDeleteBrandReassign(t *testing.T) {
+	tx := newBrandTx()
+	cache := &brandRedis{}
+	s := &service{repository: &brandStore{tx: tx}, redis: cache}
+
+	serr := s.DeleteBrand(context.Background(), "admin", "b-old", "b-new")
+	require.Nil(t, serr)
+	// khóa thương hiệu rồi mới đếm sản phẩm, tất cả trong cùng transaction
+	require.Equal(t, []string{"lock", "count", "reassign", "delete"}, tx.calls)
+	require.Equal(t, map[string]string{"p-1": "b-new", "p-2": "b-new", "p-3": "b-new"}, tx.products)
+	require.NotContains(t, tx.brands, "b-old")
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	require.ElementsMatch(t, []string{"p-1", "p-2"}, cache.invalidated)
+}
+
+func TestDeleteBrandRejected(t *testing.T) {
+	ctx := context.Background()
+	tx := newBrandTx()
+	cache := &brandRedis{}
+	s := &service{repository: &brandStore{tx: tx}, redis: cache}
+
+	// còn sản phẩm mà không chọn thương hiệu thay thế
+	serr := s.DeleteBrand(ctx, "admin", "b-old", "")
+	require.NotNil(t, serr)
+	require.Equal(t, 409, serr.Code)
+
+	serr = s.DeleteBrand(ctx, "admin", "b-old", "b-missing")
+	require.NotNil(t, serr)
+	require.Equal(t, 404, serr.Code)
+
+	serr = s.DeleteBrand(ctx, "admin", "b-missing", "b-new")
+	require.NotNil(t, serr)
+	require.Equal(t, 404, serr.Code)
+
+	serr = s.DeleteBrand(ctx, "admin", "b-old", "b-old")
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+
+	require.NotContains(t, tx.calls, "delete")
+	require.Contains(t, tx.brands, "b-old")
+	require.Empty(t, cache.invalidated)
+}
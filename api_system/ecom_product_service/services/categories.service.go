@@ -3,8 +3,12 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"mime/multipart"
+	"strings"
+	"time"
 
 	util_assets "github.com/TranVinhHien/ecom_product_service/assets/util"
 	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
@@ -12,6 +16,9 @@ import (
 	services "github.com/TranVinhHien/ecom_product_service/services/entity"
 )
 
+// số sản phẩm của danh mục chỉ cần gần đúng nên sản phẩm mới/xóa được phản ánh sau tối đa 5 phút
+const categoryProductCountTTL = 5 * time.Minute
+
 func (s *service) GetCategoris(ctx context.Context, cate_id string) (map[string]interface{}, *assets_services.ServiceError) {
 
 	caterd, err := s.redis.GetCategoryTree(ctx, cate_id)
@@ -20,25 +27,8 @@ func (s *service) GetCategoris(ctx context.Context, cate_id string) (map[string]
 		return nil, assets_services.NewError(400, err)
 	}
 	if len(caterd) == 0 {
-		cates, err := s.repository.ListCategories(ctx)
-		list_cate := make([]services.Categorys, 0)
-		for _, cat := range cates {
-			list_cate = append(list_cate, services.Categorys{
-				CategoryID: cat.CategoryID,
-				Name:       cat.Name,
-				Parent:     services.Narg[string]{Valid: cat.Parent.Valid, Data: cat.Parent.String},
-				Key:        cat.Key,
-				Path:       cat.Path.String,
-			})
-		}
-		// cates[0].
-		if err != nil {
-			fmt.Println("Error ListCategories:", err)
-			return nil, assets_services.NewError(400, err)
-		}
-		err = s.redis.AddCategories(ctx, list_cate)
-		if err != nil {
-			fmt.Println("Error AddCategories:", err)
+		if err := s.rebuildCategoryCache(ctx); err != nil {
+			fmt.Println("Error rebuildCategoryCache:", err)
 			return nil, assets_services.NewError(400, err)
 		}
 		caterd, err = s.redis.GetCategoryTree(ctx, cate_id)
@@ -63,6 +53,13 @@ func (s *service) GetCategoris(ctx context.Context, cate_id string) (map[string]
 	// 	}
 	// }
 
+	counts, err := s.categoryProductCounts(ctx)
+	if err != nil {
+		fmt.Println("Error categoryProductCounts:", err)
+		return nil, assets_services.NewError(400, err)
+	}
+	fillCategoryProductCounts(caterd, counts)
+
 	result, err := assets_services.HideFields(caterd, "categories", "parent")
 	if err != nil {
 		fmt.Println("Error HideFields:", err)
@@ -71,151 +68,444 @@ func (s *service) GetCategoris(ctx context.Context, cate_id string) (map[string]
 	return result, nil
 }
 
-// Add a new category: create in DB, refresh redis cache, return category tree
+// categorySubtreeIDs trả về id của danh mục cùng toàn bộ danh mục con (theo tiền tố path)
+func (s *service) categorySubtreeIDs(ctx context.Context, category db.Category) ([]string, error) {
+	ids := []string{category.CategoryID}
+	if !category.Path.Valid || category.Path.String == "" {
+		return ids, nil
+	}
+	descendants, err := s.repository.ListCategoryDescendants(ctx, category.Path.String)
+	if err != nil {
+		return nil, fmt.Errorf("không thể lấy danh mục con: %w", err)
+	}
+	for _, d := range descendants {
+		ids = append(ids, d.CategoryID)
+	}
+	return ids, nil
+}
+
+// categoryProductCounts trả về số sản phẩm đang bán của mỗi danh mục, đã cộng dồn các danh mục con.
+// Kết quả được cache cùng cây danh mục để không GROUP BY bảng product ở mỗi lần xem cây.
+func (s *service) categoryProductCounts(ctx context.Context) (map[string]int64, error) {
+	cached, err := s.redis.GetCategoryProductCounts(ctx)
+	if err != nil {
+		log.Printf("[Category] không thể đọc cache số sản phẩm theo danh mục: %v", err)
+	}
+	if cached != nil {
+		return cached, nil
+	}
+	cates, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("không thể lấy danh sách danh mục: %w", err)
+	}
+	rows, err := s.repository.CountActiveProductsPerCategory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("không thể đếm sản phẩm theo danh mục: %w", err)
+	}
+	counts := subtreeProductCounts(cates, rows)
+	if err := s.redis.SetCategoryProductCounts(ctx, counts, categoryProductCountTTL); err != nil {
+		log.Printf("[Category] không thể ghi cache số sản phẩm theo danh mục: %v", err)
+	}
+	return counts, nil
+}
+
+// subtreeProductCounts cộng số sản phẩm của mỗi danh mục lên tất cả danh mục tổ tiên của nó
+func subtreeProductCounts(cates []db.Category, rows []db.CountActiveProductsPerCategoryRow) map[string]int64 {
+	parents := make(map[string]string, len(cates))
+	for _, c := range cates {
+		if c.Parent.Valid {
+			parents[c.CategoryID] = c.Parent.String
+		}
+	}
+	counts := make(map[string]int64, len(cates))
+	for _, row := range rows {
+		id := row.CategoryID
+		// giới hạn số bước để dữ liệu cha lỗi (vòng lặp) không làm treo
+		for step := 0; id != "" && step <= len(cates); step++ {
+			counts[id] += row.Total
+			id = parents[id]
+		}
+	}
+	return counts
+}
+
+func fillCategoryProductCounts(cates []services.Categorys, counts map[string]int64) {
+	for i := range cates {
+		cates[i].ProductCount = counts[cates[i].CategoryID]
+		if cates[i].Childs.Valid {
+			fillCategoryProductCounts(cates[i].Childs.Data, counts)
+		}
+	}
+}
+
+// categoryPath tính path của danh mục theo dạng /cha/con giống trigger cũ trong 000002
+func categoryPath(parentPath, key string) string {
+	return parentPath + "/" + key
+}
+
+// rebuildCategoryCache đọc lại toàn bộ danh mục và ghi đè cây danh mục trên redis
+func (s *service) rebuildCategoryCache(ctx context.Context) error {
+	cates, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("không thể lấy danh sách danh mục: %w", err)
+	}
+	list_cate := make([]services.Categorys, 0, len(cates))
+	for _, c := range cates {
+		list_cate = append(list_cate, services.Categorys{
+			CategoryID: c.CategoryID,
+			Name:       c.Name,
+			Parent:     services.Narg[string]{Valid: c.Parent.Valid, Data: c.Parent.String},
+			Key:        c.Key,
+			Path:       c.Path.String,
+			Image:      services.Narg[string]{Valid: c.Image.Valid, Data: c.Image.String},
+			SortOrder:  c.SortOrder,
+		})
+	}
+	return s.redis.AddCategories(ctx, list_cate)
+}
+
+// getCategoryOrError trả về lỗi 404 nếu danh mục không tồn tại
+func (s *service) getCategoryOrError(ctx context.Context, categoryID string) (db.Category, *assets_services.ServiceError) {
+	cat, err := s.repository.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return cat, assets_services.NewError(404, fmt.Errorf("danh mục %s không tồn tại", categoryID))
+		}
+		return cat, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin danh mục: %w", err))
+	}
+	return cat, nil
+}
+
+// lockCategoryTree khóa lại danh mục và danh mục cha mới bên trong transaction rồi trả về danh mục cùng path của cha mới.
+// Kiểm tra vòng lặp chạy trên path vừa khóa nên hai lần di chuyển đồng thời không thể tạo vòng trong cây.
+// parentID rỗng là danh mục gốc.
+func lockCategoryTree(ctx context.Context, tx db.Querier, categoryID, parentID string) (db.Category, string, error) {
+	cat, err := tx.GetCategoryForUpdate(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return cat, "", assets_services.NewError(404, fmt.Errorf("danh mục %s không tồn tại", categoryID))
+		}
+		return cat, "", fmt.Errorf("lỗi khi lấy thông tin danh mục: %w", err)
+	}
+	if parentID == "" {
+		return cat, "", nil
+	}
+	if parentID == cat.CategoryID {
+		return cat, "", assets_services.NewError(400, fmt.Errorf("không thể chọn chính danh mục làm danh mục cha"))
+	}
+	parent, err := tx.GetCategoryForUpdate(ctx, parentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return cat, "", assets_services.NewError(404, fmt.Errorf("danh mục %s không tồn tại", parentID))
+		}
+		return cat, "", fmt.Errorf("lỗi khi lấy thông tin danh mục cha: %w", err)
+	}
+	if cat.Path.Valid && strings.HasPrefix(parent.Path.String, cat.Path.String+"/") {
+		return cat, "", assets_services.NewError(400, fmt.Errorf("không thể chuyển danh mục vào danh mục con của nó"))
+	}
+	return cat, parent.Path.String, nil
+}
+
+// categoryTxError trả lại lỗi nghiệp vụ sinh ra trong transaction, lỗi khác được gói lại với message
+func categoryTxError(err error, message string) *assets_services.ServiceError {
+	var serr *assets_services.ServiceError
+	if errors.As(err, &serr) {
+		return serr
+	}
+	return assets_services.NewError(400, fmt.Errorf("%s. Lỗi: %v", message, err))
+}
+
+// rewriteCategorySubtree đổi cha/path của danh mục và tính lại path cho toàn bộ cây con, dùng bên trong transaction
+func rewriteCategorySubtree(ctx context.Context, tx db.Querier, cat db.Category, parentID, newPath string) error {
+	err := tx.UpdateCategoryParent(ctx, db.UpdateCategoryParentParams{
+		Parent:     sql.NullString{String: parentID, Valid: parentID != ""},
+		CategoryID: cat.CategoryID,
+	})
+	if err != nil {
+		return fmt.Errorf("không thể cập nhật danh mục cha: %w", err)
+	}
+	oldPath := cat.Path.String
+	if oldPath == newPath {
+		return nil
+	}
+	err = tx.UpdateCategoryPath(ctx, db.UpdateCategoryPathParams{
+		Path:       sql.NullString{String: newPath, Valid: true},
+		CategoryID: cat.CategoryID,
+	})
+	if err != nil {
+		return fmt.Errorf("không thể cập nhật path danh mục: %w", err)
+	}
+	if oldPath == "" {
+		return nil
+	}
+	descendants, err := tx.ListCategoryDescendants(ctx, oldPath)
+	if err != nil {
+		return fmt.Errorf("không thể lấy danh mục con: %w", err)
+	}
+	for _, d := range descendants {
+		err := tx.UpdateCategoryPath(ctx, db.UpdateCategoryPathParams{
+			Path:       sql.NullString{String: newPath + strings.TrimPrefix(d.Path.String, oldPath), Valid: true},
+			CategoryID: d.CategoryID,
+		})
+		if err != nil {
+			return fmt.Errorf("không thể cập nhật path danh mục con %s: %w", d.CategoryID, err)
+		}
+	}
+	return nil
+}
+
+// Add a new category: create in DB, refresh redis cache
 func (s *service) AddCategory(ctx context.Context, userName string, cat services.Categorys, file *multipart.FileHeader) *assets_services.ServiceError {
-	// create in repository
 	if cat.Name == "" {
 		return assets_services.NewError(400, fmt.Errorf("category name is required"))
 	}
 	if cat.Image.Valid {
 		/// CALL API LUU TRONG SERVICE ẢNH
 	}
-	// create in repository
 	key, err := assets_services.ConvertToSlug(cat.Name)
 	if err != nil {
 		return assets_services.NewError(400, fmt.Errorf("invalid category name: %v", err))
 	}
+	parentPath := ""
+	if cat.Parent.Valid {
+		parent, errCate := s.getCategoryOrError(ctx, cat.Parent.Data)
+		if errCate != nil {
+			return errCate
+		}
+		parentPath = parent.Path.String
+	}
 	err = s.repository.CreateCategory(ctx, db.CreateCategoryParams{
 		CategoryID: util_assets.RandomString(36),
 		Name:       cat.Name,
 		Parent:     sql.NullString{String: cat.Parent.Data, Valid: cat.Parent.Valid},
 		Key:        key,
 		Image:      sql.NullString{String: cat.Image.Data, Valid: cat.Image.Valid},
-		// Path:       sql.NullString{String: key, Valid: true},
+		Path:       sql.NullString{String: categoryPath(parentPath, key), Valid: true},
 	})
 	if err != nil {
 		fmt.Println("Error CreateCategory:", err)
 		return assets_services.NewError(400, err)
 	}
 
-	// refresh categories cache: list from DB -> convert -> add to redis
-	cates, err := s.repository.ListCategories(ctx)
-	if err != nil {
-		fmt.Println("Error ListCategories after create:", err)
-		return assets_services.NewError(400, err)
-	}
-	list_cate := make([]services.Categorys, 0, len(cates))
-	for _, c := range cates {
-		list_cate = append(list_cate, services.Categorys{
-			CategoryID: c.CategoryID,
-			Name:       c.Name,
-			Parent:     services.Narg[string]{Valid: c.Parent.Valid, Data: c.Parent.String},
-			Key:        c.Key,
-			Path:       c.Path.String,
-		})
-	}
-	if err = s.redis.AddCategories(ctx, list_cate); err != nil {
-		fmt.Println("Error AddCategories after create:", err)
+	if err = s.rebuildCategoryCache(ctx); err != nil {
+		fmt.Println("Error rebuildCategoryCache after create:", err)
 		return assets_services.NewError(400, err)
 	}
-
-	// return updated tree
-	// tree, err := s.redis.GetCategoryTree(ctx, userName)
-	// if err != nil {
-	// 	fmt.Println("Error GetCategoryTree after create:", err)
-	// 	return assets_services.NewError(400, err)
-	// }
-	// result, err := assets_services.HideFields(tree, "categories", "parent")
-	// if err != nil {
-	// 	fmt.Println("Error HideFields after create:", err)
-	// 	return assets_services.NewError(400, err)
-	// }
-	// optionally include created item in response (created not used here)
-	// _ = created
 	return nil
 }
 
-// Update an existing category: update in DB, refresh redis cache, return category tree
+// Update an existing category: update in DB, recompute subtree path when key/parent change, refresh redis cache
 func (s *service) UpdateCategory(ctx context.Context, userName string, cat services.Categorys, file *multipart.FileHeader) *assets_services.ServiceError {
-	// update in repository
 	if cat.CategoryID == "" {
 		return assets_services.NewError(400, fmt.Errorf("category_id is required"))
 	}
 	if cat.Image.Valid {
 		/// CALL API LUU TRONG SERVICE ẢNH
 	}
+	current, errCate := s.getCategoryOrError(ctx, cat.CategoryID)
+	if errCate != nil {
+		return errCate
+	}
+	newKey := current.Key
 	if cat.Name != "" {
-
 		key, err := assets_services.ConvertToSlug(cat.Name)
 		if err != nil {
 			return assets_services.NewError(400, fmt.Errorf("invalid category name: %v", err))
 		}
-		cat.Key = key
+		newKey = key
+	}
+	// parent rỗng nghĩa là giữ nguyên danh mục cha hiện tại
+	newParentID := current.Parent.String
+	if cat.Parent.Valid {
+		newParentID = cat.Parent.Data
 	}
 
-	if err := s.repository.UpdateCategory(ctx, db.UpdateCategoryParams{
-		CategoryID: cat.CategoryID,
-		Name:       sql.NullString{String: cat.Name, Valid: cat.Name != ""},
-		Parent:     sql.NullString{String: cat.Parent.Data, Valid: cat.Parent.Valid},
-		Key:        sql.NullString{String: cat.Key, Valid: cat.Key != ""},
-		Image:      sql.NullString{String: cat.Image.Data, Valid: cat.Image.Valid},
-		Path:       sql.NullString{String: cat.Key, Valid: cat.Key != ""},
-	}); err != nil {
+	err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		locked, parentPath, err := lockCategoryTree(ctx, tx, cat.CategoryID, newParentID)
+		if err != nil {
+			return err
+		}
+		if err := tx.UpdateCategory(ctx, db.UpdateCategoryParams{
+			CategoryID: cat.CategoryID,
+			Name:       sql.NullString{String: cat.Name, Valid: cat.Name != ""},
+			Key:        sql.NullString{String: newKey, Valid: newKey != locked.Key},
+			Image:      sql.NullString{String: cat.Image.Data, Valid: cat.Image.Valid},
+		}); err != nil {
+			return err
+		}
+		if newKey == locked.Key && newParentID == locked.Parent.String {
+			return nil
+		}
+		return rewriteCategorySubtree(ctx, tx, locked, newParentID, categoryPath(parentPath, newKey))
+	})
+	if err != nil {
 		fmt.Println("Error UpdateCategory:", err)
+		return categoryTxError(err, "không thể cập nhật danh mục")
+	}
+
+	if err = s.rebuildCategoryCache(ctx); err != nil {
+		fmt.Println("Error rebuildCategoryCache after update:", err)
 		return assets_services.NewError(400, err)
 	}
+	// tên và path danh mục nằm trong tài liệu tìm kiếm của sản phẩm
+	if (cat.Name != "" && cat.Name != current.Name) || newKey != current.Key || newParentID != current.Parent.String {
+		s.refreshCategoryProductsSearch(ctx, cat.CategoryID)
+	}
+	return nil
+}
 
-	// refresh cache
-	cates, err := s.repository.ListCategories(ctx)
+// MoveCategory chuyển danh mục (cùng toàn bộ cây con) sang danh mục cha mới, parentID rỗng là lên gốc
+func (s *service) MoveCategory(ctx context.Context, userName, categoryID, parentID string) *assets_services.ServiceError {
+	current, errCate := s.getCategoryOrError(ctx, categoryID)
+	if errCate != nil {
+		return errCate
+	}
+	if parentID == current.Parent.String {
+		return nil
+	}
+	err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		locked, parentPath, err := lockCategoryTree(ctx, tx, categoryID, parentID)
+		if err != nil {
+			return err
+		}
+		if parentID == locked.Parent.String {
+			return nil
+		}
+		return rewriteCategorySubtree(ctx, tx, locked, parentID, categoryPath(parentPath, locked.Key))
+	})
 	if err != nil {
-		fmt.Println("Error ListCategories after update:", err)
+		fmt.Println("Error MoveCategory:", err)
+		return categoryTxError(err, "không thể di chuyển danh mục")
+	}
+
+	if err = s.rebuildCategoryCache(ctx); err != nil {
+		fmt.Println("Error rebuildCategoryCache after move:", err)
 		return assets_services.NewError(400, err)
 	}
-	list_cate := make([]services.Categorys, 0, len(cates))
-	for _, c := range cates {
-		list_cate = append(list_cate, services.Categorys{
-			CategoryID: c.CategoryID,
-			Name:       c.Name,
-			Parent:     services.Narg[string]{Valid: c.Parent.Valid, Data: c.Parent.String},
-			Key:        c.Key,
-			Path:       c.Path.String,
-		})
+	s.refreshCategoryProductsSearch(ctx, categoryID)
+	return nil
+}
+
+// ReorderCategories đặt thứ tự cho các danh mục cùng cấp theo đúng thứ tự trong categoryIDs.
+// categoryIDs phải chứa đủ toàn bộ danh mục cùng cấp để sort_order không bị trùng với danh mục không được gửi lên.
+func (s *service) ReorderCategories(ctx context.Context, userName, parentID string, categoryIDs []string) *assets_services.ServiceError {
+	err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		var siblings []db.Category
+		var err error
+		if parentID == "" {
+			siblings, err = tx.GetRootCategories(ctx)
+		} else {
+			siblings, err = tx.GetSubCategories(ctx, sql.NullString{String: parentID, Valid: true})
+		}
+		if err != nil {
+			return fmt.Errorf("không thể lấy danh sách danh mục cùng cấp: %w", err)
+		}
+		if err := validateSiblingOrder(siblings, categoryIDs); err != nil {
+			return err
+		}
+		for i, id := range categoryIDs {
+			if err := tx.UpdateCategorySortOrder(ctx, db.UpdateCategorySortOrderParams{
+				SortOrder:  int32(i),
+				CategoryID: id,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error ReorderCategories:", err)
+		return categoryTxError(err, "không thể sắp xếp danh mục")
 	}
-	if err = s.redis.AddCategories(ctx, list_cate); err != nil {
-		fmt.Println("Error AddCategories after update:", err)
+
+	if err = s.rebuildCategoryCache(ctx); err != nil {
+		fmt.Println("Error rebuildCategoryCache after reorder:", err)
 		return assets_services.NewError(400, err)
 	}
 	return nil
 }
 
-// Delete a category: remove in DB, refresh redis cache, return category tree
-func (s *service) DeleteCategory(ctx context.Context, userName string, categoryID string) *assets_services.ServiceError {
-	// delete in repository
-	if err := s.repository.DeleteCategory(ctx, categoryID); err != nil {
-		fmt.Println("Error DeleteCategory:", err)
-		return assets_services.NewError(400, err)
+// validateSiblingOrder kiểm tra categoryIDs là một hoán vị của đúng các danh mục cùng cấp
+func validateSiblingOrder(siblings []db.Category, categoryIDs []string) *assets_services.ServiceError {
+	siblingSet := make(map[string]bool, len(siblings))
+	for _, c := range siblings {
+		siblingSet[c.CategoryID] = true
 	}
+	seen := make(map[string]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		if !siblingSet[id] {
+			return assets_services.NewError(400, fmt.Errorf("danh mục %s không thuộc danh mục cha đã chọn", id))
+		}
+		if seen[id] {
+			return assets_services.NewError(400, fmt.Errorf("danh mục %s bị lặp lại", id))
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(siblingSet) {
+		return assets_services.NewError(400, fmt.Errorf("phải gửi đủ %d danh mục cùng cấp, nhận được %d", len(siblingSet), len(seen)))
+	}
+	return nil
+}
 
-	// refresh cache
-	cates, err := s.repository.ListCategories(ctx)
+// Delete a category: chỉ xóa khi không còn danh mục con; nếu còn sản phẩm thì phải truyền reassignTo
+// để chuyển sản phẩm sang danh mục khác trong cùng transaction
+func (s *service) DeleteCategory(ctx context.Context, userName, categoryID, reassignTo string) *assets_services.ServiceError {
+	if _, errCate := s.getCategoryOrError(ctx, categoryID); errCate != nil {
+		return errCate
+	}
+	children, err := s.repository.GetSubCategories(ctx, sql.NullString{String: categoryID, Valid: true})
 	if err != nil {
-		fmt.Println("Error ListCategories after delete:", err)
-		return assets_services.NewError(400, err)
+		return assets_services.NewError(400, fmt.Errorf("không thể lấy danh mục con. Lỗi: %v", err))
 	}
-	list_cate := make([]services.Categorys, 0, len(cates))
-	for _, c := range cates {
-		list_cate = append(list_cate, services.Categorys{
-			CategoryID: c.CategoryID,
-			Name:       c.Name,
-			Parent:     services.Narg[string]{Valid: c.Parent.Valid, Data: c.Parent.String},
-			Key:        c.Key,
-			Path:       c.Path.String,
-		})
+	if len(children) > 0 {
+		return assets_services.NewError(409, fmt.Errorf("danh mục còn %d danh mục con, hãy di chuyển hoặc xóa trước", len(children)))
 	}
-	if err = s.redis.AddCategories(ctx, list_cate); err != nil {
-		fmt.Println("Error AddCategories after delete:", err)
-		return assets_services.NewError(400, err)
+	if reassignTo == categoryID {
+		return assets_services.NewError(400, fmt.Errorf("không thể chuyển sản phẩm sang chính danh mục đang xóa"))
+	}
+	totalProducts, err := s.repository.CountProductsByCategory(ctx, categoryID)
+	if err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể đếm sản phẩm của danh mục. Lỗi: %v", err))
+	}
+	if totalProducts > 0 {
+		if reassignTo == "" {
+			return assets_services.NewError(409, fmt.Errorf("danh mục đang có %d sản phẩm, hãy chọn danh mục thay thế", totalProducts))
+		}
+		if _, errCate := s.getCategoryOrError(ctx, reassignTo); errCate != nil {
+			return errCate
+		}
+	}
+
+	// lấy trước danh sách sản phẩm bị chuyển danh mục để dựng lại tài liệu tìm kiếm sau khi xóa
+	var reassigned []string
+	if totalProducts > 0 {
+		reassigned, err = s.repository.ListProductIDsByCategories(ctx, []string{categoryID})
+		if err != nil {
+			return assets_services.NewError(400, fmt.Errorf("không thể lấy sản phẩm của danh mục. Lỗi: %v", err))
+		}
+	}
+
+	err = s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		if totalProducts > 0 {
+			err := tx.ReassignProductsCategory(ctx, db.ReassignProductsCategoryParams{
+				NewCategoryID: reassignTo,
+				OldCategoryID: categoryID,
+			})
+			if err != nil {
+				return fmt.Errorf("không thể chuyển sản phẩm sang danh mục mới: %w", err)
+			}
+		}
+		return tx.DeleteCategory(ctx, categoryID)
+	})
+	if err != nil {
+		fmt.Println("Error DeleteCategory:", err)
+		return assets_services.NewError(400, fmt.Errorf("không thể xóa danh mục. Lỗi: %v", err))
 	}
 
+	if err = s.rebuildCategoryCache(ctx); err != nil {
+		fmt.Println("Error rebuildCategoryCache after delete:", err)
+		return assets_services.NewError(400, err)
+	}
+	s.refreshProductSearchAsync(reassigned)
 	return nil
 }
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// cây: dien-tu -> dien-thoai -> iphone, dien-tu -> laptop, thoi-trang (không có sản phẩm)
+var testCategories = []db.Category{
+	{CategoryID: "dien-tu"},
+	{CategoryID: "dien-thoai", Parent: sql.NullString{String: "dien-tu", Valid: true}},
+	{CategoryID: "iphone", Parent: sql.NullString{String: "dien-thoai", Valid: true}},
+	{CategoryID: "laptop", Parent: sql.NullString{String: "dien-tu", Valid: true}},
+	{CategoryID: "thoi-trang"},
+}
+
+func TestSubtreeProductCounts(t *testing.T) {
+	rows := []db.CountActiveProductsPerCategoryRow{
+		{CategoryID: "iphone", Total: 5},
+		{CategoryID: "dien-thoai", Total: 2},
+		{CategoryID: "laptop", Total: 3},
+	}
+	counts := subtreeProductCounts(testCategories, rows)
+	require.Equal(t, int64(5), counts["iphone"])
+	require.Equal(t, int64(7), counts["dien-thoai"])
+	require.Equal(t, int64(3), counts["laptop"])
+	require.Equal(t, int64(10), counts["dien-tu"])
+	require.Zero(t, counts["thoi-trang"])
+}
+
+func TestSubtreeProductCountsParentCycle(t *testing.T) {
+	// dữ liệu cha lỗi tạo vòng lặp không được làm treo
+	cates := []db.Category{
+		{CategoryID: "a", Parent: sql.NullString{String: "b", Valid: true}},
+		{CategoryID: "b", Parent: sql.NullString{String: "a", Valid: true}},
+	}
+	counts := subtreeProductCounts(cates, []db.CountActiveProductsPerCategoryRow{{CategoryID: "a", Total: 1}})
+	require.Positive(t, counts["a"])
+	require.Positive(t, counts["b"])
+}
+
+type categoryCountStore struct {
+	db_mysql.Store
+	countCalls int
+}
+
+func (f *categoryCountStore) ListCategories(ctx context.Context) ([]db.Category, error) {
+	return testCategories, nil
+}
+
+func (f *categoryCountStore) CountActiveProductsPerCategory(ctx context.Context) ([]db.CountActiveProductsPerCategoryRow, error) {
+	f.countCalls++
+	return []db.CountActiveProductsPerCategoryRow{{CategoryID: "iphone", Total: 4}}, nil
+}
+
+type categoryCountRedis struct {
+	ServicesRedis
+	counts map[string]int64
+}
+
+func (f *categoryCountRedis) GetCategoryProductCounts(ctx context.Context) (map[string]int64, error) {
+	return f.counts, nil
+}
+
+func (f *categoryCountRedis) SetCategoryProductCounts(ctx context.Context, counts map[string]int64, ttl time.Duration) error {
+	f.counts = counts
+	return nil
+}
+
+func TestCategoryProductCountsCached(t *testing.T) {
+	store := &categoryCountStore{}
+	cache := &categoryCountRedis{}
+	s := &service{repository: store, redis: cache}
+
+	counts, err := s.categoryProductCounts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), counts["dien-tu"])
+
+	// lần xem cây tiếp theo đọc từ cache, không GROUP BY lại
+	counts, err = s.categoryProductCounts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), counts["dien-thoai"])
+	require.Equal(t, 1, store.countCalls)
+
+	// cây danh mục bị dựng lại thì cache số lượng cũng bị xóa
+	cache.counts = nil
+	_, err = s.categoryProductCounts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, store.countCalls)
+}
+
+// categoryTree giữ bảng category trong bộ nhớ, dùng chung cho đọc ngoài và trong transaction.
+// stale (nếu có) là dữ liệu cũ mà lần đọc ngoài transaction nhìn thấy.
+type categoryTree struct {
+	db_mysql.Store
+	cats   map[string]db.Category
+	stale  map[string]db.Category
+	locked []string
+}
+
+func newCategoryTree() *categoryTree {
+	cat := func(id, parent, path string) db.Category {
+		return db.Category{
+			CategoryID: id,
+			Name:       id,
+			Key:        id,
+			Path:       sql.NullString{String: path, Valid: true},
+			Parent:     sql.NullString{String: parent, Valid: parent != ""},
+		}
+	}
+	tree := &categoryTree{cats: map[string]db.Category{}}
+	for _, c := range []db.Category{
+		cat("dien-tu", "", "/dien-tu"),
+		cat("dien-thoai", "dien-tu", "/dien-tu/dien-thoai"),
+		cat("iphone", "dien-thoai", "/dien-tu/dien-thoai/iphone"),
+		cat("laptop", "dien-tu", "/dien-tu/laptop"),
+		cat("thoi-trang", "", "/thoi-trang"),
+	} {
+		tree.cats[c.CategoryID] = c
+	}
+	return tree
+}
+
+func (f *categoryTree) path(id string) string {
+	return f.cats[id].Path.String
+}
+
+func (f *categoryTree) ExecTS(ctx context.Context, fn func(tx db.Querier) error) error {
+	return fn(f)
+}
+
+func (f *categoryTree) GetCategory(ctx context.Context, categoryID string) (db.Category, error) {
+	if c, ok := f.stale[categoryID]; ok {
+		return c, nil
+	}
+	c, ok := f.cats[categoryID]
+	if !ok {
+		return c, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (f *categoryTree) GetCategoryForUpdate(ctx context.Context, categoryID string) (db.Category, error) {
+	f.locked = append(f.locked, categoryID)
+	c, ok := f.cats[categoryID]
+	if !ok {
+		return c, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (f *categoryTree) UpdateCategory(ctx context.Context, arg db.UpdateCategoryParams) error {
+	c := f.cats[arg.CategoryID]
+	if arg.Name.Valid {
+		c.Name = arg.Name.String
+	}
+	if arg.Key.Valid {
+		c.Key = arg.Key.String
+	}
+	f.cats[arg.CategoryID] = c
+	return nil
+}
+
+func (f *categoryTree) UpdateCategoryParent(ctx context.Context, arg db.UpdateCategoryParentParams) error {
+	c := f.cats[arg.CategoryID]
+	c.Parent = arg.Parent
+	f.cats[arg.CategoryID] = c
+	return nil
+}
+
+func (f *categoryTree) UpdateCategoryPath(ctx context.Context, arg db.UpdateCategoryPathParams) error {
+	c := f.cats[arg.CategoryID]
+	c.Path = arg.Path
+	f.cats[arg.CategoryID] = c
+	return nil
+}
+
+func (f *categoryTree) UpdateCategorySortOrder(ctx context.Context, arg db.UpdateCategorySortOrderParams) error {
+	c := f.cats[arg.CategoryID]
+	c.SortOrder = arg.SortOrder
+	f.cats[arg.CategoryID] = c
+	return nil
+}
+
+func (f *categoryTree) ListCategoryDescendants(ctx context.Context, path interface{}) ([]db.Category, error) {
+	var out []db.Category
+	for _, c := range f.cats {
+		if strings.HasPrefix(c.Path.String, path.(string)+"/") {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *categoryTree) GetRootCategories(ctx context.Context) ([]db.Category, error) {
+	return f.GetSubCategories(ctx, sql.NullString{})
+}
+
+func (f *categoryTree) GetSubCategories(ctx context.Context, parent sql.NullString) ([]db.Category, error) {
+	var out []db.Category
+	for _, c := range f.cats {
+		if c.Parent == parent {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *categoryTree) ListCategories(ctx context.Context) ([]db.Category, error) {
+	out := make([]db.Category, 0, len(f.cats))
+	for _, c := range f.cats {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (f *categoryTree) ListProductIDsByCategories(ctx context.Context, categoryIds []string) ([]string, error) {
+	return nil, nil
+}
+
+type categoryTreeRedis struct {
+	ServicesRedis
+}
+
+func (f *categoryTreeRedis) AddCategories(ctx context.Context, cates []services.Categorys) error {
+	return nil
+}
+
+func TestMoveCategoryRewritesSubtree(t *testing.T) {
+	tree := newCategoryTree()
+	s := &service{repository: tree, redis: &categoryTreeRedis{}}
+
+	serr := s.MoveCategory(context.Background(), "admin", "dien-thoai", "thoi-trang")
+	require.Nil(t, serr)
+	require.Equal(t, "/thoi-trang/dien-thoai", tree.path("dien-thoai"))
+	require.Equal(t, "/thoi-trang/dien-thoai/iphone", tree.path("iphone"))
+	require.Equal(t, "thoi-trang", tree.cats["dien-thoai"].Parent.String)
+	require.Equal(t, "/dien-tu/laptop", tree.path("laptop"))
+	// danh mục và danh mục cha mới đều bị khóa trong transaction
+	require.Equal(t, []string{"dien-thoai", "thoi-trang"}, tree.locked)
+
+	// chuyển lên gốc
+	serr = s.MoveCategory(context.Background(), "admin", "dien-thoai", "")
+	require.Nil(t, serr)
+	require.Equal(t, "/dien-thoai", tree.path("dien-thoai"))
+	require.Equal(t, "/dien-thoai/iphone", tree.path("iphone"))
+	require.False(t, tree.cats["dien-thoai"].Parent.Valid)
+}
+
+func TestMoveCategoryRejectsCycle(t *testing.T) {
+	ctx := context.Background()
+	tree := newCategoryTree()
+	s := &service{repository: tree, redis: &categoryTreeRedis{}}
+
+	serr := s.MoveCategory(ctx, "admin", "dien-tu", "iphone")
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+
+	serr = s.MoveCategory(ctx, "admin", "dien-tu", "dien-tu")
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+
+	serr = s.MoveCategory(ctx, "admin", "dien-tu", "khong-co")
+	require.NotNil(t, serr)
+	require.Equal(t, 404, serr.Code)
+
+	require.Equal(t, "/dien-tu/dien-thoai/iphone", tree.path("iphone"))
+	require.False(t, tree.cats["dien-tu"].Parent.Valid)
+}
+
+func TestMoveCategoryChecksLockedPath(t *testing.T) {
+	// request khác vừa chuyển thoi-trang vào dưới dien-tu, lần đọc ngoài transaction vẫn thấy thoi-trang ở gốc
+	tree := newCategoryTree()
+	tree.stale = map[string]db.Category{"thoi-trang": tree.cats["thoi-trang"]}
+	moved := tree.cats["thoi-trang"]
+	moved.Parent = sql.NullString{String: "laptop", Valid: true}
+	moved.Path = sql.NullString{String: "/dien-tu/laptop/thoi-trang", Valid: true}
+	tree.cats["thoi-trang"] = moved
+	s := &service{repository: tree, redis: &categoryTreeRedis{}}
+
+	serr := s.MoveCategory(context.Background(), "admin", "dien-tu", "thoi-trang")
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+	require.Equal(t, "/dien-tu", tree.path("dien-tu"))
+}
+
+func TestUpdateCategoryKeyRewritesSubtree(t *testing.T) {
+	tree := newCategoryTree()
+	s := &service{repository: tree, redis: &categoryTreeRedis{}}
+
+	serr := s.UpdateCategory(context.Background(), "admin", services.Categorys{CategoryID: "dien-thoai", Name: "Điện thoại di động"}, nil)
+	require.Nil(t, serr)
+	require.Equal(t, "dien_thoai_di_dong", tree.cats["dien-thoai"].Key)
+	require.Equal(t, "/dien-tu/dien_thoai_di_dong", tree.path("dien-thoai"))
+	require.Equal(t, "/dien-tu/dien_thoai_di_dong/iphone", tree.path("iphone"))
+	require.Equal(t, "dien-tu", tree.cats["dien-thoai"].Parent.String)
+}
+
+func TestReorderCategories(t *testing.T) {
+	ctx := context.Background()
+	tree := newCategoryTree()
+	s := &service{repository: tree, redis: &categoryTreeRedis{}}
+
+	serr := s.ReorderCategories(ctx, "admin", "dien-tu", []string{"laptop", "dien-thoai"})
+	require.Nil(t, serr)
+	require.Equal(t, int32(0), tree.cats["laptop"].SortOrder)
+	require.Equal(t, int32(1), tree.cats["dien-thoai"].SortOrder)
+
+	serr = s.ReorderCategories(ctx, "admin", "", []string{"thoi-trang", "dien-tu"})
+	require.Nil(t, serr)
+	require.Equal(t, int32(0), tree.cats["thoi-trang"].SortOrder)
+	require.Equal(t, int32(1), tree.cats["dien-tu"].SortOrder)
+
+	for _, ids := range [][]string{
+		// thiếu danh mục cùng cấp
+		{"dien-thoai"},
+		{"laptop", "laptop"},
+		{"laptop", "dien-thoai", "iphone"},
+		{},
+	} {
+		serr = s.ReorderCategories(ctx, "admin", "dien-tu", ids)
+		require.NotNil(t, serr, "%v", ids)
+		require.Equal(t, 400, serr.Code)
+	}
+	require.Equal(t, int32(0), tree.cats["laptop"].SortOrder)
+	require.Equal(t, int32(1), tree.cats["dien-thoai"].SortOrder)
+}
@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+func (s *service) ListCategoryAttributes(ctx context.Context, categoryID string) (map[string]interface{}, *assets_services.ServiceError) {
+	defs, errCate := s.effectiveCategoryAttributes(ctx, s.repository, categoryID)
+	if errCate != nil {
+		return nil, errCate
+	}
+	attrs := make([]services.CategoryAttribute, 0, len(defs))
+	for _, def := range defs {
+		attr := toCategoryAttributeEntity(def)
+		attr.Inherited = def.CategoryID != categoryID
+		attrs = append(attrs, attr)
+	}
+	return map[string]interface{}{"data": attrs}, nil
+}
+
+func (s *service) CreateCategoryAttribute(ctx context.Context, userName string, attr services.CategoryAttribute) *assets_services.ServiceError {
+	code, err := normalizeAttributeCode(attr.Code)
+	if err != nil {
+		return assets_services.NewError(400, err)
+	}
+	if strings.TrimSpace(attr.Name) == "" {
+		return assets_services.NewError(400, fmt.Errorf("tên thuộc tính không được để trống"))
+	}
+	dataType := db.CategoryAttributeDataType(strings.ToUpper(attr.DataType))
+	switch dataType {
+	case db.CategoryAttributeDataTypeENUM:
+		if len(attr.EnumValues) == 0 {
+			return assets_services.NewError(400, fmt.Errorf("thuộc tính kiểu ENUM phải có danh sách giá trị"))
+		}
+	case db.CategoryAttributeDataTypeNUMBER, db.CategoryAttributeDataTypeBOOLEAN:
+	default:
+		return assets_services.NewError(400, fmt.Errorf("kiểu thuộc tính không hợp lệ: %s", attr.DataType))
+	}
+	if dataType != db.CategoryAttributeDataTypeNUMBER && attr.Unit != "" {
+		return assets_services.NewError(400, fmt.Errorf("chỉ thuộc tính kiểu NUMBER mới có đơn vị"))
+	}
+
+	// code không được trùng với thuộc tính đã có (kể cả thuộc tính kế thừa)
+	defs, errCate := s.effectiveCategoryAttributes(ctx, s.repository, attr.CategoryID)
+	if errCate != nil {
+		return errCate
+	}
+	for _, def := range defs {
+		if def.Code == code {
+			return assets_services.NewError(409, fmt.Errorf("thuộc tính '%s' đã tồn tại trong danh mục hoặc danh mục cha", code))
+		}
+	}
+	// danh mục con kế thừa thuộc tính mới nên code cũng không được trùng thuộc tính riêng của danh mục con
+	descendants, err := s.repository.CountDescendantAttributesByCode(ctx, db.CountDescendantAttributesByCodeParams{
+		CategoryID: attr.CategoryID,
+		Code:       code,
+	})
+	if err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể kiểm tra thuộc tính của danh mục con. Lỗi: %v", err))
+	}
+	if descendants > 0 {
+		return assets_services.NewError(409, fmt.Errorf("thuộc tính '%s' đã tồn tại trong danh mục con", code))
+	}
+
+	enumValues, err := marshalEnumValues(attr.EnumValues)
+	if err != nil {
+		return assets_services.NewError(400, err)
+	}
+	err = s.repository.CreateCategoryAttribute(ctx, db.CreateCategoryAttributeParams{
+		ID:           uuid.New().String(),
+		CategoryID:   attr.CategoryID,
+		Code:         code,
+		Name:         strings.TrimSpace(attr.Name),
+		DataType:     dataType,
+		Unit:         sql.NullString{String: attr.Unit, Valid: attr.Unit != ""},
+		EnumValues:   enumValues,
+		IsRequired:   attr.IsRequired,
+		IsFilterable: attr.IsFilterable,
+		SortOrder:    attr.SortOrder,
+	})
+	if err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể tạo thuộc tính danh mục. Lỗi: %v", err))
+	}
+	return nil
+}
+
+// UpdateCategoryAttribute không cho đổi code và kiểu dữ liệu vì sẽ làm sai các giá trị sản phẩm đã lưu
+func (s *service) UpdateCategoryAttribute(ctx context.Context, userName string, attr services.CategoryAttributeUpdate) *assets_services.ServiceError {
+	current, err := s.repository.GetCategoryAttribute(ctx, attr.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(404, fmt.Errorf("thuộc tính không tồn tại"))
+		}
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin thuộc tính: %w", err))
+	}
+	params := db.UpdateCategoryAttributeParams{ID: attr.ID}
+	if attr.Name != nil {
+		params.Name = sql.NullString{String: strings.TrimSpace(*attr.Name), Valid: strings.TrimSpace(*attr.Name) != ""}
+	}
+	if attr.Unit != nil {
+		if current.DataType != db.CategoryAttributeDataTypeNUMBER {
+			return assets_services.NewError(400, fmt.Errorf("chỉ thuộc tính kiểu NUMBER mới có đơn vị"))
+		}
+		params.Unit = sql.NullString{String: *attr.Unit, Valid: true}
+	}
+	if attr.EnumValues != nil {
+		if current.DataType != db.CategoryAttributeDataTypeENUM || len(attr.EnumValues) == 0 {
+			return assets_services.NewError(400, fmt.Errorf("danh sách giá trị chỉ áp dụng cho thuộc tính kiểu ENUM và không được rỗng"))
+		}
+		enumValues, err := marshalEnumValues(attr.EnumValues)
+		if err != nil {
+			return assets_services.NewError(400, err)
+		}
+		params.EnumValues = enumValues
+	}
+	if attr.IsRequired != nil {
+		params.IsRequired = sql.NullBool{Bool: *attr.IsRequired, Valid: true}
+	}
+	if attr.IsFilterable != nil {
+		params.IsFilterable = sql.NullBool{Bool: *attr.IsFilterable, Valid: true}
+	}
+	if attr.SortOrder != nil {
+		params.SortOrder = sql.NullInt32{Int32: *attr.SortOrder, Valid: true}
+	}
+	if err := s.repository.UpdateCategoryAttribute(ctx, params); err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể cập nhật thuộc tính danh mục. Lỗi: %v", err))
+	}
+	return nil
+}
+
+func (s *service) DeleteCategoryAttribute(ctx context.Context, userName, attributeID string) *assets_services.ServiceError {
+	if _, err := s.repository.GetCategoryAttribute(ctx, attributeID); err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(404, fmt.Errorf("thuộc tính không tồn tại"))
+		}
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin thuộc tính: %w", err))
+	}
+	// giá trị của sản phẩm bị xóa theo ON DELETE CASCADE
+	if err := s.repository.DeleteCategoryAttribute(ctx, attributeID); err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể xóa thuộc tính danh mục. Lỗi: %v", err))
+	}
+	return nil
+}
+
+// effectiveCategoryAttributes trả về thuộc tính của danh mục kèm thuộc tính kế thừa từ các danh mục cha.
+// Nếu danh mục con định nghĩa lại cùng code thì định nghĩa của danh mục con được dùng.
+func (s *service) effectiveCategoryAttributes(ctx context.Context, q db.Querier, categoryID string) ([]db.CategoryAttribute, *assets_services.ServiceError) {
+	category, err := q.GetCategory(ctx, categoryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(404, fmt.Errorf("danh mục %s không tồn tại", categoryID))
+		}
+		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin danh mục: %w", err))
+	}
+	if !category.Path.Valid || category.Path.String == "" {
+		return nil, assets_services.NewError(400, fmt.Errorf("danh mục %s chưa có path", categoryID))
+	}
+	rows, err := q.ListCategoryAttributesByPath(ctx, category.Path.String)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thuộc tính danh mục. Lỗi: %v", err))
+	}
+	index := make(map[string]int, len(rows))
+	defs := make([]db.CategoryAttribute, 0, len(rows))
+	for _, row := range rows {
+		if i, ok := index[row.Code]; ok {
+			defs[i] = row
+			continue
+		}
+		index[row.Code] = len(defs)
+		defs = append(defs, row)
+	}
+	return defs, nil
+}
+
+// buildProductAttributeValues kiểm tra giá trị thuộc tính theo định nghĩa của danh mục
+// và trả về các bản ghi product_attribute_value đã chuẩn hóa
+func buildProductAttributeValues(productID string, defs []db.CategoryAttribute, values map[string]string) ([]db.CreateProductAttributeValueParams, error) {
+	defByCode := make(map[string]db.CategoryAttribute, len(defs))
+	for _, def := range defs {
+		defByCode[def.Code] = def
+	}
+	for code := range values {
+		if _, ok := defByCode[code]; !ok {
+			return nil, fmt.Errorf("thuộc tính '%s' không thuộc danh mục của sản phẩm", code)
+		}
+	}
+
+	result := make([]db.CreateProductAttributeValueParams, 0, len(values))
+	for _, def := range defs {
+		raw := strings.TrimSpace(values[def.Code])
+		if raw == "" {
+			if def.IsRequired {
+				return nil, fmt.Errorf("thuộc tính '%s' là bắt buộc", def.Name)
+			}
+			continue
+		}
+		value, number, err := normalizeAttributeValue(def, raw)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, db.CreateProductAttributeValueParams{
+			ProductID:   productID,
+			AttributeID: def.ID,
+			Value:       value,
+			ValueNumber: number,
+		})
+	}
+	return result, nil
+}
+
+func normalizeAttributeValue(def db.CategoryAttribute, raw string) (string, sql.NullFloat64, error) {
+	switch def.DataType {
+	case db.CategoryAttributeDataTypeENUM:
+		for _, allowed := range unmarshalEnumValues(def.EnumValues) {
+			if strings.EqualFold(allowed, raw) {
+				return allowed, sql.NullFloat64{}, nil
+			}
+		}
+		return "", sql.NullFloat64{}, fmt.Errorf("giá trị '%s' không hợp lệ cho thuộc tính '%s'", raw, def.Name)
+	case db.CategoryAttributeDataTypeNUMBER:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", sql.NullFloat64{}, fmt.Errorf("thuộc tính '%s' phải là số", def.Name)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), sql.NullFloat64{Float64: number, Valid: true}, nil
+	case db.CategoryAttributeDataTypeBOOLEAN:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", sql.NullFloat64{}, fmt.Errorf("thuộc tính '%s' phải là true hoặc false", def.Name)
+		}
+		return strconv.FormatBool(b), sql.NullFloat64{}, nil
+	}
+	return "", sql.NullFloat64{}, fmt.Errorf("kiểu thuộc tính không hợp lệ: %s", def.DataType)
+}
+
+// parseAttributeFilters chuyển bộ lọc attr.<code>=<giá trị> thành điều kiện truy vấn.
+// ENUM/BOOLEAN: nhiều giá trị cách nhau bởi dấu phẩy; NUMBER: giá trị cụ thể hoặc khoảng "min..max".
+func parseAttributeFilters(defs []db.CategoryAttribute, filters map[string]string) ([]db_mysql.ProductAttributeFilter, error) {
+	defByCode := make(map[string]db.CategoryAttribute, len(defs))
+	for _, def := range defs {
+		defByCode[def.Code] = def
+	}
+	result := make([]db_mysql.ProductAttributeFilter, 0, len(filters))
+	for code, raw := range filters {
+		def, ok := defByCode[code]
+		if !ok || !def.IsFilterable {
+			return nil, fmt.Errorf("không thể lọc theo thuộc tính '%s'", code)
+		}
+		filter := db_mysql.ProductAttributeFilter{AttributeID: def.ID}
+		if def.DataType == db.CategoryAttributeDataTypeNUMBER && strings.Contains(raw, "..") {
+			bounds := strings.SplitN(raw, "..", 2)
+			for i, bound := range bounds {
+				bound = strings.TrimSpace(bound)
+				if bound == "" {
+					continue
+				}
+				number, err := strconv.ParseFloat(bound, 64)
+				if err != nil {
+					return nil, fmt.Errorf("khoảng giá trị không hợp lệ cho thuộc tính '%s'", code)
+				}
+				if i == 0 {
+					filter.Min = sql.NullFloat64{Float64: number, Valid: true}
+				} else {
+					filter.Max = sql.NullFloat64{Float64: number, Valid: true}
+				}
+			}
+		} else {
+			for _, part := range strings.Split(raw, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					continue
+				}
+				value, _, err := normalizeAttributeValue(def, part)
+				if err != nil {
+					return nil, err
+				}
+				filter.Values = append(filter.Values, value)
+			}
+			if len(filter.Values) == 0 {
+				continue
+			}
+		}
+		result = append(result, filter)
+	}
+	return result, nil
+}
+
+// attributeFacets đếm số sản phẩm theo từng giá trị của các thuộc tính có thể lọc.
+// Thuộc tính đang được lọc đếm trên tập kết quả bỏ điều kiện của chính nó để vẫn chọn thêm giá trị khác được,
+// các thuộc tính còn lại đếm chung một lần trên tập kết quả hiện tại
+func (s *service) attributeFacets(ctx context.Context, params db_mysql.ProductDynamicParams, defs []db.CategoryAttribute) ([]services.AttributeFacet, error) {
+	active := make(map[string]bool, len(params.Attributes))
+	for _, filter := range params.Attributes {
+		active[filter.AttributeID] = true
+	}
+	var plainIDs, activeIDs []string
+	for _, def := range defs {
+		if !def.IsFilterable {
+			continue
+		}
+		if active[def.ID] {
+			activeIDs = append(activeIDs, def.ID)
+		} else {
+			plainIDs = append(plainIDs, def.ID)
+		}
+	}
+
+	var rows []db_mysql.ProductAttributeFacetRow
+	if len(plainIDs) > 0 {
+		plainRows, err := s.repository.CountProductAttributeFacets(ctx, params, plainIDs)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, plainRows...)
+	}
+	for _, id := range activeIDs {
+		own := params
+		own.Attributes = withoutAttributeFilter(params.Attributes, id)
+		ownRows, err := s.repository.CountProductAttributeFacets(ctx, own, []string{id})
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, ownRows...)
+	}
+
+	valuesByAttr := make(map[string][]services.AttributeFacetValue)
+	for _, row := range rows {
+		valuesByAttr[row.AttributeID] = append(valuesByAttr[row.AttributeID], services.AttributeFacetValue{Value: row.Value, Total: row.Total})
+	}
+	facets := make([]services.AttributeFacet, 0, len(plainIDs)+len(activeIDs))
+	for _, def := range defs {
+		if !def.IsFilterable {
+			continue
+		}
+		values := valuesByAttr[def.ID]
+		if values == nil {
+			values = []services.AttributeFacetValue{}
+		}
+		facets = append(facets, services.AttributeFacet{
+			Code:     def.Code,
+			Name:     def.Name,
+			DataType: string(def.DataType),
+			Unit:     def.Unit.String,
+			Values:   values,
+		})
+	}
+	return facets, nil
+}
+
+// brandFacets đếm số sản phẩm theo thương hiệu trên tập kết quả bỏ điều kiện thương hiệu
+func (s *service) brandFacets(ctx context.Context, params db_mysql.ProductDynamicParams) ([]services.BrandFacet, error) {
+	params.BrandID = sql.NullString{}
+	rows, err := s.repository.CountProductBrandFacets(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	facets := make([]services.BrandFacet, 0, len(rows))
+	for _, row := range rows {
+		facets = append(facets, services.BrandFacet{BrandID: row.BrandID, Code: row.Code, Name: row.Name, Total: row.Total})
+	}
+	return facets, nil
+}
+
+// withoutAttributeFilter bản sao danh sách điều kiện lọc đã bỏ điều kiện của thuộc tính attributeID
+func withoutAttributeFilter(filters []db_mysql.ProductAttributeFilter, attributeID string) []db_mysql.ProductAttributeFilter {
+	result := make([]db_mysql.ProductAttributeFilter, 0, len(filters))
+	for _, filter := range filters {
+		if filter.AttributeID != attributeID {
+			result = append(result, filter)
+		}
+	}
+	return result
+}
+
+func toCategoryAttributeEntity(def db.CategoryAttribute) services.CategoryAttribute {
+	return services.CategoryAttribute{
+		ID:           def.ID,
+		CategoryID:   def.CategoryID,
+		Code:         def.Code,
+		Name:         def.Name,
+		DataType:     string(def.DataType),
+		Unit:         def.Unit.String,
+		EnumValues:   unmarshalEnumValues(def.EnumValues),
+		IsRequired:   def.IsRequired,
+		IsFilterable: def.IsFilterable,
+		SortOrder:    def.SortOrder,
+	}
+}
+
+// normalizeAttributeCode chuyển code về dạng chữ thường, không dấu, phân cách bằng "_"
+func normalizeAttributeCode(code string) (string, error) {
+	slug, err := assets_services.ConvertToSlug(code)
+	if err != nil || slug == "" {
+		return "", fmt.Errorf("code thuộc tính không hợp lệ: %s", code)
+	}
+	return strings.ReplaceAll(slug, "-", "_"), nil
+}
+
+func marshalEnumValues(values []string) (sql.NullString, error) {
+	if len(values) == 0 {
+		return sql.NullString{}, nil
+	}
+	cleaned := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[strings.ToLower(v)] {
+			continue
+		}
+		seen[strings.ToLower(v)] = true
+		cleaned = append(cleaned, v)
+	}
+	data, err := json.Marshal(cleaned)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("lỗi xử lý danh sách giá trị: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalEnumValues(values sql.NullString) []string {
+	result := []string{}
+	if !values.Valid || values.String == "" {
+		return result
+	}
+	if err := json.Unmarshal([]byte(values.String), &result); err != nil {
+		return []string{}
+	}
+	return result
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// facetStore ghi lại các lần đếm facet, trả về số liệu theo điều kiện lọc nhận được
+type facetStore struct {
+	db_mysql.Store
+	attributeCalls []db_mysql.ProductDynamicParams
+	brandCalls     []db_mysql.ProductDynamicParams
+}
+
+func (f *facetStore) CountProductAttributeFacets(ctx context.Context, params db_mysql.ProductDynamicParams, attributeIDs []string) ([]db_mysql.ProductAttributeFacetRow, error) {
+	f.attributeCalls = append(f.attributeCalls, params)
+	var rows []db_mysql.ProductAttributeFacetRow
+	for _, id := range attributeIDs {
+		switch id {
+		case "attr-color":
+			// có lọc màu thì chỉ còn màu đã chọn
+			if len(params.Attributes) > 0 && params.Attributes[0].AttributeID == "attr-color" {
+				rows = append(rows, db_mysql.ProductAttributeFacetRow{AttributeID: id, Value: "red", Total: 3})
+				continue
+			}
+			rows = append(rows,
+				db_mysql.ProductAttributeFacetRow{AttributeID: id, Value: "red", Total: 3},
+				db_mysql.ProductAttributeFacetRow{AttributeID: id, Value: "blue", Total: 2},
+			)
+		case "attr-size":
+			rows = append(rows, db_mysql.ProductAttributeFacetRow{AttributeID: id, Value: "L", Total: int64(len(params.Attributes))})
+		}
+	}
+	return rows, nil
+}
+
+func (f *facetStore) CountProductBrandFacets(ctx context.Context, params db_mysql.ProductDynamicParams) ([]db_mysql.ProductBrandFacetRow, error) {
+	f.brandCalls = append(f.brandCalls, params)
+	return []db_mysql.ProductBrandFacetRow{
+		{BrandID: "b-1", Code: "apple", Name: "Apple", Total: 4},
+		{BrandID: "b-2", Code: "samsung", Name: "Samsung", Total: 1},
+	}, nil
+}
+
+func TestAttributeFacetsExcludeOwnFilter(t *testing.T) {
+	store := &facetStore{}
+	s := &service{repository: store}
+	defs := []db.CategoryAttribute{
+		{ID: "attr-color", Code: "color", Name: "Màu", DataType: db.CategoryAttributeDataTypeENUM, IsFilterable: true},
+		{ID: "attr-size", Code: "size", Name: "Cỡ", DataType: db.CategoryAttributeDataTypeENUM, IsFilterable: true},
+		{ID: "attr-note", Code: "note", Name: "Ghi chú", DataType: db.CategoryAttributeDataTypeNUMBER},
+	}
+	params := db_mysql.ProductDynamicParams{
+		Attributes: []db_mysql.ProductAttributeFilter{{AttributeID: "attr-color", Values: []string{"red"}}},
+	}
+
+	facets, err := s.attributeFacets(context.Background(), params, defs)
+	require.NoError(t, err)
+
+	// thuộc tính không lọc đếm chung 1 lần với đủ điều kiện, thuộc tính đang lọc đếm riêng và bỏ điều kiện của nó
+	require.Len(t, store.attributeCalls, 2)
+	require.Equal(t, params.Attributes, store.attributeCalls[0].Attributes)
+	require.Empty(t, store.attributeCalls[1].Attributes)
+	require.Len(t, params.Attributes, 1, "không được sửa điều kiện lọc của trang kết quả")
+
+	require.Len(t, facets, 2)
+	require.Equal(t, "color", facets[0].Code)
+	require.Len(t, facets[0].Values, 2, "chọn 1 màu vẫn phải thấy các màu khác")
+	require.Equal(t, "size", facets[1].Code)
+	require.Equal(t, int64(1), facets[1].Values[0].Total)
+}
+
+func TestAttributeFacetsWithoutFilters(t *testing.T) {
+	store := &facetStore{}
+	s := &service{repository: store}
+	defs := []db.CategoryAttribute{
+		{ID: "attr-color", Code: "color", IsFilterable: true},
+		{ID: "attr-weight", Code: "weight", IsFilterable: true},
+	}
+
+	facets, err := s.attributeFacets(context.Background(), db_mysql.ProductDynamicParams{}, defs)
+	require.NoError(t, err)
+	require.Len(t, store.attributeCalls, 1)
+	require.Len(t, facets, 2)
+	require.Len(t, facets[0].Values, 2)
+	// thuộc tính không có giá trị nào vẫn trả mảng rỗng
+	require.NotNil(t, facets[1].Values)
+	require.Empty(t, facets[1].Values)
+}
+
+func TestBrandFacetsExcludeBrandFilter(t *testing.T) {
+	store := &facetStore{}
+	s := &service{repository: store}
+	params := db_mysql.ProductDynamicParams{}
+	params.BrandID = sql.NullString{String: "b-1", Valid: true}
+	params.ShopID = sql.NullString{String: "shop-1", Valid: true}
+
+	facets, err := s.brandFacets(context.Background(), params)
+	require.NoError(t, err)
+	require.Len(t, store.brandCalls, 1)
+	require.False(t, store.brandCalls[0].BrandID.Valid)
+	require.Equal(t, params.ShopID, store.brandCalls[0].ShopID)
+	require.Len(t, facets, 2)
+	require.Equal(t, "samsung", facets[1].Code)
+}
+
+// attributeTreeStore giữ danh mục và thuộc tính danh mục trong bộ nhớ, so khớp path như các câu SQL
+type attributeTreeStore struct {
+	db_mysql.Store
+	categories map[string]db.Category
+	attributes []db.CategoryAttribute
+}
+
+func newAttributeTreeStore() *attributeTreeStore {
+	store := &attributeTreeStore{categories: map[string]db.Category{}}
+	for id, path := range map[string]string{
+		"dien-tu":    "/dien-tu",
+		"dien-thoai": "/dien-tu/dien-thoai",
+		"iphone":     "/dien-tu/dien-thoai/iphone",
+		"thoi-trang": "/thoi-trang",
+	} {
+		store.categories[id] = db.Category{CategoryID: id, Path: sql.NullString{String: path, Valid: true}}
+	}
+	store.attributes = []db.CategoryAttribute{
+		{ID: "a-1", CategoryID: "dien-thoai", Code: "ram"},
+		{ID: "a-2", CategoryID: "iphone", Code: "chip"},
+	}
+	return store
+}
+
+func (f *attributeTreeStore) GetCategory(ctx context.Context, categoryID string) (db.Category, error) {
+	c, ok := f.categories[categoryID]
+	if !ok {
+		return c, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (f *attributeTreeStore) ListCategoryAttributesByPath(ctx context.Context, path interface{}) ([]db.CategoryAttribute, error) {
+	var rows []db.CategoryAttribute
+	for _, a := range f.attributes {
+		if strings.HasPrefix(path.(string)+"/", f.categories[a.CategoryID].Path.String+"/") {
+			rows = append(rows, a)
+		}
+	}
+	return rows, nil
+}
+
+func (f *attributeTreeStore) CountDescendantAttributesByCode(ctx context.Context, arg db.CountDescendantAttributesByCodeParams) (int64, error) {
+	var total int64
+	parent := f.categories[arg.CategoryID].Path.String
+	for _, a := range f.attributes {
+		if a.Code == arg.Code && strings.HasPrefix(f.categories[a.CategoryID].Path.String, parent+"/") {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (f *attributeTreeStore) CreateCategoryAttribute(ctx context.Context, arg db.CreateCategoryAttributeParams) error {
+	f.attributes = append(f.attributes, db.CategoryAttribute{ID: arg.ID, CategoryID: arg.CategoryID, Code: arg.Code})
+	return nil
+}
+
+func TestCreateCategoryAttributeCodeConflicts(t *testing.T) {
+	ctx := context.Background()
+	store := newAttributeTreeStore()
+	s := &service{repository: store}
+	attr := func(categoryID, code string) services.CategoryAttribute {
+		return services.CategoryAttribute{CategoryID: categoryID, Code: code, Name: code, DataType: "NUMBER"}
+	}
+
+	tests := []struct {
+		categoryID, code string
+		want             int
+	}{
+		// trùng với danh mục cha hoặc chính danh mục
+		{"iphone", "ram", 409},
+		{"dien-thoai", "ram", 409},
+		// trùng với danh mục con (iphone có chip riêng)
+		{"dien-tu", "chip", 409},
+		{"dien-thoai", "chip", 409},
+		// khác nhánh thì được trùng
+		{"thoi-trang", "chip", 0},
+		{"dien-tu", "pin", 0},
+		{"khong-co", "pin", 404},
+	}
+	for _, tt := range tests {
+		serr := s.CreateCategoryAttribute(ctx, "admin", attr(tt.categoryID, tt.code))
+		if tt.want == 0 {
+			require.Nil(t, serr, "%s %s", tt.categoryID, tt.code)
+			continue
+		}
+		require.NotNil(t, serr, "%s %s", tt.categoryID, tt.code)
+		require.Equal(t, tt.want, serr.Code, "%s %s", tt.categoryID, tt.code)
+	}
+	require.Len(t, store.attributes, 4)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"errors"
 	"mime/multipart"
 	"time"
 )
@@ -35,6 +36,10 @@ type QueryFilter struct {
 	OrderBy    *OrderBy // Trường để sắp xếp
 	Page       int      // Trang hiện tại
 	PageSize   int      // Số lượng kết quả mỗi trang
+	// Phân trang theo cursor (keyset) thay cho Page, Cursor rỗng là trang đầu
+	CursorMode bool
+	Cursor     string
+	WithTotal  bool // chế độ cursor mặc định không đếm tổng số bản ghi
 }
 
 func NewQueryFilter(page int, pageSize int, conditions []Condition, orderBy *OrderBy) QueryFilter {
@@ -58,6 +63,9 @@ type Categorys struct {
 	Childs     Narg[[]Categorys] `json:"child"`
 	Parent     Narg[string]      `json:"parent"`
 	Image      Narg[string]      `json:"image"`
+	SortOrder  int32             `json:"sort_order"`
+	// số sản phẩm đang bán trong danh mục và toàn bộ danh mục con, tính lúc đọc nên không lưu trong cache
+	ProductCount int64 `json:"product_count"`
 }
 
 type Product struct {
@@ -132,6 +140,7 @@ type ProductParams struct {
 	ProductIsPermissionCheck  bool                  `json:"product_is_permission_check" `
 	ProductSKU                []ProductSKUParams    `json:"product_sku" `
 	OptionValue               []ProductOptionParams `json:"option_value" `
+	Attributes                map[string]string     `json:"attributes"`
 }
 
 type ProductSKUAttrParams struct {
@@ -163,6 +172,8 @@ type SkuResponse struct {
 	UpdateDate       time.Time `json:"update_date"`
 	SkuName          string    `json:"sku_name"`
 	OptionValueIDs   []string  `json:"option_value_ids"`
+	// giá thấp nhất trong 30 ngày gần nhất, tính cả giá hiện tại
+	LowestPrice30Days float64 `json:"lowest_price_30d"`
 }
 
 type ProductDetailResponse struct {
@@ -178,6 +189,44 @@ type ProductUpdateSKUReserver struct {
 	QuantityReserver int32  `json:"quantity_reserver"`
 }
 
+// SKUOrderInfo thông tin một SKU mà order service cần khi tạo đơn
+type SKUOrderInfo struct {
+	SkuID          string  `json:"sku_id"`
+	SkuCode        string  `json:"sku_code"`
+	SkuName        string  `json:"sku_name"`
+	ProductID      string  `json:"product_id"`
+	ProductName    string  `json:"product_name"`
+	Image          string  `json:"image"`
+	ShopID         string  `json:"shop_id"`
+	CategoryID     string  `json:"category_id"`
+	Price          float64 `json:"price"`
+	AvailableStock int32   `json:"available_stock"` // quantity - quantity_reserver
+	Weight         float64 `json:"weight"`
+	ProductStatus  string  `json:"product_status"` // chỉ sản phẩm Active mới được đặt hàng
+}
+
+type PriceRaiseReportParams struct {
+	PromotionStart     time.Time
+	Window             time.Duration // khoảng thời gian trước khuyến mãi cần kiểm tra
+	ShopID             string
+	MinIncreasePercent float64
+	Limit              int
+}
+
+type PriceRaiseItem struct {
+	SkuID           string    `json:"sku_id"`
+	SkuCode         string    `json:"sku_code"`
+	ProductID       string    `json:"product_id"`
+	ProductName     string    `json:"product_name"`
+	ShopID          string    `json:"shop_id"`
+	OldPrice        float64   `json:"old_price"`
+	NewPrice        float64   `json:"new_price"`
+	CurrentPrice    float64   `json:"current_price"`
+	IncreasePercent float64   `json:"increase_percent"`
+	ChangedBy       string    `json:"changed_by"`
+	ChangedAt       time.Time `json:"changed_at"`
+}
+
 type ProductUpdateType string
 
 const (
@@ -198,6 +247,9 @@ type ProductUpdateParams struct {
 	OptionValue               []ProductOptionParams `json:"option_value,omitempty"`
 	DeleteStatus              *bool                 `json:"delete_status" `
 	ApprovalProduct           *bool                 `json:"approval_product"`
+	RejectReason              string                `json:"reject_reason"`
+	CategoryID                *string               `json:"category_id,omitempty"`
+	Attributes                map[string]string     `json:"attributes,omitempty"` // nil: giữ nguyên thuộc tính hiện tại
 	// --- Cập nhật quản lý ảnh ---
 	RemoveMainImage *bool    `json:"remove_main_image,omitempty"` // Cờ để xóa ảnh chính
 	KeepMediaURLs   []string `json:"keep_media_urls,omitempty"`   // Giữ lại media URLs này
@@ -250,9 +302,19 @@ type ProductForSearch struct {
 
 // ProductRating represents rating information for a product
 type ProductRating struct {
+	ProductID     string           `json:"product_id"`
+	TotalReviews  int64            `json:"total_reviews"`
+	AverageRating float64          `json:"average_rating"`
+	BayesianScore float64          `json:"bayesian_score"`
+	Distribution  map[string]int64 `json:"distribution"`
+}
+
+// ProductRatingSync điểm đánh giá của sản phẩm do order service gửi sang
+type ProductRatingSync struct {
 	ProductID     string  `json:"product_id"`
-	TotalReviews  int64   `json:"total_reviews"`
-	AverageRating float64 `json:"average_rating"`
+	RatingAverage float64 `json:"rating_average"`
+	RatingCount   int32   `json:"rating_count"`
+	RatingScore   float64 `json:"rating_score"`
 }
 
 // ProductRatingStatsItem represents rating statistics for a single product
@@ -260,3 +322,79 @@ type ProductTotalSold struct {
 	ProductID string `json:"product_id"`
 	TotalSold int64  `json:"total_sold"`
 }
+
+type Brand struct {
+	BrandID string       `json:"brand_id"`
+	Name    string       `json:"name"`
+	Code    string       `json:"code"`
+	Image   Narg[string] `json:"image"`
+}
+
+type CategoryAttribute struct {
+	ID           string   `json:"id"`
+	CategoryID   string   `json:"category_id"`
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	DataType     string   `json:"data_type"`
+	Unit         string   `json:"unit"`
+	EnumValues   []string `json:"enum_values"`
+	IsRequired   bool     `json:"is_required"`
+	IsFilterable bool     `json:"is_filterable"`
+	SortOrder    int32    `json:"sort_order"`
+	Inherited    bool     `json:"inherited"` // thuộc tính kế thừa từ danh mục cha
+}
+
+type CategoryAttributeUpdate struct {
+	ID           string
+	Name         *string
+	Unit         *string
+	EnumValues   []string
+	IsRequired   *bool
+	IsFilterable *bool
+	SortOrder    *int32
+}
+
+type AttributeFacetValue struct {
+	Value string `json:"value"`
+	Total int64  `json:"total"`
+}
+
+type AttributeFacet struct {
+	Code     string                `json:"code"`
+	Name     string                `json:"name"`
+	DataType string                `json:"data_type"`
+	Unit     string                `json:"unit"`
+	Values   []AttributeFacetValue `json:"values"`
+}
+
+type BrandFacet struct {
+	BrandID string `json:"brand_id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Total   int64  `json:"total"`
+}
+
+// các loại gợi ý tìm kiếm
+const (
+	SuggestTypeProduct  = "product"
+	SuggestTypeBrand    = "brand"
+	SuggestTypeCategory = "category"
+)
+
+// SuggestEntry một mục trong chỉ mục gợi ý (sản phẩm, thương hiệu hoặc danh mục)
+type SuggestEntry struct {
+	Type   string  `json:"type"`
+	ID     string  `json:"id"`
+	Text   string  `json:"text"`
+	Key    string  `json:"key"`    // key sản phẩm, mã thương hiệu hoặc path danh mục
+	Folded string  `json:"folded"` // Text đã bỏ dấu, dùng để sinh tiền tố
+	Score  float64 `json:"score"`  // total_sold
+}
+
+type SuggestQuery struct {
+	Query string  `json:"query"`
+	Count float64 `json:"count"` // số lần tìm đã giảm dần theo thời gian
+}
+
+// ErrSuggestRebuildRunning trả về khi một lần dựng lại chỉ mục gợi ý khác đang chạy
+var ErrSuggestRebuildRunning = errors.New("chỉ mục gợi ý đang được dựng lại")
@@ -9,3 +9,19 @@ type Narg[T any] struct {
 	Data  T    `json:"data"`
 	Valid bool `json:"valid"`
 }
+
+// Principal thông tin người dùng đang thao tác, lấy từ token đã xác thực
+type Principal struct {
+	UserID   string
+	UserName string
+	Role     string
+	ShopID   string // shop trong claim "shopId" của token, rỗng nếu token không có
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.Role == "ROLE_ADMIN"
+}
+
+func (p Principal) IsSeller() bool {
+	return p.Role == "ROLE_SELLER"
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	services "github.com/TranVinhHien/ecom_product_service/services/entity"
 	iservices "github.com/TranVinhHien/ecom_product_service/services/interface"
@@ -12,7 +13,12 @@ import (
 //	}
 type ServiceUseCase interface {
 	iservices.Categories
+	iservices.Brands
+	iservices.CategoryAttributes
 	iservices.Products
+	iservices.ProductModeration
+	iservices.ProductStatus
+	iservices.ShopOwnership
 	iservices.Media
 }
 
@@ -26,6 +32,29 @@ type ServicesRedis interface {
 	AddCategories(ctx context.Context, cates []services.Categorys) error
 	RemoveCategories(ctx context.Context) error
 	GetCategoryTree(ctx context.Context, rootID string) ([]services.Categorys, error)
+	GetCategoryProductCounts(ctx context.Context) (map[string]int64, error)
+	SetCategoryProductCounts(ctx context.Context, counts map[string]int64, ttl time.Duration) error
+	// brand
+	AddBrands(ctx context.Context, brands []services.Brand) error
+	RemoveBrands(ctx context.Context) error
+	GetBrands(ctx context.Context) ([]services.Brand, error)
+	GetBrand(ctx context.Context, brandID string) (*services.Brand, error)
+	// suggest
+	HasSuggestions(ctx context.Context) (bool, error)
+	ReplaceSuggestions(ctx context.Context, entries []services.SuggestEntry) error
+	UpsertSuggestion(ctx context.Context, entry services.SuggestEntry) error
+	RemoveSuggestion(ctx context.Context, entryType, id string) error
+	SearchSuggestions(ctx context.Context, entryType, prefix string, limit int) ([]services.SuggestEntry, error)
+	LogSearchQuery(ctx context.Context, query string) error
+	SuggestQueries(ctx context.Context, prefix string, limit int) ([]services.SuggestQuery, error)
+	TopSearchQueries(ctx context.Context, limit int) ([]services.SuggestQuery, error)
+	// product detail cache
+	ProductDetailVersion(ctx context.Context, productID string) (int64, error)
+	GetProductDetail(ctx context.Context, kind, productID string, version int64) (map[string]interface{}, error)
+	SetProductDetail(ctx context.Context, kind, productID string, version int64, detail map[string]interface{}, ttl time.Duration) error
+	ProductIDBySlug(ctx context.Context, key string) (string, error)
+	SetProductIDBySlug(ctx context.Context, key, productID string, ttl time.Duration) error
+	InvalidateProductDetail(ctx context.Context, productIDs []string, slugs ...string) error
 
 	DeleteOrderOnline(ctx context.Context, orderID string) error
 }
@@ -12,20 +12,36 @@ type Categories interface {
 	GetCategoris(ctx context.Context, cate_id string) (map[string]interface{}, *assets_services.ServiceError)
 	AddCategory(ctx context.Context, userName string, cat services.Categorys, file *multipart.FileHeader) *assets_services.ServiceError
 	UpdateCategory(ctx context.Context, userName string, cat services.Categorys, file *multipart.FileHeader) *assets_services.ServiceError
-	DeleteCategory(ctx context.Context, userName, categoryID string) *assets_services.ServiceError
+	DeleteCategory(ctx context.Context, userName, categoryID, reassignTo string) *assets_services.ServiceError
+	MoveCategory(ctx context.Context, userName, categoryID, parentID string) *assets_services.ServiceError
+	ReorderCategories(ctx context.Context, userName, parentID string, categoryIDs []string) *assets_services.ServiceError
+}
+type Brands interface {
+	ListBrands(ctx context.Context, query services.QueryFilter) (map[string]interface{}, *assets_services.ServiceError)
+	SearchBrands(ctx context.Context, keyword string) (map[string]interface{}, *assets_services.ServiceError)
+	GetBrandDetail(ctx context.Context, key string) (map[string]interface{}, *assets_services.ServiceError)
+	CreateBrand(ctx context.Context, userName string, brand services.Brand, image *multipart.FileHeader) *assets_services.ServiceError
+	UpdateBrand(ctx context.Context, userName string, brand services.Brand, image *multipart.FileHeader) *assets_services.ServiceError
+	DeleteBrand(ctx context.Context, userName, brandID, reassignTo string) *assets_services.ServiceError
+}
+type CategoryAttributes interface {
+	ListCategoryAttributes(ctx context.Context, categoryID string) (map[string]interface{}, *assets_services.ServiceError)
+	CreateCategoryAttribute(ctx context.Context, userName string, attr services.CategoryAttribute) *assets_services.ServiceError
+	UpdateCategoryAttribute(ctx context.Context, userName string, attr services.CategoryAttributeUpdate) *assets_services.ServiceError
+	DeleteCategoryAttribute(ctx context.Context, userName, attributeID string) *assets_services.ServiceError
 }
 type Products interface {
 	UpdateSKUReserverProduct(ctx context.Context, productSKU []services.ProductUpdateSKUReserver, type_req services.ProductUpdateType) *assets_services.ServiceError
-	GetAllProductSimple(ctx context.Context, query services.QueryFilter, category_path, brand_code, shop_id, keywords, sort string, min_price, max_price float64, status string) (map[string]interface{}, *assets_services.ServiceError)
+	GetAllProductSimple(ctx context.Context, principal services.Principal, query services.QueryFilter, category_path, brand_code, shop_id, keywords, sort string, min_price, max_price float64, status string, attributes map[string]string) (map[string]interface{}, *assets_services.ServiceError)
 	GetDetailProduct(ctx context.Context, productSpuID string) (map[string]interface{}, *assets_services.ServiceError)
-	CreateProduct(ctx context.Context, token, userName string, product services.ProductParams, image *multipart.FileHeader, mediaFiles []*multipart.FileHeader, optionImages []struct {
+	CreateProduct(ctx context.Context, token string, principal services.Principal, product services.ProductParams, image *multipart.FileHeader, mediaFiles []*multipart.FileHeader, optionImages []struct {
 		OptionName string
 		Value      string
 		Image      *multipart.FileHeader
 	}) *assets_services.ServiceError
 	UpdateProduct(
 		ctx context.Context,
-		token, userName, productID string,
+		principal services.Principal, productID string,
 		product services.ProductUpdateParams, // Struct chứa dữ liệu JSON
 		mainImage *multipart.FileHeader, // Ảnh chính mới (nếu có)
 		newMediaFiles []*multipart.FileHeader, // Ảnh media mới (nếu có)
@@ -33,13 +49,47 @@ type Products interface {
 
 	) *assets_services.ServiceError
 	GetSKUProduct(ctx context.Context, product_sku_id string) (map[string]interface{}, *assets_services.ServiceError)
+	GetSKUsBatch(ctx context.Context, skuIDs []string) (map[string]interface{}, *assets_services.ServiceError)
+	GetSKUPriceHistory(ctx context.Context, skuID string, limit int) (map[string]interface{}, *assets_services.ServiceError)
+	PriceRaiseReport(ctx context.Context, params services.PriceRaiseReportParams) (map[string]interface{}, *assets_services.ServiceError)
 	GetProductWithID(ctx context.Context, product_id string) (map[string]interface{}, *assets_services.ServiceError)
 	BuildProductSearchString(ctx context.Context, productID string) (string, error)
 	GetALLProductID(ctx context.Context) ([]string, *assets_services.ServiceError)
 	GetListProductWithIDs(ctx context.Context, productID []string) (map[string]interface{}, *assets_services.ServiceError)
+	ReindexProductAggregates(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+	ReindexProductSearch(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+	StartSearchIndexSync(ctx context.Context)
+	Suggest(ctx context.Context, q string, limit int) (map[string]interface{}, *assets_services.ServiceError)
+	TopSearchQueries(ctx context.Context, limit int) (map[string]interface{}, *assets_services.ServiceError)
+	RebuildSuggestIndex(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+	StartSuggestIndexSync(ctx context.Context)
+	InvalidateProductDetailCache(ctx context.Context, productIDs []string) *assets_services.ServiceError
+	SyncProductRatings(ctx context.Context, ratings []services.ProductRatingSync) *assets_services.ServiceError
+	ProductDetailCacheStats(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+}
+type ProductModeration interface {
+	ApproveProduct(ctx context.Context, userName, productID string) *assets_services.ServiceError
+	RejectProduct(ctx context.Context, userName, productID, reason string) *assets_services.ServiceError
+	ListProductModeration(ctx context.Context, principal services.Principal, productID string) (map[string]interface{}, *assets_services.ServiceError)
+}
+type ProductStatus interface {
+	UnlistProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError
+	RelistProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError
+	SoftDeleteProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError
+	RestoreProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError
+}
+type ShopOwnership interface {
+	AssignSellerShop(ctx context.Context, userName, userID, shopID string) *assets_services.ServiceError
+	RemoveSellerShop(ctx context.Context, userID, shopID string) *assets_services.ServiceError
+	ListSellerShops(ctx context.Context, userID string) (map[string]interface{}, *assets_services.ServiceError)
+	ListOwnershipAudit(ctx context.Context, query services.QueryFilter) (map[string]interface{}, *assets_services.ServiceError)
 }
 type Media interface {
-	RenderImage(ctx context.Context, id string) string
+	RenderImage(ctx context.Context, id, size string) string
 	UploadMultiMedia(ctx context.Context, user_id string, files []*multipart.FileHeader) (result []string, err *assets_services.ServiceError)
+	UploadReviewMedia(ctx context.Context, user_id string, files []*multipart.FileHeader) (result []string, err *assets_services.ServiceError)
 	DeleteMultiImage(ctx context.Context, user_id string, image_files []string) (err *assets_services.ServiceError)
+	DeleteOwnedMedia(ctx context.Context, principal services.Principal, image_files []string) *assets_services.ServiceError
+	ReapOrphanMedia(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError)
+	StartMediaReaper(ctx context.Context)
 }
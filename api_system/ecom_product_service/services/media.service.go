@@ -1,84 +1,404 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"log"
 	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
 	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
 	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
 )
 
-func (s *service) RenderImage(ctx context.Context, id string) string {
-	filePath := s.env.ImagePath + id
-	return filePath
+const (
+	defaultMediaMaxUploadSize    = 10 << 20 // 10MB
+	defaultMediaMaxDimension     = 6000
+	defaultMediaMaxPixels        = 25_000_000
+	defaultMediaMaxVideoSize     = 30 << 20 // 30MB
+	defaultMediaMaxVideoDuration = time.Minute
+
+	// số file được xử lý (đọc, giải mã, thu nhỏ) cùng lúc trên toàn service
+	mediaMaxConcurrentProcessing = 4
+)
+
+// mediaProcessSlots semaphore giới hạn số file xử lý đồng thời để nhiều lần upload cùng lúc không làm cạn bộ nhớ
+var mediaProcessSlots = make(chan struct{}, mediaMaxConcurrentProcessing)
+
+// các bản thu nhỏ sinh ra khi upload, theo cạnh dài nhất (pixel), xếp từ lớn đến nhỏ
+var mediaVariants = []struct {
+	Name    string
+	MaxSide int
+}{
+	{"large", 1200},
+	{"medium", 600},
+	{"thumb", 200},
+}
+
+func (s *service) mediaMaxUploadSize() int64 {
+	if s.env.MediaMaxUploadSize > 0 {
+		return s.env.MediaMaxUploadSize
+	}
+	return defaultMediaMaxUploadSize
+}
+
+func (s *service) mediaMaxDimension() int {
+	if s.env.MediaMaxDimension > 0 {
+		return s.env.MediaMaxDimension
+	}
+	return defaultMediaMaxDimension
+}
+
+func (s *service) mediaMaxPixels() int64 {
+	if s.env.MediaMaxPixels > 0 {
+		return s.env.MediaMaxPixels
+	}
+	return defaultMediaMaxPixels
+}
+
+func (s *service) mediaMaxVideoSize() int64 {
+	if s.env.MediaMaxVideoSize > 0 {
+		return s.env.MediaMaxVideoSize
+	}
+	return defaultMediaMaxVideoSize
+}
+
+func (s *service) mediaMaxVideoDuration() time.Duration {
+	if s.env.MediaMaxVideoDuration > 0 {
+		return s.env.MediaMaxVideoDuration
+	}
+	return defaultMediaMaxVideoDuration
+}
+
+// IsMediaVariant kiểm tra tên kích thước ảnh client yêu cầu
+func IsMediaVariant(size string) bool {
+	for _, v := range mediaVariants {
+		if v.Name == size {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaVariantFileName tên file của bản thu nhỏ, luôn lưu dạng JPEG cạnh file gốc
+func mediaVariantFileName(id, size string) string {
+	return strings.TrimSuffix(id, filepath.Ext(id)) + "_" + size + ".jpg"
+}
+
+// validMediaID chặn tên file chứa đường dẫn (../) khi đọc/xóa file
+func validMediaID(id string) bool {
+	return id != "" && id != "." && id != ".." && filepath.Base(id) == id
+}
+
+// RenderImage trả về đường dẫn file cần phục vụ. size rỗng hoặc bản thu nhỏ chưa có
+// (ảnh gốc nhỏ hơn kích thước yêu cầu, ảnh upload trước khi có bản thu nhỏ) thì trả về ảnh gốc.
+func (s *service) RenderImage(ctx context.Context, id, size string) string {
+	if !validMediaID(id) {
+		return ""
+	}
+	if size != "" {
+		variantPath := filepath.Join(s.env.ImagePath, mediaVariantFileName(id, size))
+		if _, err := os.Stat(variantPath); err == nil {
+			return variantPath
+		}
+	}
+	return filepath.Join(s.env.ImagePath, id)
+}
+
+// saveMediaFile kiểm tra file theo nội dung, lưu file gốc cùng các bản thu nhỏ và ghi nhận người upload.
+// allowVideo cho phép thêm video MP4 ngắn (đánh giá sản phẩm), ảnh sản phẩm/thương hiệu chỉ nhận ảnh.
+func (s *service) saveMediaFile(ctx context.Context, file *multipart.FileHeader, userID string, allowVideo bool) (*services.Media, error) {
+	maxSize := s.mediaMaxUploadSize()
+	readLimit := maxSize
+	if allowVideo && s.mediaMaxVideoSize() > readLimit {
+		readLimit = s.mediaMaxVideoSize()
+	}
+	if file.Size > readLimit {
+		return nil, fmt.Errorf("file %s vượt quá dung lượng cho phép (%d bytes)", file.Filename, readLimit)
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, readLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("không thể đọc file %s: %w", file.Filename, err)
+	}
+	if int64(len(data)) > readLimit {
+		return nil, fmt.Errorf("file %s vượt quá dung lượng cho phép (%d bytes)", file.Filename, readLimit)
+	}
+
+	mimeType, ext, err := assets_services.DetectImageType(data)
+	if err != nil && allowVideo {
+		if _, _, videoErr := assets_services.DetectVideoType(data); videoErr == nil {
+			return s.saveVideoFile(ctx, file.Filename, data, userID)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", file.Filename, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("file %s vượt quá dung lượng cho phép (%d bytes)", file.Filename, maxSize)
+	}
+	width, height, err := assets_services.ImageSize(mimeType, data)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", file.Filename, err)
+	}
+	maxDim := s.mediaMaxDimension()
+	if width <= 0 || height <= 0 || width > maxDim || height > maxDim {
+		return nil, fmt.Errorf("file %s có kích thước %dx%d, cạnh ảnh tối đa %d pixel", file.Filename, width, height, maxDim)
+	}
+	if maxPixels := s.mediaMaxPixels(); int64(width)*int64(height) > maxPixels {
+		return nil, fmt.Errorf("file %s có %d pixel, tối đa %d pixel", file.Filename, int64(width)*int64(height), maxPixels)
+	}
+
+	fileName := uuid.NewString() + ext
+	if err := assets_services.WriteFile(s.env.ImagePath, fileName, data); err != nil {
+		return nil, err
+	}
+	if err := s.writeMediaVariants(fileName, data); err != nil {
+		s.removeMediaFiles(fileName)
+		return nil, err
+	}
+	err = s.repository.CreateMedia(ctx, db.CreateMediaParams{
+		ID:       fileName,
+		Owner:    userID,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+		Width:    int32(width),
+		Height:   int32(height),
+	})
+	if err != nil {
+		s.removeMediaFiles(fileName)
+		return nil, fmt.Errorf("không thể lưu thông tin media: %w", err)
+	}
+
+	return &services.Media{
+		ID:        fileName,
+		FileName:  fileName,
+		Size:      int64(len(data)),
+		FilePath:  s.env.ImagePath,
+		FileType:  mimeType,
+		MediaType: "image",
+		Width:     width,
+		Height:    height,
+		CreatedAt: time.Now(),
+		CreateBy:  userID,
+	}, nil
+}
+
+// saveVideoFile lưu video MP4 sau khi kiểm tra dung lượng và thời lượng, video không có bản thu nhỏ
+func (s *service) saveVideoFile(ctx context.Context, originalName string, data []byte, userID string) (*services.Media, error) {
+	mimeType, ext, err := assets_services.DetectVideoType(data)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", originalName, err)
+	}
+	if maxSize := s.mediaMaxVideoSize(); int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("video %s vượt quá dung lượng cho phép (%d bytes)", originalName, maxSize)
+	}
+	duration, err := assets_services.MP4Duration(data)
+	if err != nil {
+		return nil, fmt.Errorf("video %s: %w", originalName, err)
+	}
+	if maxDuration := s.mediaMaxVideoDuration(); duration > maxDuration {
+		return nil, fmt.Errorf("video %s dài %s, thời lượng tối đa %s", originalName, duration.Round(time.Second), maxDuration)
+	}
+
+	fileName := uuid.NewString() + ext
+	if err := assets_services.WriteFile(s.env.ImagePath, fileName, data); err != nil {
+		return nil, err
+	}
+	err = s.repository.CreateMedia(ctx, db.CreateMediaParams{
+		ID:       fileName,
+		Owner:    userID,
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+	})
+	if err != nil {
+		s.removeMediaFiles(fileName)
+		return nil, fmt.Errorf("không thể lưu thông tin media: %w", err)
+	}
+
+	return &services.Media{
+		ID:        fileName,
+		FileName:  fileName,
+		Size:      int64(len(data)),
+		FilePath:  s.env.ImagePath,
+		FileType:  mimeType,
+		MediaType: "video",
+		Duration:  int64(duration / time.Second),
+		CreatedAt: time.Now(),
+		CreateBy:  userID,
+	}, nil
 }
-func saveMediaFiles(files []*multipart.FileHeader, dstDir string, userID string) ([]services.Media, error) {
+
+// writeMediaVariants sinh các bản thu nhỏ (JPEG, kể cả ảnh gốc WebP), bản nhỏ hơn được thu từ bản lớn hơn liền trước để đỡ tốn CPU.
+// Ảnh gốc đã nhỏ hơn kích thước của bản thu nhỏ thì bỏ qua, khi đọc sẽ trả về ảnh gốc.
+// Số pixel được kiểm tra từ header trước khi giải mã toàn bộ ảnh.
+func (s *service) writeMediaVariants(fileName string, data []byte) error {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("không đọc được kích thước ảnh: %w", err)
+	}
+	if maxPixels := s.mediaMaxPixels(); int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return fmt.Errorf("ảnh có %d pixel, tối đa %d pixel", int64(cfg.Width)*int64(cfg.Height), maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("không thể giải mã ảnh: %w", err)
+	}
+	for _, v := range mediaVariants {
+		b := img.Bounds()
+		if b.Dx() <= v.MaxSide && b.Dy() <= v.MaxSide {
+			continue
+		}
+		img = assets_services.ResizeImage(img, v.MaxSide)
+		var buf bytes.Buffer
+		if err := assets_services.EncodeJPEG(&buf, img); err != nil {
+			return fmt.Errorf("không thể tạo ảnh %s: %w", v.Name, err)
+		}
+		if err := assets_services.WriteFile(s.env.ImagePath, mediaVariantFileName(fileName, v.Name), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMediaFiles xóa file gốc và các bản thu nhỏ, bỏ qua file không tồn tại
+func (s *service) removeMediaFiles(fileName string) error {
+	names := []string{fileName}
+	for _, v := range mediaVariants {
+		names = append(names, mediaVariantFileName(fileName, v.Name))
+	}
+	for _, name := range names {
+		if err := assets_services.DeleteFile(s.env.ImagePath, name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMedia xóa file cùng bản ghi media
+func (s *service) removeMedia(ctx context.Context, fileName string) error {
+	if !validMediaID(fileName) {
+		return fmt.Errorf("tên file không hợp lệ: %s", fileName)
+	}
+	if err := s.removeMediaFiles(fileName); err != nil {
+		return err
+	}
+	if err := s.repository.DeleteMedia(ctx, fileName); err != nil {
+		return fmt.Errorf("không thể xóa thông tin media %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// saveMediaFiles lưu nhiều file song song (tối đa mediaMaxConcurrentProcessing file cùng lúc), giữ đúng thứ tự upload.
+// Một file lỗi thì hủy cả lần upload và xóa các file đã lưu để không để lại file mồ côi.
+func (s *service) saveMediaFiles(ctx context.Context, files []*multipart.FileHeader, userID string, allowVideo bool) ([]services.Media, error) {
 	var wg sync.WaitGroup
-	resultCh := make(chan services.Media, len(files))
-	errCh := make(chan error, len(files))
+	results := make([]*services.Media, len(files))
+	errs := make([]error, len(files))
 
-	for _, f := range files {
+	for i, f := range files {
+		// chờ tới lượt trước khi tạo goroutine, client hủy request thì các file còn lại không được xử lý
+		select {
+		case mediaProcessSlots <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
 		wg.Add(1)
-		go func(file *multipart.FileHeader) {
+		go func(i int, file *multipart.FileHeader) {
 			defer wg.Done()
-			if file_name, err := assets_services.SaveFile(file, dstDir); err != nil {
-				errCh <- err
-			} else {
-				resultCh <- services.Media{
-					FileName:  *file_name,
-					Size:      file.Size,
-					FilePath:  dstDir,
-					MediaType: file.Header.Get("Content-Type"),
-					CreatedAt: time.Now(),
-					CreateBy:  userID,
-				}
-			}
-		}(f)
+			defer func() { <-mediaProcessSlots }()
+			results[i], errs[i] = s.saveMediaFile(ctx, file, userID, allowVideo)
+		}(i, f)
 	}
-
 	wg.Wait()
-	close(resultCh)
-	close(errCh)
 
-	var medias []services.Media
-	for m := range resultCh {
-		medias = append(medias, m)
+	var firstErr error
+	medias := make([]services.Media, 0, len(files))
+	for i := range files {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		medias = append(medias, *results[i])
 	}
-
-	if len(errCh) > 0 {
-		return medias, <-errCh
+	if firstErr != nil {
+		for _, m := range medias {
+			if err := s.removeMedia(ctx, m.FileName); err != nil {
+				log.Printf("[Media] không thể xóa file %s sau khi upload lỗi: %v", m.FileName, err)
+			}
+		}
+		return nil, firstErr
 	}
 	return medias, nil
 }
 
 func (s *service) UploadMultiMedia(ctx context.Context, user_id string, files []*multipart.FileHeader) (result []string, err *assets_services.ServiceError) {
+	return s.uploadMedia(ctx, user_id, files, false)
+}
+
+// UploadReviewMedia upload qua API /media/reviews: nhận ảnh và video MP4 ngắn (ảnh/video đánh giá sản phẩm)
+func (s *service) UploadReviewMedia(ctx context.Context, user_id string, files []*multipart.FileHeader) (result []string, err *assets_services.ServiceError) {
+	return s.uploadMedia(ctx, user_id, files, true)
+}
+
+func (s *service) uploadMedia(ctx context.Context, user_id string, files []*multipart.FileHeader, allowVideo bool) (result []string, err *assets_services.ServiceError) {
 	// upload file to local folder
-	medias, errors := saveMediaFiles(files, s.env.ImagePath, user_id)
+	medias, errors := s.saveMediaFiles(ctx, files, user_id, allowVideo)
 	if errors != nil {
 		return nil, &assets_services.ServiceError{Code: 400, Err: errors}
 	}
-	// urls:=
 	urls := make([]string, 0, len(medias))
 	for _, media := range medias {
-		// urls = append(urls, s.env.HTTPServerAddress+"/v1/media/stream/"+media.FileName)
 		urls = append(urls, media.FileName)
 	}
-	// result, errors = assets_services.HideFields(urls, "urls")
-	// if errors != nil {
-	// 	return nil, &assets_services.ServiceError{Code: 400, Err: errors}
-	// }
 	return urls, nil
 }
 
 func (s *service) DeleteMultiImage(ctx context.Context, user_id string, image_files []string) (err *assets_services.ServiceError) {
-
 	for _, image := range image_files {
-		errors := assets_services.DeleteFile(s.env.ImagePath, image)
+		errors := s.removeMedia(ctx, image)
 		if errors != nil {
 			return &assets_services.ServiceError{Code: 400, Err: errors}
 		}
 	}
 	return nil
 }
+
+// DeleteOwnedMedia xóa media theo yêu cầu của người dùng, chỉ người upload hoặc admin được xóa.
+// File upload trước khi có bảng media không rõ chủ nên chỉ admin được xóa.
+func (s *service) DeleteOwnedMedia(ctx context.Context, principal services.Principal, image_files []string) *assets_services.ServiceError {
+	if !principal.IsAdmin() {
+		for _, id := range image_files {
+			media, err := s.repository.GetMedia(ctx, id)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return assets_services.NewError(403, fmt.Errorf("bạn không có quyền xóa file %s", id))
+				}
+				return assets_services.NewError(500, fmt.Errorf("lỗi khi lấy thông tin media: %w", err))
+			}
+			if media.Owner != principal.UserName {
+				return assets_services.NewError(403, fmt.Errorf("bạn không có quyền xóa file %s", id))
+			}
+		}
+	}
+	return s.DeleteMultiImage(ctx, principal.UserName, image_files)
+}
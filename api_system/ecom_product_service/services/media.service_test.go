@@ -0,0 +1,50 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+
+	config_assets "github.com/TranVinhHien/ecom_product_service/assets/config"
+	"github.com/stretchr/testify/require"
+)
+
+// ảnh WebP lossless 75x100 (gopher-doc.1bpp.lossless.webp trong testdata của golang.org/x/image)
+const testWebP = "UklGRrIBAABXRUJQVlA4TKUBAAAvSsAYAA8w//M///MfeJAkbXvaSG7m8Q3GfYSBJekwQztm/IcZlgwnmWImn2BK7aFmBtnVir6q//8VOkFE/xm4baTIu8c48ArEo6+B3zFKYln3pqClSCKX0begFTAXFOLXHSyF8cCNcZEG4OywuA4KVVfJCiArU7GAgJI8+lJP/OKMT/fBAjevg1cYB7YVkFuWga2lyPi5I0HFy5YTpWIHg0RZpkniRVW9odHAKOwosWuOGdxIyn2OvaCDvhg/we6TwadPBPbqBV58MsLmMJ8yZnOWk8SRz4N+QoyPL+MnamzMvcE1rHNEr91F9GKZPVUcS9w7PhhH36suB9qPeYb/oLk6cuTiJ0wOK3m5h1cKjW6EVZCYMK7dxcKCBdgP9HkKr9gkAO2P8GKZGWVdIAatQa+1IDpt6qyorVwdy01xdW8Jkfk6xjEXmVQQ+HQdFr6OKhIN34dXWq0+0qr6EJSCeeVLH9+gvGTLyqM65PQ44ihzlTXxQKjKbAvshXgir7Lil9w4L2bvMycmjQcqXaMCO6BlY28i+FOLzbfI1vEqxAhotocAAA=="
+
+func TestWriteMediaVariantsWebP(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(testWebP)
+	require.NoError(t, err)
+	saved := mediaVariants
+	defer func() { mediaVariants = saved }()
+	mediaVariants = []struct {
+		Name    string
+		MaxSide int
+	}{{"thumb", 50}}
+
+	dir := t.TempDir()
+	s := &service{env: config_assets.ReadENV{ImagePath: dir}}
+	require.NoError(t, s.writeMediaVariants("anh.webp", data))
+
+	variant, err := os.ReadFile(filepath.Join(dir, mediaVariantFileName("anh.webp", "thumb")))
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(variant))
+	require.NoError(t, err)
+	require.Equal(t, 37, img.Bounds().Dx())
+	require.Equal(t, 50, img.Bounds().Dy())
+}
+
+func TestWriteMediaVariantsPixelLimit(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(testWebP)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	// 75x100 = 7500 pixel vượt giới hạn thì không giải mã
+	s := &service{env: config_assets.ReadENV{ImagePath: dir, MediaMaxPixels: 5000}}
+	require.Error(t, s.writeMediaVariants("anh.webp", data))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
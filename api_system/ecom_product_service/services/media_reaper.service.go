@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+)
+
+const (
+	defaultMediaReaperInterval = 6 * time.Hour
+	// file vừa upload chưa kịp gắn vào sản phẩm/danh mục (vd CreateProduct upload ảnh trước transaction)
+	defaultMediaOrphanGrace = 24 * time.Hour
+	mediaReaperBatchSize    = 200
+)
+
+func (s *service) mediaReaperInterval() time.Duration {
+	if s.env.MediaReaperInterval > 0 {
+		return s.env.MediaReaperInterval
+	}
+	return defaultMediaReaperInterval
+}
+
+func (s *service) mediaOrphanGrace() time.Duration {
+	if s.env.MediaOrphanGrace > 0 {
+		return s.env.MediaOrphanGrace
+	}
+	return defaultMediaOrphanGrace
+}
+
+// StartMediaReaper định kỳ xóa file media không còn được sản phẩm, danh mục, thương hiệu hay bình luận nào sử dụng.
+// Chạy trong goroutine riêng, dừng khi ctx bị hủy.
+func (s *service) StartMediaReaper(ctx context.Context) {
+	ticker := time.NewTicker(s.mediaReaperInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scanned, deleted, err := s.reapOrphanMedia(ctx)
+			if err != nil {
+				log.Printf("[Media] dọn file media lỗi sau khi kiểm tra %d file: %v", scanned, err)
+			}
+			if deleted > 0 {
+				log.Printf("[Media] đã xóa %d/%d file media không còn được sử dụng", deleted, scanned)
+			}
+		}
+	}
+}
+
+// ReapOrphanMedia chạy dọn file media ngay (admin)
+func (s *service) ReapOrphanMedia(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	scanned, deleted, err := s.reapOrphanMedia(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("lỗi khi dọn file media (đã xóa %d file): %w", deleted, err))
+	}
+	return map[string]interface{}{"scanned": scanned, "deleted": deleted}, nil
+}
+
+// reapOrphanMedia duyệt các media đã qua thời gian chờ theo từng lô. File không còn tham chiếu trong DB sản phẩm
+// được hỏi tiếp order service (bình luận); không hỏi được thì dừng, không xóa gì thêm.
+func (s *service) reapOrphanMedia(ctx context.Context) (scanned, deleted int, err error) {
+	createdBefore := time.Now().Add(-s.mediaOrphanGrace())
+	afterID := ""
+	for {
+		rows, err := s.repository.ListMediaCreatedBefore(ctx, db.ListMediaCreatedBeforeParams{
+			CreatedBefore: createdBefore,
+			AfterID:       afterID,
+			Limit:         mediaReaperBatchSize,
+		})
+		if err != nil {
+			return scanned, deleted, fmt.Errorf("không thể lấy danh sách media: %w", err)
+		}
+		if len(rows) == 0 {
+			return scanned, deleted, nil
+		}
+		afterID = rows[len(rows)-1].ID
+
+		candidates := make([]string, 0, len(rows))
+		for _, row := range rows {
+			scanned++
+			count, err := s.repository.CountMediaReferences(ctx, row.ID)
+			if err != nil {
+				return scanned, deleted, fmt.Errorf("không thể kiểm tra tham chiếu của media %s: %w", row.ID, err)
+			}
+			if count == 0 {
+				candidates = append(candidates, row.ID)
+			}
+		}
+		if len(candidates) > 0 {
+			usedByComments, err := s.apiServer.ListCommentMediaReferences(ctx, s.env.TokenSystem, candidates)
+			if err != nil {
+				return scanned, deleted, fmt.Errorf("không thể kiểm tra media của bình luận: %w", err)
+			}
+			used := make(map[string]bool, len(usedByComments))
+			for _, id := range usedByComments {
+				used[id] = true
+			}
+			for _, id := range candidates {
+				if used[id] {
+					continue
+				}
+				if err := s.removeMedia(ctx, id); err != nil {
+					log.Printf("[Media] không thể xóa media %s: %v", id, err)
+					continue
+				}
+				deleted++
+			}
+		}
+		if len(rows) < mediaReaperBatchSize {
+			return scanned, deleted, nil
+		}
+	}
+}
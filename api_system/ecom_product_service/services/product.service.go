@@ -9,6 +9,7 @@ import (
 	"mime/multipart"
 	"strings"
 
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
 	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
 	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
 	services "github.com/TranVinhHien/ecom_product_service/services/entity"
@@ -26,20 +27,105 @@ func (s *service) GetSKUProduct(ctx context.Context, product_sku_id string) (map
 	}
 
 	result := assets_services.NormalizeSQLNulls(product_sku, "data")
+	// trạng thái sản phẩm để bên đặt hàng từ chối sản phẩm đã ẩn hoặc đã xóa
+	products, err := s.repository.GetProductIDs(ctx, []string{product_sku.ProductID})
+	if err != nil || len(products) == 0 {
+		return nil, assets_services.NewError(400, fmt.Errorf("không tìm thấy sản phẩm của SKU: %s", product_sku_id))
+	}
+	if data, ok := result["data"].(map[string]interface{}); ok {
+		data["product_status"] = string(products[0].DeleteStatus.ProductDeleteStatus)
+	}
 	//log.Printf("[GetSKUProduct] Thành công lấy thông tin SKU với ID: %s", product_sku_id)
 	return result, nil
 }
-func (s *service) GetAllProductSimple(ctx context.Context, query services.QueryFilter, category_path, brand_code, shop_id, keywords, sort string, min_price, max_price float64, status string) (map[string]interface{}, *assets_services.ServiceError) {
+
+// số SKU tối đa trong một lần tra cứu hàng loạt (một giỏ hàng)
+const maxSKUBatchSize = 200
+
+// GetSKUsBatch tra cứu nhiều SKU trong một truy vấn cho order service, SKU không tồn tại nằm trong "missing"
+func (s *service) GetSKUsBatch(ctx context.Context, skuIDs []string) (map[string]interface{}, *assets_services.ServiceError) {
+	seen := make(map[string]bool, len(skuIDs))
+	ids := make([]string, 0, len(skuIDs))
+	for _, id := range skuIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, assets_services.NewError(400, fmt.Errorf("danh sách sku_ids không được để trống"))
+	}
+	if len(ids) > maxSKUBatchSize {
+		return nil, assets_services.NewError(400, fmt.Errorf("chỉ được tra cứu tối đa %d SKU mỗi lần", maxSKUBatchSize))
+	}
+
+	rows, err := s.repository.ListSKUsForOrder(ctx, ids)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách SKU. Lỗi: %s", err.Error()))
+	}
+	items := make([]services.SKUOrderInfo, 0, len(rows))
+	for _, row := range rows {
+		delete(seen, row.ID)
+		items = append(items, services.SKUOrderInfo{
+			SkuID:          row.ID,
+			SkuCode:        row.SkuCode,
+			SkuName:        row.SkuName.String,
+			ProductID:      row.ProductID,
+			ProductName:    row.ProductName,
+			Image:          row.ProductImage,
+			ShopID:         row.ShopID,
+			CategoryID:     row.CategoryID,
+			Price:          row.Price,
+			AvailableStock: row.Quantity - row.QuantityReserver,
+			Weight:         row.Weight,
+			ProductStatus:  string(row.ProductStatus.ProductDeleteStatus),
+		})
+	}
+	missing := make([]string, 0, len(seen))
+	for _, id := range ids {
+		if seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	return map[string]interface{}{"data": items, "missing": missing}, nil
+}
+func (s *service) GetAllProductSimple(ctx context.Context, principal services.Principal, query services.QueryFilter, category_path, brand_code, shop_id, keywords, sort string, min_price, max_price float64, status string, attributes map[string]string) (map[string]interface{}, *assets_services.ServiceError) {
+	if err := s.authorizeListStatus(ctx, principal, status, shop_id); err != nil {
+		return nil, err
+	}
 	//log.Printf("[GetAllProductSimple] Bắt đầu lấy danh sách sản phẩm - Trang: %d, Kích thước: %d, Danh mục: %s, Thương hiệu: %s, Shop: %s, Từ khóa: %s",
 	//	query.Page, query.PageSize, category_path, brand_code, shop_id, keywords)
 	cate_id := ""
 	brand_id := ""
+	// danh mục cha hiển thị cả sản phẩm nằm ở các danh mục con
+	var cateIDs []string
 	if category_path != "" {
 		category, err := s.repository.GetCategoryByPath(ctx, sql.NullString{String: category_path, Valid: true})
 		if err != nil {
 			return nil, assets_services.NewError(400, fmt.Errorf("không tìm thấy danh mục với đường dẫn: %s. Lỗi: %v", category_path, err))
 		}
 		cate_id = category.CategoryID
+		cateIDs, err = s.categorySubtreeIDs(ctx, category)
+		if err != nil {
+			return nil, assets_services.NewError(400, err)
+		}
+	}
+	// lọc theo thuộc tính chỉ có ý nghĩa khi đã chọn danh mục
+	var attrDefs []db.CategoryAttribute
+	var attrFilters []db_mysql.ProductAttributeFilter
+	if cate_id != "" {
+		defs, errAttr := s.effectiveCategoryAttributes(ctx, s.repository, cate_id)
+		if errAttr != nil {
+			return nil, errAttr
+		}
+		attrDefs = defs
+		filters, err := parseAttributeFilters(attrDefs, attributes)
+		if err != nil {
+			return nil, assets_services.NewError(400, err)
+		}
+		attrFilters = filters
+	} else if len(attributes) > 0 {
+		return nil, assets_services.NewError(400, fmt.Errorf("phải chọn danh mục (category_path) khi lọc theo thuộc tính"))
 	}
 	if brand_code != "" {
 		brand, err := s.repository.GetBrandByCode(ctx, brand_code)
@@ -48,44 +134,124 @@ func (s *service) GetAllProductSimple(ctx context.Context, query services.QueryF
 		}
 		brand_id = brand.BrandID
 	}
+	// tìm theo từ khóa bằng chỉ mục tìm kiếm (bỏ dấu, chịu lỗi chính tả); chỉ mục chưa sẵn sàng thì dùng LIKE
+	var searchIDs []string
+	keywordFilter := sql.NullString{String: keywords, Valid: keywords != ""}
+	if keywords != "" {
+		// tìm theo từ khóa mặc định xếp theo độ liên quan, relevance không phải cột nên không phân trang cursor được
+		if sort == "" && query.CursorMode {
+			return nil, assets_services.NewError(400, fmt.Errorf("tìm theo từ khóa sắp xếp theo độ liên quan không hỗ trợ phân trang theo cursor, hãy chọn sort khác"))
+		}
+		if ids, ok := s.searchProductIDs(keywords); ok {
+			searchIDs = ids
+			keywordFilter = sql.NullString{}
+			if sort == "" {
+				sort = "relevance"
+			}
+		}
+	}
+	// phân trang theo cursor: lấy dư 1 bản ghi để biết còn trang sau hay không
+	sort = strings.ToLower(sort)
+	limit := int32(query.PageSize)
+	offset := int32((query.Page - 1) * query.PageSize)
+	var keyset *db_mysql.ProductKeyset
+	var cursor *assets_services.Cursor
+	if query.CursorMode {
+		if !db_mysql.ProductKeysetSortable(sort) {
+			return nil, assets_services.NewError(400, fmt.Errorf("sort %q không hỗ trợ phân trang theo cursor", sort))
+		}
+		k, c, err := s.parseProductKeyset(sort, query.Cursor)
+		if err != nil {
+			return nil, assets_services.NewError(400, err)
+		}
+		keyset, cursor = k, c
+		limit, offset = int32(query.PageSize+1), 0
+	}
 	var deleteStatus db.ProductDeleteStatus
 	switch status {
 	case "Pending":
 		deleteStatus = db.ProductDeleteStatusPending
 	case "Deleted":
 		deleteStatus = db.ProductDeleteStatusDeleted
+	case "Rejected":
+		deleteStatus = db.ProductDeleteStatusRejected
+	case "Unlisted":
+		deleteStatus = db.ProductDeleteStatusUnlisted
 	default:
 		deleteStatus = db.ProductDeleteStatusActive
 	}
-	product_spu, err := s.repository.ListProductsDynamic(ctx, db.ListProductsAdvancedParams{
-		Limit:        int32(query.PageSize),
-		Offset:       int32((query.Page - 1) * query.PageSize),
-		BrandID:      sql.NullString{String: brand_id, Valid: brand_id != ""},
-		DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: deleteStatus, Valid: true},
-		CategoryID:   sql.NullString{String: cate_id, Valid: cate_id != ""},
-		ShopID:       sql.NullString{String: shop_id, Valid: shop_id != ""},
-		PriceMin:     sql.NullFloat64{Float64: min_price, Valid: min_price >= 0},
-		PriceMax:     sql.NullFloat64{Float64: max_price, Valid: max_price >= 0},
-		Keyword:      sql.NullString{String: keywords, Valid: keywords != ""},
-		Sort:         sql.NullString{String: strings.ToLower(sort), Valid: sort != ""},
+	countParams := db_mysql.ProductDynamicParams{
+		ListProductsAdvancedParams: db.ListProductsAdvancedParams{
+			BrandID:      sql.NullString{String: brand_id, Valid: brand_id != ""},
+			CategoryID:   sql.NullString{String: cate_id, Valid: cate_id != ""},
+			ShopID:       sql.NullString{String: shop_id, Valid: shop_id != ""},
+			PriceMin:     sql.NullFloat64{Float64: min_price, Valid: min_price >= 0},
+			PriceMax:     sql.NullFloat64{Float64: max_price, Valid: max_price >= 0},
+			Keyword:      keywordFilter,
+			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: deleteStatus, Valid: true},
+		},
+		Attributes:  attrFilters,
+		ProductIDs:  searchIDs,
+		CategoryIDs: cateIDs,
+	}
+	// chế độ cursor chỉ đếm tổng khi client yêu cầu
+	var totalElements int64
+	countTotal := !query.CursorMode || query.WithTotal
+	listIDs := searchIDs
+	if searchIDs != nil {
+		// áp các bộ lọc khác lên toàn bộ ứng viên của chỉ mục trước khi phân trang để không bỏ sót sản phẩm hợp lệ
+		matched, err := s.repository.FilterProductIDsDynamic(ctx, countParams)
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("không thể lọc kết quả tìm kiếm. Lỗi: %v", err))
+		}
+		totalElements, countTotal = int64(len(matched)), false
+		if sort == "relevance" {
+			// matched đã theo thứ tự liên quan nên cắt trang ngay tại đây
+			start := min(int(offset), len(matched))
+			listIDs = matched[start:min(start+int(limit), len(matched))]
+			offset = 0
+		} else {
+			listIDs = matched[:min(len(matched), searchSortLimit)]
+		}
+		if listIDs == nil {
+			listIDs = []string{}
+		}
+	}
+	product_spu, err := s.repository.ListProductsDynamic(ctx, db_mysql.ProductDynamicParams{
+		ListProductsAdvancedParams: db.ListProductsAdvancedParams{
+			Limit:        limit,
+			Offset:       offset,
+			BrandID:      sql.NullString{String: brand_id, Valid: brand_id != ""},
+			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: deleteStatus, Valid: true},
+			CategoryID:   sql.NullString{String: cate_id, Valid: cate_id != ""},
+			ShopID:       sql.NullString{String: shop_id, Valid: shop_id != ""},
+			PriceMin:     sql.NullFloat64{Float64: min_price, Valid: min_price >= 0},
+			PriceMax:     sql.NullFloat64{Float64: max_price, Valid: max_price >= 0},
+			Keyword:      keywordFilter,
+			Sort:         sql.NullString{String: sort, Valid: sort != ""},
+		},
+		Attributes:  attrFilters,
+		ProductIDs:  listIDs,
+		CategoryIDs: cateIDs,
+		Keyset:      keyset,
 	})
 	if err != nil {
 		//log.Printf("[GetAllProductSimple] LỖI: Không thể lấy danh sách sản phẩm từ database. Chi tiết: %v", err)
 		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách sản phẩm. Lỗi: %v", err))
 	}
 
-	totalElements, err := s.repository.CountProductsDynamic(ctx, db.ListProductsAdvancedParams{
-		BrandID:      sql.NullString{String: brand_id, Valid: brand_id != ""},
-		CategoryID:   sql.NullString{String: cate_id, Valid: cate_id != ""},
-		ShopID:       sql.NullString{String: shop_id, Valid: shop_id != ""},
-		PriceMin:     sql.NullFloat64{Float64: min_price, Valid: min_price >= 0},
-		PriceMax:     sql.NullFloat64{Float64: max_price, Valid: max_price >= 0},
-		Keyword:      sql.NullString{String: keywords, Valid: keywords != ""},
-		DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: deleteStatus, Valid: true},
-	})
-	if err != nil {
-		//log.Printf("[GetAllProductSimple] LỖI: Không thể đếm tổng số sản phẩm. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể đếm tổng số sản phẩm. Lỗi: %v", err))
+	if countTotal {
+		totalElements, err = s.repository.CountProductsDynamic(ctx, countParams)
+		if err != nil {
+			//log.Printf("[GetAllProductSimple] LỖI: Không thể đếm tổng số sản phẩm. Chi tiết: %v", err)
+			return nil, assets_services.NewError(400, fmt.Errorf("không thể đếm tổng số sản phẩm. Lỗi: %v", err))
+		}
+	}
+	var nextCursor, prevCursor interface{}
+	if query.CursorMode {
+		page, hasNext, hasPrev := assets_services.KeysetPage(product_spu, query.PageSize, cursor)
+		product_spu = page
+		nextCursor, prevCursor = s.productPageCursors(sort, product_spu, hasNext, hasPrev)
 	}
 
 	// Lấy danh sách product_id từ kết quả
@@ -108,6 +274,8 @@ func (s *service) GetAllProductSimple(ctx context.Context, query services.QueryF
 					ProductID:     stat.ProductID,
 					TotalReviews:  stat.TotalReviews,
 					AverageRating: stat.AverageRating,
+					BayesianScore: stat.BayesianScore,
+					Distribution:  stat.Distribution,
 				}
 			}
 		}
@@ -127,6 +295,7 @@ func (s *service) GetAllProductSimple(ctx context.Context, query services.QueryF
 				ProductID:     product.ID,
 				TotalReviews:  0,
 				AverageRating: 0.0,
+				Distribution:  map[string]int64{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0},
 			}
 		}
 
@@ -136,121 +305,159 @@ func (s *service) GetAllProductSimple(ctx context.Context, query services.QueryF
 	// Tạo result với data đã có rating
 	result := make(map[string]interface{})
 	result["data"] = productsWithRating
-	totalPage := int64(math.Ceil(float64(totalElements) / float64(query.PageSize)))
-	result["currentPage"] = query.Page
-	result["totalPages"] = totalPage
-	result["totalElements"] = totalElements
+	firstPage := query.Page == 1
+	if query.CursorMode {
+		firstPage = query.Cursor == ""
+		result["next_cursor"] = nextCursor
+		result["prev_cursor"] = prevCursor
+		if query.WithTotal {
+			result["totalElements"] = totalElements
+		}
+	} else {
+		totalPage := int64(math.Ceil(float64(totalElements) / float64(query.PageSize)))
+		result["currentPage"] = query.Page
+		result["totalPages"] = totalPage
+		result["totalElements"] = totalElements
+	}
 	result["limit"] = query.PageSize
+	// chỉ ghi nhận lần tìm có kết quả ở trang đầu để từ khóa phổ biến không bị đếm lặp khi phân trang
+	if keywords != "" && firstPage && len(product_spu) > 0 {
+		s.logSearchQuery(ctx, keywords)
+	}
+	if len(attrDefs) > 0 {
+		facets, err := s.attributeFacets(ctx, countParams, attrDefs)
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("không thể thống kê thuộc tính sản phẩm. Lỗi: %v", err))
+		}
+		result["facets"] = facets
+	}
+	// thống kê thương hiệu cho trang danh mục và trang tìm kiếm
+	if cate_id != "" || keywords != "" {
+		brandFacets, err := s.brandFacets(ctx, countParams)
+		if err != nil {
+			return nil, assets_services.NewError(400, fmt.Errorf("không thể thống kê thương hiệu sản phẩm. Lỗi: %v", err))
+		}
+		result["brand_facets"] = brandFacets
+	}
 
 	//log.Printf("[GetAllProductSimple] Thành công lấy %d sản phẩm - Trang %d/%d", len(product_spu), query.Page, totalPage)
 	return result, nil
 }
 
-func (s *service) GetProductWithID(ctx context.Context, product_id string) (map[string]interface{}, *assets_services.ServiceError) {
+// loadProductWithID đọc chi tiết sản phẩm từ DB, GetProductWithID dùng bản có cache
+func (s *service) loadProductWithID(ctx context.Context, product_id string) (map[string]interface{}, string, *assets_services.ServiceError) {
 	//log.Printf("[GetProductWithID] Bắt đầu lấy chi tiết sản phẩm với ID: %s", product_id)
 
 	product_spu_detail, err := s.repository.GetProduct(ctx, product_id)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không tìm thấy sản phẩm với ID: %s. Chi tiết: %v", product_id, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không tìm thấy sản phẩm với ID: %s. Lỗi: %v", product_id, err))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không tìm thấy sản phẩm với ID: %s. Lỗi: %v", product_id, err))
 	}
 
 	// call sku
 	sku, err := s.repository.ListSKUsByProduct(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể lấy danh sách SKU cho sản phẩm %s. Chi tiết: %v", product_id, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách SKU. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách SKU. Lỗi: %s", err.Error()))
 	}
 	sku_res := make([]services.ProductSku, len(sku))
 	if err := copier.Copy(&sku_res, &sku); err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể sao chép dữ liệu SKU. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu SKU: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu SKU: %s", err.Error()))
 	}
 
 	// call option value
 	option, err := s.repository.ListOptionValuesByProductID(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể lấy danh sách Option Values cho sản phẩm %s. Chi tiết: %v", product_id, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách thuộc tính sản phẩm. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách thuộc tính sản phẩm. Lỗi: %s", err.Error()))
 	}
 	option_res := make([]services.OptionValue, len(option))
 	if err := copier.Copy(&option_res, &option); err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể sao chép dữ liệu Option Values. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu thuộc tính: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu thuộc tính: %s", err.Error()))
 	}
 
 	// call sku attr
 	sku_attr, err := s.repository.ListSKUOptionValuesByProductID(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể lấy thông tin liên kết SKU-Option cho sản phẩm %s. Chi tiết: %v", product_id, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin liên kết SKU. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin liên kết SKU. Lỗi: %s", err.Error()))
 	}
 	sku_attr_res := make([]services.SkuAttr, len(sku_attr))
 	if err := copier.Copy(&sku_attr_res, &sku_attr); err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể sao chép dữ liệu SKU Attributes. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu liên kết: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu liên kết: %s", err.Error()))
 	}
 
 	// call brand name
 	brand, err := s.repository.GetBrand(ctx, product_spu_detail.BrandID.String)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể lấy thông tin thương hiệu %s. Chi tiết: %v", product_spu_detail.BrandID.String, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin thương hiệu. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin thương hiệu. Lỗi: %s", err.Error()))
 	}
 
 	// call category name
 	category, err := s.repository.GetCategory(ctx, product_spu_detail.CategoryID)
 	if err != nil {
 		//log.Printf("[GetProductWithID] LỖI: Không thể lấy thông tin danh mục %s. Chi tiết: %v", product_spu_detail.CategoryID, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin danh mục. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin danh mục. Lỗi: %s", err.Error()))
 	}
 	detail := buildProductDetail(option_res, sku_res, sku_attr_res)
+	lowestPrice30Days, err := s.fillLowestPrices30Days(ctx, product_spu_detail.ID, detail.SKUs)
+	if err != nil {
+		return nil, "", assets_services.NewError(400, err)
+	}
 	result_summary := struct {
 		Product  db.GetProductRow          `json:"product"`
 		Brand    db.Brand                  `json:"brand"`
 		Category db.Category               `json:"category"`
 		Option   []services.OptionResponse `json:"option"`
 		SKU      []services.SkuResponse    `json:"sku"`
+		// giá thấp nhất của sản phẩm trong 30 ngày (tính cả giá hiện tại)
+		LowestPrice30Days float64 `json:"lowest_price_30d"`
 	}{
-		Product:  product_spu_detail,
-		Brand:    brand,
-		Category: category,
-		Option:   detail.OptionMap,
-		SKU:      detail.SKUs,
+		Product:           product_spu_detail,
+		Brand:             brand,
+		Category:          category,
+		Option:            detail.OptionMap,
+		SKU:               detail.SKUs,
+		LowestPrice30Days: lowestPrice30Days,
 	}
 
 	result := assets_services.NormalizeSQLNulls(result_summary, "data")
 
 	//log.Printf("[GetProductWithID] Thành công lấy chi tiết sản phẩm '%s' (ID: %s) với %d SKU", product_spu_detail.Name, product_id, len(sku))
-	return result, nil
+	return result, product_spu_detail.ID, nil
 }
 
-func (s *service) GetDetailProduct(ctx context.Context, key string) (map[string]interface{}, *assets_services.ServiceError) {
+// loadDetailProduct đọc chi tiết sản phẩm theo key từ DB, GetDetailProduct dùng bản có cache
+func (s *service) loadDetailProduct(ctx context.Context, key string) (map[string]interface{}, string, *assets_services.ServiceError) {
 	//log.Printf("[GetDetailProduct] Bắt đầu lấy chi tiết sản phẩm với key: %s", key)
 
 	product_spu_detail, err := s.repository.GetProductByKey(ctx, key)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không tìm thấy sản phẩm với key: %s. Chi tiết: %v", key, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không tìm thấy sản phẩm với key: %s. Lỗi: %v", key, err))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không tìm thấy sản phẩm với key: %s. Lỗi: %v", key, err))
 	}
 
 	// call sku
 	sku, err := s.repository.ListSKUsByProduct(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể lấy danh sách SKU cho sản phẩm %s. Chi tiết: %v", key, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách SKU. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách SKU. Lỗi: %s", err.Error()))
 	}
 	sku_res := make([]services.ProductSku, len(sku))
 	if err := copier.Copy(&sku_res, &sku); err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể sao chép dữ liệu SKU. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu SKU: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu SKU: %s", err.Error()))
 	}
 
 	// call option value
 	option, err := s.repository.ListOptionValuesByProductID(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể lấy danh sách Option Values cho sản phẩm %s. Chi tiết: %v", key, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách thuộc tính sản phẩm. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách thuộc tính sản phẩm. Lỗi: %s", err.Error()))
 	}
 	option_res := make([]services.OptionValue, len(option))
 	for i, opt := range option {
@@ -267,53 +474,82 @@ func (s *service) GetDetailProduct(ctx context.Context, key string) (map[string]
 	sku_attr, err := s.repository.ListSKUOptionValuesByProductID(ctx, product_spu_detail.ID)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể lấy thông tin liên kết SKU-Option cho sản phẩm %s. Chi tiết: %v", key, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin liên kết SKU. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin liên kết SKU. Lỗi: %s", err.Error()))
 	}
 	sku_attr_res := make([]services.SkuAttr, len(sku_attr))
 	if err := copier.Copy(&sku_attr_res, &sku_attr); err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể sao chép dữ liệu SKU Attributes. Chi tiết: %v", err)
-		return nil, assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu liên kết: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("lỗi xử lý dữ liệu liên kết: %s", err.Error()))
 	}
 
 	// call brand name
 	brand, err := s.repository.GetBrand(ctx, product_spu_detail.BrandID.String)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể lấy thông tin thương hiệu %s. Chi tiết: %v", product_spu_detail.BrandID.String, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin thương hiệu. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin thương hiệu. Lỗi: %s", err.Error()))
 	}
 
 	// call category name
 	category, err := s.repository.GetCategory(ctx, product_spu_detail.CategoryID)
 	if err != nil {
 		//log.Printf("[GetDetailProduct] LỖI: Không thể lấy thông tin danh mục %s. Chi tiết: %v", product_spu_detail.CategoryID, err)
-		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin danh mục. Lỗi: %s", err.Error()))
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin danh mục. Lỗi: %s", err.Error()))
+	}
+
+	// call attribute values
+	attributes, err := s.repository.ListProductAttributeValues(ctx, product_spu_detail.ID)
+	if err != nil {
+		return nil, "", assets_services.NewError(400, fmt.Errorf("không thể lấy thuộc tính sản phẩm. Lỗi: %s", err.Error()))
 	}
 
 	detail := buildProductDetail(option_res, sku_res, sku_attr_res)
+	lowestPrice30Days, err := s.fillLowestPrices30Days(ctx, product_spu_detail.ID, detail.SKUs)
+	if err != nil {
+		return nil, "", assets_services.NewError(400, err)
+	}
 	result_summary := struct {
-		Product  db.GetProductByKeyRow     `json:"product"`
-		Brand    db.Brand                  `json:"brand"`
-		Category db.Category               `json:"category"`
-		Option   []services.OptionResponse `json:"option"`
-		SKU      []services.SkuResponse    `json:"sku"`
+		Product    db.GetProductByKeyRow              `json:"product"`
+		Brand      db.Brand                           `json:"brand"`
+		Category   db.Category                        `json:"category"`
+		Option     []services.OptionResponse          `json:"option"`
+		SKU        []services.SkuResponse             `json:"sku"`
+		Attributes []db.ListProductAttributeValuesRow `json:"attributes"`
+		// giá thấp nhất của sản phẩm trong 30 ngày (tính cả giá hiện tại)
+		LowestPrice30Days float64 `json:"lowest_price_30d"`
 	}{
-		Product:  product_spu_detail,
-		Brand:    brand,
-		Category: category,
-		Option:   detail.OptionMap,
-		SKU:      detail.SKUs,
+		Product:           product_spu_detail,
+		Brand:             brand,
+		Category:          category,
+		Option:            detail.OptionMap,
+		SKU:               detail.SKUs,
+		Attributes:        attributes,
+		LowestPrice30Days: lowestPrice30Days,
 	}
 
 	result := assets_services.NormalizeSQLNulls(result_summary, "data")
 
 	//log.Printf("[GetDetailProduct] Thành công lấy chi tiết sản phẩm '%s' (key: %s) với %d SKU", product_spu_detail.Name, key, len(sku))
-	return result, nil
+	return result, product_spu_detail.ID, nil
 }
-func (s *service) CreateProduct(ctx context.Context, token, userName string, product services.ProductParams, image *multipart.FileHeader, mediaFiles []*multipart.FileHeader, optionImages []struct {
+func (s *service) CreateProduct(ctx context.Context, token string, principal services.Principal, product services.ProductParams, image *multipart.FileHeader, mediaFiles []*multipart.FileHeader, optionImages []struct {
 	OptionName string
 	Value      string
 	Image      *multipart.FileHeader
 }) *assets_services.ServiceError {
+	userName := principal.UserName
+	// người bán chỉ được tạo sản phẩm cho shop của mình
+	if err := s.authorizeShop(ctx, principal, product.ShopID, "", ownershipActionCreateProduct); err != nil {
+		return err
+	}
+	// kiểm tra thuộc tính theo định nghĩa của danh mục trước khi upload ảnh
+	attrDefs, errAttr := s.effectiveCategoryAttributes(ctx, s.repository, product.CategoryID)
+	if errAttr != nil {
+		return errAttr
+	}
+	attrValues, errValues := buildProductAttributeValues("", attrDefs, product.Attributes)
+	if errValues != nil {
+		return assets_services.NewError(400, errValues)
+	}
 	//log.Printf("[CreateProduct] Bắt đầu tạo sản phẩm '%s' (key: %s) bởi người dùng: %s", product.Name, product.Key, userName)
 	//log.Printf("[CreateProduct] Thông tin: %d Option Values, %d SKUs, %d Option Images", len(product.OptionValue), len(product.ProductSKU), len(optionImages))
 
@@ -322,7 +558,7 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 	url_image, err := s.UploadMultiMedia(ctx, userName, []*multipart.FileHeader{image})
 	if err != nil {
 		//log.Printf("[CreateProduct] LỖI: Không thể upload ảnh chính. Chi tiết: %v", err)
-		return assets_services.NewError(500, fmt.Errorf("không thể upload ảnh chính. Lỗi: %v", err))
+		return assets_services.NewError(err.Code, fmt.Errorf("không thể upload ảnh chính. Lỗi: %v", err))
 	}
 	if len(url_image) == 0 {
 		//log.Printf("[CreateProduct] LỖI: Không có ảnh nào được upload")
@@ -335,13 +571,15 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 	url_media, err := s.UploadMultiMedia(ctx, userName, mediaFiles)
 	if err != nil {
 		//log.Printf("[CreateProduct] LỖI: Không thể upload ảnh media. Chi tiết: %v", err)
-		return assets_services.NewError(500, fmt.Errorf("không thể upload ảnh media. Lỗi: %v", err))
+		s.DeleteMultiImage(ctx, userName, url_image)
+		return assets_services.NewError(err.Code, fmt.Errorf("không thể upload ảnh media. Lỗi: %v", err))
 	}
 	//log.Printf("[CreateProduct] Upload %d ảnh media thành công", len(url_media))
 
 	url_media_json, errorsJson := json.Marshal(url_media)
 	if errorsJson != nil {
 		//log.Printf("[CreateProduct] LỖI: Không thể chuyển đổi danh sách ảnh media sang JSON. Chi tiết: %v", errorsJson)
+		s.DeleteMultiImage(ctx, userName, append(url_image, url_media...))
 		return assets_services.NewError(500, fmt.Errorf("lỗi xử lý dữ liệu ảnh media. Lỗi: %v", errorsJson))
 	}
 
@@ -355,7 +593,10 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 		url_option, err := s.UploadMultiMedia(ctx, userName, []*multipart.FileHeader{option.Image})
 		if err != nil {
 			//log.Printf("[CreateProduct] LỖI: Không thể upload ảnh cho option '%s - %s'. Chi tiết: %v", option.OptionName, option.Value, err)
-			return assets_services.NewError(500, fmt.Errorf("không thể upload ảnh cho option '%s - %s'. Lỗi: %v", option.OptionName, option.Value, err))
+			// xóa các ảnh đã upload trước đó để không để lại file mồ côi
+			uploaded := append(append(url_image, url_media...), option_image...)
+			s.DeleteMultiImage(ctx, userName, uploaded)
+			return assets_services.NewError(err.Code, fmt.Errorf("không thể upload ảnh cho option '%s - %s'. Lỗi: %v", option.OptionName, option.Value, err))
 		}
 		if option_image_path[option.OptionName] == nil {
 			option_image_path[option.OptionName] = make(map[string]string)
@@ -366,8 +607,8 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 	}
 
 	//log.Printf("[CreateProduct] Bắt đầu transaction tạo sản phẩm trong database...")
+	product_id := uuid.New().String()
 	errors := s.repository.ExecTS(ctx, func(tx db.Querier) error {
-		product_id := uuid.New().String()
 		//log.Printf("[CreateProduct] Tạo product ID: %s", product_id)
 
 		//log.Printf("[CreateProduct] Tạo bản ghi sản phẩm chính...")
@@ -390,6 +631,10 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 			//log.Printf("[CreateProduct] LỖI: Không thể tạo bản ghi sản phẩm trong database. Chi tiết: %v", err)
 			return fmt.Errorf("không thể tạo sản phẩm trong database: %w", err)
 		}
+		// sản phẩm mới luôn ở trạng thái Pending, ghi lại lần gửi duyệt đầu tiên
+		if err := createSubmittedModeration(ctx, tx, product_id, userName, nil); err != nil {
+			return err
+		}
 		//log.Printf("[CreateProduct] Tạo bản ghi sản phẩm thành công")
 		// create option value
 		//log.Printf("[CreateProduct] Tạo %d Option Values...", len(product.OptionValue))
@@ -435,6 +680,9 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 				//log.Printf("[CreateProduct] LỖI: Không thể tạo SKU '%s'. Chi tiết: %v", sku.SkuCode, err)
 				return fmt.Errorf("không thể tạo SKU '%s': %w", sku.SkuCode, err)
 			}
+			if err := recordSKUPrice(ctx, tx, skuID, product_id, sql.NullFloat64{}, sku.Price, userName); err != nil {
+				return err
+			}
 			//log.Printf("[CreateProduct] Tạo SKU %d/%d: %s (ID: %s, Giá: %.0f, Số lượng: %d)", i+1, len(product.ProductSKU), sku.SkuCode, skuID, sku.Price, sku.Quantity)
 
 			// Link SKU với Option Values
@@ -465,6 +713,16 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 			}
 		}
 		//log.Printf("[CreateProduct] Hoàn thành tạo tất cả liên kết SKU-Option")
+		for _, value := range attrValues {
+			value.ProductID = product_id
+			if err := tx.CreateProductAttributeValue(ctx, value); err != nil {
+				return fmt.Errorf("không thể lưu thuộc tính sản phẩm: %w", err)
+			}
+		}
+		// giá/tồn kho tổng hợp dùng cho danh sách sản phẩm
+		if err := recomputeProductAggregates(ctx, tx, product_id); err != nil {
+			return err
+		}
 		return nil
 	})
 	// check if user have permission to create product for this shop
@@ -476,12 +734,14 @@ func (s *service) CreateProduct(ctx context.Context, token, userName string, pro
 		s.DeleteMultiImage(ctx, userName, allImages)
 		return assets_services.NewError(400, fmt.Errorf("không thể tạo sản phẩm. Lỗi: %v", errors))
 	}
+	s.refreshProductSearch(ctx, product_id)
+	s.refreshProductSuggestion(ctx, product_id)
 	return nil
 }
 
 func (s *service) UpdateProduct(
 	ctx context.Context,
-	role_user, userName, productID string,
+	principal services.Principal, productID string,
 
 	product services.ProductUpdateParams, // Struct chứa dữ liệu JSON
 	mainImage *multipart.FileHeader, // Ảnh chính mới (nếu có)
@@ -495,41 +755,67 @@ func (s *service) UpdateProduct(
 		}
 		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin sản phẩm: %w", err))
 	}
+	userName := principal.UserName
+	if err := s.authorizeShop(ctx, principal, currentProduct.ShopID, productID, ownershipActionUpdateProduct); err != nil {
+		return err
+	}
 	// kiểm tra ngoại lệ nếu là delete status thì sẽ cập nhật lại sản phẩm trạng thái là xóa
 	if product.DeleteStatus != nil && *product.DeleteStatus {
-		err := s.repository.UpdateProduct(ctx, db.UpdateProductParams{
-			ID:           productID,
-			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: db.ProductDeleteStatusDeleted, Valid: true},
-			UpdateBy:     sql.NullString{String: userName, Valid: true},
-		})
-		if err != nil {
-			return assets_services.NewError(400, fmt.Errorf("lỗi khi xóa sản phẩm: %w", err))
+		if err := s.SoftDeleteProduct(ctx, principal, productID); err != nil {
+			return err
 		}
 		return assets_services.NewError(200, fmt.Errorf("xóa sản phẩm thành công"))
 	}
+	if currentProduct.DeleteStatus.ProductDeleteStatus == db.ProductDeleteStatusDeleted && product.ApprovalProduct == nil {
+		return assets_services.NewError(409, fmt.Errorf("sản phẩm đã bị xóa, cần khôi phục trước khi chỉnh sửa"))
+	}
 	if product.ApprovalProduct != nil {
-		if role_user != "ROLE_ADMIN" {
+		if !principal.IsAdmin() {
 			return assets_services.NewError(403, fmt.Errorf("bạn không có quyền kiểm duyệt sản phẩm"))
 		}
-		ProductDeleteStatus := db.ProductDeleteStatusDeleted
 		if *product.ApprovalProduct {
-			if currentProduct.DeleteStatus.ProductDeleteStatus == db.ProductDeleteStatusActive {
-				return assets_services.NewError(400, fmt.Errorf("sản phẩm đã được duyệt trước đó"))
+			return s.ApproveProduct(ctx, userName, productID)
+		}
+		return s.RejectProduct(ctx, userName, productID, product.RejectReason)
+	}
+
+	// thuộc tính sản phẩm: gộp giá trị hiện tại với giá trị mới (chuỗi rỗng là xóa) rồi kiểm tra theo danh mục đích
+	targetCategoryID := currentProduct.CategoryID
+	if product.CategoryID != nil {
+		targetCategoryID = *product.CategoryID
+	}
+	attrChanged := product.Attributes != nil || targetCategoryID != currentProduct.CategoryID
+	var attrValues []db.CreateProductAttributeValueParams
+	if attrChanged {
+		attrDefs, errAttr := s.effectiveCategoryAttributes(ctx, s.repository, targetCategoryID)
+		if errAttr != nil {
+			return errAttr
+		}
+		currentValues, err := s.repository.ListProductAttributeValues(ctx, productID)
+		if err != nil {
+			return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thuộc tính sản phẩm: %w", err))
+		}
+		definedCodes := make(map[string]bool, len(attrDefs))
+		for _, def := range attrDefs {
+			definedCodes[def.Code] = true
+		}
+		merged := make(map[string]string, len(currentValues)+len(product.Attributes))
+		for _, value := range currentValues {
+			// khi đổi danh mục, giá trị của thuộc tính không còn áp dụng sẽ bị bỏ
+			if definedCodes[value.Code] {
+				merged[value.Code] = value.Value
 			}
-			if currentProduct.DeleteStatus.ProductDeleteStatus == db.ProductDeleteStatusDeleted {
-				return assets_services.NewError(400, fmt.Errorf("sản phẩm đã bị xóa không thể duyệt"))
+		}
+		for code, value := range product.Attributes {
+			if value == "" {
+				delete(merged, code)
+				continue
 			}
-
-			ProductDeleteStatus = db.ProductDeleteStatusActive
-
+			merged[code] = value
 		}
-		err := s.repository.UpdateProduct(ctx, db.UpdateProductParams{
-			ID:           productID,
-			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: ProductDeleteStatus, Valid: true},
-			UpdateBy:     sql.NullString{String: userName, Valid: true},
-		})
+		attrValues, err = buildProductAttributeValues(productID, attrDefs, merged)
 		if err != nil {
-			return assets_services.NewError(400, fmt.Errorf("lỗi khi xóa sản phẩm: %w", err))
+			return assets_services.NewError(400, err)
 		}
 	}
 
@@ -554,6 +840,7 @@ func (s *service) UpdateProduct(
 	if len(newMediaFiles) > 0 {
 		uploadedMedia, err := s.UploadMultiMedia(ctx, userName, newMediaFiles) // Giả định trả về []string URLs
 		if err != nil {
+			s.DeleteMultiImage(ctx, userName, imagesToDeleteWhenFail)
 			return assets_services.NewError(400, fmt.Errorf("lỗi khi tải ảnh media lên: %w", err))
 		}
 		newMediaUrls = uploadedMedia
@@ -566,6 +853,7 @@ func (s *service) UpdateProduct(
 		if optUpdate.Image != nil && optUpdate.OptionValueID != "" {
 			uploadedOptImage, err := s.UploadMultiMedia(ctx, userName, []*multipart.FileHeader{optUpdate.Image})
 			if err != nil {
+				s.DeleteMultiImage(ctx, userName, imagesToDeleteWhenFail)
 				return assets_services.NewError(400, fmt.Errorf("lỗi khi tải ảnh option lên cho %s: %w", optUpdate.OptionValueID, err))
 			}
 			newOptionImageUrls[optUpdate.OptionValueID] = uploadedOptImage[0]
@@ -582,7 +870,8 @@ func (s *service) UpdateProduct(
 			ID:       productID,
 			UpdateBy: sql.NullString{String: userName, Valid: true},
 		}
-		productChanged := false // Cờ để kiểm tra xem có cần update product không
+		productChanged := false     // Cờ để kiểm tra xem có cần update product không
+		changedFields := []string{} // Các thay đổi quan trọng khiến sản phẩm phải duyệt lại
 
 		// --- 2.2 Xử lý cập nhật ảnh chính ---
 		currentMainImage := currentProduct.Image // Lấy URL ảnh chính hiện tại
@@ -593,6 +882,7 @@ func (s *service) UpdateProduct(
 			}
 			updateProductParams.Image = sql.NullString{String: newMainImageUrl, Valid: true}
 			productChanged = true
+			changedFields = append(changedFields, "image")
 		} else if product.RemoveMainImage != nil && *product.RemoveMainImage { // Yêu cầu xóa ảnh chính
 			if currentMainImage != "" {
 				imagesToDelete = append(imagesToDelete, currentMainImage) // Thêm ảnh cũ vào danh sách xóa
@@ -600,6 +890,7 @@ func (s *service) UpdateProduct(
 			}
 			updateProductParams.Image = sql.NullString{String: "", Valid: true} // Set rỗng/NULL trong DB
 			productChanged = true
+			changedFields = append(changedFields, "image")
 		} // Không có ảnh mới và không yêu cầu xóa -> giữ nguyên
 
 		// --- 2.3 Xử lý cập nhật ảnh media ---
@@ -674,6 +965,7 @@ func (s *service) UpdateProduct(
 			}
 			updateProductParams.Media = sql.NullString{String: finalMediaJson, Valid: true}
 			productChanged = true
+			changedFields = append(changedFields, "media")
 			//log.Printf("Final media JSON for product %s: %s", productID, finalMediaJson)
 		}
 
@@ -681,6 +973,16 @@ func (s *service) UpdateProduct(
 		if product.Name != nil {
 			updateProductParams.Name = sql.NullString{String: *product.Name, Valid: true}
 			productChanged = true
+			if *product.Name != currentProduct.Name {
+				changedFields = append(changedFields, "name")
+			}
+		}
+		if product.CategoryID != nil {
+			updateProductParams.CategoryID = sql.NullString{String: *product.CategoryID, Valid: true}
+			productChanged = true
+			if *product.CategoryID != currentProduct.CategoryID {
+				changedFields = append(changedFields, "category_id")
+			}
 		}
 		if product.Key != nil {
 			updateProductParams.Key = sql.NullString{String: *product.Key, Valid: true}
@@ -711,6 +1013,16 @@ func (s *service) UpdateProduct(
 				return fmt.Errorf("lỗi khi cập nhật sản phẩm: %w", err)
 			}
 		}
+		if attrChanged {
+			if err := tx.DeleteProductAttributeValues(ctx, productID); err != nil {
+				return fmt.Errorf("lỗi khi cập nhật thuộc tính sản phẩm: %w", err)
+			}
+			for _, value := range attrValues {
+				if err := tx.CreateProductAttributeValue(ctx, value); err != nil {
+					return fmt.Errorf("lỗi khi cập nhật thuộc tính sản phẩm: %w", err)
+				}
+			}
+		}
 
 		// --- 2.5 Cập nhật option_value và ảnh option ---
 		// Lấy danh sách option values hiện tại của sản phẩm để lấy ảnh cũ
@@ -784,12 +1096,26 @@ func (s *service) UpdateProduct(
 		}
 
 		// --- 2.6 Cập nhật product_sku ---
+		currentSkus, err := tx.ListSKUsByProduct(ctx, productID)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("lỗi khi lấy thông tin SKU hiện tại: %w", err)
+		}
+		currentSkuPrices := make(map[string]float64) // map[skuID]price
+		for _, cs := range currentSkus {
+			currentSkuPrices[cs.ID] = cs.Price
+		}
+		// SKU thêm mới hoặc đổi giá vượt ngưỡng đều là thay đổi cần duyệt lại
+		changedFields = append(changedFields, materialSKUChanges(currentSkuPrices, product.ProductSKU, s.moderationPriceThreshold())...)
 		for _, sku := range product.ProductSKU {
 			if sku.ID == "" {
 				// Bỏ qua nếu là tạo mới
 				//log.Printf("Skipping SKU update due to missing ID for SKU Code: %s", sku.SkuCode)
 				continue
 			}
+			oldPrice, ok := currentSkuPrices[sku.ID]
+			if !ok {
+				return fmt.Errorf("SKU %s không thuộc sản phẩm %s", sku.ID, productID)
+			}
 
 			// Chỉ update các trường được cung cấp (dùng COALESCE hoặc kiểm tra nil)
 			updateSkuParams := db.UpdateProductSKUParams{
@@ -804,11 +1130,28 @@ func (s *service) UpdateProduct(
 			if err != nil {
 				return fmt.Errorf("lỗi khi cập nhật SKU ID: %s : %w", sku.ID, err)
 			}
+			if updateSkuParams.Price.Valid && oldPrice != sku.Price {
+				if err := recordSKUPrice(ctx, tx, sku.ID, productID, sql.NullFloat64{Float64: oldPrice, Valid: true}, sku.Price, userName); err != nil {
+					return err
+				}
+			}
 
 			// TODO: Cập nhật bảng liên kết SKU và Option Values nếu cần (product_sku_attributes)
 			// Logic này phụ thuộc vào thiết kế CSDL của bạn cho việc liên kết này.
 			// Có thể cần xóa các liên kết cũ và tạo lại các liên kết mới dựa trên sku.OptionValue
 		}
+		if err := recomputeProductAggregates(ctx, tx, productID); err != nil {
+			return err
+		}
+
+		// --- 2.7 Kiểm duyệt lại sản phẩm ---
+		// Admin sửa không cần duyệt lại. Sản phẩm đang Active hoặc đang ẩn chỉ duyệt lại khi có thay đổi quan trọng,
+		// sản phẩm bị từ chối được gửi duyệt lại sau mỗi lần shop chỉnh sửa.
+		if !principal.IsAdmin() && needsResubmission(currentProduct.DeleteStatus.ProductDeleteStatus, changedFields) {
+			if err := submitForModeration(ctx, tx, productID, userName, currentProduct.DeleteStatus.ProductDeleteStatus, changedFields); err != nil {
+				return err
+			}
+		}
 
 		return nil // Commit transaction
 	})
@@ -831,6 +1174,11 @@ func (s *service) UpdateProduct(
 		return assets_services.NewError(400, txErr)
 	}
 
+	s.refreshProductSearch(ctx, productID)
+	s.refreshProductSuggestion(ctx, productID)
+	// key (slug) có thể đã đổi nên bỏ luôn ánh xạ của key cũ
+	s.invalidateProductDetail(ctx, []string{productID}, currentProduct.Key)
+
 	// ----- Bước 3: Xóa ảnh cũ SAU KHI commit thành công -----
 	if len(imagesToDelete) > 0 {
 		//log.Printf("Attempting to delete %d old images for product %s...", len(imagesToDelete), productID)
@@ -859,6 +1207,7 @@ func containsString(slice []string, str string) bool {
 }
 
 func (s *service) UpdateSKUReserverProduct(ctx context.Context, productSKU []services.ProductUpdateSKUReserver, type_req services.ProductUpdateType) *assets_services.ServiceError {
+	touchedProducts := make(map[string]bool)
 	err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
 		for _, sku := range productSKU {
 			sku_db, err := tx.GetProductSKU(ctx, sku.SkuID)
@@ -919,6 +1268,13 @@ func (s *service) UpdateSKUReserverProduct(ctx context.Context, productSKU []ser
 			default:
 				return fmt.Errorf("loại cập nhật không hợp lệ: %v", type_req)
 			}
+			touchedProducts[sku_db.ProductID] = true
+		}
+		// giữ/trả hàng làm thay đổi tồn kho khả dụng của sản phẩm
+		for productID := range touchedProducts {
+			if err := recomputeProductAggregates(ctx, tx, productID); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
@@ -926,6 +1282,12 @@ func (s *service) UpdateSKUReserverProduct(ctx context.Context, productSKU []ser
 	if err != nil {
 		return assets_services.NewError(400, fmt.Errorf("không thể cập nhật số lượng đặt trước: %w", err))
 	}
+	// chỉ tồn kho của SKU thay đổi, bỏ cache chi tiết của đúng các sản phẩm có SKU bị giữ/trả hàng
+	productIDs := make([]string, 0, len(touchedProducts))
+	for productID := range touchedProducts {
+		productIDs = append(productIDs, productID)
+	}
+	s.invalidateProductDetail(ctx, productIDs)
 
 	return nil
 }
@@ -1017,7 +1379,7 @@ func (s *service) BuildProductSearchString(ctx context.Context, productID string
 	// 7. Lấy và thêm thông tin danh mục
 	category, err := s.repository.GetCategory(ctx, product.CategoryID)
 	if err == nil {
-		searchParts = append(searchParts, fmt.Sprintf("Danh mục: %s (Path: %s)", category.Name, category.Path))
+		searchParts = append(searchParts, fmt.Sprintf("Danh mục: %s (Path: %s)", category.Name, category.Path.String))
 	}
 
 	// 8. Lấy và thêm thông tin Option Values
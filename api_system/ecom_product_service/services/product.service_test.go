@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// skuBatchStore trả về các SKU có trong skus, ghi lại danh sách id được truy vấn
+type skuBatchStore struct {
+	db_mysql.Store
+	skus    map[string]db.ListSKUsForOrderRow
+	queried [][]string
+}
+
+func (f *skuBatchStore) ListSKUsForOrder(ctx context.Context, skuIds []string) ([]db.ListSKUsForOrderRow, error) {
+	f.queried = append(f.queried, skuIds)
+	var rows []db.ListSKUsForOrderRow
+	for _, id := range skuIds {
+		if row, ok := f.skus[id]; ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func TestGetSKUsBatch(t *testing.T) {
+	store := &skuBatchStore{skus: map[string]db.ListSKUsForOrderRow{
+		"sku-1": {ID: "sku-1", ProductID: "p-1", Price: 100000, Quantity: 10, QuantityReserver: 3,
+			ProductStatus: db.NullProductDeleteStatus{ProductDeleteStatus: db.ProductDeleteStatusActive, Valid: true}},
+		"sku-2": {ID: "sku-2", ProductID: "p-1", Price: 120000, Quantity: 5},
+	}}
+	s := &service{repository: store}
+
+	result, serr := s.GetSKUsBatch(context.Background(), []string{"sku-1", "sku-x", "sku-1", "", "sku-2", "sku-x"})
+	require.Nil(t, serr)
+	// id trùng và id rỗng bị bỏ trước khi truy vấn
+	require.Equal(t, [][]string{{"sku-1", "sku-x", "sku-2"}}, store.queried)
+
+	items := result["data"].([]services.SKUOrderInfo)
+	require.Len(t, items, 2)
+	require.Equal(t, "sku-1", items[0].SkuID)
+	require.Equal(t, int32(7), items[0].AvailableStock)
+	require.Equal(t, string(db.ProductDeleteStatusActive), items[0].ProductStatus)
+	require.Equal(t, []string{"sku-x"}, result["missing"])
+}
+
+func TestGetSKUsBatchLimits(t *testing.T) {
+	ctx := context.Background()
+	store := &skuBatchStore{}
+	s := &service{repository: store}
+
+	_, serr := s.GetSKUsBatch(ctx, nil)
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+	_, serr = s.GetSKUsBatch(ctx, []string{"", ""})
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+
+	ids := make([]string, 0, maxSKUBatchSize+1)
+	for i := 0; i < maxSKUBatchSize; i++ {
+		ids = append(ids, fmt.Sprintf("sku-%d", i))
+	}
+	// đúng giới hạn vẫn được, id trùng không tính vào giới hạn
+	result, serr := s.GetSKUsBatch(ctx, append(ids, ids[0]))
+	require.Nil(t, serr)
+	require.Len(t, result["missing"], maxSKUBatchSize)
+
+	_, serr = s.GetSKUsBatch(ctx, append(ids, "sku-extra"))
+	require.NotNil(t, serr)
+	require.Equal(t, 400, serr.Code)
+	require.Len(t, store.queried, 1)
+}
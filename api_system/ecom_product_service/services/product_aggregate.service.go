@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+)
+
+// số sản phẩm xử lý trong mỗi transaction của job reindex
+const reindexAggregateBatchSize = 200
+
+// productAggregates các giá trị tổng hợp từ SKU được lưu sẵn trên bảng product
+// để danh sách sản phẩm lọc/sắp xếp theo giá mà không cần join product_sku
+type productAggregates struct {
+	MinPrice      float64
+	MaxPrice      float64
+	MinPriceSkuID string
+	MaxPriceSkuID string
+	TotalStock    int32
+	InStock       bool
+}
+
+// computeProductAggregates tính giá thấp/cao nhất, SKU đại diện và tồn kho khả dụng.
+// Khi nhiều SKU cùng giá thì chọn SKU có id nhỏ hơn để kết quả ổn định giữa các lần tính.
+func computeProductAggregates(skus []db.ProductSku) productAggregates {
+	var agg productAggregates
+	for i, sku := range skus {
+		if i == 0 || sku.Price < agg.MinPrice || (sku.Price == agg.MinPrice && sku.ID < agg.MinPriceSkuID) {
+			agg.MinPrice = sku.Price
+			agg.MinPriceSkuID = sku.ID
+		}
+		if i == 0 || sku.Price > agg.MaxPrice || (sku.Price == agg.MaxPrice && sku.ID < agg.MaxPriceSkuID) {
+			agg.MaxPrice = sku.Price
+			agg.MaxPriceSkuID = sku.ID
+		}
+		// tồn kho khả dụng = số lượng - số lượng đang giữ cho đơn hàng
+		if available := sku.Quantity - sku.QuantityReserver; available > 0 {
+			agg.TotalStock += available
+		}
+	}
+	agg.InStock = agg.TotalStock > 0
+	return agg
+}
+
+// recomputeProductAggregates tính lại các giá trị tổng hợp của sản phẩm từ SKU hiện tại.
+// Phải được gọi trong cùng transaction với thao tác thay đổi SKU.
+func recomputeProductAggregates(ctx context.Context, tx db.Querier, productID string) error {
+	skus, err := tx.ListSKUsByProduct(ctx, productID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("không thể lấy SKU của sản phẩm %s: %w", productID, err)
+	}
+	agg := computeProductAggregates(skus)
+	err = tx.UpdateProductAggregates(ctx, db.UpdateProductAggregatesParams{
+		ID:            productID,
+		MinPrice:      agg.MinPrice,
+		MaxPrice:      agg.MaxPrice,
+		MinPriceSkuID: sql.NullString{String: agg.MinPriceSkuID, Valid: agg.MinPriceSkuID != ""},
+		MaxPriceSkuID: sql.NullString{String: agg.MaxPriceSkuID, Valid: agg.MaxPriceSkuID != ""},
+		TotalStock:    agg.TotalStock,
+		InStock:       agg.InStock,
+	})
+	if err != nil {
+		return fmt.Errorf("không thể cập nhật giá/tồn kho tổng hợp của sản phẩm %s: %w", productID, err)
+	}
+	return nil
+}
+
+// ReindexProductAggregates tính lại giá/tồn kho tổng hợp cho toàn bộ sản phẩm,
+// dùng để sửa dữ liệu cũ trước khi có bước tính lại trong service.
+func (s *service) ReindexProductAggregates(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	total := 0
+	failed := []string{}
+	afterID := ""
+	for {
+		ids, err := s.repository.ListProductIDsAfter(ctx, db.ListProductIDsAfterParams{
+			AfterID: afterID,
+			Limit:   reindexAggregateBatchSize,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy danh sách sản phẩm để reindex. Lỗi: %v", err))
+		}
+		if len(ids) == 0 {
+			break
+		}
+		txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+			for _, id := range ids {
+				if err := recomputeProductAggregates(ctx, tx, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if txErr != nil {
+			// lỗi một lô thì xử lý lại từng sản phẩm để không bỏ sót các sản phẩm còn lại
+			log.Printf("[ReindexProductAggregates] lô bắt đầu từ %s thất bại: %v", ids[0], txErr)
+			for _, id := range ids {
+				err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+					return recomputeProductAggregates(ctx, tx, id)
+				})
+				if err != nil {
+					failed = append(failed, id)
+					continue
+				}
+				total++
+			}
+		} else {
+			total += len(ids)
+		}
+		afterID = ids[len(ids)-1]
+	}
+	log.Printf("[ReindexProductAggregates] đã tính lại %d sản phẩm, lỗi %d", total, len(failed))
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"total_reindexed": total,
+			"failed":          failed,
+		},
+	}, nil
+}
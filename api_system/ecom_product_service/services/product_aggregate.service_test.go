@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeProductAggregates(t *testing.T) {
+	skus := []db.ProductSku{
+		{ID: "sku-b", Price: 150000, Quantity: 5, QuantityReserver: 2},
+		{ID: "sku-a", Price: 90000, Quantity: 3, QuantityReserver: 3},
+		{ID: "sku-c", Price: 300000, Quantity: 1, QuantityReserver: 4}, // dữ liệu lệch không được làm âm tồn kho
+	}
+
+	agg := computeProductAggregates(skus)
+	require.Equal(t, 90000.0, agg.MinPrice)
+	require.Equal(t, "sku-a", agg.MinPriceSkuID)
+	require.Equal(t, 300000.0, agg.MaxPrice)
+	require.Equal(t, "sku-c", agg.MaxPriceSkuID)
+	require.Equal(t, int32(3), agg.TotalStock)
+	require.True(t, agg.InStock)
+}
+
+func TestComputeProductAggregatesTieAndEmpty(t *testing.T) {
+	agg := computeProductAggregates([]db.ProductSku{
+		{ID: "sku-2", Price: 100000},
+		{ID: "sku-1", Price: 100000},
+	})
+	require.Equal(t, "sku-1", agg.MinPriceSkuID)
+	require.Equal(t, "sku-1", agg.MaxPriceSkuID)
+	require.False(t, agg.InStock)
+
+	agg = computeProductAggregates(nil)
+	require.Equal(t, productAggregates{}, agg)
+}
+
+// aggregateQuerier giữ SKU và giá trị tổng hợp của sản phẩm trong bộ nhớ thay cho DB
+type aggregateQuerier struct {
+	db.Querier
+	skus    map[string][]db.ProductSku
+	updates map[string]db.UpdateProductAggregatesParams
+}
+
+func (q *aggregateQuerier) ListSKUsByProduct(ctx context.Context, productID string) ([]db.ProductSku, error) {
+	return q.skus[productID], nil
+}
+
+func (q *aggregateQuerier) UpdateProductAggregates(ctx context.Context, arg db.UpdateProductAggregatesParams) error {
+	q.updates[arg.ID] = arg
+	return nil
+}
+
+func TestRecomputeProductAggregatesAfterSKUEdits(t *testing.T) {
+	ctx := context.Background()
+	q := &aggregateQuerier{
+		skus: map[string][]db.ProductSku{
+			"product-a": {{ID: "a1", Price: 200000, Quantity: 2}, {ID: "a2", Price: 250000}},
+		},
+		updates: map[string]db.UpdateProductAggregatesParams{},
+	}
+	require.NoError(t, recomputeProductAggregates(ctx, q, "product-a"))
+	require.Equal(t, 200000.0, q.updates["product-a"].MinPrice)
+	require.Equal(t, sql.NullString{String: "a1", Valid: true}, q.updates["product-a"].MinPriceSkuID)
+	require.True(t, q.updates["product-a"].InStock)
+
+	// thêm SKU rẻ hơn thì cột min_price (dùng cho sort=price_asc) phải đổi theo
+	q.skus["product-a"] = append(q.skus["product-a"], db.ProductSku{ID: "a3", Price: 99000})
+	require.NoError(t, recomputeProductAggregates(ctx, q, "product-a"))
+	require.Equal(t, 99000.0, q.updates["product-a"].MinPrice)
+	require.Equal(t, int32(2), q.updates["product-a"].TotalStock)
+
+	// xóa hết SKU thì không còn SKU đại diện và hết hàng
+	q.skus["product-a"] = nil
+	require.NoError(t, recomputeProductAggregates(ctx, q, "product-a"))
+	require.False(t, q.updates["product-a"].MinPriceSkuID.Valid)
+	require.False(t, q.updates["product-a"].InStock)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+)
+
+// cursorSecret khóa ký cursor phân trang, mặc định dùng chung JWT_SECRET
+func (s *service) cursorSecret() string {
+	if s.env.CursorSecret != "" {
+		return s.env.CursorSecret
+	}
+	return s.env.JWTSecret
+}
+
+// productCursor tạo cursor từ giá trị cột sắp xếp của sản phẩm, create_date và min_price NULL được đánh dấu Null
+func productCursor(sort string, p db.ListProductsAdvancedRow, backward bool) assets_services.Cursor {
+	c := assets_services.Cursor{Sort: sort, ID: p.ID, Backward: backward}
+	switch sort {
+	case "best_sell":
+		c.Key = strconv.FormatInt(p.TotalSold, 10)
+	case "price_asc", "price_desc":
+		if p.MinPrice.Valid {
+			c.Key = strconv.FormatFloat(p.MinPrice.Float64, 'f', -1, 64)
+		} else {
+			c.Null = true
+		}
+	case "name_asc", "name_desc":
+		c.Key = p.Name
+	case "rating":
+		c.Key = strconv.FormatFloat(p.RatingScore, 'f', -1, 64)
+	default:
+		if p.CreateDate.Valid {
+			c.Key = p.CreateDate.Time.UTC().Format(time.RFC3339Nano)
+		} else {
+			c.Null = true
+		}
+	}
+	return c
+}
+
+// parseProductKeyset đọc cursor từ client thành bản ghi mốc cho truy vấn, cursor phải được tạo với cùng sort
+func (s *service) parseProductKeyset(sort, token string) (*db_mysql.ProductKeyset, *assets_services.Cursor, error) {
+	if token == "" {
+		return &db_mysql.ProductKeyset{}, nil, nil
+	}
+	c, err := assets_services.DecodeCursor(s.cursorSecret(), token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.Sort != sort {
+		return nil, nil, fmt.Errorf("cursor không dùng được với sort %q", sort)
+	}
+	var value interface{}
+	switch {
+	case c.Null:
+		if !productSortNullable(sort) {
+			return nil, nil, assets_services.ErrInvalidCursor
+		}
+	case sort == "best_sell":
+		value, err = strconv.ParseInt(c.Key, 10, 64)
+	case sort == "price_asc" || sort == "price_desc" || sort == "rating":
+		value, err = strconv.ParseFloat(c.Key, 64)
+	case sort == "name_asc" || sort == "name_desc":
+		value = c.Key
+	default:
+		value, err = time.Parse(time.RFC3339Nano, c.Key)
+	}
+	if err != nil {
+		return nil, nil, assets_services.ErrInvalidCursor
+	}
+	return &db_mysql.ProductKeyset{Value: value, ID: c.ID, Backward: c.Backward}, &c, nil
+}
+
+// productSortNullable các sort theo cột có thể NULL (create_date, min_price)
+func productSortNullable(sort string) bool {
+	switch sort {
+	case "best_sell", "name_asc", "name_desc", "rating":
+		return false
+	}
+	return true
+}
+
+// productPageCursors sinh next_cursor/prev_cursor cho trang hiện tại, nil khi không còn trang
+func (s *service) productPageCursors(sort string, page []db.ListProductsAdvancedRow, hasNext, hasPrev bool) (next, prev interface{}) {
+	if len(page) == 0 {
+		return nil, nil
+	}
+	if hasNext {
+		next = assets_services.EncodeCursor(s.cursorSecret(), productCursor(sort, page[len(page)-1], false))
+	}
+	if hasPrev {
+		prev = assets_services.EncodeCursor(s.cursorSecret(), productCursor(sort, page[0], true))
+	}
+	return next, prev
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	config_assets "github.com/TranVinhHien/ecom_product_service/assets/config"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	s := &service{env: config_assets.ReadENV{JWTSecret: "secret"}}
+	p := db.ListProductsAdvancedRow{
+		ID:         "p-1",
+		MinPrice:   sql.NullFloat64{Float64: 125000.5, Valid: true},
+		CreateDate: sql.NullTime{Time: time.Date(2026, 3, 4, 5, 6, 7, 8, time.UTC), Valid: true},
+	}
+
+	token := assets_services.EncodeCursor(s.cursorSecret(), productCursor("price_asc", p, false))
+	keyset, _, err := s.parseProductKeyset("price_asc", token)
+	require.NoError(t, err)
+	require.Equal(t, 125000.5, keyset.Value)
+	require.Equal(t, "p-1", keyset.ID)
+
+	token = assets_services.EncodeCursor(s.cursorSecret(), productCursor("", p, true))
+	keyset, _, err = s.parseProductKeyset("", token)
+	require.NoError(t, err)
+	require.Equal(t, p.CreateDate.Time, keyset.Value)
+	require.True(t, keyset.Backward)
+}
+
+func TestProductCursorNullMinPrice(t *testing.T) {
+	s := &service{env: config_assets.ReadENV{JWTSecret: "secret"}}
+	// sản phẩm chưa có SKU nào có giá không được coi là giá 0
+	p := db.ListProductsAdvancedRow{ID: "p-2"}
+
+	c := productCursor("price_desc", p, false)
+	require.True(t, c.Null)
+	keyset, _, err := s.parseProductKeyset("price_desc", assets_services.EncodeCursor(s.cursorSecret(), c))
+	require.NoError(t, err)
+	require.Nil(t, keyset.Value)
+	require.Equal(t, "p-2", keyset.ID)
+
+	// cột không thể NULL thì cursor đánh dấu Null là không hợp lệ
+	bad := assets_services.EncodeCursor(s.cursorSecret(), assets_services.Cursor{Sort: "best_sell", ID: "p-2", Null: true})
+	_, _, err = s.parseProductKeyset("best_sell", bad)
+	require.Error(t, err)
+}
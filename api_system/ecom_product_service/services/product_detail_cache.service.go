@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+)
+
+const (
+	// loại cache: getdetail/:key trả thêm attributes nên không dùng chung với getdetail_with_id/:id
+	productDetailByKey = "key"
+	productDetailByID  = "id"
+
+	defaultProductDetailCacheTTL = 10 * time.Minute
+)
+
+// số lần hit/miss của cache chi tiết sản phẩm, xem qua API admin GET /v1/product/admin/cache_stats.
+// shared: số request được gộp vào lần đọc DB của request khác (singleflight), error: lỗi Redis.
+var productDetailCacheStats = expvar.NewMap("product_detail_cache")
+
+func (s *service) productDetailCacheTTL() time.Duration {
+	if s.env.ProductDetailCacheTTL > 0 {
+		return s.env.ProductDetailCacheTTL
+	}
+	return defaultProductDetailCacheTTL
+}
+
+func (s *service) GetProductWithID(ctx context.Context, product_id string) (map[string]interface{}, *assets_services.ServiceError) {
+	return s.cachedProductDetail(ctx, productDetailByID, product_id, func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		return s.loadProductWithID(ctx, product_id)
+	})
+}
+
+func (s *service) GetDetailProduct(ctx context.Context, key string) (map[string]interface{}, *assets_services.ServiceError) {
+	load := func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		return s.loadDetailProduct(ctx, key)
+	}
+	productID, err := s.redis.ProductIDBySlug(ctx, key)
+	if err != nil {
+		productDetailCacheStats.Add("error", 1)
+		log.Printf("[ProductCache] %v", err)
+	}
+	if productID != "" {
+		return s.cachedProductDetail(ctx, productDetailByKey, productID, load)
+	}
+	// chưa biết product_id nên không đọc phiên bản trước được: lần này đọc DB và chỉ lưu ánh xạ key -> id,
+	// từ request sau mới đi qua cache theo phiên bản
+	productDetailCacheStats.Add("miss", 1)
+	result, productID, serr := load(ctx)
+	if serr != nil {
+		return nil, serr
+	}
+	if err := s.redis.SetProductIDBySlug(ctx, key, productID, s.productDetailCacheTTL()); err != nil {
+		productDetailCacheStats.Add("error", 1)
+		log.Printf("[ProductCache] %v", err)
+	}
+	return result, nil
+}
+
+// cachedProductDetail đọc cache theo phiên bản hiện tại của sản phẩm, nếu không có thì đọc DB.
+// Phiên bản được đọc trước khi đọc DB nên nếu sản phẩm bị ghi trong lúc đọc, bản vừa đọc
+// được lưu dưới phiên bản cũ và không bao giờ được trả ra. Các request trùng nhau chỉ đọc DB một lần.
+func (s *service) cachedProductDetail(ctx context.Context, kind, productID string, load func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError)) (map[string]interface{}, *assets_services.ServiceError) {
+	version, err := s.redis.ProductDetailVersion(ctx, productID)
+	if err != nil {
+		// Redis lỗi thì vẫn phục vụ từ DB
+		productDetailCacheStats.Add("error", 1)
+		log.Printf("[ProductCache] %v", err)
+		result, _, serr := load(ctx)
+		return result, serr
+	}
+	detail, err := s.redis.GetProductDetail(ctx, kind, productID, version)
+	if err != nil {
+		productDetailCacheStats.Add("error", 1)
+		log.Printf("[ProductCache] %v", err)
+	}
+	if detail != nil {
+		productDetailCacheStats.Add("hit", 1)
+		return detail, nil
+	}
+	productDetailCacheStats.Add("miss", 1)
+
+	flightKey := kind + ":" + productID + ":" + strconv.FormatInt(version, 10)
+	value, _, shared := s.detailFlight.Do(flightKey, func() (interface{}, error) {
+		// request đầu tiên bị hủy không được làm hỏng các request đang chờ cùng kết quả
+		flightCtx := context.WithoutCancel(ctx)
+		result, _, serr := load(flightCtx)
+		if serr != nil {
+			return serr, nil
+		}
+		if err := s.redis.SetProductDetail(flightCtx, kind, productID, version, result, s.productDetailCacheTTL()); err != nil {
+			productDetailCacheStats.Add("error", 1)
+			log.Printf("[ProductCache] %v", err)
+		}
+		return result, nil
+	})
+	if shared {
+		productDetailCacheStats.Add("shared", 1)
+	}
+	if serr, ok := value.(*assets_services.ServiceError); ok {
+		return nil, serr
+	}
+	return value.(map[string]interface{}), nil
+}
+
+// invalidateProductDetail bỏ cache chi tiết sau khi ghi sản phẩm, lỗi chỉ ghi log vì cache tự hết hạn theo TTL
+func (s *service) invalidateProductDetail(ctx context.Context, productIDs []string, slugs ...string) {
+	if err := s.redis.InvalidateProductDetail(ctx, productIDs, slugs...); err != nil {
+		productDetailCacheStats.Add("error", 1)
+		log.Printf("[ProductCache] %v", err)
+	}
+}
+
+// InvalidateProductDetailCache dùng cho service khác báo thay đổi (vd đánh giá mới từ order service)
+func (s *service) InvalidateProductDetailCache(ctx context.Context, productIDs []string) *assets_services.ServiceError {
+	if len(productIDs) == 0 {
+		return assets_services.NewError(400, fmt.Errorf("danh sách product_ids không được để trống"))
+	}
+	if err := s.redis.InvalidateProductDetail(ctx, productIDs); err != nil {
+		productDetailCacheStats.Add("error", 1)
+		return assets_services.NewError(500, err)
+	}
+	return nil
+}
+
+// SyncProductRatings order service gửi điểm đánh giá mới của sản phẩm (sau khi đánh giá được tạo, sửa hoặc ẩn/hiện),
+// điểm được lưu vào bảng product để sắp xếp theo đánh giá và cache chi tiết của các sản phẩm này bị bỏ
+func (s *service) SyncProductRatings(ctx context.Context, ratings []services.ProductRatingSync) *assets_services.ServiceError {
+	if len(ratings) == 0 {
+		return assets_services.NewError(400, fmt.Errorf("danh sách ratings không được để trống"))
+	}
+	productIDs := make([]string, 0, len(ratings))
+	err := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		for _, rating := range ratings {
+			if err := tx.UpdateProductRating(ctx, db.UpdateProductRatingParams{
+				RatingAverage: rating.RatingAverage,
+				RatingCount:   rating.RatingCount,
+				RatingScore:   rating.RatingScore,
+				ID:            rating.ProductID,
+			}); err != nil {
+				return fmt.Errorf("lỗi khi cập nhật điểm đánh giá sản phẩm %s: %w", rating.ProductID, err)
+			}
+			productIDs = append(productIDs, rating.ProductID)
+		}
+		return nil
+	})
+	if err != nil {
+		return assets_services.NewError(500, err)
+	}
+	s.invalidateProductDetail(ctx, productIDs)
+	return nil
+}
+
+func (s *service) ProductDetailCacheStats(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	stats := map[string]interface{}{"hit": int64(0), "miss": int64(0), "shared": int64(0), "error": int64(0)}
+	productDetailCacheStats.Do(func(kv expvar.KeyValue) {
+		if v, ok := kv.Value.(*expvar.Int); ok {
+			stats[kv.Key] = v.Value()
+		}
+	})
+	return map[string]interface{}{"data": stats}, nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	"github.com/stretchr/testify/require"
+)
+
+// detailCacheRedis giả lập cache chi tiết theo phiên bản như RedisDB
+type detailCacheRedis struct {
+	ServicesRedis
+	mu       sync.Mutex
+	versions map[string]int64
+	details  map[string]map[string]interface{}
+	gets     int32
+}
+
+func newDetailCacheRedis() *detailCacheRedis {
+	return &detailCacheRedis{versions: map[string]int64{}, details: map[string]map[string]interface{}{}}
+}
+
+func (f *detailCacheRedis) ProductDetailVersion(ctx context.Context, productID string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.versions[productID], nil
+}
+
+func (f *detailCacheRedis) GetProductDetail(ctx context.Context, kind, productID string, version int64) (map[string]interface{}, error) {
+	atomic.AddInt32(&f.gets, 1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.details[fmt.Sprintf("%s:%s:%d", kind, productID, version)], nil
+}
+
+func (f *detailCacheRedis) SetProductDetail(ctx context.Context, kind, productID string, version int64, detail map[string]interface{}, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.details[fmt.Sprintf("%s:%s:%d", kind, productID, version)] = detail
+	return nil
+}
+
+func (f *detailCacheRedis) InvalidateProductDetail(ctx context.Context, productIDs []string, slugs ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, id := range productIDs {
+		f.versions[id]++
+	}
+	return nil
+}
+
+func TestCachedProductDetailVersionBump(t *testing.T) {
+	cache := newDetailCacheRedis()
+	s := &service{redis: cache}
+	name := "ao thun"
+	loads := 0
+	load := func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		loads++
+		return map[string]interface{}{"name": name}, "p-1", nil
+	}
+
+	detail, serr := s.cachedProductDetail(context.Background(), productDetailByID, "p-1", load)
+	require.Nil(t, serr)
+	require.Equal(t, "ao thun", detail["name"])
+	_, serr = s.cachedProductDetail(context.Background(), productDetailByID, "p-1", load)
+	require.Nil(t, serr)
+	require.Equal(t, 1, loads)
+
+	// sửa sản phẩm thì tăng phiên bản, bản cache cũ không còn được trả ra
+	name = "ao thun nam"
+	s.invalidateProductDetail(context.Background(), []string{"p-1"})
+	detail, serr = s.cachedProductDetail(context.Background(), productDetailByID, "p-1", load)
+	require.Nil(t, serr)
+	require.Equal(t, "ao thun nam", detail["name"])
+	require.Equal(t, 2, loads)
+}
+
+func TestCachedProductDetailWriteDuringLoad(t *testing.T) {
+	cache := newDetailCacheRedis()
+	s := &service{redis: cache}
+	// sản phẩm bị sửa trong lúc đang đọc DB: bản vừa đọc lưu dưới phiên bản cũ
+	load := func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		s.invalidateProductDetail(ctx, []string{"p-1"})
+		return map[string]interface{}{"name": "cu"}, "p-1", nil
+	}
+	_, serr := s.cachedProductDetail(context.Background(), productDetailByID, "p-1", load)
+	require.Nil(t, serr)
+
+	fresh := func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		return map[string]interface{}{"name": "moi"}, "p-1", nil
+	}
+	detail, serr := s.cachedProductDetail(context.Background(), productDetailByID, "p-1", fresh)
+	require.Nil(t, serr)
+	require.Equal(t, "moi", detail["name"])
+}
+
+func TestCachedProductDetailSingleflight(t *testing.T) {
+	cache := newDetailCacheRedis()
+	s := &service{redis: cache}
+	const requests = 5
+	var loads int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (map[string]interface{}, string, *assets_services.ServiceError) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return map[string]interface{}{"name": "ao thun"}, "p-1", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]map[string]interface{}, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = s.cachedProductDetail(context.Background(), productDetailByID, "p-1", load)
+		}(i)
+	}
+	// chờ mọi request đều miss cache rồi mới cho lần đọc DB trả về
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&cache.gets) == requests }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&loads))
+	for _, r := range results {
+		require.Equal(t, "ao thun", r["name"])
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"firebase.google.com/go/messaging"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	sendMessage "github.com/TranVinhHien/ecom_product_service/services/assets/sendMessage"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+// ngưỡng mặc định khi MODERATION_PRICE_THRESHOLD không được cấu hình
+const defaultModerationPriceThreshold = 0.3
+
+func (s *service) ApproveProduct(ctx context.Context, userName, productID string) *assets_services.ServiceError {
+	return s.moderateProduct(ctx, userName, productID, true, "")
+}
+
+func (s *service) RejectProduct(ctx context.Context, userName, productID, reason string) *assets_services.ServiceError {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return assets_services.NewError(400, fmt.Errorf("phải nhập lý do từ chối sản phẩm"))
+	}
+	return s.moderateProduct(ctx, userName, productID, false, reason)
+}
+
+func (s *service) ListProductModeration(ctx context.Context, principal services.Principal, productID string) (map[string]interface{}, *assets_services.ServiceError) {
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(404, fmt.Errorf("sản phẩm không tồn tại"))
+		}
+		return nil, assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin sản phẩm: %w", err))
+	}
+	if err := s.authorizeShop(ctx, principal, product.ShopID, productID, ownershipActionModerationHistory); err != nil {
+		return nil, err
+	}
+	history, err := s.repository.ListProductModerationByProduct(ctx, productID)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy lịch sử kiểm duyệt. Lỗi: %v", err))
+	}
+	return assets_services.NormalizeListSQLNulls(history, "data"), nil
+}
+
+// moderateProduct chuyển trạng thái sản phẩm sang Active (duyệt) hoặc Rejected (từ chối),
+// lưu lịch sử kiểm duyệt trong cùng transaction và gửi thông báo cho shop sau khi commit.
+// Sản phẩm đang ẩn được shop sửa rồi gửi duyệt thì khi duyệt vẫn giữ trạng thái ẩn.
+func (s *service) moderateProduct(ctx context.Context, userName, productID string, approve bool, reason string) *assets_services.ServiceError {
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(404, fmt.Errorf("sản phẩm không tồn tại"))
+		}
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin sản phẩm: %w", err))
+	}
+
+	action := db.ProductModerationActionREJECTED
+	if approve {
+		action = db.ProductModerationActionAPPROVED
+	}
+
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		locked, err := tx.GetProductStatusForUpdate(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy trạng thái sản phẩm: %w", err)
+		}
+		currentStatus := locked.ProductDeleteStatus
+		newStatus, serr := moderatedStatus(ctx, tx, productID, currentStatus, approve)
+		if serr != nil {
+			return serr
+		}
+		err = tx.UpdateProduct(ctx, db.UpdateProductParams{
+			ID:           productID,
+			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: newStatus, Valid: true},
+			UpdateBy:     sql.NullString{String: userName, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi cập nhật trạng thái sản phẩm: %w", err)
+		}
+		if err := recordProductStatus(ctx, tx, productID, currentStatus, newStatus, userName); err != nil {
+			return err
+		}
+		return tx.CreateProductModeration(ctx, db.CreateProductModerationParams{
+			ID:        uuid.New().String(),
+			ProductID: productID,
+			Action:    action,
+			Reason:    sql.NullString{String: reason, Valid: reason != ""},
+			Actor:     userName,
+		})
+	})
+	if txErr != nil {
+		var serr *assets_services.ServiceError
+		if errors.As(txErr, &serr) {
+			return serr
+		}
+		return assets_services.NewError(400, fmt.Errorf("không thể kiểm duyệt sản phẩm. Lỗi: %v", txErr))
+	}
+	s.refreshProductSuggestion(ctx, productID)
+	s.invalidateProductDetail(ctx, []string{productID})
+
+	if approve {
+		s.notifyShop(ctx, product.ShopID, sendMessage.SanPhamDaDuyet(product.Name))
+	} else {
+		s.notifyShop(ctx, product.ShopID, sendMessage.SanPhamBiTuChoi(product.Name, reason))
+	}
+	return nil
+}
+
+// moderatedStatus tính trạng thái sau khi admin duyệt/từ chối. Duyệt thì trả về trạng thái trước khi vào
+// vòng kiểm duyệt (ẩn thì vẫn ẩn), không có lịch sử thì là Active.
+func moderatedStatus(ctx context.Context, tx db.Querier, productID string, current db.ProductDeleteStatus, approve bool) (db.ProductDeleteStatus, *assets_services.ServiceError) {
+	if current == db.ProductDeleteStatusDeleted {
+		return "", assets_services.NewError(400, fmt.Errorf("sản phẩm đã bị xóa không thể kiểm duyệt"))
+	}
+	if !approve {
+		if current == db.ProductDeleteStatusRejected {
+			return "", assets_services.NewError(400, fmt.Errorf("sản phẩm đã bị từ chối trước đó"))
+		}
+		return db.ProductDeleteStatusRejected, nil
+	}
+	if current == db.ProductDeleteStatusActive || current == db.ProductDeleteStatusUnlisted {
+		return "", assets_services.NewError(400, fmt.Errorf("sản phẩm đã được duyệt trước đó"))
+	}
+	previous, err := tx.GetStatusBeforeModeration(ctx, productID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", assets_services.NewError(400, fmt.Errorf("lỗi khi lấy lịch sử trạng thái sản phẩm: %w", err))
+	}
+	if db.ProductDeleteStatus(previous) == db.ProductDeleteStatusUnlisted {
+		return db.ProductDeleteStatusUnlisted, nil
+	}
+	return db.ProductDeleteStatusActive, nil
+}
+
+// submitForModeration đưa sản phẩm về trạng thái Pending và ghi lịch sử, dùng bên trong transaction.
+// from là trạng thái trước khi gửi duyệt, được dùng để trả lại trạng thái ẩn khi duyệt xong.
+func submitForModeration(ctx context.Context, tx db.Querier, productID, actor string, from db.ProductDeleteStatus, changedFields []string) error {
+	err := tx.UpdateProduct(ctx, db.UpdateProductParams{
+		ID:           productID,
+		DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: db.ProductDeleteStatusPending, Valid: true},
+		UpdateBy:     sql.NullString{String: actor, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi chuyển sản phẩm sang chờ duyệt: %w", err)
+	}
+	if err := recordProductStatus(ctx, tx, productID, from, db.ProductDeleteStatusPending, actor); err != nil {
+		return err
+	}
+	return createSubmittedModeration(ctx, tx, productID, actor, changedFields)
+}
+
+func createSubmittedModeration(ctx context.Context, tx db.Querier, productID, actor string, changedFields []string) error {
+	changed := sql.NullString{}
+	if len(changedFields) > 0 {
+		changedJSON, err := json.Marshal(changedFields)
+		if err != nil {
+			return fmt.Errorf("lỗi xử lý danh sách trường thay đổi: %w", err)
+		}
+		changed = sql.NullString{String: string(changedJSON), Valid: true}
+	}
+	err := tx.CreateProductModeration(ctx, db.CreateProductModerationParams{
+		ID:            uuid.New().String(),
+		ProductID:     productID,
+		Action:        db.ProductModerationActionSUBMITTED,
+		ChangedFields: changed,
+		Actor:         actor,
+	})
+	if err != nil {
+		return fmt.Errorf("không thể ghi lịch sử kiểm duyệt: %w", err)
+	}
+	return nil
+}
+
+func (s *service) moderationPriceThreshold() float64 {
+	if s.env.ModerationPriceThreshold > 0 {
+		return s.env.ModerationPriceThreshold
+	}
+	return defaultModerationPriceThreshold
+}
+
+// isMaterialPriceChange kiểm tra giá mới có lệch so với giá cũ vượt ngưỡng cho phép hay không
+func isMaterialPriceChange(oldPrice, newPrice, threshold float64) bool {
+	if oldPrice <= 0 {
+		return newPrice != oldPrice
+	}
+	return math.Abs(newPrice-oldPrice)/oldPrice > threshold
+}
+
+// materialSKUChanges trả về các trường thay đổi quan trọng của danh sách SKU gửi lên so với giá hiện tại.
+// SKU chưa có ID là SKU thêm mới nên luôn phải duyệt lại, giá trị Price = 0 nghĩa là không đổi giá.
+func materialSKUChanges(currentPrices map[string]float64, skus []services.ProductSKUParams, threshold float64) []string {
+	var fields []string
+	added, priceChanged := false, false
+	for _, sku := range skus {
+		if sku.ID == "" {
+			added = true
+			continue
+		}
+		oldPrice, ok := currentPrices[sku.ID]
+		if ok && sku.Price != 0 && isMaterialPriceChange(oldPrice, sku.Price, threshold) {
+			priceChanged = true
+		}
+	}
+	if added {
+		fields = append(fields, "sku")
+	}
+	if priceChanged {
+		fields = append(fields, "price")
+	}
+	return fields
+}
+
+// needsResubmission cho biết lần shop sửa sản phẩm có phải gửi duyệt lại hay không.
+// Sản phẩm Active hoặc đang ẩn chỉ duyệt lại khi có thay đổi quan trọng, sản phẩm bị từ chối luôn được gửi duyệt lại.
+func needsResubmission(status db.ProductDeleteStatus, changedFields []string) bool {
+	switch status {
+	case db.ProductDeleteStatusActive, db.ProductDeleteStatusUnlisted:
+		return len(changedFields) > 0
+	case db.ProductDeleteStatusRejected:
+		return true
+	}
+	return false
+}
+
+// notifyShop gửi thông báo tới topic của shop, bỏ qua nếu firebase chưa được cấu hình
+func (s *service) notifyShop(ctx context.Context, shopID string, notification *messaging.Notification) {
+	if s.firebase == nil || shopID == "" {
+		return
+	}
+	if err := s.firebase.SendToTopic(ctx, sendMessage.ShopTopic(shopID), notification); err != nil {
+		fmt.Println("Error SendToTopic:", err)
+	}
+}
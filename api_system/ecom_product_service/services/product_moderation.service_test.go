@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsMaterialPriceChange(t *testing.T) {
+	tests := []struct {
+		oldPrice, newPrice float64
+		want               bool
+	}{
+		// đúng bằng ngưỡng 30% chưa phải thay đổi quan trọng
+		{100000, 130000, false},
+		{100000, 70000, false},
+		{100000, 130001, true},
+		{100000, 69999, true},
+		{100000, 100000, false},
+		// giá cũ bằng 0 thì mọi lần đổi giá đều phải duyệt lại
+		{0, 1000, true},
+		{0, 0, false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, isMaterialPriceChange(tt.oldPrice, tt.newPrice, defaultModerationPriceThreshold), "%v -> %v", tt.oldPrice, tt.newPrice)
+	}
+}
+
+func TestMaterialSKUChanges(t *testing.T) {
+	current := map[string]float64{"sku-1": 100000, "sku-2": 200000}
+
+	require.Empty(t, materialSKUChanges(current, nil, defaultModerationPriceThreshold))
+	// Price = 0 là không đổi giá, đổi giá trong ngưỡng không cần duyệt lại
+	require.Empty(t, materialSKUChanges(current, []services.ProductSKUParams{
+		{ID: "sku-1", Quantity: 5},
+		{ID: "sku-2", Price: 250000},
+	}, defaultModerationPriceThreshold))
+
+	require.Equal(t, []string{"price"}, materialSKUChanges(current, []services.ProductSKUParams{
+		{ID: "sku-1", Price: 140000},
+	}, defaultModerationPriceThreshold))
+
+	// SKU thêm mới luôn phải duyệt lại dù giá bao nhiêu
+	require.Equal(t, []string{"sku"}, materialSKUChanges(current, []services.ProductSKUParams{
+		{SkuCode: "NEW", Price: 100000},
+	}, defaultModerationPriceThreshold))
+	require.Equal(t, []string{"sku", "price"}, materialSKUChanges(current, []services.ProductSKUParams{
+		{ID: "sku-2", Price: 1000},
+		{SkuCode: "NEW-1", Price: 1},
+		{SkuCode: "NEW-2", Price: 2},
+	}, defaultModerationPriceThreshold))
+}
+
+func TestNeedsResubmission(t *testing.T) {
+	tests := []struct {
+		status  db.ProductDeleteStatus
+		changed []string
+		want    bool
+	}{
+		{db.ProductDeleteStatusActive, nil, false},
+		{db.ProductDeleteStatusActive, []string{"price"}, true},
+		{db.ProductDeleteStatusUnlisted, nil, false},
+		{db.ProductDeleteStatusUnlisted, []string{"sku"}, true},
+		// bị từ chối thì sửa gì cũng gửi duyệt lại
+		{db.ProductDeleteStatusRejected, nil, true},
+		{db.ProductDeleteStatusRejected, []string{"name"}, true},
+		// đang chờ duyệt thì giữ nguyên yêu cầu cũ
+		{db.ProductDeleteStatusPending, []string{"name"}, false},
+		{db.ProductDeleteStatusDeleted, []string{"name"}, false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, needsResubmission(tt.status, tt.changed), "%s %v", tt.status, tt.changed)
+	}
+}
+
+func TestModeratedStatusTransitions(t *testing.T) {
+	tests := []struct {
+		current db.ProductDeleteStatus
+		approve bool
+		want    db.ProductDeleteStatus
+		code    int
+	}{
+		{db.ProductDeleteStatusPending, true, db.ProductDeleteStatusActive, 0},
+		{db.ProductDeleteStatusPending, false, db.ProductDeleteStatusRejected, 0},
+		{db.ProductDeleteStatusRejected, true, db.ProductDeleteStatusActive, 0},
+		{db.ProductDeleteStatusRejected, false, "", 400},
+		{db.ProductDeleteStatusActive, true, "", 400},
+		{db.ProductDeleteStatusActive, false, db.ProductDeleteStatusRejected, 0},
+		{db.ProductDeleteStatusUnlisted, true, "", 400},
+		{db.ProductDeleteStatusUnlisted, false, db.ProductDeleteStatusRejected, 0},
+		{db.ProductDeleteStatusDeleted, true, "", 400},
+		{db.ProductDeleteStatusDeleted, false, "", 400},
+	}
+	for _, tt := range tests {
+		next, serr := moderatedStatus(context.Background(), &statusHistoryTx{}, "p-1", tt.current, tt.approve)
+		if tt.code != 0 {
+			require.NotNil(t, serr, "%s approve=%v", tt.current, tt.approve)
+			require.Equal(t, tt.code, serr.Code)
+			continue
+		}
+		require.Nil(t, serr, "%s approve=%v", tt.current, tt.approve)
+		require.Equal(t, tt.want, next, "%s approve=%v", tt.current, tt.approve)
+	}
+}
+
+func TestRejectedProductEditGoesPending(t *testing.T) {
+	ctx := context.Background()
+	tx := &statusHistoryTx{status: db.ProductDeleteStatusPending}
+	tx.moderate(t, false)
+	require.Equal(t, db.ProductDeleteStatusRejected, tx.status)
+
+	// shop sửa sản phẩm bị từ chối, kể cả không có thay đổi quan trọng, vẫn quay lại chờ duyệt
+	require.True(t, needsResubmission(tx.status, nil))
+	require.NoError(t, submitForModeration(ctx, tx, "p-1", "seller", tx.status, nil))
+	require.Equal(t, db.ProductDeleteStatusPending, tx.status)
+	last := tx.history[len(tx.history)-1]
+	require.Equal(t, string(db.ProductDeleteStatusRejected), last.FromStatus)
+	require.Equal(t, string(db.ProductDeleteStatusPending), last.ToStatus)
+}
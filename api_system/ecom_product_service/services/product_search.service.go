@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services_search "github.com/TranVinhHien/ecom_product_service/services/search"
+)
+
+const (
+	// số kết quả tìm kiếm (đã lọc, theo độ liên quan) tối đa được sắp xếp lại theo giá/tên/...,
+	// tổng số kết quả vẫn đếm đủ, sort=relevance không bị giới hạn
+	searchSortLimit      = 10000
+	searchIndexBatchSize = 500
+	// chu kỳ đồng bộ chỉ mục với bảng product_search_document (cập nhật từ instance khác)
+	searchIndexSyncInterval = time.Minute
+)
+
+func toSearchDocument(row db.ProductSearchDocument) services_search.Document {
+	return services_search.Document{
+		ID: row.ProductID,
+		Fields: [4]string{
+			services_search.FieldName:        row.NameFolded,
+			services_search.FieldBrand:       row.BrandFolded,
+			services_search.FieldCategory:    row.CategoryFolded,
+			services_search.FieldDescription: row.DescriptionFolded,
+		},
+	}
+}
+
+// RefreshProductSearchDocument dựng lại tài liệu tìm kiếm của sản phẩm và cập nhật chỉ mục.
+// Gọi sau khi transaction thay đổi sản phẩm đã commit.
+func (s *service) RefreshProductSearchDocument(ctx context.Context, productID string) error {
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("không tìm thấy sản phẩm: %w", err)
+	}
+	if product.DeleteStatus.ProductDeleteStatus == db.ProductDeleteStatusDeleted {
+		// giữ dòng rỗng thay vì xóa để job đồng bộ của các instance khác cũng gỡ sản phẩm khỏi chỉ mục
+		if err := s.repository.UpsertProductSearchDocument(ctx, db.UpsertProductSearchDocumentParams{ProductID: productID}); err != nil {
+			return fmt.Errorf("không thể lưu tài liệu tìm kiếm: %w", err)
+		}
+		s.search.Remove(productID)
+		return nil
+	}
+	document, err := s.BuildProductSearchString(ctx, productID)
+	if err != nil {
+		return err
+	}
+	var brand, category string
+	if product.BrandID.Valid && product.BrandID.String != "" {
+		if b, err := s.repository.GetBrand(ctx, product.BrandID.String); err == nil {
+			brand = b.Name + " " + b.Code
+		}
+	}
+	if c, err := s.repository.GetCategory(ctx, product.CategoryID); err == nil {
+		// path dạng /thoi-trang/ao-thun nên tìm được cả theo danh mục cha
+		category = c.Name + " " + c.Path.String
+	}
+	description := strings.TrimSpace(product.ShortDescription.String + " " + product.Description.String)
+
+	params := db.UpsertProductSearchDocumentParams{
+		ProductID:         productID,
+		NameFolded:        assets_services.FoldVietnamese(product.Name),
+		BrandFolded:       assets_services.FoldVietnamese(brand),
+		CategoryFolded:    assets_services.FoldVietnamese(category),
+		DescriptionFolded: assets_services.FoldVietnamese(description),
+		Document:          assets_services.FoldVietnamese(document),
+	}
+	if err := s.repository.UpsertProductSearchDocument(ctx, params); err != nil {
+		return fmt.Errorf("không thể lưu tài liệu tìm kiếm: %w", err)
+	}
+	s.search.Upsert(toSearchDocument(db.ProductSearchDocument{
+		ProductID:         params.ProductID,
+		NameFolded:        params.NameFolded,
+		BrandFolded:       params.BrandFolded,
+		CategoryFolded:    params.CategoryFolded,
+		DescriptionFolded: params.DescriptionFolded,
+	}))
+	return nil
+}
+
+// refreshProductSearch cập nhật tài liệu tìm kiếm sau khi ghi sản phẩm, lỗi chỉ ghi log
+// vì job đồng bộ / reindex sẽ sửa lại sau
+func (s *service) refreshProductSearch(ctx context.Context, productID string) {
+	if err := s.RefreshProductSearchDocument(ctx, productID); err != nil {
+		log.Printf("[Search] không thể cập nhật tài liệu tìm kiếm cho sản phẩm %s: %v", productID, err)
+	}
+}
+
+// refreshProductSearchAsync dựng lại tài liệu tìm kiếm của nhiều sản phẩm trong nền,
+// dùng khi đổi tên thương hiệu/danh mục làm thay đổi tài liệu của các sản phẩm thuộc về chúng
+func (s *service) refreshProductSearchAsync(productIDs []string) {
+	if len(productIDs) == 0 {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		for _, id := range productIDs {
+			s.refreshProductSearch(ctx, id)
+		}
+		log.Printf("[Search] đã dựng lại tài liệu tìm kiếm cho %d sản phẩm", len(productIDs))
+	}()
+}
+
+// refreshBrandProducts bỏ cache chi tiết và dựng lại tài liệu tìm kiếm của các sản phẩm thuộc thương hiệu
+func (s *service) refreshBrandProducts(ctx context.Context, brandID string) {
+	ids, err := s.repository.ListProductIDsByBrand(ctx, sql.NullString{String: brandID, Valid: true})
+	if err != nil {
+		log.Printf("[Search] không thể lấy sản phẩm của thương hiệu %s: %v", brandID, err)
+		return
+	}
+	s.invalidateProductDetail(ctx, ids)
+	s.refreshProductSearchAsync(ids)
+}
+
+// refreshCategoryProductsSearch dựng lại tài liệu tìm kiếm của các sản phẩm thuộc danh mục và các danh mục con
+// (path của danh mục nằm trong tài liệu nên đổi tên/di chuyển danh mục cha cũng ảnh hưởng danh mục con)
+func (s *service) refreshCategoryProductsSearch(ctx context.Context, categoryID string) {
+	category, err := s.repository.GetCategory(ctx, categoryID)
+	if err != nil {
+		log.Printf("[Search] không tìm thấy danh mục %s: %v", categoryID, err)
+		return
+	}
+	cateIDs, err := s.categorySubtreeIDs(ctx, category)
+	if err != nil {
+		log.Printf("[Search] %v", err)
+		return
+	}
+	ids, err := s.repository.ListProductIDsByCategories(ctx, cateIDs)
+	if err != nil {
+		log.Printf("[Search] không thể lấy sản phẩm của danh mục %s: %v", categoryID, err)
+		return
+	}
+	s.refreshProductSearchAsync(ids)
+}
+
+// ReindexProductSearch dựng lại tài liệu tìm kiếm cho toàn bộ sản phẩm
+func (s *service) ReindexProductSearch(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	total := 0
+	failed := []string{}
+	afterID := ""
+	for {
+		ids, err := s.repository.ListProductIDsAfter(ctx, db.ListProductIDsAfterParams{
+			AfterID: afterID,
+			Limit:   searchIndexBatchSize,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy danh sách sản phẩm để reindex. Lỗi: %v", err))
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			if err := s.RefreshProductSearchDocument(ctx, id); err != nil {
+				log.Printf("[ReindexProductSearch] sản phẩm %s lỗi: %v", id, err)
+				failed = append(failed, id)
+				continue
+			}
+			total++
+		}
+		afterID = ids[len(ids)-1]
+	}
+	log.Printf("[ReindexProductSearch] đã dựng lại %d tài liệu, lỗi %d", total, len(failed))
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"total_reindexed": total,
+			"failed":          failed,
+		},
+	}, nil
+}
+
+// loadSearchIndex nạp toàn bộ bảng product_search_document vào chỉ mục trong bộ nhớ
+func (s *service) loadSearchIndex(ctx context.Context) (int, error) {
+	total := 0
+	afterID := ""
+	for {
+		rows, err := s.repository.ListProductSearchDocuments(ctx, db.ListProductSearchDocumentsParams{
+			AfterID: afterID,
+			Limit:   searchIndexBatchSize,
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+		for _, row := range rows {
+			s.search.Upsert(toSearchDocument(row))
+		}
+		total += len(rows)
+		afterID = rows[len(rows)-1].ProductID
+	}
+}
+
+// StartSearchIndexSync nạp chỉ mục khi khởi động rồi định kỳ lấy các tài liệu mới cập nhật.
+// Chạy trong goroutine riêng, dừng khi ctx bị hủy.
+func (s *service) StartSearchIndexSync(ctx context.Context) {
+	lastSync := time.Now()
+	total, err := s.loadSearchIndex(ctx)
+	if err != nil {
+		log.Printf("[Search] không thể nạp chỉ mục tìm kiếm: %v", err)
+	}
+	if err == nil && total == 0 {
+		// bảng còn trống (mới migrate) thì dựng tài liệu từ dữ liệu sản phẩm
+		if _, errReindex := s.ReindexProductSearch(ctx); errReindex != nil {
+			log.Printf("[Search] không thể dựng tài liệu tìm kiếm: %v", errReindex)
+		}
+	}
+	log.Printf("[Search] đã nạp %d tài liệu vào chỉ mục tìm kiếm", s.search.Len())
+
+	ticker := time.NewTicker(searchIndexSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			// lùi lại một chút để không bỏ sót bản ghi cập nhật cùng lúc với lần đồng bộ trước
+			rows, err := s.repository.ListProductSearchDocumentsUpdatedSince(ctx, sql.NullTime{Time: lastSync.Add(-5 * time.Second), Valid: true})
+			if err != nil {
+				log.Printf("[Search] không thể đồng bộ chỉ mục tìm kiếm: %v", err)
+				continue
+			}
+			for _, row := range rows {
+				s.search.Upsert(toSearchDocument(row))
+			}
+			lastSync = now
+		}
+	}
+}
+
+// searchProductIDs trả về toàn bộ sản phẩm khớp từ khóa theo độ liên quan giảm dần,
+// các bộ lọc khác được áp trong SQL trước khi phân trang.
+// ok = false khi chỉ mục chưa sẵn sàng, khi đó gọi hàm tìm theo LIKE như cũ.
+func (s *service) searchProductIDs(keywords string) (ids []string, ok bool) {
+	if s.search.Len() == 0 {
+		return nil, false
+	}
+	results := s.search.Search(keywords, 0)
+	ids = make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids, true
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+// authorizeListStatus khách chỉ được xem sản phẩm đang bán. Trạng thái khác dành cho admin,
+// hoặc người bán khi lọc theo đúng shop của mình.
+func (s *service) authorizeListStatus(ctx context.Context, principal services.Principal, status, shopID string) *assets_services.ServiceError {
+	if status == "" || status == string(db.ProductDeleteStatusActive) || principal.IsAdmin() {
+		return nil
+	}
+	if principal.UserName == "" && principal.UserID == "" {
+		return assets_services.NewError(401, fmt.Errorf("phải đăng nhập để xem sản phẩm ở trạng thái %s", status))
+	}
+	if !principal.IsSeller() {
+		return assets_services.NewError(403, fmt.Errorf("không có quyền xem sản phẩm ở trạng thái %s", status))
+	}
+	if shopID == "" {
+		return assets_services.NewError(400, fmt.Errorf("phải chọn shop_id khi xem sản phẩm ở trạng thái %s", status))
+	}
+	return s.authorizeShop(ctx, principal, shopID, "", ownershipActionListProducts)
+}
+
+// UnlistProduct ẩn sản phẩm đang bán khỏi trang bán, người bán vẫn chỉnh sửa được
+func (s *service) UnlistProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError {
+	return s.changeProductStatus(ctx, principal, productID, ownershipActionUnlistProduct,
+		func(tx db.Querier, current db.ProductDeleteStatus) (db.ProductDeleteStatus, *assets_services.ServiceError) {
+			if current != db.ProductDeleteStatusActive {
+				return "", assets_services.NewError(409, fmt.Errorf("chỉ ẩn được sản phẩm đang bán, trạng thái hiện tại: %s", current))
+			}
+			return db.ProductDeleteStatusUnlisted, nil
+		})
+}
+
+// RelistProduct hiện lại sản phẩm đã ẩn
+func (s *service) RelistProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError {
+	return s.changeProductStatus(ctx, principal, productID, ownershipActionRelistProduct,
+		func(tx db.Querier, current db.ProductDeleteStatus) (db.ProductDeleteStatus, *assets_services.ServiceError) {
+			if current != db.ProductDeleteStatusUnlisted {
+				return "", assets_services.NewError(409, fmt.Errorf("sản phẩm không ở trạng thái ẩn, trạng thái hiện tại: %s", current))
+			}
+			return db.ProductDeleteStatusActive, nil
+		})
+}
+
+// SoftDeleteProduct xóa mềm sản phẩm, trạng thái trước khi xóa được lưu lại để khôi phục
+func (s *service) SoftDeleteProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError {
+	return s.changeProductStatus(ctx, principal, productID, ownershipActionDeleteProduct,
+		func(tx db.Querier, current db.ProductDeleteStatus) (db.ProductDeleteStatus, *assets_services.ServiceError) {
+			if current == db.ProductDeleteStatusDeleted {
+				return "", assets_services.NewError(409, fmt.Errorf("sản phẩm đã bị xóa"))
+			}
+			return db.ProductDeleteStatusDeleted, nil
+		})
+}
+
+// RestoreProduct khôi phục sản phẩm đã xóa về trạng thái ngay trước khi xóa.
+// Sản phẩm bị xóa trước khi có lịch sử trạng thái được gửi duyệt lại.
+func (s *service) RestoreProduct(ctx context.Context, principal services.Principal, productID string) *assets_services.ServiceError {
+	return s.changeProductStatus(ctx, principal, productID, ownershipActionRestoreProduct,
+		func(tx db.Querier, current db.ProductDeleteStatus) (db.ProductDeleteStatus, *assets_services.ServiceError) {
+			if current != db.ProductDeleteStatusDeleted {
+				return "", assets_services.NewError(409, fmt.Errorf("sản phẩm chưa bị xóa"))
+			}
+			previous, err := tx.GetStatusBeforeDelete(ctx, productID)
+			if err != nil && err != sql.ErrNoRows {
+				return "", assets_services.NewError(400, fmt.Errorf("lỗi khi lấy lịch sử trạng thái sản phẩm: %w", err))
+			}
+			switch status := db.ProductDeleteStatus(previous); status {
+			case db.ProductDeleteStatusActive, db.ProductDeleteStatusUnlisted, db.ProductDeleteStatusRejected, db.ProductDeleteStatusPending:
+				return status, nil
+			default:
+				return db.ProductDeleteStatusPending, nil
+			}
+		})
+}
+
+// changeProductStatus kiểm tra quyền shop, tính trạng thái mới từ trạng thái hiện tại rồi ghi trạng thái cùng lịch sử trong một transaction.
+// Trạng thái hiện tại được đọc lại bằng SELECT ... FOR UPDATE trong transaction để hai thao tác đồng thời không ghi đè nhau.
+func (s *service) changeProductStatus(
+	ctx context.Context,
+	principal services.Principal, productID, action string,
+	next func(tx db.Querier, current db.ProductDeleteStatus) (db.ProductDeleteStatus, *assets_services.ServiceError),
+) *assets_services.ServiceError {
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return assets_services.NewError(404, fmt.Errorf("sản phẩm không tồn tại"))
+		}
+		return assets_services.NewError(400, fmt.Errorf("lỗi khi lấy thông tin sản phẩm: %w", err))
+	}
+	if err := s.authorizeShop(ctx, principal, product.ShopID, productID, action); err != nil {
+		return err
+	}
+
+	txErr := s.repository.ExecTS(ctx, func(tx db.Querier) error {
+		locked, err := tx.GetProductStatusForUpdate(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("lỗi khi lấy trạng thái sản phẩm: %w", err)
+		}
+		current := locked.ProductDeleteStatus
+		status, serr := next(tx, current)
+		if serr != nil {
+			return serr
+		}
+		err = tx.UpdateProduct(ctx, db.UpdateProductParams{
+			ID:           productID,
+			DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: status, Valid: true},
+			UpdateBy:     sql.NullString{String: principal.UserName, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("lỗi khi cập nhật trạng thái sản phẩm: %w", err)
+		}
+		if err := recordProductStatus(ctx, tx, productID, current, status, principal.UserName); err != nil {
+			return err
+		}
+		// khôi phục về chờ duyệt thì phải có bản ghi gửi duyệt để admin thấy trong hàng đợi
+		if status == db.ProductDeleteStatusPending {
+			return createSubmittedModeration(ctx, tx, productID, principal.UserName, nil)
+		}
+		return nil
+	})
+	if txErr != nil {
+		var serr *assets_services.ServiceError
+		if errors.As(txErr, &serr) {
+			return serr
+		}
+		return assets_services.NewError(400, txErr)
+	}
+
+	s.refreshProductSearch(ctx, productID)
+	s.refreshProductSuggestion(ctx, productID)
+	s.invalidateProductDetail(ctx, []string{productID}, product.Key)
+	return nil
+}
+
+// recordProductStatus ghi lịch sử chuyển trạng thái sản phẩm, dùng bên trong transaction
+func recordProductStatus(ctx context.Context, tx db.Querier, productID string, from, to db.ProductDeleteStatus, actor string) error {
+	err := tx.CreateProductStatusHistory(ctx, db.CreateProductStatusHistoryParams{
+		ID:         uuid.New().String(),
+		ProductID:  productID,
+		FromStatus: string(from),
+		ToStatus:   string(to),
+		Actor:      actor,
+	})
+	if err != nil {
+		return fmt.Errorf("lỗi khi ghi lịch sử trạng thái sản phẩm: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// statusHistoryTx giữ trạng thái và lịch sử trạng thái của một sản phẩm trong bộ nhớ
+type statusHistoryTx struct {
+	db.Querier
+	status  db.ProductDeleteStatus
+	history []db.CreateProductStatusHistoryParams
+}
+
+func (f *statusHistoryTx) UpdateProduct(ctx context.Context, arg db.UpdateProductParams) error {
+	f.status = arg.DeleteStatus.ProductDeleteStatus
+	return nil
+}
+
+func (f *statusHistoryTx) CreateProductStatusHistory(ctx context.Context, arg db.CreateProductStatusHistoryParams) error {
+	f.history = append(f.history, arg)
+	return nil
+}
+
+func (f *statusHistoryTx) CreateProductModeration(ctx context.Context, arg db.CreateProductModerationParams) error {
+	return nil
+}
+
+// GetStatusBeforeModeration làm giống câu SQL: lần gần nhất rời trạng thái hiển thị để vào chờ duyệt/bị từ chối
+func (f *statusHistoryTx) GetStatusBeforeModeration(ctx context.Context, productID string) (string, error) {
+	moderation := map[string]bool{"Pending": true, "Rejected": true}
+	for i := len(f.history) - 1; i >= 0; i-- {
+		h := f.history[i]
+		if moderation[h.ToStatus] && !moderation[h.FromStatus] {
+			return h.FromStatus, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+// moderate mô phỏng moderateProduct bên trong transaction
+func (f *statusHistoryTx) moderate(t *testing.T, approve bool) {
+	next, serr := moderatedStatus(context.Background(), f, "p-1", f.status, approve)
+	require.Nil(t, serr)
+	require.NoError(t, recordProductStatus(context.Background(), f, "p-1", f.status, next, "admin"))
+	f.status = next
+}
+
+func TestModeratedStatusKeepsUnlisted(t *testing.T) {
+	ctx := context.Background()
+	tx := &statusHistoryTx{status: db.ProductDeleteStatusUnlisted}
+
+	// shop sửa sản phẩm đang ẩn -> chờ duyệt -> duyệt thì vẫn ẩn
+	require.NoError(t, submitForModeration(ctx, tx, "p-1", "seller", tx.status, []string{"name"}))
+	require.Equal(t, db.ProductDeleteStatusPending, tx.status)
+	tx.moderate(t, true)
+	require.Equal(t, db.ProductDeleteStatusUnlisted, tx.status)
+
+	// bị từ chối rồi sửa lại vẫn nhớ trạng thái ẩn ban đầu
+	require.NoError(t, submitForModeration(ctx, tx, "p-1", "seller", tx.status, []string{"price"}))
+	tx.moderate(t, false)
+	require.NoError(t, submitForModeration(ctx, tx, "p-1", "seller", tx.status, []string{"price"}))
+	tx.moderate(t, true)
+	require.Equal(t, db.ProductDeleteStatusUnlisted, tx.status)
+}
+
+func TestModeratedStatusActive(t *testing.T) {
+	ctx := context.Background()
+	// sản phẩm mới tạo chưa có lịch sử trạng thái
+	tx := &statusHistoryTx{status: db.ProductDeleteStatusPending}
+	tx.moderate(t, true)
+	require.Equal(t, db.ProductDeleteStatusActive, tx.status)
+
+	// admin từ chối sản phẩm đang bán, shop sửa lại thì duyệt xong được bán lại
+	tx.moderate(t, false)
+	require.NoError(t, submitForModeration(ctx, tx, "p-1", "seller", tx.status, nil))
+	tx.moderate(t, true)
+	require.Equal(t, db.ProductDeleteStatusActive, tx.status)
+}
+
+func TestModeratedStatusInvalid(t *testing.T) {
+	ctx := context.Background()
+	tx := &statusHistoryTx{}
+	_, serr := moderatedStatus(ctx, tx, "p-1", db.ProductDeleteStatusUnlisted, true)
+	require.NotNil(t, serr)
+	_, serr = moderatedStatus(ctx, tx, "p-1", db.ProductDeleteStatusDeleted, false)
+	require.NotNil(t, serr)
+	_, serr = moderatedStatus(ctx, tx, "p-1", db.ProductDeleteStatusRejected, false)
+	require.NotNil(t, serr)
+}
+
+func (f *statusHistoryTx) GetProductStatusForUpdate(ctx context.Context, id string) (db.NullProductDeleteStatus, error) {
+	return db.NullProductDeleteStatus{ProductDeleteStatus: f.status, Valid: true}, nil
+}
+
+// staleProductStore GetProduct trả về trạng thái cũ, trạng thái thật nằm trong transaction
+type staleProductStore struct {
+	db_mysql.Store
+	tx *statusHistoryTx
+}
+
+func (f *staleProductStore) GetProduct(ctx context.Context, id string) (db.GetProductRow, error) {
+	return db.GetProductRow{ID: id, DeleteStatus: db.NullProductDeleteStatus{ProductDeleteStatus: db.ProductDeleteStatusActive, Valid: true}}, nil
+}
+
+func (f *staleProductStore) ExecTS(ctx context.Context, fn func(tx db.Querier) error) error {
+	return fn(f.tx)
+}
+
+func TestChangeProductStatusUsesLockedStatus(t *testing.T) {
+	// request khác vừa ẩn sản phẩm: lần ẩn thứ hai phải thấy trạng thái mới trong transaction
+	tx := &statusHistoryTx{status: db.ProductDeleteStatusUnlisted}
+	s := &service{repository: &staleProductStore{tx: tx}}
+	serr := s.UnlistProduct(context.Background(), services.Principal{UserName: "admin", Role: "ROLE_ADMIN"}, "p-1")
+	require.NotNil(t, serr)
+	require.Equal(t, 409, serr.Code)
+	require.Empty(t, tx.history)
+}
+
+func TestAuthorizeListStatus(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeOwnershipStore{sellerShops: map[string][]string{"u-1": {"shop-a"}}}
+	s := &service{repository: store}
+	guest := services.Principal{}
+	buyer := services.Principal{UserID: "u-2", UserName: "buyer", Role: "ROLE_USER"}
+	seller := services.Principal{UserID: "u-1", UserName: "seller", Role: "ROLE_SELLER"}
+	admin := services.Principal{UserName: "admin", Role: "ROLE_ADMIN"}
+
+	tests := []struct {
+		principal      services.Principal
+		status, shopID string
+		code           int
+	}{
+		{guest, "", "", 0},
+		{guest, "Active", "shop-a", 0},
+		{guest, "Pending", "", 401},
+		{guest, "Unlisted", "shop-a", 401},
+		{buyer, "Rejected", "shop-a", 403},
+		{admin, "Deleted", "", 0},
+		{seller, "Unlisted", "shop-a", 0},
+		{seller, "Pending", "", 400},
+		{seller, "Pending", "shop-b", 403},
+	}
+	for _, tt := range tests {
+		serr := s.authorizeListStatus(ctx, tt.principal, tt.status, tt.shopID)
+		if tt.code == 0 {
+			require.Nil(t, serr, "%s %s %s", tt.principal.Role, tt.status, tt.shopID)
+			continue
+		}
+		require.NotNil(t, serr, "%s %s %s", tt.principal.Role, tt.status, tt.shopID)
+		require.Equal(t, tt.code, serr.Code)
+	}
+	// người bán xem shop khác bị ghi nhật ký
+	require.Len(t, store.audits, 1)
+	require.Equal(t, "shop-b", store.audits[0].ShopID)
+	require.Equal(t, ownershipActionListProducts, store.audits[0].Action)
+}
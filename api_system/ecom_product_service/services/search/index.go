@@ -0,0 +1,345 @@
+package services_search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+)
+
+// Field các trường của tài liệu tìm kiếm, trọng số giảm dần theo thứ tự khai báo
+type Field int
+
+const (
+	FieldName Field = iota
+	FieldBrand
+	FieldCategory
+	FieldDescription
+	fieldCount
+)
+
+// trọng số trường: tên > thương hiệu > danh mục > mô tả
+var fieldWeights = [fieldCount]float64{8, 4, 2, 1}
+
+// mức độ khớp của từ trong câu truy vấn với từ trong chỉ mục
+const (
+	exactMatchScore  = 1.0
+	prefixMatchScore = 0.7
+	typoMatchScore   = 0.5
+)
+
+// Document tài liệu tìm kiếm của một sản phẩm, các trường chưa cần bỏ dấu
+type Document struct {
+	ID     string
+	Fields [fieldCount]string
+}
+
+type Result struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Index chỉ mục ngược trong bộ nhớ: từ (đã bỏ dấu) -> sản phẩm -> trọng số.
+// Để không phải quét toàn bộ từ điển mỗi lần tìm, từ điển được giữ thêm ở dạng sắp xếp (khớp tiền tố)
+// và chỉ mục xóa ký tự kiểu SymSpell (khớp sai chính tả).
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]float64
+	docTerms map[string][]string
+	// biến thể xóa 1-2 ký tự -> các từ sinh ra biến thể đó
+	deletes map[string]map[string]struct{}
+	// từ điển đã sắp xếp, dựng lại khi tìm kiếm nếu từ điển đã thay đổi
+	sortedTerms []string
+	termsDirty  bool
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]float64),
+		docTerms: make(map[string][]string),
+		deletes:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Tokenize bỏ dấu và tách chuỗi thành các từ
+func Tokenize(text string) []string {
+	return strings.Fields(assets_services.FoldVietnamese(text))
+}
+
+// Upsert thêm hoặc thay thế tài liệu của sản phẩm, tài liệu không có từ nào thì sản phẩm bị gỡ khỏi chỉ mục
+func (ix *Index) Upsert(doc Document) {
+	weights := make(map[string]float64)
+	for field, text := range doc.Fields {
+		for _, term := range Tokenize(text) {
+			weights[term] += fieldWeights[field]
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(doc.ID)
+	if len(weights) == 0 {
+		return
+	}
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]float64)
+			ix.addTermLocked(term)
+		}
+		ix.postings[term][doc.ID] = weight
+		terms = append(terms, term)
+	}
+	ix.docTerms[doc.ID] = terms
+}
+
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(id)
+}
+
+func (ix *Index) removeLocked(id string) {
+	for _, term := range ix.docTerms[id] {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.removeTermLocked(term)
+		}
+	}
+	delete(ix.docTerms, id)
+}
+
+// addTermLocked ghi từ mới vào chỉ mục xóa ký tự và đánh dấu cần sắp xếp lại từ điển
+func (ix *Index) addTermLocked(term string) {
+	for variant := range deleteVariants(term, termDeleteDepth(term)) {
+		if ix.deletes[variant] == nil {
+			ix.deletes[variant] = make(map[string]struct{})
+		}
+		ix.deletes[variant][term] = struct{}{}
+	}
+	ix.termsDirty = true
+}
+
+func (ix *Index) removeTermLocked(term string) {
+	for variant := range deleteVariants(term, termDeleteDepth(term)) {
+		delete(ix.deletes[variant], term)
+		if len(ix.deletes[variant]) == 0 {
+			delete(ix.deletes, variant)
+		}
+	}
+	ix.termsDirty = true
+}
+
+// ensureSortedTerms dựng lại từ điển sắp xếp nếu đã có từ được thêm/bớt kể từ lần tìm trước
+func (ix *Index) ensureSortedTerms() {
+	ix.mu.RLock()
+	dirty := ix.termsDirty
+	ix.mu.RUnlock()
+	if !dirty {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.termsDirty {
+		return
+	}
+	terms := make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	ix.sortedTerms = terms
+	ix.termsDirty = false
+}
+
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docTerms)
+}
+
+// Search trả về tối đa limit sản phẩm (limit <= 0 là không giới hạn) khớp với tất cả các từ trong câu truy vấn,
+// điểm cao nhất trước. Mỗi từ có thể khớp chính xác, khớp tiền tố hoặc sai chính tả (khoảng cách sửa 1-2 ký tự).
+func (ix *Index) Search(query string, limit int) []Result {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	ix.ensureSortedTerms()
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	total := float64(len(ix.docTerms))
+	var scores map[string]float64
+	for _, token := range tokens {
+		tokenScores := make(map[string]float64)
+		for term := range ix.candidateTermsLocked(token) {
+			docs, ok := ix.postings[term]
+			if !ok {
+				continue
+			}
+			quality := matchQuality(token, term)
+			if quality == 0 {
+				continue
+			}
+			idf := math.Log(1 + total/float64(len(docs)))
+			for id, weight := range docs {
+				if score := weight * quality * idf; score > tokenScores[id] {
+					tokenScores[id] = score
+				}
+			}
+		}
+		// sản phẩm phải khớp tất cả các từ
+		if scores == nil {
+			scores = tokenScores
+		} else {
+			for id := range scores {
+				if s, ok := tokenScores[id]; ok {
+					scores[id] += s
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		results = append(results, Result{ID: id, Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].ID < results[j].ID
+		}
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// candidateTermsLocked các từ trong từ điển có thể khớp token: chính nó, các từ có token là tiền tố
+// và các từ có chung biến thể xóa ký tự với token (ứng viên sai chính tả, matchQuality kiểm tra lại khoảng cách)
+func (ix *Index) candidateTermsLocked(token string) map[string]struct{} {
+	candidates := make(map[string]struct{})
+	if _, ok := ix.postings[token]; ok {
+		candidates[token] = struct{}{}
+	}
+	if len(token) >= 2 {
+		for i := sort.SearchStrings(ix.sortedTerms, token); i < len(ix.sortedTerms) && strings.HasPrefix(ix.sortedTerms[i], token); i++ {
+			candidates[ix.sortedTerms[i]] = struct{}{}
+		}
+	}
+	if distance := typoDistance(token); distance > 0 {
+		variants := deleteVariants(token, distance)
+		variants[token] = struct{}{}
+		for variant := range variants {
+			// từ trong từ điển ngắn hơn token (token thừa ký tự)
+			if _, ok := ix.postings[variant]; ok {
+				candidates[variant] = struct{}{}
+			}
+			for term := range ix.deletes[variant] {
+				candidates[term] = struct{}{}
+			}
+		}
+	}
+	return candidates
+}
+
+func matchQuality(token, term string) float64 {
+	if token == term {
+		return exactMatchScore
+	}
+	if len(token) >= 2 && strings.HasPrefix(term, token) {
+		return prefixMatchScore
+	}
+	if maxDistance := typoDistance(token); maxDistance > 0 && withinDistance(token, term, maxDistance) {
+		return typoMatchScore
+	}
+	return 0
+}
+
+// typoDistance số lỗi chính tả chấp nhận theo độ dài từ truy vấn
+func typoDistance(token string) int {
+	switch {
+	case len(token) >= 8:
+		return 2
+	case len(token) >= 4:
+		return 1
+	}
+	return 0
+}
+
+// termDeleteDepth số ký tự tối đa bị xóa khi đánh chỉ mục một từ, đủ để gặp các token có thể khớp nó:
+// token >= 8 ký tự (2 lỗi) chỉ khớp từ >= 6 ký tự, token >= 4 ký tự (1 lỗi) chỉ khớp từ >= 3 ký tự
+func termDeleteDepth(term string) int {
+	switch {
+	case len(term) >= 6:
+		return 2
+	case len(term) >= 3:
+		return 1
+	}
+	return 0
+}
+
+// deleteVariants các chuỗi có được khi xóa 1 tới depth ký tự của s (không gồm chính s).
+// Hai từ cách nhau tối đa d lần sửa luôn có chung ít nhất một biến thể xóa tối đa d ký tự (hoặc là biến thể của nhau)
+func deleteVariants(s string, depth int) map[string]struct{} {
+	variants := make(map[string]struct{})
+	current := []string{s}
+	for step := 0; step < depth; step++ {
+		var next []string
+		for _, word := range current {
+			for i := 0; i < len(word); i++ {
+				variant := word[:i] + word[i+1:]
+				if _, ok := variants[variant]; ok {
+					continue
+				}
+				variants[variant] = struct{}{}
+				next = append(next, variant)
+			}
+		}
+		current = next
+	}
+	return variants
+}
+
+// withinDistance kiểm tra khoảng cách Damerau-Levenshtein (có tính đảo 2 ký tự liền kề) không vượt quá max
+func withinDistance(a, b string, max int) bool {
+	if d := len(a) - len(b); d > max || -d > max {
+		return false
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > max {
+			return false
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)] <= max
+}
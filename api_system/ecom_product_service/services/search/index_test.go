@@ -0,0 +1,116 @@
+package services_search
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Upsert(Document{ID: "p-ao", Fields: [fieldCount]string{FieldName: "Áo thun nam", FieldBrand: "Coolmate", FieldDescription: "vải cotton"}})
+	ix.Upsert(Document{ID: "p-quan", Fields: [fieldCount]string{FieldName: "Quần jean", FieldBrand: "Levis", FieldCategory: "thời trang nam"}})
+	ix.Upsert(Document{ID: "p-dien-thoai", Fields: [fieldCount]string{FieldName: "Điện thoại Samsung Galaxy", FieldBrand: "Samsung"}})
+	return ix
+}
+
+func ids(results []Result) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestIndexSearchFoldingAndWeights(t *testing.T) {
+	ix := newTestIndex()
+	require.Equal(t, []string{"p-ao"}, ids(ix.Search("ao thun", 0)))
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("ĐIỆN THOẠI", 0)))
+	// "nam" nằm ở tên của p-ao nhưng chỉ ở danh mục của p-quan
+	require.Equal(t, []string{"p-ao", "p-quan"}, ids(ix.Search("nam", 0)))
+	// phải khớp tất cả các từ
+	require.Empty(t, ix.Search("ao samsung", 0))
+	require.Len(t, ix.Search("nam", 1), 1)
+}
+
+func TestIndexSearchPrefixAndTypo(t *testing.T) {
+	ix := newTestIndex()
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("gala", 0)))
+	// 1 lỗi với từ >= 4 ký tự: thay, thiếu, thừa, đảo ký tự
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("samsong", 0)))
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("samung", 0)))
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("sammsung", 0)))
+	require.Equal(t, []string{"p-dien-thoai"}, ids(ix.Search("smasung", 0)))
+	// 2 lỗi chỉ chấp nhận với từ >= 8 ký tự
+	require.Equal(t, []string{"p-ao"}, ids(ix.Search("coulmete", 0)))
+	require.Empty(t, ix.Search("samsaag", 0))
+	// từ ngắn không chịu lỗi chính tả
+	require.Empty(t, ix.Search("jan", 0))
+}
+
+func TestIndexUpsertAndRemove(t *testing.T) {
+	ix := newTestIndex()
+	require.Equal(t, 3, ix.Len())
+
+	// đổi tên thì từ cũ không còn khớp
+	ix.Upsert(Document{ID: "p-ao", Fields: [fieldCount]string{FieldName: "Áo polo"}})
+	require.Empty(t, ix.Search("thun", 0))
+	require.Equal(t, []string{"p-ao"}, ids(ix.Search("polo", 0)))
+
+	// tài liệu rỗng (sản phẩm đã xóa) bị gỡ khỏi chỉ mục
+	ix.Upsert(Document{ID: "p-ao"})
+	require.Equal(t, 2, ix.Len())
+	require.Empty(t, ix.Search("polo", 0))
+
+	ix.Remove("p-quan")
+	require.Equal(t, 1, ix.Len())
+	require.Empty(t, ix.Search("levis", 0))
+	require.Empty(t, ix.deletes["evis"], "biến thể xóa ký tự của từ đã gỡ phải được dọn")
+}
+
+// Ứng viên từ chỉ mục phải cho cùng kết quả với việc so từng từ trong từ điển
+func TestCandidateTermsMatchFullScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	letters := "abcdeghiklmnostu"
+	word := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = letters[rng.Intn(len(letters))]
+		}
+		return string(b)
+	}
+	ix := NewIndex()
+	var vocabulary []string
+	for i := 0; i < 300; i++ {
+		w := word(2 + rng.Intn(10))
+		vocabulary = append(vocabulary, w)
+		ix.Upsert(Document{ID: fmt.Sprint(i), Fields: [fieldCount]string{FieldName: w}})
+	}
+	ix.ensureSortedTerms()
+
+	for i := 0; i < 500; i++ {
+		// token là biến thể của một từ có sẵn để có nhiều trường hợp khớp
+		token := []byte(vocabulary[rng.Intn(len(vocabulary))])
+		for edits := rng.Intn(3); edits > 0 && len(token) > 1; edits-- {
+			pos := rng.Intn(len(token))
+			switch rng.Intn(3) {
+			case 0:
+				token[pos] = letters[rng.Intn(len(letters))]
+			case 1:
+				token = append(token[:pos], token[pos+1:]...)
+			default:
+				token = append(token[:pos], append([]byte{letters[rng.Intn(len(letters))]}, token[pos:]...)...)
+			}
+		}
+		candidates := ix.candidateTermsLocked(string(token))
+		for term := range ix.postings {
+			if matchQuality(string(token), term) == 0 {
+				continue
+			}
+			_, ok := candidates[term]
+			require.True(t, ok, "token %q phải tìm thấy từ %q", token, term)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package services
 
 import (
 	config_assets "github.com/TranVinhHien/ecom_product_service/assets/config"
+	assets_firebase "github.com/TranVinhHien/ecom_product_service/assets/fire-base"
 	"github.com/TranVinhHien/ecom_product_service/assets/token"
 	db "github.com/TranVinhHien/ecom_product_service/db/mysql"
 	"github.com/TranVinhHien/ecom_product_service/server"
+	services_search "github.com/TranVinhHien/ecom_product_service/services/search"
+	"golang.org/x/sync/singleflight"
 )
 
 type service struct {
@@ -13,10 +16,13 @@ type service struct {
 	jwt        token.Maker
 	env        config_assets.ReadENV
 	apiServer  server.ApiServer
-	// firebase   *assets_firebase.FirebaseMessaging
+	firebase   *assets_firebase.FirebaseMessaging // nil nếu không cấu hình FIREBASE_CREDENTIALS
+	search     *services_search.Index             // chỉ mục tìm kiếm sản phẩm trong bộ nhớ
+	// gộp các lần đọc DB trùng nhau khi cache chi tiết sản phẩm bị miss
+	detailFlight singleflight.Group
 	// jobs       *assets_jobs.JobScheduler
 }
 
-func NewService(repo db.Store, jwt token.Maker, env config_assets.ReadENV, redis ServicesRedis, apiServer server.ApiServer) ServiceUseCase {
-	return &service{repository: repo, jwt: jwt, env: env, redis: redis, apiServer: apiServer}
+func NewService(repo db.Store, jwt token.Maker, env config_assets.ReadENV, redis ServicesRedis, apiServer server.ApiServer, firebase *assets_firebase.FirebaseMessaging) ServiceUseCase {
+	return &service{repository: repo, jwt: jwt, env: env, redis: redis, apiServer: apiServer, firebase: firebase, search: services_search.NewIndex()}
 }
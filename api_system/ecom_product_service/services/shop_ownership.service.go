@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+// các thao tác được ghi vào nhật ký khi vi phạm quyền sở hữu shop
+const (
+	ownershipActionCreateProduct     = "CREATE_PRODUCT"
+	ownershipActionUpdateProduct     = "UPDATE_PRODUCT"
+	ownershipActionModerationHistory = "VIEW_MODERATION_HISTORY"
+	ownershipActionUnlistProduct     = "UNLIST_PRODUCT"
+	ownershipActionRelistProduct     = "RELIST_PRODUCT"
+	ownershipActionDeleteProduct     = "DELETE_PRODUCT"
+	ownershipActionRestoreProduct    = "RESTORE_PRODUCT"
+	ownershipActionListProducts      = "LIST_PRODUCTS"
+)
+
+func (s *service) AssignSellerShop(ctx context.Context, userName, userID, shopID string) *assets_services.ServiceError {
+	err := s.repository.CreateSellerShop(ctx, db.CreateSellerShopParams{
+		UserID:   userID,
+		ShopID:   shopID,
+		CreateBy: sql.NullString{String: userName, Valid: userName != ""},
+	})
+	if err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể gán shop cho người bán. Lỗi: %v", err))
+	}
+	return nil
+}
+
+func (s *service) RemoveSellerShop(ctx context.Context, userID, shopID string) *assets_services.ServiceError {
+	err := s.repository.DeleteSellerShop(ctx, db.DeleteSellerShopParams{UserID: userID, ShopID: shopID})
+	if err != nil {
+		return assets_services.NewError(400, fmt.Errorf("không thể gỡ shop khỏi người bán. Lỗi: %v", err))
+	}
+	return nil
+}
+
+func (s *service) ListSellerShops(ctx context.Context, userID string) (map[string]interface{}, *assets_services.ServiceError) {
+	shopIDs, err := s.repository.ListShopIDsBySeller(ctx, userID)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy danh sách shop của người bán. Lỗi: %v", err))
+	}
+	if shopIDs == nil {
+		shopIDs = []string{}
+	}
+	return map[string]interface{}{"data": shopIDs}, nil
+}
+
+func (s *service) ListOwnershipAudit(ctx context.Context, query services.QueryFilter) (map[string]interface{}, *assets_services.ServiceError) {
+	audits, err := s.repository.ListProductOwnershipAudit(ctx, db.ListProductOwnershipAuditParams{
+		Limit:  int32(query.PageSize),
+		Offset: int32((query.Page - 1) * query.PageSize),
+	})
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy nhật ký vi phạm quyền sở hữu. Lỗi: %v", err))
+	}
+	totalElements, err := s.repository.CountProductOwnershipAudit(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể đếm nhật ký vi phạm quyền sở hữu. Lỗi: %v", err))
+	}
+	result := assets_services.NormalizeListSQLNulls(audits, "data")
+	result["currentPage"] = query.Page
+	result["totalPages"] = int64(math.Ceil(float64(totalElements) / float64(query.PageSize)))
+	result["totalElements"] = totalElements
+	result["limit"] = query.PageSize
+	return result, nil
+}
+
+// sellerShopIDs trả về danh sách shop của người dùng: ưu tiên claim shopId trong token,
+// nếu token không có thì tra bảng seller_shop
+func (s *service) sellerShopIDs(ctx context.Context, principal services.Principal) ([]string, error) {
+	if principal.ShopID != "" {
+		return []string{principal.ShopID}, nil
+	}
+	if principal.UserID == "" {
+		return nil, nil
+	}
+	return s.repository.ListShopIDsBySeller(ctx, principal.UserID)
+}
+
+// authorizeShop kiểm tra người dùng có quyền thao tác trên shop hay không.
+// Admin luôn được phép; các trường hợp bị từ chối sẽ được ghi vào product_ownership_audit.
+func (s *service) authorizeShop(ctx context.Context, principal services.Principal, shopID, productID, action string) *assets_services.ServiceError {
+	if principal.IsAdmin() {
+		return nil
+	}
+	shopIDs, err := s.sellerShopIDs(ctx, principal)
+	if err != nil {
+		return assets_services.NewError(500, fmt.Errorf("không thể xác định shop của người dùng. Lỗi: %v", err))
+	}
+	for _, id := range shopIDs {
+		if id == shopID {
+			return nil
+		}
+	}
+	s.auditOwnershipViolation(ctx, principal, shopID, productID, action)
+	return assets_services.NewError(403, fmt.Errorf("bạn không có quyền thao tác trên sản phẩm của shop này"))
+}
+
+func (s *service) auditOwnershipViolation(ctx context.Context, principal services.Principal, shopID, productID, action string) {
+	log.Printf("[Ownership] user %s (%s) bị từ chối %s trên shop %s, sản phẩm %s", principal.UserName, principal.UserID, action, shopID, productID)
+	err := s.repository.CreateProductOwnershipAudit(ctx, db.CreateProductOwnershipAuditParams{
+		ID:        uuid.New().String(),
+		UserID:    principal.UserID,
+		UserName:  principal.UserName,
+		ShopID:    shopID,
+		ProductID: sql.NullString{String: productID, Valid: productID != ""},
+		Action:    action,
+	})
+	if err != nil {
+		log.Printf("[Ownership] không thể ghi nhật ký vi phạm: %v", err)
+	}
+}
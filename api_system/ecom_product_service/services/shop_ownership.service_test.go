@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	db_mysql "github.com/TranVinhHien/ecom_product_service/db/mysql"
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOwnershipStore giữ bảng seller_shop và nhật ký vi phạm trong bộ nhớ
+type fakeOwnershipStore struct {
+	db_mysql.Store
+	sellerShops map[string][]string
+	lookups     int
+	audits      []db.CreateProductOwnershipAuditParams
+}
+
+func (f *fakeOwnershipStore) ListShopIDsBySeller(ctx context.Context, userID string) ([]string, error) {
+	f.lookups++
+	return f.sellerShops[userID], nil
+}
+
+func (f *fakeOwnershipStore) CreateProductOwnershipAudit(ctx context.Context, arg db.CreateProductOwnershipAuditParams) error {
+	f.audits = append(f.audits, arg)
+	return nil
+}
+
+func TestSellerShopIDs(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeOwnershipStore{sellerShops: map[string][]string{"u-1": {"shop-a", "shop-b"}}}
+	s := &service{repository: store}
+
+	// claim shopId trong token được ưu tiên, không cần tra bảng
+	ids, err := s.sellerShopIDs(ctx, services.Principal{UserID: "u-1", ShopID: "shop-jwt"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shop-jwt"}, ids)
+	require.Zero(t, store.lookups)
+
+	ids, err = s.sellerShopIDs(ctx, services.Principal{UserID: "u-1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shop-a", "shop-b"}, ids)
+	require.Equal(t, 1, store.lookups)
+
+	// token không có user id thì không có shop nào
+	ids, err = s.sellerShopIDs(ctx, services.Principal{UserName: "guest"})
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
+func TestAuthorizeShop(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeOwnershipStore{sellerShops: map[string][]string{"u-1": {"shop-a", "shop-b"}}}
+	s := &service{repository: store}
+
+	// claim shopId khớp
+	serr := s.authorizeShop(ctx, services.Principal{UserID: "u-1", ShopID: "shop-jwt"}, "shop-jwt", "p-1", ownershipActionUpdateProduct)
+	require.Nil(t, serr)
+
+	// không có claim thì tra bảng seller_shop
+	serr = s.authorizeShop(ctx, services.Principal{UserID: "u-1"}, "shop-b", "p-1", ownershipActionUpdateProduct)
+	require.Nil(t, serr)
+
+	// admin không cần thuộc shop nào
+	serr = s.authorizeShop(ctx, services.Principal{UserName: "admin", Role: "ROLE_ADMIN"}, "shop-x", "p-1", ownershipActionDeleteProduct)
+	require.Nil(t, serr)
+	require.Empty(t, store.audits)
+}
+
+func TestAuthorizeShopMismatchAudited(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeOwnershipStore{sellerShops: map[string][]string{"u-1": {"shop-a"}}}
+	s := &service{repository: store}
+
+	// claim trong token là shop khác thì không được tra thêm bảng seller_shop
+	seller := services.Principal{UserID: "u-1", UserName: "seller", Role: "ROLE_SELLER", ShopID: "shop-jwt"}
+	serr := s.authorizeShop(ctx, seller, "shop-a", "p-1", ownershipActionUpdateProduct)
+	require.NotNil(t, serr)
+	require.Equal(t, 403, serr.Code)
+
+	serr = s.authorizeShop(ctx, services.Principal{UserID: "u-1", UserName: "seller"}, "shop-x", "", ownershipActionCreateProduct)
+	require.NotNil(t, serr)
+	require.Equal(t, 403, serr.Code)
+
+	require.Len(t, store.audits, 2)
+	audit := store.audits[0]
+	require.NotEmpty(t, audit.ID)
+	require.Equal(t, "u-1", audit.UserID)
+	require.Equal(t, "seller", audit.UserName)
+	require.Equal(t, "shop-a", audit.ShopID)
+	require.Equal(t, "p-1", audit.ProductID.String)
+	require.True(t, audit.ProductID.Valid)
+	require.Equal(t, ownershipActionUpdateProduct, audit.Action)
+	// tạo sản phẩm chưa có product id
+	require.False(t, store.audits[1].ProductID.Valid)
+	require.Equal(t, ownershipActionCreateProduct, store.audits[1].Action)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	// khoảng thời gian tính "giá thấp nhất" hiển thị cùng giá hiện tại
+	lowestPriceWindow = 30 * 24 * time.Hour
+
+	defaultPriceHistoryLimit = 100
+	maxPriceHistoryLimit     = 500
+
+	// mặc định xem các lần tăng giá trong 7 ngày trước khi khuyến mãi bắt đầu
+	defaultPriceRaiseWindow = 7 * 24 * time.Hour
+	defaultPriceRaiseLimit  = 200
+	maxPriceRaiseLimit      = 1000
+)
+
+// recordSKUPrice ghi lịch sử giá trong cùng transaction với thao tác đổi giá.
+// oldPrice không hợp lệ nghĩa là giá ban đầu khi tạo SKU.
+func recordSKUPrice(ctx context.Context, tx db.Querier, skuID, productID string, oldPrice sql.NullFloat64, newPrice float64, actor string) error {
+	err := tx.CreateSKUPriceHistory(ctx, db.CreateSKUPriceHistoryParams{
+		ID:        uuid.New().String(),
+		SkuID:     skuID,
+		ProductID: productID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		Actor:     actor,
+	})
+	if err != nil {
+		return fmt.Errorf("không thể ghi lịch sử giá SKU %s: %w", skuID, err)
+	}
+	return nil
+}
+
+// fillLowestPrices30Days tính giá thấp nhất 30 ngày của từng SKU (gồm cả giá hiện tại)
+// và trả về giá thấp nhất 30 ngày của cả sản phẩm
+func (s *service) fillLowestPrices30Days(ctx context.Context, productID string, skus []services.SkuResponse) (float64, error) {
+	rows, err := s.repository.ListSKULowestPricesSince(ctx, db.ListSKULowestPricesSinceParams{
+		ProductID: productID,
+		Since:     time.Now().Add(-lowestPriceWindow),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("không thể lấy lịch sử giá sản phẩm. Lỗi: %w", err)
+	}
+	lowest := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		lowest[row.SkuID] = row.LowestPrice
+	}
+	productLowest := 0.0
+	for i := range skus {
+		price := skus[i].Price
+		if p, ok := lowest[skus[i].ID]; ok && p < price {
+			price = p
+		}
+		skus[i].LowestPrice30Days = price
+		if i == 0 || price < productLowest {
+			productLowest = price
+		}
+	}
+	return productLowest, nil
+}
+
+// GetSKUPriceHistory trả về các lần đổi giá của SKU, mới nhất trước
+func (s *service) GetSKUPriceHistory(ctx context.Context, skuID string, limit int) (map[string]interface{}, *assets_services.ServiceError) {
+	if limit <= 0 {
+		limit = defaultPriceHistoryLimit
+	}
+	if limit > maxPriceHistoryLimit {
+		limit = maxPriceHistoryLimit
+	}
+	sku, err := s.repository.GetProductSKU(ctx, skuID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, assets_services.NewError(404, fmt.Errorf("không tìm thấy SKU với ID: %s", skuID))
+		}
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy thông tin SKU. Lỗi: %v", err))
+	}
+	history, err := s.repository.ListSKUPriceHistory(ctx, db.ListSKUPriceHistoryParams{
+		SkuID: skuID,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy lịch sử giá SKU. Lỗi: %v", err))
+	}
+	skus := []services.SkuResponse{{ID: sku.ID, Price: sku.Price}}
+	if _, err := s.fillLowestPrices30Days(ctx, sku.ProductID, skus); err != nil {
+		return nil, assets_services.NewError(400, err)
+	}
+	if history == nil {
+		history = []db.SkuPriceHistory{}
+	}
+
+	return map[string]interface{}{"data": map[string]interface{}{
+		"sku_id":           sku.ID,
+		"product_id":       sku.ProductID,
+		"current_price":    sku.Price,
+		"lowest_price_30d": skus[0].LowestPrice30Days,
+		"history":          assets_services.NormalizeToInterface(history),
+	}}, nil
+}
+
+// PriceRaiseReport liệt kê các SKU bị tăng giá trong khoảng window trước thời điểm khuyến mãi bắt đầu.
+// Khuyến mãi (voucher) nằm ở order service nên admin truyền thời điểm bắt đầu và shop cần kiểm tra.
+func (s *service) PriceRaiseReport(ctx context.Context, params services.PriceRaiseReportParams) (map[string]interface{}, *assets_services.ServiceError) {
+	if params.PromotionStart.IsZero() {
+		return nil, assets_services.NewError(400, fmt.Errorf("phải truyền thời điểm bắt đầu khuyến mãi"))
+	}
+	if params.Window <= 0 {
+		params.Window = defaultPriceRaiseWindow
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultPriceRaiseLimit
+	}
+	if params.Limit > maxPriceRaiseLimit {
+		params.Limit = maxPriceRaiseLimit
+	}
+	rows, err := s.repository.ListSKUPriceRaises(ctx, db.ListSKUPriceRaisesParams{
+		FromDate:           params.PromotionStart.Add(-params.Window),
+		ToDate:             params.PromotionStart,
+		MinIncreasePercent: params.MinIncreasePercent,
+		ShopID:             sql.NullString{String: params.ShopID, Valid: params.ShopID != ""},
+		Limit:              int32(params.Limit),
+	})
+	if err != nil {
+		return nil, assets_services.NewError(400, fmt.Errorf("không thể lấy lịch sử tăng giá. Lỗi: %v", err))
+	}
+
+	items := make([]services.PriceRaiseItem, 0, len(rows))
+	for _, row := range rows {
+		increase := 0.0
+		if row.OldPrice.Float64 > 0 {
+			increase = (row.NewPrice - row.OldPrice.Float64) / row.OldPrice.Float64 * 100
+		}
+		items = append(items, services.PriceRaiseItem{
+			SkuID:           row.SkuID,
+			SkuCode:         row.SkuCode,
+			ProductID:       row.ProductID,
+			ProductName:     row.ProductName,
+			ShopID:          row.ShopID,
+			OldPrice:        row.OldPrice.Float64,
+			NewPrice:        row.NewPrice,
+			CurrentPrice:    row.CurrentPrice,
+			IncreasePercent: math.Round(increase*100) / 100,
+			ChangedBy:       row.Actor,
+			ChangedAt:       row.CreateDate,
+		})
+	}
+	return map[string]interface{}{
+		"data": items,
+		"range": map[string]interface{}{
+			"from": params.PromotionStart.Add(-params.Window),
+			"to":   params.PromotionStart,
+		},
+	}, nil
+}
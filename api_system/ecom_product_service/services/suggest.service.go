@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/TranVinhHien/ecom_product_service/db/sqlc"
+	assets_services "github.com/TranVinhHien/ecom_product_service/services/assets"
+	services "github.com/TranVinhHien/ecom_product_service/services/entity"
+)
+
+const (
+	suggestDefaultLimit = 5
+	suggestMaxLimit     = 20
+	// dựng lại định kỳ để cập nhật total_sold và thay đổi của thương hiệu/danh mục
+	suggestRebuildInterval = time.Hour
+)
+
+// Suggest gợi ý khi người dùng đang gõ: từ khóa phổ biến, sản phẩm (theo total_sold),
+// thương hiệu và danh mục có tên bắt đầu bằng q, không phân biệt dấu
+func (s *service) Suggest(ctx context.Context, q string, limit int) (map[string]interface{}, *assets_services.ServiceError) {
+	prefix := assets_services.FoldVietnamese(q)
+	if prefix == "" {
+		return nil, assets_services.NewError(400, fmt.Errorf("phải nhập từ khóa gợi ý"))
+	}
+	if limit <= 0 {
+		limit = suggestDefaultLimit
+	}
+	if limit > suggestMaxLimit {
+		limit = suggestMaxLimit
+	}
+
+	queries, err := s.redis.SuggestQueries(ctx, prefix, limit)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy gợi ý từ khóa. Lỗi: %v", err))
+	}
+	result := map[string]interface{}{"queries": queries}
+	for key, entryType := range map[string]string{
+		"products":   services.SuggestTypeProduct,
+		"brands":     services.SuggestTypeBrand,
+		"categories": services.SuggestTypeCategory,
+	} {
+		entries, err := s.redis.SearchSuggestions(ctx, entryType, prefix, limit)
+		if err != nil {
+			return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy gợi ý tìm kiếm. Lỗi: %v", err))
+		}
+		result[key] = entries
+	}
+	return map[string]interface{}{"data": result}, nil
+}
+
+func (s *service) TopSearchQueries(ctx context.Context, limit int) (map[string]interface{}, *assets_services.ServiceError) {
+	if limit <= 0 {
+		limit = suggestMaxLimit
+	}
+	queries, err := s.redis.TopSearchQueries(ctx, limit)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy từ khóa phổ biến. Lỗi: %v", err))
+	}
+	return map[string]interface{}{"data": queries}, nil
+}
+
+// RebuildSuggestIndex dựng lại toàn bộ chỉ mục gợi ý từ bảng product, brand và category.
+// Điểm của thương hiệu/danh mục là tổng total_sold của các sản phẩm đang bán thuộc về nó.
+func (s *service) RebuildSuggestIndex(ctx context.Context) (map[string]interface{}, *assets_services.ServiceError) {
+	entries := []services.SuggestEntry{}
+	brandSold := make(map[string]float64)
+	categorySold := make(map[string]float64)
+	afterID := ""
+	for {
+		rows, err := s.repository.ListActiveProductsForSuggest(ctx, db.ListActiveProductsForSuggestParams{
+			AfterID: afterID,
+			Limit:   searchIndexBatchSize,
+		})
+		if err != nil {
+			return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy danh sách sản phẩm. Lỗi: %v", err))
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, p := range rows {
+			entries = append(entries, services.SuggestEntry{
+				Type:   services.SuggestTypeProduct,
+				ID:     p.ID,
+				Text:   p.Name,
+				Key:    p.Key,
+				Folded: assets_services.FoldVietnamese(p.Name),
+				Score:  float64(p.TotalSold),
+			})
+			if p.BrandID.Valid {
+				brandSold[p.BrandID.String] += float64(p.TotalSold)
+			}
+			categorySold[p.CategoryID] += float64(p.TotalSold)
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+
+	brands, err := s.repository.ListBrands(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy danh sách thương hiệu. Lỗi: %v", err))
+	}
+	for _, b := range brands {
+		entries = append(entries, services.SuggestEntry{
+			Type:   services.SuggestTypeBrand,
+			ID:     b.BrandID,
+			Text:   b.Name,
+			Key:    b.Code,
+			Folded: assets_services.FoldVietnamese(b.Name),
+			Score:  brandSold[b.BrandID],
+		})
+	}
+	categories, err := s.repository.ListCategories(ctx)
+	if err != nil {
+		return nil, assets_services.NewError(500, fmt.Errorf("không thể lấy danh sách danh mục. Lỗi: %v", err))
+	}
+	for _, c := range categories {
+		entries = append(entries, services.SuggestEntry{
+			Type:   services.SuggestTypeCategory,
+			ID:     c.CategoryID,
+			Text:   c.Name,
+			Key:    c.Path.String,
+			Folded: assets_services.FoldVietnamese(c.Name),
+			Score:  categorySold[c.CategoryID],
+		})
+	}
+
+	if err := s.redis.ReplaceSuggestions(ctx, entries); err != nil {
+		if errors.Is(err, services.ErrSuggestRebuildRunning) {
+			return nil, assets_services.NewError(409, err)
+		}
+		return nil, assets_services.NewError(500, fmt.Errorf("không thể lưu chỉ mục gợi ý. Lỗi: %v", err))
+	}
+	log.Printf("[Suggest] đã dựng chỉ mục gợi ý với %d mục", len(entries))
+	return map[string]interface{}{"data": map[string]interface{}{"total_entries": len(entries)}}, nil
+}
+
+// refreshProductSuggestion cập nhật gợi ý của một sản phẩm sau khi ghi: chỉ sản phẩm đang bán mới được gợi ý
+func (s *service) refreshProductSuggestion(ctx context.Context, productID string) {
+	// GetProduct không trả total_sold nên đọc thẳng bản ghi product
+	products, err := s.repository.GetProductIDs(ctx, []string{productID})
+	if err != nil || len(products) == 0 {
+		log.Printf("[Suggest] không tìm thấy sản phẩm %s: %v", productID, err)
+		return
+	}
+	product := products[0]
+	if product.DeleteStatus.ProductDeleteStatus != db.ProductDeleteStatusActive {
+		err = s.redis.RemoveSuggestion(ctx, services.SuggestTypeProduct, productID)
+	} else {
+		err = s.redis.UpsertSuggestion(ctx, services.SuggestEntry{
+			Type:   services.SuggestTypeProduct,
+			ID:     product.ID,
+			Text:   product.Name,
+			Key:    product.Key,
+			Folded: assets_services.FoldVietnamese(product.Name),
+			Score:  float64(product.TotalSold),
+		})
+	}
+	if err != nil {
+		log.Printf("[Suggest] không thể cập nhật gợi ý cho sản phẩm %s: %v", productID, err)
+	}
+}
+
+// logSearchQuery ghi nhận từ khóa người dùng thực sự tìm để xếp hạng gợi ý
+func (s *service) logSearchQuery(ctx context.Context, keywords string) {
+	query := assets_services.FoldVietnamese(keywords)
+	if query == "" {
+		return
+	}
+	if err := s.redis.LogSearchQuery(ctx, query); err != nil {
+		log.Printf("[Suggest] không thể ghi log từ khóa: %v", err)
+	}
+}
+
+// StartSuggestIndexSync dựng chỉ mục gợi ý nếu chưa có, sau đó dựng lại định kỳ
+func (s *service) StartSuggestIndexSync(ctx context.Context) {
+	exists, err := s.redis.HasSuggestions(ctx)
+	if err != nil {
+		log.Printf("[Suggest] không thể kiểm tra chỉ mục gợi ý: %v", err)
+	}
+	if !exists {
+		if _, errRebuild := s.RebuildSuggestIndex(ctx); errRebuild != nil {
+			log.Printf("[Suggest] không thể dựng chỉ mục gợi ý: %v", errRebuild)
+		}
+	}
+	ticker := time.NewTicker(suggestRebuildInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, errRebuild := s.RebuildSuggestIndex(ctx); errRebuild != nil {
+				log.Printf("[Suggest] không thể dựng lại chỉ mục gợi ý: %v", errRebuild)
+			}
+		}
+	}
+}